@@ -26,7 +26,9 @@ in
       type = types.path;
       description = ''
         Path to environment file containing secrets.
-        Should include: CARDDAV_URL, CARDDAV_USERNAME, CARDDAV_PASSWORD, and ZK settings.
+        Should include ZK settings, and optionally CARDDAV_URL, CARDDAV_USERNAME
+        and CARDDAV_PASSWORD to add a default CardDAV source on startup, and
+        IMAP_URL, IMAP_USERNAME and IMAP_PASSWORD to track email interactions.
       '';
     };
   };
@@ -59,6 +59,9 @@ const runtimeEnvVar = "GROUNDWAVE_ENV"
 
 var safeImageDataURLPattern = regexp.MustCompile(`(?i)^data:image/(?:png|jpe?g|gif|webp|bmp);base64,[a-z0-9+/=]+$`)
 
+// safeImageContactPhotoPattern matches the route serving uploaded contact photos
+var safeImageContactPhotoPattern = regexp.MustCompile(`^/contact/[0-9a-f-]{36}/photo(?:\?v=\d+)?$`)
+
 func safeImageURL(raw *string) template.URL {
 	if raw == nil {
 		return ""
@@ -81,6 +84,10 @@ func safeImageURL(raw *string) template.URL {
 		return template.URL(value) //nolint:gosec // Value is constrained to a strict image data URL allowlist.
 	}
 
+	if safeImageContactPhotoPattern.MatchString(value) {
+		return template.URL(value) //nolint:gosec // Value is constrained to the local contact photo route.
+	}
+
 	return ""
 }
 
@@ -214,18 +221,21 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 	// Start cache rebuild worker
 	db.StartRebuildCacheWorker(ctx)
 
-	// Sync CardDAV contacts
-	appLogger.Info("Syncing contacts from CardDAV")
-
-	if err := db.SyncAllCardDAVContacts(ctx); err != nil {
-		appLogger.Warn("CardDAV sync failed", "error", err)
-		// Don't fail startup, just log the error
+	// Carry over an address book configured through the environment
+	if err := db.ImportCardDAVSourceFromEnv(ctx); err != nil {
+		appLogger.Error("Failed to add CardDAV source from environment", "error", err)
 	}
 
+	// Start CardDAV sync worker
+	db.StartCardDAVSyncWorker(ctx)
+
+	// Start email poll worker (optional feature)
+	db.StartEmailPollWorker(ctx)
+
 	// Initialize WhatsApp client (optional feature)
 	whatsappLogger.Info("Initializing WhatsApp client")
 
-	if err := whatsapp.Initialize(ctx, databaseURL, handleWhatsAppMessage); err != nil {
+	if err := whatsapp.Initialize(ctx, databaseURL, handleWhatsAppMessage, handleWhatsAppHistory); err != nil {
 		whatsappLogger.Warn("WhatsApp initialization failed", "error", err)
 		// Don't fail startup, WhatsApp is optional
 	} else {
@@ -546,6 +556,7 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 	f.Get("/f/{path: **}", routes.PublicFilesView)
 	f.Get("/xc/{token}", routes.ViewContactExchange)
 	f.Get("/xc/{token}/me.vcf", routes.DownloadContactExchangeMeVCF)
+	f.Get("/calendar/dates.ics", routes.ContactDatesCalendar)
 	f.Get("/ext/auth", routes.RequireAuth, routes.RequireAdmin, routes.ExtensionAuth)
 	f.Get("/ext/complete", routes.ExtensionComplete)
 	f.Get("/ext/validate", routes.ExtensionValidate)
@@ -564,6 +575,7 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 		f.Get("/home", routes.HomeWikiIndex)
 		f.Get("/home/{id}", routes.ViewHomeWikiNote)
 		f.Get("/break-glass", routes.BreakGlassForm)
+		f.Get("/search", routes.Search)
 
 		// Inventory read-only routes
 		f.Get("/inventory", routes.InventoryList)
@@ -622,10 +634,26 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 			f.Get("/ledger/accounts/{id}/reconciliations/{rec_id}/edit", routes.LedgerReconciliationEditForm)
 			f.Get("/contact/new", routes.NewContactForm)
 			f.Get("/contact/{id}/edit", routes.EditContactForm)
+			f.Get("/contact/{id}/carddav/conflict", routes.ViewCardDAVConflict)
+			f.Get("/carddav/sources", routes.CardDAVSources)
+			f.Get("/whatsapp/inbox", routes.WhatsAppInbox)
+			f.Get("/chats/import", routes.ChatImport)
+			f.Get("/email", routes.EmailTracking)
+			f.Get("/tags/{id}/whatsapp", routes.TagWhatsAppForm)
 
 			// Bulk contact operations
 			f.Get("/bulk-contact-log", routes.BulkContactLogForm)
 
+			// Duplicate detection and merging
+			f.Get("/contacts/duplicates", routes.ContactDuplicates)
+			f.Get("/contacts/merge", routes.MergeContactsForm)
+
+			// Relationship network between contacts
+			f.Get("/contacts/graph", routes.RelationshipGraph)
+
+			// vCard export of the contact database
+			f.Get("/contacts/export.vcf", routes.ExportContactsVCard)
+
 			f.Group("", func() {
 				f.Post("/journal/{date}/location", routes.AddJournalLocation)
 				f.Post("/journal/{date}/location/{location_id}/delete", routes.DeleteJournalLocation)
@@ -646,16 +674,35 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 				f.Post("/contact/{id}/log", routes.AddLog)
 				f.Post("/contact/{id}/log/{log_id}/edit", routes.UpdateLog)
 				f.Post("/contact/{id}/log/{log_id}/delete", routes.DeleteLog)
+				f.Post("/contact/{id}/briefing", routes.GenerateContactBriefing)
 				f.Post("/contact/{id}/note", routes.AddNote)
 				f.Post("/contact/{id}/note/{note_id}/edit", routes.UpdateNote)
 				f.Post("/contact/{id}/note/{note_id}/delete", routes.DeleteNote)
 				f.Post("/contact/{id}/carddav/link", routes.LinkCardDAV)
 				f.Post("/contact/{id}/carddav/unlink", routes.UnlinkCardDAV)
+				f.Post("/contact/{id}/carddav/conflict", routes.ResolveCardDAVConflict)
 				f.Post("/contact/{id}/carddav/migrate", routes.MigrateToCardDAV)
+				f.Post("/carddav/sources", routes.CreateCardDAVSource)
+				f.Post("/carddav/sources/{id}/edit", routes.UpdateCardDAVSource)
+				f.Post("/carddav/sources/{id}/delete", routes.DeleteCardDAVSource)
+				f.Post("/whatsapp/inbox/{phone}/create", routes.CreateContactFromWhatsAppNumber)
+				f.Post("/whatsapp/inbox/{phone}/attach", routes.AttachWhatsAppNumber)
+				f.Post("/whatsapp/inbox/{phone}/ignore", routes.IgnoreWhatsAppNumber)
+				f.Post("/contact/{id}/whatsapp/send", routes.SendContactWhatsApp)
+				f.Post("/tags/{id}/whatsapp", routes.SendTagWhatsApp)
 				f.Post("/contact/{id}/delete", routes.DeleteContact)
+				f.Post("/contacts/merge", routes.MergeContacts)
+				f.Post("/contacts/import", routes.ImportContactsVCard)
+				f.Post("/chats/import", routes.ImportChatArchive)
+				f.Post("/email/poll", routes.PollEmailNow)
+				f.Post("/email/ignore-rules", routes.AddEmailIgnoreRule)
+				f.Post("/email/ignore-rules/{id}/delete", routes.DeleteEmailIgnoreRule)
 				f.Post("/contact/{id}/tag", routes.AddTag)
 				f.Post("/contact/{id}/tag/{tag_id}/delete", routes.RemoveTag)
+				f.Post("/contact/{id}/relationship", routes.AddRelationship)
+				f.Post("/contact/{id}/relationship/{relationship_id}/delete", routes.DeleteRelationship)
 				f.Post("/bulk-contact-log", routes.BulkAddLog)
+				f.Post("/overdue/cadences", routes.UpdateTierCadences)
 			}, csrf.Validate)
 		}, routes.RequireSensitiveAccess)
 
@@ -669,6 +716,9 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 		f.Get("/files/edit", routes.FilesEditForm)
 		f.Get("/contact/{id}", routes.ViewContact)
 		f.Get("/contact/{id}/chats", routes.ViewContactChats)
+		f.Get("/contact/{id}/timeline", routes.ViewContactTimeline)
+		f.Get("/contact/{id}/address/{address_id}/map.png", routes.ContactAddressMap)
+		f.Get("/contact/{id}/photo", routes.ContactPhoto)
 		f.Get("/carddav/contacts", routes.ListCardDAVContacts)
 		f.Get("/carddav/picker", routes.CardDAVPicker)
 
@@ -685,7 +735,9 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 		f.Get("/zk/random", routes.ZettelkastenRandom)
 		f.Get("/zk/list", routes.ZettelkastenList)
 		f.Get("/zk/chat", routes.ZettelkastenChat)
+		f.Get("/zk/new", routes.NewZKNoteForm)
 		f.Get("/zk/{id}", routes.ViewZKNote)
+		f.Get("/zk/{id}/edit", routes.EditZKNoteForm)
 		f.Get("/zettel-inbox", routes.ZettelCommentsInbox)
 
 		// Inventory routes (admin)
@@ -726,17 +778,29 @@ func start(ctx context.Context, cmd *cli.Command) (err error) {
 			f.Post("/contact/{id}/email/{email_id}/edit", routes.UpdateEmail)
 			f.Post("/contact/{id}/phone/{phone_id}/delete", routes.DeletePhone)
 			f.Post("/contact/{id}/phone/{phone_id}/edit", routes.UpdatePhone)
+			f.Post("/contact/{id}/address", routes.AddAddress)
+			f.Post("/contact/{id}/address/{address_id}/delete", routes.DeleteAddress)
+			f.Post("/contact/{id}/address/{address_id}/edit", routes.UpdateAddress)
 			f.Post("/contact/{id}/url/{url_id}/delete", routes.DeleteURL)
+			f.Post("/contact/{id}/photo", routes.UploadContactPhoto)
+			f.Post("/contact/{id}/photo/delete", routes.DeleteContactPhoto)
+			f.Post("/contacts/saved-searches", routes.SaveSearch)
+			f.Post("/contacts/saved-searches/{id}/delete", routes.DeleteSavedSearch)
+			f.Post("/contacts/carddav/sync", routes.SyncCardDAVNow)
 			f.Post("/tags/{id}/edit", routes.UpdateTag)
 			f.Post("/tags/{id}/delete", routes.DeleteTag)
 			f.Post("/zk/chat/links", routes.ZettelkastenChatLinks)
 			f.Post("/zk/chat/backlinks", routes.ZettelkastenChatBacklinks)
 			f.Post("/zk/chat/stream", routes.ZettelkastenChatStream)
+			f.Post("/zk/new", routes.CreateZKNote)
+			f.Post("/zk/{id}/edit", routes.UpdateZKNote)
 			f.Post("/zk/{id}/comment", routes.AddZettelComment)
 			f.Post("/zk/{id}/comment/{comment_id}/edit", routes.UpdateZettelComment)
 			f.Post("/zk/{id}/comment/{comment_id}/delete", routes.DeleteZettelComment)
 			f.Post("/zk/{id}/comments/delete", routes.DeleteAllZettelComments)
 			f.Post("/rebuild-cache", routes.RebuildCache)
+			f.Post("/todo/state", routes.UpdateTodoState)
+			f.Post("/todo/new", routes.CreateTodoTask)
 			f.Post("/inventory/new", routes.CreateInventoryItem)
 			f.Post("/inventory/{id}/edit", routes.UpdateInventoryItem)
 			f.Post("/inventory/{id}/delete", routes.DeleteInventoryItem)
@@ -789,13 +853,55 @@ func configureEmptyNotFoundHandler(f *flamego.Flame) {
 	})
 }
 
+// whatsAppMediaDownloadEnvVar turns on downloading WhatsApp attachments of
+// known contacts into their WebDAV media folder
+const whatsAppMediaDownloadEnvVar = "WHATSAPP_MEDIA_DOWNLOAD"
+
+// whatsAppMediaDownloadEnabled reports whether attachments are downloaded
+func whatsAppMediaDownloadEnabled() bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(whatsAppMediaDownloadEnvVar)))
+
+	return err == nil && enabled
+}
+
+// whatsAppChatMedia converts an attachment descriptor for storage
+func whatsAppChatMedia(media *whatsapp.Media) *db.ChatMedia {
+	if media == nil {
+		return nil
+	}
+
+	return &db.ChatMedia{
+		Type:     db.ChatMediaType(media.Type),
+		MimeType: media.MimeType,
+		FileName: media.FileName,
+		Size:     int64(media.Size), //nolint:gosec // Attachment sizes are far below the int64 limit.
+	}
+}
+
 // handleWhatsAppMessage is called when a WhatsApp message is sent or received.
-// It updates the last_auto_contact timestamp for matching contacts.
-func handleWhatsAppMessage(jid string, timestamp time.Time, isOutgoing bool, message string) {
+// It updates the last_auto_contact timestamp for matching contacts, applies
+// edits and revokes to stored chats, and queues messages of unmatched numbers
+// in the unknown correspondents inbox.
+func handleWhatsAppMessage(message whatsapp.Message) {
 	ctx := context.Background()
 
+	switch message.Kind {
+	case whatsapp.MessageEdit:
+		if err := db.EditChatMessage(ctx, db.ChatPlatformWhatsApp, message.ID, message.Text, message.Timestamp); err != nil {
+			whatsappLogger.Error("Failed to apply WhatsApp edit", "message_id", message.ID, "error", err)
+		}
+
+		return
+	case whatsapp.MessageRevoke:
+		if err := db.RevokeChatMessage(ctx, db.ChatPlatformWhatsApp, message.ID, message.Timestamp); err != nil {
+			whatsappLogger.Error("Failed to apply WhatsApp revoke", "message_id", message.ID, "error", err)
+		}
+
+		return
+	}
+
 	// Extract phone number from JID
-	phone := whatsapp.JIDToPhone(jid)
+	phone := whatsapp.JIDToPhone(message.JID)
 
 	// Find contact by phone number
 	contactID, err := db.FindContactByPhone(ctx, phone)
@@ -805,32 +911,43 @@ func handleWhatsAppMessage(jid string, timestamp time.Time, isOutgoing bool, mes
 	}
 
 	if contactID == nil {
-		// No matching contact found, ignore
+		if err := db.RecordUnknownWhatsAppMessage(ctx, phone, message.ID, message.Timestamp, message.IsOutgoing, message.Text); err != nil {
+			whatsappLogger.Error("Failed to queue message from unknown number", "phone", phone, "error", err)
+		}
+
 		return
 	}
 
 	// Update the contact's auto-contact timestamp
-	err = db.UpdateContactAutoTimestamp(ctx, *contactID, timestamp)
+	err = db.UpdateContactAutoTimestamp(ctx, *contactID, message.Timestamp)
 	if err != nil {
 		whatsappLogger.Error("Failed to update auto contact timestamp", "contact_id", *contactID, "error", err)
 		return
 	}
 
-	cleanMessage := strings.TrimSpace(message)
-	if cleanMessage != "" {
-		sentAt := timestamp.Format(time.RFC3339Nano)
+	media := whatsAppChatMedia(message.Media)
+	if media != nil && whatsAppMediaDownloadEnabled() {
+		storeWhatsAppMedia(ctx, *contactID, message, media)
+	}
+
+	cleanMessage := strings.TrimSpace(message.Text)
+	if cleanMessage != "" || media != nil {
+		sentAt := message.Timestamp.Format(time.RFC3339Nano)
 
 		sender := db.ChatSenderThem
-		if isOutgoing {
+		if message.IsOutgoing {
 			sender = db.ChatSenderMe
 		}
 
 		err = db.AddChat(ctx, db.AddChatInput{
-			ContactID: *contactID,
-			Platform:  db.ChatPlatformWhatsApp,
-			Sender:    sender,
-			Message:   cleanMessage,
-			SentAt:    &sentAt,
+			ContactID:        *contactID,
+			Platform:         db.ChatPlatformWhatsApp,
+			Sender:           sender,
+			Message:          cleanMessage,
+			SentAt:           &sentAt,
+			MessageID:        message.ID,
+			ReplyToMessageID: message.ReplyToID,
+			Media:            media,
 		})
 		if err != nil {
 			whatsappLogger.Error("Failed to add WhatsApp chat entry", "contact_id", *contactID, "error", err)
@@ -838,9 +955,82 @@ func handleWhatsAppMessage(jid string, timestamp time.Time, isOutgoing bool, mes
 	}
 
 	direction := "received"
-	if isOutgoing {
+	if message.IsOutgoing {
 		direction = "sent"
 	}
 
 	whatsappLogger.Info("Updated last_auto_contact", "contact_id", *contactID, "direction", direction)
 }
+
+// storeWhatsAppMedia downloads the attachment of a message into the
+// contact's media folder, setting its path on media. Service contacts keep no
+// chats and redelivered messages already have their file, so neither is
+// downloaded.
+func storeWhatsAppMedia(ctx context.Context, contactID string, message whatsapp.Message, media *db.ChatMedia) {
+	client := whatsapp.GetClient()
+	if client == nil {
+		return
+	}
+
+	isService, err := db.IsServiceContact(ctx, contactID)
+	if err != nil {
+		whatsappLogger.Warn("Failed to check service contact", "contact_id", contactID, "error", err)
+		return
+	}
+
+	if isService {
+		return
+	}
+
+	exists, err := db.ChatMessageExists(ctx, db.ChatPlatformWhatsApp, message.ID)
+	if err != nil {
+		whatsappLogger.Warn("Failed to check WhatsApp chat", "message_id", message.ID, "error", err)
+		return
+	}
+
+	if exists {
+		return
+	}
+
+	data, err := client.DownloadMedia(ctx, message.Media)
+	if err != nil {
+		whatsappLogger.Warn("Failed to download WhatsApp media", "contact_id", contactID, "error", err)
+		return
+	}
+
+	if media.Path, err = db.StoreChatMedia(ctx, contactID, message.ID, *media, data); err != nil {
+		whatsappLogger.Warn("Failed to store WhatsApp media", "contact_id", contactID, "error", err)
+	}
+}
+
+// handleWhatsAppHistory is called with each history sync blob, from pairing
+// onwards. It backfills the messages into contact chats and moves
+// last_auto_contact up to the latest interaction.
+func handleWhatsAppHistory(messages []whatsapp.Message) {
+	history := make([]db.WhatsAppHistoryMessage, 0, len(messages))
+	for _, message := range messages {
+		history = append(history, db.WhatsAppHistoryMessage{
+			MessageID:        message.ID,
+			Phone:            whatsapp.JIDToPhone(message.JID),
+			SentAt:           message.Timestamp,
+			IsOutgoing:       message.IsOutgoing,
+			Message:          message.Text,
+			ReplyToMessageID: message.ReplyToID,
+			Media:            whatsAppChatMedia(message.Media),
+		})
+	}
+
+	result, err := db.ImportWhatsAppHistory(context.Background(), history)
+	if err != nil {
+		whatsappLogger.Error("Failed to import WhatsApp history", "error", err)
+		return
+	}
+
+	whatsappLogger.Info(
+		"Imported WhatsApp history",
+		"contacts", result.Contacts,
+		"imported", result.Imported,
+		"duplicates", result.Duplicates,
+		"unknown_numbers", result.Unknown,
+	)
+}
@@ -59,6 +59,20 @@ func TestSafeImageURLRejectsUnsupportedDataImageType(t *testing.T) {
 	}
 }
 
+func TestSafeImageURLAllowsContactPhotoRoute(t *testing.T) {
+	t.Parallel()
+
+	photo := "/contact/0b7e6c1a-3f1e-4d2a-9c55-1f2a3b4c5d6e/photo?v=1700000000"
+	if got := safeImageURL(&photo); string(got) != photo {
+		t.Fatalf("expected contact photo route to be allowed, got %q", got)
+	}
+
+	other := "/files/download?path=secret.jpg"
+	if got := safeImageURL(&other); got != "" {
+		t.Fatalf("expected other local paths to be rejected, got %q", got)
+	}
+}
+
 func TestConfigureEmptyNotFoundHandlerReturnsStatusOnly(t *testing.T) {
 	t.Parallel()
 
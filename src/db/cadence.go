@@ -0,0 +1,312 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CadenceSource identifies where a contact's effective follow-up interval comes from
+type CadenceSource string
+
+const (
+	// CadenceSourceContact is a per-contact override
+	CadenceSourceContact CadenceSource = "contact"
+	// CadenceSourceTag is the shortest override among the contact's tags
+	CadenceSourceTag CadenceSource = "tag"
+	// CadenceSourceTier is the default for the contact's tier
+	CadenceSourceTier CadenceSource = "tier"
+)
+
+// effectiveCadenceCTE resolves the follow-up interval for every contact.
+// A contact override wins over tag overrides, which win over the tier default.
+// When several tags carry an override, the shortest interval applies.
+const effectiveCadenceCTE = `
+	tag_cadences AS (
+		SELECT DISTINCT ON (ct.contact_id)
+			ct.contact_id,
+			t.name AS tag_name,
+			t.cadence_days
+		FROM contact_tags ct
+		INNER JOIN tags t ON t.id = ct.tag_id
+		WHERE t.cadence_days IS NOT NULL
+		ORDER BY ct.contact_id, t.cadence_days ASC, t.name ASC
+	),
+	contact_intervals AS (
+		SELECT
+			c.id,
+			COALESCE(c.cadence_days, tgc.cadence_days, tc.interval_days) AS interval_days,
+			CASE
+				WHEN c.cadence_days IS NOT NULL THEN 'contact'
+				WHEN tgc.cadence_days IS NOT NULL THEN 'tag'
+				ELSE 'tier'
+			END AS cadence_source,
+			tgc.tag_name AS cadence_tag
+		FROM contacts c
+		LEFT JOIN tag_cadences tgc ON tgc.contact_id = c.id
+		LEFT JOIN tier_cadences tc ON tc.tier = c.tier
+	)`
+
+// defaultTierCadences are the built-in intervals seeded for tiers without a configured cadence
+var defaultTierCadences = []struct {
+	Tier Tier
+	Days int // 0 means no regular contact
+}{
+	{Tier: TierA, Days: 21},  // 3 weeks
+	{Tier: TierB, Days: 60},  // 2 months
+	{Tier: TierC, Days: 180}, // 6 months
+	{Tier: TierD, Days: 365}, // 1 year
+	{Tier: TierE, Days: 730}, // 2 years
+	{Tier: TierF, Days: 0},
+}
+
+// TierCadence is the default follow-up interval for a tier
+type TierCadence struct {
+	Tier         Tier      `db:"tier"`
+	IntervalDays *int      `db:"interval_days"` // nil means no regular contact
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// ContactCadence describes a contact's follow-up settings and the interval they resolve to
+type ContactCadence struct {
+	CadenceDays   *int       // Per-contact override
+	SnoozedUntil  *time.Time // Hidden from the overdue list until this date
+	TierDays      *int       // Default for the contact's tier
+	TagDays       *int       // Shortest override among the contact's tags
+	TagName       *string    // Tag providing TagDays
+	EffectiveDays *int       // Interval actually used, nil if never overdue
+	Source        CadenceSource
+}
+
+// IsSnoozed reports whether the contact is snoozed on the given day
+func (c ContactCadence) IsSnoozed(now time.Time) bool {
+	if c.SnoozedUntil == nil {
+		return false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return c.SnoozedUntil.After(today)
+}
+
+func validateCadenceDays(days *int) error {
+	if days != nil && *days <= 0 {
+		return ErrCadenceDaysInvalid
+	}
+
+	return nil
+}
+
+// SyncTierCadences seeds the built-in default interval for any tier missing one.
+// Intervals that were already configured are left untouched.
+func SyncTierCadences(ctx context.Context) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	for _, cadence := range defaultTierCadences {
+		var days *int
+		if cadence.Days > 0 {
+			days = &cadence.Days
+		}
+
+		_, err := pool.Exec(ctx, `
+			INSERT INTO tier_cadences (tier, interval_days)
+			VALUES ($1, $2)
+			ON CONFLICT (tier) DO NOTHING
+		`, cadence.Tier, days)
+		if err != nil {
+			return fmt.Errorf("failed to seed tier cadence %s: %w", cadence.Tier, err)
+		}
+	}
+
+	return nil
+}
+
+// ListTierCadences returns the default follow-up interval for each tier
+func ListTierCadences(ctx context.Context) ([]TierCadence, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT tier, interval_days, updated_at
+		FROM tier_cadences
+		ORDER BY tier ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tier cadences: %w", err)
+	}
+	defer rows.Close()
+
+	var cadences []TierCadence
+
+	for rows.Next() {
+		var cadence TierCadence
+		if err := rows.Scan(&cadence.Tier, &cadence.IntervalDays, &cadence.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tier cadence: %w", err)
+		}
+
+		cadences = append(cadences, cadence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tier cadences: %w", err)
+	}
+
+	return cadences, nil
+}
+
+// UpdateTierCadence sets the default follow-up interval for a tier.
+// A nil interval means contacts in the tier need no regular contact.
+func UpdateTierCadence(ctx context.Context, tier Tier, days *int) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	switch tier {
+	case TierA, TierB, TierC, TierD, TierE, TierF:
+	default:
+		return ErrTierInvalid
+	}
+
+	if err := validateCadenceDays(days); err != nil {
+		return err
+	}
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO tier_cadences (tier, interval_days)
+		VALUES ($1, $2)
+		ON CONFLICT (tier) DO UPDATE SET interval_days = EXCLUDED.interval_days
+	`, tier, days)
+	if err != nil {
+		return fmt.Errorf("failed to update tier cadence: %w", err)
+	}
+
+	return nil
+}
+
+// GetTagCadence returns the follow-up override for contacts with a tag
+func GetTagCadence(ctx context.Context, tagID string) (*int, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	var days *int
+
+	err := pool.QueryRow(ctx, `SELECT cadence_days FROM tags WHERE id = $1`, tagID).Scan(&days)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+
+		return nil, fmt.Errorf("failed to query tag cadence: %w", err)
+	}
+
+	return days, nil
+}
+
+// UpdateTagCadence sets or clears the follow-up override for contacts with a tag
+func UpdateTagCadence(ctx context.Context, tagID string, days *int) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if err := validateCadenceDays(days); err != nil {
+		return err
+	}
+
+	result, err := pool.Exec(ctx, `UPDATE tags SET cadence_days = $1 WHERE id = $2`, days, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to update tag cadence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// GetContactCadence returns a contact's follow-up settings with the resolved interval
+func GetContactCadence(ctx context.Context, contactID string) (*ContactCadence, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `
+		WITH ` + effectiveCadenceCTE + `
+		SELECT
+			c.cadence_days,
+			c.cadence_snoozed_until,
+			tc.interval_days,
+			t.cadence_days,
+			t.tag_name,
+			ci.interval_days,
+			ci.cadence_source
+		FROM contacts c
+		INNER JOIN contact_intervals ci ON ci.id = c.id
+		LEFT JOIN tier_cadences tc ON tc.tier = c.tier
+		LEFT JOIN tag_cadences t ON t.contact_id = c.id
+		WHERE c.id = $1
+	`
+
+	var (
+		cadence ContactCadence
+		source  string
+	)
+
+	err := pool.QueryRow(ctx, query, contactID).Scan(
+		&cadence.CadenceDays,
+		&cadence.SnoozedUntil,
+		&cadence.TierDays,
+		&cadence.TagDays,
+		&cadence.TagName,
+		&cadence.EffectiveDays,
+		&source,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrContactNotFound
+		}
+
+		return nil, fmt.Errorf("failed to query contact cadence: %w", err)
+	}
+
+	cadence.Source = CadenceSource(source)
+
+	return &cadence, nil
+}
+
+// UpdateContactCadence sets or clears a contact's follow-up override and snooze date
+func UpdateContactCadence(ctx context.Context, contactID string, days *int, snoozedUntil *time.Time) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if err := validateCadenceDays(days); err != nil {
+		return err
+	}
+
+	result, err := pool.Exec(ctx, `
+		UPDATE contacts
+		SET cadence_days = $1, cadence_snoozed_until = $2::date
+		WHERE id = $3
+	`, days, snoozedUntil, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to update contact cadence: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrContactNotFound
+	}
+
+	return nil
+}
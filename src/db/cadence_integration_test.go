@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func overdueContactByID(items []OverdueContactItem, id string) *OverdueContactItem {
+	for i := range items {
+		if items[i].ID == id {
+			return &items[i]
+		}
+	}
+
+	return nil
+}
+
+func TestContactCadenceResolution(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	tierContact := mustCreateContact(t, CreateContactInput{NameGiven: "Tier", Tier: TierA})
+	tagContact := mustCreateContact(t, CreateContactInput{NameGiven: "Tagged", Tier: TierD})
+	customContact := mustCreateContact(t, CreateContactInput{NameGiven: "Custom", Tier: TierF})
+	snoozedContact := mustCreateContact(t, CreateContactInput{NameGiven: "Snoozed", Tier: TierA})
+
+	if err := AddTagToContact(ctx, tagContact, "family"); err != nil {
+		t.Fatalf("AddTagToContact failed: %v", err)
+	}
+
+	if err := AddTagToContact(ctx, tagContact, "club"); err != nil {
+		t.Fatalf("AddTagToContact failed: %v", err)
+	}
+
+	tags, err := GetContactTags(ctx, tagContact)
+	if err != nil {
+		t.Fatalf("GetContactTags failed: %v", err)
+	}
+
+	for _, tag := range tags {
+		days := 30
+		if tag.Name == "club" {
+			days = 90
+		}
+
+		if err := UpdateTagCadence(ctx, tag.ID.String(), &days); err != nil {
+			t.Fatalf("UpdateTagCadence failed: %v", err)
+		}
+	}
+
+	customDays := 10
+	if err := UpdateContactCadence(ctx, customContact, &customDays, nil); err != nil {
+		t.Fatalf("UpdateContactCadence failed: %v", err)
+	}
+
+	snoozeUntil := time.Now().AddDate(0, 0, 7)
+	if err := UpdateContactCadence(ctx, snoozedContact, nil, &snoozeUntil); err != nil {
+		t.Fatalf("UpdateContactCadence failed: %v", err)
+	}
+
+	tagCadence, err := GetContactCadence(ctx, tagContact)
+	if err != nil {
+		t.Fatalf("GetContactCadence failed: %v", err)
+	}
+
+	if tagCadence.Source != CadenceSourceTag || tagCadence.EffectiveDays == nil || *tagCadence.EffectiveDays != 30 {
+		t.Fatalf("expected shortest tag cadence of 30 days, got %#v", tagCadence)
+	}
+
+	if tagCadence.TagName == nil || *tagCadence.TagName != "family" {
+		t.Fatalf("expected family tag to provide the cadence, got %v", tagCadence.TagName)
+	}
+
+	if tagCadence.TierDays == nil || *tagCadence.TierDays != 365 {
+		t.Fatalf("expected tier D default of 365 days, got %v", tagCadence.TierDays)
+	}
+
+	customCadence, err := GetContactCadence(ctx, customContact)
+	if err != nil {
+		t.Fatalf("GetContactCadence failed: %v", err)
+	}
+
+	if customCadence.Source != CadenceSourceContact || customCadence.EffectiveDays == nil || *customCadence.EffectiveDays != 10 {
+		t.Fatalf("expected contact cadence of 10 days, got %#v", customCadence)
+	}
+
+	snoozedCadence, err := GetContactCadence(ctx, snoozedContact)
+	if err != nil {
+		t.Fatalf("GetContactCadence failed: %v", err)
+	}
+
+	if !snoozedCadence.IsSnoozed(time.Now()) {
+		t.Fatalf("expected contact to be snoozed")
+	}
+
+	staleTime := time.Now().AddDate(0, 0, -40)
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET created_at = $1, last_auto_contact = $1`, staleTime); err != nil {
+		t.Fatalf("failed to adjust contact timestamps: %v", err)
+	}
+
+	overdue, err := GetOverdueContacts(ctx)
+	if err != nil {
+		t.Fatalf("GetOverdueContacts failed: %v", err)
+	}
+
+	if item := overdueContactByID(overdue, tierContact); item == nil || item.ContactInterval != 21 || item.CadenceSource != CadenceSourceTier {
+		t.Fatalf("expected tier contact overdue with 21 day interval, got %#v", item)
+	}
+
+	if item := overdueContactByID(overdue, tagContact); item == nil || item.ContactInterval != 30 || item.CadenceSource != CadenceSourceTag {
+		t.Fatalf("expected tagged contact overdue with 30 day interval, got %#v", item)
+	}
+
+	if item := overdueContactByID(overdue, customContact); item == nil || item.ContactInterval != 10 || item.CadenceSource != CadenceSourceContact {
+		t.Fatalf("expected custom contact overdue with 10 day interval, got %#v", item)
+	}
+
+	if item := overdueContactByID(overdue, snoozedContact); item != nil {
+		t.Fatalf("expected snoozed contact to be excluded, got %#v", item)
+	}
+
+	tierDays := 60
+	if err := UpdateTierCadence(ctx, TierA, &tierDays); err != nil {
+		t.Fatalf("UpdateTierCadence failed: %v", err)
+	}
+
+	overdue, err = GetOverdueContacts(ctx)
+	if err != nil {
+		t.Fatalf("GetOverdueContacts failed: %v", err)
+	}
+
+	if item := overdueContactByID(overdue, tierContact); item != nil {
+		t.Fatalf("expected tier contact to no longer be overdue, got %#v", item)
+	}
+
+	tierCadences, err := ListTierCadences(ctx)
+	if err != nil {
+		t.Fatalf("ListTierCadences failed: %v", err)
+	}
+
+	if len(tierCadences) != 6 {
+		t.Fatalf("expected 6 tier cadences, got %d", len(tierCadences))
+	}
+}
+
+func TestCadenceValidation(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Invalid", Tier: TierC})
+	zero := 0
+
+	if err := UpdateContactCadence(ctx, contactID, &zero, nil); !errors.Is(err, ErrCadenceDaysInvalid) {
+		t.Fatalf("expected ErrCadenceDaysInvalid, got %v", err)
+	}
+
+	if err := UpdateTierCadence(ctx, Tier("Z"), nil); !errors.Is(err, ErrTierInvalid) {
+		t.Fatalf("expected ErrTierInvalid, got %v", err)
+	}
+
+	if err := UpdateTagCadence(ctx, "00000000-0000-0000-0000-000000000000", nil); !errors.Is(err, ErrTagNotFound) {
+		t.Fatalf("expected ErrTagNotFound, got %v", err)
+	}
+
+	if _, err := GetContactCadence(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound, got %v", err)
+	}
+}
@@ -6,11 +6,12 @@ package db
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -64,42 +65,35 @@ type CardDAVPhone struct {
 
 // CardDAVAddress represents an address from CardDAV
 type CardDAVAddress struct {
+	POBox      string
+	Extended   string
 	Street     string
 	Locality   string
 	Region     string
 	PostalCode string
 	Country    string
 	Type       string
+	Preferred  bool
+	Latitude   *float64 // From the GEO parameter, if present
+	Longitude  *float64
 }
 
-// GetCardDAVConfig loads CardDAV configuration from environment variables
-func GetCardDAVConfig() (*CardDAVConfig, error) {
-	url := os.Getenv("CARDDAV_URL")
-	username := os.Getenv("CARDDAV_USERNAME")
-	password := os.Getenv("CARDDAV_PASSWORD")
-
-	if url == "" || username == "" || password == "" {
-		return nil, ErrCardDAVConfigIncomplete
-	}
-
-	return &CardDAVConfig{
-		URL:      url,
-		Username: username,
-		Password: password,
-	}, nil
-}
-
-// newCardDAVClient creates a new CardDAV client
-func newCardDAVClient(config *CardDAVConfig) (*carddav.Client, error) {
-	// Create HTTP client with Basic Auth
-	httpClient := &http.Client{
-		Timeout: 3 * time.Second, // Fast timeout for local/same-network CardDAV
+// newCardDAVHTTPClient creates an HTTP client authenticating with Basic Auth
+func newCardDAVHTTPClient(config *CardDAVConfig, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
 		Transport: &basicAuthTransport{
 			Username: config.Username,
 			Password: config.Password,
 			Base:     http.DefaultTransport,
 		},
 	}
+}
+
+// newCardDAVClient creates a new CardDAV client
+func newCardDAVClient(config *CardDAVConfig) (*carddav.Client, error) {
+	// Fast timeout for local/same-network CardDAV
+	httpClient := newCardDAVHTTPClient(config, 3*time.Second)
 
 	client, err := carddav.NewClient(httpClient, config.URL)
 	if err != nil {
@@ -127,9 +121,9 @@ func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error
 	return resp, nil
 }
 
-// ListCardDAVContacts fetches all contacts from the CardDAV server
-func ListCardDAVContacts(ctx context.Context) ([]CardDAVContact, error) {
-	config, err := GetCardDAVConfig()
+// ListCardDAVContacts fetches all contacts from a CardDAV source
+func ListCardDAVContacts(ctx context.Context, sourceID string) ([]CardDAVContact, error) {
+	config, err := GetCardDAVConfig(ctx, sourceID)
 	if err != nil {
 		return nil, err
 	}
@@ -185,10 +179,10 @@ func ListCardDAVContacts(ctx context.Context) ([]CardDAVContact, error) {
 	return contacts, nil
 }
 
-// GetCardDAVContact fetches a specific contact from the CardDAV server by UUID
-func GetCardDAVContact(ctx context.Context, uuid string) (*CardDAVContact, error) {
+// GetCardDAVContact fetches a specific contact from a CardDAV source by UUID
+func GetCardDAVContact(ctx context.Context, sourceID, uuid string) (*CardDAVContact, error) {
 	// Fetch all contacts and find the one with matching UUID
-	contacts, err := ListCardDAVContacts(ctx)
+	contacts, err := ListCardDAVContacts(ctx, sourceID)
 	if err != nil {
 		return nil, err
 	}
@@ -290,6 +284,8 @@ func parseVCard(card vcard.Card) CardDAVContact {
 				phoneType = "home"
 			} else if field.Params.HasType("fax") {
 				phoneType = "fax"
+			} else if field.Params.HasType("pager") {
+				phoneType = "pager"
 			}
 
 			contact.Phones = append(contact.Phones, CardDAVPhone{
@@ -301,6 +297,7 @@ func parseVCard(card vcard.Card) CardDAVContact {
 	}
 
 	// Get addresses
+	preferredAddress := card.Preferred(vcard.FieldAddress)
 	for _, addr := range card.Addresses() {
 		addrType := "other"
 		if addr.Params.HasType("work") {
@@ -309,13 +306,21 @@ func parseVCard(card vcard.Card) CardDAVContact {
 			addrType = "home"
 		}
 
+		// The decoder splits parameter values on commas, so rejoin the geo URI
+		lat, lon := parseGeoURI(strings.Join(addr.Params[vcard.ParamGeolocation], ","))
+
 		contact.Addresses = append(contact.Addresses, CardDAVAddress{
+			POBox:      addr.PostOfficeBox,
+			Extended:   addr.ExtendedAddress,
 			Street:     addr.StreetAddress,
 			Locality:   addr.Locality,
 			Region:     addr.Region,
 			PostalCode: addr.PostalCode,
 			Country:    addr.Country,
 			Type:       addrType,
+			Preferred:  addr.Field == preferredAddress,
+			Latitude:   lat,
+			Longitude:  lon,
 		})
 	}
 
@@ -410,6 +415,88 @@ func preferredPhoneValue(phones []CardDAVPhone) string {
 	return ""
 }
 
+// parseGeoURI parses an RFC 5870 geo URI such as "geo:25.2048,55.2708".
+// It returns nil coordinates when the value is missing or invalid.
+func parseGeoURI(value string) (*float64, *float64) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if len(value) < len("geo:") || !strings.EqualFold(value[:len("geo:")], "geo:") {
+		return nil, nil
+	}
+
+	coords, _, _ := strings.Cut(value[len("geo:"):], ";")
+
+	parts := strings.Split(coords, ",")
+	if len(parts) < 2 {
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, nil
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, nil
+	}
+
+	return &lat, &lon
+}
+
+// cardDAVAddressKey identifies an address by its components, ignoring case and spacing
+func cardDAVAddressKey(components ...string) string {
+	for i, component := range components {
+		components[i] = strings.ToLower(strings.Join(strings.Fields(component), " "))
+	}
+
+	return strings.Join(components, "\x1f")
+}
+
+func contactAddressKey(addr ContactAddress) string {
+	return cardDAVAddressKey(
+		pointerString(addr.POBox),
+		pointerString(addr.Extended),
+		pointerString(addr.Street),
+		pointerString(addr.Locality),
+		pointerString(addr.Region),
+		pointerString(addr.PostalCode),
+		pointerString(addr.Country),
+	)
+}
+
+func (a CardDAVAddress) key() string {
+	return cardDAVAddressKey(a.POBox, a.Extended, a.Street, a.Locality, a.Region, a.PostalCode, a.Country)
+}
+
+// newVCardAddress builds an ADR field for a contact address.
+// Coordinates stay local as the vCard encoder cannot write a quoted GEO parameter.
+func newVCardAddress(addr ContactAddress, includePreferred bool) *vcard.Address {
+	params := vcard.Params{}
+
+	switch addr.AddressType {
+	case AddressHome:
+		params.Set(vcard.ParamType, "home")
+	case AddressWork:
+		params.Set(vcard.ParamType, "work")
+	case AddressOther:
+	}
+
+	if includePreferred && addr.IsPrimary {
+		params.Set(vcard.ParamPreferred, "1")
+	}
+
+	return &vcard.Address{
+		Field:           &vcard.Field{Params: params},
+		PostOfficeBox:   pointerString(addr.POBox),
+		ExtendedAddress: pointerString(addr.Extended),
+		StreetAddress:   pointerString(addr.Street),
+		Locality:        pointerString(addr.Locality),
+		Region:          pointerString(addr.Region),
+		PostalCode:      pointerString(addr.PostalCode),
+		Country:         pointerString(addr.Country),
+	}
+}
+
 func normalizeCardDAVEmail(value string) string {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -535,6 +622,13 @@ func parseDateString(s string) (time.Time, error) {
 		}
 	}
 
+	// Dates without a year (vCard 4.0 "--MMDD", vCard 3.0 "--MM-DD")
+	for _, format := range []string{"--0102", "--01-02"} {
+		if t, err := time.Parse(format, s); err == nil {
+			return time.Date(ContactDateYearUnknown, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
 	return time.Time{}, fmt.Errorf("%w: %s", ErrUnableToParseDate, s)
 }
 
@@ -545,12 +639,49 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 		return ErrDatabaseConnectionNotInitialized
 	}
 
-	// Fetch the CardDAV contact
-	cardDAVContact, err := GetCardDAVContact(ctx, cardDAVUUID)
+	sourceID, err := contactCardDAVSourceID(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	config, err := GetCardDAVConfig(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+
+	client, err := newCardDAVClient(config)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the card itself so its ETag is known
+	path, err := findCardDAVContactPath(ctx, client, cardDAVUUID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch CardDAV contact: %w", err)
 	}
 
+	obj, err := client.GetAddressObject(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CardDAV contact: %w", err)
+	}
+
+	cardDAVContact := parseVCard(obj.Card)
+
+	// Local edits waiting on a conflict resolution must not be overwritten
+	held, err := holdConflictedCardDAVContact(ctx, contactID, obj.ETag, &cardDAVContact)
+	if err != nil || held {
+		return err
+	}
+
+	if err := applyCardDAVContact(ctx, contactID, &cardDAVContact); err != nil {
+		return err
+	}
+
+	return upsertCardDAVCard(ctx, sourceID, cardDAVCardState{UID: cardDAVUUID, Path: path, ETag: obj.ETag})
+}
+
+// applyCardDAVContact copies a fetched card onto the linked contact
+func applyCardDAVContact(ctx context.Context, contactID string, cardDAVContact *CardDAVContact) error {
 	// Build display name
 	nameDisplay := cardDAVContact.GivenName
 	if cardDAVContact.FamilyName != "" {
@@ -592,7 +723,9 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 		photoURLPtr = &cardDAVContact.PhotoURL
 	}
 
-	// Update the contact in the database
+	// Update the contact in the database. Birthday and anniversary keep any
+	// local value when the card has none, and an uploaded photo is kept since
+	// it is pushed to the card anyway.
 	query := `
 		UPDATE contacts SET
 			name_display = $1,
@@ -600,18 +733,22 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 			name_family = $3,
 			organization = $4,
 			title = $5,
-			photo_url = $6,
+			photo_url = CASE WHEN photo_path IS NULL THEN $6 ELSE photo_url END,
+			birthday = COALESCE($7::date, birthday),
+			anniversary = COALESCE($8::date, anniversary),
 			updated_at = now()
-		WHERE id = $7
+		WHERE id = $9
 	`
 
-	_, err = pool.Exec(ctx, query,
+	_, err := pool.Exec(ctx, query,
 		nameDisplay,
 		nameGiven,
 		nameFamilyPtr,
 		organizationPtr,
 		titlePtr,
 		photoURLPtr,
+		cardDAVContact.Birthday,
+		cardDAVContact.Anniversary,
 		contactID,
 	)
 	if err != nil {
@@ -645,12 +782,12 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 
 		emailAvailable, err := isCardDAVEmailAvailable(ctx, contactID, normalizedEmail)
 		if err != nil {
-			fmt.Printf("Warning: failed to check CardDAV email availability %s: %v\n", normalizedEmail, err)
+			logger.Warn("Failed to check CardDAV email availability", "email", normalizedEmail, "error", err)
 			continue
 		}
 
 		if !emailAvailable {
-			fmt.Printf("Warning: skipped duplicate CardDAV email %s for contact %s\n", normalizedEmail, contactID)
+			logger.Warn("Skipped duplicate CardDAV email", "email", normalizedEmail, "contact_id", contactID)
 			continue
 		}
 
@@ -661,7 +798,7 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 		`, contactID, normalizedEmail, emailType)
 		if err != nil {
 			// Log but continue - email might fail validation
-			fmt.Printf("Warning: failed to sync CardDAV email %s: %v\n", normalizedEmail, err)
+			logger.Warn("Failed to sync CardDAV email", "email", normalizedEmail, "error", err)
 			continue
 		}
 
@@ -721,6 +858,8 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 			phoneType = "home"
 		case "fax":
 			phoneType = "fax"
+		case "pager":
+			phoneType = "pager"
 		}
 
 		_, err = pool.Exec(ctx, `
@@ -729,7 +868,7 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 		`, contactID, normalizedPhone, phoneType)
 		if err != nil {
 			// Log but continue
-			fmt.Printf("Warning: failed to sync CardDAV phone %s: %v\n", normalizedPhone, err)
+			logger.Warn("Failed to sync CardDAV phone", "phone", normalizedPhone, "error", err)
 			continue
 		}
 
@@ -748,78 +887,167 @@ func SyncContactFromCardDAV(ctx context.Context, contactID string, cardDAVUUID s
 		}
 	}
 
-	return nil
+	return syncCardDAVAddresses(ctx, contactID, cardDAVContact.Addresses)
 }
 
-// SyncAllCardDAVContacts syncs all contacts that are linked to CardDAV
-func SyncAllCardDAVContacts(ctx context.Context) error {
-	if pool == nil {
-		return ErrDatabaseConnectionNotInitialized
+// syncCardDAVAddresses replaces the cached CardDAV addresses of a contact.
+// Local addresses matching a card address are adopted rather than duplicated,
+// and coordinates are carried over since they are not stored on the server.
+func syncCardDAVAddresses(ctx context.Context, contactID string, addresses []CardDAVAddress) error {
+	rows, err := pool.Query(ctx, `
+		SELECT id, street, locality, region, postal_code, country, po_box, extended,
+			source, latitude, longitude
+		FROM contact_addresses WHERE contact_id = $1
+	`, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to query existing contact addresses: %w", err)
 	}
 
-	// Check if CardDAV is configured
-	_, err := GetCardDAVConfig()
-	if err != nil {
-		// CardDAV not configured, skip sync silently
-		return nil
+	var existing []ContactAddress
+
+	for rows.Next() {
+		var addr ContactAddress
+		if err := rows.Scan(&addr.ID, &addr.Street, &addr.Locality, &addr.Region, &addr.PostalCode,
+			&addr.Country, &addr.POBox, &addr.Extended, &addr.Source, &addr.Latitude, &addr.Longitude); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan existing contact address: %w", err)
+		}
+
+		existing = append(existing, addr)
 	}
 
-	// Get all contacts with CardDAV UUIDs
-	query := `SELECT id, carddav_uuid FROM contacts WHERE carddav_uuid IS NOT NULL`
+	rows.Close()
 
-	rows, err := pool.Query(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to query contacts with CardDAV UUIDs: %w", err)
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating existing contact addresses: %w", err)
 	}
-	defer rows.Close()
 
-	var syncErrors []string
+	_, err = pool.Exec(ctx, `DELETE FROM contact_addresses WHERE contact_id = $1 AND source = 'carddav'`, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to delete existing contact addresses: %w", err)
+	}
 
-	syncCount := 0
+	var (
+		primaryID string
+		firstID   string
+	)
 
-	for rows.Next() {
-		var (
-			contactID   string
-			cardDAVUUID string
-		)
+	seenAddresses := make(map[string]bool)
 
-		err := rows.Scan(&contactID, &cardDAVUUID)
-		if err != nil {
-			syncErrors = append(syncErrors, fmt.Sprintf("failed to scan contact: %v", err))
+	for _, addr := range addresses {
+		key := addr.key()
+		if strings.Trim(key, "\x1f") == "" || seenAddresses[key] {
 			continue
 		}
 
-		// Sync each contact
-		err = SyncContactFromCardDAV(ctx, contactID, cardDAVUUID)
+		seenAddresses[key] = true
+
+		addressType := AddressOther
+
+		switch addr.Type {
+		case "home":
+			addressType = AddressHome
+		case "work":
+			addressType = AddressWork
+		}
+
+		lat, lon := addr.Latitude, addr.Longitude
+
+		var localID string
+
+		for _, prev := range existing {
+			if contactAddressKey(prev) != key {
+				continue
+			}
+
+			if lat == nil {
+				lat, lon = prev.Latitude, prev.Longitude
+			}
+
+			if prev.Source != "carddav" {
+				localID = prev.ID.String()
+			}
+		}
+
+		var addressID string
+
+		if localID != "" {
+			_, err = pool.Exec(ctx, `
+				UPDATE contact_addresses
+				SET street = $1, extended = $2, po_box = $3, locality = $4, region = $5,
+					postal_code = $6, country = $7, address_type = $8, latitude = $9,
+					longitude = $10, source = 'carddav'
+				WHERE id = $11
+			`, trimOptional(addr.Street), trimOptional(addr.Extended), trimOptional(addr.POBox),
+				trimOptional(addr.Locality), trimOptional(addr.Region), trimOptional(addr.PostalCode),
+				trimOptional(addr.Country), addressType, lat, lon, localID)
+			addressID = localID
+		} else {
+			err = pool.QueryRow(ctx, `
+				INSERT INTO contact_addresses (
+					contact_id, street, extended, po_box, locality, region, postal_code, country,
+					address_type, is_primary, latitude, longitude, source
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false, $10, $11, 'carddav')
+				RETURNING id
+			`, contactID, trimOptional(addr.Street), trimOptional(addr.Extended), trimOptional(addr.POBox),
+				trimOptional(addr.Locality), trimOptional(addr.Region), trimOptional(addr.PostalCode),
+				trimOptional(addr.Country), addressType, lat, lon).Scan(&addressID)
+		}
+
 		if err != nil {
-			syncErrors = append(syncErrors, fmt.Sprintf("failed to sync contact %s: %v", contactID, err))
+			// Log but continue
+			logger.Warn("Failed to sync CardDAV address", "contact_id", contactID, "error", err)
 			continue
 		}
 
-		syncCount++
-	}
+		if firstID == "" {
+			firstID = addressID
+		}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating contacts: %w", err)
+		if addr.Preferred && primaryID == "" {
+			primaryID = addressID
+		}
 	}
 
-	if len(syncErrors) > 0 {
-		fmt.Printf("CardDAV sync completed with %d contacts synced, %d errors:\n", syncCount, len(syncErrors))
+	if primaryID == "" {
+		primaryID = firstID
+	}
 
-		for _, errMsg := range syncErrors {
-			fmt.Printf("  - %s\n", errMsg)
+	if primaryID != "" {
+		_, err = pool.Exec(ctx, `
+			UPDATE contact_addresses
+			SET is_primary = (id = $2)
+			WHERE contact_id = $1
+		`, contactID, primaryID)
+		if err != nil {
+			return fmt.Errorf("failed to set primary address: %w", err)
 		}
-	} else if syncCount > 0 {
-		fmt.Printf("CardDAV sync completed: %d contacts synced\n", syncCount)
 	}
 
 	return nil
 }
 
-// CreateCardDAVContact creates a new contact on the CardDAV server
+// SyncAllCardDAVContacts re-applies every card in every source to its
+// linked contacts, ignoring stored sync tokens and ETags. It does nothing
+// when no source is configured.
+func SyncAllCardDAVContacts(ctx context.Context) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	_, err := SyncCardDAV(ctx, CardDAVSyncManual, true)
+	if errors.Is(err, ErrCardDAVNoSources) {
+		return nil
+	}
+
+	return err
+}
+
+// CreateCardDAVContact creates a new contact in a CardDAV source
 // Returns the UUID of the created contact
-func CreateCardDAVContact(ctx context.Context, contact *ContactDetail) (string, error) {
-	config, err := GetCardDAVConfig()
+func CreateCardDAVContact(ctx context.Context, sourceID string, contact *ContactDetail) (string, error) {
+	config, err := GetCardDAVConfig(ctx, sourceID)
 	if err != nil {
 		return "", err
 	}
@@ -910,6 +1138,13 @@ func CreateCardDAVContact(ctx context.Context, contact *ContactDetail) (string,
 		}
 	}
 
+	// Add addresses (only local ones, not already from carddav)
+	for _, addr := range contact.Addresses {
+		if addr.Source != "carddav" {
+			card.AddAddress(newVCardAddress(addr, false))
+		}
+	}
+
 	// Convert to vCard 4.0
 	vcard.ToV4(card)
 
@@ -956,13 +1191,19 @@ func findCardDAVContactPath(ctx context.Context, client *carddav.Client, uuid st
 
 // UpdateCardDAVContact updates an existing contact on the CardDAV server
 // This fetches the existing vCard first, updates only the fields we manage,
-// and preserves all other fields (notes, photo, birthday, addresses, etc.)
+// and preserves all other fields (notes, photo, birthday, etc.)
 func UpdateCardDAVContact(ctx context.Context, contact *ContactDetail) error {
 	if contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" {
 		return ErrContactNotLinkedToCardDAV
 	}
 
-	config, err := GetCardDAVConfig()
+	if contact.CardDAVSourceID == nil {
+		return ErrCardDAVSourceNotFound
+	}
+
+	sourceID := *contact.CardDAVSourceID
+
+	config, err := GetCardDAVConfig(ctx, sourceID)
 	if err != nil {
 		return err
 	}
@@ -991,9 +1232,131 @@ func UpdateCardDAVContact(ctx context.Context, contact *ContactDetail) error {
 
 	fmt.Printf("[DEBUG] Successfully fetched CardDAV contact\n")
 
+	// Refuse to overwrite a card that changed since it was last seen
+	inConflict, err := checkCardDAVConflict(ctx, contact, existingObj)
+	if err != nil {
+		return err
+	}
+
+	if inConflict {
+		return ErrCardDAVConflict
+	}
+
 	card := existingObj.Card
+	setCardDAVCardFields(card, contact, nil)
+
+	// Update the contact on the server using PUT
+	updatedObj, err := client.PutAddressObject(ctx, path, card)
+	if err != nil {
+		return fmt.Errorf("failed to update CardDAV contact: %w", err)
+	}
+
+	advanceCardDAVETag(ctx, sourceID, existingUUID, path, existingObj.ETag, updatedObj.ETag)
+
+	fmt.Printf("UpdateCardDAVContact: Successfully updated CardDAV contact %s\n", existingUUID)
+
+	return nil
+}
+
+// setCardDAVCardFields writes the fields Groundwave manages from a contact
+// into a card, preserving everything else. A nil fields set writes all of
+// them; otherwise only the listed conflict fields are written.
+func setCardDAVCardFields(card vcard.Card, contact *ContactDetail, fields map[string]bool) {
+	include := func(field string) bool {
+		return fields == nil || fields[field]
+	}
+
+	if include(CardDAVFieldName) {
+		setCardDAVCardName(card, contact)
+	}
+
+	if include(CardDAVFieldOrganization) {
+		// Update organization - remove existing and add if present
+		delete(card, vcard.FieldOrganization)
+
+		if contact.Organization != nil && *contact.Organization != "" {
+			card.SetValue(vcard.FieldOrganization, *contact.Organization)
+		}
+	}
+
+	if include(CardDAVFieldTitle) {
+		// Update title - remove existing and add if present
+		delete(card, vcard.FieldTitle)
+
+		if contact.Title != nil && *contact.Title != "" {
+			card.SetValue(vcard.FieldTitle, *contact.Title)
+		}
+	}
+
+	if include(CardDAVFieldEmails) {
+		// Update emails - remove existing and add all from local
+		delete(card, vcard.FieldEmail)
+
+		for _, email := range contact.Emails {
+			emailType := "home"
+			if email.EmailType == EmailWork {
+				emailType = "work"
+			}
+
+			params := vcard.Params{vcard.ParamType: []string{emailType}}
+			if email.IsPrimary {
+				params.Set(vcard.ParamPreferred, "1")
+			}
+
+			card.Add(vcard.FieldEmail, &vcard.Field{
+				Value:  email.Email,
+				Params: params,
+			})
+		}
+	}
 
-	// Update name fields, preserving components we don't manage (prefix, suffix, middle name)
+	if include(CardDAVFieldPhones) {
+		// Update phones - remove existing and add all from local
+		delete(card, vcard.FieldTelephone)
+
+		for _, phone := range contact.Phones {
+			phoneType := "cell"
+
+			switch phone.PhoneType {
+			case PhoneCell:
+				phoneType = "cell"
+			case PhoneHome:
+				phoneType = "home"
+			case PhoneWork:
+				phoneType = "work"
+			case PhoneFax:
+				phoneType = "fax"
+			case PhonePager:
+				phoneType = "pager"
+			case PhoneOther:
+				phoneType = "other"
+			}
+
+			params := vcard.Params{vcard.ParamType: []string{phoneType}}
+			if phone.IsPrimary {
+				params.Set(vcard.ParamPreferred, "1")
+			}
+
+			card.Add(vcard.FieldTelephone, &vcard.Field{
+				Value:  phone.Phone,
+				Params: params,
+			})
+		}
+	}
+
+	if include(CardDAVFieldAddresses) {
+		// Update addresses - remove existing and add all from local
+		delete(card, vcard.FieldAddress)
+
+		for _, addr := range contact.Addresses {
+			card.AddAddress(newVCardAddress(addr, true))
+		}
+	}
+}
+
+// setCardDAVCardName writes FN and N, preserving the name components we
+// don't manage (prefix, suffix, middle name)
+func setCardDAVCardName(card vcard.Card, contact *ContactDetail) {
 	nameGiven := ""
 	if contact.NameGiven != nil {
 		nameGiven = *contact.NameGiven
@@ -1026,80 +1389,69 @@ func UpdateCardDAVContact(ctx context.Context, contact *ContactDetail) error {
 		HonorificPrefix: honorificPrefix,
 		HonorificSuffix: honorificSuffix,
 	})
+}
 
-	// Update organization - remove existing and add if present
-	delete(card, vcard.FieldOrganization)
+// setVCardPhoto replaces the PHOTO of a card with an embedded JPEG, or
+// removes it when photo is empty. vCard 4.0 cards use a data URI and older
+// cards use inline base64.
+func setVCardPhoto(card vcard.Card, photo []byte) {
+	delete(card, vcard.FieldPhoto)
 
-	if contact.Organization != nil && *contact.Organization != "" {
-		card.SetValue(vcard.FieldOrganization, *contact.Organization)
+	if len(photo) == 0 {
+		return
 	}
 
-	// Update title - remove existing and add if present
-	delete(card, vcard.FieldTitle)
+	encoded := base64.StdEncoding.EncodeToString(photo)
 
-	if contact.Title != nil && *contact.Title != "" {
-		card.SetValue(vcard.FieldTitle, *contact.Title)
+	if card.Value(vcard.FieldVersion) == "4.0" {
+		card.Add(vcard.FieldPhoto, &vcard.Field{Value: "data:image/jpeg;base64," + encoded})
+		return
 	}
 
-	// Update emails - remove existing and add all from local
-	delete(card, vcard.FieldEmail)
-
-	for _, email := range contact.Emails {
-		emailType := "home"
-		if email.EmailType == EmailWork {
-			emailType = "work"
-		}
-
-		params := vcard.Params{vcard.ParamType: []string{emailType}}
-		if email.IsPrimary {
-			params.Set(vcard.ParamPreferred, "1")
-		}
+	card.Add(vcard.FieldPhoto, &vcard.Field{
+		Value: encoded,
+		Params: vcard.Params{
+			"ENCODING":      []string{"b"},
+			vcard.ParamType: []string{"JPEG"},
+		},
+	})
+}
 
-		card.Add(vcard.FieldEmail, &vcard.Field{
-			Value:  email.Email,
-			Params: params,
-		})
+// UpdateCardDAVContactPhoto pushes a JPEG photo into a linked CardDAV card,
+// leaving every other field untouched. An empty photo removes it.
+func UpdateCardDAVContactPhoto(ctx context.Context, sourceID, cardDAVUUID string, photo []byte) error {
+	if strings.TrimSpace(cardDAVUUID) == "" {
+		return ErrContactNotLinkedToCardDAV
 	}
 
-	// Update phones - remove existing and add all from local
-	delete(card, vcard.FieldTelephone)
-
-	for _, phone := range contact.Phones {
-		phoneType := "cell"
+	config, err := GetCardDAVConfig(ctx, sourceID)
+	if err != nil {
+		return err
+	}
 
-		switch phone.PhoneType {
-		case PhoneCell:
-			phoneType = "cell"
-		case PhoneHome:
-			phoneType = "home"
-		case PhoneWork:
-			phoneType = "work"
-		case PhoneFax:
-			phoneType = "fax"
-		case PhonePager:
-			phoneType = "pager"
-		case PhoneOther:
-			phoneType = "other"
-		}
+	client, err := newCardDAVClient(config)
+	if err != nil {
+		return err
+	}
 
-		params := vcard.Params{vcard.ParamType: []string{phoneType}}
-		if phone.IsPrimary {
-			params.Set(vcard.ParamPreferred, "1")
-		}
+	path, err := findCardDAVContactPath(ctx, client, cardDAVUUID)
+	if err != nil {
+		return fmt.Errorf("failed to find CardDAV contact path: %w", err)
+	}
 
-		card.Add(vcard.FieldTelephone, &vcard.Field{
-			Value:  phone.Phone,
-			Params: params,
-		})
+	existingObj, err := client.GetAddressObject(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch existing CardDAV contact: %w", err)
 	}
 
-	// Update the contact on the server using PUT
-	_, err = client.PutAddressObject(ctx, path, card)
+	setVCardPhoto(existingObj.Card, photo)
+
+	updatedObj, err := client.PutAddressObject(ctx, path, existingObj.Card)
 	if err != nil {
-		return fmt.Errorf("failed to update CardDAV contact: %w", err)
+		return fmt.Errorf("failed to update CardDAV contact photo: %w", err)
 	}
 
-	fmt.Printf("UpdateCardDAVContact: Successfully updated CardDAV contact %s\n", existingUUID)
+	advanceCardDAVETag(ctx, sourceID, cardDAVUUID, path, existingObj.ETag, updatedObj.ETag)
 
 	return nil
 }
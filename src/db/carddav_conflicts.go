@@ -0,0 +1,502 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-webdav/carddav"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fields Groundwave writes to CardDAV, which is where conflicts can happen
+const (
+	CardDAVFieldName         = "name"
+	CardDAVFieldOrganization = "organization"
+	CardDAVFieldTitle        = "title"
+	CardDAVFieldEmails       = "emails"
+	CardDAVFieldPhones       = "phones"
+	CardDAVFieldAddresses    = "addresses"
+)
+
+// CardDAVConflictFields lists the conflict fields in display order
+var CardDAVConflictFields = []string{
+	CardDAVFieldName,
+	CardDAVFieldOrganization,
+	CardDAVFieldTitle,
+	CardDAVFieldEmails,
+	CardDAVFieldPhones,
+	CardDAVFieldAddresses,
+}
+
+var cardDAVConflictFieldLabels = map[string]string{
+	CardDAVFieldName:         "Name",
+	CardDAVFieldOrganization: "Organization",
+	CardDAVFieldTitle:        "Title",
+	CardDAVFieldEmails:       "Emails",
+	CardDAVFieldPhones:       "Phones",
+	CardDAVFieldAddresses:    "Addresses",
+}
+
+// CardDAVConflictField is one field that differs between the contact and
+// its card. Lists hold one value per line.
+type CardDAVConflictField struct {
+	Field  string `json:"field"`
+	Label  string `json:"label"`
+	Local  string `json:"local"`
+	Server string `json:"server"`
+}
+
+// CardDAVConflict is an unresolved divergence between a linked contact and
+// its card on the server
+type CardDAVConflict struct {
+	ID          uuid.UUID              `db:"id"`
+	ContactID   uuid.UUID              `db:"contact_id"`
+	ContactName string                 `db:"name_display"`
+	CardDAVUUID string                 `db:"carddav_uuid"`
+	ServerETag  string                 `db:"server_etag"`
+	Fields      []CardDAVConflictField `db:"fields"`
+	DetectedAt  time.Time              `db:"detected_at"`
+	UpdatedAt   time.Time              `db:"updated_at"`
+}
+
+// diffCardDAVContact compares the fields Groundwave manages between a
+// contact and a parsed card
+func diffCardDAVContact(local *ContactDetail, server *CardDAVContact) []CardDAVConflictField {
+	serverGiven := server.GivenName
+	if serverGiven == "" {
+		serverGiven = server.DisplayName
+	}
+
+	type comparison struct {
+		local, server       string
+		localKey, serverKey string
+	}
+
+	localName := joinNonEmpty(" ", pointerString(local.NameGiven), pointerString(local.NameFamily))
+	serverName := joinNonEmpty(" ", serverGiven, server.FamilyName)
+
+	localEmails, localEmailKeys := make([]string, 0, len(local.Emails)), make([]string, 0, len(local.Emails))
+	for _, email := range local.Emails {
+		localEmails = append(localEmails, email.Email)
+		localEmailKeys = append(localEmailKeys, normalizeCardDAVEmail(email.Email))
+	}
+
+	serverEmails, serverEmailKeys := make([]string, 0, len(server.Emails)), make([]string, 0, len(server.Emails))
+	for _, email := range server.Emails {
+		serverEmails = append(serverEmails, email.Email)
+		serverEmailKeys = append(serverEmailKeys, normalizeCardDAVEmail(email.Email))
+	}
+
+	localPhones, localPhoneKeys := make([]string, 0, len(local.Phones)), make([]string, 0, len(local.Phones))
+	for _, phone := range local.Phones {
+		localPhones = append(localPhones, phone.Phone)
+		localPhoneKeys = append(localPhoneKeys, normalizePhoneDigits(phone.Phone))
+	}
+
+	serverPhones, serverPhoneKeys := make([]string, 0, len(server.Phones)), make([]string, 0, len(server.Phones))
+	for _, phone := range server.Phones {
+		serverPhones = append(serverPhones, phone.Phone)
+		serverPhoneKeys = append(serverPhoneKeys, normalizePhoneDigits(phone.Phone))
+	}
+
+	localAddresses, localAddressKeys := make([]string, 0, len(local.Addresses)), make([]string, 0, len(local.Addresses))
+	for _, addr := range local.Addresses {
+		localAddresses = append(localAddresses, joinNonEmpty(", ", pointerString(addr.POBox), pointerString(addr.Extended),
+			pointerString(addr.Street), pointerString(addr.Locality), pointerString(addr.Region),
+			pointerString(addr.PostalCode), pointerString(addr.Country)))
+		localAddressKeys = append(localAddressKeys, contactAddressKey(addr))
+	}
+
+	serverAddresses, serverAddressKeys := make([]string, 0, len(server.Addresses)), make([]string, 0, len(server.Addresses))
+	for _, addr := range server.Addresses {
+		serverAddresses = append(serverAddresses, joinNonEmpty(", ", addr.POBox, addr.Extended, addr.Street,
+			addr.Locality, addr.Region, addr.PostalCode, addr.Country))
+		serverAddressKeys = append(serverAddressKeys, addr.key())
+	}
+
+	comparisons := map[string]comparison{
+		CardDAVFieldName: {
+			local: localName, server: serverName,
+			localKey:  strings.ToLower(strings.TrimSpace(pointerString(local.NameGiven))) + "\x1f" + strings.ToLower(strings.TrimSpace(pointerString(local.NameFamily))),
+			serverKey: strings.ToLower(strings.TrimSpace(serverGiven)) + "\x1f" + strings.ToLower(strings.TrimSpace(server.FamilyName)),
+		},
+		CardDAVFieldOrganization: {
+			local: pointerString(local.Organization), server: server.Organization,
+			localKey: strings.TrimSpace(pointerString(local.Organization)), serverKey: strings.TrimSpace(server.Organization),
+		},
+		CardDAVFieldTitle: {
+			local: pointerString(local.Title), server: server.Title,
+			localKey: strings.TrimSpace(pointerString(local.Title)), serverKey: strings.TrimSpace(server.Title),
+		},
+		CardDAVFieldEmails: {
+			local: strings.Join(localEmails, "\n"), server: strings.Join(serverEmails, "\n"),
+			localKey: cardDAVSetKey(localEmailKeys), serverKey: cardDAVSetKey(serverEmailKeys),
+		},
+		CardDAVFieldPhones: {
+			local: strings.Join(localPhones, "\n"), server: strings.Join(serverPhones, "\n"),
+			localKey: cardDAVSetKey(localPhoneKeys), serverKey: cardDAVSetKey(serverPhoneKeys),
+		},
+		CardDAVFieldAddresses: {
+			local: strings.Join(localAddresses, "\n"), server: strings.Join(serverAddresses, "\n"),
+			localKey: cardDAVSetKey(localAddressKeys), serverKey: cardDAVSetKey(serverAddressKeys),
+		},
+	}
+
+	var fields []CardDAVConflictField
+
+	for _, field := range CardDAVConflictFields {
+		cmp := comparisons[field]
+		if cmp.localKey == cmp.serverKey {
+			continue
+		}
+
+		fields = append(fields, CardDAVConflictField{
+			Field:  field,
+			Label:  cardDAVConflictFieldLabels[field],
+			Local:  cmp.local,
+			Server: cmp.server,
+		})
+	}
+
+	return fields
+}
+
+// cardDAVSetKey compares lists ignoring order, duplicates and blanks
+func cardDAVSetKey(values []string) string {
+	keys := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if strings.Trim(value, "\x1f ") != "" {
+			keys = append(keys, value)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return strings.Join(slices.Compact(keys), "\n")
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+
+	return strings.Join(parts, sep)
+}
+
+// checkCardDAVConflict decides whether pushing a contact would overwrite
+// server changes Groundwave has not seen. The card on the server diverged
+// when its ETag no longer matches the last one seen, or a conflict is
+// already open. Diverged cards that agree on every managed field are not a
+// conflict.
+func checkCardDAVConflict(ctx context.Context, contact *ContactDetail, obj *carddav.AddressObject) (bool, error) {
+	contactID := contact.ID.String()
+	cardDAVUUID := pointerString(contact.CardDAVUUID)
+
+	open, err := HasOpenCardDAVConflict(ctx, contactID)
+	if err != nil {
+		return false, err
+	}
+
+	lastSeen, err := cardDAVLastSeenETag(ctx, pointerString(contact.CardDAVSourceID), cardDAVUUID)
+	if err != nil {
+		return false, err
+	}
+
+	if !open && (lastSeen == "" || obj.ETag == "" || lastSeen == obj.ETag) {
+		return false, nil
+	}
+
+	server := parseVCard(obj.Card)
+
+	fields := diffCardDAVContact(contact, &server)
+	if len(fields) == 0 {
+		if open {
+			return false, closeCardDAVConflict(ctx, contactID, cardDAVConflictAgreed)
+		}
+
+		return false, nil
+	}
+
+	if err := recordCardDAVConflict(ctx, contactID, cardDAVUUID, obj.ETag, fields); err != nil {
+		return false, err
+	}
+
+	logger.Warn("CardDAV card changed on both sides, recorded conflict", "contact_id", contactID, "fields", len(fields))
+
+	return true, nil
+}
+
+// holdConflictedCardDAVContact is used by the sync worker for a changed
+// card. A contact with an open conflict keeps its local details and only the
+// server side of the conflict is refreshed. It reports whether the contact
+// is still in conflict.
+func holdConflictedCardDAVContact(ctx context.Context, contactID, etag string, server *CardDAVContact) (bool, error) {
+	open, err := HasOpenCardDAVConflict(ctx, contactID)
+	if err != nil || !open {
+		return false, err
+	}
+
+	contact, err := getContactDetail(ctx, contactID, false)
+	if err != nil {
+		return false, err
+	}
+
+	fields := diffCardDAVContact(contact, server)
+	if len(fields) == 0 {
+		return false, closeCardDAVConflict(ctx, contactID, cardDAVConflictAgreed)
+	}
+
+	if err := recordCardDAVConflict(ctx, contactID, pointerString(contact.CardDAVUUID), etag, fields); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// cardDAVLastSeenETag returns the ETag of the card as last synced or
+// pushed, or an empty string when unknown
+func cardDAVLastSeenETag(ctx context.Context, sourceID, cardDAVUUID string) (string, error) {
+	var etag string
+
+	err := pool.QueryRow(ctx, `
+		SELECT COALESCE(etag, '') FROM carddav_cards WHERE source_id = $1 AND lower(uid) = lower($2)
+	`, sourceID, cardDAVUUID).Scan(&etag)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("failed to load last seen CardDAV ETag: %w", err)
+	}
+
+	return etag, nil
+}
+
+// advanceCardDAVETag records the ETag after a push, but only when the card
+// was unchanged since last seen. Otherwise the sync worker still has server
+// changes to pick up and must not skip them.
+func advanceCardDAVETag(ctx context.Context, sourceID, cardDAVUUID, path, previousETag, newETag string) {
+	if previousETag == "" {
+		return
+	}
+
+	_, err := pool.Exec(ctx, `
+		UPDATE carddav_cards SET path = $3, etag = NULLIF($4, ''), synced_at = now()
+		WHERE source_id = $1 AND lower(uid) = lower($2) AND etag = $5
+	`, sourceID, cardDAVUUID, path, newETag, previousETag)
+	if err != nil {
+		logger.Warn("Failed to record CardDAV ETag after push", "uid", cardDAVUUID, "error", err)
+	}
+}
+
+// recordCardDAVConflict opens a conflict for a contact, or refreshes the
+// open one with the latest server state
+func recordCardDAVConflict(ctx context.Context, contactID, cardDAVUUID, etag string, fields []CardDAVConflictField) error {
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode conflict fields: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO contact_carddav_conflicts (contact_id, carddav_uuid, server_etag, fields)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		ON CONFLICT (contact_id) WHERE resolved_at IS NULL DO UPDATE
+		SET carddav_uuid = EXCLUDED.carddav_uuid,
+			server_etag = EXCLUDED.server_etag,
+			fields = EXCLUDED.fields
+	`, contactID, cardDAVUUID, etag, fieldsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record CardDAV conflict: %w", err)
+	}
+
+	return nil
+}
+
+// Resolutions stored on a closed conflict
+const (
+	cardDAVConflictKeptLocal  = "local"
+	cardDAVConflictKeptServer = "server"
+	cardDAVConflictMerged     = "merged"
+	cardDAVConflictAgreed     = "agreed"
+)
+
+func closeCardDAVConflict(ctx context.Context, contactID, resolution string) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE contact_carddav_conflicts SET resolved_at = now(), resolution = $2
+		WHERE contact_id = $1 AND resolved_at IS NULL
+	`, contactID, resolution)
+	if err != nil {
+		return fmt.Errorf("failed to close CardDAV conflict: %w", err)
+	}
+
+	return nil
+}
+
+// HasOpenCardDAVConflict reports whether a contact has an unresolved conflict
+func HasOpenCardDAVConflict(ctx context.Context, contactID string) (bool, error) {
+	if pool == nil {
+		return false, ErrDatabaseConnectionNotInitialized
+	}
+
+	var open bool
+
+	err := pool.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM contact_carddav_conflicts
+			WHERE contact_id = $1 AND resolved_at IS NULL
+		)
+	`, contactID).Scan(&open)
+	if err != nil {
+		return false, fmt.Errorf("failed to check CardDAV conflict: %w", err)
+	}
+
+	return open, nil
+}
+
+// GetOpenCardDAVConflict returns the unresolved conflict of a contact
+func GetOpenCardDAVConflict(ctx context.Context, contactID string) (*CardDAVConflict, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	var (
+		conflict   CardDAVConflict
+		fieldsJSON []byte
+	)
+
+	err := pool.QueryRow(ctx, `
+		SELECT cf.id, cf.contact_id, c.name_display, cf.carddav_uuid, COALESCE(cf.server_etag, ''),
+			cf.fields, cf.detected_at, cf.updated_at
+		FROM contact_carddav_conflicts cf
+		JOIN contacts c ON c.id = cf.contact_id
+		WHERE cf.contact_id = $1 AND cf.resolved_at IS NULL
+	`, contactID).Scan(&conflict.ID, &conflict.ContactID, &conflict.ContactName, &conflict.CardDAVUUID,
+		&conflict.ServerETag, &fieldsJSON, &conflict.DetectedAt, &conflict.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCardDAVConflictNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CardDAV conflict: %w", err)
+	}
+
+	if err := json.Unmarshal(fieldsJSON, &conflict.Fields); err != nil {
+		return nil, fmt.Errorf("failed to decode conflict fields: %w", err)
+	}
+
+	return &conflict, nil
+}
+
+// ResolveCardDAVConflict settles an open conflict. Fields listed in
+// keepServer take the card's value and every other field keeps the local
+// value; the merged card is written to the server and copied onto the
+// contact. If the card changed again since the conflict was recorded, the
+// conflict is refreshed and ErrCardDAVConflictChanged is returned.
+func ResolveCardDAVConflict(ctx context.Context, contactID string, keepServer []string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	conflict, err := GetOpenCardDAVConflict(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	contact, err := getContactDetail(ctx, contactID, false)
+	if err != nil {
+		return err
+	}
+
+	if pointerString(contact.CardDAVUUID) == "" || contact.CardDAVSourceID == nil {
+		return ErrContactNotLinkedToCardDAV
+	}
+
+	cardDAVUUID := *contact.CardDAVUUID
+	sourceID := *contact.CardDAVSourceID
+
+	config, err := GetCardDAVConfig(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+
+	client, err := newCardDAVClient(config)
+	if err != nil {
+		return err
+	}
+
+	path, err := findCardDAVContactPath(ctx, client, cardDAVUUID)
+	if err != nil {
+		return fmt.Errorf("failed to find CardDAV contact path: %w", err)
+	}
+
+	obj, err := client.GetAddressObject(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to fetch existing CardDAV contact: %w", err)
+	}
+
+	if obj.ETag != "" && conflict.ServerETag != "" && obj.ETag != conflict.ServerETag {
+		server := parseVCard(obj.Card)
+		if err := recordCardDAVConflict(ctx, contactID, cardDAVUUID, obj.ETag, diffCardDAVContact(contact, &server)); err != nil {
+			return err
+		}
+
+		return ErrCardDAVConflictChanged
+	}
+
+	keepLocal := make(map[string]bool, len(CardDAVConflictFields))
+	for _, field := range CardDAVConflictFields {
+		keepLocal[field] = !slices.Contains(keepServer, field)
+	}
+
+	setCardDAVCardFields(obj.Card, contact, keepLocal)
+
+	updatedObj, err := client.PutAddressObject(ctx, path, obj.Card)
+	if err != nil {
+		return fmt.Errorf("failed to update CardDAV contact: %w", err)
+	}
+
+	merged := parseVCard(obj.Card)
+	if err := applyCardDAVContact(ctx, contactID, &merged); err != nil {
+		return err
+	}
+
+	// The contact now matches the card, so its new ETag has been seen
+	if err := upsertCardDAVCard(ctx, sourceID, cardDAVCardState{UID: cardDAVUUID, Path: path, ETag: updatedObj.ETag}); err != nil {
+		return err
+	}
+
+	resolution := cardDAVConflictMerged
+
+	switch {
+	case len(keepServer) == 0:
+		resolution = cardDAVConflictKeptLocal
+	case !slices.ContainsFunc(conflict.Fields, func(f CardDAVConflictField) bool { return !slices.Contains(keepServer, f.Field) }):
+		resolution = cardDAVConflictKeptServer
+	}
+
+	return closeCardDAVConflict(ctx, contactID, resolution)
+}
+
+// clearCardDAVConflicts drops the open conflict of a contact that is no
+// longer linked to a card
+func clearCardDAVConflicts(ctx context.Context, contactID string) error {
+	_, err := pool.Exec(ctx, `
+		DELETE FROM contact_carddav_conflicts WHERE contact_id = $1 AND resolved_at IS NULL
+	`, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to clear CardDAV conflicts: %w", err)
+	}
+
+	return nil
+}
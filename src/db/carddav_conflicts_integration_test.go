@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emersion/go-vcard"
+)
+
+func TestCardDAVConflictDetectionAndResolution(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	server := newCardDAVTestServer(t)
+	defer server.close()
+
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldUID, "conflict-1")
+	card.SetValue(vcard.FieldFormattedName, "Server Name")
+	card.AddName(&vcard.Name{GivenName: "Server", FamilyName: "Name"})
+	card.SetValue(vcard.FieldOrganization, "Server Org")
+	server.cards["conflict-1.vcf"] = card
+	server.etags["conflict-1.vcf"] = `"v2"`
+
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
+
+	carddavID := "conflict-1"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
+
+	// The card was last seen at v1, then changed on the server
+	if err := upsertCardDAVCard(ctx, sourceID, cardDAVCardState{UID: carddavID, Path: "/addressbook/conflict-1.vcf", ETag: `"v1"`}); err != nil {
+		t.Fatalf("upsertCardDAVCard failed: %v", err)
+	}
+
+	contact, err := getContactDetail(ctx, contactID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if err := UpdateCardDAVContact(ctx, contact); !errors.Is(err, ErrCardDAVConflict) {
+		t.Fatalf("expected ErrCardDAVConflict, got %v", err)
+	}
+
+	if got := server.cards["conflict-1.vcf"].Name().GivenName; got != "Server" {
+		t.Fatalf("expected the server card to be left alone, got %q", got)
+	}
+
+	conflict, err := GetOpenCardDAVConflict(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetOpenCardDAVConflict failed: %v", err)
+	}
+
+	if conflict.ServerETag != `"v2"` || conflict.ContactName != "Local" || len(conflict.Fields) != 2 {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+
+	if conflict.Fields[0].Field != CardDAVFieldName || conflict.Fields[0].Local != "Local" || conflict.Fields[0].Server != "Server Name" {
+		t.Fatalf("unexpected name diff %+v", conflict.Fields[0])
+	}
+
+	// Pulling from the server keeps the local edits while the conflict is open
+	if err := SyncContactFromCardDAV(ctx, contactID, carddavID); err != nil {
+		t.Fatalf("SyncContactFromCardDAV failed: %v", err)
+	}
+
+	contact, err = getContactDetail(ctx, contactID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if pointerString(contact.NameGiven) != "Local" {
+		t.Fatalf("expected local name to be kept, got %q", pointerString(contact.NameGiven))
+	}
+
+	if err := ResolveCardDAVConflict(ctx, contactID, []string{CardDAVFieldName}); err != nil {
+		t.Fatalf("ResolveCardDAVConflict failed: %v", err)
+	}
+
+	contact, err = getContactDetail(ctx, contactID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if pointerString(contact.NameGiven) != "Server" || contact.Organization != nil {
+		t.Fatalf("expected server name and local organization, got %q %v", pointerString(contact.NameGiven), contact.Organization)
+	}
+
+	if got := server.cards["conflict-1.vcf"].Value(vcard.FieldOrganization); got != "" {
+		t.Fatalf("expected the local organization to be pushed, got %q", got)
+	}
+
+	if _, err := GetOpenCardDAVConflict(ctx, contactID); !errors.Is(err, ErrCardDAVConflictNotFound) {
+		t.Fatalf("expected conflict to be closed, got %v", err)
+	}
+
+	var resolution string
+	if err := pool.QueryRow(ctx, `SELECT resolution FROM contact_carddav_conflicts WHERE contact_id = $1`, contactID).Scan(&resolution); err != nil {
+		t.Fatalf("failed to load resolution: %v", err)
+	}
+
+	if resolution != cardDAVConflictMerged {
+		t.Fatalf("expected merged resolution, got %q", resolution)
+	}
+}
+
+func TestCardDAVConflictNotRecordedWhenUnchanged(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	server := newCardDAVTestServer(t)
+	defer server.close()
+
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldUID, "conflict-2")
+	card.SetValue(vcard.FieldFormattedName, "Same")
+	card.AddName(&vcard.Name{GivenName: "Same"})
+	server.cards["conflict-2.vcf"] = card
+	server.etags["conflict-2.vcf"] = `"v1"`
+
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
+
+	carddavID := "conflict-2"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Edited", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
+
+	if err := upsertCardDAVCard(ctx, sourceID, cardDAVCardState{UID: carddavID, Path: "/addressbook/conflict-2.vcf", ETag: `"v1"`}); err != nil {
+		t.Fatalf("upsertCardDAVCard failed: %v", err)
+	}
+
+	contact, err := getContactDetail(ctx, contactID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if err := UpdateCardDAVContact(ctx, contact); err != nil {
+		t.Fatalf("UpdateCardDAVContact failed: %v", err)
+	}
+
+	if got := server.cards["conflict-2.vcf"].Name().GivenName; got != "Edited" {
+		t.Fatalf("expected local edit to be pushed, got %q", got)
+	}
+
+	open, err := HasOpenCardDAVConflict(ctx, contactID)
+	if err != nil || open {
+		t.Fatalf("expected no conflict, got %v %v", open, err)
+	}
+}
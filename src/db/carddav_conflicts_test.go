@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+)
+
+func TestDiffCardDAVContact(t *testing.T) {
+	t.Parallel()
+
+	given := "Sara"
+	org := " Acme "
+
+	local := &ContactDetail{
+		Contact: Contact{NameGiven: &given, Organization: &org},
+		Emails: []ContactEmail{
+			{Email: "sara@example.com"},
+			{Email: "Sara.Work@Example.com"},
+		},
+		Phones: []ContactPhone{{Phone: "+971 50 123 4567"}},
+	}
+
+	// Same emails in another order and case, same phone with other formatting
+	server := &CardDAVContact{
+		DisplayName:  "Sara",
+		Organization: "Acme",
+		Title:        "Engineer",
+		Emails: []CardDAVEmail{
+			{Email: "sara.work@example.com"},
+			{Email: "sara@example.com"},
+		},
+		Phones: []CardDAVPhone{{Phone: "+971501234567"}},
+	}
+
+	fields := diffCardDAVContact(local, server)
+	if len(fields) != 1 {
+		t.Fatalf("expected only the title to differ, got %+v", fields)
+	}
+
+	if fields[0] != (CardDAVConflictField{Field: CardDAVFieldTitle, Label: "Title", Local: "", Server: "Engineer"}) {
+		t.Fatalf("unexpected title diff %+v", fields[0])
+	}
+
+	server.Title = ""
+	server.Phones = append(server.Phones, CardDAVPhone{Phone: "+1 555 0100"})
+
+	fields = diffCardDAVContact(local, server)
+	if len(fields) != 1 || fields[0].Field != CardDAVFieldPhones {
+		t.Fatalf("expected the phones to differ, got %+v", fields)
+	}
+
+	if fields[0].Local != "+971 50 123 4567" || fields[0].Server != "+971501234567\n+1 555 0100" {
+		t.Fatalf("unexpected phone values %+v", fields[0])
+	}
+}
+
+func TestCardDAVSetKey(t *testing.T) {
+	t.Parallel()
+
+	if got := cardDAVSetKey([]string{"b", "", "a", "b", "\x1f"}); got != "a\nb" {
+		t.Fatalf("unexpected set key %q", got)
+	}
+
+	if cardDAVSetKey(nil) != cardDAVSetKey([]string{" "}) {
+		t.Fatal("expected blank values to be ignored")
+	}
+}
@@ -5,6 +5,7 @@ package db
 
 import (
 	"testing"
+	"time"
 
 	"github.com/emersion/go-vcard"
 )
@@ -39,6 +40,30 @@ func TestParseDateStringInvalid(t *testing.T) {
 	}
 }
 
+func TestParseDateStringWithoutYear(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"--0315", "--03-15"} {
+		got, err := parseDateString(input)
+		if err != nil {
+			t.Fatalf("parseDateString(%q) failed: %v", input, err)
+		}
+
+		if got.Year() != ContactDateYearUnknown || got.Month() != time.March || got.Day() != 15 {
+			t.Fatalf("parseDateString(%q) = %v, want 15 March with unknown year", input, got)
+		}
+	}
+
+	leapDay, err := parseDateString("--0229")
+	if err != nil {
+		t.Fatalf("parseDateString(--0229) failed: %v", err)
+	}
+
+	if leapDay.Month() != time.February || leapDay.Day() != 29 {
+		t.Fatalf("expected 29 February to be preserved, got %v", leapDay)
+	}
+}
+
 func TestNormalizeCardDAVEmailMailto(t *testing.T) {
 	t.Parallel()
 
@@ -107,3 +132,101 @@ func TestMediaTypeFromPhotoParams(t *testing.T) {
 		t.Fatalf("expected empty media type")
 	}
 }
+
+func TestParseGeoURI(t *testing.T) {
+	t.Parallel()
+
+	lat, lon := parseGeoURI(`"geo:25.2048,55.2708;u=35"`)
+	if lat == nil || lon == nil || *lat != 25.2048 || *lon != 55.2708 {
+		t.Fatalf("expected parsed coordinates, got %v %v", lat, lon)
+	}
+
+	for _, input := range []string{"", "25.2,55.2", "geo:", "geo:abc,55", "geo:95,10", "geo:10,190"} {
+		if lat, lon := parseGeoURI(input); lat != nil || lon != nil {
+			t.Fatalf("parseGeoURI(%q) expected nil coordinates", input)
+		}
+	}
+}
+
+func TestParseVCardAddresses(t *testing.T) {
+	t.Parallel()
+
+	card := make(vcard.Card)
+	card.Add(vcard.FieldAddress, &vcard.Field{
+		Value:  "PO 12;Apt 4;1 Main Street;Dubai;Dubai;00000;UAE",
+		Params: vcard.Params{vcard.ParamType: []string{"work"}, vcard.ParamGeolocation: []string{"geo:25.2048", "55.2708"}},
+	})
+	card.Add(vcard.FieldAddress, &vcard.Field{
+		Value:  ";;2 Side Road;Sharjah;;;UAE",
+		Params: vcard.Params{vcard.ParamType: []string{"home"}, vcard.ParamPreferred: []string{"1"}},
+	})
+
+	contact := parseVCard(card)
+	if len(contact.Addresses) != 2 {
+		t.Fatalf("expected 2 addresses, got %d", len(contact.Addresses))
+	}
+
+	work := contact.Addresses[0]
+	if work.POBox != "PO 12" || work.Extended != "Apt 4" || work.Street != "1 Main Street" || work.Type != "work" {
+		t.Fatalf("unexpected work address: %+v", work)
+	}
+
+	if work.Preferred || work.Latitude == nil || *work.Latitude != 25.2048 {
+		t.Fatalf("expected non-preferred work address with coordinates, got %+v", work)
+	}
+
+	if !contact.Addresses[1].Preferred || contact.Addresses[1].Latitude != nil {
+		t.Fatalf("expected preferred home address without coordinates, got %+v", contact.Addresses[1])
+	}
+}
+
+func TestNewVCardAddress(t *testing.T) {
+	t.Parallel()
+
+	lat, lon := 25.2, 55.3
+	addr := ContactAddress{
+		Street:      stringPtr("1 Main Street"),
+		Locality:    stringPtr("Dubai"),
+		Country:     stringPtr("UAE"),
+		AddressType: AddressWork,
+		IsPrimary:   true,
+		Latitude:    &lat,
+		Longitude:   &lon,
+	}
+
+	card := make(vcard.Card)
+	card.AddAddress(newVCardAddress(addr, true))
+
+	field := card.Get(vcard.FieldAddress)
+	if field == nil || field.Value != ";;1 Main Street;Dubai;;;UAE" {
+		t.Fatalf("unexpected ADR field: %+v", field)
+	}
+
+	if !field.Params.HasType("work") || field.Params.Get(vcard.ParamPreferred) != "1" {
+		t.Fatalf("expected work type and PREF, got %+v", field.Params)
+	}
+
+	if field.Params.Get(vcard.ParamGeolocation) != "" {
+		t.Fatalf("expected coordinates to stay local")
+	}
+
+	other := newVCardAddress(ContactAddress{Street: stringPtr("x"), AddressType: AddressOther, IsPrimary: true}, false)
+	if len(other.Params) != 0 {
+		t.Fatalf("expected no params for other address without PREF, got %+v", other.Params)
+	}
+}
+
+func TestCardDAVAddressKeyMatchesContactAddress(t *testing.T) {
+	t.Parallel()
+
+	cardAddr := CardDAVAddress{Street: "1  Main Street", Locality: "DUBAI", Country: "UAE"}
+	contactAddr := ContactAddress{Street: stringPtr("1 main street"), Locality: stringPtr("Dubai"), Country: stringPtr(" uae ")}
+
+	if cardAddr.key() != contactAddressKey(contactAddr) {
+		t.Fatalf("expected keys to match: %q vs %q", cardAddr.key(), contactAddressKey(contactAddr))
+	}
+
+	if cardAddr.key() == (CardDAVAddress{Street: "1 Main Street", Locality: "Dubai"}).key() {
+		t.Fatalf("expected different keys for different addresses")
+	}
+}
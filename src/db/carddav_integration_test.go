@@ -18,17 +18,31 @@ type carddavTestServer struct {
 	server *httptest.Server
 	mu     sync.Mutex
 	cards  map[string]vcard.Card
+	// etags optionally sets the ETag returned when fetching a card
+	etags map[string]string
 }
 
 func newCardDAVTestServer(t *testing.T) *carddavTestServer {
 	t.Helper()
 
-	cd := &carddavTestServer{cards: make(map[string]vcard.Card)}
+	cd := &carddavTestServer{cards: make(map[string]vcard.Card), etags: make(map[string]string)}
 	cd.server = httptest.NewServer(http.HandlerFunc(cd.handle))
 
 	return cd
 }
 
+// mustCreateCardDAVSource adds a CardDAV source for the given address book
+func mustCreateCardDAVSource(t *testing.T, name, url string) string {
+	t.Helper()
+
+	id, err := CreateCardDAVSource(testContext(), CardDAVSourceInput{Name: name, URL: url, Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("CreateCardDAVSource failed: %v", err)
+	}
+
+	return id
+}
+
 func (c *carddavTestServer) close() {
 	if c.server != nil {
 		c.server.Close()
@@ -85,6 +99,7 @@ func (c *carddavTestServer) handleGet(w http.ResponseWriter, path string) {
 
 	c.mu.Lock()
 	card, ok := c.cards[key]
+	etag := c.etags[key]
 	c.mu.Unlock()
 
 	if !ok {
@@ -96,6 +111,10 @@ func (c *carddavTestServer) handleGet(w http.ResponseWriter, path string) {
 
 	_ = vcard.NewEncoder(&buf).Encode(card)
 
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
 	w.Header().Set("Content-Type", "text/vcard")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(buf.Bytes())
@@ -212,15 +231,13 @@ func TestCardDAVListAndHelpers(t *testing.T) {
 	card2.AddName(&vcard.Name{GivenName: "Bob", FamilyName: "Example"})
 	server.cards["card-2.vcf"] = card2
 
-	t.Setenv("CARDDAV_URL", server.server.URL+"/addressbook/")
-	t.Setenv("CARDDAV_USERNAME", "user")
-	t.Setenv("CARDDAV_PASSWORD", "pass")
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
 
-	if _, err := GetCardDAVConfig(); err != nil {
+	if _, err := GetCardDAVConfig(testContext(), sourceID); err != nil {
 		t.Fatalf("GetCardDAVConfig failed: %v", err)
 	}
 
-	contacts, err := ListCardDAVContacts(testContext())
+	contacts, err := ListCardDAVContacts(testContext(), sourceID)
 	if err != nil {
 		t.Fatalf("ListCardDAVContacts failed: %v", err)
 	}
@@ -229,7 +246,7 @@ func TestCardDAVListAndHelpers(t *testing.T) {
 		t.Fatalf("expected 2 contacts, got %d", len(contacts))
 	}
 
-	contact, err := GetCardDAVContact(testContext(), "card-1")
+	contact, err := GetCardDAVContact(testContext(), sourceID, "card-1")
 	if err != nil {
 		t.Fatalf("GetCardDAVContact failed: %v", err)
 	}
@@ -238,7 +255,7 @@ func TestCardDAVListAndHelpers(t *testing.T) {
 		t.Fatalf("expected display name, got %q", contact.DisplayName)
 	}
 
-	if _, err := GetCardDAVContact(testContext(), "missing"); err == nil {
+	if _, err := GetCardDAVContact(testContext(), sourceID, "missing"); err == nil {
 		t.Fatalf("expected error for missing contact")
 	}
 
@@ -300,12 +317,10 @@ func TestCardDAVSyncAndUpdate(t *testing.T) {
 	card.Add(vcard.FieldTelephone, &vcard.Field{Value: "+1 555 1111", Params: vcard.Params{vcard.ParamType: []string{"cell"}}})
 	server.cards["sync-1.vcf"] = card
 
-	t.Setenv("CARDDAV_URL", server.server.URL+"/addressbook/")
-	t.Setenv("CARDDAV_USERNAME", "user")
-	t.Setenv("CARDDAV_PASSWORD", "pass")
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
 
 	carddavID := "sync-1"
-	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", CardDAVUUID: &carddavID, Tier: TierB})
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
 
 	if err := SyncContactFromCardDAV(testContext(), contactID, carddavID); err != nil {
 		t.Fatalf("SyncContactFromCardDAV failed: %v", err)
@@ -333,7 +348,7 @@ func TestCardDAVSyncAndUpdate(t *testing.T) {
 		t.Fatalf("SyncAllCardDAVContacts failed: %v", err)
 	}
 
-	newUUID, err := CreateCardDAVContact(testContext(), detail)
+	newUUID, err := CreateCardDAVContact(testContext(), sourceID, detail)
 	if err != nil {
 		t.Fatalf("CreateCardDAVContact failed: %v", err)
 	}
@@ -348,6 +363,7 @@ func TestCardDAVSyncAndUpdate(t *testing.T) {
 	}
 
 	contact.CardDAVUUID = &carddavID
+	contact.CardDAVSourceID = &sourceID
 	if err := UpdateCardDAVContact(testContext(), contact); err != nil {
 		t.Fatalf("UpdateCardDAVContact failed: %v", err)
 	}
@@ -356,7 +372,7 @@ func TestCardDAVSyncAndUpdate(t *testing.T) {
 	localPhone := "+1 555 9999"
 
 	localContactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", Email: &localEmail, Phone: &localPhone, Tier: TierB})
-	if err := MigrateContactToCardDAV(testContext(), localContactID); err != nil {
+	if err := MigrateContactToCardDAV(testContext(), localContactID, sourceID); err != nil {
 		t.Fatalf("MigrateContactToCardDAV failed: %v", err)
 	}
 
@@ -373,3 +389,103 @@ func TestCardDAVSyncAndUpdate(t *testing.T) {
 		t.Fatalf("expected emails and phones after migration")
 	}
 }
+
+func TestCardDAVAddressSyncAndPush(t *testing.T) {
+	resetDatabase(t)
+
+	server := newCardDAVTestServer(t)
+	defer server.close()
+
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldUID, "addr-1")
+	card.SetValue(vcard.FieldFormattedName, "Address User")
+	card.AddName(&vcard.Name{GivenName: "Address", FamilyName: "User"})
+	card.Add(vcard.FieldAddress, &vcard.Field{Value: ";;1 Main Street;Dubai;;;UAE", Params: vcard.Params{vcard.ParamType: []string{"work"}}})
+	card.Add(vcard.FieldAddress, &vcard.Field{Value: ";Apt 4;2 Side Road;Sharjah;;;UAE", Params: vcard.Params{vcard.ParamType: []string{"home"}, vcard.ParamPreferred: []string{"1"}}})
+	server.cards["addr-1.vcf"] = card
+
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
+
+	ctx := testContext()
+	carddavID := "addr-1"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
+
+	// A local address matching the card is adopted and keeps its coordinates
+	lat, lon := 25.2048, 55.2708
+	if err := AddAddress(ctx, AddAddressInput{ContactID: contactID, AddressFields: AddressFields{
+		Street:    stringPtr("1 main street"),
+		Locality:  stringPtr("Dubai"),
+		Country:   stringPtr("UAE"),
+		Latitude:  &lat,
+		Longitude: &lon,
+	}}); err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+
+	if err := SyncContactFromCardDAV(ctx, contactID, carddavID); err != nil {
+		t.Fatalf("SyncContactFromCardDAV failed: %v", err)
+	}
+
+	detail, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(detail.Addresses) != 2 {
+		t.Fatalf("expected 2 addresses after sync, got %d", len(detail.Addresses))
+	}
+
+	for _, addr := range detail.Addresses {
+		if addr.Source != "carddav" {
+			t.Fatalf("expected all addresses to come from CardDAV, got %+v", addr)
+		}
+
+		switch pointerString(addr.Locality) {
+		case "Dubai":
+			if !addr.HasCoordinates() || addr.AddressType != AddressWork || addr.IsPrimary {
+				t.Fatalf("expected adopted work address with coordinates, got %+v", addr)
+			}
+		case "Sharjah":
+			if !addr.IsPrimary || pointerString(addr.Extended) != "Apt 4" {
+				t.Fatalf("expected preferred home address to be primary, got %+v", addr)
+			}
+		default:
+			t.Fatalf("unexpected address %+v", addr)
+		}
+	}
+
+	var dubai ContactAddress
+
+	for _, addr := range detail.Addresses {
+		if pointerString(addr.Locality) == "Dubai" {
+			dubai = addr
+		}
+	}
+
+	detail.Addresses = []ContactAddress{dubai}
+	if err := UpdateCardDAVContact(ctx, detail); err != nil {
+		t.Fatalf("UpdateCardDAVContact failed: %v", err)
+	}
+
+	pushed := server.cards["addr-1.vcf"].Addresses()
+	if len(pushed) != 1 || pushed[0].StreetAddress != "1 Main Street" || pushed[0].Params.Get(vcard.ParamPreferred) != "" {
+		t.Fatalf("expected one pushed non-preferred address, got %+v", pushed)
+	}
+
+	if err := SyncContactFromCardDAV(ctx, contactID, carddavID); err != nil {
+		t.Fatalf("SyncContactFromCardDAV failed: %v", err)
+	}
+
+	detail, err = GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(detail.Addresses) != 1 || !detail.Addresses[0].IsPrimary {
+		t.Fatalf("expected the remaining address to be primary, got %+v", detail.Addresses)
+	}
+
+	if !detail.Addresses[0].HasCoordinates() || *detail.Addresses[0].Latitude != lat {
+		t.Fatalf("expected coordinates to survive a round trip, got %+v", detail.Addresses[0])
+	}
+}
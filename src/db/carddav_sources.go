@@ -0,0 +1,407 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultCardDAVSourceName names the source created from the environment
+const defaultCardDAVSourceName = "Default"
+
+// CardDAVSource is a named CardDAV address book with its own credentials
+type CardDAVSource struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	URL       string    `db:"url"`
+	Username  string    `db:"username"`
+	Password  string    `db:"password"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	// LinkedContacts is the number of contacts linked to cards in the source
+	LinkedContacts int `db:"linked_contacts"`
+}
+
+// CardDAVSourceInput holds the editable fields of a source. An empty
+// password on update keeps the stored one.
+type CardDAVSourceInput struct {
+	Name     string
+	URL      string
+	Username string
+	Password string
+}
+
+func (s *CardDAVSource) config() *CardDAVConfig {
+	return &CardDAVConfig{URL: s.URL, Username: s.Username, Password: s.Password}
+}
+
+func (input *CardDAVSourceInput) normalize() error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.URL = strings.TrimSpace(input.URL)
+	input.Username = strings.TrimSpace(input.Username)
+
+	if input.Name == "" {
+		return ErrCardDAVSourceNameRequired
+	}
+
+	if input.URL == "" {
+		return ErrCardDAVSourceURLRequired
+	}
+
+	return nil
+}
+
+// ListCardDAVSources returns the configured sources ordered by name
+func ListCardDAVSources(ctx context.Context) ([]CardDAVSource, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT s.id, s.name, s.url, s.username, s.password, s.created_at, s.updated_at,
+			(SELECT count(*) FROM contacts c WHERE c.carddav_source_id = s.id AND c.carddav_uuid IS NOT NULL)
+		FROM carddav_sources s
+		ORDER BY lower(s.name) ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CardDAV sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []CardDAVSource
+
+	for rows.Next() {
+		var source CardDAVSource
+		if err := rows.Scan(&source.ID, &source.Name, &source.URL, &source.Username, &source.Password,
+			&source.CreatedAt, &source.UpdatedAt, &source.LinkedContacts); err != nil {
+			return nil, fmt.Errorf("failed to scan CardDAV source: %w", err)
+		}
+
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating CardDAV sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// HasCardDAVSources reports whether at least one source is configured
+func HasCardDAVSources(ctx context.Context) (bool, error) {
+	if pool == nil {
+		return false, ErrDatabaseConnectionNotInitialized
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM carddav_sources)`).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check CardDAV sources: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetCardDAVSource returns a source by ID
+func GetCardDAVSource(ctx context.Context, id string) (*CardDAVSource, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	if uuid.Validate(id) != nil {
+		return nil, ErrCardDAVSourceNotFound
+	}
+
+	var source CardDAVSource
+
+	err := pool.QueryRow(ctx, `
+		SELECT id, name, url, username, password, created_at, updated_at
+		FROM carddav_sources
+		WHERE id = $1
+	`, id).Scan(&source.ID, &source.Name, &source.URL, &source.Username, &source.Password,
+		&source.CreatedAt, &source.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCardDAVSourceNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CardDAV source: %w", err)
+	}
+
+	return &source, nil
+}
+
+// GetCardDAVConfig loads the connection settings of a source
+func GetCardDAVConfig(ctx context.Context, sourceID string) (*CardDAVConfig, error) {
+	source, err := GetCardDAVSource(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return source.config(), nil
+}
+
+// contactCardDAVSourceID returns the source a contact's card lives in
+func contactCardDAVSourceID(ctx context.Context, contactID string) (string, error) {
+	var sourceID *string
+
+	err := pool.QueryRow(ctx, `
+		SELECT carddav_source_id::text FROM contacts WHERE id = $1
+	`, contactID).Scan(&sourceID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrContactNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get contact CardDAV source: %w", err)
+	}
+
+	if sourceID == nil {
+		return "", ErrCardDAVSourceNotFound
+	}
+
+	return *sourceID, nil
+}
+
+// CreateCardDAVSource adds a source. Contacts linked before sources existed
+// belong to the first source added.
+func CreateCardDAVSource(ctx context.Context, input CardDAVSourceInput) (string, error) {
+	if pool == nil {
+		return "", ErrDatabaseConnectionNotInitialized
+	}
+
+	if err := input.normalize(); err != nil {
+		return "", err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back CardDAV source transaction", "error", err)
+		}
+	}()
+
+	var first bool
+	if err := tx.QueryRow(ctx, `SELECT NOT EXISTS(SELECT 1 FROM carddav_sources)`).Scan(&first); err != nil {
+		return "", fmt.Errorf("failed to check CardDAV sources: %w", err)
+	}
+
+	var id string
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO carddav_sources (name, url, username, password)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id::text
+	`, input.Name, input.URL, input.Username, input.Password).Scan(&id)
+	if isUniqueViolation(err) {
+		return "", ErrCardDAVSourceNameTaken
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create CardDAV source: %w", err)
+	}
+
+	if first {
+		tag, err := tx.Exec(ctx, `
+			UPDATE contacts SET carddav_source_id = $1
+			WHERE carddav_uuid IS NOT NULL AND carddav_source_id IS NULL
+		`, id)
+		if err != nil {
+			return "", fmt.Errorf("failed to assign existing CardDAV links: %w", err)
+		}
+
+		if tag.RowsAffected() > 0 {
+			logger.Info("Assigned existing CardDAV links to source", "source", input.Name, "contacts", tag.RowsAffected())
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit CardDAV source: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateCardDAVSource changes a source's name, address or credentials
+func UpdateCardDAVSource(ctx context.Context, id string, input CardDAVSourceInput) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if uuid.Validate(id) != nil {
+		return ErrCardDAVSourceNotFound
+	}
+
+	if err := input.normalize(); err != nil {
+		return err
+	}
+
+	result, err := pool.Exec(ctx, `
+		UPDATE carddav_sources
+		SET name = $2, url = $3, username = $4,
+			password = CASE WHEN $5::text = '' THEN password ELSE $5::text END
+		WHERE id = $1
+	`, id, input.Name, input.URL, input.Username, input.Password)
+	if isUniqueViolation(err) {
+		return ErrCardDAVSourceNameTaken
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update CardDAV source: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrCardDAVSourceNotFound
+	}
+
+	// What earlier runs recorded belongs to the old address book
+	_, err = pool.Exec(ctx, `
+		DELETE FROM carddav_sync_state WHERE source_id = $1 AND address_book_url <> $2
+	`, id, input.URL)
+	if err != nil {
+		return fmt.Errorf("failed to reset CardDAV sync state: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		DELETE FROM carddav_cards
+		WHERE source_id = $1 AND NOT EXISTS (SELECT 1 FROM carddav_sync_state WHERE source_id = $1)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reset CardDAV cards: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCardDAVSource removes a source. Its contacts are unlinked and keep
+// their local details, like cards deleted on the server.
+func DeleteCardDAVSource(ctx context.Context, id string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if uuid.Validate(id) != nil {
+		return ErrCardDAVSourceNotFound
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back CardDAV source transaction", "error", err)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM contact_carddav_conflicts
+		WHERE resolved_at IS NULL
+			AND contact_id IN (SELECT id FROM contacts WHERE carddav_source_id = $1)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear CardDAV conflicts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE contacts SET carddav_uuid = NULL, carddav_source_id = NULL
+		WHERE carddav_source_id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlink CardDAV source contacts: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM carddav_sources WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete CardDAV source: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrCardDAVSourceNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit CardDAV source removal: %w", err)
+	}
+
+	return nil
+}
+
+// ImportCardDAVSourceFromEnv adds the address book configured through
+// CARDDAV_URL, CARDDAV_USERNAME and CARDDAV_PASSWORD as a source the first
+// time it is seen, as long as no source has been set up yet. The URL is
+// remembered, so removing or editing the source in the web UI sticks across
+// restarts. It does nothing when unset.
+func ImportCardDAVSourceFromEnv(ctx context.Context) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	input := CardDAVSourceInput{
+		Name:     defaultCardDAVSourceName,
+		URL:      os.Getenv("CARDDAV_URL"),
+		Username: os.Getenv("CARDDAV_USERNAME"),
+		Password: os.Getenv("CARDDAV_PASSWORD"),
+	}
+
+	if input.URL == "" || input.Username == "" || input.Password == "" {
+		return nil
+	}
+
+	sourceURL := strings.TrimSpace(input.URL)
+
+	var imported, hasSources bool
+
+	err := pool.QueryRow(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM carddav_env_imports WHERE url = $1),
+			EXISTS(SELECT 1 FROM carddav_sources)
+	`, sourceURL).Scan(&imported, &hasSources)
+	if err != nil {
+		return fmt.Errorf("failed to check CardDAV sources: %w", err)
+	}
+
+	if imported {
+		return nil
+	}
+
+	if hasSources {
+		logger.Info("CardDAV sources are already set up, not adding CARDDAV_URL")
+	} else {
+		if _, err := CreateCardDAVSource(ctx, input); err != nil {
+			return err
+		}
+
+		logger.Info("Added CardDAV source from environment", "name", input.Name)
+	}
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO carddav_env_imports (url) VALUES ($1)
+		ON CONFLICT (url) DO NOTHING
+	`, sourceURL); err != nil {
+		return fmt.Errorf("failed to record CardDAV source import: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emersion/go-vcard"
+)
+
+func TestCardDAVSourceLifecycle(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	// Links made before sources existed belong to the first source added
+	legacyUUID := "legacy-1"
+	legacyID := mustCreateContact(t, CreateContactInput{NameGiven: "Legacy", CardDAVUUID: &legacyUUID, Tier: TierB})
+
+	if configured, err := HasCardDAVSources(ctx); err != nil || configured {
+		t.Fatalf("expected no sources, got %v %v", configured, err)
+	}
+
+	personalID := mustCreateCardDAVSource(t, "Personal", "https://dav.invalid/personal/")
+	workID := mustCreateCardDAVSource(t, "Work", "https://dav.invalid/work/")
+
+	legacy, err := getContactDetail(ctx, legacyID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if pointerString(legacy.CardDAVSourceID) != personalID || legacy.CardDAVSourceName != "Personal" {
+		t.Fatalf("expected legacy link to join the first source, got %v %q", legacy.CardDAVSourceID, legacy.CardDAVSourceName)
+	}
+
+	if _, err := CreateCardDAVSource(ctx, CardDAVSourceInput{Name: "work", URL: "https://dav.invalid/other/"}); !errors.Is(err, ErrCardDAVSourceNameTaken) {
+		t.Fatalf("expected ErrCardDAVSourceNameTaken, got %v", err)
+	}
+
+	if _, err := CreateCardDAVSource(ctx, CardDAVSourceInput{Name: " ", URL: "https://dav.invalid/"}); !errors.Is(err, ErrCardDAVSourceNameRequired) {
+		t.Fatalf("expected ErrCardDAVSourceNameRequired, got %v", err)
+	}
+
+	// The same card UID can be linked once per source
+	workContactID := mustCreateContact(t, CreateContactInput{NameGiven: "Colleague", Tier: TierC})
+	if err := LinkCardDAV(ctx, workContactID, workID, legacyUUID); err != nil {
+		t.Fatalf("LinkCardDAV failed: %v", err)
+	}
+
+	linked, err := IsCardDAVUUIDLinked(ctx, workID, "LEGACY-1")
+	if err != nil || !linked {
+		t.Fatalf("expected card to be linked in work source, got %v %v", linked, err)
+	}
+
+	otherID := mustCreateCardDAVSource(t, "Other", "https://dav.invalid/other/")
+
+	if linked, err := IsCardDAVUUIDLinked(ctx, otherID, legacyUUID); err != nil || linked {
+		t.Fatalf("expected card to be unlinked in other source, got %v %v", linked, err)
+	}
+
+	// A blank password keeps the stored one
+	if err := UpdateCardDAVSource(ctx, workID, CardDAVSourceInput{Name: "Office", URL: "https://dav.invalid/work/", Username: "me"}); err != nil {
+		t.Fatalf("UpdateCardDAVSource failed: %v", err)
+	}
+
+	work, err := GetCardDAVSource(ctx, workID)
+	if err != nil {
+		t.Fatalf("GetCardDAVSource failed: %v", err)
+	}
+
+	if work.Name != "Office" || work.Username != "me" || work.Password != "pass" {
+		t.Fatalf("unexpected updated source %+v", work)
+	}
+
+	sources, err := ListCardDAVSources(ctx)
+	if err != nil {
+		t.Fatalf("ListCardDAVSources failed: %v", err)
+	}
+
+	if len(sources) != 3 || sources[0].Name != "Office" || sources[0].LinkedContacts != 1 {
+		t.Fatalf("unexpected sources %+v", sources)
+	}
+
+	// Removing a source unlinks its contacts and keeps them
+	if err := DeleteCardDAVSource(ctx, workID); err != nil {
+		t.Fatalf("DeleteCardDAVSource failed: %v", err)
+	}
+
+	colleague, err := getContactDetail(ctx, workContactID, false)
+	if err != nil {
+		t.Fatalf("getContactDetail failed: %v", err)
+	}
+
+	if colleague.CardDAVUUID != nil || colleague.CardDAVSourceID != nil {
+		t.Fatalf("expected contact to be unlinked, got %+v", colleague.Contact)
+	}
+
+	if _, err := GetCardDAVSource(ctx, workID); !errors.Is(err, ErrCardDAVSourceNotFound) {
+		t.Fatalf("expected ErrCardDAVSourceNotFound, got %v", err)
+	}
+}
+
+func TestImportCardDAVSourceFromEnvOnlyOnce(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	t.Setenv("CARDDAV_URL", "https://dav.invalid/env/")
+	t.Setenv("CARDDAV_USERNAME", "user")
+	t.Setenv("CARDDAV_PASSWORD", "pass")
+
+	if err := ImportCardDAVSourceFromEnv(ctx); err != nil {
+		t.Fatalf("ImportCardDAVSourceFromEnv failed: %v", err)
+	}
+
+	sources, err := ListCardDAVSources(ctx)
+	if err != nil {
+		t.Fatalf("ListCardDAVSources failed: %v", err)
+	}
+
+	if len(sources) != 1 || sources[0].Name != defaultCardDAVSourceName || sources[0].URL != "https://dav.invalid/env/" {
+		t.Fatalf("expected the environment source to be added, got %#v", sources)
+	}
+
+	// A source removed in the web UI stays removed after a restart
+	if err := DeleteCardDAVSource(ctx, sources[0].ID.String()); err != nil {
+		t.Fatalf("DeleteCardDAVSource failed: %v", err)
+	}
+
+	if err := ImportCardDAVSourceFromEnv(ctx); err != nil {
+		t.Fatalf("ImportCardDAVSourceFromEnv failed: %v", err)
+	}
+
+	if configured, err := HasCardDAVSources(ctx); err != nil || configured {
+		t.Fatalf("expected the removed source not to come back, got %v %v", configured, err)
+	}
+
+	// A new address book in the environment is left alone once sources are
+	// set up in the web UI
+	mustCreateCardDAVSource(t, defaultCardDAVSourceName, "https://dav.invalid/personal/")
+	t.Setenv("CARDDAV_URL", "https://dav.invalid/other/")
+
+	if err := ImportCardDAVSourceFromEnv(ctx); err != nil {
+		t.Fatalf("expected no name conflict once sources exist, got %v", err)
+	}
+
+	sources, err = ListCardDAVSources(ctx)
+	if err != nil {
+		t.Fatalf("ListCardDAVSources failed: %v", err)
+	}
+
+	if len(sources) != 1 || sources[0].URL != "https://dav.invalid/personal/" {
+		t.Fatalf("expected only the web UI source, got %#v", sources)
+	}
+}
+
+func TestSyncCardDAVRunsEverySource(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	if _, err := SyncCardDAV(ctx, CardDAVSyncManual, false); !errors.Is(err, ErrCardDAVNoSources) {
+		t.Fatalf("expected ErrCardDAVNoSources, got %v", err)
+	}
+
+	personal := newCardDAVTestServer(t)
+	defer personal.close()
+
+	work := newCardDAVTestServer(t)
+	defer work.close()
+
+	// Both address books hold a card with the same UID
+	for name, server := range map[string]*carddavTestServer{"Personal": personal, "Work": work} {
+		card := make(vcard.Card)
+		card.SetValue(vcard.FieldUID, "shared")
+		card.SetValue(vcard.FieldFormattedName, name+" Card")
+		card.AddName(&vcard.Name{GivenName: name})
+		server.cards["shared.vcf"] = card
+	}
+
+	personalID := mustCreateCardDAVSource(t, "Personal", personal.server.URL+"/addressbook/")
+	workID := mustCreateCardDAVSource(t, "Work", work.server.URL+"/addressbook/")
+
+	cardUID := "shared"
+	personalContactID := mustCreateContact(t, CreateContactInput{NameGiven: "A", CardDAVUUID: &cardUID, CardDAVSourceID: &personalID, Tier: TierB})
+	workContactID := mustCreateContact(t, CreateContactInput{NameGiven: "B", CardDAVUUID: &cardUID, CardDAVSourceID: &workID, Tier: TierB})
+
+	runs, err := SyncCardDAV(ctx, CardDAVSyncManual, false)
+	if err != nil {
+		t.Fatalf("SyncCardDAV failed: %v", err)
+	}
+
+	if len(runs) != 2 || runs[0].SourceName != "Personal" || runs[1].SourceName != "Work" {
+		t.Fatalf("expected a run per source, got %+v", runs)
+	}
+
+	for contactID, want := range map[string]string{personalContactID: "Personal", workContactID: "Work"} {
+		contact, err := getContactDetail(ctx, contactID, false)
+		if err != nil {
+			t.Fatalf("getContactDetail failed: %v", err)
+		}
+
+		if pointerString(contact.NameGiven) != want {
+			t.Fatalf("expected card of %s source, got %q", want, pointerString(contact.NameGiven))
+		}
+	}
+}
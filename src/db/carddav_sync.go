@@ -0,0 +1,867 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/emersion/go-webdav/carddav"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CardDAVSyncTrigger records what started a sync run
+type CardDAVSyncTrigger string
+
+// CardDAVSyncMode describes how much of the address book a run looked at
+type CardDAVSyncMode string
+
+// CardDAVSyncStatus is the outcome of a sync run
+type CardDAVSyncStatus string
+
+const (
+	CardDAVSyncScheduled CardDAVSyncTrigger = "scheduled"
+	CardDAVSyncManual    CardDAVSyncTrigger = "manual"
+
+	// CardDAVSyncFull listed every card on the server
+	CardDAVSyncFull CardDAVSyncMode = "full"
+	// CardDAVSyncIncremental only listed cards changed since the last token
+	CardDAVSyncIncremental CardDAVSyncMode = "incremental"
+	// CardDAVSyncUnchanged stopped early because the ctag had not moved
+	CardDAVSyncUnchanged CardDAVSyncMode = "unchanged"
+
+	CardDAVSyncSuccess CardDAVSyncStatus = "success"
+	CardDAVSyncPartial CardDAVSyncStatus = "partial"
+	CardDAVSyncFailed  CardDAVSyncStatus = "failed"
+)
+
+const (
+	// cardDAVSyncInterval is how often the worker polls the server
+	cardDAVSyncInterval = 15 * time.Minute
+	// cardDAVSyncTimeout bounds each request made during a sync
+	cardDAVSyncTimeout = 30 * time.Second
+	// cardDAVMultiGetBatch caps the cards requested per multiget report
+	cardDAVMultiGetBatch = 50
+	// cardDAVSyncHistoryLimit is the number of runs kept in the history
+	cardDAVSyncHistoryLimit = 200
+	// cardDAVSyncMaxErrors caps the error messages stored per run
+	cardDAVSyncMaxErrors = 20
+)
+
+// cardDAVSyncMu stops the worker and a manual sync from overlapping
+var cardDAVSyncMu sync.Mutex
+
+// CardDAVSyncRun is one recorded sync run of a source
+type CardDAVSyncRun struct {
+	ID               uuid.UUID          `db:"id"`
+	SourceName       string             `db:"source_name"`
+	Trigger          CardDAVSyncTrigger `db:"trigger"`
+	Mode             CardDAVSyncMode    `db:"mode"`
+	Status           CardDAVSyncStatus  `db:"status"`
+	CardsChanged     int                `db:"cards_changed"`
+	ContactsUpdated  int                `db:"contacts_updated"`
+	CardsDeleted     int                `db:"cards_deleted"`
+	ContactsUnlinked int                `db:"contacts_unlinked"`
+	// ContactsInConflict kept local details because of an open conflict
+	ContactsInConflict int       `db:"contacts_in_conflict"`
+	Errors             []string  `db:"errors"`
+	StartedAt          time.Time `db:"started_at"`
+	FinishedAt         time.Time `db:"finished_at"`
+}
+
+// Label returns the status as shown on the contacts page
+func (s CardDAVSyncStatus) Label() string {
+	switch s {
+	case CardDAVSyncSuccess:
+		return "Up to date"
+	case CardDAVSyncPartial:
+		return "Completed with errors"
+	case CardDAVSyncFailed:
+		return "Failed"
+	default:
+		return string(s)
+	}
+}
+
+// Duration returns how long the run took
+func (r CardDAVSyncRun) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond)
+}
+
+// Summary describes what the run changed in a short sentence
+func (r CardDAVSyncRun) Summary() string {
+	if r.Mode == CardDAVSyncUnchanged {
+		return "No changes on the server"
+	}
+
+	parts := []string{pluralize(r.CardsChanged, "card changed", "cards changed")}
+	if r.ContactsUpdated > 0 {
+		parts = append(parts, pluralize(r.ContactsUpdated, "contact updated", "contacts updated"))
+	}
+
+	if r.CardsDeleted > 0 {
+		parts = append(parts, pluralize(r.CardsDeleted, "card deleted", "cards deleted"))
+	}
+
+	if r.ContactsUnlinked > 0 {
+		parts = append(parts, pluralize(r.ContactsUnlinked, "contact unlinked", "contacts unlinked"))
+	}
+
+	if r.ContactsInConflict > 0 {
+		parts = append(parts, pluralize(r.ContactsInConflict, "contact in conflict", "contacts in conflict"))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+func (r *CardDAVSyncRun) addError(message string) {
+	if len(r.Errors) < cardDAVSyncMaxErrors {
+		r.Errors = append(r.Errors, message)
+	}
+}
+
+// cardDAVSyncState is what the previous run learnt about the address book
+type cardDAVSyncState struct {
+	URL       string
+	SyncToken string
+	CTag      string
+}
+
+// cardDAVCardState is a card seen on the server during a previous run
+type cardDAVCardState struct {
+	UID  string
+	Path string
+	ETag string
+}
+
+// cardDAVChanges lists the cards a run has to look at
+type cardDAVChanges struct {
+	Updated []carddav.AddressObject
+	Deleted []string
+	Token   string
+	// Complete is set when Updated holds every card on the server
+	Complete bool
+}
+
+// StartCardDAVSyncWorker starts a background goroutine that periodically
+// pulls changed cards from every CardDAV source. Sources can be added while
+// running, so the worker idles rather than stopping when there are none.
+func StartCardDAVSyncWorker(ctx context.Context) {
+	go func() {
+		// Initial delay to let the application start up
+		logger.Info("CardDAV sync worker starting in 10 seconds")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+
+		runScheduledCardDAVSync(ctx)
+
+		ticker := time.NewTicker(cardDAVSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("CardDAV sync worker shutting down")
+				return
+			case <-ticker.C:
+				runScheduledCardDAVSync(ctx)
+			}
+		}
+	}()
+}
+
+func runScheduledCardDAVSync(ctx context.Context) {
+	runs, err := SyncCardDAV(ctx, CardDAVSyncScheduled, false)
+
+	switch {
+	case errors.Is(err, ErrCardDAVNoSources):
+		return
+	case errors.Is(err, ErrCardDAVSyncInProgress):
+		logger.Info("CardDAV sync already running, skipping scheduled run")
+
+		return
+	case err != nil:
+		logger.Error("CardDAV sync failed", "error", err)
+	}
+
+	for _, run := range runs {
+		switch {
+		case run.Status == CardDAVSyncPartial:
+			logger.Warn("CardDAV sync completed with errors", "source", run.SourceName, "summary", run.Summary(), "errors", len(run.Errors))
+		case run.Status == CardDAVSyncSuccess && run.Mode != CardDAVSyncUnchanged:
+			logger.Info("CardDAV sync completed", "source", run.SourceName, "mode", run.Mode, "summary", run.Summary())
+		}
+	}
+}
+
+// SyncCardDAV pulls changed cards from every CardDAV source into their
+// linked contacts, recording one run per source. A full sync ignores the
+// stored sync tokens and ETags and re-applies every card. The returned error
+// joins the failures of individual sources.
+func SyncCardDAV(ctx context.Context, trigger CardDAVSyncTrigger, full bool) ([]CardDAVSyncRun, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	sources, err := ListCardDAVSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources) == 0 {
+		return nil, ErrCardDAVNoSources
+	}
+
+	if !cardDAVSyncMu.TryLock() {
+		return nil, ErrCardDAVSyncInProgress
+	}
+	defer cardDAVSyncMu.Unlock()
+
+	runs := make([]CardDAVSyncRun, 0, len(sources))
+
+	var errs []error
+
+	for i := range sources {
+		run, err := syncCardDAVSource(ctx, &sources[i], trigger, full)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sources[i].Name, err))
+		}
+
+		runs = append(runs, *run)
+	}
+
+	return runs, errors.Join(errs...)
+}
+
+// syncCardDAVSource runs and records a sync of one source
+func syncCardDAVSource(ctx context.Context, source *CardDAVSource, trigger CardDAVSyncTrigger, full bool) (*CardDAVSyncRun, error) {
+	run := &CardDAVSyncRun{
+		SourceName: source.Name,
+		Trigger:    trigger,
+		Mode:       CardDAVSyncFull,
+		StartedAt:  time.Now(),
+	}
+
+	syncErr := runCardDAVSync(ctx, source, full, run)
+
+	run.FinishedAt = time.Now()
+
+	switch {
+	case syncErr != nil:
+		run.Status = CardDAVSyncFailed
+		run.addError(syncErr.Error())
+	case len(run.Errors) > 0:
+		run.Status = CardDAVSyncPartial
+	default:
+		run.Status = CardDAVSyncSuccess
+	}
+
+	if err := recordCardDAVSyncRun(ctx, source.ID, run); err != nil {
+		logger.Warn("Failed to record CardDAV sync run", "error", err)
+	}
+
+	return run, syncErr
+}
+
+func runCardDAVSync(ctx context.Context, source *CardDAVSource, force bool, run *CardDAVSyncRun) error {
+	sourceID := source.ID.String()
+	config := source.config()
+
+	state, err := loadCardDAVSyncState(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+
+	known, err := loadCardDAVCards(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+
+	linked, err := linkedCardDAVContacts(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+
+	// Contacts linked since the last run have no card state yet, so their
+	// card can only be found by listing the whole address book
+	full := force || hasUntrackedCardDAVLinks(known, linked)
+
+	httpClient := newCardDAVHTTPClient(config, cardDAVSyncTimeout)
+
+	client, err := carddav.NewClient(httpClient, config.URL)
+	if err != nil {
+		return fmt.Errorf("failed to create CardDAV client: %w", err)
+	}
+
+	ctag, err := fetchCardDAVCTag(ctx, httpClient, config.URL)
+	if err != nil {
+		logger.Warn("Failed to fetch CardDAV ctag", "error", err)
+	}
+
+	if !full && ctag != "" && ctag == state.CTag {
+		run.Mode = CardDAVSyncUnchanged
+		return nil
+	}
+
+	token := state.SyncToken
+	if full {
+		token = ""
+	}
+
+	changes, err := listCardDAVChanges(ctx, client, token)
+	if err != nil {
+		return err
+	}
+
+	if !changes.Complete {
+		run.Mode = CardDAVSyncIncremental
+	}
+
+	// seen holds the lowercased UIDs known to still exist on the server
+	seen := make(map[string]bool)
+	etags := make(map[string]string)
+
+	var paths []string
+
+	for _, obj := range changes.Updated {
+		if !isCardDAVCardPath(obj.Path) {
+			continue
+		}
+
+		if prev, ok := known[obj.Path]; ok && !force && obj.ETag != "" && prev.ETag == obj.ETag {
+			seen[strings.ToLower(prev.UID)] = true
+			continue
+		}
+
+		paths = append(paths, obj.Path)
+		etags[obj.Path] = obj.ETag
+	}
+
+	objects, err := fetchCardDAVCards(ctx, client, paths)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if obj.Card == nil {
+			continue
+		}
+
+		uid := strings.TrimSpace(obj.Card.Value(vcard.FieldUID))
+		if uid == "" {
+			continue
+		}
+
+		seen[strings.ToLower(uid)] = true
+		run.CardsChanged++
+
+		etag := obj.ETag
+		if etag == "" {
+			etag = etags[obj.Path]
+		}
+
+		cardDAVContact := parseVCard(obj.Card)
+		failed, held := false, false
+
+		for _, contactID := range linked[strings.ToLower(uid)] {
+			inConflict, err := holdConflictedCardDAVContact(ctx, contactID, etag, &cardDAVContact)
+			if err != nil {
+				run.addError(fmt.Sprintf("failed to check conflict for contact %s: %v", contactID, err))
+				failed = true
+
+				continue
+			}
+
+			if inConflict {
+				run.ContactsInConflict++
+				held = true
+
+				continue
+			}
+
+			if err := applyCardDAVContact(ctx, contactID, &cardDAVContact); err != nil {
+				run.addError(fmt.Sprintf("failed to sync contact %s: %v", contactID, err))
+				failed = true
+
+				continue
+			}
+
+			run.ContactsUpdated++
+		}
+
+		// Forgetting a failed card makes the next run fetch it again
+		if failed {
+			if err := deleteCardDAVCard(ctx, sourceID, uid); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// A held contact has not seen this version, so its ETag stays behind
+		if held {
+			continue
+		}
+
+		if err := upsertCardDAVCard(ctx, sourceID, cardDAVCardState{UID: uid, Path: obj.Path, ETag: etag}); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := deletedCardDAVUIDs(changes, known, linked, seen)
+	if err != nil {
+		return err
+	}
+
+	for _, uid := range deleted {
+		unlinked, err := removeCardDAVCard(ctx, sourceID, uid)
+		if err != nil {
+			return err
+		}
+
+		if unlinked > 0 {
+			logger.Warn("CardDAV card deleted on the server, unlinked contacts", "source", source.Name, "uid", uid, "contacts", unlinked)
+		}
+
+		run.CardsDeleted++
+		run.ContactsUnlinked += unlinked
+	}
+
+	return saveCardDAVSyncState(ctx, sourceID, cardDAVSyncState{URL: config.URL, SyncToken: changes.Token, CTag: ctag})
+}
+
+// isCardDAVCardPath reports whether a listed path is a card rather than the
+// address book collection itself
+func isCardDAVCardPath(path string) bool {
+	return path != "" && !strings.HasSuffix(path, "/")
+}
+
+// hasUntrackedCardDAVLinks reports whether a linked contact points at a
+// card that no previous run has recorded
+func hasUntrackedCardDAVLinks(known map[string]cardDAVCardState, linked map[string][]string) bool {
+	tracked := make(map[string]bool, len(known))
+	for _, card := range known {
+		tracked[strings.ToLower(card.UID)] = true
+	}
+
+	for uid := range linked {
+		if !tracked[uid] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deletedCardDAVUIDs works out which cards disappeared from the server. A
+// complete listing deletes anything not seen; an incremental one only the
+// paths the server reported as removed.
+func deletedCardDAVUIDs(changes *cardDAVChanges, known map[string]cardDAVCardState, linked map[string][]string, seen map[string]bool) ([]string, error) {
+	var deleted []string
+
+	if !changes.Complete {
+		for _, path := range changes.Deleted {
+			if prev, ok := known[path]; ok {
+				deleted = append(deleted, prev.UID)
+			}
+		}
+
+		return deleted, nil
+	}
+
+	listed := 0
+
+	for _, obj := range changes.Updated {
+		if isCardDAVCardPath(obj.Path) {
+			listed++
+		}
+	}
+
+	// An empty listing is more likely a server problem than every card
+	// having been deleted, so nothing is unlinked
+	if listed == 0 && (len(known) > 0 || len(linked) > 0) {
+		return nil, ErrCardDAVAddressBookEmpty
+	}
+
+	marked := make(map[string]bool)
+
+	for _, card := range known {
+		key := strings.ToLower(card.UID)
+		if !seen[key] && !marked[key] {
+			marked[key] = true
+
+			deleted = append(deleted, card.UID)
+		}
+	}
+
+	for uid := range linked {
+		if !seen[uid] && !marked[uid] {
+			marked[uid] = true
+
+			deleted = append(deleted, uid)
+		}
+	}
+
+	return deleted, nil
+}
+
+// listCardDAVChanges asks the server what changed since the sync token. An
+// expired token falls back to a full listing, and servers without
+// sync-collection support are listed with their ETags instead.
+func listCardDAVChanges(ctx context.Context, client *carddav.Client, token string) (*cardDAVChanges, error) {
+	query := &carddav.SyncQuery{
+		DataRequest: carddav.AddressDataRequest{Props: []string{vcard.FieldUID}},
+		SyncToken:   token,
+	}
+
+	resp, err := client.SyncCollection(ctx, "", query)
+	if err != nil && token != "" {
+		logger.Warn("CardDAV sync token rejected, falling back to a full sync", "error", err)
+
+		query.SyncToken = ""
+		resp, err = client.SyncCollection(ctx, "", query)
+	}
+
+	if err == nil {
+		return &cardDAVChanges{
+			Updated:  resp.Updated,
+			Deleted:  resp.Deleted,
+			Token:    resp.SyncToken,
+			Complete: query.SyncToken == "",
+		}, nil
+	}
+
+	logger.Warn("CardDAV sync-collection failed, comparing ETags instead", "error", err)
+
+	objects, err := client.QueryAddressBook(ctx, "", &carddav.AddressBookQuery{
+		DataRequest: carddav.AddressDataRequest{Props: []string{vcard.FieldUID}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address book: %w", err)
+	}
+
+	return &cardDAVChanges{Updated: objects, Complete: true}, nil
+}
+
+// fetchCardDAVCards downloads the full cards at the given paths
+func fetchCardDAVCards(ctx context.Context, client *carddav.Client, paths []string) ([]carddav.AddressObject, error) {
+	var objects []carddav.AddressObject
+
+	for start := 0; start < len(paths); start += cardDAVMultiGetBatch {
+		end := min(start+cardDAVMultiGetBatch, len(paths))
+
+		batch, err := client.MultiGetAddressBook(ctx, "", &carddav.AddressBookMultiGet{
+			Paths:       paths[start:end],
+			DataRequest: carddav.AddressDataRequest{AllProp: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch changed cards: %w", err)
+		}
+
+		objects = append(objects, batch...)
+	}
+
+	return objects, nil
+}
+
+type cardDAVCTagMultiStatus struct {
+	Responses []struct {
+		PropStats []struct {
+			CTag   string `xml:"prop>getctag"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const cardDAVCTagRequest = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/"><d:prop><cs:getctag/></d:prop></d:propfind>`
+
+// fetchCardDAVCTag reads the address book ctag, which changes whenever any
+// card does. An empty ctag means the server does not provide one.
+func fetchCardDAVCTag(ctx context.Context, httpClient *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", url, strings.NewReader(cardDAVCTagRequest))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "0")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch ctag: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close CardDAV response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read ctag response: %w", err)
+	}
+
+	return parseCardDAVCTag(buf.Bytes())
+}
+
+// parseCardDAVCTag extracts the ctag from a PROPFIND multistatus body
+func parseCardDAVCTag(body []byte) (string, error) {
+	var ms cardDAVCTagMultiStatus
+	if err := xml.Unmarshal(body, &ms); err != nil {
+		return "", fmt.Errorf("failed to parse ctag response: %w", err)
+	}
+
+	for _, resp := range ms.Responses {
+		for _, propStat := range resp.PropStats {
+			if strings.Contains(propStat.Status, " 200 ") && strings.TrimSpace(propStat.CTag) != "" {
+				return strings.TrimSpace(propStat.CTag), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func loadCardDAVSyncState(ctx context.Context, sourceID string) (cardDAVSyncState, error) {
+	var state cardDAVSyncState
+
+	err := pool.QueryRow(ctx, `
+		SELECT address_book_url, COALESCE(sync_token, ''), COALESCE(ctag, '')
+		FROM carddav_sync_state
+		WHERE source_id = $1
+	`, sourceID).Scan(&state.URL, &state.SyncToken, &state.CTag)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return state, fmt.Errorf("failed to load CardDAV sync state: %w", err)
+	}
+
+	return state, nil
+}
+
+func saveCardDAVSyncState(ctx context.Context, sourceID string, state cardDAVSyncState) error {
+	_, err := pool.Exec(ctx, `
+		INSERT INTO carddav_sync_state (source_id, address_book_url, sync_token, ctag)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		ON CONFLICT (source_id) DO UPDATE
+		SET address_book_url = EXCLUDED.address_book_url,
+			sync_token = EXCLUDED.sync_token, ctag = EXCLUDED.ctag
+	`, sourceID, state.URL, state.SyncToken, state.CTag)
+	if err != nil {
+		return fmt.Errorf("failed to save CardDAV sync state: %w", err)
+	}
+
+	return nil
+}
+
+// loadCardDAVCards returns the recorded cards of a source keyed by path
+func loadCardDAVCards(ctx context.Context, sourceID string) (map[string]cardDAVCardState, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT uid, path, COALESCE(etag, '') FROM carddav_cards WHERE source_id = $1
+	`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CardDAV cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := make(map[string]cardDAVCardState)
+
+	for rows.Next() {
+		var card cardDAVCardState
+		if err := rows.Scan(&card.UID, &card.Path, &card.ETag); err != nil {
+			return nil, fmt.Errorf("failed to scan CardDAV card: %w", err)
+		}
+
+		cards[card.Path] = card
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating CardDAV cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// linkedCardDAVContacts maps the lowercased card UIDs of a source to their
+// linked contacts
+func linkedCardDAVContacts(ctx context.Context, sourceID string) (map[string][]string, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT lower(carddav_uuid), id::text
+		FROM contacts
+		WHERE carddav_source_id = $1 AND carddav_uuid IS NOT NULL AND carddav_uuid <> ''
+	`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contacts with CardDAV UUIDs: %w", err)
+	}
+	defer rows.Close()
+
+	linked := make(map[string][]string)
+
+	for rows.Next() {
+		var uid, contactID string
+		if err := rows.Scan(&uid, &contactID); err != nil {
+			return nil, fmt.Errorf("failed to scan contact: %w", err)
+		}
+
+		linked[uid] = append(linked[uid], contactID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contacts: %w", err)
+	}
+
+	return linked, nil
+}
+
+func upsertCardDAVCard(ctx context.Context, sourceID string, card cardDAVCardState) error {
+	_, err := pool.Exec(ctx, `
+		INSERT INTO carddav_cards (source_id, uid, path, etag, synced_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), now())
+		ON CONFLICT (source_id, uid) DO UPDATE
+		SET path = EXCLUDED.path, etag = EXCLUDED.etag, synced_at = now()
+	`, sourceID, card.UID, card.Path, card.ETag)
+	if err != nil {
+		return fmt.Errorf("failed to record CardDAV card: %w", err)
+	}
+
+	return nil
+}
+
+func deleteCardDAVCard(ctx context.Context, sourceID, uid string) error {
+	_, err := pool.Exec(ctx, `
+		DELETE FROM carddav_cards WHERE source_id = $1 AND lower(uid) = lower($2)
+	`, sourceID, uid)
+	if err != nil {
+		return fmt.Errorf("failed to forget CardDAV card: %w", err)
+	}
+
+	return nil
+}
+
+// removeCardDAVCard forgets a card deleted on the server and unlinks the
+// contacts pointing at it, keeping their local details
+func removeCardDAVCard(ctx context.Context, sourceID, uid string) (int, error) {
+	if err := deleteCardDAVCard(ctx, sourceID, uid); err != nil {
+		return 0, err
+	}
+
+	_, err := pool.Exec(ctx, `
+		DELETE FROM contact_carddav_conflicts
+		WHERE resolved_at IS NULL AND contact_id IN (
+			SELECT id FROM contacts WHERE carddav_source_id = $1 AND lower(carddav_uuid) = lower($2)
+		)
+	`, sourceID, uid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear conflicts of deleted CardDAV card: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, `
+		UPDATE contacts SET carddav_uuid = NULL, carddav_source_id = NULL, updated_at = now()
+		WHERE carddav_source_id = $1 AND lower(carddav_uuid) = lower($2)
+	`, sourceID, uid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unlink deleted CardDAV card: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func recordCardDAVSyncRun(ctx context.Context, sourceID uuid.UUID, run *CardDAVSyncRun) error {
+	errorMessages := run.Errors
+	if errorMessages == nil {
+		errorMessages = []string{}
+	}
+
+	err := pool.QueryRow(ctx, `
+		INSERT INTO carddav_sync_runs (
+			trigger, mode, status, cards_changed, contacts_updated, cards_deleted,
+			contacts_unlinked, contacts_in_conflict, errors, started_at, finished_at,
+			source_id, source_name
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`, run.Trigger, run.Mode, run.Status, run.CardsChanged, run.ContactsUpdated, run.CardsDeleted,
+		run.ContactsUnlinked, run.ContactsInConflict, errorMessages, run.StartedAt, run.FinishedAt,
+		sourceID, run.SourceName).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert CardDAV sync run: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		DELETE FROM carddav_sync_runs
+		WHERE id NOT IN (
+			SELECT id FROM carddav_sync_runs ORDER BY started_at DESC LIMIT $1
+		)
+	`, cardDAVSyncHistoryLimit)
+	if err != nil {
+		return fmt.Errorf("failed to prune CardDAV sync runs: %w", err)
+	}
+
+	return nil
+}
+
+// ListCardDAVSyncRuns returns the most recent sync runs, newest first
+func ListCardDAVSyncRuns(ctx context.Context, limit int) ([]CardDAVSyncRun, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, source_name, trigger, mode, status, cards_changed, contacts_updated, cards_deleted,
+			contacts_unlinked, contacts_in_conflict, errors, started_at, finished_at
+		FROM carddav_sync_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CardDAV sync runs: %w", err)
+	}
+
+	defer rows.Close()
+
+	var runs []CardDAVSyncRun
+
+	for rows.Next() {
+		var run CardDAVSyncRun
+		if err := rows.Scan(&run.ID, &run.SourceName, &run.Trigger, &run.Mode, &run.Status, &run.CardsChanged,
+			&run.ContactsUpdated, &run.CardsDeleted, &run.ContactsUnlinked, &run.ContactsInConflict, &run.Errors,
+			&run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan CardDAV sync run: %w", err)
+		}
+
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating CardDAV sync runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emersion/go-vcard"
+)
+
+func TestSyncCardDAVAppliesChangesAndDeletions(t *testing.T) {
+	resetDatabase(t)
+
+	server := newCardDAVTestServer(t)
+	defer server.close()
+
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldUID, "worker-1")
+	card.SetValue(vcard.FieldFormattedName, "Worker One")
+	card.AddName(&vcard.Name{GivenName: "Worker", FamilyName: "One"})
+	server.cards["worker-1.vcf"] = card
+
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
+
+	ctx := testContext()
+
+	carddavID := "worker-1"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Local", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
+
+	runs, err := SyncCardDAV(ctx, CardDAVSyncScheduled, false)
+	if err != nil {
+		t.Fatalf("SyncCardDAV failed: %v", err)
+	}
+
+	if len(runs) != 1 || runs[0].SourceName != "Default" {
+		t.Fatalf("expected one run of the source, got %+v", runs)
+	}
+
+	run := runs[0]
+	if run.Mode != CardDAVSyncFull || run.Status != CardDAVSyncSuccess || run.ContactsUpdated != 1 {
+		t.Fatalf("unexpected first run %+v", run)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.NameGiven == nil || *contact.NameGiven != "Worker" {
+		t.Fatalf("expected the card to be applied, got %v", contact.NameGiven)
+	}
+
+	server.mu.Lock()
+	delete(server.cards, "worker-1.vcf")
+	server.cards["other.vcf"] = vcard.Card{vcard.FieldUID: []*vcard.Field{{Value: "other"}}}
+	server.mu.Unlock()
+
+	runs, err = SyncCardDAV(ctx, CardDAVSyncManual, false)
+	if err != nil {
+		t.Fatalf("SyncCardDAV failed: %v", err)
+	}
+
+	if run = runs[0]; run.CardsDeleted != 1 || run.ContactsUnlinked != 1 {
+		t.Fatalf("expected the deleted card to unlink its contact, got %+v", run)
+	}
+
+	contact, err = GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.CardDAVUUID != nil || contact.CardDAVSourceID != nil || contact.NameGiven == nil || *contact.NameGiven != "Worker" {
+		t.Fatalf("expected contact to be unlinked but kept, got %+v", contact)
+	}
+
+	runs, err = ListCardDAVSyncRuns(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListCardDAVSyncRuns failed: %v", err)
+	}
+
+	if len(runs) != 2 || runs[0].Trigger != CardDAVSyncManual || runs[1].Trigger != CardDAVSyncScheduled || runs[0].SourceName != "Default" {
+		t.Fatalf("expected both runs newest first, got %+v", runs)
+	}
+
+	server.mu.Lock()
+	server.cards = map[string]vcard.Card{}
+	server.mu.Unlock()
+
+	// An empty listing never unlinks anything
+	if _, err := SyncCardDAV(ctx, CardDAVSyncManual, false); !errors.Is(err, ErrCardDAVAddressBookEmpty) {
+		t.Fatalf("expected ErrCardDAVAddressBookEmpty, got %v", err)
+	}
+}
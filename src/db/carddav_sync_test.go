@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/emersion/go-webdav/carddav"
+)
+
+func TestParseCardDAVCTag(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:response>
+    <d:href>/addressbook/</d:href>
+    <d:propstat>
+      <d:prop><cs:getctag>"ctag-42"</cs:getctag></d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`)
+
+	ctag, err := parseCardDAVCTag(body)
+	if err != nil {
+		t.Fatalf("parseCardDAVCTag failed: %v", err)
+	}
+
+	if ctag != `"ctag-42"` {
+		t.Fatalf("unexpected ctag %q", ctag)
+	}
+
+	missing := []byte(`<d:multistatus xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:response>
+    <d:href>/addressbook/</d:href>
+    <d:propstat>
+      <d:prop><cs:getctag/></d:prop>
+      <d:status>HTTP/1.1 404 Not Found</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`)
+
+	if ctag, err := parseCardDAVCTag(missing); err != nil || ctag != "" {
+		t.Fatalf("expected no ctag, got %q (%v)", ctag, err)
+	}
+}
+
+func TestDeletedCardDAVUIDs(t *testing.T) {
+	t.Parallel()
+
+	known := map[string]cardDAVCardState{
+		"/ab/a.vcf": {UID: "A", Path: "/ab/a.vcf"},
+		"/ab/b.vcf": {UID: "b", Path: "/ab/b.vcf"},
+	}
+	linked := map[string][]string{"a": {"c1"}, "gone": {"c2"}}
+
+	incremental := &cardDAVChanges{Deleted: []string{"/ab/b.vcf", "/ab/unknown.vcf"}}
+
+	deleted, err := deletedCardDAVUIDs(incremental, known, linked, map[string]bool{})
+	if err != nil {
+		t.Fatalf("deletedCardDAVUIDs failed: %v", err)
+	}
+
+	if !slices.Equal(deleted, []string{"b"}) {
+		t.Fatalf("expected only reported deletions, got %v", deleted)
+	}
+
+	complete := &cardDAVChanges{
+		Updated:  []carddav.AddressObject{{Path: "/ab/"}, {Path: "/ab/a.vcf"}},
+		Complete: true,
+	}
+
+	deleted, err = deletedCardDAVUIDs(complete, known, linked, map[string]bool{"a": true})
+	if err != nil {
+		t.Fatalf("deletedCardDAVUIDs failed: %v", err)
+	}
+
+	slices.Sort(deleted)
+
+	if !slices.Equal(deleted, []string{"b", "gone"}) {
+		t.Fatalf("expected unseen cards and links to be deleted, got %v", deleted)
+	}
+
+	empty := &cardDAVChanges{Updated: []carddav.AddressObject{{Path: "/ab/"}}, Complete: true}
+	if _, err := deletedCardDAVUIDs(empty, known, linked, map[string]bool{}); !errors.Is(err, ErrCardDAVAddressBookEmpty) {
+		t.Fatalf("expected ErrCardDAVAddressBookEmpty, got %v", err)
+	}
+}
+
+func TestHasUntrackedCardDAVLinks(t *testing.T) {
+	t.Parallel()
+
+	known := map[string]cardDAVCardState{"/ab/a.vcf": {UID: "A", Path: "/ab/a.vcf"}}
+
+	if hasUntrackedCardDAVLinks(known, map[string][]string{"a": {"c1"}}) {
+		t.Fatal("expected a tracked link to need no full sync")
+	}
+
+	if !hasUntrackedCardDAVLinks(known, map[string][]string{"a": {"c1"}, "b": {"c2"}}) {
+		t.Fatal("expected an untracked link to need a full sync")
+	}
+}
+
+func TestCardDAVSyncRunSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		run  CardDAVSyncRun
+		want string
+	}{
+		{run: CardDAVSyncRun{Mode: CardDAVSyncUnchanged}, want: "No changes on the server"},
+		{run: CardDAVSyncRun{Mode: CardDAVSyncIncremental}, want: "0 cards changed"},
+		{
+			run:  CardDAVSyncRun{Mode: CardDAVSyncFull, CardsChanged: 1, ContactsUpdated: 2, CardsDeleted: 1, ContactsUnlinked: 1},
+			want: "1 card changed, 2 contacts updated, 1 card deleted, 1 contact unlinked",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.run.Summary(); got != tt.want {
+			t.Fatalf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
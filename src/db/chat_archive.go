@@ -0,0 +1,567 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChatArchiveMessage is a single message read from an exported chat archive
+type ChatArchiveMessage struct {
+	MessageID  string
+	SentAt     time.Time
+	IsOutgoing bool
+	Message    string
+	// ReplyToMessageID is the archive ID of the message replied to
+	ReplyToMessageID string
+	Media            *ChatMedia
+}
+
+// ChatArchiveConversation is a direct conversation read from an exported
+// chat archive. Phones and Handles identify the other participant.
+type ChatArchiveConversation struct {
+	Title    string
+	Phones   []string
+	Handles  []string
+	Messages []ChatArchiveMessage
+}
+
+// ParseChatArchive reads the direct conversations of a Signal, Telegram or
+// Matrix export. Group conversations are left out, as their messages can't
+// be attributed to a single contact.
+func ParseChatArchive(platform ChatPlatform, r io.Reader) ([]ChatArchiveConversation, error) {
+	switch platform {
+	case ChatPlatformSignal:
+		return parseSignalArchive(r)
+	case ChatPlatformTelegram:
+		return parseTelegramArchive(r)
+	case ChatPlatformMatrix:
+		return parseMatrixArchive(r)
+	default:
+		return nil, ErrChatArchivePlatformUnsupported
+	}
+}
+
+// signalArchive is a Signal Desktop backup, the conversations and messages
+// JSON of its database
+type signalArchive struct {
+	Conversations []struct {
+		ID          string `json:"id"`
+		Type        string `json:"type"`
+		E164        string `json:"e164"`
+		ServiceID   string `json:"serviceId"`
+		UUID        string `json:"uuid"`
+		Username    string `json:"username"`
+		Name        string `json:"name"`
+		ProfileName string `json:"profileName"`
+	} `json:"conversations"`
+	Messages []struct {
+		ID             string `json:"id"`
+		ConversationID string `json:"conversationId"`
+		Type           string `json:"type"`
+		Body           string `json:"body"`
+		SentAt         int64  `json:"sent_at"`
+		Quote          *struct {
+			ID int64 `json:"id"`
+		} `json:"quote"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			FileName    string `json:"fileName"`
+			Size        int64  `json:"size"`
+			Flags       int    `json:"flags"`
+		} `json:"attachments"`
+		DeletedForEveryone bool `json:"deletedForEveryone"`
+	} `json:"messages"`
+}
+
+// signalVoiceMessageFlag marks an attachment recorded as a voice note
+const signalVoiceMessageFlag = 1
+
+func parseSignalArchive(r io.Reader) ([]ChatArchiveConversation, error) {
+	var archive signalArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to parse Signal backup: %w", err)
+	}
+
+	var order []string
+
+	conversations := make(map[string]*ChatArchiveConversation)
+
+	for _, conversation := range archive.Conversations {
+		if conversation.Type != "private" {
+			continue
+		}
+
+		parsed := &ChatArchiveConversation{Title: firstNonEmpty(conversation.Name, conversation.ProfileName, conversation.E164)}
+
+		if conversation.E164 != "" {
+			parsed.Phones = append(parsed.Phones, conversation.E164)
+		}
+
+		for _, handle := range []string{conversation.Username, conversation.ServiceID, conversation.UUID} {
+			if handle != "" {
+				parsed.Handles = append(parsed.Handles, handle)
+			}
+		}
+
+		conversations[conversation.ID] = parsed
+		order = append(order, conversation.ID)
+	}
+
+	// Quotes refer to the sent time of the quoted message
+	idsBySentAt := make(map[string]string)
+
+	for _, message := range archive.Messages {
+		idsBySentAt[message.ConversationID+":"+strconv.FormatInt(message.SentAt, 10)] = message.ID
+	}
+
+	for _, message := range archive.Messages {
+		conversation, ok := conversations[message.ConversationID]
+		if !ok || message.DeletedForEveryone || (message.Type != "incoming" && message.Type != "outgoing") {
+			continue
+		}
+
+		parsed := ChatArchiveMessage{
+			MessageID:  message.ID,
+			SentAt:     time.UnixMilli(message.SentAt).UTC(),
+			IsOutgoing: message.Type == "outgoing",
+			Message:    strings.TrimSpace(message.Body),
+		}
+
+		if message.Quote != nil {
+			parsed.ReplyToMessageID = idsBySentAt[message.ConversationID+":"+strconv.FormatInt(message.Quote.ID, 10)]
+		}
+
+		if len(message.Attachments) > 0 {
+			attachment := message.Attachments[0]
+			parsed.Media = &ChatMedia{
+				Type:     chatMediaTypeForMIME(attachment.ContentType),
+				MimeType: attachment.ContentType,
+				FileName: attachment.FileName,
+				Size:     attachment.Size,
+			}
+
+			if attachment.Flags&signalVoiceMessageFlag != 0 {
+				parsed.Media.Type = ChatMediaVoice
+			}
+		}
+
+		if parsed.Message == "" && parsed.Media == nil {
+			continue
+		}
+
+		conversation.Messages = append(conversation.Messages, parsed)
+	}
+
+	return collectChatArchiveConversations(order, conversations), nil
+}
+
+// telegramChat is one chat of a Telegram Desktop JSON export
+type telegramChat struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	ID       int64  `json:"id"`
+	Messages []struct {
+		ID               int64           `json:"id"`
+		Type             string          `json:"type"`
+		DateUnixtime     string          `json:"date_unixtime"`
+		Date             string          `json:"date"`
+		FromID           string          `json:"from_id"`
+		Text             json.RawMessage `json:"text"`
+		ReplyToMessageID int64           `json:"reply_to_message_id"`
+		Photo            string          `json:"photo"`
+		PhotoFileSize    int64           `json:"photo_file_size"`
+		File             string          `json:"file"`
+		FileName         string          `json:"file_name"`
+		FileSize         int64           `json:"file_size"`
+		MediaType        string          `json:"media_type"`
+		MimeType         string          `json:"mime_type"`
+	} `json:"messages"`
+}
+
+// telegramArchive is a Telegram Desktop JSON export, either of a single chat
+// or of the whole account
+type telegramArchive struct {
+	telegramChat
+
+	Contacts struct {
+		List []struct {
+			UserID      int64  `json:"user_id"`
+			PhoneNumber string `json:"phone_number"`
+		} `json:"list"`
+	} `json:"contacts"`
+	Chats struct {
+		List []telegramChat `json:"list"`
+	} `json:"chats"`
+}
+
+// telegramMediaTypes maps Telegram export media types to chat media types
+var telegramMediaTypes = map[string]ChatMediaType{
+	"voice_message": ChatMediaVoice,
+	"audio_file":    ChatMediaAudio,
+	"video_file":    ChatMediaVideo,
+	"video_message": ChatMediaVideo,
+	"animation":     ChatMediaVideo,
+	"sticker":       ChatMediaSticker,
+}
+
+func parseTelegramArchive(r io.Reader) ([]ChatArchiveConversation, error) {
+	var archive telegramArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to parse Telegram export: %w", err)
+	}
+
+	chats := archive.Chats.List
+	if len(chats) == 0 && archive.Messages != nil {
+		chats = []telegramChat{archive.telegramChat}
+	}
+
+	phones := make(map[int64]string)
+	for _, contact := range archive.Contacts.List {
+		phones[contact.UserID] = contact.PhoneNumber
+	}
+
+	var conversations []ChatArchiveConversation
+
+	for _, chat := range chats {
+		if chat.Type != "personal_chat" {
+			continue
+		}
+
+		conversation := ChatArchiveConversation{Title: chat.Name}
+		if phone := phones[chat.ID]; phone != "" {
+			conversation.Phones = append(conversation.Phones, phone)
+		}
+
+		// Message IDs are only unique within a chat
+		peer := "user" + strconv.FormatInt(chat.ID, 10)
+		messageID := func(id int64) string {
+			if id == 0 {
+				return ""
+			}
+
+			return strconv.FormatInt(chat.ID, 10) + ":" + strconv.FormatInt(id, 10)
+		}
+
+		for _, message := range chat.Messages {
+			if message.Type != "message" {
+				continue
+			}
+
+			sentAt, err := parseTelegramDate(message.DateUnixtime, message.Date)
+			if err != nil {
+				return nil, err
+			}
+
+			parsed := ChatArchiveMessage{
+				MessageID:        messageID(message.ID),
+				SentAt:           sentAt,
+				IsOutgoing:       message.FromID != peer,
+				Message:          strings.TrimSpace(telegramText(message.Text)),
+				ReplyToMessageID: messageID(message.ReplyToMessageID),
+			}
+
+			switch {
+			case message.Photo != "":
+				parsed.Media = &ChatMedia{Type: ChatMediaImage, MimeType: "image/jpeg", Size: message.PhotoFileSize}
+			case message.File != "" || message.MediaType != "":
+				mediaType, ok := telegramMediaTypes[message.MediaType]
+				if !ok {
+					mediaType = ChatMediaDocument
+				}
+
+				parsed.Media = &ChatMedia{Type: mediaType, MimeType: message.MimeType, FileName: message.FileName, Size: message.FileSize}
+			}
+
+			if parsed.Message == "" && parsed.Media == nil {
+				continue
+			}
+
+			conversation.Messages = append(conversation.Messages, parsed)
+		}
+
+		if len(conversation.Messages) > 0 {
+			conversations = append(conversations, conversation)
+		}
+	}
+
+	return conversations, nil
+}
+
+// parseTelegramDate reads a message time, preferring the unix time newer
+// exports include over the local time of older ones
+func parseTelegramDate(unixtime, date string) (time.Time, error) {
+	if unixtime != "" {
+		seconds, err := strconv.ParseInt(unixtime, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid Telegram message time %q: %w", unixtime, err)
+		}
+
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	parsed, err := time.ParseInLocation("2006-01-02T15:04:05", date, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Telegram message date %q: %w", date, err)
+	}
+
+	return parsed, nil
+}
+
+// telegramText flattens a message text, which is either a string or a list
+// of strings and formatted entities
+func telegramText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	for _, part := range parts {
+		var plain string
+		if err := json.Unmarshal(part, &plain); err == nil {
+			builder.WriteString(plain)
+			continue
+		}
+
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &entity); err == nil {
+			builder.WriteString(entity.Text)
+		}
+	}
+
+	return builder.String()
+}
+
+// matrixEvent is a room event of a Matrix room export
+type matrixEvent struct {
+	Type           string `json:"type"`
+	EventID        string `json:"event_id"`
+	Sender         string `json:"sender"`
+	StateKey       string `json:"state_key"`
+	OriginServerTS int64  `json:"origin_server_ts"`
+	Content        struct {
+		MsgType    string          `json:"msgtype"`
+		Body       string          `json:"body"`
+		FileName   string          `json:"filename"`
+		Membership string          `json:"membership"`
+		Voice      json.RawMessage `json:"org.matrix.msc3245.voice"`
+		Info       struct {
+			MimeType string `json:"mimetype"`
+			Size     int64  `json:"size"`
+		} `json:"info"`
+		RelatesTo struct {
+			RelType   string `json:"rel_type"`
+			EventID   string `json:"event_id"`
+			InReplyTo struct {
+				EventID string `json:"event_id"`
+			} `json:"m.in_reply_to"`
+		} `json:"m.relates_to"`
+		NewContent *struct {
+			Body string `json:"body"`
+		} `json:"m.new_content"`
+	} `json:"content"`
+	Unsigned struct {
+		RedactedBecause json.RawMessage `json:"redacted_because"`
+	} `json:"unsigned"`
+}
+
+// matrixArchive is a room export as saved by Element
+type matrixArchive struct {
+	RoomName   string        `json:"room_name"`
+	ExportedBy string        `json:"exported_by"`
+	Messages   []matrixEvent `json:"messages"`
+}
+
+// matrixMediaTypes maps Matrix message types to chat media types
+var matrixMediaTypes = map[string]ChatMediaType{
+	"m.image": ChatMediaImage,
+	"m.video": ChatMediaVideo,
+	"m.audio": ChatMediaAudio,
+	"m.file":  ChatMediaDocument,
+}
+
+func parseMatrixArchive(r io.Reader) ([]ChatArchiveConversation, error) {
+	var archive matrixArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to parse Matrix export: %w", err)
+	}
+
+	if archive.ExportedBy == "" {
+		return nil, ErrChatArchiveExporterMissing
+	}
+
+	// The room is direct when it has a single member besides the exporter
+	members := make(map[string]bool)
+
+	for _, event := range archive.Messages {
+		switch {
+		case event.Type == "m.room.member" && event.Content.Membership == "join":
+			members[event.StateKey] = true
+		case event.Type == "m.room.message" || event.Type == "m.sticker":
+			members[event.Sender] = true
+		}
+	}
+
+	delete(members, archive.ExportedBy)
+
+	if len(members) != 1 {
+		return nil, nil
+	}
+
+	conversation := ChatArchiveConversation{Title: archive.RoomName}
+	for member := range members {
+		conversation.Handles = append(conversation.Handles, member)
+	}
+
+	edits := make(map[string]string)
+
+	for _, event := range archive.Messages {
+		if event.Content.RelatesTo.RelType == "m.replace" && event.Content.NewContent != nil {
+			edits[event.Content.RelatesTo.EventID] = event.Content.NewContent.Body
+		}
+	}
+
+	for _, event := range archive.Messages {
+		if (event.Type != "m.room.message" && event.Type != "m.sticker") ||
+			event.Content.RelatesTo.RelType == "m.replace" || len(event.Unsigned.RedactedBecause) > 0 {
+			continue
+		}
+
+		parsed := ChatArchiveMessage{
+			MessageID:        event.EventID,
+			SentAt:           time.UnixMilli(event.OriginServerTS).UTC(),
+			IsOutgoing:       event.Sender == archive.ExportedBy,
+			ReplyToMessageID: event.Content.RelatesTo.InReplyTo.EventID,
+		}
+
+		body := event.Content.Body
+		if edited, ok := edits[event.EventID]; ok {
+			body = edited
+		}
+
+		if parsed.ReplyToMessageID != "" {
+			body = stripMatrixReplyFallback(body)
+		}
+
+		mediaType, isMedia := matrixMediaTypes[event.Content.MsgType]
+		if event.Type == "m.sticker" {
+			mediaType, isMedia = ChatMediaSticker, true
+		}
+
+		if isMedia {
+			if mediaType == ChatMediaAudio && len(event.Content.Voice) > 0 {
+				mediaType = ChatMediaVoice
+			}
+
+			// The body is the file name, unless a separate file name
+			// makes it a caption
+			fileName := event.Content.FileName
+			if fileName == "" || fileName == body {
+				fileName, body = body, ""
+			}
+
+			parsed.Media = &ChatMedia{
+				Type:     mediaType,
+				MimeType: event.Content.Info.MimeType,
+				FileName: fileName,
+				Size:     event.Content.Info.Size,
+			}
+		}
+
+		parsed.Message = strings.TrimSpace(body)
+		if parsed.Message == "" && parsed.Media == nil {
+			continue
+		}
+
+		conversation.Messages = append(conversation.Messages, parsed)
+	}
+
+	if len(conversation.Messages) == 0 {
+		return nil, nil
+	}
+
+	return []ChatArchiveConversation{conversation}, nil
+}
+
+// stripMatrixReplyFallback drops the quoted lines clients prepend to the
+// body of a reply
+func stripMatrixReplyFallback(body string) string {
+	if !strings.HasPrefix(body, "> ") {
+		return body
+	}
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, ">") {
+			return strings.Join(lines[i:], "\n")
+		}
+	}
+
+	return ""
+}
+
+// chatMediaTypeForMIME guesses the media type of an attachment from its MIME type
+func chatMediaTypeForMIME(mimeType string) ChatMediaType {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return ChatMediaImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return ChatMediaVideo
+	case strings.HasPrefix(mimeType, "audio/"):
+		return ChatMediaAudio
+	default:
+		return ChatMediaDocument
+	}
+}
+
+// collectChatArchiveConversations returns the conversations with messages
+// in their original order, each sorted by time
+func collectChatArchiveConversations(order []string, conversations map[string]*ChatArchiveConversation) []ChatArchiveConversation {
+	var collected []ChatArchiveConversation
+
+	for _, id := range order {
+		conversation := conversations[id]
+		if len(conversation.Messages) == 0 {
+			continue
+		}
+
+		sort.SliceStable(conversation.Messages, func(i, j int) bool {
+			return conversation.Messages[i].SentAt.Before(conversation.Messages[j].SentAt)
+		})
+
+		collected = append(collected, *conversation)
+	}
+
+	return collected
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSignalArchive(t *testing.T) {
+	t.Parallel()
+
+	archive := `{
+		"conversations": [
+			{"id": "c1", "type": "private", "e164": "+971501234567", "name": "Sara", "username": "sara.01"},
+			{"id": "g1", "type": "group", "name": "Family"}
+		],
+		"messages": [
+			{"id": "m2", "conversationId": "c1", "type": "outgoing", "body": "Sure", "sent_at": 1700000060000, "quote": {"id": 1700000000000}},
+			{"id": "m1", "conversationId": "c1", "type": "incoming", "body": "Coffee?", "sent_at": 1700000000000},
+			{"id": "m3", "conversationId": "c1", "type": "incoming", "sent_at": 1700000120000,
+				"attachments": [{"contentType": "audio/aac", "size": 2048, "flags": 1}]},
+			{"id": "m4", "conversationId": "c1", "type": "keychange", "sent_at": 1700000130000},
+			{"id": "m5", "conversationId": "g1", "type": "incoming", "body": "Hi all", "sent_at": 1700000140000}
+		]
+	}`
+
+	conversations, err := ParseChatArchive(ChatPlatformSignal, strings.NewReader(archive))
+	if err != nil {
+		t.Fatalf("ParseChatArchive failed: %v", err)
+	}
+
+	if len(conversations) != 1 {
+		t.Fatalf("expected only the direct conversation, got %+v", conversations)
+	}
+
+	conversation := conversations[0]
+	if conversation.Title != "Sara" || len(conversation.Phones) != 1 || conversation.Phones[0] != "+971501234567" || conversation.Handles[0] != "sara.01" {
+		t.Fatalf("unexpected participant %+v", conversation)
+	}
+
+	messages := conversation.Messages
+	if len(messages) != 3 || messages[0].MessageID != "m1" || messages[0].IsOutgoing {
+		t.Fatalf("expected messages in time order, got %+v", messages)
+	}
+
+	if !messages[1].IsOutgoing || messages[1].ReplyToMessageID != "m1" {
+		t.Fatalf("expected an outgoing reply to m1, got %+v", messages[1])
+	}
+
+	if media := messages[2].Media; media == nil || media.Type != ChatMediaVoice || media.Size != 2048 {
+		t.Fatalf("expected a voice note, got %+v", messages[2].Media)
+	}
+
+	if !messages[0].SentAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Fatalf("unexpected sent time %v", messages[0].SentAt)
+	}
+}
+
+func TestParseTelegramArchive(t *testing.T) {
+	t.Parallel()
+
+	single := `{
+		"name": "Omar",
+		"type": "personal_chat",
+		"id": 4242,
+		"messages": [
+			{"id": 1, "type": "service", "date_unixtime": "1700000000", "actor_id": "user4242"},
+			{"id": 2, "type": "message", "date_unixtime": "1700000010", "from_id": "user4242",
+				"text": ["See ", {"type": "link", "text": "https://example.com"}]},
+			{"id": 3, "type": "message", "date_unixtime": "1700000020", "from_id": "user1", "text": "Thanks", "reply_to_message_id": 2},
+			{"id": 4, "type": "message", "date_unixtime": "1700000030", "from_id": "user4242", "text": "",
+				"file": "voice_messages/audio_1.ogg", "media_type": "voice_message", "mime_type": "audio/ogg", "file_size": 5120}
+		]
+	}`
+
+	conversations, err := ParseChatArchive(ChatPlatformTelegram, strings.NewReader(single))
+	if err != nil {
+		t.Fatalf("ParseChatArchive failed: %v", err)
+	}
+
+	if len(conversations) != 1 || len(conversations[0].Messages) != 3 {
+		t.Fatalf("unexpected conversations %+v", conversations)
+	}
+
+	messages := conversations[0].Messages
+	if messages[0].Message != "See https://example.com" || messages[0].IsOutgoing || messages[0].MessageID != "4242:2" {
+		t.Fatalf("unexpected first message %+v", messages[0])
+	}
+
+	if !messages[1].IsOutgoing || messages[1].ReplyToMessageID != "4242:2" {
+		t.Fatalf("expected an outgoing reply, got %+v", messages[1])
+	}
+
+	if media := messages[2].Media; media == nil || media.Type != ChatMediaVoice || media.MimeType != "audio/ogg" {
+		t.Fatalf("expected a voice note, got %+v", messages[2].Media)
+	}
+
+	full := `{
+		"contacts": {"list": [{"user_id": 4242, "phone_number": "+44 7700 900123"}]},
+		"chats": {"list": [
+			{"name": "Omar", "type": "personal_chat", "id": 4242, "messages": [
+				{"id": 1, "type": "message", "date_unixtime": "1700000010", "from_id": "user4242", "text": "Hi"}
+			]},
+			{"name": "Club", "type": "private_group", "id": 77, "messages": [
+				{"id": 1, "type": "message", "date_unixtime": "1700000010", "from_id": "user4242", "text": "Hi all"}
+			]}
+		]}
+	}`
+
+	conversations, err = ParseChatArchive(ChatPlatformTelegram, strings.NewReader(full))
+	if err != nil {
+		t.Fatalf("ParseChatArchive failed: %v", err)
+	}
+
+	if len(conversations) != 1 || len(conversations[0].Phones) != 1 || conversations[0].Phones[0] != "+44 7700 900123" {
+		t.Fatalf("expected the personal chat with its phone, got %+v", conversations)
+	}
+}
+
+func TestParseMatrixArchive(t *testing.T) {
+	t.Parallel()
+
+	direct := `{
+		"room_name": "Lena",
+		"exported_by": "@me:example.org",
+		"messages": [
+			{"type": "m.room.member", "state_key": "@lena:matrix.org", "sender": "@lena:matrix.org", "content": {"membership": "join"}},
+			{"type": "m.room.message", "event_id": "$a", "sender": "@lena:matrix.org", "origin_server_ts": 1700000000000,
+				"content": {"msgtype": "m.text", "body": "Are you around?"}},
+			{"type": "m.room.message", "event_id": "$b", "sender": "@me:example.org", "origin_server_ts": 1700000060000,
+				"content": {"msgtype": "m.text", "body": "> <@lena:matrix.org> Are you around?\n\nYes", "m.relates_to": {"m.in_reply_to": {"event_id": "$a"}}}},
+			{"type": "m.room.message", "event_id": "$c", "sender": "@me:example.org", "origin_server_ts": 1700000070000,
+				"content": {"msgtype": "m.text", "body": "* Yes!", "m.new_content": {"msgtype": "m.text", "body": "Yes!"}, "m.relates_to": {"rel_type": "m.replace", "event_id": "$b"}}},
+			{"type": "m.room.message", "event_id": "$d", "sender": "@lena:matrix.org", "origin_server_ts": 1700000080000,
+				"content": {"msgtype": "m.image", "body": "Look at this", "filename": "view.jpg", "info": {"mimetype": "image/jpeg", "size": 9000}}},
+			{"type": "m.room.message", "event_id": "$e", "sender": "@lena:matrix.org", "origin_server_ts": 1700000090000,
+				"content": {}, "unsigned": {"redacted_because": {"type": "m.room.redaction"}}}
+		]
+	}`
+
+	conversations, err := ParseChatArchive(ChatPlatformMatrix, strings.NewReader(direct))
+	if err != nil {
+		t.Fatalf("ParseChatArchive failed: %v", err)
+	}
+
+	if len(conversations) != 1 || conversations[0].Handles[0] != "@lena:matrix.org" {
+		t.Fatalf("unexpected conversations %+v", conversations)
+	}
+
+	messages := conversations[0].Messages
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %+v", messages)
+	}
+
+	if !messages[1].IsOutgoing || messages[1].Message != "Yes!" || messages[1].ReplyToMessageID != "$a" {
+		t.Fatalf("expected the edited reply without its fallback, got %+v", messages[1])
+	}
+
+	if media := messages[2].Media; media == nil || media.Type != ChatMediaImage || media.FileName != "view.jpg" || messages[2].Message != "Look at this" {
+		t.Fatalf("expected a captioned image, got %+v %+v", messages[2], messages[2].Media)
+	}
+
+	group := `{
+		"room_name": "Team",
+		"exported_by": "@me:example.org",
+		"messages": [
+			{"type": "m.room.message", "event_id": "$a", "sender": "@a:example.org", "content": {"msgtype": "m.text", "body": "Hi"}},
+			{"type": "m.room.message", "event_id": "$b", "sender": "@b:example.org", "content": {"msgtype": "m.text", "body": "Hey"}}
+		]
+	}`
+
+	conversations, err = ParseChatArchive(ChatPlatformMatrix, strings.NewReader(group))
+	if err != nil || len(conversations) != 0 {
+		t.Fatalf("expected group rooms to be skipped, got %+v %v", conversations, err)
+	}
+
+	if _, err := ParseChatArchive(ChatPlatformMatrix, strings.NewReader(`{"messages": []}`)); !errors.Is(err, ErrChatArchiveExporterMissing) {
+		t.Fatalf("expected ErrChatArchiveExporterMissing, got %v", err)
+	}
+
+	if _, err := ParseChatArchive(ChatPlatformWhatsApp, strings.NewReader(`{}`)); !errors.Is(err, ErrChatArchivePlatformUnsupported) {
+		t.Fatalf("expected ErrChatArchivePlatformUnsupported, got %v", err)
+	}
+}
+
+func TestNormalizeChatHandle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"@Alice":                                 "alice",
+		"https://t.me/Alice":                     "alice",
+		"https://t.me/alice/":                    "alice",
+		"https://matrix.to/#/@lena:matrix.org":   "lena:matrix.org",
+		"@lena:Matrix.org":                       "lena:matrix.org",
+		"https://matrix.to/#/@lena:matrix.org?x": "lena:matrix.org",
+		"  sara.01 ":                             "sara.01",
+	}
+
+	for input, want := range tests {
+		if got := normalizeChatHandle(input); got != want {
+			t.Fatalf("normalizeChatHandle(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
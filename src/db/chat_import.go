@@ -0,0 +1,305 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// chatPlatformURLTypes maps chat platforms to the contact link type that
+// holds a participant's handle
+var chatPlatformURLTypes = map[ChatPlatform]URLType{
+	ChatPlatformSignal:   URLSignal,
+	ChatPlatformTelegram: URLTelegram,
+	ChatPlatformMatrix:   URLMatrix,
+}
+
+// ChatArchiveImport summarises an imported chat archive
+type ChatArchiveImport struct {
+	Conversations int      // Conversations matched to a contact
+	Imported      int      // Messages added to contact chats
+	Duplicates    int      // Messages already in contact chats
+	Unmatched     []string // Titles of conversations matching no contact
+}
+
+// ImportChatArchive adds the messages of exported conversations to the chats
+// of their contacts and moves last_auto_contact up to the latest message.
+// Conversations are matched by the other participant's phone or handle,
+// unless contactID names the contact all of them belong to.
+func ImportChatArchive(ctx context.Context, platform ChatPlatform, conversations []ChatArchiveConversation, contactID string) (ChatArchiveImport, error) {
+	var result ChatArchiveImport
+
+	if pool == nil {
+		return result, ErrDatabaseConnectionNotInitialized
+	}
+
+	if _, ok := chatPlatformURLTypes[platform]; !ok {
+		return result, ErrChatArchivePlatformUnsupported
+	}
+
+	if contactID = strings.TrimSpace(contactID); contactID != "" {
+		parsedID, err := uuid.Parse(contactID)
+		if err != nil {
+			return result, ErrContactNotFound
+		}
+
+		contactID = parsedID.String()
+	}
+
+	var (
+		handles      map[string]string
+		cardDAVIndex *cardDAVPhoneIndex
+	)
+
+	for _, conversation := range conversations {
+		matchedID := contactID
+
+		if matchedID == "" {
+			if handles == nil {
+				var err error
+
+				handles, err = loadChatHandleIndex(ctx, chatPlatformURLTypes[platform])
+				if err != nil {
+					return result, err
+				}
+			}
+
+			var err error
+
+			matchedID, cardDAVIndex, err = matchChatArchiveConversation(ctx, conversation, handles, cardDAVIndex)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		if matchedID == "" {
+			result.Unmatched = append(result.Unmatched, conversation.Title)
+			continue
+		}
+
+		imported, duplicates, err := importContactChatHistory(ctx, matchedID, platform, conversation.Messages)
+		if err != nil {
+			return result, err
+		}
+
+		result.Conversations++
+		result.Imported += imported
+		result.Duplicates += duplicates
+	}
+
+	return result, nil
+}
+
+// matchChatArchiveConversation finds the contact of a conversation by handle
+// first, then by phone. The CardDAV phone index is loaded on first use and
+// returned for the next conversations.
+func matchChatArchiveConversation(ctx context.Context, conversation ChatArchiveConversation, handles map[string]string, cardDAVIndex *cardDAVPhoneIndex) (string, *cardDAVPhoneIndex, error) {
+	for _, handle := range conversation.Handles {
+		if contactID := handles[normalizeChatHandle(handle)]; contactID != "" {
+			return contactID, cardDAVIndex, nil
+		}
+	}
+
+	for _, phone := range conversation.Phones {
+		normalized := normalizePhone(phone)
+		if normalized == "" {
+			continue
+		}
+
+		contactID, err := findLocalContactByPhone(ctx, normalized)
+		if err != nil {
+			return "", cardDAVIndex, err
+		}
+
+		if contactID != "" {
+			return contactID, cardDAVIndex, nil
+		}
+
+		if cardDAVIndex == nil {
+			cardDAVIndex, err = loadCardDAVPhoneIndex(ctx)
+			if err != nil {
+				logger.Warn("Failed to load CardDAV phones for chat import", "error", err)
+
+				cardDAVIndex = &cardDAVPhoneIndex{}
+			}
+		}
+
+		if contactID := cardDAVIndex.find(normalized); contactID != "" {
+			return contactID, cardDAVIndex, nil
+		}
+	}
+
+	return "", cardDAVIndex, nil
+}
+
+// loadChatHandleIndex maps the normalized handles of a link type to their
+// contacts, preferring higher tiers when a handle is shared
+func loadChatHandleIndex(ctx context.Context, urlType URLType) (map[string]string, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT u.url, c.id
+		FROM contact_urls u
+		JOIN contacts c ON c.id = u.contact_id
+		WHERE u.url_type = $1
+		ORDER BY
+			CASE c.tier
+				WHEN 'A' THEN 1
+				WHEN 'B' THEN 2
+				WHEN 'C' THEN 3
+				WHEN 'D' THEN 4
+				WHEN 'E' THEN 5
+				WHEN 'F' THEN 6
+			END,
+			c.created_at
+	`, urlType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contact handles: %w", err)
+	}
+	defer rows.Close()
+
+	handles := make(map[string]string)
+
+	for rows.Next() {
+		var url, contactID string
+		if err := rows.Scan(&url, &contactID); err != nil {
+			return nil, fmt.Errorf("failed to scan contact handle: %w", err)
+		}
+
+		handle := normalizeChatHandle(url)
+		if _, ok := handles[handle]; handle != "" && !ok {
+			handles[handle] = contactID
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate contact handles: %w", err)
+	}
+
+	return handles, nil
+}
+
+// normalizeChatHandle reduces a handle or profile link, such as
+// https://t.me/name, https://matrix.to/#/@name:server or @name, to the
+// lowercase handle without its leading @
+func normalizeChatHandle(value string) string {
+	handle := strings.ToLower(strings.TrimSpace(value))
+
+	if index := strings.Index(handle, "#/"); index >= 0 {
+		handle = handle[index+2:]
+	} else if index := strings.Index(handle, "://"); index >= 0 {
+		handle = strings.Trim(handle[index+3:], "/")
+		if slash := strings.LastIndex(handle, "/"); slash >= 0 {
+			handle = handle[slash+1:]
+		}
+	}
+
+	if query := strings.IndexAny(handle, "?#"); query >= 0 {
+		handle = handle[:query]
+	}
+
+	return strings.TrimPrefix(handle, "@")
+}
+
+// importContactChatHistory adds past messages to a contact's chats and
+// returns how many were imported and how many were duplicates
+func importContactChatHistory(ctx context.Context, contactID string, platform ChatPlatform, messages []ChatArchiveMessage) (int, int, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back chat history transaction", "error", err)
+		}
+	}()
+
+	var isService bool
+
+	err = tx.QueryRow(ctx, `SELECT is_service FROM contacts WHERE id = $1`, contactID).Scan(&isService)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrContactNotFound
+	} else if err != nil {
+		return 0, 0, fmt.Errorf("failed to load contact: %w", err)
+	}
+
+	var (
+		imported, duplicates int
+		latest               time.Time
+	)
+
+	for _, message := range messages {
+		if message.SentAt.After(latest) {
+			latest = message.SentAt
+		}
+
+		// Service contacts keep no chat history, like live messages
+		text := strings.TrimSpace(message.Message)
+		if isService || (text == "" && message.Media == nil) {
+			continue
+		}
+
+		media := message.Media
+		if media == nil {
+			media = &ChatMedia{}
+		}
+
+		sender := ChatSenderThem
+		if message.IsOutgoing {
+			sender = ChatSenderMe
+		}
+
+		// Chats recorded before message IDs were kept are matched on their
+		// content and time instead
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO contact_chats (
+				contact_id, platform, sender, message, sent_at, message_id, reply_to_message_id,
+				media_type, media_mime_type, media_file_name, media_size
+			)
+			SELECT $1::uuid, $2::chat_platform, $3::chat_sender, $4::text, $5::timestamptz,
+				NULLIF($6::text, ''), NULLIF($7::text, ''),
+				NULLIF($8::text, ''), NULLIF($9::text, ''), NULLIF($10::text, ''), NULLIF($11::bigint, 0)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM contact_chats
+				WHERE contact_id = $1::uuid AND platform = $2::chat_platform AND message_id IS NULL
+					AND sender = $3::chat_sender AND message = $4::text AND sent_at = $5::timestamptz
+			)
+			ON CONFLICT (platform, message_id) WHERE message_id IS NOT NULL DO NOTHING
+		`, contactID, platform, sender, text, message.SentAt, message.MessageID, message.ReplyToMessageID,
+			string(media.Type), media.MimeType, media.FileName, media.Size)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to import chat message: %w", err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			duplicates++
+		} else {
+			imported++
+		}
+	}
+
+	if !latest.IsZero() {
+		_, err = tx.Exec(ctx, `
+			UPDATE contacts
+			SET last_auto_contact = GREATEST(last_auto_contact, $2), updated_at = NOW()
+			WHERE id = $1
+		`, contactID, latest)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to update auto contact timestamp: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit chat history: %w", err)
+	}
+
+	return imported, duplicates, nil
+}
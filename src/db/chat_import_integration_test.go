@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestImportChatArchive(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	first := time.Date(2025, 2, 3, 18, 0, 0, 0, time.UTC)
+
+	lenaID := mustCreateContact(t, CreateContactInput{NameGiven: "Lena", Tier: TierB})
+	if err := AddURL(ctx, AddURLInput{ContactID: lenaID, URL: "https://matrix.to/#/@lena:matrix.org", URLType: URLMatrix}); err != nil {
+		t.Fatalf("AddURL failed: %v", err)
+	}
+
+	phone := "+44 7700 900123"
+	omarID := mustCreateContact(t, CreateContactInput{NameGiven: "Omar", Phone: &phone, Tier: TierC})
+
+	conversations := []ChatArchiveConversation{
+		{Title: "Lena", Handles: []string{"@Lena:matrix.org"}, Messages: []ChatArchiveMessage{
+			{MessageID: "$a", SentAt: first, Message: "Are you around?"},
+			{MessageID: "$b", SentAt: first.Add(time.Minute), IsOutgoing: true, Message: "Yes", ReplyToMessageID: "$a"},
+		}},
+		{Title: "Omar", Phones: []string{"447700900123"}, Messages: []ChatArchiveMessage{
+			{MessageID: "$c", SentAt: first.Add(time.Hour), Media: &ChatMedia{Type: ChatMediaImage, MimeType: "image/jpeg"}},
+		}},
+		{Title: "Stranger", Handles: []string{"@nobody:example.org"}, Messages: []ChatArchiveMessage{
+			{MessageID: "$d", SentAt: first, Message: "Hello?"},
+		}},
+	}
+
+	result, err := ImportChatArchive(ctx, ChatPlatformMatrix, conversations, "")
+	if err != nil {
+		t.Fatalf("ImportChatArchive failed: %v", err)
+	}
+
+	if result.Conversations != 2 || result.Imported != 3 || result.Duplicates != 0 || len(result.Unmatched) != 1 || result.Unmatched[0] != "Stranger" {
+		t.Fatalf("unexpected import result %+v", result)
+	}
+
+	// Importing the same archive again adds nothing
+	result, err = ImportChatArchive(ctx, ChatPlatformMatrix, conversations, "")
+	if err != nil {
+		t.Fatalf("ImportChatArchive failed: %v", err)
+	}
+
+	if result.Imported != 0 || result.Duplicates != 3 {
+		t.Fatalf("expected a repeated import to only find duplicates, got %+v", result)
+	}
+
+	chats, err := GetContactChats(ctx, lenaID)
+	if err != nil {
+		t.Fatalf("GetContactChats failed: %v", err)
+	}
+
+	if len(chats) != 2 || chats[0].Platform != ChatPlatformMatrix || chats[0].Sender != ChatSenderMe || pointerString(chats[0].ReplyToMessage) != "Are you around?" {
+		t.Fatalf("unexpected chats %+v", chats)
+	}
+
+	contact, err := GetContact(ctx, omarID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.LastAutoContact == nil || !contact.LastAutoContact.Equal(first.Add(time.Hour)) {
+		t.Fatalf("expected last auto contact at the latest message, got %v", contact.LastAutoContact)
+	}
+
+	// A chosen contact takes every conversation
+	result, err = ImportChatArchive(ctx, ChatPlatformTelegram, []ChatArchiveConversation{
+		{Title: "Omar T", Messages: []ChatArchiveMessage{{MessageID: "1:1", SentAt: first, Message: "Hi"}}},
+	}, omarID)
+	if err != nil || result.Conversations != 1 || result.Imported != 1 {
+		t.Fatalf("expected the chosen contact to be used, got %+v %v", result, err)
+	}
+
+	if _, err := ImportChatArchive(ctx, ChatPlatformTelegram, conversations, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound, got %v", err)
+	}
+
+	if _, err := ImportChatArchive(ctx, ChatPlatformSlack, conversations, ""); !errors.Is(err, ErrChatArchivePlatformUnsupported) {
+		t.Fatalf("expected ErrChatArchivePlatformUnsupported, got %v", err)
+	}
+}
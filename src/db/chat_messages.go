@@ -0,0 +1,202 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var unsafeMediaNameRegex = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// chatMediaExtensions maps common attachment MIME types to the extension
+// their downloads are stored with
+var chatMediaExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"image/gif":       ".gif",
+	"video/mp4":       ".mp4",
+	"video/3gpp":      ".3gp",
+	"audio/ogg":       ".ogg",
+	"audio/mpeg":      ".mp3",
+	"audio/mp4":       ".m4a",
+	"audio/aac":       ".aac",
+	"application/pdf": ".pdf",
+}
+
+// EditChatMessage replaces the text of a chat, or of a message still queued
+// in the WhatsApp inbox, identified by its platform message ID
+func EditChatMessage(ctx context.Context, platform ChatPlatform, messageID, message string, editedAt time.Time) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	message = strings.TrimSpace(message)
+	if messageID == "" || message == "" {
+		return ErrChatMessageRequired
+	}
+
+	result, err := pool.Exec(ctx, `
+		UPDATE contact_chats SET message = $3, edited_at = $4
+		WHERE platform = $1 AND message_id = $2
+	`, platform, messageID, message, editedAt)
+	if err != nil {
+		return fmt.Errorf("failed to edit chat: %w", err)
+	}
+
+	if result.RowsAffected() > 0 || platform != ChatPlatformWhatsApp {
+		return nil
+	}
+
+	_, err = pool.Exec(ctx, `
+		UPDATE whatsapp_unknown_messages SET message = $2 WHERE message_id = $1
+	`, messageID, message)
+	if err != nil {
+		return fmt.Errorf("failed to edit queued WhatsApp message: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeChatMessage marks a chat deleted by its sender. The text is kept so
+// the history stays complete; messages still queued in the WhatsApp inbox
+// are dropped instead.
+func RevokeChatMessage(ctx context.Context, platform ChatPlatform, messageID string, revokedAt time.Time) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if messageID == "" {
+		return ErrChatEntryNotFound
+	}
+
+	result, err := pool.Exec(ctx, `
+		UPDATE contact_chats SET revoked_at = $3
+		WHERE platform = $1 AND message_id = $2 AND revoked_at IS NULL
+	`, platform, messageID, revokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke chat: %w", err)
+	}
+
+	if result.RowsAffected() > 0 || platform != ChatPlatformWhatsApp {
+		return nil
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM whatsapp_unknown_messages WHERE message_id = $1`, messageID); err != nil {
+		return fmt.Errorf("failed to drop revoked WhatsApp message: %w", err)
+	}
+
+	return nil
+}
+
+// ChatMessageExists reports whether a chat with the given platform message
+// ID is already stored
+func ChatMessageExists(ctx context.Context, platform ChatPlatform, messageID string) (bool, error) {
+	if pool == nil {
+		return false, ErrDatabaseConnectionNotInitialized
+	}
+
+	if messageID == "" {
+		return false, nil
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM contact_chats WHERE platform = $1 AND message_id = $2)
+	`, platform, messageID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check chat message: %w", err)
+	}
+
+	return exists, nil
+}
+
+// chatMediaExtension picks the file extension for a downloaded attachment
+func chatMediaExtension(media ChatMedia) string {
+	if ext := strings.ToLower(path.Ext(media.FileName)); ext != "" && !unsafeMediaNameRegex.MatchString(ext[1:]) {
+		return ext
+	}
+
+	mimeType, _, err := mime.ParseMediaType(media.MimeType)
+	if err != nil {
+		return ".bin"
+	}
+
+	if ext, ok := chatMediaExtensions[mimeType]; ok {
+		return ext
+	}
+
+	return ".bin"
+}
+
+// chatMediaPath returns where an attachment of a contact's chat is stored
+// in WebDAV, in a folder per contact next to the uploaded photos
+func chatMediaPath(contactID, messageID string, media ChatMedia) string {
+	name := unsafeMediaNameRegex.ReplaceAllString(messageID, "_")
+
+	return path.Join(ContactPhotosDir, contactID, name+chatMediaExtension(media))
+}
+
+// StoreChatMedia uploads a downloaded chat attachment to the contact's media
+// folder and returns its path under the WebDAV files root
+func StoreChatMedia(ctx context.Context, contactID, messageID string, media ChatMedia, data []byte) (string, error) {
+	parsedID, err := uuid.Parse(strings.TrimSpace(contactID))
+	if err != nil {
+		return "", ErrContactNotFound
+	}
+
+	contactID = parsedID.String()
+
+	if messageID == "" || len(data) == 0 {
+		return "", ErrChatEntryNotFound
+	}
+
+	config, err := GetWebDAVConfig()
+	if err != nil {
+		return "", err
+	}
+
+	if config.FilesPath == "" {
+		return "", ErrWebDAVFilesPathNotConfigured
+	}
+
+	if err := ensureContactPhotosDir(ctx, config); err != nil {
+		return "", err
+	}
+
+	client, err := newFilesWebDAVClient(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create WebDAV client: %w", err)
+	}
+
+	contactDir := path.Join(ContactPhotosDir, contactID)
+
+	exists, err := filesEntryExists(ctx, client, contactDir)
+	if err != nil {
+		return "", err
+	}
+
+	if !exists {
+		if err := client.Mkdir(ctx, contactDir); err != nil {
+			return "", fmt.Errorf("failed to create contact media directory: %w", err)
+		}
+	}
+
+	mediaPath := chatMediaPath(contactID, messageID, media)
+
+	if err := putFilesEntry(ctx, config, mediaPath, bytes.NewReader(data), int64(len(data))); err != nil {
+		return "", fmt.Errorf("failed to upload chat media: %w", err)
+	}
+
+	return mediaPath, nil
+}
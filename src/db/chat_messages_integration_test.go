@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChatRepliesMediaEditsAndRevokes(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Layla", Tier: TierB})
+	sentAt := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+
+	if err := AddChat(ctx, AddChatInput{ContactID: contactID, Platform: ChatPlatformWhatsApp, Message: "Dinner on Friday?", SentAt: &sentAt, MessageID: "q1"}); err != nil {
+		t.Fatalf("AddChat failed: %v", err)
+	}
+
+	// A voice note without caption replying to the question
+	voice := &ChatMedia{Type: ChatMediaVoice, MimeType: "audio/ogg; codecs=opus", Size: 4096}
+	if err := AddChat(ctx, AddChatInput{
+		ContactID: contactID, Platform: ChatPlatformWhatsApp, Sender: ChatSenderMe, SentAt: &sentAt,
+		MessageID: "v1", ReplyToMessageID: "q1", Media: voice,
+	}); err != nil {
+		t.Fatalf("AddChat with media failed: %v", err)
+	}
+
+	// The same message ID is only stored once
+	if err := AddChat(ctx, AddChatInput{ContactID: contactID, Platform: ChatPlatformWhatsApp, Message: "Dinner on Friday?", SentAt: &sentAt, MessageID: "q1"}); err != nil {
+		t.Fatalf("AddChat duplicate failed: %v", err)
+	}
+
+	editedAt := time.Date(2025, 5, 1, 10, 5, 0, 0, time.UTC)
+	if err := EditChatMessage(ctx, ChatPlatformWhatsApp, "q1", "Dinner on Saturday?", editedAt); err != nil {
+		t.Fatalf("EditChatMessage failed: %v", err)
+	}
+
+	chats, err := GetContactChats(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContactChats failed: %v", err)
+	}
+
+	if len(chats) != 2 {
+		t.Fatalf("expected 2 chats, got %+v", chats)
+	}
+
+	byID := map[string]ContactChat{}
+	for _, chat := range chats {
+		byID[pointerString(chat.MessageID)] = chat
+	}
+
+	question := byID["q1"]
+	if question.Message != "Dinner on Saturday?" || question.EditedAt == nil || !question.EditedAt.Equal(editedAt) {
+		t.Fatalf("expected edited question, got %+v", question)
+	}
+
+	reply := byID["v1"]
+	if reply.Media == nil || reply.Media.Type != ChatMediaVoice || reply.Media.Size != 4096 {
+		t.Fatalf("expected voice note media, got %+v", reply.Media)
+	}
+
+	if pointerString(reply.ReplyToMessage) != "Dinner on Saturday?" {
+		t.Fatalf("expected reply to resolve the question, got %v", reply.ReplyToMessage)
+	}
+
+	if err := RevokeChatMessage(ctx, ChatPlatformWhatsApp, "q1", editedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeChatMessage failed: %v", err)
+	}
+
+	chats, err = GetContactChatsSince(ctx, contactID, editedAt.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetContactChatsSince failed: %v", err)
+	}
+
+	for _, chat := range chats {
+		switch pointerString(chat.MessageID) {
+		case "q1":
+			if chat.RevokedAt == nil {
+				t.Fatalf("expected question to be revoked")
+			}
+		case "v1":
+			if chat.ReplyToMessage != nil {
+				t.Fatalf("expected revoked message to no longer be quoted, got %q", *chat.ReplyToMessage)
+			}
+		}
+	}
+
+	// Changes to messages still in the inbox apply there
+	if err := RecordUnknownWhatsAppMessage(ctx, "447700900555", "u1", editedAt, false, "Hi"); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	if err := EditChatMessage(ctx, ChatPlatformWhatsApp, "u1", "Hi there", editedAt); err != nil {
+		t.Fatalf("EditChatMessage failed: %v", err)
+	}
+
+	numbers, err := ListUnknownWhatsAppNumbers(ctx)
+	if err != nil || len(numbers) != 1 || numbers[0].LastMessage != "Hi there" {
+		t.Fatalf("expected queued message to be edited, got %+v %v", numbers, err)
+	}
+
+	if err := RevokeChatMessage(ctx, ChatPlatformWhatsApp, "u1", editedAt); err != nil {
+		t.Fatalf("RevokeChatMessage failed: %v", err)
+	}
+
+	numbers, err = ListUnknownWhatsAppNumbers(ctx)
+	if err != nil || len(numbers) != 1 || numbers[0].QueuedMessages != 0 {
+		t.Fatalf("expected queued message to be dropped, got %+v %v", numbers, err)
+	}
+}
+
+func TestChatMessageExists(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Layla", Tier: TierB})
+
+	if exists, err := ChatMessageExists(ctx, ChatPlatformWhatsApp, "m1"); err != nil || exists {
+		t.Fatalf("expected no stored message, got %v %v", exists, err)
+	}
+
+	if err := AddChat(ctx, AddChatInput{ContactID: contactID, Platform: ChatPlatformWhatsApp, Message: "Hello", MessageID: "m1"}); err != nil {
+		t.Fatalf("AddChat failed: %v", err)
+	}
+
+	if exists, err := ChatMessageExists(ctx, ChatPlatformWhatsApp, "m1"); err != nil || !exists {
+		t.Fatalf("expected the stored message, got %v %v", exists, err)
+	}
+
+	if exists, err := ChatMessageExists(ctx, ChatPlatformSignal, "m1"); err != nil || exists {
+		t.Fatalf("expected the ID to be per platform, got %v %v", exists, err)
+	}
+
+	if exists, err := ChatMessageExists(ctx, ChatPlatformWhatsApp, ""); err != nil || exists {
+		t.Fatalf("expected an empty ID never to exist, got %v %v", exists, err)
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import "testing"
+
+func TestChatMediaPath(t *testing.T) {
+	t.Parallel()
+
+	contactID := "5f0c8a36-3f7e-4a7e-9d2b-1c7a2f0e4b11"
+
+	tests := []struct {
+		name      string
+		messageID string
+		media     ChatMedia
+		want      string
+	}{
+		{
+			name:      "mime type with parameters",
+			messageID: "3EB0C767D26A1B",
+			media:     ChatMedia{Type: ChatMediaVoice, MimeType: "audio/ogg; codecs=opus"},
+			want:      "contacts/" + contactID + "/3EB0C767D26A1B.ogg",
+		},
+		{
+			name:      "document keeps its extension",
+			messageID: "ABC",
+			media:     ChatMedia{Type: ChatMediaDocument, MimeType: "application/octet-stream", FileName: "Itinerary.PDF"},
+			want:      "contacts/" + contactID + "/ABC.pdf",
+		},
+		{
+			name:      "unsafe id and unknown type",
+			messageID: "../x/y",
+			media:     ChatMedia{Type: ChatMediaDocument, MimeType: "application/x-unknown", FileName: "notes.t?t"},
+			want:      "contacts/" + contactID + "/___x_y.bin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := chatMediaPath(contactID, tt.messageID, tt.media); got != tt.want {
+				t.Fatalf("chatMediaPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// HasCoordinates reports whether the address can be shown on a map
+func (a ContactAddress) HasCoordinates() bool {
+	return a.Latitude != nil && a.Longitude != nil
+}
+
+// Lines returns the non-empty address components formatted for display
+func (a ContactAddress) Lines() []string {
+	var lines []string
+
+	for _, value := range []*string{a.POBox, a.Extended, a.Street} {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			lines = append(lines, strings.TrimSpace(*value))
+		}
+	}
+
+	var cityParts []string
+
+	for _, value := range []*string{a.Locality, a.Region} {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			cityParts = append(cityParts, strings.TrimSpace(*value))
+		}
+	}
+
+	cityLine := strings.Join(cityParts, ", ")
+	if a.PostalCode != nil && strings.TrimSpace(*a.PostalCode) != "" {
+		cityLine = strings.TrimSpace(cityLine + " " + strings.TrimSpace(*a.PostalCode))
+	}
+
+	if cityLine != "" {
+		lines = append(lines, cityLine)
+	}
+
+	if a.Country != nil && strings.TrimSpace(*a.Country) != "" {
+		lines = append(lines, strings.TrimSpace(*a.Country))
+	}
+
+	return lines
+}
+
+// AddressFields holds the structured components of a postal address
+type AddressFields struct {
+	Street      *string
+	Extended    *string
+	POBox       *string
+	Locality    *string
+	Region      *string
+	PostalCode  *string
+	Country     *string
+	AddressType AddressType
+	IsPrimary   bool
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// validate checks that the address has content and usable coordinates
+func (f AddressFields) validate() error {
+	hasValue := false
+
+	for _, value := range []*string{f.Street, f.Extended, f.POBox, f.Locality, f.Region, f.PostalCode, f.Country} {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			hasValue = true
+			break
+		}
+	}
+
+	if !hasValue {
+		return ErrAddressEmpty
+	}
+
+	if (f.Latitude == nil) != (f.Longitude == nil) {
+		return ErrAddressCoordinatesInvalid
+	}
+
+	if f.Latitude != nil && (*f.Latitude < -90 || *f.Latitude > 90) {
+		return ErrAddressCoordinatesInvalid
+	}
+
+	if f.Longitude != nil && (*f.Longitude < -180 || *f.Longitude > 180) {
+		return ErrAddressCoordinatesInvalid
+	}
+
+	return nil
+}
+
+func (f AddressFields) addressType() AddressType {
+	switch f.AddressType {
+	case AddressHome, AddressWork, AddressOther:
+		return f.AddressType
+	default:
+		return AddressHome
+	}
+}
+
+// AddAddressInput represents input for adding an address
+type AddAddressInput struct {
+	ContactID string
+	AddressFields
+}
+
+// AddAddress adds a new postal address to a contact
+func AddAddress(ctx context.Context, input AddAddressInput) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	if !input.IsPrimary {
+		var hasPrimary bool
+
+		checkQuery := `SELECT EXISTS(SELECT 1 FROM contact_addresses WHERE contact_id = $1 AND is_primary = true)`
+		if err := pool.QueryRow(ctx, checkQuery, input.ContactID).Scan(&hasPrimary); err != nil {
+			return fmt.Errorf("failed to check primary address: %w", err)
+		}
+
+		if !hasPrimary {
+			input.IsPrimary = true
+		}
+	}
+
+	// If setting as primary, clear other primaries first
+	if input.IsPrimary {
+		clearQuery := `UPDATE contact_addresses SET is_primary = false WHERE contact_id = $1 AND is_primary = true`
+		if _, err := pool.Exec(ctx, clearQuery, input.ContactID); err != nil {
+			return fmt.Errorf("failed to clear primary addresses: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO contact_addresses (
+			contact_id, street, extended, po_box, locality, region, postal_code, country,
+			address_type, is_primary, latitude, longitude
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := pool.Exec(ctx, query,
+		input.ContactID,
+		input.Street,
+		input.Extended,
+		input.POBox,
+		input.Locality,
+		input.Region,
+		input.PostalCode,
+		input.Country,
+		input.addressType(),
+		input.IsPrimary,
+		input.Latitude,
+		input.Longitude,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add address: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAddressInput represents input for updating an address
+type UpdateAddressInput struct {
+	ID        string
+	ContactID string
+	AddressFields
+}
+
+// UpdateAddress updates an existing postal address
+func UpdateAddress(ctx context.Context, input UpdateAddressInput) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	if !input.IsPrimary {
+		var hasOtherPrimary bool
+
+		checkQuery := `SELECT EXISTS(SELECT 1 FROM contact_addresses WHERE contact_id = $1 AND is_primary = true AND id != $2)`
+		if err := pool.QueryRow(ctx, checkQuery, input.ContactID, input.ID).Scan(&hasOtherPrimary); err != nil {
+			return fmt.Errorf("failed to check other primary addresses: %w", err)
+		}
+
+		if !hasOtherPrimary {
+			input.IsPrimary = true
+		}
+	}
+
+	// If setting as primary, clear other primaries first
+	if input.IsPrimary {
+		clearQuery := `UPDATE contact_addresses SET is_primary = false WHERE contact_id = $1 AND is_primary = true AND id != $2`
+		if _, err := pool.Exec(ctx, clearQuery, input.ContactID, input.ID); err != nil {
+			return fmt.Errorf("failed to clear primary addresses: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE contact_addresses
+		SET street = $1, extended = $2, po_box = $3, locality = $4, region = $5,
+			postal_code = $6, country = $7, address_type = $8, is_primary = $9,
+			latitude = $10, longitude = $11
+		WHERE id = $12 AND contact_id = $13
+	`
+
+	result, err := pool.Exec(ctx, query,
+		input.Street,
+		input.Extended,
+		input.POBox,
+		input.Locality,
+		input.Region,
+		input.PostalCode,
+		input.Country,
+		input.addressType(),
+		input.IsPrimary,
+		input.Latitude,
+		input.Longitude,
+		input.ID,
+		input.ContactID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update address: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAddressNotFound
+	}
+
+	return nil
+}
+
+// DeleteAddress removes a postal address from a contact
+func DeleteAddress(ctx context.Context, addressID, contactID string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `DELETE FROM contact_addresses WHERE id = $1 AND contact_id = $2`
+
+	_, err := pool.Exec(ctx, query, addressID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+
+	return nil
+}
+
+// GetContactAddress returns a single address belonging to a contact
+func GetContactAddress(ctx context.Context, addressID, contactID string) (*ContactAddress, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `SELECT id, contact_id, street, locality, region, postal_code, country,
+		address_type, is_primary, po_box, extended, source, latitude, longitude, created_at
+		FROM contact_addresses WHERE id = $1 AND contact_id = $2`
+
+	var addr ContactAddress
+
+	err := pool.QueryRow(ctx, query, addressID, contactID).Scan(&addr.ID, &addr.ContactID, &addr.Street,
+		&addr.Locality, &addr.Region, &addr.PostalCode, &addr.Country, &addr.AddressType, &addr.IsPrimary,
+		&addr.POBox, &addr.Extended, &addr.Source, &addr.Latitude, &addr.Longitude, &addr.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAddressNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+
+	return &addr, nil
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestContactAddressCRUD(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Addressed", Tier: TierB})
+
+	lat, lon := 25.2048, 55.2708
+
+	if err := AddAddress(ctx, AddAddressInput{ContactID: contactID, AddressFields: AddressFields{
+		Street:      stringPtr("1 Main Street"),
+		Locality:    stringPtr("Dubai"),
+		Country:     stringPtr("UAE"),
+		AddressType: AddressWork,
+		Latitude:    &lat,
+		Longitude:   &lon,
+	}}); err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+
+	if err := AddAddress(ctx, AddAddressInput{ContactID: contactID, AddressFields: AddressFields{
+		Street:   stringPtr("2 Side Road"),
+		Locality: stringPtr("Sharjah"),
+	}}); err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+
+	if err := AddAddress(ctx, AddAddressInput{ContactID: contactID}); !errors.Is(err, ErrAddressEmpty) {
+		t.Fatalf("expected ErrAddressEmpty, got %v", err)
+	}
+
+	detail, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(detail.Addresses) != 2 {
+		t.Fatalf("expected 2 addresses, got %d", len(detail.Addresses))
+	}
+
+	first := detail.Addresses[0]
+	if !first.IsPrimary || first.AddressType != AddressWork || first.Source != "local" {
+		t.Fatalf("expected first address to be local primary work address, got %+v", first)
+	}
+
+	if !first.HasCoordinates() || *first.Latitude != lat || *first.Longitude != lon {
+		t.Fatalf("expected coordinates to round-trip, got %v %v", first.Latitude, first.Longitude)
+	}
+
+	second := detail.Addresses[1]
+	if second.IsPrimary || second.AddressType != AddressHome || second.HasCoordinates() {
+		t.Fatalf("expected second address to default to non-primary home without coordinates, got %+v", second)
+	}
+
+	if err := UpdateAddress(ctx, UpdateAddressInput{ID: second.ID.String(), ContactID: contactID, AddressFields: AddressFields{
+		Street:      stringPtr("3 New Road"),
+		Locality:    stringPtr("Sharjah"),
+		AddressType: AddressOther,
+		IsPrimary:   true,
+	}}); err != nil {
+		t.Fatalf("UpdateAddress failed: %v", err)
+	}
+
+	updated, err := GetContactAddress(ctx, second.ID.String(), contactID)
+	if err != nil {
+		t.Fatalf("GetContactAddress failed: %v", err)
+	}
+
+	if updated.Street == nil || *updated.Street != "3 New Road" || !updated.IsPrimary || updated.AddressType != AddressOther {
+		t.Fatalf("unexpected updated address: %+v", updated)
+	}
+
+	previous, err := GetContactAddress(ctx, first.ID.String(), contactID)
+	if err != nil {
+		t.Fatalf("GetContactAddress failed: %v", err)
+	}
+
+	if previous.IsPrimary {
+		t.Fatalf("expected previous primary address to be cleared")
+	}
+
+	// Clearing the only primary keeps it primary
+	if err := UpdateAddress(ctx, UpdateAddressInput{ID: second.ID.String(), ContactID: contactID, AddressFields: AddressFields{
+		Street: stringPtr("3 New Road"),
+	}}); err != nil {
+		t.Fatalf("UpdateAddress failed: %v", err)
+	}
+
+	if addr, err := GetContactAddress(ctx, second.ID.String(), contactID); err != nil || !addr.IsPrimary {
+		t.Fatalf("expected address to stay primary, got %+v (%v)", addr, err)
+	}
+
+	otherContactID := mustCreateContact(t, CreateContactInput{NameGiven: "Other", Tier: TierB})
+
+	if err := UpdateAddress(ctx, UpdateAddressInput{ID: second.ID.String(), ContactID: otherContactID, AddressFields: AddressFields{
+		Street: stringPtr("Hijack"),
+	}}); !errors.Is(err, ErrAddressNotFound) {
+		t.Fatalf("expected ErrAddressNotFound for other contact, got %v", err)
+	}
+
+	if _, err := GetContactAddress(ctx, uuid.New().String(), contactID); !errors.Is(err, ErrAddressNotFound) {
+		t.Fatalf("expected ErrAddressNotFound, got %v", err)
+	}
+
+	if err := DeleteAddress(ctx, first.ID.String(), contactID); err != nil {
+		t.Fatalf("DeleteAddress failed: %v", err)
+	}
+
+	detail, err = GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(detail.Addresses) != 1 {
+		t.Fatalf("expected 1 address after delete, got %d", len(detail.Addresses))
+	}
+}
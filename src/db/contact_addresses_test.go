@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestContactAddressLines(t *testing.T) {
+	t.Parallel()
+
+	addr := ContactAddress{
+		Extended:   stringPtr("Apt 4"),
+		Street:     stringPtr("1 Main Street"),
+		Locality:   stringPtr("Springfield"),
+		Region:     stringPtr("IL"),
+		PostalCode: stringPtr("62701"),
+		Country:    stringPtr("USA"),
+	}
+
+	want := []string{"Apt 4", "1 Main Street", "Springfield, IL 62701", "USA"}
+	if got := addr.Lines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lines() = %q, want %q", got, want)
+	}
+
+	postcodeOnly := ContactAddress{PostalCode: stringPtr("12345"), Street: stringPtr(" ")}
+	if got := postcodeOnly.Lines(); !reflect.DeepEqual(got, []string{"12345"}) {
+		t.Fatalf("Lines() = %q, want postcode only", got)
+	}
+}
+
+func TestContactAddressHasCoordinates(t *testing.T) {
+	t.Parallel()
+
+	lat := 1.0
+	if (ContactAddress{Latitude: &lat}).HasCoordinates() {
+		t.Fatalf("expected coordinates to require both latitude and longitude")
+	}
+
+	if !(ContactAddress{Latitude: &lat, Longitude: &lat}).HasCoordinates() {
+		t.Fatalf("expected coordinates")
+	}
+}
+
+func TestAddressFieldsValidate(t *testing.T) {
+	t.Parallel()
+
+	lat, lon, bad := 25.0, 55.0, 200.0
+
+	cases := []struct {
+		name   string
+		fields AddressFields
+		want   error
+	}{
+		{name: "empty", fields: AddressFields{Street: stringPtr(" ")}, want: ErrAddressEmpty},
+		{name: "valid", fields: AddressFields{Country: stringPtr("UAE")}},
+		{name: "with coordinates", fields: AddressFields{Country: stringPtr("UAE"), Latitude: &lat, Longitude: &lon}},
+		{name: "latitude only", fields: AddressFields{Country: stringPtr("UAE"), Latitude: &lat}, want: ErrAddressCoordinatesInvalid},
+		{name: "latitude out of range", fields: AddressFields{Country: stringPtr("UAE"), Latitude: &bad, Longitude: &lon}, want: ErrAddressCoordinatesInvalid},
+		{name: "longitude out of range", fields: AddressFields{Country: stringPtr("UAE"), Latitude: &lat, Longitude: &bad}, want: ErrAddressCoordinatesInvalid},
+	}
+
+	for _, tc := range cases {
+		if err := tc.fields.validate(); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: validate() = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	if got := (AddressFields{}).addressType(); got != AddressHome {
+		t.Fatalf("expected default address type home, got %q", got)
+	}
+}
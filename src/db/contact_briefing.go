@@ -0,0 +1,281 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Limits on how much history goes into a briefing prompt
+const (
+	briefingChatWindow      = 30 * 24 * time.Hour
+	briefingMaxChats        = 60
+	briefingMaxLogs         = 10
+	briefingMaxNotes        = 10
+	briefingMaxQSOs         = 10
+	briefingMaxZKNotes      = 8
+	briefingMaxZKNoteLength = 2000
+	briefingBirthdayDays    = 30
+)
+
+// ContactBriefing is everything known about a contact that goes into a
+// "prepare for meeting" briefing
+type ContactBriefing struct {
+	Contact *ContactDetail
+	Chats   []ContactChat // Most recent chats, oldest first
+	QSOs    []QSOListItem
+	ZKNotes []ZKChatNote // Zettelkasten and journal notes linking to the contact
+	// Birthday is the next birthday when it falls within the next 30 days
+	Birthday *UpcomingContactDate
+}
+
+// GetContactBriefing gathers the profile, history, shared QSOs and linking
+// notes of a contact for a briefing. Notes that cannot be fetched from
+// WebDAV are left out rather than failing the briefing.
+func GetContactBriefing(ctx context.Context, contactID string, now time.Time) (*ContactBriefing, error) {
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	briefing := &ContactBriefing{Contact: contact}
+
+	chats, err := GetContactChatsSince(ctx, contactID, now.Add(-briefingChatWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chats) > briefingMaxChats {
+		chats = chats[len(chats)-briefingMaxChats:]
+	}
+
+	briefing.Chats = chats
+
+	if callSign := pointerString(contact.CallSign); callSign != "" {
+		qsos, err := GetQSOsByCallSign(ctx, callSign)
+		if err != nil {
+			return nil, err
+		}
+
+		briefing.QSOs = qsos
+	}
+
+	for _, sourceID := range GetContactLinksFromCache(contactID) {
+		if len(briefing.ZKNotes) >= briefingMaxZKNotes {
+			break
+		}
+
+		note, err := getBriefingZKNote(ctx, sourceID)
+		if err != nil {
+			logger.Warn("Skipping note in contact briefing", "contact_id", contactID, "note_id", sourceID, "error", err)
+			continue
+		}
+
+		briefing.ZKNotes = append(briefing.ZKNotes, *note)
+	}
+
+	if contact.Birthday != nil {
+		birthday := ContactDate{
+			ContactID:   contactID,
+			NameDisplay: contact.NameDisplay,
+			Kind:        ContactDateBirthday,
+			Date:        *contact.Birthday,
+		}
+
+		if upcoming := UpcomingContactDates([]ContactDate{birthday}, now, briefingBirthdayDays); len(upcoming) > 0 {
+			briefing.Birthday = &upcoming[0]
+		}
+	}
+
+	return briefing, nil
+}
+
+// getBriefingZKNote fetches the raw content of a note linking to a contact,
+// which is either a zettelkasten note or a daily journal entry
+func getBriefingZKNote(ctx context.Context, sourceID string) (*ZKChatNote, error) {
+	if !strings.HasPrefix(sourceID, DailyBacklinkPrefix) {
+		return GetZKNoteForChat(ctx, sourceID)
+	}
+
+	date := strings.TrimPrefix(sourceID, DailyBacklinkPrefix)
+
+	entry, ok := GetJournalEntryByDate(date)
+	if !ok {
+		return nil, fmt.Errorf("journal entry %s is not cached", date)
+	}
+
+	content, err := FetchDailyOrgFile(ctx, entry.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch journal entry: %w", err)
+	}
+
+	return &ZKChatNote{
+		ID:      sourceID,
+		Title:   "Journal " + date + " " + entry.Title,
+		Content: content,
+	}, nil
+}
+
+// truncateBriefingText shortens text to a number of runes
+func truncateBriefingText(text string, limit int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+
+	return string(runes[:limit]) + "..."
+}
+
+func buildContactBriefingPrompt(briefing *ContactBriefing, now time.Time) string {
+	var sb strings.Builder
+
+	contact := briefing.Contact
+
+	sb.WriteString("Prepare me for an upcoming meeting with the following contact. ")
+	sb.WriteString(fmt.Sprintf("Today is %s.\n\n", now.Format("Monday, Jan 2, 2006")))
+
+	sb.WriteString("Profile:\n")
+	sb.WriteString(fmt.Sprintf("Name: %s\n", contact.NameDisplay))
+
+	profileFields := []struct {
+		label string
+		value *string
+	}{
+		{"Nickname", contact.Nickname},
+		{"Organization", contact.Organization},
+		{"Title", contact.Title},
+		{"Role", contact.Role},
+		{"Timezone", contact.Timezone},
+		{"Language", contact.Language},
+		{"Call sign", contact.CallSign},
+	}
+
+	for _, field := range profileFields {
+		if value := pointerString(field.value); value != "" {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", field.label, value))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Tier: %s\n", contact.Tier))
+
+	if len(contact.Tags) > 0 {
+		names := make([]string, 0, len(contact.Tags))
+		for _, tag := range contact.Tags {
+			names = append(names, tag.Name)
+		}
+
+		sb.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(names, ", ")))
+	}
+
+	if birthday := briefing.Birthday; birthday != nil {
+		when := fmt.Sprintf("in %d days", birthday.DaysUntil)
+
+		switch birthday.DaysUntil {
+		case 0:
+			when = "today"
+		case 1:
+			when = "tomorrow"
+		}
+
+		sb.WriteString(fmt.Sprintf("Upcoming birthday: %s (%s)", birthday.On.Format("Jan 2"), when))
+
+		if birthday.Years != nil {
+			sb.WriteString(fmt.Sprintf(", turning %d", *birthday.Years))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	if len(contact.Logs) > 0 {
+		sb.WriteString("\nRecent interactions (newest first):\n")
+
+		for i, log := range contact.Logs {
+			if i >= briefingMaxLogs {
+				break
+			}
+
+			line := strings.ReplaceAll(string(log.LogType), "_", " ")
+			if subject := pointerString(log.Subject); subject != "" {
+				line += ": " + subject
+			}
+
+			if content := pointerString(log.Content); content != "" {
+				line += " - " + truncateBriefingText(content, 500)
+			}
+
+			sb.WriteString(fmt.Sprintf("[%s] %s\n", log.LoggedAt.Format("Jan 2, 2006"), line))
+		}
+	}
+
+	if len(contact.Notes) > 0 {
+		sb.WriteString("\nNotes (newest first):\n")
+
+		for i, note := range contact.Notes {
+			if i >= briefingMaxNotes {
+				break
+			}
+
+			sb.WriteString(fmt.Sprintf("[%s] %s\n", note.NotedAt.Format("Jan 2, 2006"), truncateBriefingText(note.Content, 500)))
+		}
+	}
+
+	if len(briefing.Chats) > 0 {
+		sb.WriteString("\nRecent chats:\n")
+
+		for _, chat := range briefing.Chats {
+			sender := "Them"
+			if chat.Sender == ChatSenderMe {
+				sender = "Me"
+			}
+
+			sb.WriteString(fmt.Sprintf("[%s] %s: %s\n", chat.SentAt.Format("Jan 2, 2006 3:04 PM"), sender, chat.Message))
+		}
+	}
+
+	if len(briefing.QSOs) > 0 {
+		sb.WriteString(fmt.Sprintf("\nAmateur radio contacts (%d in total, newest first):\n", len(briefing.QSOs)))
+
+		for i, qso := range briefing.QSOs {
+			if i >= briefingMaxQSOs {
+				break
+			}
+
+			line := fmt.Sprintf("[%s] %s", qso.FormatDate(), qso.Mode)
+			if band := pointerString(qso.Band); band != "" {
+				line += " on " + band
+			}
+
+			if qth := pointerString(qso.QTH); qth != "" {
+				line += " from " + qth
+			}
+
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	if len(briefing.ZKNotes) > 0 {
+		sb.WriteString("\nPersonal notes mentioning the contact (raw org-mode text):\n\n")
+
+		for _, note := range briefing.ZKNotes {
+			sb.WriteString(fmt.Sprintf("Note Title: %s\n", note.Title))
+			sb.WriteString(truncateBriefingText(note.Content, briefingMaxZKNoteLength))
+			sb.WriteString("\n---\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// StreamContactBriefing streams a "prepare for meeting" briefing for a
+// contact using Ollama.
+func StreamContactBriefing(ctx context.Context, briefing *ContactBriefing, now time.Time, onChunk func(string) error) error {
+	prompt := buildContactBriefingPrompt(briefing, now)
+	systemPrompt := "You prepare briefings before meeting someone. Using only the provided information, recap who the person is, what was discussed recently, open threads or promises to follow up on, and personal details worth remembering such as an upcoming birthday. Suggest a few conversation topics. If there is little information, say so briefly rather than guessing. Use short markdown lists without headings. Maximum 250 words."
+
+	return streamChatCompletion(ctx, systemPrompt, prompt, onChunk)
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetContactBriefing(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	now := time.Now().UTC()
+
+	callSign := "A6TEST"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Briefed", Tier: TierB, CallSign: &callSign})
+
+	soon := now.AddDate(0, 0, 3)
+	birthday := time.Date(1992, soon.Month(), soon.Day(), 0, 0, 0, 0, time.UTC)
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET birthday = $1 WHERE id = $2`, birthday, contactID); err != nil {
+		t.Fatalf("failed to set birthday: %v", err)
+	}
+
+	if err := AddNote(ctx, AddNoteInput{ContactID: contactID, Content: "Likes hiking"}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	if err := AddChat(ctx, AddChatInput{ContactID: contactID, Platform: ChatPlatformWhatsApp, Sender: ChatSenderThem, Message: "See you soon"}); err != nil {
+		t.Fatalf("AddChat failed: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO qsos (call, qso_date, time_on, mode, contact_id)
+		VALUES ($1, CURRENT_DATE, CURRENT_TIME, 'FT8', $2)
+	`, callSign, contactID); err != nil {
+		t.Fatalf("failed to insert QSO: %v", err)
+	}
+
+	briefing, err := GetContactBriefing(ctx, contactID, now)
+	if err != nil {
+		t.Fatalf("GetContactBriefing failed: %v", err)
+	}
+
+	if briefing.Contact.NameDisplay != "Briefed" {
+		t.Fatalf("expected contact to be loaded, got %q", briefing.Contact.NameDisplay)
+	}
+
+	if len(briefing.Contact.Notes) != 1 || len(briefing.Chats) != 1 || len(briefing.QSOs) != 1 {
+		t.Fatalf("expected a note, chat and QSO, got %d, %d and %d", len(briefing.Contact.Notes), len(briefing.Chats), len(briefing.QSOs))
+	}
+
+	if briefing.Birthday == nil || briefing.Birthday.DaysUntil != 3 {
+		t.Fatalf("expected birthday in 3 days, got %#v", briefing.Birthday)
+	}
+
+	if _, err := GetContactBriefing(ctx, "00000000-0000-0000-0000-000000000000", now); err == nil {
+		t.Fatalf("expected missing contact to fail")
+	}
+}
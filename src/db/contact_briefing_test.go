@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildContactBriefingPrompt(t *testing.T) {
+	t.Parallel()
+
+	organization := "Acme"
+	subject := "Coffee"
+	band := "20m"
+	years := 35
+
+	briefing := &ContactBriefing{
+		Contact: &ContactDetail{
+			Contact: Contact{NameDisplay: "Jane Doe", Organization: &organization, Tier: TierB},
+			Logs:    []ContactLog{{LogType: LogMeeting, LoggedAt: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), Subject: &subject}},
+			Notes:   []ContactNote{{Content: "Moving to Berlin", NotedAt: time.Date(2025, time.March, 2, 0, 0, 0, 0, time.UTC)}},
+			Tags:    []Tag{{Name: "friends"}, {Name: "hams"}},
+		},
+		Chats:   []ContactChat{{Sender: ChatSenderMe, Message: "See you Friday", SentAt: time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)}},
+		QSOs:    []QSOListItem{{Mode: "SSB", Band: &band, QSODate: time.Date(2024, time.May, 4, 0, 0, 0, 0, time.UTC)}},
+		ZKNotes: []ZKChatNote{{ID: "note-1", Title: "Berlin trip", Content: strings.Repeat("x", briefingMaxZKNoteLength+10)}},
+		Birthday: &UpcomingContactDate{
+			On:        time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC),
+			DaysUntil: 1,
+			Years:     &years,
+		},
+	}
+
+	prompt := buildContactBriefingPrompt(briefing, time.Date(2025, time.March, 4, 0, 0, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		"Name: Jane Doe",
+		"Organization: Acme",
+		"Tags: friends, hams",
+		"Upcoming birthday: Mar 5 (tomorrow), turning 35",
+		"[Mar 1, 2025] meeting: Coffee",
+		"[Mar 2, 2025] Moving to Berlin",
+		"Me: See you Friday",
+		"SSB on 20m",
+		"Note Title: Berlin trip",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+
+	if strings.Contains(prompt, strings.Repeat("x", briefingMaxZKNoteLength+1)) {
+		t.Fatalf("expected long note content to be truncated")
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ContactDateYearUnknown is the placeholder year stored for dates without a
+// year (e.g. vCard "--0315"). It is a leap year so 29 February is representable.
+const ContactDateYearUnknown = 1604
+
+// ContactDateKind is the type of a recurring contact date
+type ContactDateKind string
+
+const (
+	// ContactDateBirthday is a contact's birthday
+	ContactDateBirthday ContactDateKind = "birthday"
+	// ContactDateAnniversary is a contact's anniversary
+	ContactDateAnniversary ContactDateKind = "anniversary"
+)
+
+// ContactDate is a birthday or anniversary stored on a contact
+type ContactDate struct {
+	ContactID   string
+	NameDisplay string
+	Kind        ContactDateKind
+	Date        time.Time
+}
+
+// YearKnown reports whether the stored date includes a real year
+func (d ContactDate) YearKnown() bool {
+	return d.Date.Year() != ContactDateYearUnknown
+}
+
+// OccurrenceIn returns the date's occurrence in the given year.
+// 29 February falls on 28 February in non-leap years.
+func (d ContactDate) OccurrenceIn(year int) time.Time {
+	month, day := d.Date.Month(), d.Date.Day()
+
+	occurrence := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if occurrence.Month() != month {
+		occurrence = time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	}
+
+	return occurrence
+}
+
+// YearsAt returns the age or number of years reached at an occurrence,
+// or nil when the year is unknown.
+func (d ContactDate) YearsAt(occurrence time.Time) *int {
+	if !d.YearKnown() {
+		return nil
+	}
+
+	years := occurrence.Year() - d.Date.Year()
+	if years < 1 {
+		return nil
+	}
+
+	return &years
+}
+
+// UpcomingContactDate is the next occurrence of a contact date
+type UpcomingContactDate struct {
+	ContactDate
+	On        time.Time
+	DaysUntil int
+	Years     *int // Age or years reached on this occurrence, nil if unknown
+}
+
+// ListContactDates returns all birthdays and anniversaries of personal contacts
+func ListContactDates(ctx context.Context) ([]ContactDate, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `
+		SELECT id, name_display, 'birthday' AS kind, birthday AS date
+		FROM contacts
+		WHERE birthday IS NOT NULL AND is_service = false
+		UNION ALL
+		SELECT id, name_display, 'anniversary' AS kind, anniversary AS date
+		FROM contacts
+		WHERE anniversary IS NOT NULL AND is_service = false
+		ORDER BY name_display ASC, kind DESC
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contact dates: %w", err)
+	}
+	defer rows.Close()
+
+	var dates []ContactDate
+
+	for rows.Next() {
+		var (
+			date ContactDate
+			kind string
+		)
+
+		if err := rows.Scan(&date.ContactID, &date.NameDisplay, &kind, &date.Date); err != nil {
+			return nil, fmt.Errorf("failed to scan contact date: %w", err)
+		}
+
+		date.Kind = ContactDateKind(kind)
+		dates = append(dates, date)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contact dates: %w", err)
+	}
+
+	return dates, nil
+}
+
+// ListUpcomingContactDates returns contact dates occurring within the next
+// number of days (including today), soonest first
+func ListUpcomingContactDates(ctx context.Context, now time.Time, days int) ([]UpcomingContactDate, error) {
+	dates, err := ListContactDates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return UpcomingContactDates(dates, now, days), nil
+}
+
+// UpcomingContactDates returns the dates occurring within the next number of
+// days (including today), soonest first
+func UpcomingContactDates(dates []ContactDate, now time.Time, days int) []UpcomingContactDate {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var upcoming []UpcomingContactDate
+
+	for _, date := range dates {
+		occurrence := date.OccurrenceIn(today.Year())
+		if occurrence.Before(today) {
+			occurrence = date.OccurrenceIn(today.Year() + 1)
+		}
+
+		daysUntil := int(occurrence.Sub(today).Hours() / 24)
+		if daysUntil > days {
+			continue
+		}
+
+		upcoming = append(upcoming, UpcomingContactDate{
+			ContactDate: date,
+			On:          occurrence,
+			DaysUntil:   daysUntil,
+			Years:       date.YearsAt(occurrence),
+		})
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DaysUntil < upcoming[j].DaysUntil
+	})
+
+	return upcoming
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListContactDates(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	personID := mustCreateContact(t, CreateContactInput{NameGiven: "Person", Tier: TierC})
+	serviceID := mustCreateContact(t, CreateContactInput{NameGiven: "Service", Tier: TierC})
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET birthday = '1990-04-02', anniversary = '2012-09-08' WHERE id = $1`, personID); err != nil {
+		t.Fatalf("failed to set person dates: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET birthday = '1990-04-02', is_service = true WHERE id = $1`, serviceID); err != nil {
+		t.Fatalf("failed to set service dates: %v", err)
+	}
+
+	dates, err := ListContactDates(ctx)
+	if err != nil {
+		t.Fatalf("ListContactDates failed: %v", err)
+	}
+
+	if len(dates) != 2 {
+		t.Fatalf("expected 2 dates for the personal contact, got %d", len(dates))
+	}
+
+	for _, date := range dates {
+		if date.ContactID != personID {
+			t.Fatalf("expected only personal contact dates, got %#v", date)
+		}
+	}
+
+	upcoming, err := ListUpcomingContactDates(ctx, time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), 7)
+	if err != nil {
+		t.Fatalf("ListUpcomingContactDates failed: %v", err)
+	}
+
+	if len(upcoming) != 1 || upcoming[0].Kind != ContactDateBirthday || upcoming[0].DaysUntil != 1 {
+		t.Fatalf("expected birthday tomorrow, got %#v", upcoming)
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContactDateOccurrenceIn(t *testing.T) {
+	t.Parallel()
+
+	leapDay := ContactDate{Date: time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC)}
+
+	if got := leapDay.OccurrenceIn(2024); got.Month() != time.February || got.Day() != 29 {
+		t.Fatalf("expected 29 February in a leap year, got %v", got)
+	}
+
+	if got := leapDay.OccurrenceIn(2025); got.Month() != time.February || got.Day() != 28 {
+		t.Fatalf("expected 28 February in a non-leap year, got %v", got)
+	}
+}
+
+func TestContactDateYearsAt(t *testing.T) {
+	t.Parallel()
+
+	known := ContactDate{Date: time.Date(1990, time.June, 1, 0, 0, 0, 0, time.UTC)}
+
+	years := known.YearsAt(time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if years == nil || *years != 35 {
+		t.Fatalf("expected 35 years, got %v", years)
+	}
+
+	if got := known.YearsAt(time.Date(1990, time.June, 1, 0, 0, 0, 0, time.UTC)); got != nil {
+		t.Fatalf("expected no years on the original date, got %d", *got)
+	}
+
+	unknown := ContactDate{Date: time.Date(ContactDateYearUnknown, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	if unknown.YearKnown() {
+		t.Fatalf("expected placeholder year to be unknown")
+	}
+
+	if got := unknown.YearsAt(time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)); got != nil {
+		t.Fatalf("expected no years for unknown year, got %d", *got)
+	}
+}
+
+func TestUpcomingContactDates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 20, 15, 0, 0, 0, time.Local)
+	dates := []ContactDate{
+		{ContactID: "later", NameDisplay: "Later", Kind: ContactDateBirthday, Date: time.Date(1980, time.January, 10, 0, 0, 0, 0, time.UTC)},
+		{ContactID: "today", NameDisplay: "Today", Kind: ContactDateAnniversary, Date: time.Date(2015, time.December, 20, 0, 0, 0, 0, time.UTC)},
+		{ContactID: "past", NameDisplay: "Past", Kind: ContactDateBirthday, Date: time.Date(1985, time.December, 1, 0, 0, 0, 0, time.UTC)},
+		{ContactID: "noyear", NameDisplay: "No Year", Kind: ContactDateBirthday, Date: time.Date(ContactDateYearUnknown, time.December, 25, 0, 0, 0, 0, time.UTC)},
+	}
+
+	upcoming := UpcomingContactDates(dates, now, 30)
+
+	if len(upcoming) != 3 {
+		t.Fatalf("expected 3 upcoming dates, got %d", len(upcoming))
+	}
+
+	if upcoming[0].ContactID != "today" || upcoming[0].DaysUntil != 0 {
+		t.Fatalf("expected today's anniversary first, got %#v", upcoming[0])
+	}
+
+	if upcoming[0].Years == nil || *upcoming[0].Years != 10 {
+		t.Fatalf("expected 10 years for anniversary, got %v", upcoming[0].Years)
+	}
+
+	if upcoming[1].ContactID != "noyear" || upcoming[1].DaysUntil != 5 || upcoming[1].Years != nil {
+		t.Fatalf("unexpected second upcoming date: %#v", upcoming[1])
+	}
+
+	if upcoming[2].ContactID != "later" || upcoming[2].DaysUntil != 21 {
+		t.Fatalf("expected next-year birthday last, got %#v", upcoming[2])
+	}
+
+	if upcoming[2].Years == nil || *upcoming[2].Years != 46 {
+		t.Fatalf("expected age 46 on next-year birthday, got %v", upcoming[2].Years)
+	}
+}
@@ -0,0 +1,534 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/humaidq/groundwave/whatsapp"
+)
+
+// Scores contributed by each kind of match between two contacts
+const (
+	duplicateScoreEmail       = 50
+	duplicateScorePhone       = 40
+	duplicateScoreCallSign    = 40
+	duplicateScoreNameExact   = 30
+	duplicateScoreNameSimilar = 20
+
+	// DuplicateMinScore is the lowest score reported as a possible duplicate
+	DuplicateMinScore = 30
+	// duplicateNameSimilarity is the minimum similarity for a fuzzy name match
+	duplicateNameSimilarity = 0.8
+	// duplicatePhoneKeyDigits groups phone numbers by their trailing digits
+	duplicatePhoneKeyDigits = 7
+)
+
+// DuplicateContact is the contact data used to detect duplicates
+type DuplicateContact struct {
+	ID           string
+	NameDisplay  string
+	Organization *string
+	CallSign     *string
+	CardDAVUUID  *string
+	IsService    bool
+	CreatedAt    time.Time
+	Emails       []string
+	Phones       []string
+}
+
+// DuplicateCandidate is a pair of contacts that likely describe the same person
+type DuplicateCandidate struct {
+	First   DuplicateContact // Older contact, suggested as the survivor
+	Second  DuplicateContact
+	Score   int
+	Reasons []string
+}
+
+// ListDuplicateCandidates returns likely duplicate contact pairs, highest score first
+func ListDuplicateCandidates(ctx context.Context) ([]DuplicateCandidate, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, name_display, organization, call_sign, carddav_uuid, is_service, created_at
+		FROM contacts
+		WHERE is_me = false
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contacts for duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []DuplicateContact
+
+	index := make(map[string]int)
+
+	for rows.Next() {
+		var contact DuplicateContact
+		if err := rows.Scan(&contact.ID, &contact.NameDisplay, &contact.Organization, &contact.CallSign,
+			&contact.CardDAVUUID, &contact.IsService, &contact.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact for duplicates: %w", err)
+		}
+
+		index[contact.ID] = len(contacts)
+		contacts = append(contacts, contact)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contacts for duplicates: %w", err)
+	}
+
+	if err := loadDuplicateValues(ctx, `SELECT contact_id, email FROM contact_emails ORDER BY is_primary DESC, created_at`, func(id, value string) {
+		if i, ok := index[id]; ok {
+			contacts[i].Emails = append(contacts[i].Emails, value)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := loadDuplicateValues(ctx, `SELECT contact_id, phone FROM contact_phones ORDER BY is_primary DESC, created_at`, func(id, value string) {
+		if i, ok := index[id]; ok {
+			contacts[i].Phones = append(contacts[i].Phones, value)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	return FindDuplicateCandidates(contacts), nil
+}
+
+func loadDuplicateValues(ctx context.Context, query string, add func(id, value string)) error {
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query contact values for duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return fmt.Errorf("failed to scan contact value for duplicates: %w", err)
+		}
+
+		add(id, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating contact values for duplicates: %w", err)
+	}
+
+	return nil
+}
+
+// FindDuplicateCandidates scores every pair of contacts sharing an email, phone,
+// call sign or name token and returns the pairs reaching DuplicateMinScore
+func FindDuplicateCandidates(contacts []DuplicateContact) []DuplicateCandidate {
+	buckets := make(map[string][]int)
+
+	for i, contact := range contacts {
+		for _, email := range contact.Emails {
+			if key := normalizeCardDAVEmail(email); key != "" {
+				buckets["email:"+key] = append(buckets["email:"+key], i)
+			}
+		}
+
+		for _, phone := range contact.Phones {
+			digits := normalizePhoneDigits(phone)
+			if len(digits) >= duplicatePhoneKeyDigits {
+				key := "phone:" + digits[len(digits)-duplicatePhoneKeyDigits:]
+				buckets[key] = append(buckets[key], i)
+			}
+		}
+
+		if callSign := normalizeDuplicateCallSign(contact.CallSign); callSign != "" {
+			buckets["call:"+callSign] = append(buckets["call:"+callSign], i)
+		}
+
+		for _, token := range strings.Fields(normalizeDuplicateName(contact.NameDisplay)) {
+			if len([]rune(token)) >= 3 {
+				buckets["name:"+token] = append(buckets["name:"+token], i)
+			}
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+
+	var candidates []DuplicateCandidate
+
+	for _, members := range buckets {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				pair := [2]int{members[x], members[y]}
+				if pair[0] == pair[1] || seen[pair] {
+					continue
+				}
+
+				seen[pair] = true
+
+				score, reasons := scoreDuplicatePair(contacts[pair[0]], contacts[pair[1]])
+				if score < DuplicateMinScore {
+					continue
+				}
+
+				first, second := contacts[pair[0]], contacts[pair[1]]
+				if second.CreatedAt.Before(first.CreatedAt) {
+					first, second = second, first
+				}
+
+				candidates = append(candidates, DuplicateCandidate{
+					First:   first,
+					Second:  second,
+					Score:   score,
+					Reasons: reasons,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+
+		if candidates[i].First.NameDisplay != candidates[j].First.NameDisplay {
+			return candidates[i].First.NameDisplay < candidates[j].First.NameDisplay
+		}
+
+		return candidates[i].Second.ID < candidates[j].Second.ID
+	})
+
+	return candidates
+}
+
+// scoreDuplicatePair scores how likely two contacts are the same person
+func scoreDuplicatePair(a, b DuplicateContact) (int, []string) {
+	score := 0
+
+	var reasons []string
+
+	if email := sharedDuplicateEmail(a.Emails, b.Emails); email != "" {
+		score += duplicateScoreEmail
+
+		reasons = append(reasons, "Same email "+email)
+	}
+
+	if phone := sharedDuplicatePhone(a.Phones, b.Phones); phone != "" {
+		score += duplicateScorePhone
+
+		reasons = append(reasons, "Same phone "+phone)
+	}
+
+	if callSign := normalizeDuplicateCallSign(a.CallSign); callSign != "" && callSign == normalizeDuplicateCallSign(b.CallSign) {
+		score += duplicateScoreCallSign
+
+		reasons = append(reasons, "Same call sign "+callSign)
+	}
+
+	nameA, nameB := normalizeDuplicateName(a.NameDisplay), normalizeDuplicateName(b.NameDisplay)
+	if nameA != "" && nameA == nameB {
+		score += duplicateScoreNameExact
+
+		reasons = append(reasons, "Same name")
+	} else if similarity := nameSimilarity(nameA, nameB); similarity >= duplicateNameSimilarity {
+		score += duplicateScoreNameSimilar
+
+		reasons = append(reasons, fmt.Sprintf("Similar name (%.0f%%)", similarity*100))
+	}
+
+	return min(score, 100), reasons
+}
+
+func sharedDuplicateEmail(a, b []string) string {
+	for _, emailA := range a {
+		for _, emailB := range b {
+			if normalized := normalizeCardDAVEmail(emailA); normalized != "" && normalized == normalizeCardDAVEmail(emailB) {
+				return normalized
+			}
+		}
+	}
+
+	return ""
+}
+
+func sharedDuplicatePhone(a, b []string) string {
+	for _, phoneA := range a {
+		for _, phoneB := range b {
+			if whatsapp.PhoneMatches(normalizePhoneDigits(phoneA), normalizePhoneDigits(phoneB)) {
+				return phoneA
+			}
+		}
+	}
+
+	return ""
+}
+
+func normalizeDuplicateCallSign(callSign *string) string {
+	if callSign == nil {
+		return ""
+	}
+
+	return strings.ToUpper(strings.TrimSpace(*callSign))
+}
+
+// normalizeDuplicateName lowercases a name, drops punctuation and sorts its
+// words so "Doe, John" and "John Doe" compare equal
+func normalizeDuplicateName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return ' '
+	}, name)
+
+	tokens := strings.Fields(cleaned)
+	sort.Strings(tokens)
+
+	return strings.Join(tokens, " ")
+}
+
+// nameSimilarity returns the normalized Levenshtein similarity of two names (0 to 1)
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// MergeContacts folds a duplicate contact into the survivor in one transaction.
+// Emails, phones, addresses and URLs the survivor lacks are moved across; tags,
+// logs, notes, chats, QSOs, exchange links and relationships are reassigned;
+// empty profile fields on the survivor are filled from the duplicate, which is
+// then deleted.
+func MergeContacts(ctx context.Context, survivorID, duplicateID string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if survivorID == duplicateID {
+		return ErrMergeSameContact
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback contact merge", "error", err)
+		}
+	}()
+
+	var duplicateIsMe bool
+
+	rows, err := tx.Query(ctx, `SELECT id, is_me FROM contacts WHERE id IN ($1, $2) FOR UPDATE`, survivorID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to lock contacts for merge: %w", err)
+	}
+
+	found := 0
+
+	for rows.Next() {
+		var (
+			id   string
+			isMe bool
+		)
+
+		if err := rows.Scan(&id, &isMe); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan contact for merge: %w", err)
+		}
+
+		found++
+
+		if id == duplicateID {
+			duplicateIsMe = isMe
+		}
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating contacts for merge: %w", err)
+	}
+
+	if found != 2 {
+		return ErrContactNotFound
+	}
+
+	if duplicateIsMe {
+		return ErrMergeMeContact
+	}
+
+	statements := []struct {
+		description string
+		query       string
+	}{
+		{"fill profile fields", `
+			UPDATE contacts s SET
+				name_given = COALESCE(s.name_given, d.name_given),
+				name_additional = COALESCE(s.name_additional, d.name_additional),
+				name_family = COALESCE(s.name_family, d.name_family),
+				nickname = COALESCE(s.nickname, d.nickname),
+				organization = COALESCE(s.organization, d.organization),
+				title = COALESCE(s.title, d.title),
+				role = COALESCE(s.role, d.role),
+				birthday = COALESCE(s.birthday, d.birthday),
+				anniversary = COALESCE(s.anniversary, d.anniversary),
+				gender = COALESCE(s.gender, d.gender),
+				timezone = COALESCE(s.timezone, d.timezone),
+				geo_lat = COALESCE(s.geo_lat, d.geo_lat),
+				geo_lon = COALESCE(s.geo_lon, d.geo_lon),
+				language = COALESCE(s.language, d.language),
+				photo_url = COALESCE(s.photo_url,
+					replace(d.photo_url, '/contact/' || d.id::text || '/', '/contact/' || s.id::text || '/')),
+				photo_path = CASE WHEN s.photo_url IS NULL THEN d.photo_path ELSE s.photo_path END,
+				call_sign = COALESCE(s.call_sign, d.call_sign),
+				carddav_uuid = COALESCE(s.carddav_uuid, d.carddav_uuid),
+				carddav_source_id = CASE WHEN s.carddav_uuid IS NULL THEN d.carddav_source_id ELSE s.carddav_source_id END,
+				cadence_days = COALESCE(s.cadence_days, d.cadence_days),
+				last_auto_contact = GREATEST(s.last_auto_contact, d.last_auto_contact),
+				updated_at = now()
+			FROM contacts d
+			WHERE s.id = $1 AND d.id = $2
+		`},
+		{"move emails", `
+			UPDATE contact_emails SET contact_id = $1, is_primary = false
+			WHERE contact_id = $2
+				AND lower(email) NOT IN (SELECT lower(email) FROM contact_emails WHERE contact_id = $1)
+		`},
+		{"move phones", `
+			UPDATE contact_phones SET contact_id = $1, is_primary = false
+			WHERE contact_id = $2
+				AND regexp_replace(phone, '\D', '', 'g') NOT IN (
+					SELECT regexp_replace(phone, '\D', '', 'g') FROM contact_phones WHERE contact_id = $1
+				)
+		`},
+		{"move addresses", `
+			UPDATE contact_addresses d SET contact_id = $1, is_primary = false
+			WHERE d.contact_id = $2
+				AND NOT EXISTS (
+					SELECT 1 FROM contact_addresses s
+					WHERE s.contact_id = $1
+						AND lower(COALESCE(s.street, '')) = lower(COALESCE(d.street, ''))
+						AND lower(COALESCE(s.locality, '')) = lower(COALESCE(d.locality, ''))
+						AND lower(COALESCE(s.postal_code, '')) = lower(COALESCE(d.postal_code, ''))
+						AND lower(COALESCE(s.country, '')) = lower(COALESCE(d.country, ''))
+				)
+		`},
+		{"move URLs", `
+			UPDATE contact_urls SET contact_id = $1
+			WHERE contact_id = $2
+				AND url NOT IN (SELECT url FROM contact_urls WHERE contact_id = $1)
+		`},
+		{"copy tags", `
+			INSERT INTO contact_tags (contact_id, tag_id)
+			SELECT $1, tag_id FROM contact_tags WHERE contact_id = $2
+			ON CONFLICT DO NOTHING
+		`},
+		{"move logs", `
+			UPDATE contact_logs d SET contact_id = $1
+			WHERE d.contact_id = $2
+				AND (d.email_message_id IS NULL OR NOT EXISTS (
+					SELECT 1 FROM contact_logs s
+					WHERE s.contact_id = $1 AND s.email_message_id = d.email_message_id
+				))
+		`},
+		{"move notes", `UPDATE contact_notes SET contact_id = $1 WHERE contact_id = $2`},
+		{"move chats", `UPDATE contact_chats SET contact_id = $1 WHERE contact_id = $2`},
+		{"move QSOs", `UPDATE qsos SET contact_id = $1 WHERE contact_id = $2`},
+		{"move exchange links", `UPDATE contact_exchange_links SET contact_id = $1 WHERE contact_id = $2`},
+		{"move introducers", `UPDATE contact_logs SET introduced_by_contact_id = $1 WHERE introduced_by_contact_id = $2`},
+		{"drop relationships between the merged contacts", `
+			DELETE FROM contact_relationships
+			WHERE (contact_id = $1 AND related_contact_id = $2) OR (contact_id = $2 AND related_contact_id = $1)
+		`},
+		{"move relationships", `
+			UPDATE contact_relationships r SET contact_id = $1
+			WHERE r.contact_id = $2
+				AND NOT EXISTS (
+					SELECT 1 FROM contact_relationships s
+					WHERE s.contact_id = $1 AND s.related_contact_id = r.related_contact_id
+						AND s.relationship_type = r.relationship_type
+				)
+		`},
+		{"move related relationships", `
+			UPDATE contact_relationships r SET related_contact_id = $1
+			WHERE r.related_contact_id = $2
+				AND NOT EXISTS (
+					SELECT 1 FROM contact_relationships s
+					WHERE s.related_contact_id = $1 AND s.contact_id = r.contact_id
+						AND s.relationship_type = r.relationship_type
+				)
+		`},
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement.query, survivorID, duplicateID); err != nil {
+			return fmt.Errorf("failed to %s: %w", statement.description, err)
+		}
+	}
+
+	// Remaining rows (duplicate emails, phones, URLs) cascade with the contact
+	if _, err := tx.Exec(ctx, `DELETE FROM contacts WHERE id = $1`, duplicateID); err != nil {
+		return fmt.Errorf("failed to delete duplicate contact: %w", err)
+	}
+
+	for _, table := range []string{"contact_emails", "contact_phones", "contact_addresses"} {
+		// Table names come from the fixed list above
+		query := `
+			UPDATE ` + table + ` SET is_primary = true
+			WHERE id = (SELECT id FROM ` + table + ` WHERE contact_id = $1 ORDER BY created_at LIMIT 1)
+				AND NOT EXISTS (SELECT 1 FROM ` + table + ` WHERE contact_id = $1 AND is_primary = true)
+		`
+		if _, err := tx.Exec(ctx, query, survivorID); err != nil {
+			return fmt.Errorf("failed to set primary in %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit contact merge: %w", err)
+	}
+
+	return nil
+}
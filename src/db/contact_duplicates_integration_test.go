@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestListDuplicateCandidatesAndMerge(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	survivorID := mustCreateContact(t, CreateContactInput{
+		NameGiven:  "John",
+		NameFamily: stringPtr("Doe"),
+		Email:      stringPtr("john@example.com"),
+		Phone:      stringPtr("+971501234567"),
+		Tier:       TierB,
+	})
+	duplicateID := mustCreateContact(t, CreateContactInput{
+		NameGiven:    "John",
+		NameFamily:   stringPtr("Doe"),
+		Organization: stringPtr("Example Corp"),
+		Email:        stringPtr("JOHN@example.com"),
+		Phone:        stringPtr("+971 55 765 4321"),
+		CallSign:     stringPtr("A65BB"),
+		Tier:         TierC,
+	})
+	mustCreateContact(t, CreateContactInput{NameGiven: "Unrelated", Tier: TierD})
+
+	candidates, err := ListDuplicateCandidates(ctx)
+	if err != nil {
+		t.Fatalf("ListDuplicateCandidates failed: %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].First.ID != survivorID || candidates[0].Second.ID != duplicateID {
+		t.Fatalf("expected one candidate pair, got %+v", candidates)
+	}
+
+	if err := AddAddress(ctx, AddAddressInput{ContactID: duplicateID, AddressFields: AddressFields{
+		Street:   stringPtr("1 Main Street"),
+		Locality: stringPtr("Dubai"),
+	}}); err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+
+	if err := AddTagToContact(ctx, duplicateID, "friends"); err != nil {
+		t.Fatalf("AddTagToContact failed: %v", err)
+	}
+
+	if err := AddLog(ctx, AddLogInput{ContactID: duplicateID, LogType: LogGeneral, Subject: stringPtr("Met up")}); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	if err := MergeContacts(ctx, survivorID, survivorID); !errors.Is(err, ErrMergeSameContact) {
+		t.Fatalf("expected ErrMergeSameContact, got %v", err)
+	}
+
+	if err := MergeContacts(ctx, survivorID, duplicateID); err != nil {
+		t.Fatalf("MergeContacts failed: %v", err)
+	}
+
+	if _, err := GetContact(ctx, duplicateID); err == nil {
+		t.Fatal("expected duplicate contact to be deleted")
+	}
+
+	merged, err := GetContact(ctx, survivorID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(merged.Emails) != 1 || !merged.Emails[0].IsPrimary {
+		t.Fatalf("expected duplicate email to be dropped, got %+v", merged.Emails)
+	}
+
+	if len(merged.Phones) != 2 {
+		t.Fatalf("expected both phones, got %+v", merged.Phones)
+	}
+
+	if len(merged.Addresses) != 1 || !merged.Addresses[0].IsPrimary {
+		t.Fatalf("expected moved address to become primary, got %+v", merged.Addresses)
+	}
+
+	if len(merged.Tags) != 1 || len(merged.Logs) != 1 {
+		t.Fatalf("expected tag and log to move, got %d tags and %d logs", len(merged.Tags), len(merged.Logs))
+	}
+
+	if merged.Organization == nil || *merged.Organization != "Example Corp" {
+		t.Fatalf("expected organization to be filled, got %v", merged.Organization)
+	}
+
+	if merged.CallSign == nil || *merged.CallSign != "A65BB" || merged.Tier != TierB {
+		t.Fatalf("expected call sign to be filled and tier kept, got %v %s", merged.CallSign, merged.Tier)
+	}
+
+	if err := MergeContacts(ctx, survivorID, duplicateID); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound, got %v", err)
+	}
+}
+
+func TestMergeContactsRejectsMeContact(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	meID := mustCreateContact(t, CreateContactInput{NameGiven: "Me", Tier: TierA})
+	otherID := mustCreateContact(t, CreateContactInput{NameGiven: "Me Too", Tier: TierA})
+
+	if err := SetContactAsMe(ctx, meID); err != nil {
+		t.Fatalf("SetContactAsMe failed: %v", err)
+	}
+
+	if err := MergeContacts(ctx, otherID, meID); !errors.Is(err, ErrMergeMeContact) {
+		t.Fatalf("expected ErrMergeMeContact, got %v", err)
+	}
+
+	if err := MergeContacts(ctx, meID, otherID); err != nil {
+		t.Fatalf("expected merging into me contact to succeed, got %v", err)
+	}
+}
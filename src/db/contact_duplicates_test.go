@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeDuplicateName(t *testing.T) {
+	t.Parallel()
+
+	if got := normalizeDuplicateName("Doe, John"); got != "doe john" {
+		t.Fatalf("expected sorted lowercase tokens, got %q", got)
+	}
+
+	if normalizeDuplicateName("John Doe") != normalizeDuplicateName("  DOE john ") {
+		t.Fatal("expected names with reordered words to match")
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	t.Parallel()
+
+	if got := nameSimilarity("jon doe", "john doe"); got < 0.85 || got >= 1 {
+		t.Fatalf("expected high similarity for a typo, got %f", got)
+	}
+
+	if got := nameSimilarity("alice", "robert"); got > 0.3 {
+		t.Fatalf("expected low similarity for different names, got %f", got)
+	}
+
+	if got := nameSimilarity("", ""); got != 0 {
+		t.Fatalf("expected zero similarity for empty names, got %f", got)
+	}
+}
+
+func TestFindDuplicateCandidates(t *testing.T) {
+	t.Parallel()
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.AddDate(0, 1, 0)
+
+	contacts := []DuplicateContact{
+		{ID: "import", NameDisplay: "John Doe", CreatedAt: older, Emails: []string{"John@Example.com"}, Phones: []string{"+971 50 123 4567"}},
+		{ID: "public", NameDisplay: "Jon Doe", CreatedAt: newer, Emails: []string{"john@example.com "}, Phones: []string{"50-123-4567"}},
+		{ID: "radio", NameDisplay: "Someone Else", CreatedAt: older, CallSign: stringPtr("A65AA")},
+		{ID: "radio-2", NameDisplay: "Operator", CreatedAt: newer, CallSign: stringPtr("a65aa")},
+		{ID: "namesake", NameDisplay: "Jane Roe", CreatedAt: older},
+		{ID: "unrelated", NameDisplay: "Jane Smith", CreatedAt: newer},
+	}
+
+	candidates := FindDuplicateCandidates(contacts)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+
+	first := candidates[0]
+	if first.First.ID != "import" || first.Second.ID != "public" {
+		t.Fatalf("expected older contact first, got %s and %s", first.First.ID, first.Second.ID)
+	}
+
+	if first.Score != 100 || len(first.Reasons) != 3 {
+		t.Fatalf("expected capped score with email, phone and name reasons, got %d %v", first.Score, first.Reasons)
+	}
+
+	second := candidates[1]
+	if second.First.ID != "radio" || second.Second.ID != "radio-2" || second.Score != duplicateScoreCallSign {
+		t.Fatalf("expected call sign match, got %+v", second)
+	}
+}
+
+func TestFindDuplicateCandidatesExactNameOnly(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	candidates := FindDuplicateCandidates([]DuplicateContact{
+		{ID: "a", NameDisplay: "Doe, John", CreatedAt: now},
+		{ID: "b", NameDisplay: "John Doe", CreatedAt: now.Add(time.Hour)},
+	})
+
+	if len(candidates) != 1 || candidates[0].Score != duplicateScoreNameExact {
+		t.Fatalf("expected single exact name candidate, got %+v", candidates)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ContactPhotosDir is the WebDAV folder, under the files root, that holds
+// uploaded contact photos. It is marked admin-only in the files browser.
+const ContactPhotosDir = "contacts"
+
+// contactPhotoPath returns where a contact's photo is stored in WebDAV
+func contactPhotoPath(contactID string) string {
+	return path.Join(ContactPhotosDir, contactID+".jpg")
+}
+
+// ContactPhotoURL returns the thumbnail route for a contact's uploaded
+// photo. The version changes on every upload so browsers can cache it.
+func ContactPhotoURL(contactID string, version int64) string {
+	return fmt.Sprintf("/contact/%s/photo?v=%d", contactID, version)
+}
+
+// SetContactPhoto stores a JPEG photo for a contact in WebDAV, replacing any
+// previous upload, and points the contact's photo URL at it.
+func SetContactPhoto(ctx context.Context, contactID string, photo []byte) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	parsedID, err := uuid.Parse(strings.TrimSpace(contactID))
+	if err != nil {
+		return ErrContactNotFound
+	}
+
+	contactID = parsedID.String()
+
+	if len(photo) == 0 {
+		return ErrContactPhotoEmpty
+	}
+
+	exists, err := contactExists(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrContactNotFound
+	}
+
+	config, err := GetWebDAVConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.FilesPath == "" {
+		return ErrWebDAVFilesPathNotConfigured
+	}
+
+	if err := ensureContactPhotosDir(ctx, config); err != nil {
+		return err
+	}
+
+	photoPath := contactPhotoPath(contactID)
+
+	if err := putFilesEntry(ctx, config, photoPath, bytes.NewReader(photo), int64(len(photo))); err != nil {
+		return fmt.Errorf("failed to upload contact photo: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		UPDATE contacts SET photo_path = $2, photo_url = $3, updated_at = now()
+		WHERE id = $1
+	`, contactID, photoPath, ContactPhotoURL(contactID, time.Now().UnixNano()))
+	if err != nil {
+		return fmt.Errorf("failed to update contact photo: %w", err)
+	}
+
+	return nil
+}
+
+// GetContactPhoto returns the uploaded photo of a contact
+func GetContactPhoto(ctx context.Context, contactID string) ([]byte, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	var photoPath *string
+
+	err := pool.QueryRow(ctx, `SELECT photo_path FROM contacts WHERE id = $1`, contactID).Scan(&photoPath)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrContactNotFound
+		}
+
+		return nil, fmt.Errorf("failed to query contact photo: %w", err)
+	}
+
+	if photoPath == nil || *photoPath == "" {
+		return nil, ErrContactPhotoNotFound
+	}
+
+	photo, _, err := FetchFilesFile(ctx, *photoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contact photo: %w", err)
+	}
+
+	return photo, nil
+}
+
+// DeleteContactPhoto removes a contact's photo, including an uploaded file
+func DeleteContactPhoto(ctx context.Context, contactID string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	var photoPath *string
+
+	err := pool.QueryRow(ctx, `
+		UPDATE contacts c SET photo_path = NULL, photo_url = NULL, updated_at = now()
+		FROM contacts old
+		WHERE c.id = $1 AND old.id = c.id
+		RETURNING old.photo_path
+	`, contactID).Scan(&photoPath)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrContactNotFound
+		}
+
+		return fmt.Errorf("failed to clear contact photo: %w", err)
+	}
+
+	if photoPath == nil || *photoPath == "" {
+		return nil
+	}
+
+	if err := DeleteFilesFile(ctx, *photoPath); err != nil && !errors.Is(err, ErrWebDAVFilesEntryNotFound) {
+		return fmt.Errorf("failed to delete contact photo file: %w", err)
+	}
+
+	return nil
+}
+
+// contactExists reports whether a contact row exists
+func contactExists(ctx context.Context, contactID string) (bool, error) {
+	var exists bool
+
+	err := pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM contacts WHERE id = $1)`, contactID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check contact: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ensureContactPhotosDir creates the photos folder on first use and marks it
+// admin-only so photos do not show up for other users in the files browser
+func ensureContactPhotosDir(ctx context.Context, config *WebDAVConfig) error {
+	client, err := newFilesWebDAVClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create WebDAV client: %w", err)
+	}
+
+	exists, err := filesEntryExists(ctx, client, ContactPhotosDir)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if err := client.Mkdir(ctx, ContactPhotosDir); err != nil {
+			return fmt.Errorf("failed to create contact photos directory: %w", err)
+		}
+	}
+
+	markerPath := path.Join(ContactPhotosDir, ".gw_admin")
+
+	exists, err = filesEntryExists(ctx, client, markerPath)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	if err := putFilesEntry(ctx, config, markerPath, bytes.NewReader(nil), 0); err != nil {
+		return fmt.Errorf("failed to mark contact photos directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContactPhotoWithoutUpload(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Ali", Tier: TierB})
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET photo_url = 'https://example.com/ali.jpg' WHERE id = $1`, contactID); err != nil {
+		t.Fatalf("failed to set photo url: %v", err)
+	}
+
+	if _, err := GetContactPhoto(ctx, contactID); !errors.Is(err, ErrContactPhotoNotFound) {
+		t.Fatalf("expected ErrContactPhotoNotFound, got %v", err)
+	}
+
+	// Removing an external photo only clears the URL and never touches WebDAV
+	if err := DeleteContactPhoto(ctx, contactID); err != nil {
+		t.Fatalf("DeleteContactPhoto failed: %v", err)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.PhotoURL != nil || contact.PhotoPath != nil {
+		t.Fatalf("expected photo to be cleared, got %v %v", contact.PhotoURL, contact.PhotoPath)
+	}
+
+	if err := SetContactPhoto(ctx, "not-a-uuid", []byte{1}); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound for an invalid ID, got %v", err)
+	}
+
+	if err := DeleteContactPhoto(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound for a missing contact, got %v", err)
+	}
+}
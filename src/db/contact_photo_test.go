@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+
+	"github.com/emersion/go-vcard"
+)
+
+func TestSetVCardPhoto(t *testing.T) {
+	t.Parallel()
+
+	photo := []byte{0xff, 0xd8, 0xff}
+
+	v4 := make(vcard.Card)
+	v4.SetValue(vcard.FieldVersion, "4.0")
+	v4.SetValue(vcard.FieldPhoto, "https://example.com/old.jpg")
+
+	setVCardPhoto(v4, photo)
+
+	if fields := v4[vcard.FieldPhoto]; len(fields) != 1 || fields[0].Value != "data:image/jpeg;base64,/9j/" {
+		t.Fatalf("unexpected vCard 4.0 photo: %+v", fields)
+	}
+
+	if got := normalizeCardDAVPhoto(v4.Get(vcard.FieldPhoto)); got != "data:image/jpeg;base64,/9j/" {
+		t.Fatalf("expected photo to read back, got %q", got)
+	}
+
+	v3 := make(vcard.Card)
+	v3.SetValue(vcard.FieldVersion, "3.0")
+
+	setVCardPhoto(v3, photo)
+
+	field := v3.Get(vcard.FieldPhoto)
+	if field == nil || field.Value != "/9j/" || field.Params.Get("ENCODING") != "b" {
+		t.Fatalf("unexpected vCard 3.0 photo: %+v", field)
+	}
+
+	if got := normalizeCardDAVPhoto(field); got != "data:image/jpeg;base64,/9j/" {
+		t.Fatalf("expected photo to read back, got %q", got)
+	}
+
+	setVCardPhoto(v3, nil)
+
+	if v3.Get(vcard.FieldPhoto) != nil {
+		t.Fatal("expected an empty photo to remove PHOTO")
+	}
+}
+
+func TestContactPhotoURL(t *testing.T) {
+	t.Parallel()
+
+	if got := ContactPhotoURL("c1", 42); got != "/contact/c1/photo?v=42" {
+		t.Fatalf("unexpected photo URL %q", got)
+	}
+
+	if got := contactPhotoPath("c1"); got != "contacts/c1.jpg" {
+		t.Fatalf("unexpected photo path %q", got)
+	}
+}
@@ -0,0 +1,444 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RelationshipType describes how a related contact relates to a contact
+type RelationshipType string
+
+// RelationshipType values represent supported relationships.
+const (
+	RelationshipSpouse       RelationshipType = "spouse"
+	RelationshipPartner      RelationshipType = "partner"
+	RelationshipParent       RelationshipType = "parent"
+	RelationshipChild        RelationshipType = "child"
+	RelationshipSibling      RelationshipType = "sibling"
+	RelationshipRelative     RelationshipType = "relative"
+	RelationshipFriend       RelationshipType = "friend"
+	RelationshipColleague    RelationshipType = "colleague"
+	RelationshipManager      RelationshipType = "manager"
+	RelationshipReport       RelationshipType = "report"
+	RelationshipMentor       RelationshipType = "mentor"
+	RelationshipMentee       RelationshipType = "mentee"
+	RelationshipIntroducedBy RelationshipType = "introduced_by"
+	RelationshipIntroduced   RelationshipType = "introduced"
+	RelationshipOther        RelationshipType = "other"
+)
+
+// RelationshipTypes lists relationship types in display order
+var RelationshipTypes = []RelationshipType{
+	RelationshipSpouse,
+	RelationshipPartner,
+	RelationshipParent,
+	RelationshipChild,
+	RelationshipSibling,
+	RelationshipRelative,
+	RelationshipFriend,
+	RelationshipColleague,
+	RelationshipManager,
+	RelationshipReport,
+	RelationshipMentor,
+	RelationshipMentee,
+	RelationshipIntroducedBy,
+	RelationshipIntroduced,
+	RelationshipOther,
+}
+
+var relationshipInverses = map[RelationshipType]RelationshipType{
+	RelationshipParent:       RelationshipChild,
+	RelationshipChild:        RelationshipParent,
+	RelationshipManager:      RelationshipReport,
+	RelationshipReport:       RelationshipManager,
+	RelationshipMentor:       RelationshipMentee,
+	RelationshipMentee:       RelationshipMentor,
+	RelationshipIntroducedBy: RelationshipIntroduced,
+	RelationshipIntroduced:   RelationshipIntroducedBy,
+}
+
+var relationshipLabels = map[RelationshipType]string{
+	RelationshipSpouse:       "Spouse",
+	RelationshipPartner:      "Partner",
+	RelationshipParent:       "Parent",
+	RelationshipChild:        "Child",
+	RelationshipSibling:      "Sibling",
+	RelationshipRelative:     "Relative",
+	RelationshipFriend:       "Friend",
+	RelationshipColleague:    "Colleague",
+	RelationshipManager:      "Manager",
+	RelationshipReport:       "Direct report",
+	RelationshipMentor:       "Mentor",
+	RelationshipMentee:       "Mentee",
+	RelationshipIntroducedBy: "Introduced by",
+	RelationshipIntroduced:   "Introduced",
+	RelationshipOther:        "Other",
+}
+
+// Valid reports whether the relationship type is supported
+func (r RelationshipType) Valid() bool {
+	_, ok := relationshipLabels[r]
+	return ok
+}
+
+// Inverse returns the relationship as seen from the related contact's side,
+// so a parent edge reads as child from the other end
+func (r RelationshipType) Inverse() RelationshipType {
+	if inverse, ok := relationshipInverses[r]; ok {
+		return inverse
+	}
+
+	return r
+}
+
+// Label returns a human readable name for the relationship type
+func (r RelationshipType) Label() string {
+	if label, ok := relationshipLabels[r]; ok {
+		return label
+	}
+
+	return string(r)
+}
+
+// ContactRelationship is a relationship seen from one contact's side
+type ContactRelationship struct {
+	ID               uuid.UUID
+	RelatedContactID uuid.UUID
+	RelatedName      string
+	Type             RelationshipType // How the related contact relates to this contact
+	FromIntroLog     bool
+	CreatedAt        time.Time
+}
+
+// AddRelationshipInput represents input for relating two contacts
+type AddRelationshipInput struct {
+	ContactID        string
+	RelatedContactID string
+	Type             RelationshipType
+}
+
+// AddRelationship records that the related contact is the contact's Type
+func AddRelationship(ctx context.Context, input AddRelationshipInput) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if !input.Type.Valid() {
+		return ErrRelationshipTypeInvalid
+	}
+
+	if input.ContactID == input.RelatedContactID {
+		return ErrRelationshipSelf
+	}
+
+	var exists bool
+
+	checkQuery := `
+		SELECT EXISTS(
+			SELECT 1 FROM contact_relationships
+			WHERE (contact_id = $1 AND related_contact_id = $2 AND relationship_type = $3)
+				OR (contact_id = $2 AND related_contact_id = $1 AND relationship_type = $4)
+		)
+	`
+	if err := pool.QueryRow(ctx, checkQuery, input.ContactID, input.RelatedContactID, input.Type, input.Type.Inverse()).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check relationship: %w", err)
+	}
+
+	if exists {
+		return ErrRelationshipExists
+	}
+
+	query := `
+		INSERT INTO contact_relationships (contact_id, related_contact_id, relationship_type)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := pool.Exec(ctx, query, input.ContactID, input.RelatedContactID, input.Type); err != nil {
+		return fmt.Errorf("failed to add relationship: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRelationship removes a relationship that involves the contact on either side
+func DeleteRelationship(ctx context.Context, relationshipID, contactID string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `DELETE FROM contact_relationships WHERE id = $1 AND (contact_id = $2 OR related_contact_id = $2)`
+
+	result, err := pool.Exec(ctx, query, relationshipID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to delete relationship: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRelationshipNotFound
+	}
+
+	return nil
+}
+
+// ListContactRelationships returns every relationship the contact is part of,
+// oriented so Type describes the related contact
+func ListContactRelationships(ctx context.Context, contactID string) ([]ContactRelationship, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	query := `
+		SELECT r.id, r.contact_id, r.related_contact_id, r.relationship_type, r.log_id IS NOT NULL, r.created_at,
+			c.name_display, rc.name_display
+		FROM contact_relationships r
+		JOIN contacts c ON c.id = r.contact_id
+		JOIN contacts rc ON rc.id = r.related_contact_id
+		WHERE r.contact_id = $1 OR r.related_contact_id = $1
+	`
+
+	rows, err := pool.Query(ctx, query, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var relationships []ContactRelationship
+
+	for rows.Next() {
+		var (
+			rel                   ContactRelationship
+			fromID, toID          uuid.UUID
+			fromName, relatedName string
+		)
+
+		if err := rows.Scan(&rel.ID, &fromID, &toID, &rel.Type, &rel.FromIntroLog, &rel.CreatedAt,
+			&fromName, &relatedName); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship: %w", err)
+		}
+
+		rel.RelatedContactID = toID
+		rel.RelatedName = relatedName
+
+		if fromID.String() != contactID {
+			rel.Type = rel.Type.Inverse()
+			rel.RelatedContactID = fromID
+			rel.RelatedName = fromName
+		}
+
+		relationships = append(relationships, rel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating relationships: %w", err)
+	}
+
+	sortContactRelationships(relationships)
+
+	return relationships, nil
+}
+
+// sortContactRelationships orders relationships by type display order, then name
+func sortContactRelationships(relationships []ContactRelationship) {
+	order := make(map[RelationshipType]int, len(RelationshipTypes))
+	for i, relationshipType := range RelationshipTypes {
+		order[relationshipType] = i
+	}
+
+	sort.SliceStable(relationships, func(i, j int) bool {
+		if order[relationships[i].Type] != order[relationships[j].Type] {
+			return order[relationships[i].Type] < order[relationships[j].Type]
+		}
+
+		return relationships[i].RelatedName < relationships[j].RelatedName
+	})
+}
+
+// ListContactNames returns all non-service contacts for pickers, sorted by name
+func ListContactNames(ctx context.Context) ([]ContactName, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `SELECT id, name_display FROM contacts WHERE is_service = false ORDER BY name_display ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contact names: %w", err)
+	}
+	defer rows.Close()
+
+	var names []ContactName
+
+	for rows.Next() {
+		var name ContactName
+		if err := rows.Scan(&name.ID, &name.NameDisplay); err != nil {
+			return nil, fmt.Errorf("failed to scan contact name: %w", err)
+		}
+
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contact names: %w", err)
+	}
+
+	return names, nil
+}
+
+// RelationshipGraphNode is a contact shown in the relationship graph
+type RelationshipGraphNode struct {
+	ID          string
+	NameDisplay string
+	Tier        Tier
+}
+
+// RelationshipGraphEdge is a relationship shown in the relationship graph.
+// It reads as "To is From's Type".
+type RelationshipGraphEdge struct {
+	From string
+	To   string
+	Type RelationshipType
+}
+
+// RelationshipGraph holds the contacts and relationships to draw
+type RelationshipGraph struct {
+	Nodes []RelationshipGraphNode
+	Edges []RelationshipGraphEdge
+}
+
+// GetRelationshipGraph returns every related contact and the edges between them
+func GetRelationshipGraph(ctx context.Context) (*RelationshipGraph, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	graph := &RelationshipGraph{}
+
+	edgeRows, err := pool.Query(ctx, `
+		SELECT contact_id, related_contact_id, relationship_type
+		FROM contact_relationships
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationship edges: %w", err)
+	}
+	defer edgeRows.Close()
+
+	for edgeRows.Next() {
+		var edge RelationshipGraphEdge
+		if err := edgeRows.Scan(&edge.From, &edge.To, &edge.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship edge: %w", err)
+		}
+
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	if err := edgeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating relationship edges: %w", err)
+	}
+
+	nodeRows, err := pool.Query(ctx, `
+		SELECT id, name_display, tier
+		FROM contacts c
+		WHERE EXISTS (
+			SELECT 1 FROM contact_relationships r
+			WHERE r.contact_id = c.id OR r.related_contact_id = c.id
+		)
+		ORDER BY name_display
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationship nodes: %w", err)
+	}
+	defer nodeRows.Close()
+
+	for nodeRows.Next() {
+		var node RelationshipGraphNode
+		if err := nodeRows.Scan(&node.ID, &node.NameDisplay, &node.Tier); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship node: %w", err)
+		}
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	if err := nodeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating relationship nodes: %w", err)
+	}
+
+	return graph, nil
+}
+
+// Neighborhood returns the part of the graph within depth hops of the contact
+func (g *RelationshipGraph) Neighborhood(contactID string, depth int) *RelationshipGraph {
+	distance := map[string]int{contactID: 0}
+	frontier := []string{contactID}
+
+	adjacent := make(map[string][]string)
+	for _, edge := range g.Edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+		adjacent[edge.To] = append(adjacent[edge.To], edge.From)
+	}
+
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		var next []string
+
+		for _, id := range frontier {
+			for _, neighbor := range adjacent[id] {
+				if _, seen := distance[neighbor]; !seen {
+					distance[neighbor] = hop
+					next = append(next, neighbor)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	sub := &RelationshipGraph{}
+
+	for _, node := range g.Nodes {
+		if _, ok := distance[node.ID]; ok {
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		_, fromOK := distance[edge.From]
+		_, toOK := distance[edge.To]
+
+		if fromOK && toOK {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+
+	return sub
+}
+
+// recordIntroduction links an intro log to the contact who made the introduction
+func recordIntroduction(ctx context.Context, tx pgx.Tx, contactID, introducerID, logID string) error {
+	if contactID == introducerID {
+		return ErrRelationshipSelf
+	}
+
+	query := `
+		INSERT INTO contact_relationships (contact_id, related_contact_id, relationship_type, log_id)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM contact_relationships
+			WHERE (contact_id = $1 AND related_contact_id = $2 AND relationship_type = $3)
+				OR (contact_id = $2 AND related_contact_id = $1 AND relationship_type = $5)
+		)
+	`
+
+	if _, err := tx.Exec(ctx, query, contactID, introducerID, RelationshipIntroducedBy, logID, RelationshipIntroduced); err != nil {
+		return fmt.Errorf("failed to record introduction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContactRelationships(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	parentID := mustCreateContact(t, CreateContactInput{NameGiven: "Parent", Tier: TierB})
+	childID := mustCreateContact(t, CreateContactInput{NameGiven: "Child", Tier: TierC})
+
+	if err := AddRelationship(ctx, AddRelationshipInput{ContactID: childID, RelatedContactID: parentID, Type: RelationshipParent}); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	err := AddRelationship(ctx, AddRelationshipInput{ContactID: parentID, RelatedContactID: childID, Type: RelationshipChild})
+	if !errors.Is(err, ErrRelationshipExists) {
+		t.Fatalf("expected ErrRelationshipExists for the inverse edge, got %v", err)
+	}
+
+	if err := AddRelationship(ctx, AddRelationshipInput{ContactID: childID, RelatedContactID: childID, Type: RelationshipFriend}); !errors.Is(err, ErrRelationshipSelf) {
+		t.Fatalf("expected ErrRelationshipSelf, got %v", err)
+	}
+
+	if err := AddRelationship(ctx, AddRelationshipInput{ContactID: childID, RelatedContactID: parentID, Type: "enemy"}); !errors.Is(err, ErrRelationshipTypeInvalid) {
+		t.Fatalf("expected ErrRelationshipTypeInvalid, got %v", err)
+	}
+
+	fromChild, err := ListContactRelationships(ctx, childID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(fromChild) != 1 || fromChild[0].Type != RelationshipParent || fromChild[0].RelatedName != "Parent" {
+		t.Fatalf("expected parent relationship from child side, got %+v", fromChild)
+	}
+
+	fromParent, err := ListContactRelationships(ctx, parentID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(fromParent) != 1 || fromParent[0].Type != RelationshipChild || fromParent[0].RelatedContactID.String() != childID {
+		t.Fatalf("expected child relationship from parent side, got %+v", fromParent)
+	}
+
+	graph, err := GetRelationshipGraph(ctx)
+	if err != nil {
+		t.Fatalf("GetRelationshipGraph failed: %v", err)
+	}
+
+	if len(graph.Nodes) != 2 || len(graph.Edges) != 1 {
+		t.Fatalf("expected two nodes and one edge, got %+v", graph)
+	}
+
+	if err := DeleteRelationship(ctx, fromParent[0].ID.String(), parentID); err != nil {
+		t.Fatalf("DeleteRelationship failed: %v", err)
+	}
+
+	if err := DeleteRelationship(ctx, fromParent[0].ID.String(), parentID); !errors.Is(err, ErrRelationshipNotFound) {
+		t.Fatalf("expected ErrRelationshipNotFound, got %v", err)
+	}
+}
+
+func TestIntroLogRecordsIntroducer(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	introducerID := mustCreateContact(t, CreateContactInput{NameGiven: "Introducer", Tier: TierB})
+	newFriendID := mustCreateContact(t, CreateContactInput{NameGiven: "New Friend", Tier: TierC})
+
+	if err := AddLog(ctx, AddLogInput{
+		ContactID:      newFriendID,
+		LogType:        LogIntro,
+		Subject:        stringPtr("Met at a conference"),
+		IntroducedByID: &introducerID,
+	}); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	detail, err := GetContact(ctx, newFriendID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(detail.Logs) != 1 || detail.Logs[0].IntroducedByID == nil || detail.Logs[0].IntroducedByName == nil ||
+		*detail.Logs[0].IntroducedByName != "Introducer" {
+		t.Fatalf("expected introducer on intro log, got %+v", detail.Logs)
+	}
+
+	relationships, err := ListContactRelationships(ctx, introducerID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 1 || relationships[0].Type != RelationshipIntroduced || !relationships[0].FromIntroLog {
+		t.Fatalf("expected introduced relationship from intro log, got %+v", relationships)
+	}
+
+	if err := DeleteLog(ctx, detail.Logs[0].ID.String()); err != nil {
+		t.Fatalf("DeleteLog failed: %v", err)
+	}
+
+	relationships, err = ListContactRelationships(ctx, introducerID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 0 {
+		t.Fatalf("expected relationship to be removed with its intro log, got %+v", relationships)
+	}
+
+	if err := AddLog(ctx, AddLogInput{ContactID: newFriendID, LogType: LogGeneral, IntroducedByID: &introducerID}); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	relationships, err = ListContactRelationships(ctx, introducerID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 0 {
+		t.Fatalf("expected non-intro logs to ignore the introducer, got %+v", relationships)
+	}
+}
+
+func TestMergeContactsMovesRelationships(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	survivorID := mustCreateContact(t, CreateContactInput{NameGiven: "Sam", Tier: TierB})
+	duplicateID := mustCreateContact(t, CreateContactInput{NameGiven: "Sam", Tier: TierB})
+	spouseID := mustCreateContact(t, CreateContactInput{NameGiven: "Spouse", Tier: TierB})
+
+	if err := AddRelationship(ctx, AddRelationshipInput{ContactID: duplicateID, RelatedContactID: spouseID, Type: RelationshipSpouse}); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	if err := AddRelationship(ctx, AddRelationshipInput{ContactID: survivorID, RelatedContactID: duplicateID, Type: RelationshipOther}); err != nil {
+		t.Fatalf("AddRelationship failed: %v", err)
+	}
+
+	if err := MergeContacts(ctx, survivorID, duplicateID); err != nil {
+		t.Fatalf("MergeContacts failed: %v", err)
+	}
+
+	relationships, err := ListContactRelationships(ctx, survivorID)
+	if err != nil {
+		t.Fatalf("ListContactRelationships failed: %v", err)
+	}
+
+	if len(relationships) != 1 || relationships[0].RelatedContactID.String() != spouseID {
+		t.Fatalf("expected spouse relationship to move to survivor, got %+v", relationships)
+	}
+}
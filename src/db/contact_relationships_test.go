@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import "testing"
+
+func TestRelationshipTypeInverse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[RelationshipType]RelationshipType{
+		RelationshipParent:       RelationshipChild,
+		RelationshipChild:        RelationshipParent,
+		RelationshipManager:      RelationshipReport,
+		RelationshipIntroducedBy: RelationshipIntroduced,
+		RelationshipSpouse:       RelationshipSpouse,
+		RelationshipColleague:    RelationshipColleague,
+	}
+
+	for relationshipType, want := range tests {
+		if got := relationshipType.Inverse(); got != want {
+			t.Fatalf("%s.Inverse() = %s, want %s", relationshipType, got, want)
+		}
+	}
+
+	for _, relationshipType := range RelationshipTypes {
+		if !relationshipType.Valid() {
+			t.Fatalf("expected %s to be valid", relationshipType)
+		}
+
+		if relationshipType.Inverse().Inverse() != relationshipType {
+			t.Fatalf("expected %s inverse to round-trip", relationshipType)
+		}
+	}
+
+	if RelationshipType("enemy").Valid() {
+		t.Fatal("expected unknown relationship type to be invalid")
+	}
+}
+
+func TestRelationshipGraphNeighborhood(t *testing.T) {
+	t.Parallel()
+
+	graph := &RelationshipGraph{
+		Nodes: []RelationshipGraphNode{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}},
+		Edges: []RelationshipGraphEdge{
+			{From: "a", To: "b", Type: RelationshipSpouse},
+			{From: "c", To: "b", Type: RelationshipParent},
+			{From: "c", To: "d", Type: RelationshipColleague},
+			{From: "e", To: "d", Type: RelationshipFriend},
+		},
+	}
+
+	sub := graph.Neighborhood("a", 2)
+	if len(sub.Nodes) != 3 || len(sub.Edges) != 2 {
+		t.Fatalf("expected a, b and c with two edges, got %+v", sub)
+	}
+
+	if sub := graph.Neighborhood("missing", 2); len(sub.Nodes) != 0 || len(sub.Edges) != 0 {
+		t.Fatalf("expected empty neighborhood for unknown contact, got %+v", sub)
+	}
+}
+
+func TestSortContactRelationships(t *testing.T) {
+	t.Parallel()
+
+	relationships := []ContactRelationship{
+		{RelatedName: "Zed", Type: RelationshipFriend},
+		{RelatedName: "Bea", Type: RelationshipFriend},
+		{RelatedName: "Max", Type: RelationshipSpouse},
+	}
+
+	sortContactRelationships(relationships)
+
+	if relationships[0].RelatedName != "Max" || relationships[1].RelatedName != "Bea" || relationships[2].RelatedName != "Zed" {
+		t.Fatalf("unexpected order %+v", relationships)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContactTimelineKind identifies which channel a timeline entry came from
+type ContactTimelineKind string
+
+const (
+	TimelineKindLog         ContactTimelineKind = "log"
+	TimelineKindNote        ContactTimelineKind = "note"
+	TimelineKindChat        ContactTimelineKind = "chat"
+	TimelineKindQSO         ContactTimelineKind = "qso"
+	TimelineKindMention     ContactTimelineKind = "mention"
+	TimelineKindTransaction ContactTimelineKind = "transaction"
+	TimelineKindExchange    ContactTimelineKind = "exchange"
+)
+
+// ContactTimelineKinds lists every kind in display order
+var ContactTimelineKinds = []ContactTimelineKind{
+	TimelineKindLog,
+	TimelineKindNote,
+	TimelineKindChat,
+	TimelineKindQSO,
+	TimelineKindMention,
+	TimelineKindTransaction,
+	TimelineKindExchange,
+}
+
+// Label returns a human readable name for the kind
+func (k ContactTimelineKind) Label() string {
+	switch k {
+	case TimelineKindLog:
+		return "Log"
+	case TimelineKindNote:
+		return "Note"
+	case TimelineKindChat:
+		return "Chat"
+	case TimelineKindQSO:
+		return "QSO"
+	case TimelineKindMention:
+		return "Mention"
+	case TimelineKindTransaction:
+		return "Transaction"
+	case TimelineKindExchange:
+		return "Contact Exchange"
+	default:
+		return string(k)
+	}
+}
+
+// IsValidContactTimelineKind reports whether kind is a known timeline kind
+func IsValidContactTimelineKind(kind string) bool {
+	for _, k := range ContactTimelineKinds {
+		if string(k) == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	defaultContactTimelinePageSize = 50
+	maxContactTimelinePageSize     = 200
+)
+
+// ContactTimelineOptions selects a page of a contact's timeline
+type ContactTimelineOptions struct {
+	ContactID string
+	Kinds     []ContactTimelineKind // empty includes every kind
+	Page      int                   // 1-based
+	PageSize  int
+}
+
+// ContactTimelineEntry is a single dated event involving a contact
+type ContactTimelineEntry struct {
+	Kind       ContactTimelineKind
+	ID         string
+	OccurredAt time.Time
+	Title      string
+	Meta       string
+	Detail     string
+	URL        string
+}
+
+// ContactTimelinePage is one page of a contact's timeline, newest first
+type ContactTimelinePage struct {
+	Entries     []ContactTimelineEntry
+	Page        int
+	HasPrevious bool
+	HasNext     bool
+}
+
+// contactTimelineBranches holds one query per database-backed kind. Each
+// selects the same columns so they can be combined with UNION ALL. The
+// contact CTE is the contact being viewed ($1).
+var contactTimelineBranches = map[ContactTimelineKind]string{
+	TimelineKindLog: `
+		SELECT 'log'::text, l.id::text, ''::text, l.logged_at,
+		       coalesce(l.subject, ''), l.log_type::text, coalesce(l.content, '')
+		FROM contact_logs l
+		WHERE l.contact_id = $1`,
+	TimelineKindNote: `
+		SELECT 'note'::text, n.id::text, ''::text, n.noted_at,
+		       ''::text, ''::text, n.content
+		FROM contact_notes n
+		WHERE n.contact_id = $1`,
+	TimelineKindChat: `
+		SELECT 'chat'::text, ch.id::text, ''::text, ch.sent_at,
+		       CASE ch.sender WHEN 'me' THEN 'Sent' WHEN 'them' THEN 'Received' ELSE 'Conversation' END,
+		       ch.platform::text, ch.message
+		FROM contact_chats ch
+		WHERE ch.contact_id = $1`,
+	TimelineKindQSO: `
+		SELECT 'qso'::text, q.id::text, ''::text, (q.qso_date + q.time_on) AT TIME ZONE 'UTC',
+		       'QSO with ' || q.call, concat_ws(' · ', q.band, q.mode), ''::text
+		FROM qsos q CROSS JOIN contact
+		WHERE q.contact_id = contact.id
+		   OR (contact.call_sign IS NOT NULL AND UPPER(q.call) = UPPER(contact.call_sign))`,
+	TimelineKindTransaction: `
+		SELECT 'transaction'::text, t.id::text, t.account_id::text, t.occurred_at,
+		       t.merchant, a.name || ' · ' || t.amount::text, coalesce(t.note, '')
+		FROM ledger_transactions t
+		INNER JOIN ledger_accounts a ON a.id = t.account_id
+		CROSS JOIN contact
+		WHERE lower(trim(t.merchant)) IN (lower(contact.name_display), lower(trim(contact.organization)))`,
+	TimelineKindExchange: `
+		SELECT 'exchange'::text, x.id::text, ''::text, x.created_at,
+		       'Exchange link created'::text, ''::text, ''::text
+		FROM contact_exchange_links x
+		WHERE x.contact_id = $1
+		UNION ALL
+		SELECT 'exchange'::text, x.id::text, ''::text, x.used_at,
+		       'Exchange link used'::text, ''::text, ''::text
+		FROM contact_exchange_links x
+		WHERE x.contact_id = $1 AND x.used_at IS NOT NULL`,
+}
+
+// contactTimelineURL returns the page an entry links to
+func contactTimelineURL(kind ContactTimelineKind, contactID, id, parentID string) string {
+	switch kind {
+	case TimelineKindChat:
+		return "/contact/" + contactID + "/chats"
+	case TimelineKindQSO:
+		return "/qsl/" + id
+	case TimelineKindTransaction:
+		return "/ledger/accounts/" + parentID
+	case TimelineKindMention:
+		if strings.HasPrefix(id, DailyBacklinkPrefix) {
+			return "/journal/" + strings.TrimPrefix(id, DailyBacklinkPrefix)
+		}
+
+		return "/zk/" + url.PathEscape(id)
+	default:
+		return ""
+	}
+}
+
+// ListContactTimeline returns one page of everything that happened with a
+// contact: logs, notes, chats, QSOs, zettelkasten mentions, ledger
+// transactions with a matching merchant and contact exchange events.
+func ListContactTimeline(ctx context.Context, opts ContactTimelineOptions) (*ContactTimelinePage, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	contactID := strings.TrimSpace(opts.ContactID)
+	if contactID == "" {
+		return nil, ErrContactIDRequired
+	}
+
+	page := opts.Page
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultContactTimelinePageSize
+	}
+
+	if pageSize > maxContactTimelinePageSize {
+		pageSize = maxContactTimelinePageSize
+	}
+
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = ContactTimelineKinds
+	}
+
+	// Fetch enough of every source to fill this page and detect a next one
+	offset := (page - 1) * pageSize
+	limit := offset + pageSize + 1
+
+	entries := []ContactTimelineEntry{}
+	branches := make([]string, 0, len(kinds))
+	includeMentions := false
+
+	for _, kind := range kinds {
+		if kind == TimelineKindMention {
+			includeMentions = true
+			continue
+		}
+
+		if branch, ok := contactTimelineBranches[kind]; ok {
+			branches = append(branches, branch)
+		}
+	}
+
+	if len(branches) > 0 {
+		query := `
+			WITH contact AS (
+				SELECT id, name_display, organization, call_sign FROM contacts WHERE id = $1
+			)
+			SELECT r.kind, r.id, r.parent_id, r.occurred_at, r.title, r.meta, r.detail
+			FROM (` + strings.Join(branches, "\n\t\tUNION ALL") + `
+			) AS r (kind, id, parent_id, occurred_at, title, meta, detail)
+			ORDER BY r.occurred_at DESC, r.kind, r.id
+			LIMIT $2
+		`
+
+		rows, err := pool.Query(ctx, query, contactID, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list contact timeline: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				entry    ContactTimelineEntry
+				kind     string
+				parentID string
+			)
+
+			if err := rows.Scan(&kind, &entry.ID, &parentID, &entry.OccurredAt, &entry.Title, &entry.Meta, &entry.Detail); err != nil {
+				return nil, fmt.Errorf("failed to scan contact timeline entry: %w", err)
+			}
+
+			entry.Kind = ContactTimelineKind(kind)
+			entry.URL = contactTimelineURL(entry.Kind, contactID, entry.ID, parentID)
+			entries = append(entries, entry)
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate contact timeline: %w", err)
+		}
+	}
+
+	if includeMentions {
+		notesByID := make(map[string]ZKTimelineNote)
+
+		for _, notes := range GetZKTimelineNotesByDate() {
+			for _, note := range notes {
+				notesByID[note.ID] = note
+			}
+		}
+
+		entries = append(entries, contactMentionEntries(GetContactLinksFromCache(contactID), notesByID)...)
+	}
+
+	return paginateContactTimeline(entries, page, pageSize), nil
+}
+
+// contactMentionEntries turns cached zettelkasten backlinks into timeline
+// entries. Daily notes are dated by their day and other notes by their
+// filename timestamp; notes without a known date are left out.
+func contactMentionEntries(sourceIDs []string, notesByID map[string]ZKTimelineNote) []ContactTimelineEntry {
+	entries := make([]ContactTimelineEntry, 0, len(sourceIDs))
+
+	for _, sourceID := range sourceIDs {
+		entry := ContactTimelineEntry{
+			Kind: TimelineKindMention,
+			ID:   sourceID,
+			URL:  contactTimelineURL(TimelineKindMention, "", sourceID, ""),
+		}
+
+		if strings.HasPrefix(sourceID, DailyBacklinkPrefix) {
+			dateString := strings.TrimPrefix(sourceID, DailyBacklinkPrefix)
+
+			date, err := time.Parse("2006-01-02", dateString)
+			if err != nil {
+				continue
+			}
+
+			entry.OccurredAt = date
+			entry.Title = dateString
+			entry.Meta = "Journal"
+
+			if journal, ok := GetJournalEntryByDate(dateString); ok && journal.Title != "" {
+				entry.Title = journal.Title
+			}
+		} else {
+			note, ok := notesByID[sourceID]
+			if !ok || note.Timestamp.IsZero() {
+				continue
+			}
+
+			entry.OccurredAt = note.Timestamp
+			entry.Title = note.Title
+			entry.Meta = "Zettelkasten"
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// paginateContactTimeline sorts entries newest first and slices out a page
+func paginateContactTimeline(entries []ContactTimelineEntry, page, pageSize int) *ContactTimelinePage {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.After(entries[j].OccurredAt)
+	})
+
+	result := &ContactTimelinePage{
+		Entries:     []ContactTimelineEntry{},
+		Page:        page,
+		HasPrevious: page > 1,
+	}
+
+	offset := (page - 1) * pageSize
+	if offset >= len(entries) {
+		return result
+	}
+
+	end := offset + pageSize
+	if end < len(entries) {
+		result.HasNext = true
+	} else {
+		end = len(entries)
+	}
+
+	result.Entries = entries[offset:end]
+
+	return result
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+func TestListContactTimelineMergesChannels(t *testing.T) {
+	resetDatabase(t)
+	resetZettelkastenCaches()
+	t.Cleanup(resetZettelkastenCaches)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{
+		NameGiven: "Ali",
+		CallSign:  stringPtr("A65AA"),
+		Tier:      TierB,
+	})
+	otherID := mustCreateContact(t, CreateContactInput{NameGiven: "Omar", Tier: TierB})
+
+	if err := AddLog(ctx, AddLogInput{
+		ContactID: contactID,
+		LogType:   LogCall,
+		LoggedAt:  stringPtr("2024-01-01T10:00:00Z"),
+		Subject:   stringPtr("Catch up"),
+	}); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	if err := AddNote(ctx, AddNoteInput{ContactID: contactID, Content: "Likes tea", NotedAt: stringPtr("2024-01-02T10:00:00Z")}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	if err := AddNote(ctx, AddNoteInput{ContactID: otherID, Content: "Not Ali", NotedAt: stringPtr("2024-01-02T11:00:00Z")}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	if err := AddChat(ctx, AddChatInput{
+		ContactID: contactID,
+		Platform:  ChatPlatformWhatsApp,
+		Sender:    ChatSenderMe,
+		Message:   "Hello",
+		SentAt:    stringPtr("2024-01-03T10:00:00Z"),
+	}); err != nil {
+		t.Fatalf("AddChat failed: %v", err)
+	}
+
+	if _, err := ImportADIFQSOs(ctx, []utils.QSO{
+		{Call: "A65AA", QSODate: "20240104", TimeOn: "100000", Mode: "SSB"},
+	}); err != nil {
+		t.Fatalf("ImportADIFQSOs failed: %v", err)
+	}
+
+	accountID, err := CreateLedgerAccount(ctx, CreateLedgerAccountInput{Name: "Checking", AccountType: LedgerAccountRegular})
+	if err != nil {
+		t.Fatalf("CreateLedgerAccount failed: %v", err)
+	}
+
+	if _, err := CreateLedgerTransaction(ctx, CreateLedgerTransactionInput{
+		AccountID:  accountID,
+		Amount:     -25,
+		Merchant:   " ali ",
+		Status:     LedgerTransactionCleared,
+		OccurredAt: time.Date(2024, time.January, 5, 10, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Fatalf("CreateLedgerTransaction failed: %v", err)
+	}
+
+	backlinkMutex.Lock()
+	contactLinkCache[contactID] = []string{DailyBacklinkPrefix + "2024-01-06"}
+	backlinkMutex.Unlock()
+
+	timeline, err := ListContactTimeline(ctx, ContactTimelineOptions{ContactID: contactID})
+	if err != nil {
+		t.Fatalf("ListContactTimeline failed: %v", err)
+	}
+
+	wantKinds := []ContactTimelineKind{
+		TimelineKindMention,
+		TimelineKindTransaction,
+		TimelineKindQSO,
+		TimelineKindChat,
+		TimelineKindNote,
+		TimelineKindLog,
+	}
+
+	if len(timeline.Entries) != len(wantKinds) {
+		t.Fatalf("expected %d entries, got %+v", len(wantKinds), timeline.Entries)
+	}
+
+	for i, kind := range wantKinds {
+		if timeline.Entries[i].Kind != kind {
+			t.Fatalf("entry %d: expected kind %s, got %+v", i, kind, timeline.Entries[i])
+		}
+	}
+
+	if timeline.Entries[2].URL == "" || timeline.Entries[3].Title != "Sent" || timeline.Entries[3].Meta != "whatsapp" {
+		t.Fatalf("unexpected QSO or chat entry: %+v %+v", timeline.Entries[2], timeline.Entries[3])
+	}
+
+	paged, err := ListContactTimeline(ctx, ContactTimelineOptions{ContactID: contactID, Page: 2, PageSize: 4})
+	if err != nil {
+		t.Fatalf("ListContactTimeline page 2 failed: %v", err)
+	}
+
+	if len(paged.Entries) != 2 || paged.Entries[0].Kind != TimelineKindNote || !paged.HasPrevious || paged.HasNext {
+		t.Fatalf("unexpected second page: %+v", paged)
+	}
+
+	chats, err := ListContactTimeline(ctx, ContactTimelineOptions{ContactID: contactID, Kinds: []ContactTimelineKind{TimelineKindChat}})
+	if err != nil {
+		t.Fatalf("ListContactTimeline chats failed: %v", err)
+	}
+
+	if len(chats.Entries) != 1 || chats.Entries[0].Detail != "Hello" {
+		t.Fatalf("expected only the chat entry, got %+v", chats.Entries)
+	}
+}
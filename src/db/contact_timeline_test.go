@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContactMentionEntriesSkipsUndatedNotes(t *testing.T) {
+	t.Parallel()
+
+	noted := time.Date(2024, time.May, 4, 9, 30, 0, 0, time.UTC)
+	notesByID := map[string]ZKTimelineNote{
+		"note-1": {ID: "note-1", Title: "Antenna build", Timestamp: noted},
+	}
+
+	entries := contactMentionEntries([]string{
+		"note-1",
+		"note-missing",
+		DailyBacklinkPrefix + "2024-06-01",
+		DailyBacklinkPrefix + "not-a-date",
+	}, notesByID)
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 mention entries, got %+v", entries)
+	}
+
+	if entries[0].Title != "Antenna build" || !entries[0].OccurredAt.Equal(noted) || entries[0].URL != "/zk/note-1" {
+		t.Fatalf("unexpected note mention: %+v", entries[0])
+	}
+
+	if entries[1].Title != "2024-06-01" || entries[1].URL != "/journal/2024-06-01" || entries[1].Kind != TimelineKindMention {
+		t.Fatalf("unexpected daily mention: %+v", entries[1])
+	}
+}
+
+func TestPaginateContactTimeline(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := make([]ContactTimelineEntry, 0, 5)
+	for i := range 5 {
+		entries = append(entries, ContactTimelineEntry{
+			ID:         string(rune('a' + i)),
+			OccurredAt: base.AddDate(0, 0, i),
+		})
+	}
+
+	first := paginateContactTimeline(entries, 1, 2)
+	if len(first.Entries) != 2 || first.Entries[0].ID != "e" || first.Entries[1].ID != "d" {
+		t.Fatalf("expected newest entries first, got %+v", first.Entries)
+	}
+
+	if first.HasPrevious || !first.HasNext {
+		t.Fatalf("unexpected first page flags: %+v", first)
+	}
+
+	last := paginateContactTimeline(entries, 3, 2)
+	if len(last.Entries) != 1 || last.Entries[0].ID != "a" || !last.HasPrevious || last.HasNext {
+		t.Fatalf("unexpected last page: %+v", last)
+	}
+
+	beyond := paginateContactTimeline(entries, 4, 2)
+	if len(beyond.Entries) != 0 || beyond.HasNext {
+		t.Fatalf("expected empty page beyond the end, got %+v", beyond)
+	}
+}
+
+func TestIsValidContactTimelineKind(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range ContactTimelineKinds {
+		if !IsValidContactTimelineKind(string(kind)) {
+			t.Fatalf("expected %q to be valid", kind)
+		}
+	}
+
+	if IsValidContactTimelineKind("email") {
+		t.Fatal("expected unknown kind to be invalid")
+	}
+}
@@ -0,0 +1,746 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Groundwave specific vCard properties. Other clients keep or ignore X-
+// properties, so exporting and importing again does not lose CRM data.
+const (
+	VCardFieldTier     = "X-GROUNDWAVE-TIER"
+	VCardFieldCallSign = "X-GROUNDWAVE-CALLSIGN"
+	VCardFieldTags     = "X-GROUNDWAVE-TAGS"
+	VCardFieldService  = "X-GROUNDWAVE-SERVICE"
+)
+
+var (
+	vCardEmailPattern    = regexp.MustCompile(`^\S+@\S+\.\S+$`)
+	vCardCallSignPattern = regexp.MustCompile(`^[A-Z0-9]{3,8}$`)
+	vCardTagPattern      = regexp.MustCompile(`^[a-z0-9._:-]+$`)
+)
+
+// vCardMinPhoneDigits is the shortest number used to match an existing
+// contact, so extensions and short codes do not merge unrelated people
+const vCardMinPhoneDigits = 7
+
+// VCardExportOptions selects the contacts written by ExportContactsVCard.
+// Without a tag or query every contact is exported, service contacts included.
+type VCardExportOptions struct {
+	TagIDs      []string
+	SearchQuery string
+}
+
+// VCardImportResult summarises an import
+type VCardImportResult struct {
+	Created int
+	Updated int
+	Skipped int // Cards without a name
+}
+
+// vCardURL is a URL read from a card along with its Groundwave type
+type vCardURL struct {
+	URL     string
+	URLType URLType
+}
+
+// vCardMatchLookup is one way of finding the contact a card describes
+type vCardMatchLookup struct {
+	query string
+	arg   any
+}
+
+// vCardImport is a card parsed for import. The standard fields reuse the
+// CardDAV parser; the rest are fields CardDAV sync does not manage.
+type vCardImport struct {
+	CardDAVContact
+	Role      string
+	Timezone  string
+	Language  string
+	GeoLat    *float64
+	GeoLon    *float64
+	URLs      []vCardURL
+	Tier      Tier
+	CallSign  string
+	Tags      []string
+	IsService bool
+}
+
+// nameDisplay returns the formatted name, falling back to the name parts
+func (v vCardImport) nameDisplay() string {
+	if name := strings.TrimSpace(v.DisplayName); name != "" {
+		return name
+	}
+
+	return strings.TrimSpace(strings.Join(strings.Fields(v.GivenName+" "+v.FamilyName), " "))
+}
+
+// ExportContactsVCard writes the selected contacts as a vCard 4.0 file
+func ExportContactsVCard(ctx context.Context, opts VCardExportOptions) ([]byte, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	var buffer bytes.Buffer
+
+	encoder := vcard.NewEncoder(&buffer)
+
+	for _, isService := range []bool{false, true} {
+		contacts, err := ListContactsWithFilters(ctx, ContactListOptions{
+			TagIDs:         opts.TagIDs,
+			SearchQuery:    opts.SearchQuery,
+			IsService:      isService,
+			AlphabeticSort: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range contacts {
+			contact, err := getContactDetail(ctx, item.ID, false)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := encoder.Encode(contactVCard(contact)); err != nil {
+				return nil, fmt.Errorf("failed to encode vcard: %w", err)
+			}
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// contactVCard builds a full vCard 4.0 for a contact, including the
+// Groundwave extensions
+func contactVCard(contact *ContactDetail) vcard.Card {
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldUID, contact.ID.String())
+	card.SetValue(vcard.FieldFormattedName, contact.NameDisplay)
+	card.AddName(&vcard.Name{
+		GivenName:      pointerString(contact.NameGiven),
+		AdditionalName: pointerString(contact.NameAdditional),
+		FamilyName:     pointerString(contact.NameFamily),
+	})
+
+	optional := []struct {
+		field string
+		value *string
+	}{
+		{vcard.FieldNickname, contact.Nickname},
+		{vcard.FieldOrganization, contact.Organization},
+		{vcard.FieldTitle, contact.Title},
+		{vcard.FieldRole, contact.Role},
+		{vcard.FieldGender, contact.Gender},
+		{vcard.FieldTimezone, contact.Timezone},
+		{vcard.FieldLanguage, contact.Language},
+	}
+	for _, property := range optional {
+		if value := strings.TrimSpace(pointerString(property.value)); value != "" {
+			card.SetValue(property.field, value)
+		}
+	}
+
+	if contact.Birthday != nil {
+		card.SetValue(vcard.FieldBirthday, formatVCardDate(*contact.Birthday))
+	}
+
+	if contact.Anniversary != nil {
+		card.SetValue(vcard.FieldAnniversary, formatVCardDate(*contact.Anniversary))
+	}
+
+	if contact.GeoLat != nil && contact.GeoLon != nil {
+		card.SetValue(vcard.FieldGeolocation, "geo:"+
+			strconv.FormatFloat(*contact.GeoLat, 'f', -1, 64)+","+
+			strconv.FormatFloat(*contact.GeoLon, 'f', -1, 64))
+	}
+
+	// Uploaded photos are served by an authenticated route, so their URL is
+	// meaningless outside Groundwave
+	if photoURL := strings.TrimSpace(pointerString(contact.PhotoURL)); photoURL != "" && contact.PhotoPath == nil {
+		card.Add(vcard.FieldPhoto, &vcard.Field{
+			Value:  photoURL,
+			Params: vcard.Params{vcard.ParamValue: []string{"uri"}},
+		})
+	}
+
+	for _, email := range contact.Emails {
+		emailType := "other"
+
+		switch email.EmailType {
+		case EmailPersonal:
+			emailType = "home"
+		case EmailWork:
+			emailType = "work"
+		case EmailOther:
+		}
+
+		params := vcard.Params{vcard.ParamType: []string{emailType}}
+		if email.IsPrimary {
+			params.Set(vcard.ParamPreferred, "1")
+		}
+
+		card.Add(vcard.FieldEmail, &vcard.Field{Value: email.Email, Params: params})
+	}
+
+	for _, phone := range contact.Phones {
+		params := vcard.Params{vcard.ParamType: []string{string(phone.PhoneType)}}
+		if phone.IsPrimary {
+			params.Set(vcard.ParamPreferred, "1")
+		}
+
+		card.Add(vcard.FieldTelephone, &vcard.Field{Value: phone.Phone, Params: params})
+	}
+
+	for _, addr := range contact.Addresses {
+		card.AddAddress(newVCardAddress(addr, true))
+	}
+
+	for _, contactURL := range contact.URLs {
+		card.Add(vcard.FieldURL, &vcard.Field{
+			Value:  contactURL.URL,
+			Params: vcard.Params{vcard.ParamType: []string{string(contactURL.URLType)}},
+		})
+	}
+
+	card.SetValue(VCardFieldTier, string(contact.Tier))
+
+	if callSign := pointerString(contact.CallSign); callSign != "" {
+		card.SetValue(VCardFieldCallSign, callSign)
+	}
+
+	if len(contact.Tags) > 0 {
+		names := make([]string, 0, len(contact.Tags))
+		for _, tag := range contact.Tags {
+			names = append(names, tag.Name)
+		}
+
+		card.SetValue(VCardFieldTags, strings.Join(names, ","))
+	}
+
+	if contact.IsService {
+		card.SetValue(VCardFieldService, "true")
+	}
+
+	vcard.ToV4(card)
+
+	return card
+}
+
+// formatVCardDate writes a date, using the vCard 4.0 "--MMDD" form when the
+// year is unknown
+func formatVCardDate(date time.Time) string {
+	if date.Year() == ContactDateYearUnknown {
+		return date.Format("--0102")
+	}
+
+	return date.Format("20060102")
+}
+
+// parseContactVCard reads the fields of a card that Groundwave stores
+func parseContactVCard(card vcard.Card) vCardImport {
+	imported := vCardImport{
+		CardDAVContact: parseVCard(card),
+		Role:           strings.TrimSpace(card.Value(vcard.FieldRole)),
+		Timezone:       strings.TrimSpace(card.Value(vcard.FieldTimezone)),
+		Language:       strings.TrimSpace(card.Value(vcard.FieldLanguage)),
+	}
+
+	// The raw value keeps any gender identity alongside the sex
+	imported.Gender = strings.TrimSpace(card.Value(vcard.FieldGender))
+
+	imported.GeoLat, imported.GeoLon = parseGeoURI(card.Value(vcard.FieldGeolocation))
+
+	for _, field := range card[vcard.FieldURL] {
+		value := strings.TrimSpace(field.Value)
+		if value == "" {
+			continue
+		}
+
+		urlType := URLWebsite
+
+		for _, paramType := range field.Params.Types() {
+			if candidate := URLType(strings.ToLower(paramType)); isValidURLType(candidate) {
+				urlType = candidate
+				break
+			}
+		}
+
+		imported.URLs = append(imported.URLs, vCardURL{URL: value, URLType: urlType})
+	}
+
+	switch tier := Tier(strings.ToUpper(strings.TrimSpace(card.Value(VCardFieldTier)))); tier {
+	case TierA, TierB, TierC, TierD, TierE, TierF:
+		imported.Tier = tier
+	}
+
+	if callSign := strings.ToUpper(strings.TrimSpace(card.Value(VCardFieldCallSign))); vCardCallSignPattern.MatchString(callSign) {
+		imported.CallSign = callSign
+	}
+
+	for _, name := range strings.Split(card.Value(VCardFieldTags), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if vCardTagPattern.MatchString(name) && !slices.Contains(imported.Tags, name) {
+			imported.Tags = append(imported.Tags, name)
+		}
+	}
+
+	if isService, err := strconv.ParseBool(strings.TrimSpace(card.Value(VCardFieldService))); err == nil {
+		imported.IsService = isService
+	}
+
+	return imported
+}
+
+func isValidURLType(urlType URLType) bool {
+	switch urlType {
+	case URLWebsite, URLBlog, URLTwitter, URLMastodon, URLBluesky, URLThreads,
+		URLFacebook, URLInstagram, URLLinkedIn, URLOrcid, URLGoogleScholar,
+		URLGitHub, URLGitLab, URLCodeberg, URLYouTube, URLTwitch, URLTikTok,
+		URLSignal, URLTelegram, URLWhatsApp, URLMatrix, URLQRZ, URLOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImportContactsVCard reads a vCard file and creates or updates a contact for
+// each card. Cards are matched to existing contacts by UID, then email, call
+// sign and phone number. Matched contacts keep their existing values; the card
+// only fills in missing fields and adds new emails, phones, addresses, URLs and
+// tags. The whole file is imported in one transaction.
+func ImportContactsVCard(ctx context.Context, r io.Reader) (*VCardImportResult, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	var cards []vCardImport
+
+	decoder := vcard.NewDecoder(r)
+
+	for {
+		card, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse vCard file: %w", err)
+		}
+
+		cards = append(cards, parseContactVCard(card))
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback vCard import", "error", err)
+		}
+	}()
+
+	result := &VCardImportResult{}
+
+	for _, card := range cards {
+		if card.nameDisplay() == "" {
+			result.Skipped++
+			continue
+		}
+
+		contactID, err := matchVCardContact(ctx, tx, card)
+		if err != nil {
+			return nil, err
+		}
+
+		if contactID == "" {
+			contactID, err = createVCardContact(ctx, tx, card)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Created++
+		} else {
+			if err := updateVCardContact(ctx, tx, contactID, card); err != nil {
+				return nil, err
+			}
+
+			result.Updated++
+		}
+
+		if err := addVCardContactDetails(ctx, tx, contactID, card); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit vCard import: %w", err)
+	}
+
+	return result, nil
+}
+
+// matchVCardContact returns the ID of the existing contact a card describes,
+// or an empty string when it is a new contact
+func matchVCardContact(ctx context.Context, tx pgx.Tx, card vCardImport) (string, error) {
+	lookups := make([]vCardMatchLookup, 0, 4)
+
+	if uid := strings.ToLower(strings.TrimSpace(card.UUID)); uid != "" {
+		lookups = append(lookups, vCardMatchLookup{`SELECT id FROM contacts WHERE id::text = $1 OR lower(carddav_uuid) = $1
+			ORDER BY (id::text = $1) DESC LIMIT 1`, uid})
+	}
+
+	emails := make([]string, 0, len(card.Emails))
+	for _, email := range card.Emails {
+		if normalized := normalizeCardDAVEmail(email.Email); normalized != "" {
+			emails = append(emails, normalized)
+		}
+	}
+
+	if len(emails) > 0 {
+		lookups = append(lookups, vCardMatchLookup{`SELECT contact_id FROM contact_emails WHERE lower(email) = ANY($1)
+			ORDER BY created_at LIMIT 1`, emails})
+	}
+
+	if card.CallSign != "" {
+		lookups = append(lookups, vCardMatchLookup{`SELECT id FROM contacts WHERE call_sign = $1 ORDER BY created_at LIMIT 1`, card.CallSign})
+	}
+
+	phones := make([]string, 0, len(card.Phones))
+	for _, phone := range card.Phones {
+		if digits := normalizePhoneDigits(phone.Phone); len(digits) >= vCardMinPhoneDigits {
+			phones = append(phones, digits)
+		}
+	}
+
+	if len(phones) > 0 {
+		lookups = append(lookups, vCardMatchLookup{`SELECT contact_id FROM contact_phones WHERE regexp_replace(phone, '\D', '', 'g') = ANY($1)
+			ORDER BY created_at LIMIT 1`, phones})
+	}
+
+	for _, lookup := range lookups {
+		var contactID string
+
+		err := tx.QueryRow(ctx, lookup.query, lookup.arg).Scan(&contactID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("failed to match vCard contact: %w", err)
+		}
+
+		return contactID, nil
+	}
+
+	return "", nil
+}
+
+// createVCardContact inserts a new contact for a card. A UUID UID is kept
+// as the contact ID, so importing an export elsewhere preserves contact links.
+func createVCardContact(ctx context.Context, tx pgx.Tx, card vCardImport) (string, error) {
+	var contactID *uuid.UUID
+	if parsed, err := uuid.Parse(strings.TrimSpace(card.UUID)); err == nil {
+		contactID = &parsed
+	}
+
+	nameDisplay := card.nameDisplay()
+
+	nameGiven := card.GivenName
+	if nameGiven == "" {
+		nameGiven = nameDisplay
+	}
+
+	tier := card.Tier
+	if tier == "" {
+		tier = TierC
+	}
+
+	var id string
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO contacts (
+			id, name_display, name_given, name_additional, name_family, nickname,
+			organization, title, role, birthday, anniversary, gender, timezone,
+			geo_lat, geo_lon, language, photo_url, tier, call_sign, is_service
+		) VALUES (
+			COALESCE($1, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9, $10,
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+		)
+		RETURNING id
+	`,
+		contactID,
+		nameDisplay,
+		nameGiven,
+		trimOptional(card.AdditionalName),
+		trimOptional(card.FamilyName),
+		trimOptional(card.Nickname),
+		trimOptional(card.Organization),
+		trimOptional(card.Title),
+		trimOptional(card.Role),
+		card.Birthday,
+		card.Anniversary,
+		trimOptional(card.Gender),
+		trimOptional(card.Timezone),
+		card.GeoLat,
+		card.GeoLon,
+		trimOptional(card.Language),
+		trimOptional(card.PhotoURL),
+		tier,
+		trimOptional(card.CallSign),
+		card.IsService,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert contact %q: %w", nameDisplay, err)
+	}
+
+	if card.CallSign != "" {
+		_, err = tx.Exec(ctx, `
+			UPDATE qsos SET contact_id = $1
+			WHERE UPPER(call) = $2 AND contact_id IS NULL
+		`, id, card.CallSign)
+		if err != nil {
+			return "", fmt.Errorf("failed to link QSOs to contact: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+// updateVCardContact fills in fields the contact does not have yet. Names
+// are left alone. The tier is the exception, as every contact has one and
+// the card carries an explicit choice.
+func updateVCardContact(ctx context.Context, tx pgx.Tx, contactID string, card vCardImport) error {
+	var tier *Tier
+	if card.Tier != "" {
+		tier = &card.Tier
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE contacts SET
+			nickname = COALESCE(nickname, $2),
+			organization = COALESCE(organization, $3),
+			title = COALESCE(title, $4),
+			role = COALESCE(role, $5),
+			birthday = COALESCE(birthday, $6),
+			anniversary = COALESCE(anniversary, $7),
+			gender = COALESCE(gender, $8),
+			timezone = COALESCE(timezone, $9),
+			geo_lat = CASE WHEN geo_lat IS NULL AND geo_lon IS NULL THEN $10 ELSE geo_lat END,
+			geo_lon = CASE WHEN geo_lat IS NULL AND geo_lon IS NULL THEN $11 ELSE geo_lon END,
+			language = COALESCE(language, $12),
+			photo_url = COALESCE(photo_url, $13),
+			tier = COALESCE($14, tier),
+			call_sign = COALESCE(call_sign, $15),
+			updated_at = now()
+		WHERE id = $1
+	`,
+		contactID,
+		trimOptional(card.Nickname),
+		trimOptional(card.Organization),
+		trimOptional(card.Title),
+		trimOptional(card.Role),
+		card.Birthday,
+		card.Anniversary,
+		trimOptional(card.Gender),
+		trimOptional(card.Timezone),
+		card.GeoLat,
+		card.GeoLon,
+		trimOptional(card.Language),
+		trimOptional(card.PhotoURL),
+		tier,
+		trimOptional(card.CallSign),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update contact from vCard: %w", err)
+	}
+
+	return nil
+}
+
+// addVCardContactDetails adds the emails, phones, addresses, URLs and tags
+// of a card that the contact does not already have
+func addVCardContactDetails(ctx context.Context, tx pgx.Tx, contactID string, card vCardImport) error {
+	preferredEmail := normalizeCardDAVEmail(preferredEmailValue(card.Emails))
+
+	for i, email := range card.Emails {
+		value := normalizeCardDAVEmail(email.Email)
+		if !vCardEmailPattern.MatchString(value) {
+			continue
+		}
+
+		emailType := EmailOther
+
+		switch email.Type {
+		case "home":
+			emailType = EmailPersonal
+		case "work":
+			emailType = EmailWork
+		}
+
+		isPreferred := value == preferredEmail || (preferredEmail == "" && i == 0)
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contact_emails (contact_id, email, email_type, is_primary)
+			VALUES ($1, $2, $3, $4 AND NOT EXISTS (
+				SELECT 1 FROM contact_emails WHERE contact_id = $1 AND is_primary
+			))
+			ON CONFLICT (contact_id, lower(email)) DO NOTHING
+		`, contactID, value, emailType, isPreferred)
+		if err != nil {
+			return fmt.Errorf("failed to add vCard email: %w", err)
+		}
+	}
+
+	preferredPhoneDigits := normalizePhoneDigits(preferredPhoneValue(card.Phones))
+
+	for i, phone := range card.Phones {
+		value := normalizeCardDAVPhone(phone.Phone)
+
+		digits := normalizePhoneDigits(value)
+		if digits == "" {
+			continue
+		}
+
+		isPreferred := digits == preferredPhoneDigits || (preferredPhoneDigits == "" && i == 0)
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contact_phones (contact_id, phone, phone_type, is_primary)
+			SELECT $1::uuid, $2, $3::phone_type, $4::boolean AND NOT EXISTS (
+				SELECT 1 FROM contact_phones WHERE contact_id = $1 AND is_primary
+			)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM contact_phones
+				WHERE contact_id = $1 AND regexp_replace(phone, '\D', '', 'g') = $5
+			)
+		`, contactID, value, PhoneType(phone.Type), isPreferred, digits)
+		if err != nil {
+			return fmt.Errorf("failed to add vCard phone: %w", err)
+		}
+	}
+
+	if err := addVCardAddresses(ctx, tx, contactID, card.Addresses); err != nil {
+		return err
+	}
+
+	for _, contactURL := range card.URLs {
+		value := contactURL.URL
+		if contactURL.URLType == URLLinkedIn {
+			if normalized, ok := NormalizeLinkedInURL(value); ok {
+				value = normalized
+			}
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contact_urls (contact_id, url, url_type)
+			SELECT $1::uuid, $2, $3::url_type
+			WHERE NOT EXISTS (SELECT 1 FROM contact_urls WHERE contact_id = $1 AND url = $2)
+		`, contactID, value, contactURL.URLType)
+		if err != nil {
+			return fmt.Errorf("failed to add vCard URL: %w", err)
+		}
+	}
+
+	for _, tagName := range card.Tags {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contact_tags (contact_id, tag_id)
+			VALUES ($1, get_or_create_tag($2))
+			ON CONFLICT (contact_id, tag_id) DO NOTHING
+		`, contactID, tagName)
+		if err != nil {
+			return fmt.Errorf("failed to add vCard tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addVCardAddresses adds card addresses the contact does not have yet
+func addVCardAddresses(ctx context.Context, tx pgx.Tx, contactID string, addresses []CardDAVAddress) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT street, locality, region, postal_code, country, po_box, extended, is_primary
+		FROM contact_addresses WHERE contact_id = $1
+	`, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to query contact addresses: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	hasPrimary := false
+
+	for rows.Next() {
+		var (
+			addr      ContactAddress
+			isPrimary bool
+		)
+
+		if err := rows.Scan(&addr.Street, &addr.Locality, &addr.Region, &addr.PostalCode,
+			&addr.Country, &addr.POBox, &addr.Extended, &isPrimary); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan contact address: %w", err)
+		}
+
+		existing[contactAddressKey(addr)] = true
+		hasPrimary = hasPrimary || isPrimary
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating contact addresses: %w", err)
+	}
+
+	for _, addr := range addresses {
+		key := addr.key()
+		if existing[key] || strings.Trim(key, "\x1f") == "" {
+			continue
+		}
+
+		existing[key] = true
+
+		isPrimary := !hasPrimary && (addr.Preferred || len(addresses) == 1)
+		hasPrimary = hasPrimary || isPrimary
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contact_addresses (
+				contact_id, street, extended, po_box, locality, region, postal_code, country,
+				address_type, is_primary
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+			contactID,
+			trimOptional(addr.Street),
+			trimOptional(addr.Extended),
+			trimOptional(addr.POBox),
+			trimOptional(addr.Locality),
+			trimOptional(addr.Region),
+			trimOptional(addr.PostalCode),
+			trimOptional(addr.Country),
+			AddressType(addr.Type),
+			isPrimary,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add vCard address: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestContactVCardExportImportRoundTrip(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{
+		NameGiven:    "Ali",
+		NameFamily:   stringPtr("Hassan"),
+		Organization: stringPtr("Acme"),
+		Email:        stringPtr("ali@example.com"),
+		Phone:        stringPtr("+971501234567"),
+		CallSign:     stringPtr("A65AA"),
+		Tier:         TierA,
+	})
+	mustCreateContact(t, CreateContactInput{NameGiven: "Service", IsService: true, Tier: TierC})
+
+	if err := AddTagToContact(ctx, contactID, "friends"); err != nil {
+		t.Fatalf("AddTagToContact failed: %v", err)
+	}
+
+	if err := AddAddress(ctx, AddAddressInput{ContactID: contactID, AddressFields: AddressFields{
+		Street:   stringPtr("1 Main Street"),
+		Locality: stringPtr("Dubai"),
+	}}); err != nil {
+		t.Fatalf("AddAddress failed: %v", err)
+	}
+
+	if err := AddURL(ctx, AddURLInput{ContactID: contactID, URL: "https://github.com/ali", URLType: URLGitHub}); err != nil {
+		t.Fatalf("AddURL failed: %v", err)
+	}
+
+	exported, err := ExportContactsVCard(ctx, VCardExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportContactsVCard failed: %v", err)
+	}
+
+	if count := strings.Count(string(exported), "BEGIN:VCARD"); count != 2 {
+		t.Fatalf("expected 2 cards including the service contact, got %d", count)
+	}
+
+	filtered, err := ExportContactsVCard(ctx, VCardExportOptions{SearchQuery: "tag:friends"})
+	if err != nil {
+		t.Fatalf("ExportContactsVCard with query failed: %v", err)
+	}
+
+	if count := strings.Count(string(filtered), "BEGIN:VCARD"); count != 1 {
+		t.Fatalf("expected 1 card for tag:friends, got %d", count)
+	}
+
+	// Importing into the same database matches by UID and changes nothing
+	result, err := ImportContactsVCard(ctx, bytes.NewReader(exported))
+	if err != nil {
+		t.Fatalf("ImportContactsVCard failed: %v", err)
+	}
+
+	if result.Created != 0 || result.Updated != 2 {
+		t.Fatalf("expected both cards to match, got %+v", result)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(contact.Emails) != 1 || len(contact.Phones) != 1 || len(contact.Addresses) != 1 || len(contact.URLs) != 1 {
+		t.Fatalf("expected no duplicated details, got %d emails %d phones %d addresses %d urls",
+			len(contact.Emails), len(contact.Phones), len(contact.Addresses), len(contact.URLs))
+	}
+
+	// Importing into an empty database recreates the contacts losslessly
+	resetDatabase(t)
+
+	result, err = ImportContactsVCard(ctx, bytes.NewReader(exported))
+	if err != nil {
+		t.Fatalf("ImportContactsVCard into empty database failed: %v", err)
+	}
+
+	if result.Created != 2 || result.Updated != 0 {
+		t.Fatalf("expected both cards to be created, got %+v", result)
+	}
+
+	contact, err = GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("expected contact to keep its ID: %v", err)
+	}
+
+	if contact.NameDisplay != "Ali Hassan" || contact.Tier != TierA || contact.CallSign == nil || *contact.CallSign != "A65AA" {
+		t.Fatalf("unexpected imported contact: %+v", contact.Contact)
+	}
+
+	if len(contact.Tags) != 1 || contact.Tags[0].Name != "friends" {
+		t.Fatalf("unexpected imported tags: %+v", contact.Tags)
+	}
+
+	if len(contact.Emails) != 1 || !contact.Emails[0].IsPrimary || contact.Emails[0].EmailType != EmailPersonal {
+		t.Fatalf("unexpected imported emails: %+v", contact.Emails)
+	}
+
+	if len(contact.Phones) != 1 || !contact.Phones[0].IsPrimary || contact.Phones[0].PhoneType != PhoneCell {
+		t.Fatalf("unexpected imported phones: %+v", contact.Phones)
+	}
+
+	if len(contact.URLs) != 1 || contact.URLs[0].URLType != URLGitHub {
+		t.Fatalf("unexpected imported URLs: %+v", contact.URLs)
+	}
+
+	services, err := ListServiceContacts(ctx)
+	if err != nil {
+		t.Fatalf("ListServiceContacts failed: %v", err)
+	}
+
+	if len(services) != 1 || services[0].NameDisplay != "Service" {
+		t.Fatalf("expected service contact to be restored, got %+v", services)
+	}
+}
+
+func TestImportContactsVCardMatchesByEmailAndPhone(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	emailContactID := mustCreateContact(t, CreateContactInput{
+		NameGiven: "Sara",
+		Email:     stringPtr("sara@example.com"),
+		Tier:      TierB,
+	})
+	phoneContactID := mustCreateContact(t, CreateContactInput{
+		NameGiven: "Omar",
+		Phone:     stringPtr("+971 50 765 4321"),
+		Tier:      TierC,
+	})
+
+	file := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"FN:Sara Q",
+		"N:Q;Sara;;;",
+		"ORG:Example Co",
+		"EMAIL;TYPE=work:SARA@example.com",
+		"EMAIL;TYPE=home:sara.q@example.com",
+		"X-GROUNDWAVE-TAGS:colleagues",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"FN:Omar",
+		"TEL;TYPE=cell:+971507654321",
+		"X-GROUNDWAVE-TIER:A",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:;;;;",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"FN:New Person",
+		"TEL:555",
+		"END:VCARD",
+		"",
+	}, "\r\n")
+
+	result, err := ImportContactsVCard(ctx, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ImportContactsVCard failed: %v", err)
+	}
+
+	if result.Created != 1 || result.Updated != 2 || result.Skipped != 1 {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	sara, err := GetContact(ctx, emailContactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if sara.NameDisplay != "Sara" || sara.Organization == nil || *sara.Organization != "Example Co" {
+		t.Fatalf("expected existing name kept and organisation filled, got %+v", sara.Contact)
+	}
+
+	if len(sara.Emails) != 2 || len(sara.Tags) != 1 || sara.Tags[0].Name != "colleagues" {
+		t.Fatalf("expected new email and tag added, got %+v %+v", sara.Emails, sara.Tags)
+	}
+
+	omar, err := GetContact(ctx, phoneContactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if omar.Tier != TierA || len(omar.Phones) != 1 {
+		t.Fatalf("expected tier update without a duplicate phone, got tier %s and %d phones", omar.Tier, len(omar.Phones))
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-vcard"
+	"github.com/google/uuid"
+)
+
+func TestContactVCardRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	birthday := time.Date(ContactDateYearUnknown, time.March, 15, 0, 0, 0, 0, time.UTC)
+	anniversary := time.Date(2015, time.June, 1, 0, 0, 0, 0, time.UTC)
+	lat, lon := 25.2048, 55.2708
+
+	contact := &ContactDetail{
+		Contact: Contact{
+			ID:           uuid.New(),
+			NameGiven:    stringPtr("Ali"),
+			NameFamily:   stringPtr("Hassan"),
+			NameDisplay:  "Ali Hassan",
+			Nickname:     stringPtr("Al"),
+			Organization: stringPtr("Acme, Inc"),
+			Role:         stringPtr("Operator"),
+			Birthday:     &birthday,
+			Anniversary:  &anniversary,
+			Timezone:     stringPtr("Asia/Dubai"),
+			GeoLat:       &lat,
+			GeoLon:       &lon,
+			Tier:         TierA,
+			CallSign:     stringPtr("A65AA"),
+			IsService:    true,
+		},
+		Emails: []ContactEmail{
+			{Email: "ali@example.com", EmailType: EmailPersonal},
+			{Email: "ali@work.example", EmailType: EmailWork, IsPrimary: true},
+		},
+		Phones: []ContactPhone{{Phone: "+971 50 123 4567", PhoneType: PhonePager, IsPrimary: true}},
+		Addresses: []ContactAddress{{
+			Street:      stringPtr("1 Main Street"),
+			Locality:    stringPtr("Dubai"),
+			AddressType: AddressWork,
+			IsPrimary:   true,
+		}},
+		URLs: []ContactURL{{URL: "https://github.com/ali", URLType: URLGitHub}},
+		Tags: []Tag{{Name: "friends"}, {Name: "ham:club"}},
+	}
+
+	var buffer bytes.Buffer
+	if err := vcard.NewEncoder(&buffer).Encode(contactVCard(contact)); err != nil {
+		t.Fatalf("failed to encode vcard: %v", err)
+	}
+
+	card, err := vcard.NewDecoder(&buffer).Decode()
+	if err != nil {
+		t.Fatalf("failed to decode vcard: %v", err)
+	}
+
+	if card.Value(vcard.FieldVersion) != "4.0" {
+		t.Fatalf("expected vCard 4.0, got %q", card.Value(vcard.FieldVersion))
+	}
+
+	if card.Value(vcard.FieldBirthday) != "--0315" {
+		t.Fatalf("expected year-less birthday, got %q", card.Value(vcard.FieldBirthday))
+	}
+
+	imported := parseContactVCard(card)
+
+	if imported.UUID != contact.ID.String() || imported.nameDisplay() != "Ali Hassan" || imported.FamilyName != "Hassan" {
+		t.Fatalf("unexpected identity: %+v", imported.CardDAVContact)
+	}
+
+	if imported.Organization != "Acme, Inc" || imported.Role != "Operator" || imported.Timezone != "Asia/Dubai" {
+		t.Fatalf("unexpected organisation fields: %+v", imported)
+	}
+
+	if imported.Birthday == nil || !imported.Birthday.Equal(birthday) || imported.Anniversary == nil || !imported.Anniversary.Equal(anniversary) {
+		t.Fatalf("unexpected dates: %v %v", imported.Birthday, imported.Anniversary)
+	}
+
+	if imported.GeoLat == nil || *imported.GeoLat != lat || imported.GeoLon == nil || *imported.GeoLon != lon {
+		t.Fatalf("unexpected coordinates: %v %v", imported.GeoLat, imported.GeoLon)
+	}
+
+	if imported.Tier != TierA || imported.CallSign != "A65AA" || !imported.IsService {
+		t.Fatalf("unexpected extensions: %+v", imported)
+	}
+
+	if !slices.Equal(imported.Tags, []string{"friends", "ham:club"}) {
+		t.Fatalf("unexpected tags: %v", imported.Tags)
+	}
+
+	if len(imported.Emails) != 2 || imported.Emails[0].Type != "home" || !imported.Emails[1].Preferred {
+		t.Fatalf("unexpected emails: %+v", imported.Emails)
+	}
+
+	if len(imported.Phones) != 1 || imported.Phones[0].Type != "pager" || !imported.Phones[0].Preferred {
+		t.Fatalf("unexpected phones: %+v", imported.Phones)
+	}
+
+	if len(imported.Addresses) != 1 || imported.Addresses[0].Street != "1 Main Street" || !imported.Addresses[0].Preferred {
+		t.Fatalf("unexpected addresses: %+v", imported.Addresses)
+	}
+
+	if len(imported.URLs) != 1 || imported.URLs[0].URLType != URLGitHub {
+		t.Fatalf("unexpected URLs: %+v", imported.URLs)
+	}
+}
+
+func TestParseContactVCardIgnoresInvalidExtensions(t *testing.T) {
+	t.Parallel()
+
+	card := make(vcard.Card)
+	card.SetValue(vcard.FieldFormattedName, "Sam")
+	card.SetValue(VCardFieldTier, "Z")
+	card.SetValue(VCardFieldCallSign, "not a call")
+	card.SetValue(VCardFieldTags, "Work, has space,work,,")
+	card.Add(vcard.FieldURL, &vcard.Field{Value: "https://example.com", Params: vcard.Params{vcard.ParamType: []string{"homepage"}}})
+
+	imported := parseContactVCard(card)
+
+	if imported.Tier != "" || imported.CallSign != "" || imported.IsService {
+		t.Fatalf("expected invalid extensions to be dropped, got %+v", imported)
+	}
+
+	if !slices.Equal(imported.Tags, []string{"work"}) {
+		t.Fatalf("unexpected tags: %v", imported.Tags)
+	}
+
+	if len(imported.URLs) != 1 || imported.URLs[0].URLType != URLWebsite {
+		t.Fatalf("expected unknown URL type to fall back to website, got %+v", imported.URLs)
+	}
+}
+
+func TestVCardImportNameDisplayFallback(t *testing.T) {
+	t.Parallel()
+
+	imported := vCardImport{CardDAVContact: CardDAVContact{GivenName: " Sam ", FamilyName: "Lee"}}
+	if got := imported.nameDisplay(); got != "Sam Lee" {
+		t.Fatalf("expected name from parts, got %q", got)
+	}
+
+	if got := (vCardImport{}).nameDisplay(); got != "" {
+		t.Fatalf("expected empty name, got %q", got)
+	}
+}
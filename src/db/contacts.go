@@ -150,6 +150,89 @@ func ListContacts(ctx context.Context) ([]ContactListItem, error) {
 	return contacts, nil
 }
 
+// contactLastContactSQL is when a contact was last logged or auto-contacted
+const contactLastContactSQL = `GREATEST(
+	(SELECT MAX(logged_at) FROM contact_logs WHERE contact_id = c.id),
+	c.last_auto_contact
+)`
+
+// contactOverdueSQL matches contacts past their follow-up interval, the same
+// way the overdue list does. It needs effectiveCadenceCTE in the query.
+const contactOverdueSQL = `(
+	(c.cadence_snoozed_until IS NULL OR c.cadence_snoozed_until <= CURRENT_DATE)
+	AND EXISTS (
+		SELECT 1 FROM contact_intervals ci
+		WHERE ci.id = c.id
+			AND ci.interval_days IS NOT NULL
+			AND CAST(EXTRACT(EPOCH FROM (NOW() - COALESCE(` + contactLastContactSQL + `, c.created_at))) / 86400 AS INTEGER) > ci.interval_days
+	)
+)`
+
+// contactSearchTermClause compiles a search term for the contact list
+func contactSearchTermClause(term searchTerm, argNum int) (string, []any, error) {
+	switch term.key {
+	case "":
+		return fmt.Sprintf(`(
+			c.name_display ILIKE $%d OR
+			COALESCE(c.organization, '') ILIKE $%d OR
+			COALESCE(c.title, '') ILIKE $%d OR
+			COALESCE(c.call_sign, '') ILIKE $%d OR
+			EXISTS (
+				SELECT 1
+				FROM contact_tags ct
+				INNER JOIN tags t ON t.id = ct.tag_id
+				WHERE ct.contact_id = c.id AND t.name ILIKE $%d
+			)
+		)`, argNum, argNum, argNum, argNum, argNum), []any{"%" + term.value + "%"}, nil
+	case "tag":
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1
+			FROM contact_tags ct
+			INNER JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.contact_id = c.id AND lower(t.name) = $%d
+		)`, argNum), []any{term.value}, nil
+	case "tier":
+		return fmt.Sprintf("c.tier::text = $%d", argNum), []any{term.value}, nil
+	case "callsign":
+		if term.wildcard {
+			return fmt.Sprintf("UPPER(c.call_sign) LIKE UPPER($%d)", argNum), []any{term.value}, nil
+		}
+
+		return fmt.Sprintf("UPPER(c.call_sign) = UPPER($%d)", argNum), []any{term.value}, nil
+	case "has":
+		switch term.value {
+		case "carddav":
+			return "c.carddav_uuid IS NOT NULL", nil, nil
+		case "email":
+			return "EXISTS (SELECT 1 FROM contact_emails WHERE contact_id = c.id)", nil, nil
+		case "phone":
+			return "EXISTS (SELECT 1 FROM contact_phones WHERE contact_id = c.id)", nil, nil
+		case "linkedin":
+			return "EXISTS (SELECT 1 FROM contact_urls WHERE contact_id = c.id AND url_type = 'linkedin')", nil, nil
+		}
+	case "org":
+		return fmt.Sprintf("COALESCE(c.organization, '') ILIKE $%d", argNum), []any{"%" + term.value + "%"}, nil
+	case "tz":
+		return fmt.Sprintf("COALESCE(c.timezone, '') ILIKE $%d", argNum), []any{"%" + term.value + "%"}, nil
+	case "overdue":
+		if term.flag {
+			return contactOverdueSQL, nil, nil
+		}
+
+		return "NOT " + contactOverdueSQL, nil, nil
+	case "contacted":
+		clause, args := searchDateRangeClause(contactLastContactSQL, term.dates, argNum)
+		return clause, args, nil
+	case "created":
+		clause, args := searchDateRangeClause("c.created_at", term.dates, argNum)
+		return clause, args, nil
+	case "birthday":
+		return fmt.Sprintf("EXTRACT(MONTH FROM c.birthday) = $%d", argNum), []any{int(term.month)}, nil
+	}
+
+	return "", nil, nil
+}
+
 // ListContactsWithFilters returns contacts matching the specified filter options
 func ListContactsWithFilters(ctx context.Context, opts ContactListOptions) ([]ContactListItem, error) {
 	if pool == nil {
@@ -200,91 +283,13 @@ func ListContactsWithFilters(ctx context.Context, opts ContactListOptions) ([]Co
 
 	parsed := parseSearchQuery(opts.SearchQuery)
 
-	if len(parsed.tiers) > 0 {
-		tierValues := make([]string, 0, len(parsed.tiers))
-		for _, tier := range parsed.tiers {
-			tierValues = append(tierValues, string(tier))
-		}
-
-		whereClauses = append(whereClauses, fmt.Sprintf("c.tier::text = ANY($%d::text[])", argNum))
-		args = append(args, tierValues)
-		argNum++
-	}
-
-	if len(parsed.tagNames) > 0 {
-		whereClauses = append(whereClauses, fmt.Sprintf(
-			`c.id IN (
-				SELECT ct.contact_id
-				FROM contact_tags ct
-				INNER JOIN tags t ON t.id = ct.tag_id
-				WHERE lower(t.name) = ANY($%d::text[])
-				GROUP BY ct.contact_id
-				HAVING COUNT(DISTINCT lower(t.name)) = $%d
-			)`, argNum, argNum+1))
-		args = append(args, parsed.tagNames, len(parsed.tagNames))
-		argNum += 2
-	}
-
-	for _, callSign := range parsed.callSignExact {
-		whereClauses = append(whereClauses, fmt.Sprintf("UPPER(c.call_sign) = UPPER($%d)", argNum))
-		args = append(args, callSign)
-		argNum++
-	}
-
-	for _, callSignPattern := range parsed.callSignLike {
-		whereClauses = append(whereClauses, fmt.Sprintf("UPPER(c.call_sign) LIKE UPPER($%d)", argNum))
-		args = append(args, callSignPattern)
-		argNum++
-	}
-
-	for _, hasTarget := range parsed.hasTargets {
-		switch hasTarget {
-		case "carddav":
-			whereClauses = append(whereClauses, "c.carddav_uuid IS NOT NULL")
-		case "email":
-			whereClauses = append(whereClauses,
-				"EXISTS (SELECT 1 FROM contact_emails WHERE contact_id = c.id)")
-		case "phone":
-			whereClauses = append(whereClauses,
-				"EXISTS (SELECT 1 FROM contact_phones WHERE contact_id = c.id)")
-		case "linkedin":
-			whereClauses = append(whereClauses,
-				"EXISTS (SELECT 1 FROM contact_urls WHERE contact_id = c.id AND url_type = 'linkedin')")
-		}
+	searchClauses, searchArgs, _, err := parsed.whereClauses(argNum, contactSearchTermClause)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, missingTarget := range parsed.missing {
-		switch missingTarget {
-		case "carddav":
-			whereClauses = append(whereClauses, "c.carddav_uuid IS NULL")
-		case "email":
-			whereClauses = append(whereClauses,
-				"NOT EXISTS (SELECT 1 FROM contact_emails WHERE contact_id = c.id)")
-		case "phone":
-			whereClauses = append(whereClauses,
-				"NOT EXISTS (SELECT 1 FROM contact_phones WHERE contact_id = c.id)")
-		case "linkedin":
-			whereClauses = append(whereClauses,
-				"NOT EXISTS (SELECT 1 FROM contact_urls WHERE contact_id = c.id AND url_type = 'linkedin')")
-		}
-	}
-
-	for _, term := range parsed.freeTerms {
-		whereClauses = append(whereClauses, fmt.Sprintf(`(
-			c.name_display ILIKE $%d OR
-			COALESCE(c.organization, '') ILIKE $%d OR
-			COALESCE(c.title, '') ILIKE $%d OR
-			COALESCE(c.call_sign, '') ILIKE $%d OR
-			EXISTS (
-				SELECT 1
-				FROM contact_tags ct
-				INNER JOIN tags t ON t.id = ct.tag_id
-				WHERE ct.contact_id = c.id AND t.name ILIKE $%d
-			)
-		)`, argNum, argNum, argNum, argNum, argNum))
-		args = append(args, "%"+term+"%")
-		argNum++
-	}
+	whereClauses = append(whereClauses, searchClauses...)
+	args = append(args, searchArgs...)
 
 	// Build ORDER BY based on service status and alphabetic sort option
 	orderBy := "c.tier ASC, c.name_display ASC"
@@ -294,8 +299,9 @@ func ListContactsWithFilters(ctx context.Context, opts ContactListOptions) ([]Co
 		orderBy = "COALESCE(c.organization, c.name_display) ASC, c.name_display ASC"
 	}
 
-	// Build final query
+	// Build final query; the cadence CTE backs the overdue: operator
 	query := fmt.Sprintf(`
+		WITH `+effectiveCadenceCTE+`
 		SELECT
 			c.id,
 			c.name_display,
@@ -381,16 +387,17 @@ func ListContactsWithFilters(ctx context.Context, opts ContactListOptions) ([]Co
 
 // CreateContactInput represents the input for creating a new contact
 type CreateContactInput struct {
-	NameGiven    string
-	NameFamily   *string
-	Organization *string
-	Title        *string
-	Email        *string
-	Phone        *string
-	CallSign     *string
-	CardDAVUUID  *string
-	IsService    bool
-	Tier         Tier
+	NameGiven       string
+	NameFamily      *string
+	Organization    *string
+	Title           *string
+	Email           *string
+	Phone           *string
+	CallSign        *string
+	CardDAVUUID     *string
+	CardDAVSourceID *string
+	IsService       bool
+	Tier            Tier
 }
 
 // CreateContact creates a new contact and optionally adds email and phone
@@ -423,8 +430,9 @@ func CreateContact(ctx context.Context, input CreateContactInput) (string, error
 	query := `
 		INSERT INTO contacts (
 			name_display, name_given, name_family,
-			organization, title, call_sign, is_service, carddav_uuid, tier
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			organization, title, call_sign, is_service, carddav_uuid, tier,
+			carddav_source_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::uuid)
 		RETURNING id
 	`
 
@@ -438,6 +446,7 @@ func CreateContact(ctx context.Context, input CreateContactInput) (string, error
 		input.IsService,
 		input.CardDAVUUID,
 		input.Tier,
+		input.CardDAVSourceID,
 	).Scan(&contactID)
 	if err != nil {
 		return "", fmt.Errorf("failed to insert contact: %w", err)
@@ -507,10 +516,18 @@ type ContactDetail struct {
 	Notes          []ContactNote
 	Tags           []Tag
 	CardDAVContact *CardDAVContact // CardDAV contact data if linked
+	// CardDAVSourceName names the source of the linked card
+	CardDAVSourceName string
 }
 
 // GetContact retrieves a contact by ID with all related data
 func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
+	return getContactDetail(ctx, id, true)
+}
+
+// getContactDetail loads a contact with its related data. Bulk callers skip
+// the CardDAV fetch, as the synced emails and phones are already cached.
+func getContactDetail(ctx context.Context, id string, fetchCardDAV bool) (*ContactDetail, error) {
 	if pool == nil {
 		return nil, ErrDatabaseConnectionNotInitialized
 	}
@@ -522,13 +539,16 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 		SELECT
 			id, name_given, name_additional, name_family,
 			name_display, nickname, organization, title, role, birthday, anniversary,
-			gender, timezone, geo_lat, geo_lon, language, photo_url,
-			tier, call_sign, is_service, is_me, carddav_uuid, created_at, updated_at,
-			last_auto_contact
+			gender, timezone, geo_lat, geo_lon, language, photo_url, photo_path,
+			tier, call_sign, is_service, is_me, carddav_uuid, carddav_source_id::text,
+			created_at, updated_at, last_auto_contact,
+			COALESCE((SELECT s.name FROM carddav_sources s WHERE s.id = contacts.carddav_source_id), '')
 		FROM contacts
 		WHERE id = $1
 	`
 
+	var cardDAVSourceName string
+
 	err := pool.QueryRow(ctx, query, id).Scan(
 		&contact.ID,
 		&contact.NameGiven,
@@ -547,21 +567,25 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 		&contact.GeoLon,
 		&contact.Language,
 		&contact.PhotoURL,
+		&contact.PhotoPath,
 		&contact.Tier,
 		&contact.CallSign,
 		&contact.IsService,
 		&contact.IsMe,
 		&contact.CardDAVUUID,
+		&contact.CardDAVSourceID,
 		&contact.CreatedAt,
 		&contact.UpdatedAt,
 		&contact.LastAutoContact,
+		&cardDAVSourceName,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query contact: %w", err)
 	}
 
 	detail := &ContactDetail{
-		Contact: contact,
+		Contact:           contact,
+		CardDAVSourceName: cardDAVSourceName,
 	}
 
 	// Get emails
@@ -608,7 +632,7 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 
 	// Get addresses
 	addrQuery := `SELECT id, contact_id, street, locality, region, postal_code, country,
-		address_type, is_primary, po_box, extended, created_at
+		address_type, is_primary, po_box, extended, source, latitude, longitude, created_at
 		FROM contact_addresses WHERE contact_id = $1 ORDER BY is_primary DESC, created_at`
 
 	addrRows, err := pool.Query(ctx, addrQuery, id)
@@ -622,7 +646,7 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 
 		err := addrRows.Scan(&addr.ID, &addr.ContactID, &addr.Street, &addr.Locality, &addr.Region,
 			&addr.PostalCode, &addr.Country, &addr.AddressType, &addr.IsPrimary, &addr.POBox,
-			&addr.Extended, &addr.CreatedAt)
+			&addr.Extended, &addr.Source, &addr.Latitude, &addr.Longitude, &addr.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan address: %w", err)
 		}
@@ -652,8 +676,11 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 	}
 
 	// Get contact logs
-	logQuery := `SELECT id, contact_id, log_type, logged_at, subject, content, created_at
-		FROM contact_logs WHERE contact_id = $1 ORDER BY logged_at DESC, created_at DESC`
+	logQuery := `SELECT l.id, l.contact_id, l.log_type, l.logged_at, l.subject, l.content, l.created_at,
+			l.introduced_by_contact_id, ic.name_display
+		FROM contact_logs l
+		LEFT JOIN contacts ic ON ic.id = l.introduced_by_contact_id
+		WHERE l.contact_id = $1 ORDER BY l.logged_at DESC, l.created_at DESC`
 
 	logRows, err := pool.Query(ctx, logQuery, id)
 	if err != nil {
@@ -664,7 +691,8 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 	for logRows.Next() {
 		var log ContactLog
 
-		err := logRows.Scan(&log.ID, &log.ContactID, &log.LogType, &log.LoggedAt, &log.Subject, &log.Content, &log.CreatedAt)
+		err := logRows.Scan(&log.ID, &log.ContactID, &log.LogType, &log.LoggedAt, &log.Subject, &log.Content, &log.CreatedAt,
+			&log.IntroducedByID, &log.IntroducedByName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan log: %w", err)
 		}
@@ -700,8 +728,8 @@ func GetContact(ctx context.Context, id string) (*ContactDetail, error) {
 	}
 
 	// Fetch CardDAV contact data if linked
-	if contact.CardDAVUUID != nil && *contact.CardDAVUUID != "" {
-		cardDAVContact, err := GetCardDAVContact(ctx, *contact.CardDAVUUID)
+	if fetchCardDAV && contact.CardDAVUUID != nil && *contact.CardDAVUUID != "" && contact.CardDAVSourceID != nil {
+		cardDAVContact, err := GetCardDAVContact(ctx, *contact.CardDAVSourceID, *contact.CardDAVUUID)
 		if err != nil {
 			// Log error but don't fail the request if CardDAV is unavailable
 			// Just leave CardDAVContact as nil
@@ -1161,11 +1189,12 @@ func DeleteContact(ctx context.Context, contactID string) error {
 
 // AddLogInput represents input for adding a contact log
 type AddLogInput struct {
-	ContactID string
-	LogType   LogType
-	LoggedAt  *string // Optional, defaults to now
-	Subject   *string
-	Content   *string
+	ContactID      string
+	LogType        LogType
+	LoggedAt       *string // Optional, defaults to now
+	Subject        *string
+	Content        *string
+	IntroducedByID *string // Optional, only used for intro logs
 }
 
 // AddLog adds a new interaction log to a contact
@@ -1174,16 +1203,50 @@ func AddLog(ctx context.Context, input AddLogInput) error {
 		return ErrDatabaseConnectionNotInitialized
 	}
 
+	if input.LogType != LogIntro {
+		input.IntroducedByID = nil
+	}
+
+	if input.IntroducedByID != nil && *input.IntroducedByID == input.ContactID {
+		return ErrRelationshipSelf
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback log insert", "error", err)
+		}
+	}()
+
 	query := `
-		INSERT INTO contact_logs (contact_id, log_type, logged_at, subject, content)
-		VALUES ($1, $2, COALESCE($3::timestamptz, now()), $4, $5)
+		INSERT INTO contact_logs (contact_id, log_type, logged_at, subject, content, introduced_by_contact_id)
+		VALUES ($1, $2, COALESCE($3::timestamptz, now()), $4, $5, $6)
+		RETURNING id
 	`
 
-	_, err := pool.Exec(ctx, query, input.ContactID, input.LogType, input.LoggedAt, input.Subject, input.Content)
+	var logID string
+
+	err = tx.QueryRow(ctx, query, input.ContactID, input.LogType, input.LoggedAt, input.Subject, input.Content,
+		input.IntroducedByID).Scan(&logID)
 	if err != nil {
 		return fmt.Errorf("failed to add log: %w", err)
 	}
 
+	// Intro logs also record the introducer in the relationship graph
+	if input.IntroducedByID != nil {
+		if err := recordIntroduction(ctx, tx, input.ContactID, *input.IntroducedByID, logID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit log insert: %w", err)
+	}
+
 	return nil
 }
 
@@ -1348,6 +1411,10 @@ type AddChatInput struct {
 	Sender    ChatSender
 	Message   string
 	SentAt    *string // Optional, defaults to now
+	MessageID string  // Optional platform message ID, used to skip duplicates
+	// ReplyToMessageID is the platform ID of the message replied to
+	ReplyToMessageID string
+	Media            *ChatMedia // Optional attachment, the message is its caption
 }
 
 // AddChat adds a new chat entry to a contact
@@ -1356,7 +1423,7 @@ func AddChat(ctx context.Context, input AddChatInput) error {
 		return ErrDatabaseConnectionNotInitialized
 	}
 
-	if strings.TrimSpace(input.Message) == "" {
+	if strings.TrimSpace(input.Message) == "" && input.Media == nil {
 		return ErrChatMessageRequired
 	}
 
@@ -1379,12 +1446,25 @@ func AddChat(ctx context.Context, input AddChatInput) error {
 		sender = ChatSenderThem
 	}
 
+	media := input.Media
+	if media == nil {
+		media = &ChatMedia{}
+	}
+
 	query := `
-		INSERT INTO contact_chats (contact_id, platform, sender, message, sent_at)
-		VALUES ($1, $2, $3, $4, COALESCE($5::timestamptz, now()))
+		INSERT INTO contact_chats (
+			contact_id, platform, sender, message, sent_at, message_id, reply_to_message_id,
+			media_type, media_mime_type, media_file_name, media_size, media_path
+		)
+		VALUES (
+			$1, $2, $3, $4, COALESCE($5::timestamptz, now()), NULLIF($6, ''), NULLIF($7, ''),
+			NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11::bigint, 0), NULLIF($12, '')
+		)
+		ON CONFLICT (platform, message_id) WHERE message_id IS NOT NULL DO NOTHING
 	`
 
-	_, err = pool.Exec(ctx, query, input.ContactID, platform, sender, input.Message, input.SentAt)
+	_, err = pool.Exec(ctx, query, input.ContactID, platform, sender, input.Message, input.SentAt, input.MessageID,
+		input.ReplyToMessageID, string(media.Type), media.MimeType, media.FileName, media.Size, media.Path)
 	if err != nil {
 		return fmt.Errorf("failed to add chat entry: %w", err)
 	}
@@ -1443,6 +1523,53 @@ func UpdateChat(ctx context.Context, input UpdateChatInput) error {
 	return nil
 }
 
+// contactChatColumns selects a chat as "ch" with the text of the message it
+// replies to as "r", in the order scanContactChat expects
+const contactChatColumns = `
+	ch.id, ch.contact_id, ch.platform, ch.sender, ch.message, ch.sent_at, ch.created_at,
+	ch.message_id, ch.reply_to_message_id, r.message,
+	ch.media_type, ch.media_mime_type, ch.media_file_name, ch.media_size, ch.media_path,
+	ch.edited_at, ch.revoked_at
+`
+
+// contactChatReplyJoin finds the message a chat replies to
+const contactChatReplyJoin = `
+	LEFT JOIN contact_chats r
+		ON r.platform = ch.platform AND r.message_id = ch.reply_to_message_id AND r.revoked_at IS NULL
+`
+
+// scanContactChat scans a row selected with contactChatColumns
+func scanContactChat(rows pgx.Rows) (ContactChat, error) {
+	var (
+		chat                                    ContactChat
+		mediaType                               *string
+		mediaMimeType, mediaFileName, mediaPath *string
+		mediaSize                               *int64
+	)
+
+	if err := rows.Scan(&chat.ID, &chat.ContactID, &chat.Platform, &chat.Sender, &chat.Message, &chat.SentAt, &chat.CreatedAt,
+		&chat.MessageID, &chat.ReplyToMessageID, &chat.ReplyToMessage,
+		&mediaType, &mediaMimeType, &mediaFileName, &mediaSize, &mediaPath,
+		&chat.EditedAt, &chat.RevokedAt); err != nil {
+		return chat, fmt.Errorf("failed to scan chat: %w", err)
+	}
+
+	if mediaType != nil {
+		chat.Media = &ChatMedia{
+			Type:     ChatMediaType(*mediaType),
+			MimeType: pointerString(mediaMimeType),
+			FileName: pointerString(mediaFileName),
+			Path:     pointerString(mediaPath),
+		}
+
+		if mediaSize != nil {
+			chat.Media.Size = *mediaSize
+		}
+	}
+
+	return chat, nil
+}
+
 // GetContactChats returns chat history for a contact
 func GetContactChats(ctx context.Context, contactID string) ([]ContactChat, error) {
 	if pool == nil {
@@ -1450,10 +1577,11 @@ func GetContactChats(ctx context.Context, contactID string) ([]ContactChat, erro
 	}
 
 	query := `
-		SELECT id, contact_id, platform, sender, message, sent_at, created_at
-		FROM contact_chats
-		WHERE contact_id = $1
-		ORDER BY sent_at DESC, created_at DESC
+		SELECT ` + contactChatColumns + `
+		FROM contact_chats ch
+		` + contactChatReplyJoin + `
+		WHERE ch.contact_id = $1
+		ORDER BY ch.sent_at DESC, ch.created_at DESC
 	`
 
 	rows, err := pool.Query(ctx, query, contactID)
@@ -1465,9 +1593,9 @@ func GetContactChats(ctx context.Context, contactID string) ([]ContactChat, erro
 	var chats []ContactChat
 
 	for rows.Next() {
-		var chat ContactChat
-		if err := rows.Scan(&chat.ID, &chat.ContactID, &chat.Platform, &chat.Sender, &chat.Message, &chat.SentAt, &chat.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan chat: %w", err)
+		chat, err := scanContactChat(rows)
+		if err != nil {
+			return nil, err
 		}
 
 		chats = append(chats, chat)
@@ -1487,10 +1615,11 @@ func GetContactChatsSince(ctx context.Context, contactID string, since time.Time
 	}
 
 	query := `
-		SELECT id, contact_id, platform, sender, message, sent_at, created_at
-		FROM contact_chats
-		WHERE contact_id = $1 AND sent_at >= $2
-		ORDER BY sent_at ASC, created_at ASC
+		SELECT ` + contactChatColumns + `
+		FROM contact_chats ch
+		` + contactChatReplyJoin + `
+		WHERE ch.contact_id = $1 AND ch.sent_at >= $2
+		ORDER BY ch.sent_at ASC, ch.created_at ASC
 	`
 
 	rows, err := pool.Query(ctx, query, contactID, since)
@@ -1502,9 +1631,9 @@ func GetContactChatsSince(ctx context.Context, contactID string, since time.Time
 	var chats []ContactChat
 
 	for rows.Next() {
-		var chat ContactChat
-		if err := rows.Scan(&chat.ID, &chat.ContactID, &chat.Platform, &chat.Sender, &chat.Message, &chat.SentAt, &chat.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan chat: %w", err)
+		chat, err := scanContactChat(rows)
+		if err != nil {
+			return nil, err
 		}
 
 		chats = append(chats, chat)
@@ -1533,15 +1662,15 @@ func IsServiceContact(ctx context.Context, contactID string) (bool, error) {
 	return isService, nil
 }
 
-// LinkCardDAV links a contact with a CardDAV contact UUID
-func LinkCardDAV(ctx context.Context, contactID string, cardDAVUUID string) error {
+// LinkCardDAV links a contact with a card UUID in a CardDAV source
+func LinkCardDAV(ctx context.Context, contactID, sourceID, cardDAVUUID string) error {
 	if pool == nil {
 		return ErrDatabaseConnectionNotInitialized
 	}
 
-	query := `UPDATE contacts SET carddav_uuid = $1 WHERE id = $2`
+	query := `UPDATE contacts SET carddav_uuid = $1, carddav_source_id = $3::uuid WHERE id = $2`
 
-	_, err := pool.Exec(ctx, query, cardDAVUUID, contactID)
+	_, err := pool.Exec(ctx, query, cardDAVUUID, contactID, sourceID)
 	if err != nil {
 		return fmt.Errorf("failed to link CardDAV contact: %w", err)
 	}
@@ -1555,14 +1684,14 @@ func UnlinkCardDAV(ctx context.Context, contactID string) error {
 		return ErrDatabaseConnectionNotInitialized
 	}
 
-	query := `UPDATE contacts SET carddav_uuid = NULL WHERE id = $1`
+	query := `UPDATE contacts SET carddav_uuid = NULL, carddav_source_id = NULL WHERE id = $1`
 
 	_, err := pool.Exec(ctx, query, contactID)
 	if err != nil {
 		return fmt.Errorf("failed to unlink CardDAV contact: %w", err)
 	}
 
-	return nil
+	return clearCardDAVConflicts(ctx, contactID)
 }
 
 // GetLinkedCardDAVUUIDs returns all CardDAV UUIDs that are currently linked to contacts
@@ -1597,17 +1726,23 @@ func GetLinkedCardDAVUUIDs(ctx context.Context) ([]string, error) {
 	return uuids, nil
 }
 
-// IsCardDAVUUIDLinked checks if a CardDAV UUID is already linked to a contact
-func IsCardDAVUUIDLinked(ctx context.Context, uuid string) (bool, error) {
+// IsCardDAVUUIDLinked checks if a card in a CardDAV source is already linked
+// to a contact
+func IsCardDAVUUIDLinked(ctx context.Context, sourceID, uuid string) (bool, error) {
 	if pool == nil {
 		return false, ErrDatabaseConnectionNotInitialized
 	}
 
-	query := `SELECT EXISTS(SELECT 1 FROM contacts WHERE carddav_uuid = $1)`
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM contacts
+			WHERE carddav_source_id = $1::uuid AND lower(carddav_uuid) = lower($2)
+		)
+	`
 
 	var exists bool
 
-	err := pool.QueryRow(ctx, query, uuid).Scan(&exists)
+	err := pool.QueryRow(ctx, query, sourceID, uuid).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if CardDAV UUID is linked: %w", err)
 	}
@@ -1615,8 +1750,9 @@ func IsCardDAVUUIDLinked(ctx context.Context, uuid string) (bool, error) {
 	return exists, nil
 }
 
-// MigrateContactToCardDAV creates a new CardDAV contact from local data and links it
-func MigrateContactToCardDAV(ctx context.Context, contactID string) error {
+// MigrateContactToCardDAV creates a new card in a CardDAV source from local
+// data and links it
+func MigrateContactToCardDAV(ctx context.Context, contactID, sourceID string) error {
 	if pool == nil {
 		return ErrDatabaseConnectionNotInitialized
 	}
@@ -1635,7 +1771,7 @@ func MigrateContactToCardDAV(ctx context.Context, contactID string) error {
 	}
 
 	// Create the CardDAV contact
-	newUUID, err := CreateCardDAVContact(ctx, contact)
+	newUUID, err := CreateCardDAVContact(ctx, sourceID, contact)
 	if err != nil {
 		return fmt.Errorf("failed to create CardDAV contact: %w", err)
 	}
@@ -1672,10 +1808,19 @@ func MigrateContactToCardDAV(ctx context.Context, contactID string) error {
 		return fmt.Errorf("failed to update phone sources: %w", err)
 	}
 
+	// Update the source of local addresses to 'carddav'
+	_, err = tx.Exec(ctx, `
+		UPDATE contact_addresses SET source = 'carddav'
+		WHERE contact_id = $1 AND source = 'local'
+	`, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to update address sources: %w", err)
+	}
+
 	// Link the contact to the new CardDAV UUID
 	_, err = tx.Exec(ctx, `
-		UPDATE contacts SET carddav_uuid = $1 WHERE id = $2
-	`, newUUID, contactID)
+		UPDATE contacts SET carddav_uuid = $1, carddav_source_id = $3::uuid WHERE id = $2
+	`, newUUID, contactID, sourceID)
 	if err != nil {
 		return fmt.Errorf("failed to link contact to CardDAV: %w", err)
 	}
@@ -1703,30 +1848,19 @@ type OverdueContactItem struct {
 	LastContactDate *string `db:"last_contact_date"`
 	DaysOverdue     int     `db:"days_overdue"`
 	ContactInterval int     `db:"contact_interval"`
+	CadenceSource   CadenceSource
+	CadenceTag      *string // Tag providing the interval when CadenceSource is tag
 }
 
-// GetOverdueContacts returns contacts that are overdue for contact, sorted by most overdue first
+// GetOverdueContacts returns contacts that are overdue for contact, sorted by most overdue first.
+// Intervals come from the configured cadences; snoozed contacts are skipped.
 func GetOverdueContacts(ctx context.Context) ([]OverdueContactItem, error) {
 	if pool == nil {
 		return nil, ErrDatabaseConnectionNotInitialized
 	}
 
 	query := `
-		WITH contact_intervals AS (
-			SELECT
-				id,
-				tier,
-				CASE tier
-					WHEN 'A' THEN 21   -- 3 weeks
-					WHEN 'B' THEN 60   -- 2 months
-					WHEN 'C' THEN 180  -- 6 months
-					WHEN 'D' THEN 365  -- 1 year
-					WHEN 'E' THEN 730  -- 2 years
-					ELSE NULL
-				END as interval_days
-			FROM contacts
-			WHERE tier != 'F'  -- Exclude F tier (no regular contact)
-		),
+		WITH ` + effectiveCadenceCTE + `,
 		last_contacts AS (
 			SELECT
 				c.id as contact_id,
@@ -1752,6 +1886,8 @@ func GetOverdueContacts(ctx context.Context) ([]OverdueContactItem, error) {
 					CAST(EXTRACT(EPOCH FROM (NOW() - lc.last_contact_date)) / 86400 AS INTEGER)
 			END as days_since_contact,
 			ci.interval_days,
+			ci.cadence_source,
+			ci.cadence_tag,
 			CASE
 				WHEN lc.last_contact_date IS NULL THEN
 					CAST(EXTRACT(EPOCH FROM (NOW() - c.created_at)) / 86400 AS INTEGER) - ci.interval_days
@@ -1764,6 +1900,7 @@ func GetOverdueContacts(ctx context.Context) ([]OverdueContactItem, error) {
 		WHERE
 			ci.interval_days IS NOT NULL
 			AND c.is_service = false
+			AND (c.cadence_snoozed_until IS NULL OR c.cadence_snoozed_until <= CURRENT_DATE)
 			AND (
 				CASE
 					WHEN lc.last_contact_date IS NULL THEN
@@ -1787,6 +1924,7 @@ func GetOverdueContacts(ctx context.Context) ([]OverdueContactItem, error) {
 		var (
 			contact          OverdueContactItem
 			daysSinceContact int
+			cadenceSource    string
 		)
 
 		err := rows.Scan(
@@ -1800,11 +1938,15 @@ func GetOverdueContacts(ctx context.Context) ([]OverdueContactItem, error) {
 			&contact.LastContactDate,
 			&daysSinceContact,
 			&contact.ContactInterval,
+			&cadenceSource,
+			&contact.CadenceTag,
 			&contact.DaysOverdue,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan overdue contact: %w", err)
 		}
+
+		contact.CadenceSource = CadenceSource(cadenceSource)
 		// Set lowercase tier for CSS classes
 		contact.TierLower = strings.ToLower(string(contact.Tier))
 		contacts = append(contacts, contact)
@@ -2084,15 +2226,19 @@ func ToggleServiceStatus(ctx context.Context, contactID string, isService bool)
 	return nil
 }
 
-// GetLinkedCardDAVUUIDsWithServiceStatus returns CardDAV UUIDs with service flag
-func GetLinkedCardDAVUUIDsWithServiceStatus(ctx context.Context) (map[string]bool, error) {
+// GetLinkedCardDAVUUIDsWithServiceStatus returns the linked card UUIDs of a
+// source with their service flag
+func GetLinkedCardDAVUUIDsWithServiceStatus(ctx context.Context, sourceID string) (map[string]bool, error) {
 	if pool == nil {
 		return nil, ErrDatabaseConnectionNotInitialized
 	}
 
-	query := `SELECT carddav_uuid, is_service FROM contacts WHERE carddav_uuid IS NOT NULL`
+	query := `
+		SELECT carddav_uuid, is_service FROM contacts
+		WHERE carddav_uuid IS NOT NULL AND carddav_source_id = $1::uuid
+	`
 
-	rows, err := pool.Query(ctx, query)
+	rows, err := pool.Query(ctx, query, sourceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query linked CardDAV UUIDs: %w", err)
 	}
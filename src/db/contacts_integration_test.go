@@ -4,6 +4,7 @@
 package db
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -355,11 +356,13 @@ func TestContactDetailsAndLogs(t *testing.T) {
 		t.Fatalf("DeleteNote failed: %v", err)
 	}
 
-	if err := LinkCardDAV(ctx, contactID, "carddav-uuid"); err != nil {
+	sourceID := mustCreateCardDAVSource(t, "Default", "https://dav.invalid/addressbook/")
+
+	if err := LinkCardDAV(ctx, contactID, sourceID, "carddav-uuid"); err != nil {
 		t.Fatalf("LinkCardDAV failed: %v", err)
 	}
 
-	linked, err := IsCardDAVUUIDLinked(ctx, "carddav-uuid")
+	linked, err := IsCardDAVUUIDLinked(ctx, sourceID, "carddav-uuid")
 	if err != nil {
 		t.Fatalf("IsCardDAVUUIDLinked failed: %v", err)
 	}
@@ -377,7 +380,7 @@ func TestContactDetailsAndLogs(t *testing.T) {
 		t.Fatalf("expected 1 linked uuid, got %d", len(linkedUUIDs))
 	}
 
-	statusMap, err := GetLinkedCardDAVUUIDsWithServiceStatus(ctx)
+	statusMap, err := GetLinkedCardDAVUUIDsWithServiceStatus(ctx, sourceID)
 	if err != nil {
 		t.Fatalf("GetLinkedCardDAVUUIDsWithServiceStatus failed: %v", err)
 	}
@@ -559,7 +562,9 @@ func TestContactSearchQuerySyntax(t *testing.T) {
 		t.Fatalf("AddTagToContact service university failed: %v", err)
 	}
 
-	if err := LinkCardDAV(ctx, aliID, "carddav-ali"); err != nil {
+	sourceID := mustCreateCardDAVSource(t, "Default", "https://dav.invalid/addressbook/")
+
+	if err := LinkCardDAV(ctx, aliID, sourceID, "carddav-ali"); err != nil {
 		t.Fatalf("LinkCardDAV failed: %v", err)
 	}
 
@@ -636,6 +641,70 @@ func TestContactSearchQuerySyntax(t *testing.T) {
 	}
 }
 
+func TestContactSearchOperators(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	aliID := mustCreateContact(t, CreateContactInput{NameGiven: "Ali", Organization: stringPtr("Acme Corp"), Tier: TierA})
+	noraID := mustCreateContact(t, CreateContactInput{NameGiven: "Nora", Organization: stringPtr("Globex"), Tier: TierB})
+	omarID := mustCreateContact(t, CreateContactInput{NameGiven: "Omar", Tier: TierC})
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET timezone = 'Asia/Dubai', birthday = '1990-03-14' WHERE id = $1`, aliID); err != nil {
+		t.Fatalf("failed to set Ali details: %v", err)
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE contacts SET created_at = '2024-06-01' WHERE id = $1`, omarID); err != nil {
+		t.Fatalf("failed to backdate Omar: %v", err)
+	}
+
+	if err := AddTagToContact(ctx, aliID, "work"); err != nil {
+		t.Fatalf("AddTagToContact work failed: %v", err)
+	}
+
+	if err := AddTagToContact(ctx, noraID, "family"); err != nil {
+		t.Fatalf("AddTagToContact family failed: %v", err)
+	}
+
+	if err := AddLog(ctx, AddLogInput{ContactID: noraID, LogType: LogGeneral}); err != nil {
+		t.Fatalf("AddLog failed: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"-tag:work", []string{noraID, omarID}},
+		{"tag:work OR tag:family", []string{aliID, noraID}},
+		{`org:"acme corp"`, []string{aliID}},
+		{"tz:dubai", []string{aliID}},
+		{"birthday:march", []string{aliID}},
+		{"contacted:<30d", []string{noraID}},
+		{"contacted:never", []string{aliID, omarID}},
+		{"-contacted:<30d", []string{aliID, omarID}},
+		{"created:<2025-01-01", []string{omarID}},
+		{"overdue:yes", []string{omarID}},
+		{"-tier:a -tier:b", []string{omarID}},
+		{"-nora", []string{aliID, omarID}},
+	}
+
+	for _, tt := range tests {
+		contacts, err := ListContactsWithFilters(ctx, ContactListOptions{SearchQuery: tt.query, AlphabeticSort: true})
+		if err != nil {
+			t.Fatalf("ListContactsWithFilters %q failed: %v", tt.query, err)
+		}
+
+		got := make([]string, 0, len(contacts))
+		for _, contact := range contacts {
+			got = append(got, contact.ID)
+		}
+
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("query %q returned %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
 func TestUpdateContactLinksQSOs(t *testing.T) {
 	resetDatabase(t)
 
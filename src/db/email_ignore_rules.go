@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// EmailIgnoreRuleType is what an email ignore rule matches
+type EmailIgnoreRuleType string
+
+const (
+	// EmailIgnoreFolder skips a mailbox folder entirely
+	EmailIgnoreFolder EmailIgnoreRuleType = "folder"
+	// EmailIgnoreAddress skips an address, domain or local part
+	EmailIgnoreAddress EmailIgnoreRuleType = "address"
+)
+
+// EmailIgnoreRule keeps a folder or address out of email polling
+type EmailIgnoreRule struct {
+	ID        uuid.UUID           `db:"id"`
+	RuleType  EmailIgnoreRuleType `db:"rule_type"`
+	Pattern   string              `db:"pattern"`
+	CreatedAt time.Time           `db:"created_at"`
+}
+
+// ListEmailIgnoreRules returns the ignore rules, folders first
+func ListEmailIgnoreRules(ctx context.Context) ([]EmailIgnoreRule, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, rule_type, pattern, created_at
+		FROM email_ignore_rules
+		ORDER BY rule_type DESC, LOWER(pattern)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email ignore rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []EmailIgnoreRule
+
+	for rows.Next() {
+		var rule EmailIgnoreRule
+		if err := rows.Scan(&rule.ID, &rule.RuleType, &rule.Pattern, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email ignore rule: %w", err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate email ignore rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// AddEmailIgnoreRule adds a folder name, or an address pattern such as
+// someone@example.com, @example.com or noreply@
+func AddEmailIgnoreRule(ctx context.Context, ruleType EmailIgnoreRuleType, pattern string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	pattern = strings.TrimSpace(pattern)
+
+	switch ruleType {
+	case EmailIgnoreFolder:
+		if pattern == "" {
+			return ErrEmailIgnoreRuleInvalid
+		}
+	case EmailIgnoreAddress:
+		pattern = normalizeEmailAddress(pattern)
+		if strings.Count(pattern, "@") != 1 || pattern == "@" || strings.ContainsAny(pattern, " \t") {
+			return ErrEmailIgnoreRuleInvalid
+		}
+	default:
+		return ErrEmailIgnoreRuleInvalid
+	}
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO email_ignore_rules (rule_type, pattern) VALUES ($1, $2)
+	`, ruleType, pattern)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailIgnoreRuleExists
+		}
+
+		return fmt.Errorf("failed to add email ignore rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEmailIgnoreRule removes an ignore rule
+func DeleteEmailIgnoreRule(ctx context.Context, id string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	parsedID, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return ErrEmailIgnoreRuleNotFound
+	}
+
+	var deletedID uuid.UUID
+
+	err = pool.QueryRow(ctx, `DELETE FROM email_ignore_rules WHERE id = $1 RETURNING id`, parsedID).Scan(&deletedID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrEmailIgnoreRuleNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to delete email ignore rule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,785 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// emailPollInterval is how often the worker checks the mailbox
+	emailPollInterval = 15 * time.Minute
+	// emailPollTimeout bounds each command sent to the IMAP server
+	emailPollTimeout = 60 * time.Second
+	// emailPollMaxErrors caps the folder errors kept per poll
+	emailPollMaxErrors = 20
+)
+
+// emailPollMu stops the worker and a manual poll from overlapping
+var emailPollMu sync.Mutex
+
+// EmailPollConfig is the IMAP account interactions are read from
+type EmailPollConfig struct {
+	// Addr is the host and port of the server
+	Addr string
+	// TLS connects with implicit TLS instead of upgrading with STARTTLS
+	TLS      bool
+	Username string
+	Password string
+	// AllowInsecure logs in over plaintext when the server offers no
+	// STARTTLS, instead of refusing to send the password
+	AllowInsecure bool
+	// Addresses are the account owner's own addresses, besides those of
+	// the me contact
+	Addresses []string
+}
+
+// EmailPollConfigFromEnv reads the IMAP account from IMAP_URL (imaps://host
+// or imap://host, with an optional port), IMAP_USERNAME, IMAP_PASSWORD, the
+// optional comma separated IMAP_ADDRESSES and IMAP_ALLOW_INSECURE
+func EmailPollConfigFromEnv() (*EmailPollConfig, error) {
+	rawURL := strings.TrimSpace(os.Getenv("IMAP_URL"))
+	if rawURL == "" {
+		return nil, ErrEmailPollNotConfigured
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %s", ErrEmailPollURLInvalid, rawURL)
+	}
+
+	config := &EmailPollConfig{
+		Username: os.Getenv("IMAP_USERNAME"),
+		Password: os.Getenv("IMAP_PASSWORD"),
+	}
+
+	if allow, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("IMAP_ALLOW_INSECURE"))); err == nil {
+		config.AllowInsecure = allow
+	}
+
+	port := parsed.Port()
+
+	switch parsed.Scheme {
+	case "imaps":
+		config.TLS = true
+
+		if port == "" {
+			port = "993"
+		}
+	case "imap":
+		if port == "" {
+			port = "143"
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrEmailPollURLInvalid, rawURL)
+	}
+
+	config.Addr = net.JoinHostPort(parsed.Hostname(), port)
+
+	for _, address := range strings.Split(os.Getenv("IMAP_ADDRESSES"), ",") {
+		if address = normalizeEmailAddress(address); address != "" {
+			config.Addresses = append(config.Addresses, address)
+		}
+	}
+
+	if strings.Contains(config.Username, "@") {
+		config.Addresses = append(config.Addresses, normalizeEmailAddress(config.Username))
+	}
+
+	return config, nil
+}
+
+// EmailFolderState is how far a mailbox folder has been read
+type EmailFolderState struct {
+	Folder       string    `db:"folder"`
+	UIDValidity  uint32    `db:"uid_validity"`
+	LastUID      uint32    `db:"last_uid"`
+	Interactions int       `db:"interactions"`
+	PolledAt     time.Time `db:"polled_at"`
+}
+
+// EmailPollResult summarises one poll of the mailbox
+type EmailPollResult struct {
+	Folders      int // Folders read
+	Messages     int // New messages seen
+	Interactions int // Contact logs recorded
+	Duplicates   int // Interactions already logged from another folder
+	Errors       []string
+}
+
+// Summary describes the poll in a short sentence
+func (r EmailPollResult) Summary() string {
+	summary := fmt.Sprintf("%s in %s, %s",
+		pluralize(r.Messages, "new message", "new messages"),
+		pluralize(r.Folders, "folder", "folders"),
+		pluralize(r.Interactions, "interaction logged", "interactions logged"))
+
+	if len(r.Errors) > 0 {
+		summary += ", " + pluralize(len(r.Errors), "folder failed", "folders failed")
+	}
+
+	return summary
+}
+
+func (r *EmailPollResult) addError(message string) {
+	if len(r.Errors) < emailPollMaxErrors {
+		r.Errors = append(r.Errors, message)
+	}
+}
+
+// emailEnvelope is the part of a message an interaction is recorded from
+type emailEnvelope struct {
+	UID       uint32
+	MessageID string
+	Subject   string
+	Date      time.Time
+	From      []string
+	To        []string
+	Cc        []string
+}
+
+// emailContact is a contact an address belongs to
+type emailContact struct {
+	id        string
+	isService bool
+}
+
+// StartEmailPollWorker starts a background goroutine that periodically
+// records email interactions from the IMAP account, when one is configured
+func StartEmailPollWorker(ctx context.Context) {
+	if _, err := EmailPollConfigFromEnv(); err != nil {
+		if !errors.Is(err, ErrEmailPollNotConfigured) {
+			logger.Error("Email polling disabled", "error", err)
+		}
+
+		return
+	}
+
+	go func() {
+		// Initial delay to let the application start up
+		logger.Info("Email poll worker starting in 30 seconds")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+
+		runScheduledEmailPoll(ctx)
+
+		ticker := time.NewTicker(emailPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("Email poll worker shutting down")
+				return
+			case <-ticker.C:
+				runScheduledEmailPoll(ctx)
+			}
+		}
+	}()
+}
+
+func runScheduledEmailPoll(ctx context.Context) {
+	result, err := PollEmail(ctx)
+
+	switch {
+	case errors.Is(err, ErrEmailPollInProgress):
+		logger.Info("Email poll already running, skipping scheduled run")
+	case err != nil:
+		logger.Error("Email poll failed", "error", err)
+	case len(result.Errors) > 0:
+		logger.Warn("Email poll completed with errors", "summary", result.Summary(), "errors", result.Errors)
+	case result.Messages > 0:
+		logger.Info("Email poll completed", "summary", result.Summary())
+	}
+}
+
+// PollEmail reads the messages that arrived in every folder of the IMAP
+// account since the last poll and logs an email_received or email_sent
+// interaction for each contact involved, moving last_auto_contact up to the
+// message date. Incoming messages count for the contacts in From, outgoing
+// ones for those in To and Cc. Ignored folders and addresses are skipped.
+func PollEmail(ctx context.Context) (EmailPollResult, error) {
+	var result EmailPollResult
+
+	if pool == nil {
+		return result, ErrDatabaseConnectionNotInitialized
+	}
+
+	config, err := EmailPollConfigFromEnv()
+	if err != nil {
+		return result, err
+	}
+
+	if !emailPollMu.TryLock() {
+		return result, ErrEmailPollInProgress
+	}
+	defer emailPollMu.Unlock()
+
+	rules, err := ListEmailIgnoreRules(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	states, err := loadEmailFolderStates(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	contacts, ownAddresses, err := loadEmailContactIndex(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, address := range config.Addresses {
+		ownAddresses[address] = true
+	}
+
+	c, err := dialEmailServer(config)
+	if err != nil {
+		return result, err
+	}
+
+	defer func() {
+		if err := c.Logout(); err != nil {
+			logger.Debug("Failed to log out of IMAP server", "error", err)
+		}
+	}()
+
+	folders, err := listEmailFolders(c)
+	if err != nil {
+		return result, err
+	}
+
+	for _, folder := range folders {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		if hasEmailFolderAttr(folder, imap.NoSelectAttr) || emailFolderIgnored(rules, folder) {
+			continue
+		}
+
+		state := states[folder.Name]
+
+		uidValidity, envelopes, err := fetchEmailEnvelopes(c, folder.Name, state)
+		if err != nil {
+			result.addError(fmt.Sprintf("%s: %v", folder.Name, err))
+			continue
+		}
+
+		result.Folders++
+		result.Messages += len(envelopes)
+
+		state.Folder = folder.Name
+		state.UIDValidity = uidValidity
+
+		sentFolder := hasEmailFolderAttr(folder, imap.SentAttr)
+
+		for _, envelope := range envelopes {
+			recorded, duplicates, err := recordEmailEnvelope(ctx, envelope, sentFolder, ownAddresses, contacts, rules)
+			if err != nil {
+				return result, err
+			}
+
+			result.Interactions += recorded
+			result.Duplicates += duplicates
+			state.Interactions += recorded
+
+			if envelope.UID > state.LastUID {
+				state.LastUID = envelope.UID
+			}
+		}
+
+		if err := saveEmailFolderState(ctx, state); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// dialEmailServer connects and logs in to the IMAP server
+func dialEmailServer(config *EmailPollConfig) (*client.Client, error) {
+	host, _, err := net.SplitHostPort(config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEmailPollURLInvalid, config.Addr)
+	}
+
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: emailPollTimeout}
+
+	var c *client.Client
+	if config.TLS {
+		c, err = client.DialWithDialerTLS(dialer, config.Addr, tlsConfig)
+	} else {
+		c, err = client.DialWithDialer(dialer, config.Addr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+
+	c.Timeout = emailPollTimeout
+
+	// The password is never sent in the clear unless explicitly allowed, so
+	// a STARTTLS capability stripped on the way cannot leak it
+	if !config.TLS {
+		supported, err := c.SupportStartTLS()
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to read IMAP capabilities: %w", err), c.Terminate())
+		}
+
+		switch {
+		case supported:
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return nil, errors.Join(fmt.Errorf("failed to start TLS: %w", err), c.Terminate())
+			}
+		case !config.AllowInsecure:
+			return nil, errors.Join(ErrEmailPollInsecure, c.Terminate())
+		}
+	}
+
+	if err := c.Login(config.Username, config.Password); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to log in to IMAP server: %w", err), c.Terminate())
+	}
+
+	return c, nil
+}
+
+// listEmailFolders returns every folder of the account
+func listEmailFolders(c *client.Client) ([]*imap.MailboxInfo, error) {
+	ch := make(chan *imap.MailboxInfo, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.List("", "*", ch)
+	}()
+
+	var folders []*imap.MailboxInfo
+	for folder := range ch {
+		folders = append(folders, folder)
+	}
+
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("failed to list IMAP folders: %w", err)
+	}
+
+	return folders, nil
+}
+
+// fetchEmailEnvelopes reads the envelopes of the messages added to a folder
+// since the state was saved. A changed UIDVALIDITY means the server
+// renumbered the folder, so it is read again from the start.
+func fetchEmailEnvelopes(c *client.Client, folder string, state EmailFolderState) (uint32, []emailEnvelope, error) {
+	status, err := c.Select(folder, true)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to select folder: %w", err)
+	}
+
+	lastUID := state.LastUID
+	if status.UidValidity != state.UIDValidity {
+		lastUID = 0
+	}
+
+	if status.Messages == 0 || (status.UidNext > 0 && status.UidNext <= lastUID+1) {
+		return status.UidValidity, nil, nil
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddRange(lastUID+1, 0)
+
+	ch := make(chan *imap.Message, 50)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.UidFetch(seqSet, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchInternalDate}, ch)
+	}()
+
+	var envelopes []emailEnvelope
+
+	for message := range ch {
+		// A range ending in * always includes the last message, even when
+		// it was read before
+		if message.Uid <= lastUID || message.Envelope == nil {
+			continue
+		}
+
+		envelope := emailEnvelope{
+			UID:       message.Uid,
+			MessageID: strings.TrimSpace(message.Envelope.MessageId),
+			Subject:   strings.TrimSpace(message.Envelope.Subject),
+			Date:      message.Envelope.Date,
+			From:      emailAddresses(message.Envelope.From),
+			To:        emailAddresses(message.Envelope.To),
+			Cc:        emailAddresses(message.Envelope.Cc),
+		}
+
+		if envelope.Date.IsZero() {
+			envelope.Date = message.InternalDate
+		}
+
+		envelopes = append(envelopes, envelope)
+	}
+
+	if err := <-done; err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+
+	return status.UidValidity, envelopes, nil
+}
+
+func emailAddresses(addresses []*imap.Address) []string {
+	var normalized []string
+
+	for _, address := range addresses {
+		if address == nil || address.MailboxName == "" || address.HostName == "" {
+			continue
+		}
+
+		normalized = append(normalized, normalizeEmailAddress(address.Address()))
+	}
+
+	return normalized
+}
+
+func normalizeEmailAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+func hasEmailFolderAttr(folder *imap.MailboxInfo, attr string) bool {
+	for _, folderAttr := range folder.Attributes {
+		if strings.EqualFold(folderAttr, attr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emailFolderIgnored reports whether a folder is junk, trash or drafts by
+// its special use, or matches a folder rule by its full name or last part
+func emailFolderIgnored(rules []EmailIgnoreRule, folder *imap.MailboxInfo) bool {
+	for _, attr := range []string{imap.JunkAttr, imap.TrashAttr, imap.DraftsAttr} {
+		if hasEmailFolderAttr(folder, attr) {
+			return true
+		}
+	}
+
+	name := folder.Name
+	leaf := name
+
+	if folder.Delimiter != "" {
+		if index := strings.LastIndex(name, folder.Delimiter); index >= 0 {
+			leaf = name[index+len(folder.Delimiter):]
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.RuleType == EmailIgnoreFolder && (strings.EqualFold(rule.Pattern, name) || strings.EqualFold(rule.Pattern, leaf)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emailAddressIgnored reports whether an address matches an address rule.
+// A rule is a full address, a domain (@example.com, also covering its
+// subdomains) or a local part at any domain (noreply@).
+func emailAddressIgnored(rules []EmailIgnoreRule, address string) bool {
+	localPart, domain, ok := strings.Cut(address, "@")
+	if !ok {
+		return false
+	}
+
+	for _, rule := range rules {
+		if rule.RuleType != EmailIgnoreAddress {
+			continue
+		}
+
+		pattern := normalizeEmailAddress(rule.Pattern)
+
+		switch {
+		case strings.HasPrefix(pattern, "@"):
+			if domain == pattern[1:] || strings.HasSuffix(domain, "."+pattern[1:]) {
+				return true
+			}
+		case strings.HasSuffix(pattern, "@"):
+			if localPart == strings.TrimSuffix(pattern, "@") {
+				return true
+			}
+		case pattern == address:
+			return true
+		}
+	}
+
+	return false
+}
+
+// emailCounterparts returns the addresses a message is an interaction with,
+// and whether it was sent by the account owner
+func emailCounterparts(envelope emailEnvelope, sentFolder bool, ownAddresses map[string]bool) ([]string, bool) {
+	outgoing := sentFolder
+
+	for _, address := range envelope.From {
+		if ownAddresses[address] {
+			outgoing = true
+		}
+	}
+
+	candidates := envelope.From
+	if outgoing {
+		candidates = append(append([]string{}, envelope.To...), envelope.Cc...)
+	}
+
+	seen := make(map[string]bool)
+
+	var counterparts []string
+
+	for _, address := range candidates {
+		if ownAddresses[address] || seen[address] {
+			continue
+		}
+
+		seen[address] = true
+		counterparts = append(counterparts, address)
+	}
+
+	return counterparts, outgoing
+}
+
+// recordEmailEnvelope logs a message for every contact it involves and
+// returns how many logs were added and how many already existed
+func recordEmailEnvelope(ctx context.Context, envelope emailEnvelope, sentFolder bool, ownAddresses map[string]bool,
+	contacts map[string]emailContact, rules []EmailIgnoreRule,
+) (int, int, error) {
+	counterparts, outgoing := emailCounterparts(envelope, sentFolder, ownAddresses)
+
+	logType := LogEmailReceived
+	if outgoing {
+		logType = LogEmailSent
+	}
+
+	var recorded, duplicates int
+
+	logged := make(map[string]bool)
+
+	for _, address := range counterparts {
+		contact, ok := contacts[address]
+		if !ok || logged[contact.id] || emailAddressIgnored(rules, address) {
+			continue
+		}
+
+		logged[contact.id] = true
+
+		added, err := recordEmailInteraction(ctx, contact, logType, envelope)
+		if err != nil {
+			return recorded, duplicates, err
+		}
+
+		if added {
+			recorded++
+		} else {
+			duplicates++
+		}
+	}
+
+	return recorded, duplicates, nil
+}
+
+// recordEmailInteraction adds an email log to a contact unless one for the
+// same Message-ID exists, and moves last_auto_contact up to the message
+// date. Service contacts only get the timestamp.
+func recordEmailInteraction(ctx context.Context, contact emailContact, logType LogType, envelope emailEnvelope) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back email interaction", "error", err)
+		}
+	}()
+
+	added := false
+
+	if !contact.isService {
+		subject := envelope.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO contact_logs (contact_id, log_type, logged_at, subject, email_message_id)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+			ON CONFLICT (contact_id, email_message_id) WHERE email_message_id IS NOT NULL DO NOTHING
+		`, contact.id, logType, envelope.Date, subject, envelope.MessageID)
+		if err != nil {
+			return false, fmt.Errorf("failed to log email interaction: %w", err)
+		}
+
+		added = tag.RowsAffected() > 0
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE contacts
+		SET last_auto_contact = GREATEST(last_auto_contact, $2), updated_at = NOW()
+		WHERE id = $1
+	`, contact.id, envelope.Date)
+	if err != nil {
+		return false, fmt.Errorf("failed to update auto contact timestamp: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit email interaction: %w", err)
+	}
+
+	return added, nil
+}
+
+// loadEmailContactIndex maps contact email addresses to their contacts,
+// preferring higher tiers when an address is shared, and returns the
+// addresses of the me contact separately
+func loadEmailContactIndex(ctx context.Context) (map[string]emailContact, map[string]bool, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT LOWER(TRIM(ce.email)), c.id, c.is_me, c.is_service
+		FROM contact_emails ce
+		JOIN contacts c ON c.id = ce.contact_id
+		ORDER BY
+			CASE c.tier
+				WHEN 'A' THEN 1
+				WHEN 'B' THEN 2
+				WHEN 'C' THEN 3
+				WHEN 'D' THEN 4
+				WHEN 'E' THEN 5
+				WHEN 'F' THEN 6
+			END,
+			c.created_at
+	`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query contact emails: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := make(map[string]emailContact)
+	ownAddresses := make(map[string]bool)
+
+	for rows.Next() {
+		var (
+			address string
+			contact emailContact
+			isMe    bool
+		)
+
+		if err := rows.Scan(&address, &contact.id, &isMe, &contact.isService); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan contact email: %w", err)
+		}
+
+		if isMe {
+			ownAddresses[address] = true
+			continue
+		}
+
+		if _, ok := contacts[address]; !ok {
+			contacts[address] = contact
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate contact emails: %w", err)
+	}
+
+	return contacts, ownAddresses, nil
+}
+
+func loadEmailFolderStates(ctx context.Context) (map[string]EmailFolderState, error) {
+	states, err := ListEmailFolderStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byFolder := make(map[string]EmailFolderState, len(states))
+	for _, state := range states {
+		byFolder[state.Folder] = state
+	}
+
+	return byFolder, nil
+}
+
+func saveEmailFolderState(ctx context.Context, state EmailFolderState) error {
+	_, err := pool.Exec(ctx, `
+		INSERT INTO email_poll_folders (folder, uid_validity, last_uid, interactions, polled_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (folder) DO UPDATE
+		SET uid_validity = EXCLUDED.uid_validity,
+			last_uid = EXCLUDED.last_uid,
+			interactions = EXCLUDED.interactions,
+			polled_at = EXCLUDED.polled_at
+	`, state.Folder, int64(state.UIDValidity), int64(state.LastUID), state.Interactions)
+	if err != nil {
+		return fmt.Errorf("failed to save email folder state: %w", err)
+	}
+
+	return nil
+}
+
+// ListEmailFolderStates returns how far each polled folder has been read
+func ListEmailFolderStates(ctx context.Context) ([]EmailFolderState, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT folder, uid_validity, last_uid, interactions, polled_at
+		FROM email_poll_folders
+		ORDER BY folder
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query email folders: %w", err)
+	}
+	defer rows.Close()
+
+	var states []EmailFolderState
+
+	for rows.Next() {
+		var (
+			state                EmailFolderState
+			uidValidity, lastUID int64
+		)
+
+		if err := rows.Scan(&state.Folder, &uidValidity, &lastUID, &state.Interactions, &state.PolledAt); err != nil {
+			return nil, fmt.Errorf("failed to scan email folder: %w", err)
+		}
+
+		state.UIDValidity = uint32(uidValidity)
+		state.LastUID = uint32(lastUID)
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate email folders: %w", err)
+	}
+
+	return states, nil
+}
@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollEmail(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	config, user := startTestIMAPServer(t)
+	t.Setenv("IMAP_URL", "imap://"+config.Addr)
+	t.Setenv("IMAP_USERNAME", config.Username)
+	t.Setenv("IMAP_PASSWORD", config.Password)
+	t.Setenv("IMAP_ALLOW_INSECURE", "true")
+	t.Setenv("IMAP_ADDRESSES", "")
+
+	meEmail := "me@example.net"
+	meID := mustCreateContact(t, CreateContactInput{NameGiven: "Me", Email: &meEmail, Tier: TierA})
+
+	if err := SetContactAsMe(ctx, meID); err != nil {
+		t.Fatalf("SetContactAsMe failed: %v", err)
+	}
+
+	saraEmail := "Sara@Example.com"
+	saraID := mustCreateContact(t, CreateContactInput{NameGiven: "Sara", Email: &saraEmail, Tier: TierB})
+
+	omarEmail := "omar@example.com"
+	omarID := mustCreateContact(t, CreateContactInput{NameGiven: "Omar", Email: &omarEmail, Tier: TierC})
+
+	shopEmail := "noreply@shop.example"
+	shopID := mustCreateContact(t, CreateContactInput{NameGiven: "Shop", Email: &shopEmail, Tier: TierF})
+
+	if err := AddEmailIgnoreRule(ctx, EmailIgnoreAddress, "noreply@"); err != nil {
+		t.Fatalf("AddEmailIgnoreRule failed: %v", err)
+	}
+
+	if err := AddEmailIgnoreRule(ctx, EmailIgnoreFolder, "Spam"); err != nil {
+		t.Fatalf("AddEmailIgnoreRule failed: %v", err)
+	}
+
+	for _, folder := range []string{"Sent", "Spam"} {
+		if err := user.CreateMailbox(folder); err != nil {
+			t.Fatalf("failed to create %s: %v", folder, err)
+		}
+	}
+
+	appendTestEmail(t, user, "INBOX", "From: Sara <sara@example.com>\r\n"+
+		"To: me@example.net\r\nCc: omar@example.com\r\n"+
+		"Subject: Lunch\r\nDate: Mon, 03 Feb 2025 12:00:00 +0000\r\n"+
+		"Message-ID: <lunch@example.com>\r\n")
+	appendTestEmail(t, user, "INBOX", "From: noreply@shop.example\r\nTo: me@example.net\r\n"+
+		"Subject: Your order\r\nDate: Mon, 03 Feb 2025 13:00:00 +0000\r\n"+
+		"Message-ID: <order@shop.example>\r\n")
+	appendTestEmail(t, user, "Sent", "From: me@example.net\r\n"+
+		"To: sara@example.com, omar@example.com\r\n"+
+		"Subject: Re: Lunch\r\nDate: Mon, 03 Feb 2025 14:00:00 +0000\r\n"+
+		"Message-ID: <reply@example.net>\r\n")
+	appendTestEmail(t, user, "Spam", "From: sara@example.com\r\nTo: me@example.net\r\n"+
+		"Subject: Prize\r\nDate: Tue, 04 Feb 2025 09:00:00 +0000\r\n"+
+		"Message-ID: <prize@example.com>\r\n")
+
+	result, err := PollEmail(ctx)
+	if err != nil {
+		t.Fatalf("PollEmail failed: %v", err)
+	}
+
+	if result.Folders != 2 || result.Messages != 4 || result.Interactions != 3 || result.Duplicates != 0 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected poll result %+v", result)
+	}
+
+	sara, err := GetContact(ctx, saraID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(sara.Logs) != 2 {
+		t.Fatalf("expected a received and a sent email for Sara, got %+v", sara.Logs)
+	}
+
+	types := map[LogType]string{}
+	for _, log := range sara.Logs {
+		types[log.LogType] = *log.Subject
+	}
+
+	if types[LogEmailReceived] != "Lunch" || types[LogEmailSent] != "Re: Lunch" {
+		t.Fatalf("unexpected email logs %+v", types)
+	}
+
+	reply := time.Date(2025, 2, 3, 14, 0, 0, 0, time.UTC)
+	if sara.LastAutoContact == nil || !sara.LastAutoContact.Equal(reply) {
+		t.Fatalf("expected last_auto_contact at the reply, got %v", sara.LastAutoContact)
+	}
+
+	omar, err := GetContact(ctx, omarID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(omar.Logs) != 1 || omar.Logs[0].LogType != LogEmailSent {
+		t.Fatalf("expected only the sent email for the Cc'd contact, got %+v", omar.Logs)
+	}
+
+	shop, err := GetContact(ctx, shopID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(shop.Logs) != 0 || shop.LastAutoContact != nil {
+		t.Fatalf("expected the ignored address to be skipped, got %+v", shop.Logs)
+	}
+
+	folders, err := ListEmailFolderStates(ctx)
+	if err != nil {
+		t.Fatalf("ListEmailFolderStates failed: %v", err)
+	}
+
+	if len(folders) != 2 || folders[0].Folder != "INBOX" || folders[0].LastUID != 8 || folders[1].Folder != "Sent" || folders[1].Interactions != 2 {
+		t.Fatalf("unexpected folder states %+v", folders)
+	}
+
+	// A second poll only reads new messages
+	appendTestEmail(t, user, "INBOX", "From: omar@example.com\r\nTo: me@example.net\r\n"+
+		"Subject: \r\nDate: Wed, 05 Feb 2025 08:00:00 +0000\r\n"+
+		"Message-ID: <ping@example.com>\r\n")
+
+	result, err = PollEmail(ctx)
+	if err != nil {
+		t.Fatalf("PollEmail failed: %v", err)
+	}
+
+	if result.Messages != 1 || result.Interactions != 1 {
+		t.Fatalf("unexpected second poll result %+v", result)
+	}
+
+	omar, err = GetContact(ctx, omarID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	var subjects []string
+	for _, log := range omar.Logs {
+		subjects = append(subjects, *log.Subject)
+	}
+
+	if !strings.Contains(strings.Join(subjects, "|"), "(no subject)") {
+		t.Fatalf("expected a placeholder subject, got %v", subjects)
+	}
+}
+
+func TestRecordEmailEnvelopeSkipsDuplicates(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	email := "lena@example.org"
+	lenaID := mustCreateContact(t, CreateContactInput{NameGiven: "Lena", Email: &email, Tier: TierB})
+
+	contacts, ownAddresses, err := loadEmailContactIndex(ctx)
+	if err != nil {
+		t.Fatalf("loadEmailContactIndex failed: %v", err)
+	}
+
+	envelope := emailEnvelope{
+		MessageID: "<hello@example.org>",
+		Subject:   "Hello",
+		Date:      time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		From:      []string{email},
+	}
+
+	recorded, duplicates, err := recordEmailEnvelope(ctx, envelope, false, ownAddresses, contacts, nil)
+	if err != nil || recorded != 1 || duplicates != 0 {
+		t.Fatalf("unexpected first record %d %d %v", recorded, duplicates, err)
+	}
+
+	// The same message seen in another folder is not logged twice
+	recorded, duplicates, err = recordEmailEnvelope(ctx, envelope, false, ownAddresses, contacts, nil)
+	if err != nil || recorded != 0 || duplicates != 1 {
+		t.Fatalf("unexpected second record %d %d %v", recorded, duplicates, err)
+	}
+
+	lena, err := GetContact(ctx, lenaID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if len(lena.Logs) != 1 || lena.Logs[0].LogType != LogEmailReceived {
+		t.Fatalf("expected one received email, got %+v", lena.Logs)
+	}
+}
+
+func TestEmailIgnoreRules(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	if err := AddEmailIgnoreRule(ctx, EmailIgnoreAddress, " @Lists.Example.org "); err != nil {
+		t.Fatalf("AddEmailIgnoreRule failed: %v", err)
+	}
+
+	if err := AddEmailIgnoreRule(ctx, EmailIgnoreAddress, "@lists.example.org"); !errors.Is(err, ErrEmailIgnoreRuleExists) {
+		t.Fatalf("expected ErrEmailIgnoreRuleExists, got %v", err)
+	}
+
+	for _, invalid := range []struct {
+		ruleType EmailIgnoreRuleType
+		pattern  string
+	}{
+		{EmailIgnoreAddress, "not an address"},
+		{EmailIgnoreAddress, "@"},
+		{EmailIgnoreFolder, "  "},
+		{"sender", "x@example.org"},
+	} {
+		if err := AddEmailIgnoreRule(ctx, invalid.ruleType, invalid.pattern); !errors.Is(err, ErrEmailIgnoreRuleInvalid) {
+			t.Fatalf("expected ErrEmailIgnoreRuleInvalid for %+v, got %v", invalid, err)
+		}
+	}
+
+	rules, err := ListEmailIgnoreRules(ctx)
+	if err != nil {
+		t.Fatalf("ListEmailIgnoreRules failed: %v", err)
+	}
+
+	if len(rules) != 1 || rules[0].Pattern != "@lists.example.org" {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+
+	if err := DeleteEmailIgnoreRule(ctx, rules[0].ID.String()); err != nil {
+		t.Fatalf("DeleteEmailIgnoreRule failed: %v", err)
+	}
+
+	if err := DeleteEmailIgnoreRule(ctx, rules[0].ID.String()); !errors.Is(err, ErrEmailIgnoreRuleNotFound) {
+		t.Fatalf("expected ErrEmailIgnoreRuleNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+)
+
+// countingIMAPBackend counts the logins a server receives
+type countingIMAPBackend struct {
+	backend.Backend
+
+	logins atomic.Int32
+}
+
+func (b *countingIMAPBackend) Login(connInfo *imap.ConnInfo, username, password string) (backend.User, error) {
+	b.logins.Add(1)
+
+	return b.Backend.Login(connInfo, username, password)
+}
+
+// startTestIMAPServer serves an in-memory mailbox on a local port without
+// STARTTLS. Its INBOX starts with one message from contact@example.org with
+// UID 6.
+func startTestIMAPServer(t *testing.T) (*EmailPollConfig, backend.User) {
+	t.Helper()
+
+	config, user, _ := startCountingTestIMAPServer(t)
+
+	return config, user
+}
+
+func startCountingTestIMAPServer(t *testing.T) (*EmailPollConfig, backend.User, *countingIMAPBackend) {
+	t.Helper()
+
+	be := &countingIMAPBackend{Backend: memory.New()}
+
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatalf("failed to log in to memory backend: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	// Logins made while setting up the mailbox are not counted
+	be.logins.Store(0)
+
+	srv := server.New(be)
+	srv.AllowInsecureAuth = true
+
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+
+	config := &EmailPollConfig{
+		Addr:          listener.Addr().String(),
+		Username:      "username",
+		Password:      "password",
+		AllowInsecure: true,
+	}
+
+	return config, user, be
+}
+
+func appendTestEmail(t *testing.T, user backend.User, folder, header string) {
+	t.Helper()
+
+	mailbox, err := user.GetMailbox(folder)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", folder, err)
+	}
+
+	body := bytes.NewBufferString(header + "Content-Type: text/plain\r\n\r\nHello\r\n")
+	if err := mailbox.CreateMessage(nil, time.Now(), body); err != nil {
+		t.Fatalf("failed to append message: %v", err)
+	}
+}
+
+func TestFetchEmailEnvelopes(t *testing.T) {
+	t.Parallel()
+
+	config, user := startTestIMAPServer(t)
+
+	if err := user.CreateMailbox("Sent"); err != nil {
+		t.Fatalf("failed to create Sent: %v", err)
+	}
+
+	appendTestEmail(t, user, "INBOX", "From: Sara <Sara@Example.com>\r\n"+
+		"To: me@example.net\r\n"+
+		"Cc: Omar <omar@example.com>\r\n"+
+		"Subject: Lunch\r\n"+
+		"Date: Mon, 03 Feb 2025 12:00:00 +0000\r\n"+
+		"Message-ID: <lunch@example.com>\r\n")
+
+	c, err := dialEmailServer(config)
+	if err != nil {
+		t.Fatalf("dialEmailServer failed: %v", err)
+	}
+
+	defer func() {
+		_ = c.Logout()
+	}()
+
+	folders, err := listEmailFolders(c)
+	if err != nil {
+		t.Fatalf("listEmailFolders failed: %v", err)
+	}
+
+	var names []string
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+	}
+
+	slices.Sort(names)
+
+	if !slices.Equal(names, []string{"INBOX", "Sent"}) {
+		t.Fatalf("unexpected folders %v", names)
+	}
+
+	uidValidity, envelopes, err := fetchEmailEnvelopes(c, "INBOX", EmailFolderState{})
+	if err != nil {
+		t.Fatalf("fetchEmailEnvelopes failed: %v", err)
+	}
+
+	if uidValidity == 0 || len(envelopes) != 2 {
+		t.Fatalf("expected both messages with a UIDVALIDITY, got %d %+v", uidValidity, envelopes)
+	}
+
+	lunch := envelopes[1]
+	if lunch.UID != 7 || lunch.MessageID != "<lunch@example.com>" || lunch.Subject != "Lunch" {
+		t.Fatalf("unexpected envelope %+v", lunch)
+	}
+
+	if !lunch.Date.Equal(time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected date %v", lunch.Date)
+	}
+
+	if !slices.Equal(lunch.From, []string{"sara@example.com"}) || !slices.Equal(lunch.To, []string{"me@example.net"}) ||
+		!slices.Equal(lunch.Cc, []string{"omar@example.com"}) {
+		t.Fatalf("unexpected addresses %+v", lunch)
+	}
+
+	// Only messages after the cursor are read again
+	_, envelopes, err = fetchEmailEnvelopes(c, "INBOX", EmailFolderState{UIDValidity: uidValidity, LastUID: 7})
+	if err != nil {
+		t.Fatalf("fetchEmailEnvelopes failed: %v", err)
+	}
+
+	if len(envelopes) != 0 {
+		t.Fatalf("expected no new messages, got %+v", envelopes)
+	}
+
+	_, envelopes, err = fetchEmailEnvelopes(c, "INBOX", EmailFolderState{UIDValidity: uidValidity, LastUID: 6})
+	if err != nil {
+		t.Fatalf("fetchEmailEnvelopes failed: %v", err)
+	}
+
+	if len(envelopes) != 1 || envelopes[0].UID != 7 {
+		t.Fatalf("expected only the new message, got %+v", envelopes)
+	}
+
+	// A new UIDVALIDITY invalidates the cursor
+	_, envelopes, err = fetchEmailEnvelopes(c, "INBOX", EmailFolderState{UIDValidity: uidValidity + 1, LastUID: 7})
+	if err != nil {
+		t.Fatalf("fetchEmailEnvelopes failed: %v", err)
+	}
+
+	if len(envelopes) != 2 {
+		t.Fatalf("expected a full re-read after a UIDVALIDITY change, got %+v", envelopes)
+	}
+
+	_, envelopes, err = fetchEmailEnvelopes(c, "Sent", EmailFolderState{})
+	if err != nil {
+		t.Fatalf("fetchEmailEnvelopes failed: %v", err)
+	}
+
+	if len(envelopes) != 0 {
+		t.Fatalf("expected an empty Sent folder, got %+v", envelopes)
+	}
+}
+
+func TestDialEmailServerRefusesPlaintextLogin(t *testing.T) {
+	t.Parallel()
+
+	config, _, be := startCountingTestIMAPServer(t)
+	config.AllowInsecure = false
+
+	c, err := dialEmailServer(config)
+	if !errors.Is(err, ErrEmailPollInsecure) {
+		if c != nil {
+			_ = c.Logout()
+		}
+
+		t.Fatalf("expected ErrEmailPollInsecure, got %v", err)
+	}
+
+	if logins := be.logins.Load(); logins != 0 {
+		t.Fatalf("expected no login without STARTTLS, got %d", logins)
+	}
+}
+
+func TestEmailFolderIgnored(t *testing.T) {
+	t.Parallel()
+
+	rules := []EmailIgnoreRule{
+		{RuleType: EmailIgnoreFolder, Pattern: "Spam"},
+		{RuleType: EmailIgnoreFolder, Pattern: "Lists/Newsletters"},
+		{RuleType: EmailIgnoreAddress, Pattern: "INBOX"},
+	}
+
+	tests := []struct {
+		name    string
+		folder  *imap.MailboxInfo
+		ignored bool
+	}{
+		{"inbox", &imap.MailboxInfo{Name: "INBOX", Delimiter: "/"}, false},
+		{"full name", &imap.MailboxInfo{Name: "Lists/Newsletters", Delimiter: "/"}, true},
+		{"last segment", &imap.MailboxInfo{Name: "[Gmail]/spam", Delimiter: "/"}, true},
+		{"other segment", &imap.MailboxInfo{Name: "Spam/Reviewed", Delimiter: "/"}, false},
+		{"special use", &imap.MailboxInfo{Name: "Bin", Attributes: []string{imap.TrashAttr}}, true},
+		{"sent", &imap.MailboxInfo{Name: "Sent", Attributes: []string{imap.SentAttr}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := emailFolderIgnored(rules, tt.folder); got != tt.ignored {
+				t.Fatalf("emailFolderIgnored(%q) = %v, want %v", tt.folder.Name, got, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestEmailAddressIgnored(t *testing.T) {
+	t.Parallel()
+
+	rules := []EmailIgnoreRule{
+		{RuleType: EmailIgnoreAddress, Pattern: "Boss@Work.example"},
+		{RuleType: EmailIgnoreAddress, Pattern: "@lists.example.org"},
+		{RuleType: EmailIgnoreAddress, Pattern: "noreply@"},
+		{RuleType: EmailIgnoreFolder, Pattern: "friend@example.com"},
+	}
+
+	tests := []struct {
+		address string
+		ignored bool
+	}{
+		{"boss@work.example", true},
+		{"colleague@work.example", false},
+		{"digest@lists.example.org", true},
+		{"digest@eu.lists.example.org", true},
+		{"digest@otherlists.example.org", false},
+		{"noreply@shop.example", true},
+		{"friend@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			t.Parallel()
+
+			if got := emailAddressIgnored(rules, tt.address); got != tt.ignored {
+				t.Fatalf("emailAddressIgnored(%q) = %v, want %v", tt.address, got, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestEmailCounterparts(t *testing.T) {
+	t.Parallel()
+
+	own := map[string]bool{"me@example.net": true}
+
+	received := emailEnvelope{From: []string{"sara@example.com"}, To: []string{"me@example.net"}, Cc: []string{"omar@example.com"}}
+
+	counterparts, outgoing := emailCounterparts(received, false, own)
+	if outgoing || !slices.Equal(counterparts, []string{"sara@example.com"}) {
+		t.Fatalf("unexpected received counterparts %v %v", counterparts, outgoing)
+	}
+
+	sent := emailEnvelope{
+		From: []string{"me@example.net"},
+		To:   []string{"sara@example.com", "me@example.net"},
+		Cc:   []string{"omar@example.com", "sara@example.com"},
+	}
+
+	counterparts, outgoing = emailCounterparts(sent, false, own)
+	if !outgoing || !slices.Equal(counterparts, []string{"sara@example.com", "omar@example.com"}) {
+		t.Fatalf("unexpected sent counterparts %v %v", counterparts, outgoing)
+	}
+
+	// Messages in the Sent folder are outgoing even from an unknown alias
+	alias := emailEnvelope{From: []string{"alias@example.net"}, To: []string{"sara@example.com"}}
+
+	counterparts, outgoing = emailCounterparts(alias, true, own)
+	if !outgoing || !slices.Equal(counterparts, []string{"sara@example.com"}) {
+		t.Fatalf("unexpected Sent folder counterparts %v %v", counterparts, outgoing)
+	}
+}
@@ -16,12 +16,21 @@ var (
 	ErrInvalidUserHandle   = errors.New("invalid user handle")
 	ErrPasskeyNotFound     = errors.New("passkey not found")
 
-	ErrCardDAVConfigIncomplete       = errors.New("CardDAV configuration incomplete: CARDDAV_URL, CARDDAV_USERNAME, and CARDDAV_PASSWORD must all be set")
 	ErrContactNotLinkedToCardDAV     = errors.New("contact is not linked to CardDAV")
 	ErrContactAlreadyLinkedToCardDAV = errors.New("contact is already linked to CardDAV")
 	ErrCardDAVContactByUUIDNotFound  = errors.New("contact with UUID not found")
 	ErrCardDAVContactByUIDNotFound   = errors.New("contact with UID not found")
 	ErrFetchVCFFileFailed            = errors.New("failed to fetch VCF file")
+	ErrCardDAVSyncInProgress         = errors.New("CardDAV sync already in progress")
+	ErrCardDAVAddressBookEmpty       = errors.New("CardDAV address book listing is empty")
+	ErrCardDAVConflict               = errors.New("CardDAV card changed on the server and locally")
+	ErrCardDAVConflictNotFound       = errors.New("CardDAV conflict not found")
+	ErrCardDAVConflictChanged        = errors.New("CardDAV card changed again since the conflict was recorded")
+	ErrCardDAVNoSources              = errors.New("no CardDAV source configured")
+	ErrCardDAVSourceNotFound         = errors.New("CardDAV source not found")
+	ErrCardDAVSourceNameRequired     = errors.New("CardDAV source name is required")
+	ErrCardDAVSourceURLRequired      = errors.New("CardDAV source URL is required")
+	ErrCardDAVSourceNameTaken        = errors.New("a CardDAV source with that name already exists")
 
 	ErrLogNotFound         = errors.New("log not found")
 	ErrChatMessageRequired = errors.New("chat message is required")
@@ -29,6 +38,43 @@ var (
 	ErrNoteContentEmpty    = errors.New("note content cannot be empty")
 	ErrNoteNotFound        = errors.New("note not found")
 	ErrContactNotFound     = errors.New("contact not found")
+	ErrContactIDRequired   = errors.New("contact id is required")
+
+	ErrContactPhotoEmpty    = errors.New("contact photo is empty")
+	ErrContactPhotoNotFound = errors.New("contact has no uploaded photo")
+
+	ErrCadenceDaysInvalid = errors.New("cadence interval must be a positive number of days")
+	ErrTierInvalid        = errors.New("tier is invalid")
+	ErrTagNotFound        = errors.New("tag not found")
+
+	ErrAddressEmpty              = errors.New("address requires at least one field")
+	ErrAddressNotFound           = errors.New("address not found")
+	ErrAddressCoordinatesInvalid = errors.New("address coordinates are invalid")
+
+	ErrMergeSameContact = errors.New("cannot merge a contact into itself")
+	ErrMergeMeContact   = errors.New("cannot merge away the me contact")
+
+	ErrRelationshipTypeInvalid = errors.New("relationship type is invalid")
+	ErrRelationshipSelf        = errors.New("a contact cannot be related to itself")
+	ErrRelationshipExists      = errors.New("relationship already exists")
+	ErrRelationshipNotFound    = errors.New("relationship not found")
+
+	ErrSavedSearchNameRequired  = errors.New("saved search name is required")
+	ErrSavedSearchQueryRequired = errors.New("saved search query is required")
+	ErrSavedSearchNotFound      = errors.New("saved search not found")
+
+	ErrWhatsAppNumberNotFound = errors.New("WhatsApp number not found in the inbox")
+
+	ErrChatArchivePlatformUnsupported = errors.New("chat archives can only be imported from Signal, Telegram or Matrix")
+	ErrChatArchiveExporterMissing     = errors.New("Matrix export does not name the exporting user")
+
+	ErrEmailPollNotConfigured  = errors.New("email polling is not configured")
+	ErrEmailPollURLInvalid     = errors.New("IMAP_URL must look like imaps://host[:port] or imap://host[:port]")
+	ErrEmailPollInsecure       = errors.New("IMAP server does not offer STARTTLS; set IMAP_ALLOW_INSECURE=true to log in over plaintext")
+	ErrEmailPollInProgress     = errors.New("email poll already in progress")
+	ErrEmailIgnoreRuleInvalid  = errors.New("ignore rule must be a folder name, or an address, @domain or local part@")
+	ErrEmailIgnoreRuleExists   = errors.New("ignore rule already exists")
+	ErrEmailIgnoreRuleNotFound = errors.New("ignore rule not found")
 
 	ErrContactExchangeLinkInvalid       = errors.New("contact exchange link is invalid")
 	ErrContactExchangeCollectFieldEmpty = errors.New("contact exchange requires at least one field")
@@ -58,6 +104,9 @@ var (
 	ErrOllamaConfigIncomplete      = errors.New("ollama configuration incomplete: OLLAMA_URL and OLLAMA_MODEL must be set")
 	ErrOllamaReturnedStatus        = errors.New("ollama returned status")
 	ErrOllamaError                 = errors.New("ollama error")
+	ErrOllamaEmbedConfigIncomplete = errors.New("ollama embeddings configuration incomplete: OLLAMA_URL and OLLAMA_EMBED_MODEL must be set")
+	ErrOllamaEmbeddingCount        = errors.New("ollama returned an unexpected number of embeddings")
+	ErrOllamaEmbeddingDecode       = errors.New("failed to decode embeddings")
 	ErrNoQRZAPIKeysProvided        = errors.New("no QRZ API keys provided")
 	ErrQRZUserAgentNotConfigured   = errors.New("QRZ_USERAGENT not configured")
 	ErrSyncAllQRZLogbooksFailed    = errors.New("failed to sync all QRZ logbooks")
@@ -121,6 +170,18 @@ var (
 	ErrFetchContactPageFileFailed        = errors.New("failed to fetch contact page file")
 	ErrFetchFileFailed                   = errors.New("failed to fetch file")
 	ErrZKNoteNotFound                    = errors.New("note with ID not found")
+	ErrZKNoteConflict                    = errors.New("note changed since it was loaded")
+	ErrZKNoteExists                      = errors.New("note file already exists")
+	ErrZKNoteETagRequired                = errors.New("note etag is required")
+	ErrZKNoteIDChanged                   = errors.New("note :ID: property cannot be changed")
+	ErrZKNoteTitleRequired               = errors.New("note title is required")
+	ErrZKNoteSaveHTTPStatus              = errors.New("failed to save note")
+	ErrTodoConflict                      = errors.New("todo file changed since it was loaded")
+	ErrTodoETagRequired                  = errors.New("todo file etag is required")
+	ErrTodoTaskNotFound                  = errors.New("todo headline not found")
+	ErrTodoKeywordUnknown                = errors.New("todo keyword is not in the file's TODO sequence")
+	ErrTodoTitleRequired                 = errors.New("todo title is required")
+	ErrTodoSaveHTTPStatus                = errors.New("failed to save todo file")
 
 	ErrInviteNotFound = errors.New("invite not found")
 )
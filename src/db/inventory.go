@@ -40,6 +40,46 @@ func ListInventoryItems(ctx context.Context, status ...InventoryStatus) ([]Inven
 	return ListInventoryItemsWithFilters(ctx, opts)
 }
 
+// inventorySearchTermClause compiles a search term for the inventory list
+func inventorySearchTermClause(term searchTerm, argNum int) (string, []any, error) {
+	switch term.key {
+	case "":
+		return fmt.Sprintf(`(
+			i.inventory_id ILIKE $%d OR
+			i.name ILIKE $%d OR
+			COALESCE(i.location, '') ILIKE $%d OR
+			COALESCE(i.description, '') ILIKE $%d OR
+			COALESCE(i.item_type, '') ILIKE $%d OR
+			EXISTS (
+				SELECT 1
+				FROM inventory_item_tags iit
+				INNER JOIN inventory_tags t ON t.id = iit.tag_id
+				WHERE iit.item_id = i.id AND t.name ILIKE $%d
+			)
+		)`, argNum, argNum, argNum, argNum, argNum, argNum), []any{"%" + term.value + "%"}, nil
+	case "tag":
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1
+			FROM inventory_item_tags iit
+			INNER JOIN inventory_tags t ON t.id = iit.tag_id
+			WHERE iit.item_id = i.id AND lower(t.name) = $%d
+		)`, argNum), []any{term.value}, nil
+	case "category":
+		normalizedCategory, err := normalizeInventoryTypeValue(term.value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if normalizedCategory == "" {
+			return "", nil, nil
+		}
+
+		return fmt.Sprintf("i.item_type = $%d", argNum), []any{normalizedCategory}, nil
+	}
+
+	return "", nil, nil
+}
+
 // ListInventoryItemsWithFilters returns inventory items matching provided filters.
 func ListInventoryItemsWithFilters(ctx context.Context, opts InventoryListOptions) ([]InventoryItem, error) {
 	if pool == nil {
@@ -87,52 +127,13 @@ func ListInventoryItemsWithFilters(ctx context.Context, opts InventoryListOption
 
 	parsed := parseSearchQuery(opts.SearchQuery)
 
-	for _, category := range parsed.categories {
-		normalizedCategory, err := normalizeInventoryTypeValue(category)
-		if err != nil {
-			return nil, err
-		}
-
-		if normalizedCategory == "" {
-			continue
-		}
-
-		whereClauses = append(whereClauses, fmt.Sprintf("i.item_type = $%d", argNum))
-		args = append(args, normalizedCategory)
-		argNum++
-	}
-
-	if len(parsed.tagNames) > 0 {
-		whereClauses = append(whereClauses, fmt.Sprintf(
-			`i.id IN (
-				SELECT iit.item_id
-				FROM inventory_item_tags iit
-				INNER JOIN inventory_tags t ON t.id = iit.tag_id
-				WHERE lower(t.name) = ANY($%d::text[])
-				GROUP BY iit.item_id
-				HAVING COUNT(DISTINCT lower(t.name)) = $%d
-			)`, argNum, argNum+1))
-		args = append(args, parsed.tagNames, len(parsed.tagNames))
-		argNum += 2
+	searchClauses, searchArgs, _, err := parsed.whereClauses(argNum, inventorySearchTermClause)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, term := range parsed.freeTerms {
-		whereClauses = append(whereClauses, fmt.Sprintf(`(
-			i.inventory_id ILIKE $%d OR
-			i.name ILIKE $%d OR
-			COALESCE(i.location, '') ILIKE $%d OR
-			COALESCE(i.description, '') ILIKE $%d OR
-			COALESCE(i.item_type, '') ILIKE $%d OR
-			EXISTS (
-				SELECT 1
-				FROM inventory_item_tags iit
-				INNER JOIN inventory_tags t ON t.id = iit.tag_id
-				WHERE iit.item_id = i.id AND t.name ILIKE $%d
-			)
-		)`, argNum, argNum, argNum, argNum, argNum, argNum))
-		args = append(args, "%"+term+"%")
-		argNum++
-	}
+	whereClauses = append(whereClauses, searchClauses...)
+	args = append(args, searchArgs...)
 
 	query := `
 		SELECT
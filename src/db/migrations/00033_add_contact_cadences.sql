@@ -0,0 +1,45 @@
+-- +goose Up
+-- Follow-up cadences: per-tier defaults, per-tag and per-contact overrides,
+-- and snooze-until dates for the overdue list
+
+CREATE TABLE IF NOT EXISTS tier_cadences (
+    tier          tier PRIMARY KEY,
+    interval_days INTEGER
+                  CONSTRAINT tier_cadences_interval_positive
+                  CHECK (interval_days IS NULL OR interval_days > 0),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Default intervals are seeded by SyncTierCadences after migrations run
+
+DROP TRIGGER IF EXISTS tier_cadences_updated_at ON tier_cadences;
+CREATE TRIGGER tier_cadences_updated_at
+    BEFORE UPDATE ON tier_cadences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+ALTER TABLE tags
+ADD COLUMN IF NOT EXISTS cadence_days INTEGER
+    CONSTRAINT tags_cadence_days_positive
+    CHECK (cadence_days IS NULL OR cadence_days > 0);
+
+ALTER TABLE contacts
+ADD COLUMN IF NOT EXISTS cadence_days INTEGER
+    CONSTRAINT contacts_cadence_days_positive
+    CHECK (cadence_days IS NULL OR cadence_days > 0);
+
+ALTER TABLE contacts
+ADD COLUMN IF NOT EXISTS cadence_snoozed_until DATE;
+
+-- +goose Down
+
+ALTER TABLE contacts
+DROP COLUMN IF EXISTS cadence_snoozed_until;
+
+ALTER TABLE contacts
+DROP COLUMN IF EXISTS cadence_days;
+
+ALTER TABLE tags
+DROP COLUMN IF EXISTS cadence_days;
+
+DROP TRIGGER IF EXISTS tier_cadences_updated_at ON tier_cadences;
+DROP TABLE IF EXISTS tier_cadences;
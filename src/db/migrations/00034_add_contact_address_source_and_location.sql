@@ -0,0 +1,29 @@
+-- +goose Up
+-- Migration: Track CardDAV source and map coordinates for contact addresses
+
+-- Add source column to contact_addresses, matching emails and phones
+ALTER TABLE contact_addresses ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'local';
+
+-- Optional coordinates used to render a map of the address
+ALTER TABLE contact_addresses ADD COLUMN IF NOT EXISTS latitude DECIMAL(9,6);
+ALTER TABLE contact_addresses ADD COLUMN IF NOT EXISTS longitude DECIMAL(9,6);
+
+ALTER TABLE contact_addresses ADD CONSTRAINT contact_address_latitude_range
+    CHECK (latitude IS NULL OR (latitude >= -90 AND latitude <= 90));
+ALTER TABLE contact_addresses ADD CONSTRAINT contact_address_longitude_range
+    CHECK (longitude IS NULL OR (longitude >= -180 AND longitude <= 180));
+ALTER TABLE contact_addresses ADD CONSTRAINT contact_address_coordinates_pair
+    CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_contact_addresses_source ON contact_addresses(source);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_contact_addresses_source;
+
+ALTER TABLE contact_addresses DROP CONSTRAINT IF EXISTS contact_address_coordinates_pair;
+ALTER TABLE contact_addresses DROP CONSTRAINT IF EXISTS contact_address_longitude_range;
+ALTER TABLE contact_addresses DROP CONSTRAINT IF EXISTS contact_address_latitude_range;
+
+ALTER TABLE contact_addresses DROP COLUMN IF EXISTS longitude;
+ALTER TABLE contact_addresses DROP COLUMN IF EXISTS latitude;
+ALTER TABLE contact_addresses DROP COLUMN IF EXISTS source;
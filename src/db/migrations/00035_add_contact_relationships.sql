@@ -0,0 +1,44 @@
+-- +goose Up
+-- Migration: Typed relationships between contacts and introducers on intro logs
+
+-- +goose StatementBegin
+DO $$ BEGIN
+    CREATE TYPE relationship_type AS ENUM (
+        'spouse', 'partner', 'parent', 'child', 'sibling', 'relative',
+        'friend', 'colleague', 'manager', 'report', 'mentor', 'mentee',
+        'introduced_by', 'introduced',
+        'other'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN null;
+END $$;
+-- +goose StatementEnd
+
+-- Who introduced the contact, recorded on intro logs
+ALTER TABLE contact_logs
+ADD COLUMN IF NOT EXISTS introduced_by_contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL;
+
+-- Each row reads as "related_contact_id is contact_id's relationship_type"
+CREATE TABLE IF NOT EXISTS contact_relationships (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    contact_id         UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    related_contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    relationship_type  relationship_type NOT NULL,
+    log_id             UUID REFERENCES contact_logs(id) ON DELETE CASCADE,  -- intro log that created it
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT contact_relationships_not_self CHECK (contact_id <> related_contact_id),
+    CONSTRAINT contact_relationships_unique UNIQUE (contact_id, related_contact_id, relationship_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_relationships_contact ON contact_relationships(contact_id);
+CREATE INDEX IF NOT EXISTS idx_contact_relationships_related ON contact_relationships(related_contact_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_contact_relationships_related;
+DROP INDEX IF EXISTS idx_contact_relationships_contact;
+DROP TABLE IF EXISTS contact_relationships;
+
+ALTER TABLE contact_logs DROP COLUMN IF EXISTS introduced_by_contact_id;
+
+DROP TYPE IF EXISTS relationship_type;
@@ -0,0 +1,103 @@
+-- +goose Up
+-- Migration: Full-text search across contacts, notes, chats, inventory,
+-- ledger, health and the Zettelkasten. The 'simple' configuration is used so
+-- names and non-English text match as written, without stemming.
+
+ALTER TABLE contacts
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name_display, '') || ' ' || coalesce(nickname, '') || ' ' || coalesce(call_sign, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(organization, '') || ' ' || coalesce(title, '') || ' ' || coalesce(role, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(notes, '')), 'C')
+) STORED;
+
+ALTER TABLE contact_notes
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(content, ''))
+) STORED;
+
+ALTER TABLE contact_logs
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(subject, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+) STORED;
+
+ALTER TABLE contact_chats
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(message, ''))
+) STORED;
+
+ALTER TABLE inventory_items
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(inventory_id, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(location, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+) STORED;
+
+ALTER TABLE inventory_comments
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(content, ''))
+) STORED;
+
+ALTER TABLE ledger_transactions
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(merchant, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(note, '')), 'C')
+) STORED;
+
+ALTER TABLE health_followups
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(hospital_name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(notes, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_search ON contacts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_contact_notes_search ON contact_notes USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_contact_logs_search ON contact_logs USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_contact_chats_search ON contact_chats USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_inventory_items_search ON inventory_items USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_inventory_comments_search ON inventory_comments USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_ledger_transactions_search ON ledger_transactions USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_health_followups_search ON health_followups USING GIN (search_vector);
+
+--------------------------------------------------------------------------------
+-- ZETTELKASTEN NOTE TEXT
+-- Notes live in WebDAV, so the cache rebuild copies their text here
+--------------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS zk_note_search (
+    note_id       TEXT PRIMARY KEY,                   -- org :ID:, or daily prefix + date
+    title         TEXT NOT NULL,
+    body          TEXT NOT NULL,
+    is_daily      BOOLEAN NOT NULL DEFAULT false,
+    is_public     BOOLEAN NOT NULL DEFAULT false,
+    is_home       BOOLEAN NOT NULL DEFAULT false,
+    indexed_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', body), 'C')
+    ) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_zk_note_search_search ON zk_note_search USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_zk_note_search_search;
+DROP TABLE IF EXISTS zk_note_search;
+
+DROP INDEX IF EXISTS idx_health_followups_search;
+DROP INDEX IF EXISTS idx_ledger_transactions_search;
+DROP INDEX IF EXISTS idx_inventory_comments_search;
+DROP INDEX IF EXISTS idx_inventory_items_search;
+DROP INDEX IF EXISTS idx_contact_chats_search;
+DROP INDEX IF EXISTS idx_contact_logs_search;
+DROP INDEX IF EXISTS idx_contact_notes_search;
+DROP INDEX IF EXISTS idx_contacts_search;
+
+ALTER TABLE health_followups DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE inventory_comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE inventory_items DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE contact_chats DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE contact_logs DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE contact_notes DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE contacts DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration: Named contact searches shown in the contacts sidebar
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        TEXT NOT NULL
+                CONSTRAINT saved_search_name_not_empty CHECK (length(trim(name)) > 0),
+    query       TEXT NOT NULL
+                CONSTRAINT saved_search_query_not_empty CHECK (length(trim(query)) > 0),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT saved_searches_name_unique UNIQUE (name)
+);
+
+DROP TRIGGER IF EXISTS saved_searches_updated_at ON saved_searches;
+CREATE TRIGGER saved_searches_updated_at
+    BEFORE UPDATE ON saved_searches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS saved_searches_updated_at ON saved_searches;
+DROP TABLE IF EXISTS saved_searches;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Migration: Uploaded contact photos are stored in WebDAV; photo_path is the
+-- location under the files root and photo_url points at the thumbnail route
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS photo_path TEXT;
+
+-- +goose Down
+ALTER TABLE contacts DROP COLUMN IF EXISTS photo_path;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- Migration: Incremental CardDAV sync state and per-run history
+
+-- Sync token and ctag last seen for the address book
+CREATE TABLE IF NOT EXISTS carddav_sync_state (
+    address_book_url TEXT PRIMARY KEY,
+    sync_token       TEXT,
+    ctag             TEXT,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS carddav_sync_state_updated_at ON carddav_sync_state;
+CREATE TRIGGER carddav_sync_state_updated_at
+    BEFORE UPDATE ON carddav_sync_state
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- Cards seen on the server, so unchanged ETags are not fetched again
+CREATE TABLE IF NOT EXISTS carddav_cards (
+    uid        TEXT PRIMARY KEY,
+    path       TEXT NOT NULL,
+    etag       TEXT,
+    synced_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_carddav_cards_path ON carddav_cards(path);
+
+-- One row per sync run
+CREATE TABLE IF NOT EXISTS carddav_sync_runs (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger           TEXT NOT NULL
+                      CONSTRAINT carddav_sync_run_trigger_valid CHECK (trigger IN ('scheduled', 'manual')),
+    mode              TEXT NOT NULL
+                      CONSTRAINT carddav_sync_run_mode_valid CHECK (mode IN ('full', 'incremental', 'unchanged')),
+    status            TEXT NOT NULL
+                      CONSTRAINT carddav_sync_run_status_valid CHECK (status IN ('success', 'partial', 'failed')),
+    cards_changed     INTEGER NOT NULL DEFAULT 0,
+    contacts_updated  INTEGER NOT NULL DEFAULT 0,
+    cards_deleted     INTEGER NOT NULL DEFAULT 0,
+    contacts_unlinked INTEGER NOT NULL DEFAULT 0,
+    errors            TEXT[] NOT NULL DEFAULT '{}',
+    started_at        TIMESTAMPTZ NOT NULL,
+    finished_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_carddav_sync_runs_started_at ON carddav_sync_runs(started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS carddav_sync_runs;
+DROP TABLE IF EXISTS carddav_cards;
+DROP TRIGGER IF EXISTS carddav_sync_state_updated_at ON carddav_sync_state;
+DROP TABLE IF EXISTS carddav_sync_state;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Migration: Conflicts between local contact edits and CardDAV card changes
+
+CREATE TABLE IF NOT EXISTS contact_carddav_conflicts (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    contact_id    UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    carddav_uuid  TEXT NOT NULL,
+    server_etag   TEXT,
+    fields        JSONB NOT NULL DEFAULT '[]',
+    detected_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at   TIMESTAMPTZ,
+    resolution    TEXT
+                  CONSTRAINT carddav_conflict_resolution_valid
+                  CHECK (resolution IN ('local', 'server', 'merged', 'agreed')),
+
+    CONSTRAINT carddav_conflict_resolution_set
+        CHECK ((resolved_at IS NULL) = (resolution IS NULL))
+);
+
+-- At most one open conflict per contact
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_carddav_conflicts_open
+    ON contact_carddav_conflicts(contact_id) WHERE resolved_at IS NULL;
+
+DROP TRIGGER IF EXISTS contact_carddav_conflicts_updated_at ON contact_carddav_conflicts;
+CREATE TRIGGER contact_carddav_conflicts_updated_at
+    BEFORE UPDATE ON contact_carddav_conflicts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+ALTER TABLE carddav_sync_runs
+    ADD COLUMN IF NOT EXISTS contacts_in_conflict INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE carddav_sync_runs DROP COLUMN IF EXISTS contacts_in_conflict;
+DROP TRIGGER IF EXISTS contact_carddav_conflicts_updated_at ON contact_carddav_conflicts;
+DROP TABLE IF EXISTS contact_carddav_conflicts;
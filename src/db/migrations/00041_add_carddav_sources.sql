@@ -0,0 +1,75 @@
+-- +goose Up
+-- Migration: Multiple named CardDAV sources, each with its own credentials
+
+CREATE TABLE IF NOT EXISTS carddav_sources (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        TEXT NOT NULL
+                CONSTRAINT carddav_source_name_not_empty CHECK (length(trim(name)) > 0),
+    url         TEXT NOT NULL
+                CONSTRAINT carddav_source_url_not_empty CHECK (length(trim(url)) > 0),
+    username    TEXT NOT NULL,
+    password    TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carddav_sources_name ON carddav_sources(lower(name));
+
+DROP TRIGGER IF EXISTS carddav_sources_updated_at ON carddav_sources;
+CREATE TRIGGER carddav_sources_updated_at
+    BEFORE UPDATE ON carddav_sources
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- A CardDAV link is the card UID within a source. Existing links have no
+-- source until the first one is added.
+ALTER TABLE contacts
+    ADD COLUMN IF NOT EXISTS carddav_source_id UUID REFERENCES carddav_sources(id);
+
+DROP INDEX IF EXISTS idx_contacts_carddav_uuid;
+CREATE INDEX IF NOT EXISTS idx_contacts_carddav_link
+    ON contacts(carddav_source_id, lower(carddav_uuid)) WHERE carddav_uuid IS NOT NULL;
+
+-- Sync state is per source. The old state belonged to the environment
+-- configured address book, so it is dropped and the next run starts over.
+DELETE FROM carddav_sync_state;
+ALTER TABLE carddav_sync_state DROP CONSTRAINT IF EXISTS carddav_sync_state_pkey;
+ALTER TABLE carddav_sync_state
+    ADD COLUMN source_id UUID NOT NULL REFERENCES carddav_sources(id) ON DELETE CASCADE;
+ALTER TABLE carddav_sync_state ADD PRIMARY KEY (source_id);
+
+DELETE FROM carddav_cards;
+ALTER TABLE carddav_cards DROP CONSTRAINT IF EXISTS carddav_cards_pkey;
+ALTER TABLE carddav_cards
+    ADD COLUMN source_id UUID NOT NULL REFERENCES carddav_sources(id) ON DELETE CASCADE;
+ALTER TABLE carddav_cards ADD PRIMARY KEY (source_id, uid);
+
+DROP INDEX IF EXISTS idx_carddav_cards_path;
+CREATE INDEX IF NOT EXISTS idx_carddav_cards_path ON carddav_cards(source_id, path);
+
+-- Runs keep the source name so history survives removing a source
+ALTER TABLE carddav_sync_runs
+    ADD COLUMN IF NOT EXISTS source_id UUID REFERENCES carddav_sources(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS source_name TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE carddav_sync_runs DROP COLUMN IF EXISTS source_name;
+ALTER TABLE carddav_sync_runs DROP COLUMN IF EXISTS source_id;
+
+DELETE FROM carddav_cards;
+DROP INDEX IF EXISTS idx_carddav_cards_path;
+ALTER TABLE carddav_cards DROP CONSTRAINT IF EXISTS carddav_cards_pkey;
+ALTER TABLE carddav_cards DROP COLUMN IF EXISTS source_id;
+ALTER TABLE carddav_cards ADD PRIMARY KEY (uid);
+CREATE INDEX IF NOT EXISTS idx_carddav_cards_path ON carddav_cards(path);
+
+DELETE FROM carddav_sync_state;
+ALTER TABLE carddav_sync_state DROP CONSTRAINT IF EXISTS carddav_sync_state_pkey;
+ALTER TABLE carddav_sync_state DROP COLUMN IF EXISTS source_id;
+ALTER TABLE carddav_sync_state ADD PRIMARY KEY (address_book_url);
+
+DROP INDEX IF EXISTS idx_contacts_carddav_link;
+CREATE INDEX IF NOT EXISTS idx_contacts_carddav_uuid ON contacts(carddav_uuid) WHERE carddav_uuid IS NOT NULL;
+ALTER TABLE contacts DROP COLUMN IF EXISTS carddav_source_id;
+
+DROP TRIGGER IF EXISTS carddav_sources_updated_at ON carddav_sources;
+DROP TABLE IF EXISTS carddav_sources;
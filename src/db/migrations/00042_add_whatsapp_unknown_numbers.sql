@@ -0,0 +1,32 @@
+-- +goose Up
+-- Migration: Inbox of WhatsApp numbers that matched no contact
+
+CREATE TABLE IF NOT EXISTS whatsapp_unknown_numbers (
+    phone          TEXT PRIMARY KEY
+                   CONSTRAINT whatsapp_unknown_phone_digits CHECK (phone ~ '^[0-9]+$'),
+    message_count  INTEGER NOT NULL DEFAULT 0,
+    first_seen_at  TIMESTAMPTZ NOT NULL,
+    last_seen_at   TIMESTAMPTZ NOT NULL,
+    -- Ignored numbers stay so later messages keep being dropped
+    ignored_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_whatsapp_unknown_numbers_last_seen
+    ON whatsapp_unknown_numbers(last_seen_at DESC) WHERE ignored_at IS NULL;
+
+-- Messages held until the number is matched to a contact
+CREATE TABLE IF NOT EXISTS whatsapp_unknown_messages (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    phone       TEXT NOT NULL REFERENCES whatsapp_unknown_numbers(phone) ON DELETE CASCADE,
+    sender      chat_sender NOT NULL,
+    message     TEXT NOT NULL,
+    sent_at     TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_whatsapp_unknown_messages_phone
+    ON whatsapp_unknown_messages(phone, sent_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS whatsapp_unknown_messages;
+DROP TABLE IF EXISTS whatsapp_unknown_numbers;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Migration: Platform message IDs so imported chat history is not duplicated
+
+ALTER TABLE contact_chats ADD COLUMN IF NOT EXISTS message_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_chats_message_id
+    ON contact_chats(platform, message_id) WHERE message_id IS NOT NULL;
+
+ALTER TABLE whatsapp_unknown_messages ADD COLUMN IF NOT EXISTS message_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_whatsapp_unknown_messages_message_id
+    ON whatsapp_unknown_messages(message_id) WHERE message_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_whatsapp_unknown_messages_message_id;
+ALTER TABLE whatsapp_unknown_messages DROP COLUMN IF EXISTS message_id;
+DROP INDEX IF EXISTS idx_contact_chats_message_id;
+ALTER TABLE contact_chats DROP COLUMN IF EXISTS message_id;
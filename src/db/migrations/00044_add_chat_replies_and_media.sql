@@ -0,0 +1,26 @@
+-- +goose Up
+-- Migration: Reply references, media descriptors, edits and revokes on chats
+
+ALTER TABLE contact_chats
+    ADD COLUMN IF NOT EXISTS reply_to_message_id TEXT,
+    ADD COLUMN IF NOT EXISTS media_type TEXT
+        CONSTRAINT contact_chats_media_type_valid
+        CHECK (media_type IN ('image', 'video', 'audio', 'voice', 'document', 'sticker')),
+    ADD COLUMN IF NOT EXISTS media_mime_type TEXT,
+    ADD COLUMN IF NOT EXISTS media_file_name TEXT,
+    ADD COLUMN IF NOT EXISTS media_size BIGINT,
+    -- Path of the downloaded file under the WebDAV files root
+    ADD COLUMN IF NOT EXISTS media_path TEXT,
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE contact_chats
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS edited_at,
+    DROP COLUMN IF EXISTS media_path,
+    DROP COLUMN IF EXISTS media_size,
+    DROP COLUMN IF EXISTS media_file_name,
+    DROP COLUMN IF EXISTS media_mime_type,
+    DROP COLUMN IF EXISTS media_type,
+    DROP COLUMN IF EXISTS reply_to_message_id;
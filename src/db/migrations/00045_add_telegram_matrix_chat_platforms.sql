@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE chat_platform ADD VALUE IF NOT EXISTS 'telegram';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TYPE chat_platform ADD VALUE IF NOT EXISTS 'matrix';
+-- +goose StatementEnd
+
+-- +goose Down
+-- Note: PostgreSQL doesn't support removing enum values directly
+-- The values will remain but won't be used
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Migration: Email interactions polled from IMAP
+
+-- Message-ID of the email a log was recorded from, so the same message seen
+-- in several folders is only logged once per contact
+ALTER TABLE contact_logs
+    ADD COLUMN IF NOT EXISTS email_message_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_logs_email_message
+    ON contact_logs(contact_id, email_message_id)
+    WHERE email_message_id IS NOT NULL;
+
+-- How far each mailbox folder has been read
+CREATE TABLE IF NOT EXISTS email_poll_folders (
+    folder          TEXT PRIMARY KEY,
+    uid_validity    BIGINT NOT NULL,
+    last_uid        BIGINT NOT NULL DEFAULT 0,
+    interactions    INTEGER NOT NULL DEFAULT 0,
+    polled_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Folders and addresses left out of polling
+CREATE TABLE IF NOT EXISTS email_ignore_rules (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_type       TEXT NOT NULL
+                    CONSTRAINT email_ignore_rules_type_valid CHECK (rule_type IN ('folder', 'address')),
+    pattern         TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_ignore_rules_pattern
+    ON email_ignore_rules(rule_type, LOWER(pattern));
+
+INSERT INTO email_ignore_rules (rule_type, pattern)
+VALUES ('folder', 'Junk'), ('folder', 'Spam'), ('folder', 'Trash'), ('folder', 'Drafts')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS email_ignore_rules;
+DROP TABLE IF EXISTS email_poll_folders;
+DROP INDEX IF EXISTS idx_contact_logs_email_message;
+ALTER TABLE contact_logs DROP COLUMN IF EXISTS email_message_id;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration: Embeddings of Zettelkasten notes for related notes and chat retrieval
+
+-- Notes live in WebDAV, so each note is split into chunks whose text and
+-- embedding are kept here. content_hash is the hash of the whole note, so
+-- the cache rebuild only embeds notes whose text changed.
+CREATE TABLE IF NOT EXISTS zk_note_embeddings (
+    note_id         TEXT NOT NULL,
+    chunk_index     INTEGER NOT NULL CHECK (chunk_index >= 0),
+    title           TEXT NOT NULL,
+    content         TEXT NOT NULL,
+    content_hash    TEXT NOT NULL,
+    model           TEXT NOT NULL,
+    embedding       REAL[] NOT NULL,
+    embedded_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (note_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_zk_note_embeddings_model ON zk_note_embeddings(model);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_zk_note_embeddings_model;
+DROP TABLE IF EXISTS zk_note_embeddings;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Migration: Persisted index of Zettelkasten files in WebDAV
+
+-- One row per org file, so a cache rebuild only fetches files whose ETag
+-- (or, without one, modification time and size) changed, and the caches
+-- can be loaded back after a restart without fetching anything
+CREATE TABLE IF NOT EXISTS zk_file_index (
+    kind            TEXT NOT NULL
+                    CONSTRAINT zk_file_index_kind_valid CHECK (kind IN ('note', 'daily')),
+    filename        TEXT NOT NULL,
+    etag            TEXT NOT NULL DEFAULT '',
+    last_modified   TIMESTAMPTZ,
+    size            BIGINT NOT NULL DEFAULT 0,
+    note_id         TEXT NOT NULL DEFAULT '',      -- :ID: of a note, or daily:<date>
+    title           TEXT NOT NULL DEFAULT '',
+    links           TEXT[] NOT NULL DEFAULT '{}',  -- IDs of linked notes
+    contact_links   TEXT[] NOT NULL DEFAULT '{}',  -- IDs of linked contacts
+    is_public       BOOLEAN NOT NULL DEFAULT false,
+    is_home         BOOLEAN NOT NULL DEFAULT false,
+    note_date       DATE,                          -- journal day, or #+DATE of a note
+    content         TEXT NOT NULL DEFAULT '',
+    indexed_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    checked_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (kind, filename)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS zk_file_index;
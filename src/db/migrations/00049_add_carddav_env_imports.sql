@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration: Remember address books carried over from the environment
+
+-- CARDDAV_URL is only imported once, so a source removed or edited in the
+-- web UI does not come back on the next start
+CREATE TABLE IF NOT EXISTS carddav_env_imports (
+    url          TEXT PRIMARY KEY,
+    imported_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS carddav_env_imports;
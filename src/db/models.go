@@ -83,11 +83,13 @@ type Contact struct {
 	GeoLon          *float64   `db:"geo_lon"`
 	Language        *string    `db:"language"`
 	PhotoURL        *string    `db:"photo_url"`
+	PhotoPath       *string    `db:"photo_path"` // Uploaded photo in WebDAV, relative to the files root
 	Tier            Tier       `db:"tier"`
 	CallSign        *string    `db:"call_sign"`
 	IsService       bool       `db:"is_service"`
 	IsMe            bool       `db:"is_me"`
 	CardDAVUUID     *string    `db:"carddav_uuid"`
+	CardDAVSourceID *string    `db:"carddav_source_id"`
 	CreatedAt       time.Time  `db:"created_at"`
 	UpdatedAt       time.Time  `db:"updated_at"`
 	LastAutoContact *time.Time `db:"last_auto_contact"` // Auto-updated by WhatsApp message tracking
@@ -161,6 +163,9 @@ type ContactAddress struct {
 	IsPrimary   bool        `db:"is_primary"`
 	POBox       *string     `db:"po_box"`
 	Extended    *string     `db:"extended"`
+	Source      string      `db:"source"`
+	Latitude    *float64    `db:"latitude"`
+	Longitude   *float64    `db:"longitude"`
 	CreatedAt   time.Time   `db:"created_at"`
 }
 
@@ -245,6 +250,8 @@ const (
 	ChatPlatformEmail    ChatPlatform = "email"
 	ChatPlatformWhatsApp ChatPlatform = "whatsapp"
 	ChatPlatformSignal   ChatPlatform = "signal"
+	ChatPlatformTelegram ChatPlatform = "telegram"
+	ChatPlatformMatrix   ChatPlatform = "matrix"
 	ChatPlatformWeChat   ChatPlatform = "wechat"
 	ChatPlatformTeams    ChatPlatform = "teams"
 	ChatPlatformSlack    ChatPlatform = "slack"
@@ -261,15 +268,46 @@ const (
 	ChatSenderMix  ChatSender = "mix"
 )
 
+// ChatMediaType represents the kind of attachment on a chat message
+type ChatMediaType string
+
+// ChatMediaType values represent supported chat attachments.
+const (
+	ChatMediaImage    ChatMediaType = "image"
+	ChatMediaVideo    ChatMediaType = "video"
+	ChatMediaAudio    ChatMediaType = "audio"
+	ChatMediaVoice    ChatMediaType = "voice"
+	ChatMediaDocument ChatMediaType = "document"
+	ChatMediaSticker  ChatMediaType = "sticker"
+)
+
+// ChatMedia describes an attachment on a chat message. The caption is the
+// chat message itself.
+type ChatMedia struct {
+	Type     ChatMediaType `db:"media_type"`
+	MimeType string        `db:"media_mime_type"`
+	FileName string        `db:"media_file_name"`
+	Size     int64         `db:"media_size"`
+	// Path is the downloaded file under the WebDAV files root, if any
+	Path string `db:"media_path"`
+}
+
 // ContactChat represents a chat message with a contact
 type ContactChat struct {
-	ID        uuid.UUID    `db:"id"`
-	ContactID uuid.UUID    `db:"contact_id"`
-	Platform  ChatPlatform `db:"platform"`
-	Sender    ChatSender   `db:"sender"`
-	Message   string       `db:"message"`
-	SentAt    time.Time    `db:"sent_at"`
-	CreatedAt time.Time    `db:"created_at"`
+	ID               uuid.UUID    `db:"id"`
+	ContactID        uuid.UUID    `db:"contact_id"`
+	Platform         ChatPlatform `db:"platform"`
+	Sender           ChatSender   `db:"sender"`
+	Message          string       `db:"message"`
+	SentAt           time.Time    `db:"sent_at"`
+	CreatedAt        time.Time    `db:"created_at"`
+	MessageID        *string      `db:"message_id"`
+	ReplyToMessageID *string      `db:"reply_to_message_id"`
+	// ReplyToMessage is the text of the message replied to, when it is known
+	ReplyToMessage *string
+	Media          *ChatMedia
+	EditedAt       *time.Time `db:"edited_at"`
+	RevokedAt      *time.Time `db:"revoked_at"`
 }
 
 // ContactLog represents an interaction with a contact
@@ -281,6 +319,9 @@ type ContactLog struct {
 	Subject   *string   `db:"subject"`
 	Content   *string   `db:"content"`
 	CreatedAt time.Time `db:"created_at"`
+
+	IntroducedByID   *uuid.UUID `db:"introduced_by_contact_id"` // Introducer on intro logs
+	IntroducedByName *string    `db:"introduced_by_name"`
 }
 
 // ContactLogTimelineEntry represents a contact log entry for the timeline feed.
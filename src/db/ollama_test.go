@@ -94,4 +94,18 @@ func TestOllamaStreaming(t *testing.T) {
 	if streamed != "Hello world" {
 		t.Fatalf("expected streamed output, got %q", streamed)
 	}
+
+	briefing := &ContactBriefing{Contact: &ContactDetail{Contact: *contact}, Chats: chats}
+	streamed = ""
+
+	if err := StreamContactBriefing(context.Background(), briefing, time.Now(), func(chunk string) error {
+		streamed += chunk
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamContactBriefing failed: %v", err)
+	}
+
+	if streamed != "Hello world" {
+		t.Fatalf("expected streamed output, got %q", streamed)
+	}
 }
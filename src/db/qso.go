@@ -112,41 +112,11 @@ func ListQSOs(ctx context.Context) ([]QSOListItem, error) {
 	return ListQSOsWithFilters(ctx, QSOListOptions{})
 }
 
-// ListQSOsWithFilters returns QSOs matching provided filters.
-func ListQSOsWithFilters(ctx context.Context, opts QSOListOptions) ([]QSOListItem, error) {
-	if pool == nil {
-		return nil, ErrDatabaseConnectionNotInitialized
-	}
-
-	var (
-		whereClauses = make([]string, 0, 8)
-		args         = make([]any, 0, 8)
-	)
-
-	argNum := 1
-
-	parsed := parseSearchQuery(opts.SearchQuery)
-
-	for _, callSign := range parsed.callSignExact {
-		whereClauses = append(whereClauses, fmt.Sprintf("UPPER(call) = UPPER($%d)", argNum))
-		args = append(args, callSign)
-		argNum++
-	}
-
-	for _, callSignPattern := range parsed.callSignLike {
-		whereClauses = append(whereClauses, fmt.Sprintf("UPPER(call) LIKE UPPER($%d)", argNum))
-		args = append(args, callSignPattern)
-		argNum++
-	}
-
-	for _, band := range parsed.bands {
-		whereClauses = append(whereClauses, fmt.Sprintf("LOWER(band) = LOWER($%d)", argNum))
-		args = append(args, band)
-		argNum++
-	}
-
-	for _, term := range parsed.freeTerms {
-		whereClauses = append(whereClauses, fmt.Sprintf(`(
+// qsoSearchTermClause compiles a search term for the QSO list
+func qsoSearchTermClause(term searchTerm, argNum int) (string, []any, error) {
+	switch term.key {
+	case "":
+		return fmt.Sprintf(`(
 			call ILIKE $%d OR
 			COALESCE(country, '') ILIKE $%d OR
 			mode ILIKE $%d OR
@@ -155,9 +125,31 @@ func ListQSOsWithFilters(ctx context.Context, opts QSOListOptions) ([]QSOListIte
 			COALESCE(qth, '') ILIKE $%d OR
 			COALESCE(state, '') ILIKE $%d OR
 			COALESCE(gridsquare, '') ILIKE $%d
-		)`, argNum, argNum, argNum, argNum, argNum, argNum, argNum, argNum))
-		args = append(args, "%"+term+"%")
-		argNum++
+		)`, argNum, argNum, argNum, argNum, argNum, argNum, argNum, argNum), []any{"%" + term.value + "%"}, nil
+	case "callsign":
+		if term.wildcard {
+			return fmt.Sprintf("UPPER(call) LIKE UPPER($%d)", argNum), []any{term.value}, nil
+		}
+
+		return fmt.Sprintf("UPPER(call) = UPPER($%d)", argNum), []any{term.value}, nil
+	case "band":
+		return fmt.Sprintf("LOWER(band) = LOWER($%d)", argNum), []any{term.value}, nil
+	}
+
+	return "", nil, nil
+}
+
+// ListQSOsWithFilters returns QSOs matching provided filters.
+func ListQSOsWithFilters(ctx context.Context, opts QSOListOptions) ([]QSOListItem, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	parsed := parseSearchQuery(opts.SearchQuery)
+
+	whereClauses, args, _, err := parsed.whereClauses(1, qsoSearchTermClause)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `
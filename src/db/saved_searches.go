@@ -0,0 +1,103 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedSearch is a named contact search query
+type SavedSearch struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	Query     string    `db:"query"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ListSavedSearches returns saved searches ordered by name
+func ListSavedSearches(ctx context.Context) ([]SavedSearch, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, name, query, created_at, updated_at
+		FROM saved_searches
+		ORDER BY lower(name) ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []SavedSearch{}
+
+	for rows.Next() {
+		var search SavedSearch
+		if err := rows.Scan(&search.ID, &search.Name, &search.Query, &search.CreatedAt, &search.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// SaveSearch stores a named search. Saving under an existing name replaces
+// that search's query.
+func SaveSearch(ctx context.Context, name, query string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrSavedSearchNameRequired
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return ErrSavedSearchQueryRequired
+	}
+
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO saved_searches (name, query)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET query = EXCLUDED.query
+	`, name, query); err != nil {
+		return fmt.Errorf("failed to save search: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedSearch removes a saved search
+func DeleteSavedSearch(ctx context.Context, id string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	result, err := pool.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrSavedSearchNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSavedSearches(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	if err := SaveSearch(ctx, "  ", "tag:family"); !errors.Is(err, ErrSavedSearchNameRequired) {
+		t.Fatalf("expected ErrSavedSearchNameRequired, got %v", err)
+	}
+
+	if err := SaveSearch(ctx, "Family", " "); !errors.Is(err, ErrSavedSearchQueryRequired) {
+		t.Fatalf("expected ErrSavedSearchQueryRequired, got %v", err)
+	}
+
+	if err := SaveSearch(ctx, "Family", "tag:family"); err != nil {
+		t.Fatalf("SaveSearch failed: %v", err)
+	}
+
+	if err := SaveSearch(ctx, "Family", "tag:family overdue:yes"); err != nil {
+		t.Fatalf("SaveSearch replace failed: %v", err)
+	}
+
+	if err := SaveSearch(ctx, "comrades", "tag:friends"); err != nil {
+		t.Fatalf("SaveSearch failed: %v", err)
+	}
+
+	searches, err := ListSavedSearches(ctx)
+	if err != nil {
+		t.Fatalf("ListSavedSearches failed: %v", err)
+	}
+
+	if len(searches) != 2 || searches[0].Name != "comrades" || searches[1].Query != "tag:family overdue:yes" {
+		t.Fatalf("unexpected saved searches %#v", searches)
+	}
+
+	if err := DeleteSavedSearch(ctx, searches[0].ID.String()); err != nil {
+		t.Fatalf("DeleteSavedSearch failed: %v", err)
+	}
+
+	if err := DeleteSavedSearch(ctx, searches[0].ID.String()); !errors.Is(err, ErrSavedSearchNotFound) {
+		t.Fatalf("expected ErrSavedSearchNotFound, got %v", err)
+	}
+}
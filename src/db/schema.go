@@ -66,5 +66,9 @@ func SyncSchema(ctx context.Context) error {
 		return fmt.Errorf("failed to sync reference ranges: %w", err)
 	}
 
+	if err := SyncTierCadences(ctx); err != nil {
+		return fmt.Errorf("failed to sync tier cadences: %w", err)
+	}
+
 	return nil
 }
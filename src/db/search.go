@@ -0,0 +1,446 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SearchResultKind identifies which domain a search result came from
+type SearchResultKind string
+
+const (
+	SearchKindContact           SearchResultKind = "contact"
+	SearchKindContactNote       SearchResultKind = "contact_note"
+	SearchKindContactLog        SearchResultKind = "contact_log"
+	SearchKindChat              SearchResultKind = "chat"
+	SearchKindInventoryItem     SearchResultKind = "inventory_item"
+	SearchKindInventoryComment  SearchResultKind = "inventory_comment"
+	SearchKindLedgerTransaction SearchResultKind = "ledger_transaction"
+	SearchKindHealthFollowup    SearchResultKind = "health_followup"
+	SearchKindNote              SearchResultKind = "note"
+	SearchKindJournal           SearchResultKind = "journal"
+)
+
+// SearchResultKinds lists every kind in display order
+var SearchResultKinds = []SearchResultKind{
+	SearchKindContact,
+	SearchKindContactNote,
+	SearchKindContactLog,
+	SearchKindChat,
+	SearchKindNote,
+	SearchKindJournal,
+	SearchKindInventoryItem,
+	SearchKindInventoryComment,
+	SearchKindLedgerTransaction,
+	SearchKindHealthFollowup,
+}
+
+// Label returns a human readable name for the kind
+func (k SearchResultKind) Label() string {
+	switch k {
+	case SearchKindContact:
+		return "Contact"
+	case SearchKindContactNote:
+		return "Contact Note"
+	case SearchKindContactLog:
+		return "Contact Log"
+	case SearchKindChat:
+		return "Chat"
+	case SearchKindInventoryItem:
+		return "Inventory Item"
+	case SearchKindInventoryComment:
+		return "Inventory Comment"
+	case SearchKindLedgerTransaction:
+		return "Transaction"
+	case SearchKindHealthFollowup:
+		return "Health Follow-up"
+	case SearchKindNote:
+		return "Note"
+	case SearchKindJournal:
+		return "Journal"
+	default:
+		return string(k)
+	}
+}
+
+// Search highlight markers wrap matched terms in snippets. Control characters
+// are used so the caller can escape the snippet before adding markup.
+const (
+	SearchHighlightStart = "\x02"
+	SearchHighlightStop  = "\x03"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// searchHeadlineOptions configures ts_headline snippets
+var searchHeadlineOptions = fmt.Sprintf(
+	`StartSel="%s", StopSel="%s", MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=" … "`,
+	SearchHighlightStart, SearchHighlightStop,
+)
+
+// SearchOptions controls a global search and who is asking
+type SearchOptions struct {
+	Query           string
+	Kinds           []SearchResultKind // empty searches every permitted kind
+	IsAdmin         bool
+	SensitiveAccess bool
+	UserID          string // used for health profiles shared with non-admins
+	Limit           int
+}
+
+// SearchResult is a single ranked match from any domain
+type SearchResult struct {
+	Kind     SearchResultKind
+	ID       string
+	ParentID string
+	Title    string
+	Snippet  string
+	Rank     float32
+	URL      string
+}
+
+// searchBranches holds one query per kind. Each selects the same columns so
+// they can be combined with UNION ALL. $1 is the query text.
+var searchBranches = map[SearchResultKind]string{
+	SearchKindContact: `
+		SELECT 'contact'::text, c.id::text, ''::text, c.name_display,
+		       concat_ws(' · ', c.organization, c.title, c.notes),
+		       ts_rank(c.search_vector, q.query)
+		FROM contacts c CROSS JOIN q
+		WHERE c.search_vector @@ q.query`,
+	SearchKindContactNote: `
+		SELECT 'contact_note'::text, n.id::text, n.contact_id::text, c.name_display,
+		       n.content,
+		       ts_rank(n.search_vector, q.query)
+		FROM contact_notes n
+		INNER JOIN contacts c ON c.id = n.contact_id
+		CROSS JOIN q
+		WHERE n.search_vector @@ q.query`,
+	SearchKindContactLog: `
+		SELECT 'contact_log'::text, l.id::text, l.contact_id::text,
+		       c.name_display || coalesce(': ' || l.subject, ''),
+		       concat_ws(' · ', l.subject, l.content),
+		       ts_rank(l.search_vector, q.query)
+		FROM contact_logs l
+		INNER JOIN contacts c ON c.id = l.contact_id
+		CROSS JOIN q
+		WHERE l.search_vector @@ q.query`,
+	SearchKindChat: `
+		SELECT 'chat'::text, ch.id::text, ch.contact_id::text, c.name_display,
+		       ch.message,
+		       ts_rank(ch.search_vector, q.query)
+		FROM contact_chats ch
+		INNER JOIN contacts c ON c.id = ch.contact_id
+		CROSS JOIN q
+		WHERE ch.search_vector @@ q.query`,
+	SearchKindInventoryItem: `
+		SELECT 'inventory_item'::text, i.inventory_id, ''::text, i.name,
+		       concat_ws(' · ', i.location, i.description),
+		       ts_rank(i.search_vector, q.query)
+		FROM inventory_items i CROSS JOIN q
+		WHERE i.search_vector @@ q.query`,
+	SearchKindInventoryComment: `
+		SELECT 'inventory_comment'::text, ic.id::text, i.inventory_id, i.name,
+		       ic.content,
+		       ts_rank(ic.search_vector, q.query)
+		FROM inventory_comments ic
+		INNER JOIN inventory_items i ON i.id = ic.item_id
+		CROSS JOIN q
+		WHERE ic.search_vector @@ q.query`,
+	SearchKindLedgerTransaction: `
+		SELECT 'ledger_transaction'::text, t.id::text, t.account_id::text, t.merchant,
+		       concat_ws(' · ', t.merchant, t.note),
+		       ts_rank(t.search_vector, q.query)
+		FROM ledger_transactions t CROSS JOIN q
+		WHERE t.search_vector @@ q.query`,
+	SearchKindHealthFollowup: `
+		SELECT 'health_followup'::text, f.id::text, f.profile_id::text,
+		       p.name || ': ' || f.hospital_name,
+		       concat_ws(' · ', f.hospital_name, f.notes),
+		       ts_rank(f.search_vector, q.query)
+		FROM health_followups f
+		INNER JOIN health_profiles p ON p.id = f.profile_id
+		CROSS JOIN q
+		WHERE f.search_vector @@ q.query`,
+	SearchKindNote: `
+		SELECT 'note'::text, z.note_id, ''::text, z.title,
+		       z.body,
+		       ts_rank(z.search_vector, q.query)
+		FROM zk_note_search z CROSS JOIN q
+		WHERE z.search_vector @@ q.query AND NOT z.is_daily`,
+	SearchKindJournal: `
+		SELECT 'journal'::text, z.note_id, ''::text, z.title,
+		       z.body,
+		       ts_rank(z.search_vector, q.query)
+		FROM zk_note_search z CROSS JOIN q
+		WHERE z.search_vector @@ q.query AND z.is_daily`,
+}
+
+// SearchableKinds returns the kinds the caller may see, in display order.
+// Contacts, chats and inventory comments are admin only; contact notes and
+// logs, journal and ledger also need an unlocked sensitive view. Health needs
+// the sensitive view, and non-admins only see shared profiles.
+func SearchableKinds(opts SearchOptions) []SearchResultKind {
+	requested := make(map[SearchResultKind]bool, len(opts.Kinds))
+	for _, kind := range opts.Kinds {
+		requested[kind] = true
+	}
+
+	kinds := make([]SearchResultKind, 0, len(SearchResultKinds))
+
+	for _, kind := range SearchResultKinds {
+		if len(requested) > 0 && !requested[kind] {
+			continue
+		}
+
+		allowed := false
+
+		switch kind {
+		case SearchKindInventoryItem, SearchKindNote:
+			// Non-admins only match public and home wiki notes
+			allowed = true
+		case SearchKindContact, SearchKindChat, SearchKindInventoryComment:
+			allowed = opts.IsAdmin
+		case SearchKindContactNote, SearchKindContactLog, SearchKindLedgerTransaction, SearchKindJournal:
+			allowed = opts.IsAdmin && opts.SensitiveAccess
+		case SearchKindHealthFollowup:
+			allowed = opts.SensitiveAccess && (opts.IsAdmin || opts.UserID != "")
+		}
+
+		if allowed {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds
+}
+
+// searchResultURL returns the page a result links to
+func searchResultURL(kind SearchResultKind, id, parentID string, isAdmin bool) string {
+	switch kind {
+	case SearchKindContact:
+		return "/contact/" + id
+	case SearchKindContactNote, SearchKindContactLog:
+		return "/contact/" + parentID
+	case SearchKindChat:
+		return "/contact/" + parentID + "/chats"
+	case SearchKindInventoryItem:
+		return "/inventory/" + url.PathEscape(id)
+	case SearchKindInventoryComment:
+		return "/inventory/" + url.PathEscape(parentID)
+	case SearchKindLedgerTransaction:
+		return "/ledger/accounts/" + parentID
+	case SearchKindHealthFollowup:
+		return "/health/" + parentID + "/followup/" + id
+	case SearchKindNote:
+		if !isAdmin {
+			return "/home/" + url.PathEscape(id)
+		}
+
+		return "/zk/" + url.PathEscape(id)
+	case SearchKindJournal:
+		return "/journal/" + strings.TrimPrefix(id, DailyBacklinkPrefix)
+	default:
+		return ""
+	}
+}
+
+// SearchAll runs a ranked full-text search across every domain the caller
+// is permitted to see. The query uses web search syntax, so quoted phrases,
+// OR and -exclusions work.
+func SearchAll(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	queryText := strings.TrimSpace(opts.Query)
+	if queryText == "" {
+		return []SearchResult{}, nil
+	}
+
+	kinds := SearchableKinds(opts)
+	if len(kinds) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	args := []interface{}{queryText, limit, searchHeadlineOptions}
+	branches := make([]string, 0, len(kinds))
+
+	for _, kind := range kinds {
+		branch := searchBranches[kind]
+
+		switch kind {
+		case SearchKindNote:
+			if !opts.IsAdmin {
+				branch += " AND (z.is_public OR z.is_home)"
+			}
+		case SearchKindHealthFollowup:
+			if !opts.IsAdmin {
+				args = append(args, opts.UserID)
+				branch += fmt.Sprintf(
+					" AND f.profile_id IN (SELECT profile_id FROM health_profile_shares WHERE user_id = $%d::uuid)",
+					len(args))
+			}
+		}
+
+		branches = append(branches, branch)
+	}
+
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query)
+		SELECT r.kind, r.id, r.parent_id, r.title,
+		       ts_headline('simple', r.body, q.query, $3), r.rank
+		FROM (` + strings.Join(branches, "\n\t\tUNION ALL") + `
+		) AS r (kind, id, parent_id, title, body, rank)
+		CROSS JOIN q
+		ORDER BY r.rank DESC, r.title ASC
+		LIMIT $2
+	`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+
+	for rows.Next() {
+		var result SearchResult
+		if err := rows.Scan(&result.Kind, &result.ID, &result.ParentID, &result.Title, &result.Snippet, &result.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		result.URL = searchResultURL(result.Kind, result.ID, result.ParentID, opts.IsAdmin)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// zkSearchDocument is the text of a Zettelkasten note copied for search
+type zkSearchDocument struct {
+	NoteID   string
+	Title    string
+	Body     string
+	IsDaily  bool
+	IsPublic bool
+	IsHome   bool
+}
+
+// zkNoteSearchUpsertQuery inserts or updates the searchable copy of notes
+// given as parallel arrays, leaving rows whose text is unchanged untouched
+const zkNoteSearchUpsertQuery = `
+	INSERT INTO zk_note_search (note_id, title, body, is_daily, is_public, is_home)
+	SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::boolean[], $5::boolean[], $6::boolean[])
+	ON CONFLICT (note_id) DO UPDATE SET
+		title = EXCLUDED.title,
+		body = EXCLUDED.body,
+		is_daily = EXCLUDED.is_daily,
+		is_public = EXCLUDED.is_public,
+		is_home = EXCLUDED.is_home,
+		indexed_at = now()
+	WHERE (zk_note_search.title, zk_note_search.body, zk_note_search.is_daily, zk_note_search.is_public, zk_note_search.is_home)
+		IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.body, EXCLUDED.is_daily, EXCLUDED.is_public, EXCLUDED.is_home)
+`
+
+// indexZettelkastenNote updates the searchable copy of a single note
+func indexZettelkastenNote(ctx context.Context, doc zkSearchDocument) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if _, err := pool.Exec(ctx, zkNoteSearchUpsertQuery,
+		[]string{doc.NoteID},
+		[]string{strings.ReplaceAll(doc.Title, "\x00", "")},
+		[]string{strings.ReplaceAll(doc.Body, "\x00", "")},
+		[]bool{doc.IsDaily},
+		[]bool{doc.IsPublic},
+		[]bool{doc.IsHome},
+	); err != nil {
+		return fmt.Errorf("failed to index note: %w", err)
+	}
+
+	return nil
+}
+
+// indexZettelkastenNotes replaces the searchable copy of the Zettelkasten
+// with the given notes. Rows whose text is unchanged are left untouched.
+func indexZettelkastenNotes(ctx context.Context, docs []zkSearchDocument) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	seen := make(map[string]struct{}, len(docs))
+	ids := make([]string, 0, len(docs))
+	titles := make([]string, 0, len(docs))
+	bodies := make([]string, 0, len(docs))
+	dailies := make([]bool, 0, len(docs))
+	publics := make([]bool, 0, len(docs))
+	homes := make([]bool, 0, len(docs))
+
+	for _, doc := range docs {
+		if _, exists := seen[doc.NoteID]; exists {
+			continue
+		}
+
+		seen[doc.NoteID] = struct{}{}
+
+		ids = append(ids, doc.NoteID)
+		titles = append(titles, strings.ReplaceAll(doc.Title, "\x00", ""))
+		bodies = append(bodies, strings.ReplaceAll(doc.Body, "\x00", ""))
+		dailies = append(dailies, doc.IsDaily)
+		publics = append(publics, doc.IsPublic)
+		homes = append(homes, doc.IsHome)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback note search index", "error", err)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, zkNoteSearchUpsertQuery, ids, titles, bodies, dailies, publics, homes); err != nil {
+		return fmt.Errorf("failed to index notes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM zk_note_search WHERE NOT (note_id = ANY($1::text[]))`, ids); err != nil {
+		return fmt.Errorf("failed to remove stale notes from index: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit note search index: %w", err)
+	}
+
+	return nil
+}
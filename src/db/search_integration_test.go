@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func searchKinds(results []SearchResult) map[SearchResultKind]int {
+	kinds := make(map[SearchResultKind]int)
+	for _, result := range results {
+		kinds[result.Kind]++
+	}
+
+	return kinds
+}
+
+func TestSearchAll(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Zephyrine", NameFamily: stringPtr("Okafor"), Tier: TierB})
+
+	if err := AddNote(ctx, AddNoteInput{ContactID: contactID, Content: "Met Zephyrine at the antenna workshop"}); err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+
+	if _, err := CreateInventoryItem(ctx, "Antenna analyser", stringPtr("Shelf 2"), nil, InventoryStatusActive, nil, nil); err != nil {
+		t.Fatalf("CreateInventoryItem failed: %v", err)
+	}
+
+	if err := indexZettelkastenNotes(ctx, []zkSearchDocument{
+		{NoteID: "public-note", Title: "Antenna theory", Body: "Dipoles and antenna gain", IsPublic: true},
+		{NoteID: "private-note", Title: "Antenna plans", Body: "Private antenna budget"},
+		{NoteID: DailyBacklinkPrefix + "2025-03-01", Title: "2025-03-01", Body: "Raised the antenna", IsDaily: true},
+	}); err != nil {
+		t.Fatalf("indexZettelkastenNotes failed: %v", err)
+	}
+
+	member, err := SearchAll(ctx, SearchOptions{Query: "antenna", UserID: "00000000-0000-0000-0000-000000000000"})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	memberKinds := searchKinds(member)
+	if memberKinds[SearchKindInventoryItem] != 1 || memberKinds[SearchKindNote] != 1 || len(memberKinds) != 2 {
+		t.Fatalf("expected member to see the item and public note only, got %+v", member)
+	}
+
+	for _, result := range member {
+		if result.Kind == SearchKindNote && result.URL != "/home/public-note" {
+			t.Fatalf("expected member note to link to the home wiki, got %q", result.URL)
+		}
+	}
+
+	admin, err := SearchAll(ctx, SearchOptions{Query: "antenna", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	adminKinds := searchKinds(admin)
+	if adminKinds[SearchKindNote] != 2 || adminKinds[SearchKindContactNote] != 0 || adminKinds[SearchKindJournal] != 0 {
+		t.Fatalf("expected locked admin to see notes but no contact notes or journal, got %+v", admin)
+	}
+
+	unlocked, err := SearchAll(ctx, SearchOptions{Query: "antenna", IsAdmin: true, SensitiveAccess: true})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	unlockedKinds := searchKinds(unlocked)
+	if unlockedKinds[SearchKindContactNote] != 1 || unlockedKinds[SearchKindJournal] != 1 {
+		t.Fatalf("expected unlocked admin to see contact notes and journal, got %+v", unlocked)
+	}
+
+	for _, result := range unlocked {
+		if result.Kind == SearchKindContactNote {
+			if result.URL != "/contact/"+contactID {
+				t.Fatalf("unexpected contact note URL %q", result.URL)
+			}
+
+			if !strings.Contains(result.Snippet, SearchHighlightStart+"antenna"+SearchHighlightStop) {
+				t.Fatalf("expected highlighted snippet, got %q", result.Snippet)
+			}
+		}
+	}
+
+	contacts, err := SearchAll(ctx, SearchOptions{Query: "zephyrine", IsAdmin: true, Kinds: []SearchResultKind{SearchKindContact}})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	if len(contacts) != 1 || contacts[0].ID != contactID {
+		t.Fatalf("expected contact match, got %+v", contacts)
+	}
+
+	if err := indexZettelkastenNotes(ctx, []zkSearchDocument{
+		{NoteID: "public-note", Title: "Antenna theory", Body: "Dipoles and antenna gain", IsPublic: true},
+	}); err != nil {
+		t.Fatalf("indexZettelkastenNotes failed: %v", err)
+	}
+
+	reindexed, err := SearchAll(ctx, SearchOptions{Query: "budget", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	if len(reindexed) != 0 {
+		t.Fatalf("expected removed note to leave the index, got %+v", reindexed)
+	}
+
+	empty, err := SearchAll(ctx, SearchOptions{Query: "   ", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("SearchAll failed: %v", err)
+	}
+
+	if len(empty) != 0 {
+		t.Fatalf("expected no results for a blank query, got %+v", empty)
+	}
+}
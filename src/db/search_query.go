@@ -4,119 +4,394 @@
  */
 package db
 
-import "strings"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
 
+// searchTerm is a single token of a list search query. Free text has an
+// empty key; operators such as tag: or created: carry their parsed value.
+type searchTerm struct {
+	key      string
+	value    string
+	negated  bool
+	wildcard bool            // callsign value is a LIKE pattern
+	flag     bool            // overdue:yes or overdue:no
+	dates    searchDateRange // contacted: and created:
+	month    time.Month      // birthday:
+}
+
+// searchDateRange bounds a timestamp, from inclusive and to exclusive.
+// Never matches rows where the timestamp is missing.
+type searchDateRange struct {
+	from  *time.Time
+	to    *time.Time
+	never bool
+}
+
+// parsedSearchQuery is a conjunction of groups, where each group matches if
+// any of its terms match. Most groups hold a single term; OR joins terms into
+// one group.
 type parsedSearchQuery struct {
-	freeTerms     []string
-	tagNames      []string
-	tiers         []Tier
-	callSignExact []string
-	callSignLike  []string
-	bands         []string
-	categories    []string
-	hasTargets    []string
-	missing       []string
+	groups [][]searchTerm
 }
 
+// searchTermCompiler turns one positive term into a SQL condition whose
+// placeholders start at argNum. An empty condition means the list does not
+// support the term.
+type searchTermCompiler func(term searchTerm, argNum int) (string, []any, error)
+
+var relativeSearchDatePattern = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
 func parseSearchQuery(raw string) parsedSearchQuery {
+	return parseSearchQueryAt(raw, time.Now())
+}
+
+// parseSearchQueryAt parses a query with relative dates counted back from now
+func parseSearchQueryAt(raw string, now time.Time) parsedSearchQuery {
 	parsed := parsedSearchQuery{}
 
-	for _, rawToken := range strings.Fields(strings.TrimSpace(raw)) {
-		token := strings.TrimSpace(rawToken)
-		if token == "" {
-			continue
+	// Positive tier terms have always matched any of the listed tiers
+	var tiers []searchTerm
+
+	var group []searchTerm
+
+	flush := func() {
+		switch {
+		case len(group) == 0:
+		case len(group) == 1 && group[0].key == "tier" && !group[0].negated:
+			tiers = appendUniqueSearchTerm(tiers, group[0])
+		default:
+			parsed.groups = append(parsed.groups, group)
 		}
 
-		isNegated := strings.HasPrefix(token, "-")
-		if isNegated {
-			token = strings.TrimSpace(strings.TrimPrefix(token, "-"))
-			if token == "" {
-				continue
-			}
+		group = nil
+	}
+
+	pendingOr := false
+
+	for _, token := range splitSearchTokens(raw) {
+		if token == "OR" || token == "|" {
+			pendingOr = len(group) > 0
+			continue
 		}
 
-		key, value, hasOperator := strings.Cut(token, ":")
-		if !hasOperator {
-			parsed.freeTerms = append(parsed.freeTerms, token)
+		term, ok := parseSearchTerm(token, now)
+		if !ok {
 			continue
 		}
 
-		key = strings.ToLower(strings.TrimSpace(key))
+		if pendingOr {
+			group = append(group, term)
+			pendingOr = false
 
-		value = strings.TrimSpace(value)
-		if key == "" || value == "" {
-			parsed.freeTerms = append(parsed.freeTerms, token)
 			continue
 		}
 
-		switch key {
-		case "tag":
-			if isNegated {
-				continue
-			}
+		flush()
 
-			parsed.tagNames = appendUniqueString(parsed.tagNames, strings.ToLower(value))
-		case "tier":
-			if isNegated {
-				continue
-			}
+		group = []searchTerm{term}
+	}
 
-			tier := Tier(strings.ToUpper(value))
-			switch tier {
-			case TierA, TierB, TierC, TierD, TierE, TierF:
-				parsed.tiers = appendUniqueTier(parsed.tiers, tier)
-			}
-		case "callsign":
-			normalized := strings.ToUpper(strings.TrimSpace(value))
-			if normalized == "" {
-				continue
-			}
+	flush()
+
+	if len(tiers) > 0 {
+		parsed.groups = append(parsed.groups, tiers)
+	}
+
+	return parsed
+}
 
-			if strings.Contains(normalized, "*") {
-				parsed.callSignLike = appendUniqueString(parsed.callSignLike, sqlLikePatternFromWildcard(normalized))
-			} else {
-				parsed.callSignExact = appendUniqueString(parsed.callSignExact, normalized)
+// splitSearchTokens splits a query on whitespace, keeping double quoted
+// sections such as org:"Acme Corp" together
+func splitSearchTokens(raw string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
 			}
-		case "band":
-			if isNegated {
-				continue
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+func parseSearchTerm(rawToken string, now time.Time) (searchTerm, bool) {
+	token := strings.TrimSpace(rawToken)
+
+	negated := strings.HasPrefix(token, "-")
+	if negated {
+		token = strings.TrimSpace(strings.TrimPrefix(token, "-"))
+	}
+
+	if token == "" {
+		return searchTerm{}, false
+	}
+
+	freeText := searchTerm{value: token, negated: negated}
+
+	key, value, hasOperator := strings.Cut(token, ":")
+	if !hasOperator {
+		return freeText, true
+	}
+
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	value = strings.TrimSpace(value)
+	if key == "" || value == "" {
+		return freeText, true
+	}
+
+	term := searchTerm{key: key, negated: negated}
+
+	switch key {
+	case "tag":
+		term.value = strings.ToLower(value)
+	case "tier":
+		tier := Tier(strings.ToUpper(value))
+		switch tier {
+		case TierA, TierB, TierC, TierD, TierE, TierF:
+			term.value = string(tier)
+		default:
+			return searchTerm{}, false
+		}
+	case "callsign":
+		term.value = strings.ToUpper(value)
+		if strings.Contains(term.value, "*") {
+			term.value = sqlLikePatternFromWildcard(term.value)
+			term.wildcard = true
+		}
+	case "band":
+		term.value = strings.ToLower(value)
+	case "category", "type":
+		term.key = "category"
+		term.value = strings.ToLower(strings.Join(strings.Fields(value), " "))
+	case "has":
+		term.value = strings.ToLower(value)
+	case "org", "organization", "company":
+		term.key = "org"
+		term.value = value
+	case "tz", "timezone":
+		term.key = "tz"
+		term.value = value
+	case "overdue":
+		flag, err := parseSearchFlag(value)
+		if err != nil {
+			return searchTerm{}, false
+		}
+
+		term.flag = flag
+	case "contacted", "created":
+		dates, ok := parseSearchDateRange(value, now)
+		if !ok || (dates.never && key == "created") {
+			return searchTerm{}, false
+		}
+
+		term.dates = dates
+	case "birthday", "bday":
+		month, ok := parseSearchMonth(value)
+		if !ok {
+			return searchTerm{}, false
+		}
+
+		term.key = "birthday"
+		term.month = month
+	default:
+		return freeText, true
+	}
+
+	return term, true
+}
+
+func parseSearchFlag(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true", "1":
+		return true, nil
+	case "no", "n", "false", "0":
+		return false, nil
+	default:
+		return false, strconv.ErrSyntax
+	}
+}
+
+// parseSearchDateRange reads "never", a relative age such as <30d or >1y,
+// or a date such as >2025-01-01. A relative age counts back from now, so
+// <30d means within the last 30 days.
+func parseSearchDateRange(value string, now time.Time) (searchDateRange, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "never" {
+		return searchDateRange{never: true}, true
+	}
+
+	op := ""
+
+	for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			value = strings.TrimSpace(strings.TrimPrefix(value, prefix))
+
+			break
+		}
+	}
+
+	if matches := relativeSearchDatePattern.FindStringSubmatch(value); matches != nil {
+		amount, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return searchDateRange{}, false
+		}
+
+		var cutoff time.Time
+
+		switch matches[2] {
+		case "d":
+			cutoff = now.AddDate(0, 0, -amount)
+		case "w":
+			cutoff = now.AddDate(0, 0, -7*amount)
+		case "m":
+			cutoff = now.AddDate(0, -amount, 0)
+		case "y":
+			cutoff = now.AddDate(-amount, 0, 0)
+		}
+
+		switch op {
+		case ">", ">=":
+			return searchDateRange{to: &cutoff}, true
+		default:
+			return searchDateRange{from: &cutoff}, true
+		}
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return searchDateRange{}, false
+	}
+
+	next := day.AddDate(0, 0, 1)
+
+	switch op {
+	case ">":
+		return searchDateRange{from: &next}, true
+	case ">=":
+		return searchDateRange{from: &day}, true
+	case "<":
+		return searchDateRange{to: &day}, true
+	case "<=":
+		return searchDateRange{to: &next}, true
+	default:
+		return searchDateRange{from: &day, to: &next}, true
+	}
+}
+
+// parseSearchMonth reads a month name, its three letter abbreviation or number
+func parseSearchMonth(value string) (time.Month, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if number, err := strconv.Atoi(value); err == nil {
+		if number >= 1 && number <= 12 {
+			return time.Month(number), true
+		}
+
+		return 0, false
+	}
+
+	for month := time.January; month <= time.December; month++ {
+		name := strings.ToLower(month.String())
+		if value == name || value == name[:3] {
+			return month, true
+		}
+	}
+
+	return 0, false
+}
+
+// whereClauses compiles the query into AND-ed conditions. Negated terms are
+// wrapped so rows where the condition is NULL still count as not matching.
+func (p parsedSearchQuery) whereClauses(argNum int, compile searchTermCompiler) ([]string, []any, int, error) {
+	var (
+		clauses []string
+		args    []any
+	)
+
+	for _, group := range p.groups {
+		alternatives := make([]string, 0, len(group))
+
+		for _, term := range group {
+			clause, termArgs, err := compile(term, argNum)
+			if err != nil {
+				return nil, nil, argNum, err
 			}
 
-			parsed.bands = appendUniqueString(parsed.bands, strings.ToLower(value))
-		case "category", "type":
-			if isNegated {
+			if clause == "" {
 				continue
 			}
 
-			parsed.categories = appendUniqueString(parsed.categories, strings.ToLower(strings.Join(strings.Fields(value), " ")))
-		case "has":
-			target := strings.ToLower(value)
-			if isNegated {
-				parsed.missing = appendUniqueString(parsed.missing, target)
-			} else {
-				parsed.hasTargets = appendUniqueString(parsed.hasTargets, target)
+			if term.negated {
+				clause = "NOT COALESCE((" + clause + "), false)"
 			}
+
+			alternatives = append(alternatives, clause)
+			args = append(args, termArgs...)
+			argNum += len(termArgs)
+		}
+
+		switch len(alternatives) {
+		case 0:
+		case 1:
+			clauses = append(clauses, alternatives[0])
 		default:
-			parsed.freeTerms = append(parsed.freeTerms, token)
+			clauses = append(clauses, "("+strings.Join(alternatives, " OR ")+")")
 		}
 	}
 
-	return parsed
+	return clauses, args, argNum, nil
 }
 
-func appendUniqueString(values []string, value string) []string {
-	for _, existing := range values {
-		if existing == value {
-			return values
-		}
+// searchDateRangeClause bounds a timestamp expression by a date range
+func searchDateRangeClause(expr string, dates searchDateRange, argNum int) (string, []any) {
+	if dates.never {
+		return expr + " IS NULL", nil
 	}
 
-	return append(values, value)
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if dates.from != nil {
+		conditions = append(conditions, expr+" >= $"+strconv.Itoa(argNum+len(args)))
+		args = append(args, *dates.from)
+	}
+
+	if dates.to != nil {
+		conditions = append(conditions, expr+" < $"+strconv.Itoa(argNum+len(args)))
+		args = append(args, *dates.to)
+	}
+
+	return strings.Join(conditions, " AND "), args
 }
 
-func appendUniqueTier(values []Tier, value Tier) []Tier {
+func appendUniqueSearchTerm(values []searchTerm, value searchTerm) []searchTerm {
 	for _, existing := range values {
-		if existing == value {
+		if existing.key == value.key && existing.value == value.value {
 			return values
 		}
 	}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func searchTermKeys(group []searchTerm) []string {
+	keys := make([]string, 0, len(group))
+	for _, term := range group {
+		prefix := ""
+		if term.negated {
+			prefix = "-"
+		}
+
+		keys = append(keys, prefix+term.key+":"+term.value)
+	}
+
+	return keys
+}
+
+func TestParseSearchQueryGroups(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	parsed := parseSearchQueryAt(`tier:a -tag:Work tag:family OR tag:friends tier:b org:"Acme Corp" -ali bogus:x tier:z`, now)
+
+	got := make([][]string, 0, len(parsed.groups))
+	for _, group := range parsed.groups {
+		got = append(got, searchTermKeys(group))
+	}
+
+	want := [][]string{
+		{"-tag:work"},
+		{"tag:family", "tag:friends"},
+		{"org:Acme Corp"},
+		{"-:ali"},
+		{":bogus:x"},
+		{"tier:A", "tier:B"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d groups, got %v", len(want), got)
+	}
+
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Fatalf("group %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSearchQueryOperators(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	parsed := parseSearchQueryAt("contacted:<30d created:>2025-01-01 birthday:march overdue:no callsign:a6* contacted:never", now)
+
+	if len(parsed.groups) != 6 {
+		t.Fatalf("expected six groups, got %d", len(parsed.groups))
+	}
+
+	contacted := parsed.groups[0][0]
+	if contacted.dates.from == nil || !contacted.dates.from.Equal(now.AddDate(0, 0, -30)) || contacted.dates.to != nil {
+		t.Fatalf("unexpected contacted range %+v", contacted.dates)
+	}
+
+	created := parsed.groups[1][0]
+	if created.dates.from == nil || !created.dates.from.Equal(time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected created range %+v", created.dates)
+	}
+
+	if parsed.groups[2][0].month != time.March {
+		t.Fatalf("expected March, got %v", parsed.groups[2][0].month)
+	}
+
+	if overdue := parsed.groups[3][0]; overdue.key != "overdue" || overdue.flag {
+		t.Fatalf("unexpected overdue term %+v", overdue)
+	}
+
+	if callSign := parsed.groups[4][0]; !callSign.wildcard || callSign.value != "A6%" {
+		t.Fatalf("unexpected callsign term %+v", callSign)
+	}
+
+	if !parsed.groups[5][0].dates.never {
+		t.Fatal("expected contacted:never to match missing dates")
+	}
+
+	for _, invalid := range []string{"birthday:smarch", "created:never", "contacted:soon", "overdue:maybe"} {
+		if groups := parseSearchQueryAt(invalid, now).groups; len(groups) != 0 {
+			t.Fatalf("expected %q to be dropped, got %+v", invalid, groups)
+		}
+	}
+}
+
+func TestParseSearchDateRange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		value string
+		from  *time.Time
+		to    *time.Time
+	}{
+		{"2025-01-01", &day, &next},
+		{">=2025-01-01", &day, nil},
+		{"<2025-01-01", nil, &day},
+		{"<=2025-01-01", nil, &next},
+		{">2w", nil, ptrTime(now.AddDate(0, 0, -14))},
+		{"6m", ptrTime(now.AddDate(0, -6, 0)), nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseSearchDateRange(tt.value, now)
+		if !ok {
+			t.Fatalf("parseSearchDateRange(%q) failed", tt.value)
+		}
+
+		if !sameTimePtr(got.from, tt.from) || !sameTimePtr(got.to, tt.to) {
+			t.Fatalf("parseSearchDateRange(%q) = %+v", tt.value, got)
+		}
+	}
+}
+
+func TestSearchWhereClauses(t *testing.T) {
+	t.Parallel()
+
+	parsed := parseSearchQueryAt("tag:a OR tag:b -tag:c band:40m", time.Now())
+
+	compile := func(term searchTerm, argNum int) (string, []any, error) {
+		if term.key != "tag" {
+			return "", nil, nil
+		}
+
+		return fmt.Sprintf("tag = $%d", argNum), []any{term.value}, nil
+	}
+
+	clauses, args, next, err := parsed.whereClauses(3, compile)
+	if err != nil {
+		t.Fatalf("whereClauses failed: %v", err)
+	}
+
+	wantClauses := []string{"(tag = $3 OR tag = $4)", "NOT COALESCE((tag = $5), false)"}
+	if !slices.Equal(clauses, wantClauses) {
+		t.Fatalf("clauses = %v, want %v", clauses, wantClauses)
+	}
+
+	if !slices.Equal(args, []any{"a", "b", "c"}) || next != 6 {
+		t.Fatalf("unexpected args %v and next placeholder %d", args, next)
+	}
+}
+
+func ptrTime(value time.Time) *time.Time {
+	return &value
+}
+
+func sameTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
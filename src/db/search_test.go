@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchableKindsHonorsAccess(t *testing.T) {
+	t.Parallel()
+
+	member := SearchableKinds(SearchOptions{UserID: "u1"})
+	if !slices.Equal(member, []SearchResultKind{SearchKindNote, SearchKindInventoryItem}) {
+		t.Fatalf("unexpected member kinds %v", member)
+	}
+
+	memberUnlocked := SearchableKinds(SearchOptions{UserID: "u1", SensitiveAccess: true})
+	if !slices.Contains(memberUnlocked, SearchKindHealthFollowup) || slices.Contains(memberUnlocked, SearchKindLedgerTransaction) {
+		t.Fatalf("unexpected unlocked member kinds %v", memberUnlocked)
+	}
+
+	admin := SearchableKinds(SearchOptions{IsAdmin: true})
+	if !slices.Contains(admin, SearchKindContact) || !slices.Contains(admin, SearchKindChat) {
+		t.Fatalf("expected admin to search contacts and chats, got %v", admin)
+	}
+
+	for _, kind := range []SearchResultKind{SearchKindContactNote, SearchKindContactLog, SearchKindJournal, SearchKindLedgerTransaction, SearchKindHealthFollowup} {
+		if slices.Contains(admin, kind) {
+			t.Fatalf("expected %s to need the sensitive view", kind)
+		}
+	}
+
+	adminUnlocked := SearchableKinds(SearchOptions{IsAdmin: true, SensitiveAccess: true})
+	if !slices.Equal(adminUnlocked, SearchResultKinds) {
+		t.Fatalf("expected every kind when unlocked, got %v", adminUnlocked)
+	}
+
+	filtered := SearchableKinds(SearchOptions{IsAdmin: true, Kinds: []SearchResultKind{SearchKindChat, SearchKindLedgerTransaction}})
+	if !slices.Equal(filtered, []SearchResultKind{SearchKindChat}) {
+		t.Fatalf("unexpected filtered kinds %v", filtered)
+	}
+}
+
+func TestSearchResultURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind     SearchResultKind
+		id       string
+		parentID string
+		isAdmin  bool
+		want     string
+	}{
+		{SearchKindContact, "c1", "", true, "/contact/c1"},
+		{SearchKindContactLog, "l1", "c1", true, "/contact/c1"},
+		{SearchKindChat, "m1", "c1", true, "/contact/c1/chats"},
+		{SearchKindInventoryComment, "x1", "GW-00001", true, "/inventory/GW-00001"},
+		{SearchKindLedgerTransaction, "t1", "a1", true, "/ledger/accounts/a1"},
+		{SearchKindHealthFollowup, "f1", "p1", false, "/health/p1/followup/f1"},
+		{SearchKindNote, "n1", "", true, "/zk/n1"},
+		{SearchKindNote, "n1", "", false, "/home/n1"},
+		{SearchKindJournal, DailyBacklinkPrefix + "2025-03-01", "", true, "/journal/2025-03-01"},
+	}
+
+	for _, tt := range tests {
+		if got := searchResultURL(tt.kind, tt.id, tt.parentID, tt.isAdmin); got != tt.want {
+			t.Fatalf("searchResultURL(%s) = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
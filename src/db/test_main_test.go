@@ -203,6 +203,10 @@ func resetDatabase(t *testing.T) {
 		t.Fatalf("failed to truncate schema: %v", err)
 	}
 
+	if err := SyncTierCadences(ctx); err != nil {
+		t.Fatalf("failed to seed tier cadences: %v", err)
+	}
+
 	resetZettelkastenCaches()
 }
 
@@ -277,4 +281,10 @@ func resetZettelkastenCaches() {
 	lastZKNoteBuild = time.Time{}
 
 	zkNoteMutex.Unlock()
+	zkEmbeddingMutex.Lock()
+
+	zkEmbeddingModel = ""
+	zkEmbeddingNotes = make(map[string]zkEmbeddedNote)
+
+	zkEmbeddingMutex.Unlock()
 }
@@ -20,8 +20,12 @@ import (
 
 // TodoNote represents a single org-mode todo page.
 type TodoNote struct {
-	Title    string
-	HTMLBody template.HTML
+	Title     string
+	HTMLBody  template.HTML
+	Tasks     []utils.OrgTask
+	Headlines []utils.OrgTask // Every headline, to add tasks under
+	Keywords  utils.OrgTodoKeywords
+	ETag      string // Empty when the WebDAV server reports none
 }
 
 func newTodoHTTPClient(username, password string) *http.Client {
@@ -40,34 +44,43 @@ func newTodoHTTPClient(username, password string) *http.Client {
 	}
 }
 
-// GetTodoNote fetches and parses the todo org-mode file from WebDAV.
-func GetTodoNote(ctx context.Context) (*TodoNote, error) {
+// todoFileURL returns WEBDAV_TODO_PATH once it is checked to be an org file
+func todoFileURL() (string, error) {
 	todoPath := os.Getenv("WEBDAV_TODO_PATH")
 	if todoPath == "" {
-		return nil, ErrWebDAVTodoPathNotConfigured
+		return "", ErrWebDAVTodoPathNotConfigured
 	}
 
 	parsedURL, err := url.Parse(todoPath)
 	if err != nil {
-		return nil, fmt.Errorf("invalid WEBDAV_TODO_PATH URL: %w", err)
+		return "", fmt.Errorf("invalid WEBDAV_TODO_PATH URL: %w", err)
 	}
 
 	if !strings.HasSuffix(parsedURL.Path, ".org") {
-		return nil, ErrWebDAVTodoPathMustBeOrgFile
+		return "", ErrWebDAVTodoPathMustBeOrgFile
 	}
 
-	username := os.Getenv("WEBDAV_USERNAME")
-	password := os.Getenv("WEBDAV_PASSWORD")
-	httpClient := newTodoHTTPClient(username, password)
+	return todoPath, nil
+}
+
+// fetchTodoFile downloads the todo file along with its ETag, which is empty
+// when the server sends none
+func fetchTodoFile(ctx context.Context) (string, string, error) {
+	todoPath, err := todoFileURL()
+	if err != nil {
+		return "", "", err
+	}
+
+	httpClient := newTodoHTTPClient(os.Getenv("WEBDAV_USERNAME"), os.Getenv("WEBDAV_PASSWORD"))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, todoPath, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch todo file: %w", err)
+		return "", "", fmt.Errorf("failed to fetch todo file: %w", err)
 	}
 
 	defer func() {
@@ -77,15 +90,65 @@ func GetTodoNote(ctx context.Context) (*TodoNote, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: HTTP %d", ErrFetchTodoFileFailed, resp.StatusCode)
+		return "", "", fmt.Errorf("%w: HTTP %d", ErrFetchTodoFileFailed, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read todo file content: %w", err)
+		return "", "", fmt.Errorf("failed to read todo file content: %w", err)
+	}
+
+	return string(body), resp.Header.Get("ETag"), nil
+}
+
+// putTodoFile uploads the todo file only while it still has the expected
+// ETag. A failed precondition is reported as ErrTodoConflict.
+func putTodoFile(ctx context.Context, content, expectedETag string) error {
+	todoPath, err := todoFileURL()
+	if err != nil {
+		return err
 	}
 
-	content := string(body)
+	httpClient := newTodoHTTPClient(os.Getenv("WEBDAV_USERNAME"), os.Getenv("WEBDAV_PASSWORD"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, todoPath, strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("If-Match", expectedETag)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to save todo file: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close todo response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrTodoConflict
+	}
+
+	return fmt.Errorf("%w: HTTP %d", ErrTodoSaveHTTPStatus, resp.StatusCode)
+}
+
+// GetTodoNote fetches and parses the todo org-mode file from WebDAV. The
+// headlines are parsed into tasks for the agenda alongside the rendered file.
+func GetTodoNote(ctx context.Context) (*TodoNote, error) {
+	content, etag, err := fetchTodoFile(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	html, err := utils.ParseOrgToHTML(content)
 	if err != nil {
@@ -93,7 +156,11 @@ func GetTodoNote(ctx context.Context) (*TodoNote, error) {
 	}
 
 	return &TodoNote{
-		Title:    utils.ExtractTitle(content),
-		HTMLBody: template.HTML(html), //nolint:gosec // HTML comes from trusted org parser output.
+		Title:     utils.ExtractTitle(content),
+		HTMLBody:  template.HTML(html), //nolint:gosec // HTML comes from trusted org parser output.
+		Tasks:     utils.ParseOrgTasks(content),
+		Headlines: utils.ParseOrgHeadlines(content),
+		Keywords:  utils.ParseOrgTodoKeywords(content),
+		ETag:      etag,
 	}, nil
 }
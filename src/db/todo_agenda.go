@@ -0,0 +1,271 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+// TodoUpcomingDays is how far past the shown range upcoming tasks are listed
+const TodoUpcomingDays = 30
+
+// TodoAgendaSpan is the range the agenda covers
+type TodoAgendaSpan string
+
+const (
+	// TodoAgendaSpanDay shows a single day
+	TodoAgendaSpanDay TodoAgendaSpan = "day"
+	// TodoAgendaSpanWeek shows the week, Monday to Sunday
+	TodoAgendaSpanWeek TodoAgendaSpan = "week"
+)
+
+// TodoAgendaItemKind tells why a task shows on a day
+type TodoAgendaItemKind string
+
+const (
+	// TodoAgendaScheduled is a SCHEDULED timestamp
+	TodoAgendaScheduled TodoAgendaItemKind = "scheduled"
+	// TodoAgendaDeadline is a DEADLINE timestamp
+	TodoAgendaDeadline TodoAgendaItemKind = "deadline"
+)
+
+// TodoAgendaItem is a task on a given day
+type TodoAgendaItem struct {
+	Task      utils.OrgTask
+	Kind      TodoAgendaItemKind
+	On        time.Time // Day of the occurrence, following repeaters
+	Timestamp utils.OrgTimestamp
+	DaysAway  int // Days from today, negative when overdue
+}
+
+// DaysOverdue returns how many days ago an overdue item was due
+func (i TodoAgendaItem) DaysOverdue() int {
+	return -i.DaysAway
+}
+
+// TodoAgendaDay lists the tasks of one day
+type TodoAgendaDay struct {
+	Date    time.Time
+	IsToday bool
+	Items   []TodoAgendaItem
+}
+
+// TodoAgendaOptions selects what the agenda shows
+type TodoAgendaOptions struct {
+	Span TodoAgendaSpan
+	Date time.Time // Any day within the range to show
+	Tag  string    // Only tasks carrying this tag, all when empty
+}
+
+// TodoAgenda groups the tasks of the todo file by day
+type TodoAgenda struct {
+	Span        TodoAgendaSpan
+	Tag         string
+	Today       time.Time
+	Start       time.Time
+	End         time.Time // Last day shown, inclusive
+	Previous    time.Time // A day in the range before
+	Next        time.Time // A day in the range after
+	Days        []TodoAgendaDay
+	Overdue     []TodoAgendaItem // Open tasks scheduled or due before today
+	Upcoming    []TodoAgendaItem // Open tasks in the TodoUpcomingDays after the range
+	Unscheduled []utils.OrgTask  // Open tasks without a date
+	Tags        []string         // Every tag in the file, for filtering
+}
+
+// civilDay returns the date of t at midnight UTC, the form org timestamps use
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween counts whole days from one civil day to another
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// todoTaskTimestamps returns the dated timestamps of a task, deadline first
+func todoTaskTimestamps(task utils.OrgTask) []TodoAgendaItem {
+	var items []TodoAgendaItem
+
+	if task.Deadline != nil {
+		items = append(items, TodoAgendaItem{Task: task, Kind: TodoAgendaDeadline, Timestamp: *task.Deadline})
+	}
+
+	if task.Scheduled != nil {
+		items = append(items, TodoAgendaItem{Task: task, Kind: TodoAgendaScheduled, Timestamp: *task.Scheduled})
+	}
+
+	return items
+}
+
+// todoPriorityRank orders priorities, treating a missing cookie as B like org
+func todoPriorityRank(priority string) string {
+	if priority == "" {
+		return "B"
+	}
+
+	return priority
+}
+
+// sortTodoAgendaItems orders items by day, timed items first by time, then
+// deadlines before scheduled tasks and by priority
+func sortTodoAgendaItems(items []TodoAgendaItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+
+		if !a.On.Equal(b.On) {
+			return a.On.Before(b.On)
+		}
+
+		if a.Timestamp.HasTime != b.Timestamp.HasTime {
+			return a.Timestamp.HasTime
+		}
+
+		if a.Timestamp.HasTime {
+			aClock := a.Timestamp.Date.Hour()*60 + a.Timestamp.Date.Minute()
+			bClock := b.Timestamp.Date.Hour()*60 + b.Timestamp.Date.Minute()
+
+			if aClock != bClock {
+				return aClock < bClock
+			}
+		}
+
+		if a.Kind != b.Kind {
+			return a.Kind == TodoAgendaDeadline
+		}
+
+		return todoPriorityRank(a.Task.Priority) < todoPriorityRank(b.Task.Priority)
+	})
+}
+
+// overdueTodoItem returns the first timestamp of an open task dated before
+// today. Org keeps a repeating task at its last date until it is marked
+// done, so the written date decides rather than the next repeat.
+func overdueTodoItem(items []TodoAgendaItem, today time.Time) (TodoAgendaItem, bool) {
+	for _, item := range items {
+		if base := item.Timestamp.Day(); base.Before(today) {
+			item.On = base
+			item.DaysAway = daysBetween(today, base)
+
+			return item, true
+		}
+	}
+
+	return TodoAgendaItem{}, false
+}
+
+// BuildTodoAgenda lays out the tasks over a day or week along with overdue,
+// upcoming and unscheduled lists. Repeating timestamps show on every
+// occurrence in the range while the task is open.
+func BuildTodoAgenda(tasks []utils.OrgTask, now time.Time, opts TodoAgendaOptions) TodoAgenda {
+	today := civilDay(now)
+
+	date := today
+	if !opts.Date.IsZero() {
+		date = civilDay(opts.Date)
+	}
+
+	agenda := TodoAgenda{
+		Span:  TodoAgendaSpanDay,
+		Tag:   strings.TrimSpace(opts.Tag),
+		Today: today,
+		Tags:  utils.OrgTaskTags(tasks),
+	}
+
+	days := 1
+	if opts.Span == TodoAgendaSpanWeek {
+		agenda.Span = TodoAgendaSpanWeek
+		days = 7
+		// Weeks start on Monday as in the org agenda
+		date = date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+	}
+
+	agenda.Start = date
+	agenda.End = date.AddDate(0, 0, days-1)
+	agenda.Previous = date.AddDate(0, 0, -days)
+	agenda.Next = date.AddDate(0, 0, days)
+
+	byDay := make(map[time.Time][]TodoAgendaItem, days)
+
+	upcomingFrom := agenda.End.AddDate(0, 0, 1)
+	if upcomingFrom.Before(today) {
+		upcomingFrom = today
+	}
+
+	upcomingTo := upcomingFrom.AddDate(0, 0, TodoUpcomingDays-1)
+
+	for _, task := range tasks {
+		if agenda.Tag != "" && !task.HasTag(agenda.Tag) {
+			continue
+		}
+
+		items := todoTaskTimestamps(task)
+		if len(items) == 0 {
+			if !task.Done {
+				agenda.Unscheduled = append(agenda.Unscheduled, task)
+			}
+
+			continue
+		}
+
+		for _, item := range items {
+			// A closed task only shows on its own date, repeats ended with it
+			if task.Done {
+				if on := item.Timestamp.Day(); !on.Before(agenda.Start) && !on.After(agenda.End) {
+					item.On = on
+					item.DaysAway = daysBetween(today, on)
+					byDay[on] = append(byDay[on], item)
+				}
+
+				continue
+			}
+
+			for _, on := range item.Timestamp.OccurrencesBetween(agenda.Start, agenda.End) {
+				dayItem := item
+				dayItem.On = on
+				dayItem.DaysAway = daysBetween(today, on)
+				byDay[on] = append(byDay[on], dayItem)
+			}
+		}
+
+		if task.Done {
+			continue
+		}
+
+		if item, ok := overdueTodoItem(items, today); ok {
+			agenda.Overdue = append(agenda.Overdue, item)
+			continue
+		}
+
+		for _, item := range items {
+			if next, ok := item.Timestamp.NextOccurrence(upcomingFrom); ok && !next.After(upcomingTo) {
+				item.On = next
+				item.DaysAway = daysBetween(today, next)
+				agenda.Upcoming = append(agenda.Upcoming, item)
+			}
+		}
+	}
+
+	for i := 0; i < days; i++ {
+		day := agenda.Start.AddDate(0, 0, i)
+		items := byDay[day]
+		sortTodoAgendaItems(items)
+
+		agenda.Days = append(agenda.Days, TodoAgendaDay{
+			Date:    day,
+			IsToday: day.Equal(today),
+			Items:   items,
+		})
+	}
+
+	sortTodoAgendaItems(agenda.Overdue)
+	sortTodoAgendaItems(agenda.Upcoming)
+
+	return agenda
+}
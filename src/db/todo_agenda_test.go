@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+const todoAgendaTestFile = "#+FILETAGS: :home:\n" +
+	"* Work :work:\n" +
+	"** TODO [#A] Ship report\n   DEADLINE: <2025-03-05 Wed>\n" +
+	"** TODO Review PR\n   SCHEDULED: <2025-03-07 Fri 09:30>\n" +
+	"** TODO Weekly sync\n   SCHEDULED: <2025-03-12 Wed 10:00 +1w>\n" +
+	"** DONE Old thing\n   CLOSED: [2025-03-11 Tue 11:00] SCHEDULED: <2025-03-11 Tue>\n" +
+	"* TODO Renew passport\n  DEADLINE: <2025-04-01 Tue>\n" +
+	"* TODO Buy milk\n"
+
+func todoAgendaTitles(items []TodoAgendaItem) []string {
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.Task.Title)
+	}
+
+	return titles
+}
+
+func TestBuildTodoAgendaWeek(t *testing.T) {
+	t.Parallel()
+
+	tasks := utils.ParseOrgTasks(todoAgendaTestFile)
+	now := time.Date(2025, time.March, 12, 15, 0, 0, 0, time.UTC) // Wednesday
+
+	agenda := BuildTodoAgenda(tasks, now, TodoAgendaOptions{Span: TodoAgendaSpanWeek})
+
+	monday := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	if !agenda.Start.Equal(monday) || len(agenda.Days) != 7 || !agenda.Previous.Equal(monday.AddDate(0, 0, -7)) {
+		t.Fatalf("expected the week to start on Monday, got %v with %d days", agenda.Start, len(agenda.Days))
+	}
+
+	if !agenda.Days[2].IsToday || agenda.Days[1].IsToday {
+		t.Fatalf("expected Wednesday to be today")
+	}
+
+	if got := todoAgendaTitles(agenda.Days[2].Items); !reflect.DeepEqual(got, []string{"Weekly sync"}) {
+		t.Fatalf("unexpected items today: %v", got)
+	}
+
+	if got := todoAgendaTitles(agenda.Days[1].Items); !reflect.DeepEqual(got, []string{"Old thing"}) {
+		t.Fatalf("expected the done task on its own day, got %v", got)
+	}
+
+	if got := todoAgendaTitles(agenda.Overdue); !reflect.DeepEqual(got, []string{"Ship report", "Review PR"}) {
+		t.Fatalf("unexpected overdue items: %v", got)
+	}
+
+	if agenda.Overdue[0].Kind != TodoAgendaDeadline || agenda.Overdue[0].DaysOverdue() != 7 {
+		t.Fatalf("unexpected overdue deadline: %#v", agenda.Overdue[0])
+	}
+
+	// The weekly repeat continues after the shown week
+	if got := todoAgendaTitles(agenda.Upcoming); !reflect.DeepEqual(got, []string{"Weekly sync", "Renew passport"}) {
+		t.Fatalf("unexpected upcoming items: %v", got)
+	}
+
+	if !agenda.Upcoming[0].On.Equal(monday.AddDate(0, 0, 9)) || agenda.Upcoming[0].DaysAway != 7 {
+		t.Fatalf("unexpected upcoming repeat: %#v", agenda.Upcoming[0])
+	}
+
+	if len(agenda.Unscheduled) != 1 || agenda.Unscheduled[0].Title != "Buy milk" {
+		t.Fatalf("unexpected unscheduled tasks: %#v", agenda.Unscheduled)
+	}
+
+	if !reflect.DeepEqual(agenda.Tags, []string{"home", "work"}) {
+		t.Fatalf("unexpected tags: %v", agenda.Tags)
+	}
+}
+
+func TestBuildTodoAgendaDayWithTagFilter(t *testing.T) {
+	t.Parallel()
+
+	tasks := utils.ParseOrgTasks(todoAgendaTestFile)
+	now := time.Date(2025, time.March, 12, 15, 0, 0, 0, time.UTC)
+
+	agenda := BuildTodoAgenda(tasks, now, TodoAgendaOptions{
+		Span: TodoAgendaSpanDay,
+		Date: time.Date(2025, time.March, 19, 0, 0, 0, 0, time.UTC),
+		Tag:  "WORK",
+	})
+
+	if len(agenda.Days) != 1 || !agenda.Next.Equal(time.Date(2025, time.March, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a single day, got %#v", agenda.Days)
+	}
+
+	if got := todoAgendaTitles(agenda.Days[0].Items); !reflect.DeepEqual(got, []string{"Weekly sync"}) {
+		t.Fatalf("expected the repeat on the chosen day, got %v", got)
+	}
+
+	if len(agenda.Unscheduled) != 0 {
+		t.Fatalf("expected untagged tasks to be filtered out, got %#v", agenda.Unscheduled)
+	}
+
+	for _, item := range append(agenda.Overdue, agenda.Upcoming...) {
+		if !item.Task.HasTag("work") {
+			t.Fatalf("expected only work tasks, got %#v", item.Task)
+		}
+	}
+}
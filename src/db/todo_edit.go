@@ -0,0 +1,384 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+const (
+	todoDateFormat      = "2006-01-02 Mon"
+	todoTimestampFormat = "2006-01-02 Mon 15:04"
+)
+
+var (
+	todoHeadlinePattern     = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	todoPlanningLinePattern = regexp.MustCompile(`^\s*(?:SCHEDULED|DEADLINE|CLOSED):`)
+	todoClosedPattern       = regexp.MustCompile(`CLOSED:\s*\[[^\]]*\]\s*`)
+	todoRepeatablePattern   = regexp.MustCompile(`(SCHEDULED|DEADLINE):(\s*)<([^>]+)>`)
+	todoDayNamePattern      = regexp.MustCompile(`^\pL+\.?$`)
+	todoClockPattern        = regexp.MustCompile(`^\d{1,2}:\d{2}`)
+)
+
+// TodoStateChange describes what changing the state of a task did
+type TodoStateChange struct {
+	Title      string
+	Keyword    string     // Keyword the headline ended up with
+	RepeatedTo *time.Time // Next date of a repeating task, which stays open
+}
+
+// NewTodoTask is a task to append to the todo file
+type NewTodoTask struct {
+	Title     string
+	Priority  string
+	Tags      []string
+	Scheduled *time.Time
+	Deadline  *time.Time
+}
+
+// SetTodoState changes the TODO keyword of the headline at a line of the
+// todo file, cycling to the next keyword of the file's sequence when none
+// is given. Marking a task done adds a CLOSED timestamp, unless it repeats,
+// in which case its dates move on and it stays open as org-mode does. The
+// file is only written while it still has the expected ETag.
+func SetTodoState(ctx context.Context, line int, title, keyword, expectedETag string, now time.Time) (*TodoStateChange, error) {
+	var change *TodoStateChange
+
+	err := editTodoFile(ctx, expectedETag, func(content string) (string, error) {
+		updated, result, err := setTodoKeyword(content, line, title, keyword, now)
+		change = result
+
+		return updated, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// AddTodoTask appends a task with the first keyword of the file's sequence
+// under the headline at parentLine, or at the end of the file when
+// parentLine is negative. The file is only written while it still has the
+// expected ETag.
+func AddTodoTask(ctx context.Context, parentLine int, parentTitle string, task NewTodoTask, expectedETag string) error {
+	return editTodoFile(ctx, expectedETag, func(content string) (string, error) {
+		return insertTodoTask(content, parentLine, parentTitle, task)
+	})
+}
+
+// editTodoFile applies an edit to the todo file. The ETag is checked before
+// editing so a stale page fails fast, and again by If-Match on upload so an
+// edit made in between is never overwritten.
+func editTodoFile(ctx context.Context, expectedETag string, edit func(content string) (string, error)) error {
+	expectedETag, ok := sanitizeWebDAVETag(expectedETag)
+	if !ok {
+		return ErrTodoETagRequired
+	}
+
+	content, etag, err := fetchTodoFile(ctx)
+	if err != nil {
+		return err
+	}
+
+	if currentETag, ok := sanitizeWebDAVETag(etag); !ok || currentETag != expectedETag {
+		return ErrTodoConflict
+	}
+
+	updated, err := edit(content)
+	if err != nil {
+		return err
+	}
+
+	return putTodoFile(ctx, updated, expectedETag)
+}
+
+// findTodoHeadline returns the headline at a line, checking it still has
+// the title the page showed
+func findTodoHeadline(content string, line int, title string) (utils.OrgTask, error) {
+	for _, headline := range utils.ParseOrgHeadlines(content) {
+		if headline.Line == line {
+			if headline.Title != title {
+				break
+			}
+
+			return headline, nil
+		}
+	}
+
+	return utils.OrgTask{}, ErrTodoTaskNotFound
+}
+
+// setTodoKeyword rewrites the keyword of a headline and its planning line
+func setTodoKeyword(content string, line int, title, keyword string, now time.Time) (string, *TodoStateChange, error) {
+	task, err := findTodoHeadline(content, line, title)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keywords := utils.ParseOrgTodoKeywords(content)
+
+	if keyword == "" {
+		keyword = keywords.Next(task.Keyword)
+	}
+
+	isKeyword, toDone := keywords.Lookup(keyword)
+	if !isKeyword {
+		return "", nil, ErrTodoKeywordUnknown
+	}
+
+	lines := strings.Split(content, "\n")
+	change := &TodoStateChange{Title: task.Title, Keyword: keyword}
+
+	switch {
+	case toDone && !task.Done && todoTaskRepeats(task):
+		// A repeating task goes back to an open keyword with its dates moved
+		if isActive, done := keywords.Lookup(task.Keyword); !isActive || done {
+			change.Keyword = ""
+			if len(keywords.Active) > 0 {
+				change.Keyword = keywords.Active[0]
+			}
+		} else {
+			change.Keyword = task.Keyword
+		}
+
+		lines[line+1], change.RepeatedTo = repeatTodoPlanning(lines[line+1], now)
+	case toDone && !task.Done:
+		lines = setTodoClosed(lines, line, now)
+	case !toDone && task.Done:
+		lines = removeTodoClosed(lines, line)
+	}
+
+	lines[line] = replaceTodoKeyword(lines[line], task.Keyword, change.Keyword)
+
+	return strings.Join(lines, "\n"), change, nil
+}
+
+// todoTaskRepeats reports whether a task has a repeating date
+func todoTaskRepeats(task utils.OrgTask) bool {
+	return (task.Scheduled != nil && task.Scheduled.Repeater != "") ||
+		(task.Deadline != nil && task.Deadline.Repeater != "")
+}
+
+// replaceTodoKeyword swaps the keyword of a headline line, keeping the
+// priority, title and tags
+func replaceTodoKeyword(headline, oldKeyword, newKeyword string) string {
+	matches := todoHeadlinePattern.FindStringSubmatch(strings.TrimRight(headline, "\r"))
+	if matches == nil {
+		return headline
+	}
+
+	rest := matches[2]
+	if oldKeyword != "" {
+		rest = strings.TrimLeft(strings.TrimPrefix(rest, oldKeyword), " \t")
+	}
+
+	parts := []string{matches[1]}
+	if newKeyword != "" {
+		parts = append(parts, newKeyword)
+	}
+
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// hasTodoPlanningLine reports whether the headline at a line is followed by
+// a planning line
+func hasTodoPlanningLine(lines []string, line int) bool {
+	return line+1 < len(lines) && todoPlanningLinePattern.MatchString(lines[line+1])
+}
+
+// setTodoClosed stamps the CLOSED time on the planning line of a headline,
+// adding the line when there is none
+func setTodoClosed(lines []string, line int, now time.Time) []string {
+	closed := "CLOSED: [" + now.Format(todoTimestampFormat) + "]"
+
+	if !hasTodoPlanningLine(lines, line) {
+		return slices.Insert(lines, line+1, closed)
+	}
+
+	planning := lines[line+1]
+	if todoClosedPattern.MatchString(planning) {
+		lines[line+1] = todoClosedPattern.ReplaceAllString(planning, closed+" ")
+		lines[line+1] = strings.TrimRight(lines[line+1], " ")
+
+		return lines
+	}
+
+	indent := planning[:len(planning)-len(strings.TrimLeft(planning, " \t"))]
+	lines[line+1] = indent + closed + " " + strings.TrimLeft(planning, " \t")
+
+	return lines
+}
+
+// removeTodoClosed drops the CLOSED time of a headline, along with the
+// planning line when nothing else is left on it
+func removeTodoClosed(lines []string, line int) []string {
+	if !hasTodoPlanningLine(lines, line) {
+		return lines
+	}
+
+	planning := strings.TrimRight(todoClosedPattern.ReplaceAllString(lines[line+1], ""), " \t\r")
+	if strings.TrimSpace(planning) == "" {
+		return slices.Delete(lines, line+1, line+2)
+	}
+
+	lines[line+1] = planning
+
+	return lines
+}
+
+// repeatTodoPlanning moves every repeating SCHEDULED and DEADLINE timestamp
+// of a planning line on by its repeater, returning the earliest new date
+func repeatTodoPlanning(planning string, now time.Time) (string, *time.Time) {
+	var earliest *time.Time
+
+	updated := todoRepeatablePattern.ReplaceAllStringFunc(planning, func(match string) string {
+		parts := todoRepeatablePattern.FindStringSubmatch(match)
+
+		ts, ok := utils.ParseOrgTimestamp(parts[3])
+		if !ok {
+			return match
+		}
+
+		next, ok := ts.RepeatAfter(now)
+		if !ok {
+			return match
+		}
+
+		if earliest == nil || next.Before(*earliest) {
+			earliest = &next
+		}
+
+		fields := strings.Fields(parts[3])
+		fields[0] = next.Format("2006-01-02")
+
+		for i, field := range fields[1:] {
+			switch {
+			case todoDayNamePattern.MatchString(field):
+				fields[i+1] = next.Format("Mon")
+			case ts.HasTime && todoClockPattern.MatchString(field):
+				fields[i+1] = todoClockPattern.ReplaceAllString(field, next.Format("15:04"))
+			}
+		}
+
+		return parts[1] + ":" + parts[2] + "<" + strings.Join(fields, " ") + ">"
+	})
+
+	return updated, earliest
+}
+
+// todoTags keeps the words of a tag list that are valid org tags
+func todoTags(raw []string) []string {
+	var tags []string
+
+	for _, entry := range raw {
+		for _, tag := range strings.FieldsFunc(entry, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ',' || r == ':'
+		}) {
+			valid := true
+
+			for _, r := range tag {
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_@#%", r) {
+					valid = false
+					break
+				}
+			}
+
+			if valid && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+// insertTodoTask adds a task as the last child of a headline, or at the end
+// of the file when parentLine is negative
+func insertTodoTask(content string, parentLine int, parentTitle string, task NewTodoTask) (string, error) {
+	title := strings.Join(strings.Fields(task.Title), " ")
+	if title == "" {
+		return "", ErrTodoTitleRequired
+	}
+
+	lines := strings.Split(content, "\n")
+	level := 1
+	insertAt := len(lines)
+	after := -1
+
+	if parentLine >= 0 {
+		parent, err := findTodoHeadline(content, parentLine, parentTitle)
+		if err != nil {
+			return "", err
+		}
+
+		level = parent.Level + 1
+		after = parentLine
+
+		for _, headline := range utils.ParseOrgHeadlines(content) {
+			if headline.Line > parentLine && headline.Level <= parent.Level {
+				insertAt = headline.Line
+				break
+			}
+		}
+	}
+
+	// Keep blank lines that end the subtree or file after the new task
+	for insertAt-1 > after && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+
+	keyword := "TODO"
+	if keywords := utils.ParseOrgTodoKeywords(content); len(keywords.Active) > 0 {
+		keyword = keywords.Active[0]
+	}
+
+	headline := strings.Repeat("*", level) + " " + keyword
+
+	if priority := strings.ToUpper(strings.TrimSpace(task.Priority)); len(priority) == 1 && priority[0] >= 'A' && priority[0] <= 'Z' {
+		headline += " [#" + priority + "]"
+	}
+
+	headline += " " + title
+
+	if tags := todoTags(task.Tags); len(tags) > 0 {
+		headline += " :" + strings.Join(tags, ":") + ":"
+	}
+
+	newLines := []string{headline}
+
+	var planning []string
+	if task.Deadline != nil {
+		planning = append(planning, "DEADLINE: <"+task.Deadline.Format(todoDateFormat)+">")
+	}
+
+	if task.Scheduled != nil {
+		planning = append(planning, "SCHEDULED: <"+task.Scheduled.Format(todoDateFormat)+">")
+	}
+
+	if len(planning) > 0 {
+		newLines = append(newLines, strings.Join(planning, " "))
+	}
+
+	lines = slices.Insert(lines, insertAt, newLines...)
+
+	updated := strings.Join(lines, "\n")
+	if !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const todoEditTestFile = "#+TITLE: Tasks\n" +
+	"* Work\n" +
+	"** TODO [#A] Ship report :urgent:\n" +
+	"   DEADLINE: <2025-03-05 Wed>\n" +
+	"** DONE Old thing\n" +
+	"   CLOSED: [2025-03-02 Sun 11:00] SCHEDULED: <2025-03-01 Sat>\n" +
+	"** TODO Weekly sync\n" +
+	"   SCHEDULED: <2025-03-10 Mon 10:00 +1w> DEADLINE: <2025-03-11 Tue .+2d>\n" +
+	"\n" +
+	"* Home\n" +
+	"** HOLD Paint fence\n"
+
+func TestSetTodoKeywordClosesAndReopens(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 12, 15, 4, 0, 0, time.UTC)
+
+	done, change, err := setTodoKeyword(todoEditTestFile, 2, "Ship report", "DONE", now)
+	if err != nil {
+		t.Fatalf("setTodoKeyword failed: %v", err)
+	}
+
+	want := "\n** DONE [#A] Ship report :urgent:\n   CLOSED: [2025-03-12 Wed 15:04] DEADLINE: <2025-03-05 Wed>\n"
+	if !strings.Contains(done, want) {
+		t.Fatalf("unexpected file:\n%s", done)
+	}
+
+	if change.Keyword != "DONE" || change.RepeatedTo != nil {
+		t.Fatalf("unexpected change: %#v", change)
+	}
+
+	reopened, _, err := setTodoKeyword(todoEditTestFile, 4, "Old thing", "TODO", now)
+	if err != nil {
+		t.Fatalf("setTodoKeyword failed: %v", err)
+	}
+
+	if want := "** TODO Old thing\n   SCHEDULED: <2025-03-01 Sat>\n"; !strings.Contains(reopened, want) {
+		t.Fatalf("expected CLOSED to be removed, got:\n%s", reopened)
+	}
+
+	added, _, err := setTodoKeyword(todoEditTestFile, 10, "Paint fence", "KILL", now)
+	if err != nil {
+		t.Fatalf("setTodoKeyword failed: %v", err)
+	}
+
+	if want := "** KILL Paint fence\nCLOSED: [2025-03-12 Wed 15:04]\n"; !strings.Contains(added, want) {
+		t.Fatalf("expected a planning line to be added, got:\n%s", added)
+	}
+}
+
+func TestSetTodoKeywordCyclesAndRepeats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 12, 15, 4, 0, 0, time.UTC)
+
+	cycled, change, err := setTodoKeyword(todoEditTestFile, 10, "Paint fence", "", now)
+	if err != nil || change.Keyword != "DONE" || !strings.Contains(cycled, "** DONE Paint fence\nCLOSED:") {
+		t.Fatalf("expected HOLD to cycle to DONE, got %#v (%v):\n%s", change, err, cycled)
+	}
+
+	repeated, change, err := setTodoKeyword(todoEditTestFile, 6, "Weekly sync", "DONE", now)
+	if err != nil {
+		t.Fatalf("setTodoKeyword failed: %v", err)
+	}
+
+	want := "** TODO Weekly sync\n   SCHEDULED: <2025-03-17 Mon 10:00 +1w> DEADLINE: <2025-03-14 Fri .+2d>\n"
+	if !strings.Contains(repeated, want) {
+		t.Fatalf("expected the dates to move on, got:\n%s", repeated)
+	}
+
+	if change.Keyword != "TODO" || change.RepeatedTo == nil || change.RepeatedTo.Format("2006-01-02") != "2025-03-14" {
+		t.Fatalf("unexpected change: %#v", change)
+	}
+
+	if _, _, err := setTodoKeyword(todoEditTestFile, 6, "Weekly sync", "LATER", now); !errors.Is(err, ErrTodoKeywordUnknown) {
+		t.Fatalf("expected ErrTodoKeywordUnknown, got %v", err)
+	}
+
+	if _, _, err := setTodoKeyword(todoEditTestFile, 6, "Moved", "DONE", now); !errors.Is(err, ErrTodoTaskNotFound) {
+		t.Fatalf("expected ErrTodoTaskNotFound, got %v", err)
+	}
+}
+
+func TestInsertTodoTask(t *testing.T) {
+	t.Parallel()
+
+	scheduled := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	updated, err := insertTodoTask(todoEditTestFile, 1, "Work", NewTodoTask{
+		Title:     "  Write   tests ",
+		Priority:  "b",
+		Tags:      []string{"dev, go", "bad tag!"},
+		Scheduled: &scheduled,
+	})
+	if err != nil {
+		t.Fatalf("insertTodoTask failed: %v", err)
+	}
+
+	want := "DEADLINE: <2025-03-11 Tue .+2d>\n** TODO [#B] Write tests :dev:go:bad:\nSCHEDULED: <2025-03-14 Fri>\n\n* Home\n"
+	if !strings.Contains(updated, want) {
+		t.Fatalf("expected the task at the end of the Work subtree, got:\n%s", updated)
+	}
+
+	appended, err := insertTodoTask(todoEditTestFile, -1, "", NewTodoTask{Title: "Call bank"})
+	if err != nil {
+		t.Fatalf("insertTodoTask failed: %v", err)
+	}
+
+	if !strings.HasSuffix(appended, "\n** HOLD Paint fence\n* TODO Call bank\n") {
+		t.Fatalf("expected the task at the end of the file, got:\n%s", appended)
+	}
+
+	if _, err := insertTodoTask(todoEditTestFile, -1, "", NewTodoTask{Title: " "}); !errors.Is(err, ErrTodoTitleRequired) {
+		t.Fatalf("expected ErrTodoTitleRequired, got %v", err)
+	}
+
+	if _, err := insertTodoTask(todoEditTestFile, 1, "Home", NewTodoTask{Title: "x"}); !errors.Is(err, ErrTodoTaskNotFound) {
+		t.Fatalf("expected ErrTodoTaskNotFound, got %v", err)
+	}
+}
@@ -4,9 +4,15 @@
 package db
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestGetTodoNote(t *testing.T) {
@@ -19,7 +25,7 @@ func TestGetTodoNote(t *testing.T) {
 		}
 
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("#+TITLE: Tasks\n* TODO Test"))
+		_, _ = w.Write([]byte("#+TITLE: Tasks\n* TODO [#A] Test :work:\n  SCHEDULED: <2025-03-01 Sat>"))
 	}))
 	defer server.Close()
 
@@ -35,4 +41,80 @@ func TestGetTodoNote(t *testing.T) {
 	if note.Title != "Tasks" {
 		t.Fatalf("expected title Tasks, got %q", note.Title)
 	}
+
+	if len(note.Tasks) != 1 || note.Tasks[0].Title != "Test" || note.Tasks[0].Priority != "A" || note.Tasks[0].Scheduled == nil {
+		t.Fatalf("expected the headline to be parsed into a task, got %#v", note.Tasks)
+	}
+}
+
+func TestSetTodoStateWritesBackWithETag(t *testing.T) {
+	resetDatabase(t)
+
+	var (
+		mu      sync.Mutex
+		content = "* TODO Test\n* TODO Other\n"
+		version = 1
+	)
+
+	etag := func() string { return `"v` + strconv.Itoa(version) + `"` }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag())
+			_, _ = w.Write([]byte(content))
+		case http.MethodPut:
+			if r.Header.Get("If-Match") != etag() {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			content = string(body)
+			version++
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("WEBDAV_TODO_PATH", server.URL+"/todo.org")
+	t.Setenv("WEBDAV_USERNAME", "")
+	t.Setenv("WEBDAV_PASSWORD", "")
+
+	now := time.Date(2025, time.March, 12, 9, 30, 0, 0, time.UTC)
+
+	note, err := GetTodoNote(testContext())
+	if err != nil {
+		t.Fatalf("GetTodoNote failed: %v", err)
+	}
+
+	change, err := SetTodoState(testContext(), 0, "Test", "DONE", note.ETag, now)
+	if err != nil {
+		t.Fatalf("SetTodoState failed: %v", err)
+	}
+
+	if change.Keyword != "DONE" || content != "* DONE Test\nCLOSED: [2025-03-12 Wed 09:30]\n* TODO Other\n" {
+		t.Fatalf("unexpected write back %#v:\n%s", change, content)
+	}
+
+	// The page was loaded before the write, so its ETag is stale
+	if _, err := SetTodoState(testContext(), 2, "Other", "DONE", note.ETag, now); !errors.Is(err, ErrTodoConflict) {
+		t.Fatalf("expected ErrTodoConflict, got %v", err)
+	}
+
+	if err := AddTodoTask(testContext(), -1, "", NewTodoTask{Title: "New"}, ""); !errors.Is(err, ErrTodoETagRequired) {
+		t.Fatalf("expected ErrTodoETagRequired, got %v", err)
+	}
+
+	if err := AddTodoTask(testContext(), 2, "Other", NewTodoTask{Title: "Child"}, etag()); err != nil {
+		t.Fatalf("AddTodoTask failed: %v", err)
+	}
+
+	if !strings.HasSuffix(content, "* TODO Other\n** TODO Child\n") {
+		t.Fatalf("expected the child task under Other, got:\n%s", content)
+	}
 }
@@ -175,11 +175,13 @@ func (h *simpleWebDAVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
 func handleGetFile(w http.ResponseWriter, r *http.Request, fsPath string) {
-	if _, err := os.Stat(fsPath); err != nil {
+	info, err := os.Stat(fsPath)
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
+	w.Header().Set("ETag", fmt.Sprintf("\"%d\"", info.ModTime().UnixNano()))
 	http.ServeFile(w, r, fsPath)
 }
 
@@ -189,6 +191,13 @@ func (h *simpleWebDAVHandler) handlePut(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	if strings.TrimSpace(r.Header.Get("If-None-Match")) == "*" {
+		if _, err := os.Stat(fsPath); err == nil {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+	}
+
 	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
 	if ifMatch != "" {
 		info, err := os.Stat(fsPath)
@@ -56,7 +56,27 @@ func FindContactByPhone(ctx context.Context, phoneNumber string) (*string, error
 		return nil, nil //nolint:nilnil // Empty input is treated as no lookup result.
 	}
 
-	// First, try to find in local contact_phones table
+	contactID, err := findLocalContactByPhone(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	if contactID != "" {
+		return &contactID, nil
+	}
+
+	// Not found in local database, check CardDAV
+	cardDAVContactID, cardDAVErr := findContactByCardDAVPhone(ctx, normalized)
+	if cardDAVErr == nil && cardDAVContactID != "" {
+		return &cardDAVContactID, nil
+	}
+
+	return nil, nil //nolint:nilnil // No match is a valid, non-error outcome.
+}
+
+// findLocalContactByPhone searches the contact_phones table for a normalized
+// phone number. Returns an empty string if no contact matches.
+func findLocalContactByPhone(ctx context.Context, normalized string) (string, error) {
 	// Query for contacts with matching phone numbers
 	// Uses suffix matching to handle country code differences
 	// Orders by tier (A first) to prefer higher-priority contacts
@@ -93,38 +113,44 @@ func FindContactByPhone(ctx context.Context, phoneNumber string) (*string, error
 	var contactID string
 
 	err := pool.QueryRow(ctx, query, normalized).Scan(&contactID)
-	if err == nil {
-		return &contactID, nil
-	}
-
-	if !errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("failed to find contact by phone: %w", err)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
 	}
 
-	// Not found in local database, check CardDAV
-	cardDAVContactID, cardDAVErr := findContactByCardDAVPhone(ctx, normalized)
-	if cardDAVErr == nil && cardDAVContactID != "" {
-		return &cardDAVContactID, nil
+	if err != nil {
+		return "", fmt.Errorf("failed to find contact by phone: %w", err)
 	}
 
-	return nil, nil //nolint:nilnil // No match is a valid, non-error outcome.
+	return contactID, nil
 }
 
 // findContactByCardDAVPhone searches for a contact by checking CardDAV phone numbers.
 // Returns the contact ID if found, empty string otherwise.
 func findContactByCardDAVPhone(ctx context.Context, normalizedPhone string) (string, error) {
-	// Check if CardDAV is configured
-	_, err := GetCardDAVConfig()
+	index, err := loadCardDAVPhoneIndex(ctx)
 	if err != nil {
-		// CardDAV not configured, skip
-		return "", nil
+		return "", err
 	}
 
-	// Get all contacts with CardDAV UUIDs
+	return index.find(normalizedPhone), nil
+}
+
+// cardDAVPhoneIndex holds the card phones of CardDAV-linked contacts, so
+// several numbers can be matched with a single fetch per source
+type cardDAVPhoneIndex struct {
+	// contactIDs is in tier priority order
+	contactIDs []string
+	phones     map[string][]string
+}
+
+// loadCardDAVPhoneIndex fetches the cards of every source with linked
+// contacts once and indexes their phones by contact
+func loadCardDAVPhoneIndex(ctx context.Context) (*cardDAVPhoneIndex, error) {
+	// Get all contacts linked to a card in a CardDAV source
 	query := `
-		SELECT id, carddav_uuid, tier
+		SELECT id, carddav_uuid, carddav_source_id::text, tier
 		FROM contacts
-		WHERE carddav_uuid IS NOT NULL
+		WHERE carddav_uuid IS NOT NULL AND carddav_source_id IS NOT NULL
 		ORDER BY
 			CASE tier
 				WHEN 'A' THEN 1
@@ -139,13 +165,14 @@ func findContactByCardDAVPhone(ctx context.Context, normalizedPhone string) (str
 
 	rows, err := pool.Query(ctx, query)
 	if err != nil {
-		return "", fmt.Errorf("failed to query CardDAV-linked contacts: %w", err)
+		return nil, fmt.Errorf("failed to query CardDAV-linked contacts: %w", err)
 	}
 	defer rows.Close()
 
 	type cardDAVContact struct {
 		id          string
 		cardDAVUUID string
+		sourceID    string
 		tier        string
 	}
 
@@ -153,47 +180,68 @@ func findContactByCardDAVPhone(ctx context.Context, normalizedPhone string) (str
 
 	for rows.Next() {
 		var c cardDAVContact
-		if err := rows.Scan(&c.id, &c.cardDAVUUID, &c.tier); err != nil {
+		if err := rows.Scan(&c.id, &c.cardDAVUUID, &c.sourceID, &c.tier); err != nil {
 			continue
 		}
 
 		contacts = append(contacts, c)
 	}
 
+	index := &cardDAVPhoneIndex{phones: make(map[string][]string)}
 	if len(contacts) == 0 {
-		return "", nil
-	}
-
-	// Fetch all CardDAV contacts once
-	cardDAVContacts, err := ListCardDAVContacts(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch CardDAV contacts: %w", err)
+		return index, nil
 	}
 
-	// Build a map of CardDAV UUID to phones for quick lookup
+	// Fetch the CardDAV contacts of each source once, building a map of
+	// source and UUID to phones for quick lookup
+	fetched := make(map[string]bool)
 	cardDAVPhones := make(map[string][]string)
 
-	for _, cdContact := range cardDAVContacts {
-		for _, phone := range cdContact.Phones {
-			cardDAVPhones[cdContact.UUID] = append(cardDAVPhones[cdContact.UUID], phone.Phone)
+	for _, c := range contacts {
+		if fetched[c.sourceID] {
+			continue
+		}
+
+		fetched[c.sourceID] = true
+
+		cardDAVContacts, err := ListCardDAVContacts(ctx, c.sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch CardDAV contacts: %w", err)
+		}
+
+		for _, cdContact := range cardDAVContacts {
+			key := c.sourceID + "/" + cdContact.UUID
+			for _, phone := range cdContact.Phones {
+				cardDAVPhones[key] = append(cardDAVPhones[key], normalizePhone(phone.Phone))
+			}
 		}
 	}
 
-	// Check each local contact's CardDAV phones
 	for _, c := range contacts {
-		phones, ok := cardDAVPhones[c.cardDAVUUID]
+		phones, ok := cardDAVPhones[c.sourceID+"/"+c.cardDAVUUID]
 		if !ok {
 			continue
 		}
 
-		for _, phone := range phones {
-			if phonesMatch(normalizedPhone, normalizePhone(phone)) {
-				return c.id, nil
+		index.contactIDs = append(index.contactIDs, c.id)
+		index.phones[c.id] = phones
+	}
+
+	return index, nil
+}
+
+// find returns the highest priority contact whose card has the phone, or an
+// empty string
+func (idx *cardDAVPhoneIndex) find(normalizedPhone string) string {
+	for _, contactID := range idx.contactIDs {
+		for _, phone := range idx.phones[contactID] {
+			if phonesMatch(normalizedPhone, phone) {
+				return contactID
 			}
 		}
 	}
 
-	return "", nil
+	return ""
 }
 
 // phonesMatch checks if two normalized phone numbers match.
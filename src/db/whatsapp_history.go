@@ -0,0 +1,119 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// WhatsAppHistoryMessage is a past direct-chat message delivered by a
+// WhatsApp history sync
+type WhatsAppHistoryMessage struct {
+	MessageID  string
+	Phone      string
+	SentAt     time.Time
+	IsOutgoing bool
+	Message    string
+	// ReplyToMessageID is the WhatsApp ID of the message replied to
+	ReplyToMessageID string
+	Media            *ChatMedia
+}
+
+// WhatsAppHistoryImport summarises one imported history sync blob
+type WhatsAppHistoryImport struct {
+	Contacts   int // Contacts that had messages in the blob
+	Imported   int // Messages added to contact chats
+	Duplicates int // Messages already in contact chats
+	Unknown    int // Numbers matching no contact, queued in the inbox
+}
+
+// ImportWhatsAppHistory maps history sync messages to contacts by phone,
+// adds the ones not already in their chats and moves last_auto_contact up to
+// the latest message. Messages of unmatched numbers go to the unknown
+// correspondents inbox.
+func ImportWhatsAppHistory(ctx context.Context, messages []WhatsAppHistoryMessage) (WhatsAppHistoryImport, error) {
+	var result WhatsAppHistoryImport
+
+	if pool == nil {
+		return result, ErrDatabaseConnectionNotInitialized
+	}
+
+	var phones []string
+
+	byPhone := make(map[string][]WhatsAppHistoryMessage)
+
+	for _, message := range messages {
+		phone := normalizePhone(message.Phone)
+		if phone == "" {
+			continue
+		}
+
+		if _, ok := byPhone[phone]; !ok {
+			phones = append(phones, phone)
+		}
+
+		byPhone[phone] = append(byPhone[phone], message)
+	}
+
+	// CardDAV cards are only fetched once, and only if a number is not
+	// found locally
+	var cardDAVIndex *cardDAVPhoneIndex
+
+	for _, phone := range phones {
+		contactID, err := findLocalContactByPhone(ctx, phone)
+		if err != nil {
+			return result, err
+		}
+
+		if contactID == "" {
+			if cardDAVIndex == nil {
+				cardDAVIndex, err = loadCardDAVPhoneIndex(ctx)
+				if err != nil {
+					logger.Warn("Failed to load CardDAV phones for WhatsApp history", "error", err)
+
+					cardDAVIndex = &cardDAVPhoneIndex{}
+				}
+			}
+
+			contactID = cardDAVIndex.find(phone)
+		}
+
+		if contactID == "" {
+			for _, message := range byPhone[phone] {
+				if err := RecordUnknownWhatsAppMessage(ctx, phone, message.MessageID, message.SentAt, message.IsOutgoing, message.Message); err != nil {
+					return result, err
+				}
+			}
+
+			result.Unknown++
+
+			continue
+		}
+
+		history := make([]ChatArchiveMessage, 0, len(byPhone[phone]))
+		for _, message := range byPhone[phone] {
+			history = append(history, ChatArchiveMessage{
+				MessageID:        message.MessageID,
+				SentAt:           message.SentAt,
+				IsOutgoing:       message.IsOutgoing,
+				Message:          message.Message,
+				ReplyToMessageID: message.ReplyToMessageID,
+				Media:            message.Media,
+			})
+		}
+
+		imported, duplicates, err := importContactChatHistory(ctx, contactID, ChatPlatformWhatsApp, history)
+		if err != nil {
+			return result, err
+		}
+
+		result.Contacts++
+		result.Imported += imported
+		result.Duplicates += duplicates
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImportWhatsAppHistory(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	first := time.Date(2025, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	phone := "+971 50 765 4321"
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Noor", Phone: &phone, Tier: TierB})
+
+	// A chat logged live before message IDs were kept
+	legacySentAt := first.Format(time.RFC3339Nano)
+	if err := AddChat(ctx, AddChatInput{ContactID: contactID, Platform: ChatPlatformWhatsApp, Sender: ChatSenderThem, Message: "Morning!", SentAt: &legacySentAt}); err != nil {
+		t.Fatalf("AddChat failed: %v", err)
+	}
+
+	if err := UpdateContactAutoTimestamp(ctx, contactID, first); err != nil {
+		t.Fatalf("UpdateContactAutoTimestamp failed: %v", err)
+	}
+
+	history := []WhatsAppHistoryMessage{
+		{MessageID: "h1", Phone: "971507654321", SentAt: first, Message: "Morning!"},
+		{MessageID: "h2", Phone: "971507654321", SentAt: first.Add(time.Hour), IsOutgoing: true, Message: "Hi Noor"},
+		{MessageID: "h3", Phone: "971507654321", SentAt: first.Add(48 * time.Hour), Message: "Lunch?"},
+		{MessageID: "h4", Phone: "447700900123", SentAt: first, Message: "Who is this?"},
+	}
+
+	result, err := ImportWhatsAppHistory(ctx, history)
+	if err != nil {
+		t.Fatalf("ImportWhatsAppHistory failed: %v", err)
+	}
+
+	if result != (WhatsAppHistoryImport{Contacts: 1, Imported: 2, Duplicates: 1, Unknown: 1}) {
+		t.Fatalf("unexpected import result %+v", result)
+	}
+
+	// The same blob delivered again adds nothing
+	result, err = ImportWhatsAppHistory(ctx, history)
+	if err != nil {
+		t.Fatalf("ImportWhatsAppHistory failed: %v", err)
+	}
+
+	if result.Imported != 0 || result.Duplicates != 3 {
+		t.Fatalf("expected a repeated import to only find duplicates, got %+v", result)
+	}
+
+	chats, err := GetContactChats(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContactChats failed: %v", err)
+	}
+
+	if len(chats) != 3 || chats[0].Message != "Lunch?" {
+		t.Fatalf("unexpected chats %+v", chats)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.LastAutoContact == nil || !contact.LastAutoContact.Equal(first.Add(48*time.Hour)) {
+		t.Fatalf("expected last auto contact at the latest message, got %v", contact.LastAutoContact)
+	}
+
+	// Older history never moves last_auto_contact back
+	if _, err := ImportWhatsAppHistory(ctx, []WhatsAppHistoryMessage{
+		{MessageID: "h0", Phone: "971507654321", SentAt: first.Add(-24 * time.Hour), Message: "Earlier"},
+	}); err != nil {
+		t.Fatalf("ImportWhatsAppHistory failed: %v", err)
+	}
+
+	contact, err = GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if !contact.LastAutoContact.Equal(first.Add(48 * time.Hour)) {
+		t.Fatalf("expected last auto contact to stay at the latest message, got %v", contact.LastAutoContact)
+	}
+
+	numbers, err := ListUnknownWhatsAppNumbers(ctx)
+	if err != nil {
+		t.Fatalf("ListUnknownWhatsAppNumbers failed: %v", err)
+	}
+
+	if len(numbers) != 1 || numbers[0].Phone != "447700900123" || numbers[0].MessageCount != 1 {
+		t.Fatalf("expected the unknown number queued once, got %+v", numbers)
+	}
+}
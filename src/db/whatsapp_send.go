@@ -0,0 +1,196 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// phoneLinkRegex matches the number in a WhatsApp click-to-chat link
+var phoneLinkRegex = regexp.MustCompile(`^\+?[\d -]{7,}$`)
+
+// whatsAppMessagePlaceholders are replaced per recipient when rendering a
+// message
+const (
+	whatsAppPlaceholderName      = "{name}"
+	whatsAppPlaceholderFirstName = "{first_name}"
+)
+
+// WhatsAppRecipient is a contact a WhatsApp message can be sent to
+type WhatsAppRecipient struct {
+	ContactID string
+	Name      string // Display name
+	GivenName string
+	// Phone is the number messages go to, empty when the contact has none
+	Phone string
+}
+
+// FirstName returns the given name, or the display name when there is none
+func (r WhatsAppRecipient) FirstName() string {
+	if r.GivenName != "" {
+		return r.GivenName
+	}
+
+	return r.Name
+}
+
+// RenderWhatsAppMessage fills the {name} and {first_name} placeholders of a
+// message for one recipient
+func RenderWhatsAppMessage(message string, recipient WhatsAppRecipient) string {
+	replacer := strings.NewReplacer(
+		whatsAppPlaceholderName, recipient.Name,
+		whatsAppPlaceholderFirstName, recipient.FirstName(),
+	)
+
+	return strings.TrimSpace(replacer.Replace(message))
+}
+
+// GetWhatsAppRecipient returns the contact with the number messages to it
+// are sent to
+func GetWhatsAppRecipient(ctx context.Context, contactID string) (WhatsAppRecipient, error) {
+	if pool == nil {
+		return WhatsAppRecipient{}, ErrDatabaseConnectionNotInitialized
+	}
+
+	parsedID, err := uuid.Parse(strings.TrimSpace(contactID))
+	if err != nil {
+		return WhatsAppRecipient{}, ErrContactNotFound
+	}
+
+	recipients, err := queryWhatsAppRecipients(ctx, `c.id = $1`, parsedID)
+	if err != nil {
+		return WhatsAppRecipient{}, err
+	}
+
+	if len(recipients) == 0 {
+		return WhatsAppRecipient{}, ErrContactNotFound
+	}
+
+	return recipients[0], nil
+}
+
+// ListTagWhatsAppRecipients returns the contacts with a tag, other than the
+// "me" contact, with the numbers messages to them are sent to
+func ListTagWhatsAppRecipients(ctx context.Context, tagID string) ([]WhatsAppRecipient, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	parsedID, err := uuid.Parse(strings.TrimSpace(tagID))
+	if err != nil {
+		return nil, ErrTagNotFound
+	}
+
+	return queryWhatsAppRecipients(ctx, `
+		NOT c.is_me AND c.id IN (SELECT contact_id FROM contact_tags WHERE tag_id = $1)
+	`, parsedID)
+}
+
+// queryWhatsAppRecipients loads the contacts matching a condition. A number
+// from a WhatsApp link wins over the primary phone, then mobile numbers.
+func queryWhatsAppRecipients(ctx context.Context, condition string, args ...any) ([]WhatsAppRecipient, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			c.id,
+			c.name_display,
+			COALESCE(c.name_given, ''),
+			COALESCE((SELECT u.url FROM contact_urls u
+			 WHERE u.contact_id = c.id AND u.url_type = 'whatsapp'
+			 ORDER BY u.created_at LIMIT 1), ''),
+			COALESCE((SELECT p.phone FROM contact_phones p
+			 WHERE p.contact_id = c.id
+			 ORDER BY p.is_primary DESC, p.phone_type = 'cell' DESC, p.created_at LIMIT 1), '')
+		FROM contacts c
+		WHERE `+condition+`
+		ORDER BY c.tier ASC, c.name_display ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query WhatsApp recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []WhatsAppRecipient
+
+	for rows.Next() {
+		var (
+			recipient   WhatsAppRecipient
+			link, phone string
+		)
+
+		if err := rows.Scan(&recipient.ContactID, &recipient.Name, &recipient.GivenName, &link, &phone); err != nil {
+			return nil, fmt.Errorf("failed to scan WhatsApp recipient: %w", err)
+		}
+
+		recipient.Phone = firstNonEmpty(whatsAppLinkPhone(link), strings.TrimSpace(phone))
+		recipients = append(recipients, recipient)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate WhatsApp recipients: %w", err)
+	}
+
+	return recipients, nil
+}
+
+// whatsAppLinkPhone extracts the number of a wa.me or api.whatsapp.com link
+func whatsAppLinkPhone(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	var phone string
+
+	switch strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.") {
+	case "wa.me":
+		phone = strings.Trim(parsed.Path, "/")
+	case "api.whatsapp.com", "web.whatsapp.com":
+		phone = parsed.Query().Get("phone")
+	}
+
+	// Business links such as wa.me/message/CODE carry no number
+	if !phoneLinkRegex.MatchString(phone) {
+		return ""
+	}
+
+	return "+" + normalizePhone(phone)
+}
+
+// RecordSentWhatsAppMessage adds a message sent from Groundwave to the
+// contact's chats and moves last_auto_contact up to it
+func RecordSentWhatsAppMessage(ctx context.Context, contactID, messageID, message string, sentAt time.Time) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	if strings.TrimSpace(message) == "" {
+		return ErrChatMessageRequired
+	}
+
+	_, _, err := importContactChatHistory(ctx, contactID, ChatPlatformWhatsApp, []ChatArchiveMessage{{
+		MessageID:  messageID,
+		SentAt:     sentAt,
+		IsOutgoing: true,
+		Message:    message,
+	}})
+
+	return err
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWhatsAppRecipients(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	home := "+971 4 123 4567"
+	family := "Ali"
+	saraID := mustCreateContact(t, CreateContactInput{NameGiven: "Sara", NameFamily: &family, Phone: &home, Tier: TierA})
+
+	if err := AddPhone(ctx, AddPhoneInput{ContactID: saraID, Phone: "+971 50 123 4567", PhoneType: PhoneCell}); err != nil {
+		t.Fatalf("AddPhone failed: %v", err)
+	}
+
+	omarID := mustCreateContact(t, CreateContactInput{NameGiven: "Omar", Tier: TierB})
+	if err := AddURL(ctx, AddURLInput{ContactID: omarID, URL: "https://wa.me/447700900123", URLType: URLWhatsApp}); err != nil {
+		t.Fatalf("AddURL failed: %v", err)
+	}
+
+	lenaID := mustCreateContact(t, CreateContactInput{NameGiven: "Lena", Tier: TierC})
+
+	meID := mustCreateContact(t, CreateContactInput{NameGiven: "Me", Phone: &home, Tier: TierA})
+	if err := SetContactAsMe(ctx, meID); err != nil {
+		t.Fatalf("SetContactAsMe failed: %v", err)
+	}
+
+	for _, contactID := range []string{saraID, omarID, lenaID, meID} {
+		if err := AddTagToContact(ctx, contactID, "friends"); err != nil {
+			t.Fatalf("AddTagToContact failed: %v", err)
+		}
+	}
+
+	sara, err := GetWhatsAppRecipient(ctx, saraID)
+	if err != nil {
+		t.Fatalf("GetWhatsAppRecipient failed: %v", err)
+	}
+
+	// The phone added first stays primary
+	if sara.Name != "Sara Ali" || sara.FirstName() != "Sara" || sara.Phone != home {
+		t.Fatalf("unexpected recipient %+v", sara)
+	}
+
+	if _, err := GetWhatsAppRecipient(ctx, "not-a-uuid"); !errors.Is(err, ErrContactNotFound) {
+		t.Fatalf("expected ErrContactNotFound, got %v", err)
+	}
+
+	tags, err := GetContactTags(ctx, omarID)
+	if err != nil || len(tags) != 1 {
+		t.Fatalf("GetContactTags failed: %v %+v", err, tags)
+	}
+
+	recipients, err := ListTagWhatsAppRecipients(ctx, tags[0].ID.String())
+	if err != nil {
+		t.Fatalf("ListTagWhatsAppRecipients failed: %v", err)
+	}
+
+	if len(recipients) != 3 {
+		t.Fatalf("expected the tagged contacts without me, got %+v", recipients)
+	}
+
+	phones := map[string]string{}
+	for _, recipient := range recipients {
+		phones[recipient.ContactID] = recipient.Phone
+	}
+
+	if phones[saraID] != home || phones[omarID] != "+447700900123" || phones[lenaID] != "" {
+		t.Fatalf("unexpected recipient phones %+v", phones)
+	}
+}
+
+func TestRecordSentWhatsAppMessage(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	sentAt := time.Date(2025, 4, 2, 9, 30, 0, 0, time.UTC)
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Sara", Tier: TierB})
+
+	for range 2 {
+		if err := RecordSentWhatsAppMessage(ctx, contactID, "3EB0SENT", "Hi Sara", sentAt); err != nil {
+			t.Fatalf("RecordSentWhatsAppMessage failed: %v", err)
+		}
+	}
+
+	chats, err := GetContactChats(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContactChats failed: %v", err)
+	}
+
+	if len(chats) != 1 || chats[0].Sender != ChatSenderMe || chats[0].Platform != ChatPlatformWhatsApp ||
+		chats[0].MessageID == nil || *chats[0].MessageID != "3EB0SENT" {
+		t.Fatalf("expected one outgoing WhatsApp chat, got %+v", chats)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.LastAutoContact == nil || !contact.LastAutoContact.Equal(sentAt) {
+		t.Fatalf("expected last_auto_contact at the sent message, got %v", contact.LastAutoContact)
+	}
+
+	if err := RecordSentWhatsAppMessage(ctx, contactID, "3EB0EMPTY", "  ", sentAt); !errors.Is(err, ErrChatMessageRequired) {
+		t.Fatalf("expected ErrChatMessageRequired, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import "testing"
+
+func TestRenderWhatsAppMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		message   string
+		recipient WhatsAppRecipient
+		want      string
+	}{
+		{
+			name:      "both placeholders",
+			message:   "Hi {first_name}! ({name})",
+			recipient: WhatsAppRecipient{Name: "Sara Ali", GivenName: "Sara"},
+			want:      "Hi Sara! (Sara Ali)",
+		},
+		{
+			name:      "first name falls back to display name",
+			message:   "Hi {first_name}",
+			recipient: WhatsAppRecipient{Name: "ACME Support"},
+			want:      "Hi ACME Support",
+		},
+		{
+			name:      "unknown placeholders are kept",
+			message:   "  See you {day}  ",
+			recipient: WhatsAppRecipient{Name: "Omar"},
+			want:      "See you {day}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := RenderWhatsAppMessage(tt.message, tt.recipient); got != tt.want {
+				t.Fatalf("RenderWhatsAppMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhatsAppLinkPhone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"https://wa.me/971501234567", "+971501234567"},
+		{"wa.me/+44 7700 900123", "+447700900123"},
+		{"https://api.whatsapp.com/send?phone=15551234567&text=hi", "+15551234567"},
+		{"https://wa.me/message/ABCDEF123456", ""},
+		{"https://example.com/971501234567", ""},
+		{"https://wa.me/123", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			t.Parallel()
+
+			if got := whatsAppLinkPhone(tt.link); got != tt.want {
+				t.Fatalf("whatsAppLinkPhone(%q) = %q, want %q", tt.link, got, tt.want)
+			}
+		})
+	}
+}
@@ -58,12 +58,10 @@ func TestFindContactByPhoneCardDAV(t *testing.T) {
 	card.Add(vcard.FieldTelephone, &vcard.Field{Value: "+1 555 0000", Params: vcard.Params{vcard.ParamType: []string{"cell"}}})
 	server.cards["card-1.vcf"] = card
 
-	t.Setenv("CARDDAV_URL", server.server.URL+"/addressbook/")
-	t.Setenv("CARDDAV_USERNAME", "user")
-	t.Setenv("CARDDAV_PASSWORD", "pass")
+	sourceID := mustCreateCardDAVSource(t, "Default", server.server.URL+"/addressbook/")
 
 	carddavID := "card-1"
-	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Card", CardDAVUUID: &carddavID, Tier: TierB})
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Card", CardDAVUUID: &carddavID, CardDAVSourceID: &sourceID, Tier: TierB})
 
 	found, err := FindContactByPhone(ctx, "555-0000")
 	if err != nil {
@@ -0,0 +1,394 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WhatsAppUnknownNumber is a direct-chat correspondent matching no contact
+type WhatsAppUnknownNumber struct {
+	Phone        string    `db:"phone"`
+	MessageCount int       `db:"message_count"`
+	FirstSeenAt  time.Time `db:"first_seen_at"`
+	LastSeenAt   time.Time `db:"last_seen_at"`
+	// QueuedMessages is the number of text messages waiting to be backfilled
+	QueuedMessages int    `db:"queued_messages"`
+	LastMessage    string `db:"last_message"`
+}
+
+// DisplayPhone returns the number in international format
+func (n WhatsAppUnknownNumber) DisplayPhone() string {
+	return "+" + n.Phone
+}
+
+// RecordUnknownWhatsAppMessage queues a message exchanged with a number that
+// matches no contact. Every message counts towards the number's activity,
+// and text messages are kept for backfilling. Ignored numbers and messages
+// already queued under the same ID are dropped.
+func RecordUnknownWhatsAppMessage(ctx context.Context, phone, messageID string, sentAt time.Time, isOutgoing bool, message string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	phone = normalizePhone(phone)
+	if phone == "" {
+		return nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back WhatsApp inbox transaction", "error", err)
+		}
+	}()
+
+	if messageID != "" {
+		var queued bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM whatsapp_unknown_messages WHERE message_id = $1)
+		`, messageID).Scan(&queued); err != nil {
+			return fmt.Errorf("failed to check queued WhatsApp message: %w", err)
+		}
+
+		if queued {
+			return nil
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO whatsapp_unknown_numbers (phone, message_count, first_seen_at, last_seen_at)
+		VALUES ($1, 1, $2, $2)
+		ON CONFLICT (phone) DO UPDATE
+		SET message_count = whatsapp_unknown_numbers.message_count + 1,
+			first_seen_at = LEAST(whatsapp_unknown_numbers.first_seen_at, EXCLUDED.first_seen_at),
+			last_seen_at = GREATEST(whatsapp_unknown_numbers.last_seen_at, EXCLUDED.last_seen_at)
+		WHERE whatsapp_unknown_numbers.ignored_at IS NULL
+		RETURNING phone
+	`, phone, sentAt).Scan(&phone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to record unknown WhatsApp number: %w", err)
+	}
+
+	if message = strings.TrimSpace(message); message != "" {
+		sender := ChatSenderThem
+		if isOutgoing {
+			sender = ChatSenderMe
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO whatsapp_unknown_messages (phone, sender, message, sent_at, message_id)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		`, phone, sender, message, sentAt, messageID)
+		if err != nil {
+			return fmt.Errorf("failed to queue unknown WhatsApp message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit unknown WhatsApp message: %w", err)
+	}
+
+	return nil
+}
+
+// ListUnknownWhatsAppNumbers returns the inbox of unmatched numbers, most
+// recently active first
+func ListUnknownWhatsAppNumbers(ctx context.Context) ([]WhatsAppUnknownNumber, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT n.phone, n.message_count, n.first_seen_at, n.last_seen_at,
+			(SELECT count(*) FROM whatsapp_unknown_messages m WHERE m.phone = n.phone),
+			COALESCE((
+				SELECT m.message FROM whatsapp_unknown_messages m
+				WHERE m.phone = n.phone
+				ORDER BY m.sent_at DESC
+				LIMIT 1
+			), '')
+		FROM whatsapp_unknown_numbers n
+		WHERE n.ignored_at IS NULL
+		ORDER BY n.last_seen_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unknown WhatsApp numbers: %w", err)
+	}
+	defer rows.Close()
+
+	var numbers []WhatsAppUnknownNumber
+
+	for rows.Next() {
+		var number WhatsAppUnknownNumber
+		if err := rows.Scan(&number.Phone, &number.MessageCount, &number.FirstSeenAt, &number.LastSeenAt,
+			&number.QueuedMessages, &number.LastMessage); err != nil {
+			return nil, fmt.Errorf("failed to scan unknown WhatsApp number: %w", err)
+		}
+
+		numbers = append(numbers, number)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unknown WhatsApp numbers: %w", err)
+	}
+
+	return numbers, nil
+}
+
+// CountUnknownWhatsAppNumbers returns how many numbers wait in the inbox
+func CountUnknownWhatsAppNumbers(ctx context.Context) (int, error) {
+	if pool == nil {
+		return 0, ErrDatabaseConnectionNotInitialized
+	}
+
+	var count int
+	if err := pool.QueryRow(ctx, `
+		SELECT count(*) FROM whatsapp_unknown_numbers WHERE ignored_at IS NULL
+	`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unknown WhatsApp numbers: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateContactFromWhatsAppNumber creates a contact for a number in the
+// inbox and backfills its queued messages. An empty name falls back to the
+// number itself. The number stays locked until the contact is committed, so
+// a repeated or racing submit cannot create a second contact.
+func CreateContactFromWhatsAppNumber(ctx context.Context, phone, name string) (string, int, error) {
+	if pool == nil {
+		return "", 0, ErrDatabaseConnectionNotInitialized
+	}
+
+	phone = normalizePhone(phone)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "+" + phone
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back WhatsApp inbox transaction", "error", err)
+		}
+	}()
+
+	lastSeen, err := lockWhatsAppNumber(ctx, tx, phone)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var contactID string
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO contacts (name_display, name_given, tier)
+		VALUES ($1, $1, $2)
+		RETURNING id
+	`, name, TierC).Scan(&contactID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to insert contact: %w", err)
+	}
+
+	// The number becomes the primary phone of the new contact
+	backfilled, err := attachWhatsAppNumber(ctx, tx, phone, contactID, lastSeen)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", 0, fmt.Errorf("failed to commit WhatsApp contact: %w", err)
+	}
+
+	return contactID, backfilled, nil
+}
+
+// AttachWhatsAppNumber adds a number from the inbox to an existing contact,
+// moves its queued messages into the contact's chats and removes it from the
+// inbox. It returns the number of messages backfilled.
+func AttachWhatsAppNumber(ctx context.Context, phone, contactID string) (int, error) {
+	if pool == nil {
+		return 0, ErrDatabaseConnectionNotInitialized
+	}
+
+	if uuid.Validate(contactID) != nil {
+		return 0, ErrContactNotFound
+	}
+
+	phone = normalizePhone(phone)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back WhatsApp inbox transaction", "error", err)
+		}
+	}()
+
+	lastSeen, err := lockWhatsAppNumber(ctx, tx, phone)
+	if err != nil {
+		return 0, err
+	}
+
+	backfilled, err := attachWhatsAppNumber(ctx, tx, phone, contactID, lastSeen)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit WhatsApp number: %w", err)
+	}
+
+	return backfilled, nil
+}
+
+// lockWhatsAppNumber locks a number waiting in the inbox until the
+// transaction ends, returning when it was last seen
+func lockWhatsAppNumber(ctx context.Context, tx pgx.Tx, phone string) (time.Time, error) {
+	var lastSeen time.Time
+
+	err := tx.QueryRow(ctx, `
+		SELECT last_seen_at FROM whatsapp_unknown_numbers
+		WHERE phone = $1 AND ignored_at IS NULL
+		FOR UPDATE
+	`, phone).Scan(&lastSeen)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, ErrWhatsAppNumberNotFound
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load unknown WhatsApp number: %w", err)
+	}
+
+	return lastSeen, nil
+}
+
+// attachWhatsAppNumber adds a locked number to a contact, backfills its
+// messages and removes it from the inbox within the transaction
+func attachWhatsAppNumber(ctx context.Context, tx pgx.Tx, phone, contactID string, lastSeen time.Time) (int, error) {
+	var isService bool
+
+	err := tx.QueryRow(ctx, `SELECT is_service FROM contacts WHERE id = $1`, contactID).Scan(&isService)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrContactNotFound
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to load contact: %w", err)
+	}
+
+	// Add the number unless the contact already has it in another format
+	_, err = tx.Exec(ctx, `
+		INSERT INTO contact_phones (contact_id, phone, phone_type, is_primary)
+		SELECT $1::uuid, $2::text, 'cell'::phone_type,
+			NOT EXISTS(SELECT 1 FROM contact_phones WHERE contact_id = $1::uuid AND is_primary = true)
+		WHERE NOT EXISTS(
+			SELECT 1 FROM contact_phones
+			WHERE contact_id = $1::uuid AND REGEXP_REPLACE(phone, '[^\d]', '', 'g') = $3
+		)
+	`, contactID, "+"+phone, phone)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add WhatsApp number to contact: %w", err)
+	}
+
+	// Service contacts keep no chat history, like live messages
+	var backfilled int64
+
+	if !isService {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO contact_chats (contact_id, platform, sender, message, sent_at, message_id)
+			SELECT $1::uuid, $2::chat_platform, sender, message, sent_at, message_id
+			FROM whatsapp_unknown_messages
+			WHERE phone = $3
+			ON CONFLICT (platform, message_id) WHERE message_id IS NOT NULL DO NOTHING
+		`, contactID, ChatPlatformWhatsApp, phone)
+		if err != nil {
+			return 0, fmt.Errorf("failed to backfill WhatsApp messages: %w", err)
+		}
+
+		backfilled = tag.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE contacts
+		SET last_auto_contact = GREATEST(last_auto_contact, $2), updated_at = NOW()
+		WHERE id = $1
+	`, contactID, lastSeen)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update auto contact timestamp: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM whatsapp_unknown_numbers WHERE phone = $1`, phone); err != nil {
+		return 0, fmt.Errorf("failed to remove WhatsApp number from inbox: %w", err)
+	}
+
+	return int(backfilled), nil
+}
+
+// IgnoreWhatsAppNumber drops a number's queued messages and ignores any
+// later ones
+func IgnoreWhatsAppNumber(ctx context.Context, phone string) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	phone = normalizePhone(phone)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to roll back WhatsApp inbox transaction", "error", err)
+		}
+	}()
+
+	result, err := tx.Exec(ctx, `
+		UPDATE whatsapp_unknown_numbers SET ignored_at = now()
+		WHERE phone = $1 AND ignored_at IS NULL
+	`, phone)
+	if err != nil {
+		return fmt.Errorf("failed to ignore WhatsApp number: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrWhatsAppNumberNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM whatsapp_unknown_messages WHERE phone = $1`, phone); err != nil {
+		return fmt.Errorf("failed to drop ignored WhatsApp messages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit ignored WhatsApp number: %w", err)
+	}
+
+	return nil
+}
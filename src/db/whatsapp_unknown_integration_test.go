@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUnknownWhatsAppNumberAttachBackfillsChats(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	first := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := RecordUnknownWhatsAppMessage(ctx, "+971 50 123 4567", "m1", first, false, "Hi, it's Sam"); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	if err := RecordUnknownWhatsAppMessage(ctx, "971501234567", "m2", first.Add(time.Hour), true, "Hello Sam"); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	// Media without text still counts as activity
+	if err := RecordUnknownWhatsAppMessage(ctx, "971501234567", "", first.Add(2*time.Hour), false, " "); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	numbers, err := ListUnknownWhatsAppNumbers(ctx)
+	if err != nil {
+		t.Fatalf("ListUnknownWhatsAppNumbers failed: %v", err)
+	}
+
+	if len(numbers) != 1 {
+		t.Fatalf("expected one unknown number, got %+v", numbers)
+	}
+
+	number := numbers[0]
+	if number.Phone != "971501234567" || number.MessageCount != 3 || number.QueuedMessages != 2 || number.LastMessage != "Hello Sam" {
+		t.Fatalf("unexpected unknown number %+v", number)
+	}
+
+	if !number.FirstSeenAt.Equal(first) || !number.LastSeenAt.Equal(first.Add(2*time.Hour)) {
+		t.Fatalf("unexpected seen times %v %v", number.FirstSeenAt, number.LastSeenAt)
+	}
+
+	contactID := mustCreateContact(t, CreateContactInput{NameGiven: "Sam", Tier: TierC})
+
+	backfilled, err := AttachWhatsAppNumber(ctx, "971501234567", contactID)
+	if err != nil {
+		t.Fatalf("AttachWhatsAppNumber failed: %v", err)
+	}
+
+	if backfilled != 2 {
+		t.Fatalf("expected 2 backfilled messages, got %d", backfilled)
+	}
+
+	chats, err := GetContactChats(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContactChats failed: %v", err)
+	}
+
+	if len(chats) != 2 || chats[0].Platform != ChatPlatformWhatsApp {
+		t.Fatalf("unexpected chats %+v", chats)
+	}
+
+	// Later messages now reach the contact directly
+	matched, err := FindContactByPhone(ctx, "971501234567")
+	if err != nil || matched == nil || *matched != contactID {
+		t.Fatalf("expected number to match the contact, got %v %v", matched, err)
+	}
+
+	if count, err := CountUnknownWhatsAppNumbers(ctx); err != nil || count != 0 {
+		t.Fatalf("expected an empty inbox, got %d %v", count, err)
+	}
+
+	if _, err := AttachWhatsAppNumber(ctx, "971501234567", contactID); !errors.Is(err, ErrWhatsAppNumberNotFound) {
+		t.Fatalf("expected ErrWhatsAppNumberNotFound, got %v", err)
+	}
+}
+
+func TestUnknownWhatsAppNumberCreateAndIgnore(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+	sentAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, phone := range []string{"447700900001", "447700900002"} {
+		if err := RecordUnknownWhatsAppMessage(ctx, phone, "", sentAt, false, "Hello"); err != nil {
+			t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+		}
+	}
+
+	contactID, backfilled, err := CreateContactFromWhatsAppNumber(ctx, "447700900001", "")
+	if err != nil {
+		t.Fatalf("CreateContactFromWhatsAppNumber failed: %v", err)
+	}
+
+	if backfilled != 1 {
+		t.Fatalf("expected 1 backfilled message, got %d", backfilled)
+	}
+
+	contact, err := GetContact(ctx, contactID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+
+	if contact.NameDisplay != "+447700900001" || len(contact.Phones) != 1 || contact.Phones[0].Phone != "+447700900001" {
+		t.Fatalf("unexpected created contact %q %+v", contact.NameDisplay, contact.Phones)
+	}
+
+	if err := IgnoreWhatsAppNumber(ctx, "447700900002"); err != nil {
+		t.Fatalf("IgnoreWhatsAppNumber failed: %v", err)
+	}
+
+	// Ignored numbers stay out of the inbox
+	if err := RecordUnknownWhatsAppMessage(ctx, "447700900002", "", sentAt.Add(time.Hour), false, "Again"); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	numbers, err := ListUnknownWhatsAppNumbers(ctx)
+	if err != nil {
+		t.Fatalf("ListUnknownWhatsAppNumbers failed: %v", err)
+	}
+
+	if len(numbers) != 0 {
+		t.Fatalf("expected an empty inbox, got %+v", numbers)
+	}
+
+	if err := IgnoreWhatsAppNumber(ctx, "447700900002"); !errors.Is(err, ErrWhatsAppNumberNotFound) {
+		t.Fatalf("expected ErrWhatsAppNumberNotFound, got %v", err)
+	}
+
+	if _, _, err := CreateContactFromWhatsAppNumber(ctx, "447700900002", "Spam"); !errors.Is(err, ErrWhatsAppNumberNotFound) {
+		t.Fatalf("expected ErrWhatsAppNumberNotFound, got %v", err)
+	}
+}
+
+func TestCreateContactFromWhatsAppNumberSubmittedTwice(t *testing.T) {
+	resetDatabase(t)
+
+	ctx := testContext()
+
+	if err := RecordUnknownWhatsAppMessage(ctx, "447700900003", "m1", time.Now(), false, "Hello"); err != nil {
+		t.Fatalf("RecordUnknownWhatsAppMessage failed: %v", err)
+	}
+
+	errs := make(chan error, 2)
+
+	var wg sync.WaitGroup
+
+	for range 2 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, _, err := CreateContactFromWhatsAppNumber(ctx, "447700900003", "Sam")
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	created := 0
+
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrWhatsAppNumberNotFound):
+			t.Fatalf("expected ErrWhatsAppNumberNotFound for the second submit, got %v", err)
+		}
+	}
+
+	var contacts int
+	if err := pool.QueryRow(ctx, `SELECT count(*) FROM contacts`).Scan(&contacts); err != nil {
+		t.Fatalf("failed to count contacts: %v", err)
+	}
+
+	if created != 1 || contacts != 1 {
+		t.Fatalf("expected exactly one contact, got %d created and %d stored", created, contacts)
+	}
+}
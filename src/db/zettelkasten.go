@@ -192,9 +192,17 @@ func newZKHTTPClient(config *ZettelkastenConfig) *http.Client {
 
 // FetchOrgFile fetches a single .org file from WebDAV
 func FetchOrgFile(ctx context.Context, filename string) (string, error) {
+	content, _, err := fetchOrgFileWithETag(ctx, filename)
+
+	return content, err
+}
+
+// fetchOrgFileWithETag fetches a single .org file along with the ETag the
+// server reported for it, which is empty when the server sends none
+func fetchOrgFileWithETag(ctx context.Context, filename string) (string, string, error) {
 	config, err := GetZKConfig()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	httpClient := newZKHTTPClient(config)
@@ -204,12 +212,12 @@ func FetchOrgFile(ctx context.Context, filename string) (string, error) {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch file %s: %w", filename, err)
+		return "", "", fmt.Errorf("failed to fetch file %s: %w", filename, err)
 	}
 
 	defer func() {
@@ -219,15 +227,15 @@ func FetchOrgFile(ctx context.Context, filename string) (string, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("%w %s: HTTP %d", ErrFetchFileFailed, filename, resp.StatusCode)
+		return "", "", fmt.Errorf("%w %s: HTTP %d", ErrFetchFileFailed, filename, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file content: %w", err)
+		return "", "", fmt.Errorf("failed to read file content: %w", err)
 	}
 
-	return string(body), nil
+	return string(body), resp.Header.Get("ETag"), nil
 }
 
 // FetchDailyOrgFile fetches a daily journal org file from WebDAV.
@@ -270,8 +278,57 @@ func FetchDailyOrgFile(ctx context.Context, filename string) (string, error) {
 	return string(body), nil
 }
 
+// zkRemoteFile is an org file as listed by PROPFIND
+type zkRemoteFile struct {
+	Name         string
+	ETag         string
+	LastModified time.Time
+	Size         int64
+}
+
+// zkRemoteFileNames returns the names of listed files
+func zkRemoteFileNames(files []zkRemoteFile) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+
+	return names
+}
+
+// zkRemoteOrgFiles keeps the .org files of a directory listing
+func zkRemoteOrgFiles(fileInfos []webdav.FileInfo) []zkRemoteFile {
+	var orgFiles []zkRemoteFile
+
+	for _, info := range fileInfos {
+		if !info.IsDir && strings.HasSuffix(info.Path, ".org") {
+			// Extract just the filename from the path
+			parts := strings.Split(strings.TrimPrefix(info.Path, "/"), "/")
+			orgFiles = append(orgFiles, zkRemoteFile{
+				Name:         parts[len(parts)-1],
+				ETag:         info.ETag,
+				LastModified: info.ModTime,
+				Size:         info.Size,
+			})
+		}
+	}
+
+	return orgFiles
+}
+
 // ListOrgFiles lists all .org files in the WebDAV directory
 func ListOrgFiles(ctx context.Context) ([]string, error) {
+	files, err := listZKRemoteOrgFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return zkRemoteFileNames(files), nil
+}
+
+// listZKRemoteOrgFiles lists the .org files in the WebDAV directory with
+// their ETags and modification times
+func listZKRemoteOrgFiles(ctx context.Context) ([]zkRemoteFile, error) {
 	config, err := GetZKConfig()
 	if err != nil {
 		return nil, err
@@ -300,22 +357,22 @@ func ListOrgFiles(ctx context.Context) ([]string, error) {
 
 	logger.Info("Found WebDAV directory items", "count", len(fileInfos), "base_url", config.BaseURL)
 
-	var orgFiles []string
+	return zkRemoteOrgFiles(fileInfos), nil
+}
 
-	for _, info := range fileInfos {
-		if !info.IsDir && strings.HasSuffix(info.Path, ".org") {
-			// Extract just the filename from the path
-			parts := strings.Split(strings.TrimPrefix(info.Path, "/"), "/")
-			filename := parts[len(parts)-1]
-			orgFiles = append(orgFiles, filename)
-		}
+// ListDailyOrgFiles lists .org files in the WebDAV daily journal directory.
+func ListDailyOrgFiles(ctx context.Context) ([]string, error) {
+	files, err := listZKRemoteDailyFiles(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return orgFiles, nil
+	return zkRemoteFileNames(files), nil
 }
 
-// ListDailyOrgFiles lists .org files in the WebDAV daily journal directory.
-func ListDailyOrgFiles(ctx context.Context) ([]string, error) {
+// listZKRemoteDailyFiles lists the .org files in the WebDAV daily journal
+// directory with their ETags and modification times
+func listZKRemoteDailyFiles(ctx context.Context) ([]zkRemoteFile, error) {
 	config, err := GetZKConfig()
 	if err != nil {
 		return nil, err
@@ -339,7 +396,7 @@ func ListDailyOrgFiles(ctx context.Context) ([]string, error) {
 	fileInfos, err := client.ReadDir(ctx, dailyPath, false)
 	if err != nil {
 		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
-			return []string{}, nil
+			return []zkRemoteFile{}, nil
 		}
 
 		return nil, fmt.Errorf("failed to list daily directory: %w", err)
@@ -347,17 +404,7 @@ func ListDailyOrgFiles(ctx context.Context) ([]string, error) {
 
 	logger.Info("Found WebDAV daily directory items", "count", len(fileInfos), "base_url", dailyBaseURL)
 
-	var orgFiles []string
-
-	for _, info := range fileInfos {
-		if !info.IsDir && strings.HasSuffix(info.Path, ".org") {
-			parts := strings.Split(strings.TrimPrefix(info.Path, "/"), "/")
-			filename := parts[len(parts)-1]
-			orgFiles = append(orgFiles, filename)
-		}
-	}
-
-	return orgFiles, nil
+	return zkRemoteOrgFiles(fileInfos), nil
 }
 
 // FindFileByID resolves a note ID to its filename using caching
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+// ZKNoteSource is the raw org-mode text of a note, with the ETag it was
+// read at so a later save can detect concurrent edits
+type ZKNoteSource struct {
+	ID       string
+	Title    string
+	Filename string
+	Content  string
+	ETag     string // Empty when the WebDAV server reports none
+}
+
+// GetZKNoteSource fetches the raw org-mode text of a note for editing
+func GetZKNoteSource(ctx context.Context, id string) (*ZKNoteSource, error) {
+	filename, err := FindFileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, etag, err := fetchOrgFileWithETag(ctx, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch note: %w", err)
+	}
+
+	return &ZKNoteSource{
+		ID:       id,
+		Title:    utils.ExtractTitle(content),
+		Filename: filename,
+		Content:  content,
+		ETag:     etag,
+	}, nil
+}
+
+// UpdateZKNote writes new org-mode text for a note, but only when the file
+// still has the expected ETag. The :ID: property must be kept as is.
+func UpdateZKNote(ctx context.Context, id, content, expectedETag string) error {
+	expectedETag, ok := sanitizeWebDAVETag(expectedETag)
+	if !ok {
+		return ErrZKNoteETagRequired
+	}
+
+	content = normalizeOrgNoteContent(content)
+
+	if contentID, err := utils.ExtractIDProperty(content); err != nil || contentID != id {
+		return ErrZKNoteIDChanged
+	}
+
+	filename, err := FindFileByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := putOrgFile(ctx, filename, content, "If-Match", expectedETag); err != nil {
+		return err
+	}
+
+	refreshZKNoteCaches(ctx, id, filename, content)
+
+	return nil
+}
+
+// CreateZKNote writes a new note with a fresh :ID: property and title,
+// named after the org-roam default of a timestamp and a slug of the title.
+// It returns the ID of the new note.
+func CreateZKNote(ctx context.Context, title, body string, now time.Time) (string, error) {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return "", ErrZKNoteTitleRequired
+	}
+
+	id := uuid.NewString()
+	filename := zkNoteFilename(title, now)
+	content := newZKNoteContent(id, title, body)
+
+	// If-None-Match keeps an existing file with the same name intact
+	if err := putOrgFile(ctx, filename, content, "If-None-Match", "*"); err != nil {
+		if errors.Is(err, ErrZKNoteConflict) {
+			return "", ErrZKNoteExists
+		}
+
+		return "", err
+	}
+
+	cacheMutex.Lock()
+
+	idToFilenameCache[id] = filename
+
+	cacheMutex.Unlock()
+
+	refreshZKNoteCaches(ctx, id, filename, content)
+
+	return id, nil
+}
+
+// newZKNoteContent renders the org-mode text of a new note
+func newZKNoteContent(id, title, body string) string {
+	var sb strings.Builder
+
+	sb.WriteString(":PROPERTIES:\n")
+	sb.WriteString(":ID:       " + id + "\n")
+	sb.WriteString(":END:\n")
+	sb.WriteString("#+TITLE: " + title + "\n")
+
+	if body = strings.TrimSpace(normalizeOrgNoteContent(body)); body != "" {
+		sb.WriteString("\n" + body + "\n")
+	}
+
+	return sb.String()
+}
+
+// zkNoteFilename names a note file like org-roam does, e.g.
+// 20250102150405-my_note.org
+func zkNoteFilename(title string, now time.Time) string {
+	var sb strings.Builder
+
+	pendingSeparator := false
+
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingSeparator && sb.Len() > 0 {
+				sb.WriteByte('_')
+			}
+
+			sb.WriteRune(r)
+
+			pendingSeparator = false
+
+			continue
+		}
+
+		pendingSeparator = true
+	}
+
+	slug := sb.String()
+	if slug == "" {
+		slug = "note"
+	}
+
+	return now.Format("20060102150405") + "-" + slug + ".org"
+}
+
+// normalizeOrgNoteContent converts browser line endings to Unix ones and
+// ends the text with a newline
+func normalizeOrgNoteContent(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return content
+}
+
+// putOrgFile uploads a note file guarded by a conditional header. A failed
+// precondition is reported as ErrZKNoteConflict.
+func putOrgFile(ctx context.Context, filename, content, conditionHeader, conditionValue string) error {
+	config, err := GetZKConfig()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, config.BaseURL+filename, strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set(conditionHeader, conditionValue)
+
+	resp, err := newZKHTTPClient(config).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s: %w", filename, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close zettelkasten response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return ErrZKNoteNotFound
+	case http.StatusPreconditionFailed:
+		return ErrZKNoteConflict
+	default:
+		return fmt.Errorf("%w %s: HTTP %d", ErrZKNoteSaveHTTPStatus, filename, resp.StatusCode)
+	}
+}
+
+// refreshZKNoteCaches brings the backlink, timeline and search caches up to
+// date with a note that was just written, so the change shows immediately
+func refreshZKNoteCaches(ctx context.Context, id, filename, content string) {
+	refreshBacklinkCacheForNote(id, content)
+
+	if config, err := GetZKConfig(); err == nil && filename != config.IndexFile {
+		refreshZKTimelineNote(id, filename, content)
+	}
+
+	doc := zkSearchDocument{
+		NoteID:   id,
+		Title:    utils.ExtractTitle(content),
+		Body:     content,
+		IsPublic: utils.IsPublicAccess(content),
+		IsHome:   utils.IsHomeAccess(content),
+	}
+
+	if err := indexZettelkastenNote(ctx, doc); err != nil && !errors.Is(err, ErrDatabaseConnectionNotInitialized) {
+		logger.Warn("Failed to update note search index", "note_id", id, "error", err)
+	}
+
+	// Embedding waits on Ollama, so it does not hold up the save
+	go func(ctx context.Context) {
+		if err := indexZettelkastenEmbedding(ctx, doc); err != nil &&
+			!errors.Is(err, ErrDatabaseConnectionNotInitialized) && !errors.Is(err, ErrOllamaEmbedConfigIncomplete) {
+			logger.Warn("Failed to update note embeddings", "note_id", id, "error", err)
+		}
+	}(context.WithoutCancel(ctx))
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestZettelkastenNoteEditing(t *testing.T) {
+	resetDatabase(t)
+
+	server := newWebDAVTestServer(t)
+	defer server.close()
+
+	t.Setenv("WEBDAV_USERNAME", "")
+	t.Setenv("WEBDAV_PASSWORD", "")
+	t.Setenv("WEBDAV_ZK_PATH", server.server.URL+"/zk/index.org")
+	t.Setenv("GROUNDWAVE_BASE_URL", "https://groundwave.example.com")
+
+	ctx := testContext()
+	noteID := "33333333-3333-3333-3333-333333333333"
+	linkedID := "22222222-2222-2222-2222-222222222222"
+
+	source, err := GetZKNoteSource(ctx, noteID)
+	if err != nil {
+		t.Fatalf("GetZKNoteSource failed: %v", err)
+	}
+
+	if source.Title != "Note Two" || source.ETag == "" {
+		t.Fatalf("expected note source with an ETag, got %#v", source)
+	}
+
+	updated := source.Content + "\nSee [[id:" + linkedID + "][Note One]]."
+
+	if err := UpdateZKNote(ctx, noteID, updated, ""); !errors.Is(err, ErrZKNoteETagRequired) {
+		t.Fatalf("expected missing ETag error, got %v", err)
+	}
+
+	changedID := strings.Replace(updated, noteID, "77777777-7777-7777-7777-777777777777", 1)
+	if err := UpdateZKNote(ctx, noteID, changedID, source.ETag); !errors.Is(err, ErrZKNoteIDChanged) {
+		t.Fatalf("expected changed ID error, got %v", err)
+	}
+
+	if err := UpdateZKNote(ctx, noteID, updated, source.ETag); err != nil {
+		t.Fatalf("UpdateZKNote failed: %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(server.zkDir, source.Filename))
+	if err != nil {
+		t.Fatalf("failed to read written note: %v", err)
+	}
+
+	if !strings.Contains(string(written), "[[id:"+linkedID+"][Note One]]") {
+		t.Fatalf("expected note to be written, got %q", written)
+	}
+
+	if !slices.Contains(GetBacklinksFromCache(linkedID), noteID) {
+		t.Fatalf("expected backlink cache to be refreshed")
+	}
+
+	// The file changed, so the ETag read before the first save is stale
+	time.Sleep(10 * time.Millisecond)
+
+	if err := UpdateZKNote(ctx, noteID, updated+"\nMore.", source.ETag); !errors.Is(err, ErrZKNoteConflict) {
+		t.Fatalf("expected conflict for stale ETag, got %v", err)
+	}
+
+	now := time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	createdID, err := CreateZKNote(ctx, "Fresh Idea", "Links to [[id:"+noteID+"][Note Two]]", now)
+	if err != nil {
+		t.Fatalf("CreateZKNote failed: %v", err)
+	}
+
+	created, err := GetZKNoteSource(ctx, createdID)
+	if err != nil {
+		t.Fatalf("GetZKNoteSource for created note failed: %v", err)
+	}
+
+	if created.Filename != "20250304050607-fresh_idea.org" || created.Title != "Fresh Idea" {
+		t.Fatalf("unexpected created note %#v", created)
+	}
+
+	if !slices.Contains(GetBacklinksFromCache(noteID), createdID) {
+		t.Fatalf("expected created note to be in the backlink cache")
+	}
+
+	if notes := GetZKTimelineNotesByDate()["2025-03-04"]; len(notes) != 1 || notes[0].ID != createdID {
+		t.Fatalf("expected created note in the timeline cache, got %#v", notes)
+	}
+
+	if _, err := CreateZKNote(ctx, "Fresh Idea", "", now); !errors.Is(err, ErrZKNoteExists) {
+		t.Fatalf("expected existing file error, got %v", err)
+	}
+
+	if _, err := CreateZKNote(ctx, "  ", "", now); !errors.Is(err, ErrZKNoteTitleRequired) {
+		t.Fatalf("expected title required error, got %v", err)
+	}
+}
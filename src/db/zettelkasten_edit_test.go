@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+func TestZKNoteFilename(t *testing.T) {
+	now := time.Date(2025, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := map[string]string{
+		"My Note":              "20250102150405-my_note.org",
+		"  Go: tips & tricks!": "20250102150405-go_tips_tricks.org",
+		"Café Ümlaut":          "20250102150405-café_ümlaut.org",
+		"???":                  "20250102150405-note.org",
+	}
+
+	for title, want := range tests {
+		if got := zkNoteFilename(title, now); got != want {
+			t.Fatalf("zkNoteFilename(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestNewZKNoteContent(t *testing.T) {
+	id := "0f8fad5b-d9cb-469f-a165-70867728950e"
+	content := newZKNoteContent(id, "Reading list", "First line\r\nSecond line")
+
+	if got, err := utils.ExtractIDProperty(content); err != nil || got != id {
+		t.Fatalf("expected ID %q, got %q (%v)", id, got, err)
+	}
+
+	if got := utils.ExtractTitle(content); got != "Reading list" {
+		t.Fatalf("expected title, got %q", got)
+	}
+
+	if !strings.HasSuffix(content, "\nFirst line\nSecond line\n") || strings.Contains(content, "\r") {
+		t.Fatalf("expected normalized body, got %q", content)
+	}
+
+	if empty := newZKNoteContent(id, "Empty", "  "); !strings.HasSuffix(empty, "#+TITLE: Empty\n") {
+		t.Fatalf("expected no body section, got %q", empty)
+	}
+}
+
+func TestRefreshBacklinkCacheForNote(t *testing.T) {
+	t.Setenv("GROUNDWAVE_BASE_URL", "")
+
+	const (
+		source  = "aaaaaaaa-0000-0000-0000-000000000001"
+		oldLink = "bbbbbbbb-0000-0000-0000-000000000002"
+		newLink = "cccccccc-0000-0000-0000-000000000003"
+		other   = "dddddddd-0000-0000-0000-000000000004"
+		contact = "eeeeeeee-0000-0000-0000-000000000005"
+	)
+
+	backlinkMutex.Lock()
+
+	originalBacklinks, originalForward := backlinkCache, forwardLinkCache
+	originalPublic, originalContacts := publicNoteCache, contactLinkCache
+	backlinkCache = map[string][]string{oldLink: {other, source}}
+	forwardLinkCache = map[string][]string{source: {oldLink}}
+	publicNoteCache = map[string]bool{}
+	contactLinkCache = map[string][]string{contact: {source}}
+
+	backlinkMutex.Unlock()
+
+	t.Cleanup(func() {
+		backlinkMutex.Lock()
+		backlinkCache, forwardLinkCache = originalBacklinks, originalForward
+		publicNoteCache, contactLinkCache = originalPublic, originalContacts
+		backlinkMutex.Unlock()
+	})
+
+	refreshBacklinkCacheForNote(source, "#+access: public\n[[id:"+newLink+"][New]] [[id:"+newLink+"]]")
+
+	if got := GetBacklinksFromCache(oldLink); !slices.Equal(got, []string{other}) {
+		t.Fatalf("expected old backlink to be dropped, got %v", got)
+	}
+
+	if got := GetBacklinksFromCache(newLink); !slices.Equal(got, []string{source}) {
+		t.Fatalf("expected new backlink once, got %v", got)
+	}
+
+	if got := GetForwardLinksFromCache(source); !slices.Equal(got, []string{newLink}) {
+		t.Fatalf("expected forward links to be replaced, got %v", got)
+	}
+
+	if got := GetContactLinksFromCache(contact); len(got) != 0 {
+		t.Fatalf("expected contact link to be dropped, got %v", got)
+	}
+
+	if !IsPublicNoteFromCache(source) {
+		t.Fatalf("expected note to be public")
+	}
+}
@@ -0,0 +1,371 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/humaidq/groundwave/utils"
+)
+
+// zkFileKind tells notes apart from daily journal files
+type zkFileKind string
+
+const (
+	zkFileKindNote  zkFileKind = "note"
+	zkFileKindDaily zkFileKind = "daily"
+)
+
+// zkIndexedFile is what the caches need from one org file. It is kept in
+// zk_file_index along with the ETag it was fetched at.
+type zkIndexedFile struct {
+	Kind         zkFileKind
+	Filename     string
+	ETag         string
+	LastModified time.Time
+	Size         int64
+	NoteID       string // :ID: of a note or daily:<date>, empty when neither applies
+	Title        string // #+TITLE as extracted, "Untitled Note" when missing
+	Links        []string
+	ContactLinks []string
+	IsPublic     bool
+	IsHome       bool
+	Date         *time.Time // journal day, or the #+DATE directive of a note
+	Content      string
+}
+
+// displayName names the file in reports, with daily files under daily/
+func (f zkIndexedFile) displayName() string {
+	if f.Kind == zkFileKindDaily {
+		return "daily/" + f.Filename
+	}
+
+	return f.Filename
+}
+
+// matchesRemote reports whether a listed file is the one indexed. The ETag
+// decides when the server sends one, else modification time and size.
+func (f zkIndexedFile) matchesRemote(remote zkRemoteFile) bool {
+	if remote.ETag != "" {
+		return f.ETag == remote.ETag
+	}
+
+	return f.ETag == "" && !remote.LastModified.IsZero() &&
+		f.LastModified.Equal(remote.LastModified) && f.Size == remote.Size
+}
+
+// ZKCacheRebuildReport describes what a cache rebuild found in WebDAV.
+// File names of daily journal files start with daily/.
+type ZKCacheRebuildReport struct {
+	Added     []string
+	Changed   []string
+	Removed   []string
+	Failed    []string // Files that could not be fetched, kept as last indexed
+	Unchanged int
+	Duration  time.Duration
+}
+
+// HasChanges reports whether any file was added, changed or removed
+func (r *ZKCacheRebuildReport) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Changed) > 0 || len(r.Removed) > 0
+}
+
+// parseZKIndexedFile extracts what the caches need from an org file
+func parseZKIndexedFile(kind zkFileKind, remote zkRemoteFile, content string, contactLinkMatchers []*regexp.Regexp) zkIndexedFile {
+	file := zkIndexedFile{
+		Kind:         kind,
+		Filename:     remote.Name,
+		ETag:         remote.ETag,
+		LastModified: remote.LastModified,
+		Size:         remote.Size,
+		Title:        utils.ExtractTitle(content),
+		Links:        uniqueLinkIDs(ExtractLinksFromContent(content)),
+		ContactLinks: uniqueLinkIDs(extractContactLinksFromContent(content, contactLinkMatchers)),
+		Content:      content,
+	}
+
+	if kind == zkFileKindDaily {
+		dateString := strings.TrimSuffix(remote.Name, ".org")
+		if parsedDate, err := time.Parse("2006-01-02", dateString); err == nil {
+			file.NoteID = DailyBacklinkPrefix + dateString
+			file.Date = &parsedDate
+		}
+
+		return file
+	}
+
+	if noteID, err := utils.ExtractIDProperty(content); err == nil {
+		file.NoteID = noteID
+	}
+
+	file.IsPublic = utils.IsPublicAccess(content)
+	file.IsHome = utils.IsHomeAccess(content)
+
+	if overrideDate, ok := utils.ExtractDateDirective(content); ok {
+		date := time.Date(overrideDate.Year(), overrideDate.Month(), overrideDate.Day(), 0, 0, 0, 0, time.UTC)
+		file.Date = &date
+	}
+
+	return file
+}
+
+// fetchZKIndexedFile downloads and parses a listed org file
+func fetchZKIndexedFile(ctx context.Context, kind zkFileKind, remote zkRemoteFile, contactLinkMatchers []*regexp.Regexp) (zkIndexedFile, error) {
+	var (
+		content string
+		err     error
+	)
+
+	if kind == zkFileKindDaily {
+		content, err = FetchDailyOrgFile(ctx, remote.Name)
+	} else {
+		content, err = FetchOrgFile(ctx, remote.Name)
+	}
+
+	if err != nil {
+		return zkIndexedFile{}, err
+	}
+
+	return parseZKIndexedFile(kind, remote, content, contactLinkMatchers), nil
+}
+
+// fetchZKIndexedFiles downloads and parses every listed file, skipping the
+// ones that cannot be read
+func fetchZKIndexedFiles(ctx context.Context, orgFiles, dailyFiles []string) []zkIndexedFile {
+	contactLinkMatchers := buildContactLinkMatchers(os.Getenv("GROUNDWAVE_BASE_URL"))
+	files := make([]zkIndexedFile, 0, len(orgFiles)+len(dailyFiles))
+
+	for _, group := range []struct {
+		kind  zkFileKind
+		names []string
+	}{
+		{zkFileKindNote, orgFiles},
+		{zkFileKindDaily, dailyFiles},
+	} {
+		for _, name := range group.names {
+			file, err := fetchZKIndexedFile(ctx, group.kind, zkRemoteFile{Name: name}, contactLinkMatchers)
+			if err != nil {
+				logger.Warn("Skipping unreadable file", "file", name, "error", err)
+				continue
+			}
+
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// syncZKIndexedFiles compares a listing with the indexed files, fetching
+// only new and changed files. It returns every file the caches should hold,
+// the fetched files to save to the index and the indexed files now gone.
+func syncZKIndexedFiles(
+	ctx context.Context,
+	orgFiles, dailyFiles []zkRemoteFile,
+	indexed []zkIndexedFile,
+	report *ZKCacheRebuildReport,
+) ([]zkIndexedFile, []zkIndexedFile, []zkIndexedFile) {
+	type fileKey struct {
+		kind zkFileKind
+		name string
+	}
+
+	indexedByKey := make(map[fileKey]zkIndexedFile, len(indexed))
+	for _, file := range indexed {
+		indexedByKey[fileKey{file.Kind, file.Filename}] = file
+	}
+
+	contactLinkMatchers := buildContactLinkMatchers(os.Getenv("GROUNDWAVE_BASE_URL"))
+	files := make([]zkIndexedFile, 0, len(orgFiles)+len(dailyFiles))
+
+	var updated []zkIndexedFile
+
+	for _, group := range []struct {
+		kind  zkFileKind
+		files []zkRemoteFile
+	}{
+		{zkFileKindNote, orgFiles},
+		{zkFileKindDaily, dailyFiles},
+	} {
+		for _, remote := range group.files {
+			key := fileKey{group.kind, remote.Name}
+			previous, wasIndexed := indexedByKey[key]
+
+			delete(indexedByKey, key)
+
+			if wasIndexed && previous.matchesRemote(remote) {
+				files = append(files, previous)
+				report.Unchanged++
+
+				continue
+			}
+
+			file, err := fetchZKIndexedFile(ctx, group.kind, remote, contactLinkMatchers)
+			if err != nil {
+				logger.Warn("Skipping unreadable file", "file", remote.Name, "error", err)
+				report.Failed = append(report.Failed, zkIndexedFile{Kind: group.kind, Filename: remote.Name}.displayName())
+
+				// The last indexed copy beats dropping the file from the caches
+				if wasIndexed {
+					files = append(files, previous)
+				}
+
+				continue
+			}
+
+			files = append(files, file)
+			updated = append(updated, file)
+
+			if wasIndexed {
+				report.Changed = append(report.Changed, file.displayName())
+			} else {
+				report.Added = append(report.Added, file.displayName())
+			}
+		}
+	}
+
+	removed := make([]zkIndexedFile, 0, len(indexedByKey))
+
+	for _, file := range indexed {
+		if _, gone := indexedByKey[fileKey{file.Kind, file.Filename}]; gone {
+			removed = append(removed, file)
+			report.Removed = append(report.Removed, file.displayName())
+		}
+	}
+
+	return files, updated, removed
+}
+
+// listZKIndexedFiles loads every indexed file, notes first, along with the
+// time the index was last checked against WebDAV
+func listZKIndexedFiles(ctx context.Context) ([]zkIndexedFile, time.Time, error) {
+	if pool == nil {
+		return nil, time.Time{}, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT kind, filename, etag, last_modified, size, note_id, title, links, contact_links,
+		       is_public, is_home, note_date, content, checked_at
+		FROM zk_file_index
+		ORDER BY kind = 'daily', filename
+	`)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to query zettelkasten file index: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		files     []zkIndexedFile
+		checkedAt time.Time
+	)
+
+	for rows.Next() {
+		var (
+			file         zkIndexedFile
+			kind         string
+			lastModified *time.Time
+			fileChecked  time.Time
+		)
+
+		if err := rows.Scan(
+			&kind, &file.Filename, &file.ETag, &lastModified, &file.Size, &file.NoteID, &file.Title,
+			&file.Links, &file.ContactLinks, &file.IsPublic, &file.IsHome, &file.Date, &file.Content, &fileChecked,
+		); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to scan zettelkasten file index: %w", err)
+		}
+
+		file.Kind = zkFileKind(kind)
+		if lastModified != nil {
+			file.LastModified = *lastModified
+		}
+
+		if fileChecked.After(checkedAt) {
+			checkedAt = fileChecked
+		}
+
+		files = append(files, file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("error iterating zettelkasten file index: %w", err)
+	}
+
+	return files, checkedAt, nil
+}
+
+// saveZKIndexedFiles stores new and changed files, drops removed ones and
+// marks the whole index as checked
+func saveZKIndexedFiles(ctx context.Context, updated, removed []zkIndexedFile) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback zettelkasten file index", "error", err)
+		}
+	}()
+
+	for _, file := range removed {
+		if _, err := tx.Exec(ctx, `DELETE FROM zk_file_index WHERE kind = $1 AND filename = $2`, string(file.Kind), file.Filename); err != nil {
+			return fmt.Errorf("failed to remove %s from zettelkasten file index: %w", file.displayName(), err)
+		}
+	}
+
+	for _, file := range updated {
+		var lastModified *time.Time
+		if !file.LastModified.IsZero() {
+			lastModified = &file.LastModified
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO zk_file_index (kind, filename, etag, last_modified, size, note_id, title, links,
+			                           contact_links, is_public, is_home, note_date, content)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (kind, filename) DO UPDATE SET
+				etag = EXCLUDED.etag,
+				last_modified = EXCLUDED.last_modified,
+				size = EXCLUDED.size,
+				note_id = EXCLUDED.note_id,
+				title = EXCLUDED.title,
+				links = EXCLUDED.links,
+				contact_links = EXCLUDED.contact_links,
+				is_public = EXCLUDED.is_public,
+				is_home = EXCLUDED.is_home,
+				note_date = EXCLUDED.note_date,
+				content = EXCLUDED.content,
+				indexed_at = now()
+		`,
+			string(file.Kind), file.Filename, file.ETag, lastModified, file.Size, file.NoteID,
+			strings.ReplaceAll(file.Title, "\x00", ""), file.Links, file.ContactLinks,
+			file.IsPublic, file.IsHome, file.Date, strings.ReplaceAll(file.Content, "\x00", ""),
+		); err != nil {
+			return fmt.Errorf("failed to index %s: %w", file.displayName(), err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE zk_file_index SET checked_at = now()`); err != nil {
+		return fmt.Errorf("failed to mark zettelkasten file index as checked: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit zettelkasten file index: %w", err)
+	}
+
+	return nil
+}
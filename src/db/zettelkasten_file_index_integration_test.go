@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRebuildZettelkastenCachesIsIncremental(t *testing.T) {
+	resetDatabase(t)
+	// Search and embeddings are indexed in the background after rebuilds
+	t.Cleanup(waitForZettelkastenIndex)
+
+	server := newWebDAVTestServer(t)
+	defer server.close()
+
+	t.Setenv("WEBDAV_USERNAME", "")
+	t.Setenv("WEBDAV_PASSWORD", "")
+	t.Setenv("WEBDAV_ZK_PATH", server.server.URL+"/zk/index.org")
+	t.Setenv("GROUNDWAVE_BASE_URL", "https://groundwave.example.com")
+
+	ctx := testContext()
+
+	report, err := RebuildZettelkastenCaches(ctx)
+	if err != nil {
+		t.Fatalf("RebuildZettelkastenCaches failed: %v", err)
+	}
+
+	sort.Strings(report.Added)
+
+	wantAdded := []string{
+		"20240101010101-note-one.org",
+		"20240102020202-note-two.org",
+		"daily/2024-01-01.org",
+		"home.org",
+		"index.org",
+	}
+	if !reflect.DeepEqual(report.Added, wantAdded) || report.Unchanged != 0 {
+		t.Fatalf("expected every file to be added on the first rebuild, got %#v", report)
+	}
+
+	report, err = RebuildZettelkastenCaches(ctx)
+	if err != nil {
+		t.Fatalf("RebuildZettelkastenCaches failed: %v", err)
+	}
+
+	if report.HasChanges() || report.Unchanged != len(wantAdded) {
+		t.Fatalf("expected no changes on the second rebuild, got %#v", report)
+	}
+
+	// Note two now links back to note one, home.org is gone and a new note
+	// appears
+	noteTwoPath := filepath.Join(server.zkDir, "20240102020202-note-two.org")
+	noteTwoContent := "#+TITLE: Note Two\n:PROPERTIES:\n:ID: 33333333-3333-3333-3333-333333333333\n:END:\n[[id:22222222-2222-2222-2222-222222222222][Note One]]"
+
+	if err := os.WriteFile(noteTwoPath, []byte(noteTwoContent), 0o600); err != nil {
+		t.Fatalf("failed to update note two: %v", err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(noteTwoPath, later, later); err != nil {
+		t.Fatalf("failed to touch note two: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(server.zkDir, "home.org")); err != nil {
+		t.Fatalf("failed to remove home note: %v", err)
+	}
+
+	noteThreeContent := "#+TITLE: Note Three\n:PROPERTIES:\n:ID: 77777777-7777-7777-7777-777777777777\n:END:\n"
+	if err := os.WriteFile(filepath.Join(server.zkDir, "20240103030303-note-three.org"), []byte(noteThreeContent), 0o600); err != nil {
+		t.Fatalf("failed to write note three: %v", err)
+	}
+
+	report, err = RebuildZettelkastenCaches(ctx)
+	if err != nil {
+		t.Fatalf("RebuildZettelkastenCaches failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(report.Added, []string{"20240103030303-note-three.org"}) ||
+		!reflect.DeepEqual(report.Changed, []string{"20240102020202-note-two.org"}) ||
+		!reflect.DeepEqual(report.Removed, []string{"home.org"}) ||
+		report.Unchanged != 3 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+
+	assertBacklinks := func(targetID string, want []string) {
+		t.Helper()
+
+		got := GetBacklinksFromCache(targetID)
+		sort.Strings(got)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected backlinks of %s to be %v, got %v", targetID, want, got)
+		}
+	}
+
+	assertBacklinks("22222222-2222-2222-2222-222222222222", []string{
+		"11111111-1111-1111-1111-111111111111",
+		"33333333-3333-3333-3333-333333333333",
+	})
+
+	// A restart starts with empty caches, which the index fills back in
+	backlinkMutex.Lock()
+	backlinkCache = make(map[string][]string)
+	backlinkMutex.Unlock()
+
+	journalMutex.Lock()
+	journalCache = make(map[string]JournalEntry)
+	journalMutex.Unlock()
+
+	zkNoteMutex.Lock()
+	zkNoteCache = make(map[string][]ZKTimelineNote)
+	zkNoteMutex.Unlock()
+
+	if err := LoadZettelkastenCachesFromIndex(ctx); err != nil {
+		t.Fatalf("LoadZettelkastenCachesFromIndex failed: %v", err)
+	}
+
+	assertBacklinks("22222222-2222-2222-2222-222222222222", []string{
+		"11111111-1111-1111-1111-111111111111",
+		"33333333-3333-3333-3333-333333333333",
+	})
+
+	if _, ok := GetJournalEntryByDate("2024-01-01"); !ok {
+		t.Fatalf("expected the journal entry to be loaded from the index")
+	}
+
+	if notes := GetZKTimelineNotesByDate()["2024-01-03"]; len(notes) != 1 || notes[0].Title != "Note Three" {
+		t.Fatalf("expected note three in the timeline, got %#v", notes)
+	}
+
+	if len(GetContactLinksFromCache("44444444-4444-4444-4444-444444444444")) != 2 {
+		t.Fatalf("expected contact links to be loaded from the index")
+	}
+
+	if GetLastCacheBuildTime().IsZero() {
+		t.Fatalf("expected the last check time to be restored")
+	}
+}
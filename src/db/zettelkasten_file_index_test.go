@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseZKIndexedFile(t *testing.T) {
+	t.Parallel()
+
+	matchers := buildContactLinkMatchers("groundwave.example.com")
+	content := "#+TITLE: Note One\n#+access: public\n#+DATE: 2024-02-03\n:PROPERTIES:\n:ID: 22222222-2222-2222-2222-222222222222\n:END:\n" +
+		"[[id:33333333-3333-3333-3333-333333333333][Two]] [[id:33333333-3333-3333-3333-333333333333][Again]]\n" +
+		"[[https://groundwave.example.com/contact/44444444-4444-4444-4444-444444444444][Contact]]"
+
+	file := parseZKIndexedFile(zkFileKindNote, zkRemoteFile{Name: "20240101010101-note-one.org", ETag: "v1"}, content, matchers)
+
+	if file.NoteID != "22222222-2222-2222-2222-222222222222" || file.Title != "Note One" || file.ETag != "v1" {
+		t.Fatalf("unexpected note: %#v", file)
+	}
+
+	if !reflect.DeepEqual(file.Links, []string{"33333333-3333-3333-3333-333333333333"}) {
+		t.Fatalf("expected links to be unique, got %v", file.Links)
+	}
+
+	if !reflect.DeepEqual(file.ContactLinks, []string{"44444444-4444-4444-4444-444444444444"}) {
+		t.Fatalf("unexpected contact links: %v", file.ContactLinks)
+	}
+
+	if !file.IsPublic || file.IsHome {
+		t.Fatalf("expected a public note, got %#v", file)
+	}
+
+	if file.Date == nil || file.Date.Format("2006-01-02") != "2024-02-03" {
+		t.Fatalf("expected the #+DATE directive, got %v", file.Date)
+	}
+
+	daily := parseZKIndexedFile(zkFileKindDaily, zkRemoteFile{Name: "2024-01-01.org"}, "Journal", matchers)
+	if daily.NoteID != DailyBacklinkPrefix+"2024-01-01" || daily.Date == nil || daily.displayName() != "daily/2024-01-01.org" {
+		t.Fatalf("unexpected daily file: %#v", daily)
+	}
+
+	if undated := parseZKIndexedFile(zkFileKindDaily, zkRemoteFile{Name: "notes.org"}, "Journal", matchers); undated.NoteID != "" || undated.Date != nil {
+		t.Fatalf("expected a daily file without a date name to have no ID, got %#v", undated)
+	}
+}
+
+func TestZKIndexedFileMatchesRemote(t *testing.T) {
+	t.Parallel()
+
+	modified := time.Date(2025, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	withETag := zkIndexedFile{ETag: "v1", LastModified: modified, Size: 10}
+	if !withETag.matchesRemote(zkRemoteFile{ETag: "v1"}) {
+		t.Fatalf("expected the same ETag to match")
+	}
+
+	if withETag.matchesRemote(zkRemoteFile{ETag: "v2", LastModified: modified, Size: 10}) {
+		t.Fatalf("expected another ETag not to match")
+	}
+
+	withoutETag := zkIndexedFile{LastModified: modified, Size: 10}
+	if !withoutETag.matchesRemote(zkRemoteFile{LastModified: modified, Size: 10}) {
+		t.Fatalf("expected the same modification time and size to match")
+	}
+
+	if withoutETag.matchesRemote(zkRemoteFile{LastModified: modified, Size: 11}) {
+		t.Fatalf("expected another size not to match")
+	}
+
+	if (zkIndexedFile{}).matchesRemote(zkRemoteFile{}) {
+		t.Fatalf("expected a file without ETag or modification time never to match")
+	}
+}
+
+func TestSyncZKIndexedFilesKeepsUnchangedAndDropsRemoved(t *testing.T) {
+	t.Parallel()
+
+	indexed := []zkIndexedFile{
+		{Kind: zkFileKindNote, Filename: "a.org", ETag: "a1", NoteID: "a"},
+		{Kind: zkFileKindNote, Filename: "gone.org", ETag: "g1", NoteID: "gone"},
+		{Kind: zkFileKindDaily, Filename: "2024-01-01.org", ETag: "d1", NoteID: DailyBacklinkPrefix + "2024-01-01"},
+	}
+
+	report := &ZKCacheRebuildReport{}
+	files, updated, removed := syncZKIndexedFiles(
+		context.Background(),
+		[]zkRemoteFile{{Name: "a.org", ETag: "a1"}},
+		[]zkRemoteFile{{Name: "2024-01-01.org", ETag: "d1"}},
+		indexed,
+		report,
+	)
+
+	if len(files) != 2 || files[0].NoteID != "a" || files[1].Kind != zkFileKindDaily {
+		t.Fatalf("expected the unchanged files to be kept, got %#v", files)
+	}
+
+	if len(updated) != 0 || len(removed) != 1 || removed[0].Filename != "gone.org" {
+		t.Fatalf("expected only gone.org to be removed, got %#v and %#v", updated, removed)
+	}
+
+	if report.Unchanged != 2 || !reflect.DeepEqual(report.Removed, []string{"gone.org"}) || !report.HasChanges() {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+}
@@ -7,6 +7,8 @@ import "testing"
 
 func TestZettelkastenWebDAVAndCaches(t *testing.T) {
 	resetDatabase(t)
+	// Search and embeddings are indexed in the background after rebuilds
+	t.Cleanup(waitForZettelkastenIndex)
 
 	server := newWebDAVTestServer(t)
 	defer server.close()
@@ -210,7 +212,7 @@ func TestZettelkastenWebDAVAndCaches(t *testing.T) {
 		t.Fatalf("expected GetHomeConfig to fail when parent directories differ")
 	}
 
-	if err := RebuildZettelkastenCaches(testContext()); err != nil {
+	if _, err := RebuildZettelkastenCaches(testContext()); err != nil {
 		t.Fatalf("RebuildZettelkastenCaches failed: %v", err)
 	}
 }
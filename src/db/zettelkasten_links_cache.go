@@ -6,6 +6,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/url"
@@ -146,7 +147,6 @@ func extractContactLinksFromContent(content string, matchers []*regexp.Regexp) [
 }
 
 // BuildBacklinkCache scans all .org files and builds the backlink index
-// This function is designed to be called periodically by a background worker
 func BuildBacklinkCache(ctx context.Context) error {
 	// List all .org files
 	orgFiles, err := ListOrgFiles(ctx)
@@ -159,120 +159,50 @@ func BuildBacklinkCache(ctx context.Context) error {
 		return fmt.Errorf("failed to list daily org files: %w", err)
 	}
 
-	return buildBacklinkCacheFromFiles(ctx, orgFiles, dailyFiles)
+	files := fetchZKIndexedFiles(ctx, orgFiles, dailyFiles)
+	applyBacklinkCache(files, time.Now())
+	indexZettelkastenFiles(ctx, files)
+
+	return nil
 }
 
-func buildBacklinkCacheFromFiles(ctx context.Context, orgFiles, dailyFiles []string) error {
+// applyBacklinkCache replaces the link caches with the links of the given
+// files
+func applyBacklinkCache(files []zkIndexedFile, builtAt time.Time) {
 	logger.Info("Building backlink cache")
 
 	startTime := time.Now()
 
-	type orgFile struct {
-		Name    string
-		IsDaily bool
-	}
-
-	files := make([]orgFile, 0, len(orgFiles)+len(dailyFiles))
-	for _, file := range orgFiles {
-		files = append(files, orgFile{Name: file})
-	}
-
-	for _, file := range dailyFiles {
-		files = append(files, orgFile{Name: file, IsDaily: true})
-	}
-
 	// Build temporary cache
 	tempBacklinkCache := make(map[string][]string)
 	tempForwardCache := make(map[string][]string)
 	tempPublicCache := make(map[string]bool)
 	tempContactLinkCache := make(map[string][]string)
-	contactLinkMatchers := buildContactLinkMatchers(os.Getenv("GROUNDWAVE_BASE_URL"))
+	tempIDToFilename := make(map[string]string)
 	filesProcessed := 0
 	filesSkipped := 0
 
-	// Scan each file
 	for _, file := range files {
-		// Fetch file content
-		var (
-			content string
-			err     error
-		)
-
-		if file.IsDaily {
-			content, err = FetchDailyOrgFile(ctx, file.Name)
-		} else {
-			content, err = FetchOrgFile(ctx, file.Name)
-		}
-
-		if err != nil {
-			logger.Warn("Skipping unreadable file", "file", file.Name, "error", err)
-
+		// Notes without an ID and daily files not named after a date
+		// cannot be linked to
+		sourceID := file.NoteID
+		if sourceID == "" {
 			filesSkipped++
-
 			continue
 		}
 
-		// Extract source ID (the note's own ID)
-		var sourceID string
-
-		if file.IsDaily {
-			dateString := strings.TrimSuffix(file.Name, ".org")
-			if _, parseErr := time.Parse("2006-01-02", dateString); parseErr != nil {
-				filesSkipped++
-				continue
-			}
-
-			sourceID = DailyBacklinkPrefix + dateString
-		} else {
-			var err error
-
-			sourceID, err = utils.ExtractIDProperty(content)
-			if err != nil {
-				// File doesn't have an ID property, skip it
-				filesSkipped++
-				continue
-			}
-
-			tempPublicCache[sourceID] = utils.IsPublicAccess(content)
+		if file.Kind == zkFileKindNote {
+			tempPublicCache[sourceID] = file.IsPublic
+			tempIDToFilename[sourceID] = file.Filename
 		}
 
-		// Extract all link targets from this note
-		targetIDs := ExtractLinksFromContent(content)
-		contactLinkIDs := extractContactLinksFromContent(content, contactLinkMatchers)
-
-		seenTargets := make(map[string]struct{}, len(targetIDs))
-		for _, targetID := range targetIDs {
-			if targetID == "" {
-				continue
-			}
-
-			if _, exists := seenTargets[targetID]; exists {
-				continue
-			}
-
-			seenTargets[targetID] = struct{}{}
+		for _, targetID := range file.Links {
 			tempBacklinkCache[targetID] = append(tempBacklinkCache[targetID], sourceID)
 		}
 
-		forwardLinks := make([]string, 0, len(seenTargets))
-		for targetID := range seenTargets {
-			forwardLinks = append(forwardLinks, targetID)
-		}
-
-		sort.Strings(forwardLinks)
-		tempForwardCache[sourceID] = forwardLinks
-
-		seenContacts := make(map[string]struct{}, len(contactLinkIDs))
-		for _, contactID := range contactLinkIDs {
-			if contactID == "" {
-				continue
-			}
+		tempForwardCache[sourceID] = sortedLinkIDs(file.Links)
 
-			if _, exists := seenContacts[contactID]; exists {
-				continue
-			}
-
-			seenContacts[contactID] = struct{}{}
+		for _, contactID := range file.ContactLinks {
 			tempContactLinkCache[contactID] = append(tempContactLinkCache[contactID], sourceID)
 		}
 
@@ -286,15 +216,140 @@ func buildBacklinkCacheFromFiles(ctx context.Context, orgFiles, dailyFiles []str
 	forwardLinkCache = tempForwardCache
 	publicNoteCache = tempPublicCache
 	contactLinkCache = tempContactLinkCache
-	lastCacheBuild = time.Now()
+	lastCacheBuild = builtAt
 
 	backlinkMutex.Unlock()
 
+	// Every note is known now, so lookups by ID no longer scan WebDAV
+	cacheMutex.Lock()
+
+	idToFilenameCache = tempIDToFilename
+
+	cacheMutex.Unlock()
+
 	duration := time.Since(startTime)
 	logger.Infof("Backlink cache built: %d files processed, %d skipped, %d backlink entries, took %v",
 		filesProcessed, filesSkipped, len(tempBacklinkCache), duration)
+}
 
-	return nil
+// indexZettelkastenFiles keeps the full-text search copy and embeddings of
+// note text in step with the given files
+func indexZettelkastenFiles(ctx context.Context, files []zkIndexedFile) {
+	searchDocs := make([]zkSearchDocument, 0, len(files))
+
+	for _, file := range files {
+		if file.NoteID == "" {
+			continue
+		}
+
+		if file.Kind == zkFileKindDaily {
+			searchDocs = append(searchDocs, zkSearchDocument{
+				NoteID:  file.NoteID,
+				Title:   strings.TrimPrefix(file.NoteID, DailyBacklinkPrefix),
+				Body:    file.Content,
+				IsDaily: true,
+			})
+
+			continue
+		}
+
+		searchDocs = append(searchDocs, zkSearchDocument{
+			NoteID:   file.NoteID,
+			Title:    file.Title,
+			Body:     file.Content,
+			IsPublic: file.IsPublic,
+			IsHome:   file.IsHome,
+		})
+	}
+
+	if err := indexZettelkastenNotes(ctx, searchDocs); err != nil && !errors.Is(err, ErrDatabaseConnectionNotInitialized) {
+		logger.Warn("Failed to update note search index", "error", err)
+	}
+
+	if err := indexZettelkastenEmbeddings(ctx, searchDocs); err != nil &&
+		!errors.Is(err, ErrDatabaseConnectionNotInitialized) && !errors.Is(err, ErrOllamaEmbedConfigIncomplete) {
+		logger.Warn("Failed to update note embeddings", "error", err)
+	}
+}
+
+// uniqueLinkIDs drops empty and repeated IDs, keeping the first occurrence
+func uniqueLinkIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+
+		if _, exists := seen[id]; exists {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	return unique
+}
+
+// sortedLinkIDs returns a sorted copy of the IDs
+func sortedLinkIDs(ids []string) []string {
+	sorted := make([]string, len(ids))
+	copy(sorted, ids)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// removeLinkID returns the IDs without the given one
+func removeLinkID(ids []string, id string) []string {
+	kept := make([]string, 0, len(ids))
+
+	for _, existing := range ids {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+
+	return kept
+}
+
+// refreshBacklinkCacheForNote replaces the cached links of one note after
+// it was edited, without waiting for the next full rebuild
+func refreshBacklinkCacheForNote(sourceID, content string) {
+	targetIDs := uniqueLinkIDs(ExtractLinksFromContent(content))
+	contactLinkIDs := uniqueLinkIDs(extractContactLinksFromContent(content, buildContactLinkMatchers(os.Getenv("GROUNDWAVE_BASE_URL"))))
+
+	backlinkMutex.Lock()
+	defer backlinkMutex.Unlock()
+
+	for _, targetID := range forwardLinkCache[sourceID] {
+		if backlinks := removeLinkID(backlinkCache[targetID], sourceID); len(backlinks) > 0 {
+			backlinkCache[targetID] = backlinks
+		} else {
+			delete(backlinkCache, targetID)
+		}
+	}
+
+	for contactID, sourceIDs := range contactLinkCache {
+		if links := removeLinkID(sourceIDs, sourceID); len(links) > 0 {
+			contactLinkCache[contactID] = links
+		} else {
+			delete(contactLinkCache, contactID)
+		}
+	}
+
+	for _, targetID := range targetIDs {
+		backlinkCache[targetID] = append(backlinkCache[targetID], sourceID)
+	}
+
+	for _, contactID := range contactLinkIDs {
+		contactLinkCache[contactID] = append(contactLinkCache[contactID], sourceID)
+	}
+
+	forwardLinkCache[sourceID] = sortedLinkIDs(targetIDs)
+	publicNoteCache[sourceID] = utils.IsPublicAccess(content)
 }
 
 // BuildJournalCache scans daily journal entries and caches them for the timeline.
@@ -304,10 +359,14 @@ func BuildJournalCache(ctx context.Context) error {
 		return fmt.Errorf("failed to list daily org files: %w", err)
 	}
 
-	return buildJournalCacheFromFiles(ctx, files)
+	applyJournalCache(fetchZKIndexedFiles(ctx, nil, files), time.Now())
+
+	return nil
 }
 
-func buildJournalCacheFromFiles(ctx context.Context, files []string) error {
+// applyJournalCache replaces the journal cache with the daily files among
+// the given files
+func applyJournalCache(files []zkIndexedFile, builtAt time.Time) {
 	logger.Info("Building journal cache")
 
 	startTime := time.Now()
@@ -317,61 +376,51 @@ func buildJournalCacheFromFiles(ctx context.Context, files []string) error {
 	filesSkipped := 0
 
 	for _, file := range files {
-		if !journalFileFormat.MatchString(file) {
+		if file.Kind != zkFileKindDaily || !journalFileFormat.MatchString(file.Filename) {
 			continue
 		}
 
-		dateString := strings.TrimSuffix(file, ".org")
-
-		parsedDate, err := time.Parse("2006-01-02", dateString)
-		if err != nil {
+		if file.Date == nil {
 			filesSkipped++
 			continue
 		}
 
-		content, err := FetchDailyOrgFile(ctx, file)
-		if err != nil {
-			logger.Warn("Skipping unreadable journal file", "file", file, "error", err)
+		dateString := strings.TrimSuffix(file.Filename, ".org")
 
-			filesSkipped++
-
-			continue
-		}
-
-		htmlBody, err := utils.ParseOrgToHTML(content)
+		htmlBody, err := utils.ParseOrgToHTML(file.Content)
 		if err != nil {
-			logger.Warn("Skipping journal file due to parse error", "file", file, "error", err)
+			logger.Warn("Skipping journal file due to parse error", "file", file.Filename, "error", err)
 
 			filesSkipped++
 
 			continue
 		}
 
-		previewContent, hasMore := buildJournalPreview(content, 2, 480)
+		previewContent, hasMore := buildJournalPreview(file.Content, 2, 480)
 
 		previewHTML := ""
 		if previewContent != "" {
 			previewHTML, err = utils.ParseOrgToHTML(previewContent)
 			if err != nil {
-				logger.Warn("Failed to parse journal preview", "file", file, "error", err)
+				logger.Warn("Failed to parse journal preview", "file", file.Filename, "error", err)
 
 				previewHTML = ""
 			}
 		}
 
-		title := utils.ExtractTitle(content)
+		title := file.Title
 		if title == "Untitled Note" {
 			title = dateString
 		}
 
 		tempCache[dateString] = JournalEntry{
-			Date:       parsedDate,
-			Filename:   file,
+			Date:       *file.Date,
+			Filename:   file.Filename,
 			Title:      title,
 			HTMLBody:   template.HTML(htmlBody),    //nolint:gosec // HTML is generated by trusted org parser.
 			Preview:    template.HTML(previewHTML), //nolint:gosec // HTML is generated by trusted org parser.
 			HasMore:    hasMore,
-			UpdatedAt:  time.Now(),
+			UpdatedAt:  builtAt,
 			DateString: dateString,
 		}
 		filesProcessed++
@@ -380,106 +429,59 @@ func buildJournalCacheFromFiles(ctx context.Context, files []string) error {
 	journalMutex.Lock()
 
 	journalCache = tempCache
-	lastJournalBuild = time.Now()
+	lastJournalBuild = builtAt
 
 	journalMutex.Unlock()
 
 	duration := time.Since(startTime)
 	logger.Infof("Journal cache built: %d files processed, %d skipped, %d entries, took %v",
 		filesProcessed, filesSkipped, len(tempCache), duration)
-
-	return nil
 }
 
 // BuildZKTimelineNotesCache scans zettelkasten notes and caches them for timeline display.
 func BuildZKTimelineNotesCache(ctx context.Context) error {
+	config, err := GetZKConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load zettelkasten config: %w", err)
+	}
+
 	files, err := ListOrgFiles(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list org files: %w", err)
 	}
 
-	return buildZKTimelineNotesCacheFromFiles(ctx, files)
+	applyZKTimelineNotesCache(fetchZKIndexedFiles(ctx, files, nil), config.IndexFile, time.Now())
+
+	return nil
 }
 
-func buildZKTimelineNotesCacheFromFiles(ctx context.Context, files []string) error {
+// applyZKTimelineNotesCache replaces the timeline cache with the
+// timestamped notes among the given files
+func applyZKTimelineNotesCache(files []zkIndexedFile, indexFile string, builtAt time.Time) {
 	logger.Info("Building zettelkasten timeline note cache")
 
 	startTime := time.Now()
 
-	config, err := GetZKConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load zettelkasten config: %w", err)
-	}
-
 	tempCache := make(map[string][]ZKTimelineNote)
 	filesProcessed := 0
 	filesSkipped := 0
 
 	for _, file := range files {
-		if file == config.IndexFile {
-			continue
-		}
-
-		if !zkNoteFileFormat.MatchString(file) {
-			continue
-		}
-
-		matches := zkTimestampFormat.FindStringSubmatch(file)
-		if len(matches) < 2 {
-			filesSkipped++
+		if file.Kind != zkFileKindNote || file.Filename == indexFile {
 			continue
 		}
 
-		parsedTimestamp, err := time.Parse("20060102150405", matches[1])
-		if err != nil {
-			filesSkipped++
-			continue
-		}
-
-		content, err := FetchOrgFile(ctx, file)
-		if err != nil {
-			logger.Warn("Skipping unreadable note file", "file", file, "error", err)
-
-			filesSkipped++
-
+		if !zkNoteFileFormat.MatchString(file.Filename) {
 			continue
 		}
 
-		noteID, err := utils.ExtractIDProperty(content)
-		if err != nil {
+		note, ok := zkTimelineNote(file.Filename, file.NoteID, file.Title, file.Date)
+		if !ok {
 			filesSkipped++
 			continue
 		}
 
-		title := utils.ExtractTitle(content)
-		if title == "Untitled Note" {
-			title = strings.TrimSuffix(file, ".org")
-		}
-
-		dateString := parsedTimestamp.Format("2006-01-02")
-
-		overrideDate, hasOverride := utils.ExtractDateDirective(content)
-		if hasOverride {
-			overrideDateString := overrideDate.Format("2006-01-02")
-			if overrideDateString != dateString {
-				parsedTimestamp = time.Date(
-					overrideDate.Year(),
-					overrideDate.Month(),
-					overrideDate.Day(),
-					0, 0, 0, 0,
-					parsedTimestamp.Location(),
-				)
-				dateString = overrideDateString
-			}
-		}
-
-		tempCache[dateString] = append(tempCache[dateString], ZKTimelineNote{
-			ID:         noteID,
-			Title:      title,
-			Filename:   file,
-			Timestamp:  parsedTimestamp,
-			DateString: dateString,
-		})
+		tempCache[note.DateString] = append(tempCache[note.DateString], note)
 		filesProcessed++
 	}
 
@@ -492,15 +494,119 @@ func buildZKTimelineNotesCacheFromFiles(ctx context.Context, files []string) err
 	zkNoteMutex.Lock()
 
 	zkNoteCache = tempCache
-	lastZKNoteBuild = time.Now()
+	lastZKNoteBuild = builtAt
 
 	zkNoteMutex.Unlock()
 
 	duration := time.Since(startTime)
 	logger.Infof("Zettelkasten note cache built: %d files processed, %d skipped, %d dates, took %v",
 		filesProcessed, filesSkipped, len(tempCache), duration)
+}
 
-	return nil
+// zkNoteFileTimestamp parses the timestamp prefix of a note filename
+func zkNoteFileTimestamp(file string) (time.Time, bool) {
+	matches := zkTimestampFormat.FindStringSubmatch(file)
+	if len(matches) < 2 {
+		return time.Time{}, false
+	}
+
+	parsedTimestamp, err := time.Parse("20060102150405", matches[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return parsedTimestamp, true
+}
+
+// parseZKTimelineNote builds the timeline entry of a timestamped note file
+func parseZKTimelineNote(file, content string) (ZKTimelineNote, bool) {
+	noteID, err := utils.ExtractIDProperty(content)
+	if err != nil {
+		return ZKTimelineNote{}, false
+	}
+
+	var date *time.Time
+	if overrideDate, ok := utils.ExtractDateDirective(content); ok {
+		date = &overrideDate
+	}
+
+	return zkTimelineNote(file, noteID, utils.ExtractTitle(content), date)
+}
+
+// zkTimelineNote builds the timeline entry of a timestamped note file.
+// A #+DATE directive moves the note to another day.
+func zkTimelineNote(file, noteID, title string, overrideDate *time.Time) (ZKTimelineNote, bool) {
+	if noteID == "" || !zkNoteFileFormat.MatchString(file) {
+		return ZKTimelineNote{}, false
+	}
+
+	parsedTimestamp, ok := zkNoteFileTimestamp(file)
+	if !ok {
+		return ZKTimelineNote{}, false
+	}
+
+	if title == "Untitled Note" {
+		title = strings.TrimSuffix(file, ".org")
+	}
+
+	dateString := parsedTimestamp.Format("2006-01-02")
+
+	if overrideDate != nil {
+		overrideDateString := overrideDate.Format("2006-01-02")
+		if overrideDateString != dateString {
+			parsedTimestamp = time.Date(
+				overrideDate.Year(),
+				overrideDate.Month(),
+				overrideDate.Day(),
+				0, 0, 0, 0,
+				parsedTimestamp.Location(),
+			)
+			dateString = overrideDateString
+		}
+	}
+
+	return ZKTimelineNote{
+		ID:         noteID,
+		Title:      title,
+		Filename:   file,
+		Timestamp:  parsedTimestamp,
+		DateString: dateString,
+	}, true
+}
+
+// refreshZKTimelineNote replaces the cached timeline entry of one note
+// after it was edited or created
+func refreshZKTimelineNote(noteID, file, content string) {
+	note, ok := parseZKTimelineNote(file, content)
+
+	zkNoteMutex.Lock()
+	defer zkNoteMutex.Unlock()
+
+	for date, notes := range zkNoteCache {
+		kept := make([]ZKTimelineNote, 0, len(notes))
+
+		for _, existing := range notes {
+			if existing.ID != noteID {
+				kept = append(kept, existing)
+			}
+		}
+
+		if len(kept) > 0 {
+			zkNoteCache[date] = kept
+		} else {
+			delete(zkNoteCache, date)
+		}
+	}
+
+	if !ok {
+		return
+	}
+
+	notes := append(zkNoteCache[note.DateString], note)
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Timestamp.After(notes[j].Timestamp)
+	})
+	zkNoteCache[note.DateString] = notes
 }
 
 func buildJournalPreview(content string, maxParagraphs, maxChars int) (string, bool) {
@@ -718,37 +824,144 @@ func GetLastCacheBuildTime() time.Time {
 	return lastCacheBuild
 }
 
-// RebuildZettelkastenCaches performs a single sweep to rebuild all cache layers.
-func RebuildZettelkastenCaches(ctx context.Context) error {
+// rebuildMutex keeps cache rebuilds from the worker and the refresh button
+// from racing on the file index
+var rebuildMutex sync.Mutex
+
+// zkIndexState runs one search and embedding pass at a time, keeping only
+// the files of the latest rebuild queued behind it
+var zkIndexState struct {
+	sync.Mutex
+
+	running bool
+	pending []zkIndexedFile
+	queued  bool
+	done    sync.WaitGroup
+}
+
+// scheduleZettelkastenIndex updates the search index and embeddings in the
+// background. Embedding every note can take minutes, so rebuilds report as
+// soon as the caches are swapped rather than waiting for it.
+func scheduleZettelkastenIndex(ctx context.Context, files []zkIndexedFile) {
+	zkIndexState.Lock()
+	defer zkIndexState.Unlock()
+
+	zkIndexState.pending = files
+	zkIndexState.queued = true
+
+	if zkIndexState.running {
+		return
+	}
+
+	zkIndexState.running = true
+	zkIndexState.done.Add(1)
+
+	go func() {
+		defer zkIndexState.done.Done()
+
+		for {
+			zkIndexState.Lock()
+
+			if !zkIndexState.queued {
+				zkIndexState.running = false
+				zkIndexState.Unlock()
+
+				return
+			}
+
+			files := zkIndexState.pending
+			zkIndexState.pending = nil
+			zkIndexState.queued = false
+			zkIndexState.Unlock()
+
+			indexZettelkastenFiles(ctx, files)
+		}
+	}()
+}
+
+// waitForZettelkastenIndex blocks until no index pass is running
+func waitForZettelkastenIndex() {
+	zkIndexState.done.Wait()
+}
+
+// RebuildZettelkastenCaches lists the WebDAV directories once, fetches only
+// the files that changed since they were last indexed and rebuilds every
+// cache layer from the index. The report lists what changed. Search and
+// embeddings are brought up to date in the background afterwards.
+func RebuildZettelkastenCaches(ctx context.Context) (*ZKCacheRebuildReport, error) {
+	rebuildMutex.Lock()
+	defer rebuildMutex.Unlock()
+
 	logger.Info("Rebuilding zettelkasten caches")
 
 	startTime := time.Now()
 
-	orgFiles, err := ListOrgFiles(ctx)
+	config, err := GetZKConfig()
 	if err != nil {
-		return fmt.Errorf("failed to list org files: %w", err)
+		return nil, fmt.Errorf("failed to load zettelkasten config: %w", err)
 	}
 
-	dailyFiles, err := ListDailyOrgFiles(ctx)
+	orgFiles, err := listZKRemoteOrgFiles(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list daily org files: %w", err)
+		return nil, fmt.Errorf("failed to list org files: %w", err)
+	}
+
+	dailyFiles, err := listZKRemoteDailyFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily org files: %w", err)
 	}
 
 	logger.Infof("Cache rebuild scan: %d org files, %d daily files", len(orgFiles), len(dailyFiles))
 
-	if err := buildBacklinkCacheFromFiles(ctx, orgFiles, dailyFiles); err != nil {
-		return err
+	// Without a database every file is fetched, as nothing is remembered
+	indexed, _, err := listZKIndexedFiles(ctx)
+	if err != nil && !errors.Is(err, ErrDatabaseConnectionNotInitialized) {
+		return nil, err
 	}
 
-	if err := buildJournalCacheFromFiles(ctx, dailyFiles); err != nil {
-		return err
+	report := &ZKCacheRebuildReport{}
+	files, updated, removed := syncZKIndexedFiles(ctx, orgFiles, dailyFiles, indexed, report)
+
+	if err := saveZKIndexedFiles(ctx, updated, removed); err != nil && !errors.Is(err, ErrDatabaseConnectionNotInitialized) {
+		return nil, err
 	}
 
-	if err := buildZKTimelineNotesCacheFromFiles(ctx, orgFiles); err != nil {
+	builtAt := time.Now()
+	applyBacklinkCache(files, builtAt)
+	applyJournalCache(files, builtAt)
+	applyZKTimelineNotesCache(files, config.IndexFile, builtAt)
+	scheduleZettelkastenIndex(ctx, files)
+
+	report.Duration = time.Since(startTime)
+	logger.Infof("Cache rebuild completed in %v: %d added, %d changed, %d removed, %d unchanged, %d unreadable",
+		report.Duration, len(report.Added), len(report.Changed), len(report.Removed), report.Unchanged, len(report.Failed))
+
+	return report, nil
+}
+
+// LoadZettelkastenCachesFromIndex fills the caches from the file index kept
+// by the last rebuild, so they are ready after a restart without fetching
+// anything from WebDAV
+func LoadZettelkastenCachesFromIndex(ctx context.Context) error {
+	files, checkedAt, err := listZKIndexedFiles(ctx)
+	if err != nil {
 		return err
 	}
 
-	logger.Infof("Cache rebuild completed in %v", time.Since(startTime))
+	if len(files) == 0 {
+		return nil
+	}
+
+	config, err := GetZKConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load zettelkasten config: %w", err)
+	}
+
+	applyBacklinkCache(files, checkedAt)
+	applyJournalCache(files, checkedAt)
+	applyZKTimelineNotesCache(files, config.IndexFile, checkedAt)
+
+	logger.Info("Loaded zettelkasten caches from file index", "files", len(files), "checked_at", checkedAt)
 
 	return nil
 }
@@ -757,12 +970,17 @@ func RebuildZettelkastenCaches(ctx context.Context) error {
 // refreshes zettelkasten-related caches.
 func StartRebuildCacheWorker(ctx context.Context) {
 	go func() {
+		// Serve what was indexed before the restart until the first rebuild
+		if err := LoadZettelkastenCachesFromIndex(ctx); err != nil {
+			logger.Warn("Failed to load zettelkasten caches from file index", "error", err)
+		}
+
 		// Initial delay to let the application start up
 		logger.Info("Cache rebuild worker starting in 5 seconds")
 		time.Sleep(5 * time.Second)
 
 		// Initial cache build
-		if err := RebuildZettelkastenCaches(ctx); err != nil {
+		if _, err := RebuildZettelkastenCaches(ctx); err != nil {
 			logger.Errorf("Error building initial caches: %v", err)
 		}
 
@@ -776,7 +994,7 @@ func StartRebuildCacheWorker(ctx context.Context) {
 				logger.Info("Cache rebuild worker shutting down")
 				return
 			case <-ticker.C:
-				if err := RebuildZettelkastenCaches(ctx); err != nil {
+				if _, err := RebuildZettelkastenCaches(ctx); err != nil {
 					logger.Errorf("Error refreshing caches: %v", err)
 				}
 			}
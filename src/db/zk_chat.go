@@ -10,10 +10,12 @@ import (
 	"strings"
 )
 
-func buildZKChatPrompt(notes []ZKChatNote, message string) string {
+func buildZKChatPrompt(notes []ZKChatNote, excerpts []ZKNoteChunk, message string) string {
 	var sb strings.Builder
 
-	sb.WriteString("Use the following zettelkasten notes as context. Each note is raw org-mode text.\n\n")
+	if len(notes) > 0 {
+		sb.WriteString("Use the following zettelkasten notes as context. Each note is raw org-mode text.\n\n")
+	}
 
 	for _, note := range notes {
 		sb.WriteString(fmt.Sprintf("Note Title: %s\n", note.Title))
@@ -28,17 +30,62 @@ func buildZKChatPrompt(notes []ZKChatNote, message string) string {
 		sb.WriteString("\n---\n\n")
 	}
 
+	if len(excerpts) > 0 {
+		sb.WriteString("Excerpts retrieved from other notes that may be relevant, most relevant first:\n\n")
+
+		for _, excerpt := range excerpts {
+			sb.WriteString(fmt.Sprintf("Note Title: %s\n", excerpt.Title))
+			sb.WriteString(fmt.Sprintf("Note ID: %s\n", excerpt.NoteID))
+			sb.WriteString("Excerpt:\n")
+			sb.WriteString(excerpt.Content)
+
+			if !strings.HasSuffix(excerpt.Content, "\n") {
+				sb.WriteString("\n")
+			}
+
+			sb.WriteString("\n---\n\n")
+		}
+	}
+
+	if len(notes) == 0 && len(excerpts) == 0 {
+		sb.WriteString("No notes were found for this question.\n\n")
+	}
+
 	sb.WriteString("User question:\n")
 	sb.WriteString(message)
 
 	return sb.String()
 }
 
-// StreamZKChat streams a zettelkasten chat response from Ollama.
+// retrieveZKChatExcerpts finds note chunks related to a question when
+// embeddings are configured. Notes given in full are not retrieved again.
+// Failures are logged and leave the chat to the selected notes.
+func retrieveZKChatExcerpts(ctx context.Context, notes []ZKChatNote, message string) []ZKNoteChunk {
+	if !ZKEmbeddingsConfigured() {
+		return nil
+	}
+
+	exclude := make([]string, 0, len(notes))
+	for _, note := range notes {
+		exclude = append(exclude, note.ID)
+	}
+
+	excerpts, err := SearchZKNoteChunks(ctx, message, zkChatRetrievalLimit, exclude)
+	if err != nil {
+		logger.Warn("Failed to retrieve notes for zettelkasten chat", "error", err)
+		return nil
+	}
+
+	return excerpts
+}
+
+// StreamZKChat streams a zettelkasten chat response from Ollama. When note
+// embeddings are configured, the chunks closest to the question are added
+// to the selected notes.
 func StreamZKChat(ctx context.Context, notes []ZKChatNote, message string, onChunk func(string) error) error {
-	prompt := buildZKChatPrompt(notes, message)
+	prompt := buildZKChatPrompt(notes, retrieveZKChatExcerpts(ctx, notes, message), message)
 
-	systemPrompt := "You are a research assistant for a personal zettelkasten. Use the provided notes as primary sources. If details are missing, say so clearly. Cite note titles with inline links to /zk/<id>. Prefer org-roam format [[id:UUID][Title]], but [Title](/zk/UUID) is also acceptable. Provide structured, concise responses. Use markdown for emphasis and lists, but avoid headings."
+	systemPrompt := "You are a research assistant for a personal zettelkasten. Use the provided notes and excerpts as primary sources. If details are missing, say so clearly. Cite the notes you use with inline links to /zk/<id> using their Note ID. Prefer org-roam format [[id:UUID][Title]], but [Title](/zk/UUID) is also acceptable. Provide structured, concise responses. Use markdown for emphasis and lists, but avoid headings."
 
 	return streamChatCompletion(ctx, systemPrompt, prompt, onChunk)
 }
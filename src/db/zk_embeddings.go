@@ -0,0 +1,785 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Limits on how notes are split and how much is retrieved for a chat
+const (
+	zkEmbeddingChunkSize   = 1500 // runes per chunk
+	zkChatRetrievalLimit   = 6
+	zkRelatedNotesLimit    = 8
+	zkEmbeddingHTTPTimeout = 120 * time.Second
+)
+
+// ZKNoteChunk is a piece of a note ranked against a question
+type ZKNoteChunk struct {
+	NoteID  string
+	Title   string
+	Content string
+	Score   float64
+}
+
+// RelatedZKNote is a note whose text is close to another note
+type RelatedZKNote struct {
+	ID    string
+	Title string
+	Score float64
+}
+
+// zkEmbeddedChunk is a stored chunk with its embedding
+type zkEmbeddedChunk struct {
+	NoteID     string
+	ChunkIndex int
+	Title      string
+	Content    string
+	Embedding  []float32
+}
+
+// zkEmbeddedNote is the stored chunks of a note along with their mean,
+// worked out once when the note is loaded or embedded
+type zkEmbeddedNote struct {
+	Title  string
+	Mean   []float32
+	Chunks []zkEmbeddedChunk
+}
+
+// The embeddings of every note are kept in memory once loaded, so related
+// notes and chat retrieval do not read every vector from Postgres on each
+// request. Indexing keeps the copy in step with the table.
+var (
+	zkEmbeddingMutex sync.RWMutex
+	// zkEmbeddingModel is the model the notes were loaded for, empty until
+	// they are loaded
+	zkEmbeddingModel string
+	zkEmbeddingNotes = make(map[string]zkEmbeddedNote)
+)
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GetOllamaEmbedConfig loads the Ollama embeddings configuration from
+// environment variables. The embeddings model is set apart from the chat
+// model, as chat models are rarely good at embeddings.
+func GetOllamaEmbedConfig() (*OllamaConfig, error) {
+	url := os.Getenv("OLLAMA_URL")
+	model := os.Getenv("OLLAMA_EMBED_MODEL")
+
+	if url == "" || model == "" {
+		return nil, ErrOllamaEmbedConfigIncomplete
+	}
+
+	return &OllamaConfig{
+		URL:   url,
+		Model: model,
+	}, nil
+}
+
+// ZKEmbeddingsConfigured reports whether notes are embedded for related
+// notes and chat retrieval
+func ZKEmbeddingsConfigured() bool {
+	_, err := GetOllamaEmbedConfig()
+	return err == nil
+}
+
+// createEmbeddings embeds each input through the OpenAI-compatible endpoint
+// of Ollama, returning the embeddings in input order
+func createEmbeddings(ctx context.Context, config *OllamaConfig, inputs []string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(embeddingRequest{Model: config.Model, Input: inputs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(config.URL, "/") + "/v1/embeddings"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Timeout: zkEmbeddingHTTPTimeout,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Ollama: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close Ollama response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: %d: %s", ErrOllamaReturnedStatus, resp.StatusCode, string(body))
+	}
+
+	var embedResp embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOllamaEmbeddingDecode, err)
+	}
+
+	if embedResp.Error != nil {
+		return nil, fmt.Errorf("%w: %s", ErrOllamaError, embedResp.Error.Message)
+	}
+
+	if len(embedResp.Data) != len(inputs) {
+		return nil, fmt.Errorf("%w: got %d for %d inputs", ErrOllamaEmbeddingCount, len(embedResp.Data), len(inputs))
+	}
+
+	embeddings := make([][]float32, len(inputs))
+
+	for _, item := range embedResp.Data {
+		if item.Index < 0 || item.Index >= len(inputs) || embeddings[item.Index] != nil || len(item.Embedding) == 0 {
+			return nil, fmt.Errorf("%w: bad index %d", ErrOllamaEmbeddingCount, item.Index)
+		}
+
+		embeddings[item.Index] = item.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// chunkZKNote splits the body of a note into paragraphs grouped up to
+// zkEmbeddingChunkSize runes. The properties drawer and #+ keywords are left
+// out, as they carry IDs and settings rather than meaning. A note without a
+// body gives a single empty chunk, so it is still embedded by its title.
+func chunkZKNote(body string) []string {
+	var (
+		paragraphs []string
+		current    []string
+		inDrawer   bool
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inDrawer = true
+			continue
+		case inDrawer:
+			if strings.EqualFold(trimmed, ":END:") {
+				inDrawer = false
+			}
+
+			continue
+		case strings.HasPrefix(trimmed, "#+"):
+			continue
+		case trimmed == "":
+			flush()
+			continue
+		}
+
+		current = append(current, strings.TrimRight(line, " \t"))
+	}
+
+	flush()
+
+	var (
+		chunks []string
+		sb     strings.Builder
+		size   int
+	)
+
+	for _, paragraph := range paragraphs {
+		runes := []rune(paragraph)
+
+		// Paragraphs longer than a chunk are cut at the limit
+		for len(runes) > zkEmbeddingChunkSize {
+			if size > 0 {
+				chunks = append(chunks, sb.String())
+				sb.Reset()
+
+				size = 0
+			}
+
+			chunks = append(chunks, string(runes[:zkEmbeddingChunkSize]))
+			runes = runes[zkEmbeddingChunkSize:]
+		}
+
+		if len(runes) == 0 {
+			continue
+		}
+
+		if size > 0 && size+2+len(runes) > zkEmbeddingChunkSize {
+			chunks = append(chunks, sb.String())
+			sb.Reset()
+
+			size = 0
+		}
+
+		if size > 0 {
+			sb.WriteString("\n\n")
+
+			size += 2
+		}
+
+		sb.WriteString(string(runes))
+
+		size += len(runes)
+	}
+
+	if size > 0 {
+		chunks = append(chunks, sb.String())
+	}
+
+	if len(chunks) == 0 {
+		return []string{""}
+	}
+
+	return chunks
+}
+
+// zkEmbeddingInput is the text embedded for a chunk, led by the note title
+// so short chunks keep their topic
+func zkEmbeddingInput(title, chunk string) string {
+	if chunk == "" {
+		return title
+	}
+
+	return title + "\n\n" + chunk
+}
+
+// zkNoteContentHash identifies the text a note was embedded from
+func zkNoteContentHash(doc zkSearchDocument) string {
+	sum := sha256.Sum256([]byte(doc.Title + "\x00" + doc.Body))
+	return hex.EncodeToString(sum[:])
+}
+
+// cosineSimilarity compares two embeddings, giving 0 when they cannot be
+// compared
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// meanEmbedding averages the chunk embeddings of a note into one vector
+func meanEmbedding(chunks []zkEmbeddedChunk) []float32 {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	mean := make([]float32, len(chunks[0].Embedding))
+
+	for _, chunk := range chunks {
+		if len(chunk.Embedding) != len(mean) {
+			continue
+		}
+
+		for i, value := range chunk.Embedding {
+			mean[i] += value / float32(len(chunks))
+		}
+	}
+
+	return mean
+}
+
+// newZKEmbeddedNote groups the chunks of a note with their mean
+func newZKEmbeddedNote(chunks []zkEmbeddedChunk) zkEmbeddedNote {
+	note := zkEmbeddedNote{Mean: meanEmbedding(chunks), Chunks: chunks}
+	if len(chunks) > 0 {
+		note.Title = chunks[0].Title
+	}
+
+	return note
+}
+
+// rankZKNoteChunks orders chunks by similarity to a query embedding,
+// leaving out chunks of the excluded notes
+func rankZKNoteChunks(query []float32, notes map[string]zkEmbeddedNote, exclude map[string]bool, limit int) []ZKNoteChunk {
+	ranked := make([]ZKNoteChunk, 0, len(notes))
+
+	for noteID, note := range notes {
+		if exclude[noteID] {
+			continue
+		}
+
+		for _, chunk := range note.Chunks {
+			ranked = append(ranked, ZKNoteChunk{
+				NoteID:  chunk.NoteID,
+				Title:   chunk.Title,
+				Content: chunk.Content,
+				Score:   cosineSimilarity(query, chunk.Embedding),
+			})
+		}
+	}
+
+	// Notes come from a map, so ties are broken by note for a stable order
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+
+		if ranked[i].NoteID != ranked[j].NoteID {
+			return ranked[i].NoteID < ranked[j].NoteID
+		}
+
+		return ranked[i].Content < ranked[j].Content
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
+
+// rankRelatedZKNotes orders notes by how close their average embedding is
+// to that of the given note. Notes pointing the other way are left out.
+func rankRelatedZKNotes(noteID string, notes map[string]zkEmbeddedNote, limit int) []RelatedZKNote {
+	target, ok := notes[noteID]
+	if !ok {
+		return nil
+	}
+
+	related := make([]RelatedZKNote, 0, len(notes))
+
+	for id, note := range notes {
+		if id == noteID {
+			continue
+		}
+
+		score := cosineSimilarity(target.Mean, note.Mean)
+		if score <= 0 {
+			continue
+		}
+
+		related = append(related, RelatedZKNote{
+			ID:    id,
+			Title: note.Title,
+			Score: score,
+		})
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+
+		return related[i].ID < related[j].ID
+	})
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related
+}
+
+// listZKEmbeddedChunks loads every stored chunk embedded with a model
+func listZKEmbeddedChunks(ctx context.Context, model string) ([]zkEmbeddedChunk, error) {
+	if pool == nil {
+		return nil, ErrDatabaseConnectionNotInitialized
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT note_id, chunk_index, title, content, embedding
+		FROM zk_note_embeddings
+		WHERE model = $1
+		ORDER BY note_id, chunk_index
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query note embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []zkEmbeddedChunk
+
+	for rows.Next() {
+		var chunk zkEmbeddedChunk
+		if err := rows.Scan(&chunk.NoteID, &chunk.ChunkIndex, &chunk.Title, &chunk.Content, &chunk.Embedding); err != nil {
+			return nil, fmt.Errorf("failed to scan note embedding: %w", err)
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating note embeddings: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// loadZKEmbeddedNotes reads the stored embeddings of a model into memory,
+// unless they already are. The table is read under the write lock, so a note
+// embedded meanwhile is either in the table read or applied after it.
+func loadZKEmbeddedNotes(ctx context.Context, model string) error {
+	zkEmbeddingMutex.RLock()
+	loaded := zkEmbeddingModel == model
+	zkEmbeddingMutex.RUnlock()
+
+	if loaded {
+		return nil
+	}
+
+	zkEmbeddingMutex.Lock()
+	defer zkEmbeddingMutex.Unlock()
+
+	if zkEmbeddingModel == model {
+		return nil
+	}
+
+	chunks, err := listZKEmbeddedChunks(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	byNote := make(map[string][]zkEmbeddedChunk)
+	for _, chunk := range chunks {
+		byNote[chunk.NoteID] = append(byNote[chunk.NoteID], chunk)
+	}
+
+	notes := make(map[string]zkEmbeddedNote, len(byNote))
+	for noteID, noteChunks := range byNote {
+		notes[noteID] = newZKEmbeddedNote(noteChunks)
+	}
+
+	zkEmbeddingModel = model
+	zkEmbeddingNotes = notes
+
+	return nil
+}
+
+// setZKEmbeddedNote replaces the in-memory chunks of a note just embedded
+func setZKEmbeddedNote(model, noteID string, chunks []zkEmbeddedChunk) {
+	zkEmbeddingMutex.Lock()
+	defer zkEmbeddingMutex.Unlock()
+
+	// Notes of another model are read from the table when next needed
+	if zkEmbeddingModel != model {
+		return
+	}
+
+	zkEmbeddingNotes[noteID] = newZKEmbeddedNote(chunks)
+}
+
+// retainZKEmbeddedNotes drops the in-memory notes that are no longer indexed
+func retainZKEmbeddedNotes(model string, noteIDs []string) {
+	zkEmbeddingMutex.Lock()
+	defer zkEmbeddingMutex.Unlock()
+
+	if zkEmbeddingModel != model {
+		zkEmbeddingModel = ""
+		zkEmbeddingNotes = make(map[string]zkEmbeddedNote)
+
+		return
+	}
+
+	keep := make(map[string]struct{}, len(noteIDs))
+	for _, id := range noteIDs {
+		keep[id] = struct{}{}
+	}
+
+	for id := range zkEmbeddingNotes {
+		if _, ok := keep[id]; !ok {
+			delete(zkEmbeddingNotes, id)
+		}
+	}
+}
+
+// SearchZKNoteChunks returns the note chunks closest in meaning to a query,
+// leaving out chunks of the excluded notes
+func SearchZKNoteChunks(ctx context.Context, query string, limit int, excludeNoteIDs []string) ([]ZKNoteChunk, error) {
+	config, err := GetOllamaEmbedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadZKEmbeddedNotes(ctx, config.Model); err != nil {
+		return nil, err
+	}
+
+	zkEmbeddingMutex.RLock()
+	empty := len(zkEmbeddingNotes) == 0
+	zkEmbeddingMutex.RUnlock()
+
+	if empty {
+		return nil, nil
+	}
+
+	embeddings, err := createEmbeddings(ctx, config, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make(map[string]bool, len(excludeNoteIDs))
+	for _, id := range excludeNoteIDs {
+		exclude[id] = true
+	}
+
+	zkEmbeddingMutex.RLock()
+	defer zkEmbeddingMutex.RUnlock()
+
+	return rankZKNoteChunks(embeddings[0], zkEmbeddingNotes, exclude, limit), nil
+}
+
+// ListRelatedZKNotes returns the notes closest in meaning to a note. It only
+// reads stored embeddings, so a note is not compared until it is indexed.
+func ListRelatedZKNotes(ctx context.Context, noteID string) ([]RelatedZKNote, error) {
+	config, err := GetOllamaEmbedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadZKEmbeddedNotes(ctx, config.Model); err != nil {
+		return nil, err
+	}
+
+	zkEmbeddingMutex.RLock()
+	defer zkEmbeddingMutex.RUnlock()
+
+	return rankRelatedZKNotes(noteID, zkEmbeddingNotes, zkRelatedNotesLimit), nil
+}
+
+// listZKEmbeddedHashes returns the content hash of every note embedded with
+// a model
+func listZKEmbeddedHashes(ctx context.Context, model string) (map[string]string, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT DISTINCT note_id, content_hash
+		FROM zk_note_embeddings
+		WHERE model = $1
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query note embedding hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]string)
+
+	for rows.Next() {
+		var noteID, hash string
+		if err := rows.Scan(&noteID, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan note embedding hash: %w", err)
+		}
+
+		hashes[noteID] = hash
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating note embedding hashes: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// embedZKNote embeds the chunks of a note and replaces its stored chunks
+func embedZKNote(ctx context.Context, config *OllamaConfig, doc zkSearchDocument, hash string) error {
+	title := strings.ReplaceAll(doc.Title, "\x00", "")
+	chunks := chunkZKNote(strings.ReplaceAll(doc.Body, "\x00", ""))
+
+	inputs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		inputs[i] = zkEmbeddingInput(title, chunk)
+	}
+
+	embeddings, err := createEmbeddings(ctx, config, inputs)
+	if err != nil {
+		return fmt.Errorf("failed to embed note %s: %w", doc.NoteID, err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Warn("Failed to rollback note embeddings", "error", err)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM zk_note_embeddings WHERE note_id = $1`, doc.NoteID); err != nil {
+		return fmt.Errorf("failed to remove old note embeddings: %w", err)
+	}
+
+	stored := make([]zkEmbeddedChunk, len(chunks))
+
+	for i, chunk := range chunks {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO zk_note_embeddings (note_id, chunk_index, title, content, content_hash, model, embedding)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, doc.NoteID, i, title, chunk, hash, config.Model, embeddings[i]); err != nil {
+			return fmt.Errorf("failed to store note embedding: %w", err)
+		}
+
+		stored[i] = zkEmbeddedChunk{NoteID: doc.NoteID, ChunkIndex: i, Title: title, Content: chunk, Embedding: embeddings[i]}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit note embeddings: %w", err)
+	}
+
+	setZKEmbeddedNote(config.Model, doc.NoteID, stored)
+
+	return nil
+}
+
+// zkEmbeddingRejected reports whether Ollama answered but could not embed a
+// note, such as one longer than the model's context, as opposed to being
+// unreachable
+func zkEmbeddingRejected(err error) bool {
+	return errors.Is(err, ErrOllamaReturnedStatus) || errors.Is(err, ErrOllamaError) ||
+		errors.Is(err, ErrOllamaEmbeddingCount) || errors.Is(err, ErrOllamaEmbeddingDecode)
+}
+
+// indexZettelkastenEmbeddings brings the stored embeddings in step with the
+// given notes. Only notes whose text or embeddings model changed are sent to
+// Ollama, and notes that are gone are removed. Journal entries are left out.
+func indexZettelkastenEmbeddings(ctx context.Context, docs []zkSearchDocument) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	config, err := GetOllamaEmbedConfig()
+	if err != nil {
+		return err
+	}
+
+	hashes, err := listZKEmbeddedHashes(ctx, config.Model)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(docs))
+	ids := make([]string, 0, len(docs))
+	embedded, failed := 0, 0
+
+	for _, doc := range docs {
+		if doc.IsDaily {
+			continue
+		}
+
+		if _, exists := seen[doc.NoteID]; exists {
+			continue
+		}
+
+		seen[doc.NoteID] = struct{}{}
+		ids = append(ids, doc.NoteID)
+
+		hash := zkNoteContentHash(doc)
+		if hashes[doc.NoteID] == hash {
+			continue
+		}
+
+		// A note Ollama rejects is retried on the next rebuild without
+		// holding back the rest, while an unreachable Ollama or database
+		// stops the pass
+		if err := embedZKNote(ctx, config, doc, hash); err != nil {
+			if !zkEmbeddingRejected(err) {
+				return err
+			}
+
+			logger.Warn("Failed to embed note, skipping it", "note_id", doc.NoteID, "error", err)
+
+			failed++
+
+			continue
+		}
+
+		embedded++
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM zk_note_embeddings WHERE NOT (note_id = ANY($1::text[])) OR model <> $2`, ids, config.Model); err != nil {
+		return fmt.Errorf("failed to remove stale note embeddings: %w", err)
+	}
+
+	retainZKEmbeddedNotes(config.Model, ids)
+
+	if embedded > 0 || failed > 0 {
+		logger.Info("Note embeddings updated", "notes", embedded, "failed", failed)
+	}
+
+	return nil
+}
+
+// indexZettelkastenEmbedding embeds a single note when its text changed
+func indexZettelkastenEmbedding(ctx context.Context, doc zkSearchDocument) error {
+	if pool == nil {
+		return ErrDatabaseConnectionNotInitialized
+	}
+
+	config, err := GetOllamaEmbedConfig()
+	if err != nil {
+		return err
+	}
+
+	hash := zkNoteContentHash(doc)
+
+	var stored string
+
+	err = pool.QueryRow(ctx, `
+		SELECT content_hash FROM zk_note_embeddings
+		WHERE note_id = $1 AND model = $2
+		LIMIT 1
+	`, doc.NoteID, config.Model).Scan(&stored)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to query note embedding hash: %w", err)
+	}
+
+	if stored == hash {
+		return nil
+	}
+
+	return embedZKNote(ctx, config, doc, hash)
+}
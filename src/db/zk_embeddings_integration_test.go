@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestEmbeddingsServer embeds text as counts of a few topic words
+func newTestEmbeddingsServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		calls.Add(1)
+
+		// Stands in for a note longer than the model's context
+		for _, input := range req.Input {
+			if strings.Contains(input, "too long") {
+				http.Error(w, `{"error":"input length exceeds the context length"}`, http.StatusBadRequest)
+				return
+			}
+		}
+
+		var resp embeddingResponse
+
+		resp.Data = make([]struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}, len(req.Input))
+
+		for i, input := range req.Input {
+			input = strings.ToLower(input)
+			resp.Data[i].Index = i
+			resp.Data[i].Embedding = []float32{
+				float32(strings.Count(input, "antenna")),
+				float32(strings.Count(input, "garden")),
+				0.01,
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestZettelkastenEmbeddings(t *testing.T) {
+	resetDatabase(t)
+
+	var calls atomic.Int32
+
+	server := newTestEmbeddingsServer(t, &calls)
+	t.Setenv("OLLAMA_URL", server.URL)
+	t.Setenv("OLLAMA_EMBED_MODEL", "embed-model")
+
+	ctx := testContext()
+	docs := []zkSearchDocument{
+		{NoteID: "dipole", Title: "Dipole antenna", Body: "Cutting a dipole antenna for 20m."},
+		{NoteID: "vertical", Title: "Vertical antenna", Body: "A vertical antenna needs radials."},
+		{NoteID: "tomatoes", Title: "Tomatoes", Body: "The garden needs water."},
+		{NoteID: DailyBacklinkPrefix + "2025-03-01", Title: "2025-03-01", Body: "Antenna day", IsDaily: true},
+	}
+
+	if err := indexZettelkastenEmbeddings(ctx, docs); err != nil {
+		t.Fatalf("indexZettelkastenEmbeddings failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 notes to be embedded, got %d calls", got)
+	}
+
+	// Unchanged notes are not embedded again
+	if err := indexZettelkastenEmbeddings(ctx, docs); err != nil {
+		t.Fatalf("indexZettelkastenEmbeddings failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected unchanged notes to be skipped, got %d calls", got)
+	}
+
+	related, err := ListRelatedZKNotes(ctx, "dipole")
+	if err != nil {
+		t.Fatalf("ListRelatedZKNotes failed: %v", err)
+	}
+
+	if len(related) == 0 || related[0].ID != "vertical" || related[0].Title != "Vertical antenna" {
+		t.Fatalf("expected the vertical antenna note first, got %#v", related)
+	}
+
+	chunks, err := SearchZKNoteChunks(ctx, "garden", 2, []string{"vertical"})
+	if err != nil {
+		t.Fatalf("SearchZKNoteChunks failed: %v", err)
+	}
+
+	if len(chunks) != 2 || chunks[0].NoteID != "tomatoes" || chunks[0].Content != "The garden needs water." {
+		t.Fatalf("expected the garden note first, got %#v", chunks)
+	}
+
+	for _, chunk := range chunks {
+		if chunk.NoteID == "vertical" || strings.HasPrefix(chunk.NoteID, DailyBacklinkPrefix) {
+			t.Fatalf("expected excluded and daily notes to be left out, got %#v", chunks)
+		}
+	}
+
+	// A changed note is embedded again and a removed one is dropped
+	docs[0].Body = "Cutting a dipole antenna for 40m."
+
+	if err := indexZettelkastenEmbeddings(ctx, docs[:2]); err != nil {
+		t.Fatalf("indexZettelkastenEmbeddings failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("expected only the changed note to be embedded, got %d calls", got)
+	}
+
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM zk_note_embeddings WHERE note_id = 'tomatoes'`).Scan(&count); err != nil {
+		t.Fatalf("failed to count embeddings: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected removed note embeddings to be deleted, got %d", count)
+	}
+
+	// Saving a single note only embeds it when its text changed
+	if err := indexZettelkastenEmbedding(ctx, docs[0]); err != nil {
+		t.Fatalf("indexZettelkastenEmbedding failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 4 {
+		t.Fatalf("expected an unchanged note to be skipped, got %d calls", got)
+	}
+
+	docs[1].Body = "A vertical antenna and a garden."
+
+	if err := indexZettelkastenEmbedding(ctx, docs[1]); err != nil {
+		t.Fatalf("indexZettelkastenEmbedding failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 5 {
+		t.Fatalf("expected the changed note to be embedded, got %d calls", got)
+	}
+
+	// Related notes are served from memory once loaded, and kept in step
+	// with what was embedded
+	if _, err := pool.Exec(ctx, `UPDATE zk_note_embeddings SET title = 'Stale title'`); err != nil {
+		t.Fatalf("failed to change stored titles: %v", err)
+	}
+
+	related, err = ListRelatedZKNotes(ctx, "dipole")
+	if err != nil {
+		t.Fatalf("ListRelatedZKNotes failed: %v", err)
+	}
+
+	if len(related) != 1 || related[0].ID != "vertical" || related[0].Title != "Vertical antenna" {
+		t.Fatalf("expected related notes from memory without the removed note, got %#v", related)
+	}
+}
+
+func TestZettelkastenEmbeddingsSkipRejectedNotes(t *testing.T) {
+	resetDatabase(t)
+
+	var calls atomic.Int32
+
+	server := newTestEmbeddingsServer(t, &calls)
+	t.Setenv("OLLAMA_URL", server.URL)
+	t.Setenv("OLLAMA_EMBED_MODEL", "embed-model")
+
+	ctx := testContext()
+	docs := []zkSearchDocument{
+		{NoteID: "huge", Title: "Huge", Body: "A note that is too long to embed."},
+		{NoteID: "dipole", Title: "Dipole antenna", Body: "Cutting a dipole antenna for 20m."},
+		{NoteID: "vertical", Title: "Vertical antenna", Body: "A vertical antenna needs radials."},
+	}
+
+	if err := indexZettelkastenEmbeddings(ctx, docs); err != nil {
+		t.Fatalf("expected a rejected note to be skipped, got %v", err)
+	}
+
+	related, err := ListRelatedZKNotes(ctx, "dipole")
+	if err != nil {
+		t.Fatalf("ListRelatedZKNotes failed: %v", err)
+	}
+
+	if len(related) != 1 || related[0].ID != "vertical" {
+		t.Fatalf("expected the notes after the rejected one to be embedded, got %#v", related)
+	}
+
+	// The rejected note is tried again on the next pass, the others are not
+	calls.Store(0)
+
+	if err := indexZettelkastenEmbeddings(ctx, docs); err != nil {
+		t.Fatalf("indexZettelkastenEmbeddings failed: %v", err)
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected only the rejected note to be retried, got %d calls", got)
+	}
+
+	// An unreachable Ollama stops the pass
+	server.Close()
+
+	docs[1].Body = "Cutting a dipole antenna for 40m."
+
+	if err := indexZettelkastenEmbeddings(ctx, docs); err == nil || zkEmbeddingRejected(err) {
+		t.Fatalf("expected a connection error to stop indexing, got %v", err)
+	}
+}
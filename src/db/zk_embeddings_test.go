@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChunkZKNote(t *testing.T) {
+	t.Parallel()
+
+	content := ":PROPERTIES:\n:ID:       note-1\n:END:\n#+TITLE: Antennas\n#+filetags: :radio:\n\nDipoles are simple.\n\nVerticals need radials.\n"
+
+	chunks := chunkZKNote(content)
+	if len(chunks) != 1 || chunks[0] != "Dipoles are simple.\n\nVerticals need radials." {
+		t.Fatalf("expected drawer and keywords to be dropped, got %q", chunks)
+	}
+
+	if chunks := chunkZKNote(":PROPERTIES:\n:ID: x\n:END:\n#+TITLE: Empty\n"); len(chunks) != 1 || chunks[0] != "" {
+		t.Fatalf("expected a single empty chunk for a note without body, got %q", chunks)
+	}
+
+	paragraph := strings.Repeat("a", zkEmbeddingChunkSize-100)
+	long := strings.Repeat("b", zkEmbeddingChunkSize+10)
+
+	chunks = chunkZKNote(paragraph + "\n\n" + paragraph + "\n\n" + long)
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks, got %d", len(chunks))
+	}
+
+	for i, chunk := range chunks {
+		if n := len([]rune(chunk)); n > zkEmbeddingChunkSize {
+			t.Fatalf("chunk %d has %d runes, over the limit", i, n)
+		}
+	}
+
+	if chunks[3] != strings.Repeat("b", 10) {
+		t.Fatalf("expected the rest of the long paragraph last, got %q", chunks[3])
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	t.Parallel()
+
+	if got := cosineSimilarity([]float32{1, 0}, []float32{2, 0}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected parallel vectors to score 1, got %v", got)
+	}
+
+	if got := cosineSimilarity([]float32{1, 0}, []float32{0, 3}); got != 0 {
+		t.Fatalf("expected orthogonal vectors to score 0, got %v", got)
+	}
+
+	if got := cosineSimilarity([]float32{1, 0}, []float32{1, 0, 0}); got != 0 {
+		t.Fatalf("expected mismatched lengths to score 0, got %v", got)
+	}
+
+	if got := cosineSimilarity([]float32{0, 0}, []float32{1, 0}); got != 0 {
+		t.Fatalf("expected a zero vector to score 0, got %v", got)
+	}
+}
+
+func TestRankZKNoteChunks(t *testing.T) {
+	t.Parallel()
+
+	notes := map[string]zkEmbeddedNote{
+		"garden": newZKEmbeddedNote([]zkEmbeddedChunk{
+			{NoteID: "garden", Title: "Garden", Content: "Tomatoes", Embedding: []float32{0, 1}},
+		}),
+		"antenna": newZKEmbeddedNote([]zkEmbeddedChunk{
+			{NoteID: "antenna", Title: "Antenna", Content: "Dipoles", Embedding: []float32{1, 0.1}},
+			{NoteID: "antenna", Title: "Antenna", Content: "Baluns", Embedding: []float32{1, 0.5}},
+		}),
+		"selected": newZKEmbeddedNote([]zkEmbeddedChunk{
+			{NoteID: "selected", Title: "Selected", Content: "Verticals", Embedding: []float32{1, 0}},
+		}),
+	}
+
+	ranked := rankZKNoteChunks([]float32{1, 0}, notes, map[string]bool{"selected": true}, 2)
+	if len(ranked) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(ranked))
+	}
+
+	if ranked[0].Content != "Dipoles" || ranked[1].Content != "Baluns" {
+		t.Fatalf("unexpected ranking: %#v", ranked)
+	}
+}
+
+func TestRankRelatedZKNotes(t *testing.T) {
+	t.Parallel()
+
+	notes := map[string]zkEmbeddedNote{
+		"antenna": newZKEmbeddedNote([]zkEmbeddedChunk{
+			{NoteID: "antenna", Title: "Antenna", Embedding: []float32{1, 0}},
+			{NoteID: "antenna", Title: "Antenna", Embedding: []float32{1, 0.2}},
+		}),
+		"garden":   newZKEmbeddedNote([]zkEmbeddedChunk{{NoteID: "garden", Title: "Garden", Embedding: []float32{0.1, 1}}}),
+		"opposite": newZKEmbeddedNote([]zkEmbeddedChunk{{NoteID: "opposite", Title: "Opposite", Embedding: []float32{-1, 0}}}),
+		"baluns":   newZKEmbeddedNote([]zkEmbeddedChunk{{NoteID: "baluns", Title: "Baluns", Embedding: []float32{1, 0.1}}}),
+	}
+
+	if mean := notes["antenna"].Mean; len(mean) != 2 || mean[0] != 1 || math.Abs(float64(mean[1])-0.1) > 1e-6 {
+		t.Fatalf("expected the mean of the antenna chunks, got %v", mean)
+	}
+
+	related := rankRelatedZKNotes("antenna", notes, 5)
+	if len(related) != 2 {
+		t.Fatalf("expected 2 related notes, got %#v", related)
+	}
+
+	if related[0].ID != "baluns" || related[0].Title != "Baluns" || related[1].ID != "garden" {
+		t.Fatalf("unexpected related notes: %#v", related)
+	}
+
+	if related := rankRelatedZKNotes("missing", notes, 5); related != nil {
+		t.Fatalf("expected no related notes for a note without embeddings, got %#v", related)
+	}
+}
+
+func TestBuildZKChatPromptWithExcerpts(t *testing.T) {
+	t.Parallel()
+
+	notes := []ZKChatNote{{ID: "note-1", Title: "Selected", Content: "Full text"}}
+	excerpts := []ZKNoteChunk{{NoteID: "note-2", Title: "Retrieved", Content: "Excerpt text"}}
+
+	prompt := buildZKChatPrompt(notes, excerpts, "What do I know?")
+
+	for _, want := range []string{
+		"Note ID: note-1",
+		"Full text",
+		"Excerpts retrieved from other notes",
+		"Note ID: note-2",
+		"Excerpt text",
+		"User question:\nWhat do I know?",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+
+	prompt = buildZKChatPrompt(nil, nil, "Anything?")
+	if !strings.Contains(prompt, "No notes were found") {
+		t.Fatalf("expected empty context to be stated, got:\n%s", prompt)
+	}
+}
+
+func TestCreateEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req embeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "embed-model" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if req.Input[0] == "short" {
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		}
+
+		// Out of order, as the index is what counts
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	config := &OllamaConfig{URL: server.URL + "/", Model: "embed-model"}
+
+	embeddings, err := createEmbeddings(context.Background(), config, []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("createEmbeddings failed: %v", err)
+	}
+
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Fatalf("unexpected embeddings: %v", embeddings)
+	}
+
+	if _, err := createEmbeddings(context.Background(), config, []string{"short"}); !errors.Is(err, ErrOllamaEmbeddingCount) {
+		t.Fatalf("expected ErrOllamaEmbeddingCount, got %v", err)
+	}
+
+	config.Model = "other-model"
+	if _, err := createEmbeddings(context.Background(), config, []string{"first", "second"}); !errors.Is(err, ErrOllamaReturnedStatus) {
+		t.Fatalf("expected ErrOllamaReturnedStatus, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var updateTierCadenceDBFn = db.UpdateTierCadence
+
+// parseCadenceDays parses an optional follow-up interval in days.
+// An empty value clears the override.
+func parseCadenceDays(value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil //nolint:nilnil // Empty input clears the override.
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return nil, db.ErrCadenceDaysInvalid
+	}
+
+	return &days, nil
+}
+
+// parseSnoozeDate parses an optional YYYY-MM-DD snooze-until date.
+func parseSnoozeDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil //nolint:nilnil // Empty input clears the snooze.
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errInvalidDate
+	}
+
+	return &parsed, nil
+}
+
+// loadTierCadenceDays returns the default interval per tier, keyed by tier letter.
+// Tiers without a regular cadence are omitted.
+func loadTierCadenceDays(ctx context.Context) map[string]int {
+	cadences, err := db.ListTierCadences(ctx)
+	if err != nil {
+		logger.Error("Error fetching tier cadences", "error", err)
+		return nil
+	}
+
+	days := make(map[string]int, len(cadences))
+
+	for _, cadence := range cadences {
+		if cadence.IntervalDays != nil {
+			days[string(cadence.Tier)] = *cadence.IntervalDays
+		}
+	}
+
+	return days
+}
+
+// UpdateTierCadences handles saving the default follow-up interval for each tier
+func UpdateTierCadences(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/overdue", http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+	tiers := []db.Tier{db.TierA, db.TierB, db.TierC, db.TierD, db.TierE, db.TierF}
+	updates := make(map[db.Tier]*int, len(tiers))
+
+	for _, tier := range tiers {
+		days, err := parseCadenceDays(form.Get("tier_" + string(tier)))
+		if err != nil {
+			SetErrorFlash(s, "Tier "+string(tier)+" interval must be a positive number of days")
+			c.Redirect("/overdue", http.StatusSeeOther)
+
+			return
+		}
+
+		updates[tier] = days
+	}
+
+	for _, tier := range tiers {
+		if err := updateTierCadenceDBFn(c.Request().Context(), tier, updates[tier]); err != nil {
+			logger.Error("Error updating tier cadence", "tier", tier, "error", err)
+			SetErrorFlash(s, "Failed to update tier cadences")
+			c.Redirect("/overdue", http.StatusSeeOther)
+
+			return
+		}
+	}
+
+	SetSuccessFlash(s, "Tier cadences updated")
+	c.Redirect("/overdue", http.StatusSeeOther)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+const calendarTokenEnvVar = "CALENDAR_TOKEN" //nolint:gosec // Environment variable name, not a credential.
+
+var listContactDatesDBFn = db.ListContactDates
+
+// calendarToken returns the configured feed token, or an empty string when
+// the calendar feed is disabled.
+func calendarToken() string {
+	return strings.TrimSpace(os.Getenv(calendarTokenEnvVar))
+}
+
+// calendarFeedPath returns the subscription path for the contact dates feed,
+// or an empty string when the feed is disabled.
+func calendarFeedPath() string {
+	token := calendarToken()
+	if token == "" {
+		return ""
+	}
+
+	return "/calendar/dates.ics?token=" + token
+}
+
+// ContactDatesCalendar serves birthdays and anniversaries as an iCalendar feed.
+// Access requires the CALENDAR_TOKEN query parameter so calendar apps can subscribe.
+func ContactDatesCalendar(c flamego.Context) {
+	expected := calendarToken()
+	provided := strings.TrimSpace(c.Query("token"))
+
+	if expected == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+		c.ResponseWriter().WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	dates, err := listContactDatesDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error fetching contact dates", "error", err)
+		c.ResponseWriter().WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	now := time.Now()
+	feed := utils.BuildICalendar("Groundwave Dates", buildContactDateEvents(dates, now), now)
+
+	c.ResponseWriter().Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	c.ResponseWriter().Header().Set("Content-Disposition", "inline; filename=\"dates.ics\"")
+	c.ResponseWriter().Header().Set("Content-Length", strconv.Itoa(len(feed)))
+	c.ResponseWriter().WriteHeader(http.StatusOK)
+
+	if _, err := c.ResponseWriter().Write([]byte(feed)); err != nil {
+		logger.Error("Error writing calendar feed response", "error", err)
+	}
+}
+
+// buildContactDateEvents expands each date into individual events for the
+// previous, current and next year so every occurrence carries its own age.
+func buildContactDateEvents(dates []db.ContactDate, now time.Time) []utils.ICalEvent {
+	events := make([]utils.ICalEvent, 0, len(dates)*3)
+
+	for _, date := range dates {
+		for year := now.Year() - 1; year <= now.Year()+1; year++ {
+			occurrence := date.OccurrenceIn(year)
+			if date.YearKnown() && occurrence.Year() <= date.Date.Year() {
+				continue
+			}
+
+			events = append(events, utils.ICalEvent{
+				UID:     fmt.Sprintf("%s-%s-%d@groundwave", date.ContactID, date.Kind, year),
+				Date:    occurrence,
+				Summary: contactDateSummary(date, date.YearsAt(occurrence)),
+			})
+		}
+	}
+
+	return events
+}
+
+// contactDateSummary describes a contact date occurrence, e.g. "Ada turns 30".
+func contactDateSummary(date db.ContactDate, years *int) string {
+	switch date.Kind {
+	case db.ContactDateAnniversary:
+		if years != nil {
+			return fmt.Sprintf("%s's %s anniversary", date.NameDisplay, ordinal(*years))
+		}
+
+		return date.NameDisplay + "'s anniversary"
+	case db.ContactDateBirthday:
+		if years != nil {
+			return fmt.Sprintf("%s turns %d", date.NameDisplay, *years)
+		}
+	}
+
+	return date.NameDisplay + "'s birthday"
+}
+
+// ordinal formats a positive number with its English ordinal suffix.
+func ordinal(n int) string {
+	suffix := "th"
+
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newCalendarTestApp() *flamego.Flame {
+	f := flamego.New()
+	f.Get("/calendar/dates.ics", ContactDatesCalendar)
+
+	return f
+}
+
+func TestContactDatesCalendarRequiresToken(t *testing.T) {
+	cases := []struct {
+		name  string
+		env   string
+		query string
+	}{
+		{name: "feed disabled", env: "", query: "?token=anything"},
+		{name: "missing token", env: "secret", query: ""},
+		{name: "wrong token", env: "secret", query: "?token=guess"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(calendarTokenEnvVar, tc.env)
+
+			rec := httptest.NewRecorder()
+			newCalendarTestApp().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/dates.ics"+tc.query, nil))
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+			}
+		})
+	}
+}
+
+func TestContactDatesCalendarServesFeed(t *testing.T) {
+	t.Setenv(calendarTokenEnvVar, "secret")
+
+	originalListContactDatesDBFn := listContactDatesDBFn
+	listContactDatesDBFn = func(context.Context) ([]db.ContactDate, error) {
+		return []db.ContactDate{
+			{ContactID: "c1", NameDisplay: "Ada", Kind: db.ContactDateBirthday, Date: time.Date(1990, time.May, 4, 0, 0, 0, 0, time.UTC)},
+		}, nil
+	}
+
+	t.Cleanup(func() {
+		listContactDatesDBFn = originalListContactDatesDBFn
+	})
+
+	rec := httptest.NewRecorder()
+	newCalendarTestApp().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/dates.ics?token=secret", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	body := rec.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 3 {
+		t.Fatalf("expected three yearly events, got:\n%s", body)
+	}
+
+	year := time.Now().Year()
+	if !strings.Contains(body, "c1-birthday-"+strconv.Itoa(year)+"@groundwave") {
+		t.Fatalf("expected event UID for current year, got:\n%s", body)
+	}
+
+	if !strings.Contains(body, "SUMMARY:Ada turns "+strconv.Itoa(year-1990)) {
+		t.Fatalf("expected computed age in summary, got:\n%s", body)
+	}
+}
+
+func TestContactDateSummary(t *testing.T) {
+	t.Parallel()
+
+	years := 3
+	age := 41
+	unknownYear := db.ContactDate{NameDisplay: "Bo", Kind: db.ContactDateBirthday}
+
+	cases := []struct {
+		date  db.ContactDate
+		years *int
+		want  string
+	}{
+		{date: db.ContactDate{NameDisplay: "Ada", Kind: db.ContactDateBirthday}, years: &age, want: "Ada turns 41"},
+		{date: unknownYear, years: nil, want: "Bo's birthday"},
+		{date: db.ContactDate{NameDisplay: "Cy", Kind: db.ContactDateAnniversary}, years: &years, want: "Cy's 3rd anniversary"},
+		{date: db.ContactDate{NameDisplay: "Di", Kind: db.ContactDateAnniversary}, years: nil, want: "Di's anniversary"},
+	}
+
+	for _, tc := range cases {
+		if got := contactDateSummary(tc.date, tc.years); got != tc.want {
+			t.Fatalf("contactDateSummary() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	t.Parallel()
+
+	cases := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 102: "102nd", 111: "111th"}
+
+	for input, want := range cases {
+		if got := ordinal(input); got != want {
+			t.Fatalf("ordinal(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
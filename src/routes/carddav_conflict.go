@@ -0,0 +1,124 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	hasCardDAVConflictDBFn     = db.HasOpenCardDAVConflict
+	getCardDAVConflictDBFn     = db.GetOpenCardDAVConflict
+	resolveCardDAVConflictDBFn = db.ResolveCardDAVConflict
+)
+
+// hasCardDAVConflict reports whether a linked contact is waiting on a
+// conflict resolution. Lookup errors are logged and treated as no conflict.
+func hasCardDAVConflict(ctx context.Context, contact *db.ContactDetail) bool {
+	if contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" {
+		return false
+	}
+
+	open, err := hasCardDAVConflictDBFn(ctx, contact.ID.String())
+	if err != nil {
+		logger.Error("Error checking CardDAV conflict", "contact_id", contact.ID, "error", err)
+		return false
+	}
+
+	return open
+}
+
+// ViewCardDAVConflict shows the fields where a contact and its CardDAV card
+// diverged
+func ViewCardDAVConflict(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	contactID := c.Param("id")
+	if contactID == "" {
+		SetErrorFlash(s, "Contact ID is required")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	conflict, err := getCardDAVConflictDBFn(c.Request().Context(), contactID)
+	if errors.Is(err, db.ErrCardDAVConflictNotFound) {
+		SetInfoFlash(s, "No CardDAV conflict to resolve")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	if err != nil {
+		logger.Error("Error fetching CardDAV conflict", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Failed to load CardDAV conflict")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	data["Conflict"] = conflict
+	data["ContactID"] = contactID
+	data["IsContacts"] = true
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: conflict.ContactName, URL: "/contact/" + contactID, IsCurrent: false},
+		{Name: "CardDAV Conflict", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "contact_carddav_conflict")
+}
+
+// ResolveCardDAVConflict settles a conflict, keeping the server value for
+// every field submitted as keep_<field>=server and the local value otherwise
+func ResolveCardDAVConflict(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	if contactID == "" {
+		c.Redirect("/contacts", http.StatusSeeOther)
+		return
+	}
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contact/"+contactID+"/carddav/conflict", http.StatusSeeOther)
+
+		return
+	}
+
+	var keepServer []string
+
+	for _, field := range db.CardDAVConflictFields {
+		if c.Request().Form.Get("keep_"+field) == "server" {
+			keepServer = append(keepServer, field)
+		}
+	}
+
+	err := resolveCardDAVConflictDBFn(c.Request().Context(), contactID, keepServer)
+
+	switch {
+	case err == nil:
+		logger.Info("Resolved CardDAV conflict", "contact_id", contactID, "server_fields", len(keepServer))
+		SetSuccessFlash(s, "Conflict resolved")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+	case errors.Is(err, db.ErrCardDAVConflictChanged):
+		SetWarningFlash(s, "The CardDAV card changed again, review the updated differences")
+		c.Redirect("/contact/"+contactID+"/carddav/conflict", http.StatusSeeOther)
+	case errors.Is(err, db.ErrCardDAVConflictNotFound):
+		SetInfoFlash(s, "No CardDAV conflict to resolve")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+	default:
+		logger.Error("Error resolving CardDAV conflict", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Failed to resolve conflict")
+		c.Redirect("/contact/"+contactID+"/carddav/conflict", http.StatusSeeOther)
+	}
+}
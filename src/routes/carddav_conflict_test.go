@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newCardDAVConflictTestApp(s session.Session, t template.Template, data template.Data) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.MapTo(t, (*template.Template)(nil))
+		c.Map(data)
+		c.Next()
+	})
+
+	f.Get("/contact/{id}/carddav/conflict", ViewCardDAVConflict)
+	f.Post("/contact/{id}/carddav/conflict", ResolveCardDAVConflict)
+
+	return f
+}
+
+// stubResolveCardDAVConflict replaces conflict resolution, returning the
+// fields that were kept from the server
+func stubResolveCardDAVConflict(t *testing.T, err error) *[]string {
+	t.Helper()
+
+	var keptServer []string
+
+	originalResolveCardDAVConflictDBFn := resolveCardDAVConflictDBFn
+	resolveCardDAVConflictDBFn = func(_ context.Context, _ string, keepServer []string) error {
+		keptServer = keepServer
+		return err
+	}
+
+	t.Cleanup(func() {
+		resolveCardDAVConflictDBFn = originalResolveCardDAVConflictDBFn
+	})
+
+	return &keptServer
+}
+
+func TestViewCardDAVConflict(t *testing.T) {
+	originalGetCardDAVConflictDBFn := getCardDAVConflictDBFn
+	getCardDAVConflictDBFn = func(_ context.Context, contactID string) (*db.CardDAVConflict, error) {
+		if contactID == "c2" {
+			return nil, db.ErrCardDAVConflictNotFound
+		}
+
+		return &db.CardDAVConflict{ContactName: "Sara", Fields: []db.CardDAVConflictField{{Field: db.CardDAVFieldTitle}}}, nil
+	}
+
+	t.Cleanup(func() {
+		getCardDAVConflictDBFn = originalGetCardDAVConflictDBFn
+	})
+
+	s := newTestSession()
+	tpl := &filesTemplateStub{}
+	data := template.Data{}
+	f := newCardDAVConflictTestApp(s, tpl, data)
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact/c1/carddav/conflict", nil))
+
+	if !tpl.called || tpl.status != http.StatusOK || tpl.name != "contact_carddav_conflict" {
+		t.Fatalf("unexpected render %+v", tpl)
+	}
+
+	if conflict, ok := data["Conflict"].(*db.CardDAVConflict); !ok || conflict.ContactName != "Sara" {
+		t.Fatalf("expected conflict in template data, got %v", data["Conflict"])
+	}
+
+	rec = httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact/c2/carddav/conflict", nil))
+
+	assertRedirect(t, rec, "/contact/c2/edit")
+	assertFlash(t, s, FlashInfo, "No CardDAV conflict to resolve")
+}
+
+func TestResolveCardDAVConflictKeepsSelectedServerFields(t *testing.T) {
+	keptServer := stubResolveCardDAVConflict(t, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVConflictTestApp(s, &filesTemplateStub{}, template.Data{}), "/contact/c1/carddav/conflict", url.Values{
+		"keep_name":   {"server"},
+		"keep_title":  {"local"},
+		"keep_phones": {"server"},
+		"keep_bogus":  {"server"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashSuccess, "Conflict resolved")
+
+	if !slices.Equal(*keptServer, []string{db.CardDAVFieldName, db.CardDAVFieldPhones}) {
+		t.Fatalf("unexpected server fields %v", *keptServer)
+	}
+}
+
+func TestResolveCardDAVConflictWhenCardChangedAgain(t *testing.T) {
+	stubResolveCardDAVConflict(t, db.ErrCardDAVConflictChanged)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVConflictTestApp(s, &filesTemplateStub{}, template.Data{}), "/contact/c1/carddav/conflict", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contact/c1/carddav/conflict")
+	assertFlash(t, s, FlashWarning, "The CardDAV card changed again, review the updated differences")
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	listCardDAVSourcesDBFn  = db.ListCardDAVSources
+	createCardDAVSourceDBFn = db.CreateCardDAVSource
+	updateCardDAVSourceDBFn = db.UpdateCardDAVSource
+	deleteCardDAVSourceDBFn = db.DeleteCardDAVSource
+)
+
+// selectCardDAVSource returns the ID of the requested source, or of the
+// first source when none was requested
+func selectCardDAVSource(ctx context.Context, requested string) (string, error) {
+	sources, err := listCardDAVSourcesDBFn(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sources) == 0 {
+		return "", db.ErrCardDAVNoSources
+	}
+
+	if requested == "" {
+		return sources[0].ID.String(), nil
+	}
+
+	for _, source := range sources {
+		if source.ID.String() == requested {
+			return requested, nil
+		}
+	}
+
+	return "", db.ErrCardDAVSourceNotFound
+}
+
+// cardDAVSourceInputFromForm reads the source fields of a submitted form
+func cardDAVSourceInputFromForm(c flamego.Context) (db.CardDAVSourceInput, error) {
+	if err := c.Request().ParseForm(); err != nil {
+		return db.CardDAVSourceInput{}, err
+	}
+
+	form := c.Request().Form
+
+	return db.CardDAVSourceInput{
+		Name:     form.Get("name"),
+		URL:      form.Get("url"),
+		Username: form.Get("username"),
+		Password: form.Get("password"),
+	}, nil
+}
+
+// cardDAVSourceErrorMessage maps a source validation error to a flash message
+func cardDAVSourceErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, db.ErrCardDAVSourceNameRequired):
+		return "Source name is required"
+	case errors.Is(err, db.ErrCardDAVSourceURLRequired):
+		return "Address book URL is required"
+	case errors.Is(err, db.ErrCardDAVSourceNameTaken):
+		return "A source with that name already exists"
+	case errors.Is(err, db.ErrCardDAVSourceNotFound):
+		return "CardDAV source not found"
+	default:
+		return fallback
+	}
+}
+
+// CardDAVSources lists the configured CardDAV sources
+func CardDAVSources(c flamego.Context, t template.Template, data template.Data) {
+	sources, err := listCardDAVSourcesDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error listing CardDAV sources", "error", err)
+
+		data["Error"] = "Failed to load CardDAV sources"
+	}
+
+	data["Sources"] = sources
+	data["IsContacts"] = true
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "CardDAV Sources", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "carddav_sources")
+}
+
+// CreateCardDAVSource adds a CardDAV source
+func CreateCardDAVSource(c flamego.Context, s session.Session) {
+	input, err := cardDAVSourceInputFromForm(c)
+	if err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/carddav/sources", http.StatusSeeOther)
+
+		return
+	}
+
+	if _, err := createCardDAVSourceDBFn(c.Request().Context(), input); err != nil {
+		logger.Error("Error creating CardDAV source", "error", err)
+		SetErrorFlash(s, cardDAVSourceErrorMessage(err, "Failed to add CardDAV source"))
+	} else {
+		SetSuccessFlash(s, "CardDAV source added")
+	}
+
+	c.Redirect("/carddav/sources", http.StatusSeeOther)
+}
+
+// UpdateCardDAVSource saves changes to a CardDAV source
+func UpdateCardDAVSource(c flamego.Context, s session.Session) {
+	input, err := cardDAVSourceInputFromForm(c)
+	if err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/carddav/sources", http.StatusSeeOther)
+
+		return
+	}
+
+	if err := updateCardDAVSourceDBFn(c.Request().Context(), c.Param("id"), input); err != nil {
+		logger.Error("Error updating CardDAV source", "source_id", c.Param("id"), "error", err)
+		SetErrorFlash(s, cardDAVSourceErrorMessage(err, "Failed to update CardDAV source"))
+	} else {
+		SetSuccessFlash(s, "CardDAV source updated")
+	}
+
+	c.Redirect("/carddav/sources", http.StatusSeeOther)
+}
+
+// DeleteCardDAVSource removes a CardDAV source, unlinking its contacts
+func DeleteCardDAVSource(c flamego.Context, s session.Session) {
+	if err := deleteCardDAVSourceDBFn(c.Request().Context(), c.Param("id")); err != nil {
+		logger.Error("Error deleting CardDAV source", "source_id", c.Param("id"), "error", err)
+		SetErrorFlash(s, cardDAVSourceErrorMessage(err, "Failed to remove CardDAV source"))
+	} else {
+		SetSuccessFlash(s, "CardDAV source removed")
+	}
+
+	c.Redirect("/carddav/sources", http.StatusSeeOther)
+}
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/google/uuid"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newCardDAVSourcesTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/carddav/sources", CreateCardDAVSource)
+	f.Post("/carddav/sources/{id}/edit", UpdateCardDAVSource)
+	f.Post("/carddav/sources/{id}/delete", DeleteCardDAVSource)
+
+	return f
+}
+
+func TestCreateCardDAVSource(t *testing.T) {
+	var created db.CardDAVSourceInput
+
+	originalCreateCardDAVSourceDBFn := createCardDAVSourceDBFn
+	createCardDAVSourceDBFn = func(_ context.Context, input db.CardDAVSourceInput) (string, error) {
+		if input.Name == "Taken" {
+			return "", db.ErrCardDAVSourceNameTaken
+		}
+
+		created = input
+
+		return "s1", nil
+	}
+
+	t.Cleanup(func() {
+		createCardDAVSourceDBFn = originalCreateCardDAVSourceDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVSourcesTestApp(s), "/carddav/sources", url.Values{
+		"name":     {"Work"},
+		"url":      {"https://dav.example.com/work/"},
+		"username": {"me"},
+		"password": {"secret"},
+	}, nil)
+
+	assertRedirect(t, rec, "/carddav/sources")
+	assertFlash(t, s, FlashSuccess, "CardDAV source added")
+
+	if created != (db.CardDAVSourceInput{Name: "Work", URL: "https://dav.example.com/work/", Username: "me", Password: "secret"}) {
+		t.Fatalf("unexpected source input %+v", created)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newCardDAVSourcesTestApp(s), "/carddav/sources", url.Values{"name": {"Taken"}}, nil)
+
+	assertRedirect(t, rec, "/carddav/sources")
+	assertFlash(t, s, FlashError, "A source with that name already exists")
+}
+
+func TestUpdateAndDeleteCardDAVSource(t *testing.T) {
+	var updatedID, deletedID string
+
+	originalUpdateCardDAVSourceDBFn := updateCardDAVSourceDBFn
+	originalDeleteCardDAVSourceDBFn := deleteCardDAVSourceDBFn
+	updateCardDAVSourceDBFn = func(_ context.Context, id string, _ db.CardDAVSourceInput) error {
+		updatedID = id
+		return nil
+	}
+	deleteCardDAVSourceDBFn = func(_ context.Context, id string) error {
+		if id == "missing" {
+			return db.ErrCardDAVSourceNotFound
+		}
+
+		deletedID = id
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		updateCardDAVSourceDBFn = originalUpdateCardDAVSourceDBFn
+		deleteCardDAVSourceDBFn = originalDeleteCardDAVSourceDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVSourcesTestApp(s), "/carddav/sources/s1/edit", url.Values{"name": {"Office"}}, nil)
+
+	assertRedirect(t, rec, "/carddav/sources")
+	assertFlash(t, s, FlashSuccess, "CardDAV source updated")
+
+	if updatedID != "s1" {
+		t.Fatalf("expected source s1 to be updated, got %q", updatedID)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newCardDAVSourcesTestApp(s), "/carddav/sources/s1/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/carddav/sources")
+	assertFlash(t, s, FlashSuccess, "CardDAV source removed")
+
+	if deletedID != "s1" {
+		t.Fatalf("expected source s1 to be deleted, got %q", deletedID)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newCardDAVSourcesTestApp(s), "/carddav/sources/missing/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/carddav/sources")
+	assertFlash(t, s, FlashError, "CardDAV source not found")
+}
+
+func TestSelectCardDAVSource(t *testing.T) {
+	personal := db.CardDAVSource{ID: uuid.New(), Name: "Personal"}
+	work := db.CardDAVSource{ID: uuid.New(), Name: "Work"}
+	sources := []db.CardDAVSource{}
+
+	originalListCardDAVSourcesDBFn := listCardDAVSourcesDBFn
+	listCardDAVSourcesDBFn = func(context.Context) ([]db.CardDAVSource, error) {
+		return sources, nil
+	}
+
+	t.Cleanup(func() {
+		listCardDAVSourcesDBFn = originalListCardDAVSourcesDBFn
+	})
+
+	ctx := context.Background()
+
+	if _, err := selectCardDAVSource(ctx, ""); !errors.Is(err, db.ErrCardDAVNoSources) {
+		t.Fatalf("expected ErrCardDAVNoSources, got %v", err)
+	}
+
+	sources = []db.CardDAVSource{personal, work}
+
+	if id, err := selectCardDAVSource(ctx, ""); err != nil || id != personal.ID.String() {
+		t.Fatalf("expected the first source by default, got %q %v", id, err)
+	}
+
+	if id, err := selectCardDAVSource(ctx, work.ID.String()); err != nil || id != work.ID.String() {
+		t.Fatalf("expected the requested source, got %q %v", id, err)
+	}
+
+	if _, err := selectCardDAVSource(ctx, uuid.NewString()); !errors.Is(err, db.ErrCardDAVSourceNotFound) {
+		t.Fatalf("expected ErrCardDAVSourceNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	hasCardDAVSourcesFn = db.HasCardDAVSources
+	syncCardDAVFn       = db.SyncCardDAV
+)
+
+// cardDAVSyncHistorySize is the number of runs shown on the contacts page
+const cardDAVSyncHistorySize = 5
+
+// SyncCardDAVNow starts an incremental CardDAV sync outside the schedule
+func SyncCardDAVNow(c flamego.Context, s session.Session) {
+	configured, err := hasCardDAVSourcesFn(c.Request().Context())
+	if err != nil || !configured {
+		SetErrorFlash(s, "CardDAV is not configured")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	// Sync asynchronously; the run shows up in the history when done
+	go func() {
+		runs, err := syncCardDAVFn(ctx, db.CardDAVSyncManual, false)
+
+		switch {
+		case errors.Is(err, db.ErrCardDAVSyncInProgress):
+			logger.Info("Manual CardDAV sync skipped, a sync is already running")
+
+			return
+		case err != nil:
+			logger.Error("Manual CardDAV sync failed", "error", err)
+		}
+
+		for _, run := range runs {
+			logger.Info("Manual CardDAV sync completed", "source", run.SourceName, "status", run.Status, "summary", run.Summary())
+		}
+	}()
+
+	SetInfoFlash(s, "CardDAV sync started in background")
+	c.Redirect("/contacts", http.StatusSeeOther)
+}
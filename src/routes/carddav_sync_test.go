@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newCardDAVSyncTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/contacts/carddav/sync", SyncCardDAVNow)
+
+	return f
+}
+
+// stubCardDAVSync replaces the CardDAV source check and sync, returning a
+// channel receiving the trigger of every sync started
+func stubCardDAVSync(t *testing.T, configured bool) chan db.CardDAVSyncTrigger {
+	t.Helper()
+
+	started := make(chan db.CardDAVSyncTrigger, 1)
+
+	originalHasCardDAVSourcesFn := hasCardDAVSourcesFn
+	originalSyncCardDAVFn := syncCardDAVFn
+
+	hasCardDAVSourcesFn = func(context.Context) (bool, error) {
+		return configured, nil
+	}
+	syncCardDAVFn = func(_ context.Context, trigger db.CardDAVSyncTrigger, full bool) ([]db.CardDAVSyncRun, error) {
+		if full {
+			t.Error("expected an incremental sync")
+		}
+
+		started <- trigger
+
+		return []db.CardDAVSyncRun{{SourceName: "Personal", Trigger: trigger, Mode: db.CardDAVSyncIncremental}}, nil
+	}
+
+	t.Cleanup(func() {
+		hasCardDAVSourcesFn = originalHasCardDAVSourcesFn
+		syncCardDAVFn = originalSyncCardDAVFn
+	})
+
+	return started
+}
+
+func TestSyncCardDAVNowRequiresConfiguration(t *testing.T) {
+	started := stubCardDAVSync(t, false)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVSyncTestApp(s), "/contacts/carddav/sync", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contacts")
+	assertFlash(t, s, FlashError, "CardDAV is not configured")
+
+	if len(started) != 0 {
+		t.Fatal("expected no sync without configuration")
+	}
+}
+
+func TestSyncCardDAVNowStartsManualSync(t *testing.T) {
+	started := stubCardDAVSync(t, true)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newCardDAVSyncTestApp(s), "/contacts/carddav/sync", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contacts")
+	assertFlash(t, s, FlashInfo, "CardDAV sync started in background")
+
+	select {
+	case trigger := <-started:
+		if trigger != db.CardDAVSyncManual {
+			t.Fatalf("expected a manual sync, got %q", trigger)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a sync to start")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+const chatImportURL = "/chats/import"
+
+// chatArchiveUploadMemory is how much of an uploaded archive is held in
+// memory; larger exports are spooled to disk while parsing
+const chatArchiveUploadMemory = 32 << 20
+
+// maxUnmatchedTitlesShown limits how many unmatched conversations are named
+// in the import summary
+const maxUnmatchedTitlesShown = 5
+
+var (
+	parseChatArchiveFn             = db.ParseChatArchive
+	importChatArchiveDBFn          = db.ImportChatArchive
+	listChatImportContactNamesDBFn = db.ListContactNames
+)
+
+// ChatImport shows the form for importing exported chat archives
+func ChatImport(c flamego.Context, t template.Template, data template.Data) {
+	contactNames, err := listChatImportContactNamesDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error fetching contact names", "error", err)
+	}
+
+	data["ContactNames"] = contactNames
+	data["IsContacts"] = true
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "Import Chats", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "chat_import")
+}
+
+// ImportChatArchive adds the conversations of an uploaded Signal, Telegram
+// or Matrix export to the chat history of their contacts
+func ImportChatArchive(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseMultipartForm(chatArchiveUploadMemory); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse upload form")
+		c.Redirect(chatImportURL, http.StatusSeeOther)
+
+		return
+	}
+
+	platform := parseChatPlatform(c.Request().Form.Get("platform"))
+	contactID := strings.TrimSpace(c.Request().Form.Get("contact_id"))
+
+	file, header, err := c.Request().FormFile("archive_file")
+	if err != nil {
+		logger.Error("Error getting file", "error", err)
+		SetErrorFlash(s, "No file uploaded or invalid file")
+		c.Redirect(chatImportURL, http.StatusSeeOther)
+
+		return
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Error("Error closing chat archive upload file", "error", err)
+		}
+	}()
+
+	logger.Info("Importing chat archive", "platform", platform, "filename", header.Filename, "bytes", header.Size)
+
+	conversations, err := parseChatArchiveFn(platform, file)
+	if err != nil {
+		logger.Error("Error parsing chat archive", "error", err)
+		SetErrorFlash(s, "Failed to read chat archive: "+err.Error())
+		c.Redirect(chatImportURL, http.StatusSeeOther)
+
+		return
+	}
+
+	result, err := importChatArchiveDBFn(c.Request().Context(), platform, conversations, contactID)
+	if err != nil {
+		logger.Error("Error importing chat archive", "error", err)
+		SetErrorFlash(s, "Failed to import chats: "+err.Error())
+		c.Redirect(chatImportURL, http.StatusSeeOther)
+
+		return
+	}
+
+	logger.Info("Imported chat archive", "platform", platform, "conversations", result.Conversations,
+		"imported", result.Imported, "duplicates", result.Duplicates, "unmatched", len(result.Unmatched))
+
+	SetSuccessFlash(s, chatArchiveImportMessage(result))
+
+	if contactID != "" {
+		c.Redirect("/contact/"+contactID+"/chats", http.StatusSeeOther)
+		return
+	}
+
+	c.Redirect(chatImportURL, http.StatusSeeOther)
+}
+
+// chatArchiveImportMessage summarises an import, naming a few of the
+// conversations that matched no contact
+func chatArchiveImportMessage(result db.ChatArchiveImport) string {
+	conversations := "conversations"
+	if result.Conversations == 1 {
+		conversations = "conversation"
+	}
+
+	message := fmt.Sprintf("Imported %d messages into %d %s", result.Imported, result.Conversations, conversations)
+	if result.Duplicates > 0 {
+		message += fmt.Sprintf(" (%d already present)", result.Duplicates)
+	}
+
+	if len(result.Unmatched) == 0 {
+		return message
+	}
+
+	titles := result.Unmatched
+	if len(titles) > maxUnmatchedTitlesShown {
+		titles = titles[:maxUnmatchedTitlesShown]
+	}
+
+	message += fmt.Sprintf("; %d matched no contact: %s", len(result.Unmatched), strings.Join(titles, ", "))
+	if len(result.Unmatched) > len(titles) {
+		message += ", ..."
+	}
+
+	return message
+}
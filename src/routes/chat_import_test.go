@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newChatImportTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/chats/import", ImportChatArchive)
+
+	return f
+}
+
+func performChatArchiveUpload(t *testing.T, f *flamego.Flame, fields map[string]string, content string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatalf("failed to write form field: %v", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile("archive_file", "result.json")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+
+	if _, err := io.WriteString(part, content); err != nil {
+		t.Fatalf("failed to write form file: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/chats/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestImportChatArchiveReportsUnmatched(t *testing.T) {
+	var (
+		gotPlatform  db.ChatPlatform
+		gotContent   string
+		gotContactID string
+	)
+
+	originalParseChatArchiveFn := parseChatArchiveFn
+	parseChatArchiveFn = func(platform db.ChatPlatform, r io.Reader) ([]db.ChatArchiveConversation, error) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		gotPlatform = platform
+		gotContent = string(content)
+
+		return []db.ChatArchiveConversation{{Title: "Lena"}}, nil
+	}
+
+	originalImportChatArchiveDBFn := importChatArchiveDBFn
+	importChatArchiveDBFn = func(_ context.Context, _ db.ChatPlatform, conversations []db.ChatArchiveConversation, contactID string) (db.ChatArchiveImport, error) {
+		gotContactID = contactID
+
+		if len(conversations) != 1 {
+			return db.ChatArchiveImport{}, errTestShouldNotBeCalled
+		}
+
+		return db.ChatArchiveImport{
+			Conversations: 2,
+			Imported:      40,
+			Duplicates:    3,
+			Unmatched:     []string{"A", "B", "C", "D", "E", "F"},
+		}, nil
+	}
+
+	t.Cleanup(func() {
+		parseChatArchiveFn = originalParseChatArchiveFn
+		importChatArchiveDBFn = originalImportChatArchiveDBFn
+	})
+
+	s := newTestSession()
+	rec := performChatArchiveUpload(t, newChatImportTestApp(s), map[string]string{"platform": "matrix"}, `{"messages":[]}`)
+
+	assertRedirect(t, rec, "/chats/import")
+	assertFlash(t, s, FlashSuccess, "Imported 40 messages into 2 conversations (3 already present); 6 matched no contact: A, B, C, D, E, ...")
+
+	if gotPlatform != db.ChatPlatformMatrix || gotContent != `{"messages":[]}` || gotContactID != "" {
+		t.Fatalf("unexpected import call %q %q %q", gotPlatform, gotContent, gotContactID)
+	}
+}
+
+func TestImportChatArchiveIntoContact(t *testing.T) {
+	originalParseChatArchiveFn := parseChatArchiveFn
+	parseChatArchiveFn = func(db.ChatPlatform, io.Reader) ([]db.ChatArchiveConversation, error) {
+		return []db.ChatArchiveConversation{{Title: "Omar"}}, nil
+	}
+
+	originalImportChatArchiveDBFn := importChatArchiveDBFn
+	importChatArchiveDBFn = func(_ context.Context, platform db.ChatPlatform, _ []db.ChatArchiveConversation, contactID string) (db.ChatArchiveImport, error) {
+		if platform != db.ChatPlatformTelegram || contactID != "c1" {
+			return db.ChatArchiveImport{}, errTestShouldNotBeCalled
+		}
+
+		return db.ChatArchiveImport{Conversations: 1, Imported: 5}, nil
+	}
+
+	t.Cleanup(func() {
+		parseChatArchiveFn = originalParseChatArchiveFn
+		importChatArchiveDBFn = originalImportChatArchiveDBFn
+	})
+
+	s := newTestSession()
+	rec := performChatArchiveUpload(t, newChatImportTestApp(s), map[string]string{"platform": "telegram", "contact_id": "c1"}, "{}")
+
+	assertRedirect(t, rec, "/contact/c1/chats")
+	assertFlash(t, s, FlashSuccess, "Imported 5 messages into 1 conversation")
+}
+
+func TestImportChatArchiveParseError(t *testing.T) {
+	originalParseChatArchiveFn := parseChatArchiveFn
+	parseChatArchiveFn = func(db.ChatPlatform, io.Reader) ([]db.ChatArchiveConversation, error) {
+		return nil, db.ErrChatArchivePlatformUnsupported
+	}
+
+	originalImportChatArchiveDBFn := importChatArchiveDBFn
+	importChatArchiveDBFn = func(context.Context, db.ChatPlatform, []db.ChatArchiveConversation, string) (db.ChatArchiveImport, error) {
+		return db.ChatArchiveImport{}, errTestShouldNotBeCalled
+	}
+
+	t.Cleanup(func() {
+		parseChatArchiveFn = originalParseChatArchiveFn
+		importChatArchiveDBFn = originalImportChatArchiveDBFn
+	})
+
+	s := newTestSession()
+	rec := performChatArchiveUpload(t, newChatImportTestApp(s), map[string]string{"platform": "slack"}, "{}")
+
+	assertRedirect(t, rec, "/chats/import")
+	assertFlash(t, s, FlashError, "Failed to read chat archive: "+db.ErrChatArchivePlatformUnsupported.Error())
+}
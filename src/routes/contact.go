@@ -6,6 +6,7 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -144,12 +145,15 @@ func NewContactForm(c flamego.Context, t template.Template, data template.Data)
 		data["AllTags"] = allTags
 	}
 
+	data["TierCadenceDays"] = loadTierCadenceDays(c.Request().Context())
+
 	t.HTML(http.StatusOK, "contact_new")
 }
 
 // CreateContact handles the contact creation form submission
 func CreateContact(c flamego.Context, s session.Session, t template.Template, data template.Data) {
 	data["EnableAutocomplete"] = true
+	data["TierCadenceDays"] = loadTierCadenceDays(c.Request().Context())
 
 	// Parse form data
 	if err := c.Request().ParseForm(); err != nil {
@@ -168,15 +172,28 @@ func CreateContact(c flamego.Context, s session.Session, t template.Template, da
 	cardDAVUUID := getOptionalString(form.Get("carddav_uuid"))
 
 	var (
-		nameGiven    string
-		nameFamily   *string
-		organization *string
-		title        *string
+		nameGiven       string
+		nameFamily      *string
+		organization    *string
+		title           *string
+		cardDAVSourceID *string
 	)
 
 	if cardDAVUUID != nil && *cardDAVUUID != "" {
 		// Import from CardDAV
-		cardDAVContact, err := db.GetCardDAVContact(c.Request().Context(), *cardDAVUUID)
+		sourceID, err := selectCardDAVSource(c.Request().Context(), strings.TrimSpace(form.Get("carddav_source")))
+		if err != nil {
+			logger.Error("Error selecting CardDAV source", "error", err)
+			data["Error"] = "Failed to fetch contact from CardDAV: " + err.Error()
+
+			t.HTML(http.StatusBadRequest, "contact_new")
+
+			return
+		}
+
+		cardDAVSourceID = &sourceID
+
+		cardDAVContact, err := db.GetCardDAVContact(c.Request().Context(), sourceID, *cardDAVUUID)
 		if err != nil {
 			logger.Error("Error fetching CardDAV contact", "error", err)
 			data["Error"] = "Failed to fetch contact from CardDAV: " + err.Error()
@@ -242,16 +259,17 @@ func CreateContact(c flamego.Context, s session.Session, t template.Template, da
 
 	// Create input struct
 	input := db.CreateContactInput{
-		NameGiven:    nameGiven,
-		NameFamily:   nameFamily,
-		Organization: organization,
-		Title:        title,
-		Email:        getOptionalString(form.Get("email")),
-		Phone:        getOptionalString(form.Get("phone")),
-		CallSign:     getOptionalString(form.Get("call_sign")),
-		CardDAVUUID:  cardDAVUUID,
-		IsService:    isService,
-		Tier:         tier,
+		NameGiven:       nameGiven,
+		NameFamily:      nameFamily,
+		Organization:    organization,
+		Title:           title,
+		Email:           getOptionalString(form.Get("email")),
+		Phone:           getOptionalString(form.Get("phone")),
+		CallSign:        getOptionalString(form.Get("call_sign")),
+		CardDAVUUID:     cardDAVUUID,
+		CardDAVSourceID: cardDAVSourceID,
+		IsService:       isService,
+		Tier:            tier,
 	}
 
 	// Create contact in database
@@ -325,6 +343,7 @@ func ViewContact(c flamego.Context, s session.Session, t template.Template, data
 	data["EnableAutocomplete"] = true
 	data["TierLower"] = strings.ToLower(string(contact.Tier))
 	data["CardDAVContact"] = contact.CardDAVContact
+	data["HasCardDAVConflict"] = hasCardDAVConflict(c.Request().Context(), contact)
 
 	if !contact.IsService {
 		activeExchangeLink, err := db.GetActiveContactExchangeLink(c.Request().Context(), contactID)
@@ -363,6 +382,22 @@ func ViewContact(c flamego.Context, s session.Session, t template.Template, data
 		}
 
 		data["ActivityGrid"] = buildActivityGrid(weekCounts, currentYear, 5)
+
+		relationships, err := db.ListContactRelationships(c.Request().Context(), contactID)
+		if err != nil {
+			logger.Error("Error fetching relationships for contact", "contact_id", contactID, "error", err)
+		} else {
+			data["Relationships"] = relationships
+		}
+
+		contactNames, err := db.ListContactNames(c.Request().Context())
+		if err != nil {
+			logger.Error("Error fetching contact names", "error", err)
+		} else {
+			data["ContactNames"] = contactNames
+		}
+
+		data["RelationshipTypes"] = db.RelationshipTypes
 	}
 
 	if contact.IsService {
@@ -450,6 +485,7 @@ func ViewContactChats(c flamego.Context, s session.Session, t template.Template,
 	data["Contact"] = contact
 	data["ContactName"] = contact.NameDisplay
 	data["Chats"] = chats
+	data["WhatsAppConnected"] = whatsAppConnectedFn()
 	data["IsContacts"] = true
 	data["Breadcrumbs"] = []BreadcrumbItem{
 		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
@@ -636,6 +672,10 @@ func parseChatPlatform(value string) db.ChatPlatform {
 		return db.ChatPlatformWhatsApp
 	case string(db.ChatPlatformSignal):
 		return db.ChatPlatformSignal
+	case string(db.ChatPlatformTelegram):
+		return db.ChatPlatformTelegram
+	case string(db.ChatPlatformMatrix):
+		return db.ChatPlatformMatrix
 	case string(db.ChatPlatformWeChat):
 		return db.ChatPlatformWeChat
 	case string(db.ChatPlatformTeams):
@@ -681,6 +721,28 @@ func EditContactForm(c flamego.Context, s session.Session, t template.Template,
 
 	data["Contact"] = contact
 	data["ContactName"] = contact.NameDisplay
+	data["HasCardDAVConflict"] = hasCardDAVConflict(c.Request().Context(), contact)
+
+	if contact.CardDAVUUID == nil {
+		sources, err := listCardDAVSourcesDBFn(c.Request().Context())
+		if err != nil {
+			logger.Error("Error listing CardDAV sources", "error", err)
+		} else {
+			data["CardDAVSources"] = sources
+		}
+	}
+
+	if !contact.IsService {
+		cadence, err := db.GetContactCadence(c.Request().Context(), contactID)
+		if err != nil {
+			logger.Error("Error fetching contact cadence", "contact_id", contactID, "error", err)
+		} else {
+			data["Cadence"] = cadence
+			data["CadenceSnoozed"] = cadence.IsSnoozed(time.Now())
+		}
+
+		data["TierCadenceDays"] = loadTierCadenceDays(c.Request().Context())
+	}
 
 	meContact, err := db.GetMeContact(c.Request().Context())
 	if err != nil {
@@ -751,6 +813,25 @@ func UpdateContact(c flamego.Context, s session.Session, _ template.Template, da
 
 	tier := db.Tier(tierStr)
 
+	// Follow-up cadence fields are only present for personal contacts
+	hasCadence := form.Has("cadence_days")
+
+	cadenceDays, err := parseCadenceDays(form.Get("cadence_days"))
+	if err != nil {
+		SetErrorFlash(s, "Follow-up interval must be a positive number of days")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	snoozedUntil, err := parseSnoozeDate(form.Get("snoozed_until"))
+	if err != nil {
+		SetErrorFlash(s, "Snooze date must be in YYYY-MM-DD format")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
 	// Check if service status toggle was requested
 	if form.Get("toggle_service") == "true" {
 		isService := form.Get("is_service") == "true"
@@ -773,7 +854,7 @@ func UpdateContact(c flamego.Context, s session.Session, _ template.Template, da
 	}
 
 	// Update contact in database
-	err := db.UpdateContact(c.Request().Context(), input)
+	err = db.UpdateContact(c.Request().Context(), input)
 	if err != nil {
 		logger.Error("Error updating contact", "error", err)
 		data["Error"] = "Failed to update contact: " + err.Error()
@@ -783,6 +864,17 @@ func UpdateContact(c flamego.Context, s session.Session, _ template.Template, da
 		return
 	}
 
+	if hasCadence {
+		err = db.UpdateContactCadence(c.Request().Context(), contactID, cadenceDays, snoozedUntil)
+		if err != nil {
+			logger.Error("Error updating contact cadence", "contact_id", contactID, "error", err)
+			SetErrorFlash(s, "Failed to update follow-up cadence")
+			c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+			return
+		}
+	}
+
 	isMe := isPrimaryChecked(form.Get("is_me"))
 	if isMe {
 		err = db.SetContactAsMe(c.Request().Context(), contactID)
@@ -807,7 +899,16 @@ func UpdateContact(c flamego.Context, s session.Session, _ template.Template, da
 	// If contact is linked to CardDAV, push the update
 	contact, err := db.GetContact(c.Request().Context(), contactID)
 	if err == nil && contact.CardDAVUUID != nil && *contact.CardDAVUUID != "" {
-		if err := db.UpdateCardDAVContact(c.Request().Context(), contact); err != nil {
+		err := db.UpdateCardDAVContact(c.Request().Context(), contact)
+		if errors.Is(err, db.ErrCardDAVConflict) {
+			logger.Warn("Contact update conflicts with CardDAV changes", "contact_id", contactID)
+			SetWarningFlash(s, "Contact saved, but the CardDAV card was also changed. Resolve the conflict to sync it.")
+			c.Redirect("/contact/"+contactID+"/carddav/conflict", http.StatusSeeOther)
+
+			return
+		}
+
+		if err != nil {
 			logger.Error("Error pushing contact update to CardDAV", "error", err)
 			// Don't fail the whole operation, just log the error
 		}
@@ -1297,11 +1398,12 @@ func AddLog(c flamego.Context) {
 	logType := parseLogType(form.Get("log_type"))
 
 	input := db.AddLogInput{
-		ContactID: contactID,
-		LogType:   logType,
-		LoggedAt:  getOptionalString(form.Get("logged_at")),
-		Subject:   getOptionalString(form.Get("subject")),
-		Content:   getOptionalString(form.Get("content")),
+		ContactID:      contactID,
+		LogType:        logType,
+		LoggedAt:       getOptionalString(form.Get("logged_at")),
+		Subject:        getOptionalString(form.Get("subject")),
+		Content:        getOptionalString(form.Get("content")),
+		IntroducedByID: getOptionalString(form.Get("introduced_by")),
 	}
 
 	err := db.AddLog(c.Request().Context(), input)
@@ -1394,8 +1496,16 @@ func LinkCardDAV(c flamego.Context, _ template.Template, data template.Data) {
 		return
 	}
 
+	sourceID, err := selectCardDAVSource(c.Request().Context(), strings.TrimSpace(form.Get("carddav_source")))
+	if err != nil {
+		logger.Error("Error selecting CardDAV source", "error", err)
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
 	// Check if this CardDAV UUID is already linked to another contact
-	isLinked, err := db.IsCardDAVUUIDLinked(c.Request().Context(), cardDAVUUID)
+	isLinked, err := db.IsCardDAVUUIDLinked(c.Request().Context(), sourceID, cardDAVUUID)
 	if err != nil {
 		logger.Error("Error checking if CardDAV UUID is linked", "error", err)
 
@@ -1416,7 +1526,7 @@ func LinkCardDAV(c flamego.Context, _ template.Template, data template.Data) {
 		return
 	}
 
-	err = db.LinkCardDAV(c.Request().Context(), contactID, cardDAVUUID)
+	err = db.LinkCardDAV(c.Request().Context(), contactID, sourceID, cardDAVUUID)
 	if err != nil {
 		logger.Error("Error linking CardDAV contact", "error", err)
 
@@ -1454,7 +1564,24 @@ func MigrateToCardDAV(c flamego.Context, s session.Session) {
 		return
 	}
 
-	err := db.MigrateContactToCardDAV(c.Request().Context(), contactID)
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	sourceID, err := selectCardDAVSource(c.Request().Context(), strings.TrimSpace(c.Request().Form.Get("carddav_source")))
+	if err != nil {
+		logger.Error("Error selecting CardDAV source", "error", err)
+		SetErrorFlash(s, "No CardDAV source to migrate to")
+		c.Redirect("/contact/"+contactID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	err = db.MigrateContactToCardDAV(c.Request().Context(), contactID, sourceID)
 	if err != nil {
 		logger.Error("Error migrating contact to CardDAV", "contact_id", contactID, "error", err)
 		SetErrorFlash(s, "Failed to migrate contact")
@@ -1488,9 +1615,24 @@ func shouldHideCardDAVContact(contact db.CardDAVContact) bool {
 	return false
 }
 
-// ListCardDAVContacts returns a list of CardDAV contacts as JSON
+// ListCardDAVContacts returns a list of CardDAV contacts of the source given
+// by the source query parameter as JSON
 func ListCardDAVContacts(c flamego.Context) {
-	contacts, err := db.ListCardDAVContacts(c.Request().Context())
+	sourceID, err := selectCardDAVSource(c.Request().Context(), c.Query("source"))
+	if err != nil {
+		logger.Error("Error selecting CardDAV source", "error", err)
+		c.ResponseWriter().WriteHeader(http.StatusBadRequest)
+
+		if err := json.NewEncoder(c.ResponseWriter()).Encode(map[string]string{
+			"error": "Failed to select CardDAV source: " + err.Error(),
+		}); err != nil {
+			logger.Error("Error encoding CardDAV source error", "error", err)
+		}
+
+		return
+	}
+
+	contacts, err := db.ListCardDAVContacts(c.Request().Context(), sourceID)
 	if err != nil {
 		logger.Error("Error listing CardDAV contacts", "error", err)
 		c.ResponseWriter().WriteHeader(http.StatusInternalServerError)
@@ -1514,7 +1656,7 @@ func ListCardDAVContacts(c flamego.Context) {
 	}
 
 	// Get all linked CardDAV UUIDs with service status
-	linkedMap, err := db.GetLinkedCardDAVUUIDsWithServiceStatus(c.Request().Context())
+	linkedMap, err := db.GetLinkedCardDAVUUIDsWithServiceStatus(c.Request().Context(), sourceID)
 	if err != nil {
 		logger.Error("Error getting linked CardDAV UUIDs", "error", err)
 		c.ResponseWriter().WriteHeader(http.StatusInternalServerError)
@@ -1551,7 +1693,14 @@ func ListCardDAVContacts(c flamego.Context) {
 }
 
 // CardDAVPicker renders the CardDAV contact picker popup
-func CardDAVPicker(_ flamego.Context, t template.Template, _ template.Data) {
+func CardDAVPicker(c flamego.Context, t template.Template, data template.Data) {
+	sources, err := listCardDAVSourcesDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error listing CardDAV sources", "error", err)
+	}
+
+	data["CardDAVSources"] = sources
+
 	t.HTML(http.StatusOK, "carddav_picker")
 }
 
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+var (
+	addAddressDBFn        = db.AddAddress
+	updateAddressDBFn     = db.UpdateAddress
+	deleteAddressDBFn     = db.DeleteAddress
+	getContactAddressDBFn = db.GetContactAddress
+	writeLocationMapFn    = utils.WriteLocationMap
+)
+
+var (
+	errAddressCoordinatesPair  = errors.New("latitude and longitude must be provided together")
+	errAddressCoordinateNumber = errors.New("coordinates must be numbers")
+)
+
+// addressMapConfig is the size of the map shown with an address
+var addressMapConfig = utils.MapConfig{Width: 600, Height: 300}
+
+// parseAddressForm reads the structured address fields from a submitted form
+func parseAddressForm(form url.Values) (db.AddressFields, error) {
+	fields := db.AddressFields{
+		Street:      getOptionalString(form.Get("street")),
+		Extended:    getOptionalString(form.Get("extended")),
+		POBox:       getOptionalString(form.Get("po_box")),
+		Locality:    getOptionalString(form.Get("locality")),
+		Region:      getOptionalString(form.Get("region")),
+		PostalCode:  getOptionalString(form.Get("postal_code")),
+		Country:     getOptionalString(form.Get("country")),
+		AddressType: db.AddressType(form.Get("address_type")),
+		IsPrimary:   isPrimaryChecked(form.Get("is_primary")),
+	}
+
+	latStr := strings.TrimSpace(form.Get("latitude"))
+	lonStr := strings.TrimSpace(form.Get("longitude"))
+
+	if latStr == "" && lonStr == "" {
+		return fields, nil
+	}
+
+	if latStr == "" || lonStr == "" {
+		return fields, errAddressCoordinatesPair
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return fields, errAddressCoordinateNumber
+	}
+
+	lon, err := strconv.ParseFloat(lonStr, 64)
+	if err != nil {
+		return fields, errAddressCoordinateNumber
+	}
+
+	fields.Latitude = &lat
+	fields.Longitude = &lon
+
+	return fields, nil
+}
+
+// addressErrorMessage maps address validation errors to a flash message
+func addressErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, db.ErrAddressEmpty):
+		return "Address requires at least one field"
+	case errors.Is(err, db.ErrAddressCoordinatesInvalid):
+		return "Latitude must be between -90 and 90 and longitude between -180 and 180"
+	case errors.Is(err, errAddressCoordinatesPair):
+		return "Latitude and longitude must be provided together"
+	case errors.Is(err, errAddressCoordinateNumber):
+		return "Latitude and longitude must be numbers"
+	case errors.Is(err, db.ErrAddressNotFound):
+		return "Address not found"
+	default:
+		return fallback
+	}
+}
+
+// pushContactAddressesToCardDAV sends the contact's addresses to CardDAV when
+// the contact is linked, then syncs back so local rows adopt the CardDAV source
+func pushContactAddressesToCardDAV(ctx context.Context, contactID string, sync bool) {
+	contact, err := db.GetContact(ctx, contactID)
+	if err != nil || contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" {
+		return
+	}
+
+	if err := db.UpdateCardDAVContact(ctx, contact); err != nil {
+		logger.Error("Error pushing addresses to CardDAV", "error", err)
+		return
+	}
+
+	if !sync {
+		return
+	}
+
+	if err := db.SyncContactFromCardDAV(ctx, contactID, *contact.CardDAVUUID); err != nil {
+		logger.Error("Error syncing contact after address change", "error", err)
+	}
+}
+
+// AddAddress handles adding a new postal address to a contact
+func AddAddress(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	if contactID == "" {
+		c.Redirect("/", http.StatusSeeOther)
+		return
+	}
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	fields, err := parseAddressForm(c.Request().Form)
+	if err != nil {
+		SetErrorFlash(s, addressErrorMessage(err, "Invalid address"))
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	input := db.AddAddressInput{
+		ContactID:     contactID,
+		AddressFields: fields,
+	}
+
+	if err := addAddressDBFn(c.Request().Context(), input); err != nil {
+		logger.Error("Error adding address", "error", err)
+		SetErrorFlash(s, addressErrorMessage(err, "Failed to add address"))
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	pushContactAddressesToCardDAV(c.Request().Context(), contactID, true)
+
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// UpdateAddress handles updating a postal address
+func UpdateAddress(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	addressID := c.Param("address_id")
+
+	if contactID == "" || addressID == "" {
+		c.Redirect("/", http.StatusSeeOther)
+		return
+	}
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	fields, err := parseAddressForm(c.Request().Form)
+	if err != nil {
+		SetErrorFlash(s, addressErrorMessage(err, "Invalid address"))
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	input := db.UpdateAddressInput{
+		ID:            addressID,
+		ContactID:     contactID,
+		AddressFields: fields,
+	}
+
+	if err := updateAddressDBFn(c.Request().Context(), input); err != nil {
+		logger.Error("Error updating address", "error", err)
+		SetErrorFlash(s, addressErrorMessage(err, "Failed to update address"))
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	pushContactAddressesToCardDAV(c.Request().Context(), contactID, false)
+
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// DeleteAddress handles deleting a postal address
+func DeleteAddress(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	addressID := c.Param("address_id")
+
+	if contactID == "" || addressID == "" {
+		c.Redirect("/", http.StatusSeeOther)
+		return
+	}
+
+	if err := deleteAddressDBFn(c.Request().Context(), addressID, contactID); err != nil {
+		logger.Error("Error deleting address", "error", err)
+		SetErrorFlash(s, "Failed to delete address")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	pushContactAddressesToCardDAV(c.Request().Context(), contactID, false)
+
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// ContactAddressMap serves a static map image for an address with coordinates
+func ContactAddressMap(c flamego.Context) {
+	addr, err := getContactAddressDBFn(c.Request().Context(), c.Param("address_id"), c.Param("id"))
+	if err != nil || !addr.HasCoordinates() {
+		c.ResponseWriter().WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeLocationMapFn(&buf, *addr.Latitude, *addr.Longitude, addressMapConfig); err != nil {
+		logger.Error("Error rendering address map", "address_id", addr.ID, "error", err)
+		c.ResponseWriter().WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	c.ResponseWriter().Header().Set("Content-Type", "image/png")
+	c.ResponseWriter().Header().Set("Cache-Control", "private, max-age=86400")
+	c.ResponseWriter().Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	c.ResponseWriter().WriteHeader(http.StatusOK)
+
+	if _, err := c.ResponseWriter().Write(buf.Bytes()); err != nil {
+		logger.Error("Error writing address map response", "error", err)
+	}
+}
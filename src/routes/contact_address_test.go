@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+func newContactAddressTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/contact/{id}/address", AddAddress)
+	f.Post("/contact/{id}/address/{address_id}/edit", UpdateAddress)
+	f.Post("/contact/{id}/address/{address_id}/delete", DeleteAddress)
+	f.Get("/contact/{id}/address/{address_id}/map.png", ContactAddressMap)
+
+	return f
+}
+
+func TestParseAddressForm(t *testing.T) {
+	fields, err := parseAddressForm(url.Values{
+		"street":       {" 1 Main Street "},
+		"locality":     {"Dubai"},
+		"country":      {""},
+		"address_type": {"work"},
+		"is_primary":   {"on"},
+		"latitude":     {"25.2048"},
+		"longitude":    {"55.2708"},
+	})
+	if err != nil {
+		t.Fatalf("parseAddressForm failed: %v", err)
+	}
+
+	if fields.Street == nil || *fields.Street != "1 Main Street" || fields.Country != nil {
+		t.Fatalf("expected trimmed optional fields, got %+v", fields)
+	}
+
+	if fields.AddressType != db.AddressWork || !fields.IsPrimary {
+		t.Fatalf("expected primary work address, got %+v", fields)
+	}
+
+	if fields.Latitude == nil || *fields.Latitude != 25.2048 || fields.Longitude == nil || *fields.Longitude != 55.2708 {
+		t.Fatalf("expected coordinates, got %v %v", fields.Latitude, fields.Longitude)
+	}
+
+	if _, err := parseAddressForm(url.Values{"latitude": {"25"}}); !errors.Is(err, errAddressCoordinatesPair) {
+		t.Fatalf("expected coordinate pair error, got %v", err)
+	}
+
+	if _, err := parseAddressForm(url.Values{"latitude": {"north"}, "longitude": {"55"}}); !errors.Is(err, errAddressCoordinateNumber) {
+		t.Fatalf("expected coordinate number error, got %v", err)
+	}
+}
+
+func TestAddAddressRejectsInvalidCoordinates(t *testing.T) {
+	originalAddAddressDBFn := addAddressDBFn
+	addAddressDBFn = func(context.Context, db.AddAddressInput) error {
+		return errTestShouldNotBeCalled
+	}
+
+	t.Cleanup(func() {
+		addAddressDBFn = originalAddAddressDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactAddressTestApp(s), "/contact/c1/address", url.Values{
+		"street":    {"1 Main Street"},
+		"longitude": {"55"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Latitude and longitude must be provided together")
+}
+
+func TestAddAddressValidationErrorSetsFlash(t *testing.T) {
+	originalAddAddressDBFn := addAddressDBFn
+	addAddressDBFn = func(context.Context, db.AddAddressInput) error {
+		return db.ErrAddressEmpty
+	}
+
+	t.Cleanup(func() {
+		addAddressDBFn = originalAddAddressDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactAddressTestApp(s), "/contact/c1/address", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Address requires at least one field")
+}
+
+func TestAddAddressPassesInput(t *testing.T) {
+	originalAddAddressDBFn := addAddressDBFn
+
+	var captured db.AddAddressInput
+
+	addAddressDBFn = func(_ context.Context, input db.AddAddressInput) error {
+		captured = input
+		return nil
+	}
+
+	t.Cleanup(func() {
+		addAddressDBFn = originalAddAddressDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactAddressTestApp(s), "/contact/c1/address", url.Values{
+		"postal_code":  {"12345"},
+		"address_type": {"home"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertNoFlash(t, s)
+
+	if captured.ContactID != "c1" || captured.PostalCode == nil || *captured.PostalCode != "12345" {
+		t.Fatalf("unexpected input: %+v", captured)
+	}
+}
+
+func TestUpdateAddressNotFoundSetsFlash(t *testing.T) {
+	originalUpdateAddressDBFn := updateAddressDBFn
+	updateAddressDBFn = func(_ context.Context, input db.UpdateAddressInput) error {
+		if input.ID != "a1" || input.ContactID != "c1" {
+			t.Fatalf("unexpected input: %+v", input)
+		}
+
+		return db.ErrAddressNotFound
+	}
+
+	t.Cleanup(func() {
+		updateAddressDBFn = originalUpdateAddressDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactAddressTestApp(s), "/contact/c1/address/a1/edit", url.Values{
+		"street": {"1 Main Street"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Address not found")
+}
+
+func TestDeleteAddressDatabaseFailure(t *testing.T) {
+	originalDeleteAddressDBFn := deleteAddressDBFn
+	deleteAddressDBFn = func(context.Context, string, string) error {
+		return errTestBoom
+	}
+
+	t.Cleanup(func() {
+		deleteAddressDBFn = originalDeleteAddressDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactAddressTestApp(s), "/contact/c1/address/a1/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Failed to delete address")
+}
+
+func TestContactAddressMap(t *testing.T) {
+	originalGetContactAddressDBFn := getContactAddressDBFn
+	originalWriteLocationMapFn := writeLocationMapFn
+
+	lat, lon := 25.2048, 55.2708
+	getContactAddressDBFn = func(_ context.Context, addressID, _ string) (*db.ContactAddress, error) {
+		switch addressID {
+		case "mapped":
+			return &db.ContactAddress{Latitude: &lat, Longitude: &lon}, nil
+		case "unmapped":
+			return &db.ContactAddress{}, nil
+		default:
+			return nil, db.ErrAddressNotFound
+		}
+	}
+	writeLocationMapFn = func(w io.Writer, gotLat, gotLon float64, config utils.MapConfig) error {
+		if gotLat != lat || gotLon != lon || config.Width == 0 {
+			t.Fatalf("unexpected map request: %f %f %+v", gotLat, gotLon, config)
+		}
+
+		_, err := w.Write([]byte("png"))
+
+		return err
+	}
+
+	t.Cleanup(func() {
+		getContactAddressDBFn = originalGetContactAddressDBFn
+		writeLocationMapFn = originalWriteLocationMapFn
+	})
+
+	f := newContactAddressTestApp(newTestSession())
+
+	for _, addressID := range []string{"missing", "unmapped"} {
+		rec := httptest.NewRecorder()
+		f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact/c1/address/"+addressID+"/map.png", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected status %d, got %d", addressID, http.StatusNotFound, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact/c1/address/mapped/map.png", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "png" {
+		t.Fatalf("expected map image, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("expected image/png, got %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	getContactBriefingDBFn  = db.GetContactBriefing
+	streamContactBriefingFn = db.StreamContactBriefing
+)
+
+// GenerateContactBriefing streams a "prepare for meeting" briefing for a
+// contact using Server-Sent Events
+func GenerateContactBriefing(c flamego.Context) {
+	ctx := c.Request().Context()
+	contactID := c.Param("id")
+	w := c.ResponseWriter()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	sendEvent := func(event, data string) {
+		if event != "" {
+			if _, err := w.Write([]byte("event: " + event + "\n")); err != nil {
+				logger.Error("Error writing SSE event", "error", err)
+				return
+			}
+		}
+
+		escapedData := strings.ReplaceAll(data, "\n", "\ndata: ")
+		if _, err := w.Write([]byte("data: " + escapedData + "\n\n")); err != nil {
+			logger.Error("Error writing SSE data", "error", err)
+			return
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	sendError := func(message string) {
+		sendEvent("error", message)
+	}
+
+	now := time.Now()
+
+	briefing, err := getContactBriefingDBFn(ctx, contactID, now)
+	if err != nil {
+		logger.Error("Error loading contact briefing", "contact_id", contactID, "error", err)
+		sendError("Contact not found")
+
+		return
+	}
+
+	if briefing.Contact.IsService {
+		sendError("Briefings are not available for service contacts")
+		return
+	}
+
+	err = streamContactBriefingFn(ctx, briefing, now, func(chunk string) error {
+		sendEvent("chunk", chunk)
+		return nil
+	})
+	if err != nil {
+		logger.Error("Error generating contact briefing", "contact_id", contactID, "error", err)
+		sendError("Failed to generate briefing: " + err.Error())
+
+		return
+	}
+
+	sendEvent("done", "")
+}
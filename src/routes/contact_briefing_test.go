@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func performContactBriefingRequest(t *testing.T) string {
+	t.Helper()
+
+	f := flamego.New()
+	f.Post("/contact/{id}/briefing", GenerateContactBriefing)
+
+	req := httptest.NewRequest(http.MethodPost, "/contact/contact-1/briefing", nil)
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", got)
+	}
+
+	return rec.Body.String()
+}
+
+func stubContactBriefing(t *testing.T, contact db.Contact) {
+	t.Helper()
+
+	originalGetContactBriefingDBFn := getContactBriefingDBFn
+	originalStreamContactBriefingFn := streamContactBriefingFn
+
+	t.Cleanup(func() {
+		getContactBriefingDBFn = originalGetContactBriefingDBFn
+		streamContactBriefingFn = originalStreamContactBriefingFn
+	})
+
+	getContactBriefingDBFn = func(_ context.Context, contactID string, _ time.Time) (*db.ContactBriefing, error) {
+		if contactID != "contact-1" {
+			return nil, db.ErrContactNotFound
+		}
+
+		return &db.ContactBriefing{Contact: &db.ContactDetail{Contact: contact}}, nil
+	}
+	streamContactBriefingFn = func(_ context.Context, _ *db.ContactBriefing, _ time.Time, onChunk func(string) error) error {
+		if err := onChunk("Met at\n"); err != nil {
+			return err
+		}
+
+		return onChunk("the conference")
+	}
+}
+
+func TestGenerateContactBriefingStreamsChunks(t *testing.T) {
+	stubContactBriefing(t, db.Contact{NameDisplay: "Jane"})
+
+	body := performContactBriefingRequest(t)
+
+	want := "event: chunk\ndata: Met at\ndata: \n\nevent: chunk\ndata: the conference\n\nevent: done\ndata: \n\n"
+	if body != want {
+		t.Fatalf("unexpected stream body:\n%q", body)
+	}
+}
+
+func TestGenerateContactBriefingRejectsServiceContacts(t *testing.T) {
+	stubContactBriefing(t, db.Contact{NameDisplay: "Bank", IsService: true})
+
+	streamContactBriefingFn = func(context.Context, *db.ContactBriefing, time.Time, func(string) error) error {
+		return errTestShouldNotBeCalled
+	}
+
+	body := performContactBriefingRequest(t)
+	if !strings.HasPrefix(body, "event: error\ndata: Briefings are not available") {
+		t.Fatalf("expected service contact error, got %q", body)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	listDuplicateCandidatesDBFn = db.ListDuplicateCandidates
+	mergeContactsDBFn           = db.MergeContacts
+	getContactDBFn              = db.GetContact
+	pushMergedContactFn         = pushMergedContactToCardDAV
+)
+
+// mergeURL builds the merge confirmation link for a survivor and duplicate pair
+func mergeURL(survivorID, duplicateID string) string {
+	query := url.Values{}
+	query.Set("survivor", survivorID)
+	query.Set("duplicate", duplicateID)
+
+	return "/contacts/merge?" + query.Encode()
+}
+
+// pushMergedContactToCardDAV writes the merged contact back to its CardDAV
+// card, then syncs so the moved rows adopt the CardDAV source
+func pushMergedContactToCardDAV(ctx context.Context, contactID string) error {
+	contact, err := db.GetContact(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" {
+		return nil
+	}
+
+	if err := db.UpdateCardDAVContact(ctx, contact); err != nil {
+		return err
+	}
+
+	return db.SyncContactFromCardDAV(ctx, contactID, *contact.CardDAVUUID)
+}
+
+// ContactDuplicates lists contact pairs that likely describe the same person
+func ContactDuplicates(c flamego.Context, t template.Template, data template.Data) {
+	candidates, err := listDuplicateCandidatesDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error finding duplicate contacts", "error", err)
+
+		data["Error"] = "Failed to find duplicate contacts"
+	} else {
+		data["Candidates"] = candidates
+	}
+
+	data["IsContacts"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "Duplicates", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "contact_duplicates")
+}
+
+// MergeContactsForm shows both contacts side by side before merging
+func MergeContactsForm(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	survivorID := c.Query("survivor")
+	duplicateID := c.Query("duplicate")
+
+	if survivorID == "" || duplicateID == "" || survivorID == duplicateID {
+		SetErrorFlash(s, "Select two different contacts to merge")
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := c.Request().Context()
+
+	survivor, err := getContactDBFn(ctx, survivorID)
+	if err != nil {
+		logger.Error("Error fetching survivor contact", "error", err)
+		SetErrorFlash(s, "Contact not found")
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	duplicate, err := getContactDBFn(ctx, duplicateID)
+	if err != nil {
+		logger.Error("Error fetching duplicate contact", "error", err)
+		SetErrorFlash(s, "Contact not found")
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	data["Survivor"] = survivor
+	data["Duplicate"] = duplicate
+	data["MergeContacts"] = []*db.ContactDetail{survivor, duplicate}
+	data["SwapURL"] = mergeURL(duplicateID, survivorID)
+	data["BothCardDAV"] = survivor.CardDAVUUID != nil && duplicate.CardDAVUUID != nil
+	data["IsContacts"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "Duplicates", URL: "/contacts/duplicates", IsCurrent: false},
+		{Name: "Merge", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "contact_merge")
+}
+
+// MergeContacts folds the duplicate contact into the survivor
+func MergeContacts(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	survivorID := c.Request().Form.Get("survivor")
+	duplicateID := c.Request().Form.Get("duplicate")
+
+	if survivorID == "" || duplicateID == "" {
+		SetErrorFlash(s, "Select two different contacts to merge")
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := c.Request().Context()
+
+	if err := mergeContactsDBFn(ctx, survivorID, duplicateID); err != nil {
+		logger.Error("Error merging contacts", "survivor", survivorID, "duplicate", duplicateID, "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrMergeSameContact):
+			SetErrorFlash(s, "Select two different contacts to merge")
+		case errors.Is(err, db.ErrMergeMeContact):
+			SetErrorFlash(s, "Your own contact can only be kept, not merged away")
+		case errors.Is(err, db.ErrContactNotFound):
+			SetErrorFlash(s, "Contact not found")
+		default:
+			SetErrorFlash(s, "Failed to merge contacts")
+		}
+
+		c.Redirect("/contacts/duplicates", http.StatusSeeOther)
+
+		return
+	}
+
+	if err := pushMergedContactFn(ctx, survivorID); err != nil {
+		logger.Error("Error updating CardDAV after merge", "contact_id", survivorID, "error", err)
+		SetWarningFlash(s, "Contacts merged, but the CardDAV card could not be updated")
+		c.Redirect("/contact/"+survivorID, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Contacts merged successfully")
+	c.Redirect("/contact/"+survivorID, http.StatusSeeOther)
+}
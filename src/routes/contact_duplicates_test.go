@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newContactDuplicatesTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/contacts/merge", MergeContacts)
+
+	return f
+}
+
+func stubMergeContacts(t *testing.T, mergeErr, pushErr error) *[]string {
+	t.Helper()
+
+	var calls []string
+
+	originalMergeContactsDBFn := mergeContactsDBFn
+	originalPushMergedContactFn := pushMergedContactFn
+
+	mergeContactsDBFn = func(_ context.Context, survivorID, duplicateID string) error {
+		calls = append(calls, "merge:"+survivorID+":"+duplicateID)
+		return mergeErr
+	}
+	pushMergedContactFn = func(_ context.Context, contactID string) error {
+		calls = append(calls, "push:"+contactID)
+		return pushErr
+	}
+
+	t.Cleanup(func() {
+		mergeContactsDBFn = originalMergeContactsDBFn
+		pushMergedContactFn = originalPushMergedContactFn
+	})
+
+	return &calls
+}
+
+func TestMergeURLSwapsSides(t *testing.T) {
+	if got := mergeURL("a", "b"); got != "/contacts/merge?duplicate=b&survivor=a" {
+		t.Fatalf("unexpected merge URL %q", got)
+	}
+}
+
+func TestMergeContactsRedirectsToSurvivor(t *testing.T) {
+	calls := stubMergeContacts(t, nil, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactDuplicatesTestApp(s), "/contacts/merge", url.Values{
+		"survivor":  {"keep"},
+		"duplicate": {"drop"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/keep")
+	assertFlash(t, s, FlashSuccess, "Contacts merged successfully")
+
+	if len(*calls) != 2 || (*calls)[0] != "merge:keep:drop" || (*calls)[1] != "push:keep" {
+		t.Fatalf("unexpected calls %v", *calls)
+	}
+}
+
+func TestMergeContactsRequiresBothContacts(t *testing.T) {
+	calls := stubMergeContacts(t, errTestShouldNotBeCalled, errTestShouldNotBeCalled)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactDuplicatesTestApp(s), "/contacts/merge", url.Values{
+		"survivor": {"keep"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contacts/duplicates")
+	assertFlash(t, s, FlashError, "Select two different contacts to merge")
+
+	if len(*calls) != 0 {
+		t.Fatalf("expected no calls, got %v", *calls)
+	}
+}
+
+func TestMergeContactsMapsErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{db.ErrMergeSameContact, "Select two different contacts to merge"},
+		{db.ErrMergeMeContact, "Your own contact can only be kept, not merged away"},
+		{db.ErrContactNotFound, "Contact not found"},
+		{errTestBoom, "Failed to merge contacts"},
+	}
+
+	for _, tt := range tests {
+		calls := stubMergeContacts(t, tt.err, errTestShouldNotBeCalled)
+
+		s := newTestSession()
+		rec := performFormPOST(t, newContactDuplicatesTestApp(s), "/contacts/merge", url.Values{
+			"survivor":  {"keep"},
+			"duplicate": {"drop"},
+		}, nil)
+
+		assertRedirect(t, rec, "/contacts/duplicates")
+		assertFlash(t, s, FlashError, tt.want)
+
+		if len(*calls) != 1 {
+			t.Fatalf("expected only the merge call for %v, got %v", tt.err, *calls)
+		}
+	}
+}
+
+func TestMergeContactsWarnsWhenCardDAVPushFails(t *testing.T) {
+	stubMergeContacts(t, nil, errTestBoom)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactDuplicatesTestApp(s), "/contacts/merge", url.Values{
+		"survivor":  {"keep"},
+		"duplicate": {"drop"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/keep")
+	assertFlash(t, s, FlashWarning, "Contacts merged, but the CardDAV card could not be updated")
+}
@@ -552,7 +552,9 @@ func buildContactVCard(contact *db.ContactDetail, additionalNote string) ([]byte
 		FamilyName: familyName,
 	})
 
-	if contact.PhotoURL != nil {
+	// Uploaded photos sit behind the authenticated photo route, so only
+	// external photo URLs are shared
+	if contact.PhotoURL != nil && contact.PhotoPath == nil {
 		photoURL := strings.TrimSpace(*contact.PhotoURL)
 		if photoURL != "" {
 			card.Add(vcard.FieldPhoto, &vcard.Field{
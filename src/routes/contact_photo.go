@@ -0,0 +1,282 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+var (
+	setContactPhotoDBFn    = db.SetContactPhoto
+	getContactPhotoDBFn    = db.GetContactPhoto
+	deleteContactPhotoDBFn = db.DeleteContactPhoto
+	pushContactPhotoFn     = pushContactPhotoToCardDAV
+	cropPhotoFn            = utils.CropPhoto
+	resizePhotoFn          = utils.ResizePhoto
+)
+
+const (
+	// contactPhotoMaxUpload caps the size of an uploaded photo
+	contactPhotoMaxUpload = 10 << 20
+	// contactPhotoStoredSize is the edge length of the stored square photo
+	contactPhotoStoredSize = 512
+	// contactPhotoCacheEntries bounds the in-memory thumbnail cache
+	contactPhotoCacheEntries = 256
+)
+
+// contactPhotoSizes are the thumbnail sizes served, smallest first. The
+// default fits the large avatar on high density screens.
+var (
+	contactPhotoSizes       = []int{96, 192, contactPhotoStoredSize}
+	defaultContactPhotoSize = 192
+)
+
+// contactPhotoCache keeps resized thumbnails keyed by contact, upload
+// version and size. Versioned URLs never change content, so entries do not
+// expire; the cache is simply emptied when it fills up.
+var contactPhotoCache = struct {
+	sync.Mutex
+	entries map[string][]byte
+}{entries: make(map[string][]byte)}
+
+func cachedContactPhoto(key string) ([]byte, bool) {
+	contactPhotoCache.Lock()
+	defer contactPhotoCache.Unlock()
+
+	photo, ok := contactPhotoCache.entries[key]
+
+	return photo, ok
+}
+
+func storeContactPhoto(key string, photo []byte) {
+	contactPhotoCache.Lock()
+	defer contactPhotoCache.Unlock()
+
+	if len(contactPhotoCache.entries) >= contactPhotoCacheEntries {
+		contactPhotoCache.entries = make(map[string][]byte)
+	}
+
+	contactPhotoCache.entries[key] = photo
+}
+
+// contactPhotoSize picks the smallest served size that covers the request
+func contactPhotoSize(value string) int {
+	requested, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || requested <= 0 {
+		return defaultContactPhotoSize
+	}
+
+	for _, size := range contactPhotoSizes {
+		if requested <= size {
+			return size
+		}
+	}
+
+	return contactPhotoSizes[len(contactPhotoSizes)-1]
+}
+
+// parseContactPhotoCrop reads the crop chosen in the editor, falling back
+// to the largest centred square for missing values
+func parseContactPhotoCrop(form url.Values) utils.PhotoCrop {
+	crop := utils.DefaultPhotoCrop
+
+	parse := func(name string, target *float64) {
+		if value, err := strconv.ParseFloat(strings.TrimSpace(form.Get(name)), 64); err == nil {
+			*target = value
+		}
+	}
+
+	parse("crop_zoom", &crop.Zoom)
+	parse("crop_x", &crop.X)
+	parse("crop_y", &crop.Y)
+
+	return crop
+}
+
+// pushContactPhotoToCardDAV writes the photo into the linked CardDAV card.
+// Contacts without a card are left alone; an empty photo removes it.
+func pushContactPhotoToCardDAV(ctx context.Context, contactID string, photo []byte) error {
+	contact, err := getContactDBFn(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" || contact.CardDAVSourceID == nil {
+		return nil
+	}
+
+	return db.UpdateCardDAVContactPhoto(ctx, *contact.CardDAVSourceID, *contact.CardDAVUUID, photo)
+}
+
+// UploadContactPhoto crops, stores and pushes a new photo for a contact
+func UploadContactPhoto(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	editURL := "/contact/" + contactID + "/edit"
+
+	if err := c.Request().ParseMultipartForm(contactPhotoMaxUpload); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse upload form")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	file, _, err := c.Request().FormFile("photo")
+	if err != nil {
+		logger.Error("Error getting file", "error", err)
+		SetErrorFlash(s, "No photo uploaded or invalid file")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Error("Error closing photo upload file", "error", err)
+		}
+	}()
+
+	photo, err := cropPhotoFn(file, parseContactPhotoCrop(c.Request().Form), contactPhotoStoredSize)
+	if err != nil {
+		logger.Error("Error processing contact photo", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Could not read the photo. Upload a JPEG, PNG or GIF image.")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := c.Request().Context()
+
+	if err := setContactPhotoDBFn(ctx, contactID, photo); err != nil {
+		logger.Error("Error saving contact photo", "contact_id", contactID, "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrContactNotFound):
+			SetErrorFlash(s, "Contact not found")
+		case errors.Is(err, db.ErrNoWebDAVPathsConfigured), errors.Is(err, db.ErrWebDAVFilesPathNotConfigured):
+			SetErrorFlash(s, "Photo storage is not configured")
+		default:
+			SetErrorFlash(s, "Failed to save photo")
+		}
+
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if err := pushContactPhotoFn(ctx, contactID, photo); err != nil {
+		logger.Error("Error pushing contact photo to CardDAV", "contact_id", contactID, "error", err)
+		SetWarningFlash(s, "Photo saved, but the CardDAV card could not be updated")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Photo updated")
+	c.Redirect(editURL, http.StatusSeeOther)
+}
+
+// DeleteContactPhoto removes a contact's photo here and from CardDAV
+func DeleteContactPhoto(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	editURL := "/contact/" + contactID + "/edit"
+	ctx := c.Request().Context()
+
+	if err := deleteContactPhotoDBFn(ctx, contactID); err != nil {
+		logger.Error("Error deleting contact photo", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Failed to remove photo")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if err := pushContactPhotoFn(ctx, contactID, nil); err != nil {
+		logger.Error("Error removing contact photo from CardDAV", "contact_id", contactID, "error", err)
+		SetWarningFlash(s, "Photo removed, but the CardDAV card could not be updated")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Photo removed")
+	c.Redirect(editURL, http.StatusSeeOther)
+}
+
+// ContactPhoto serves a resized thumbnail of an uploaded contact photo.
+// Requests carrying the upload version are cached by the browser for good.
+func ContactPhoto(c flamego.Context) {
+	contactID := c.Param("id")
+	version := strings.TrimSpace(c.Query("v"))
+	size := contactPhotoSize(c.Query("size"))
+	headers := c.ResponseWriter().Header()
+
+	etag := fmt.Sprintf(`"%s-%s-%d"`, contactID, version, size)
+	if version != "" && c.Request().Header.Get("If-None-Match") == etag {
+		headers.Set("ETag", etag)
+		c.ResponseWriter().WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	cacheKey := contactID + "|" + version + "|" + strconv.Itoa(size)
+
+	thumbnail, ok := cachedContactPhoto(cacheKey)
+	if !ok || version == "" {
+		photo, err := getContactPhotoDBFn(c.Request().Context(), contactID)
+		if err != nil {
+			if errors.Is(err, db.ErrContactNotFound) || errors.Is(err, db.ErrContactPhotoNotFound) {
+				c.ResponseWriter().WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			logger.Error("Error fetching contact photo", "contact_id", contactID, "error", err)
+			c.ResponseWriter().WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		thumbnail, err = resizePhotoFn(photo, size)
+		if err != nil {
+			logger.Error("Error resizing contact photo", "contact_id", contactID, "error", err)
+			c.ResponseWriter().WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		if version != "" {
+			storeContactPhoto(cacheKey, thumbnail)
+		}
+	}
+
+	headers.Set("Content-Type", "image/jpeg")
+	headers.Set("Content-Length", strconv.Itoa(len(thumbnail)))
+	headers.Set("X-Content-Type-Options", "nosniff")
+
+	if version != "" {
+		headers.Set("ETag", etag)
+		headers.Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		headers.Set("Cache-Control", "private, no-cache")
+	}
+
+	c.ResponseWriter().WriteHeader(http.StatusOK)
+
+	if _, err := c.ResponseWriter().Write(thumbnail); err != nil {
+		logger.Error("Error writing contact photo response", "error", err)
+	}
+}
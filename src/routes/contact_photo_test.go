@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+var errTestCardDAVDown = errors.New("carddav down")
+
+func newContactPhotoTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Get("/contact/{id}/photo", ContactPhoto)
+	f.Post("/contact/{id}/photo", UploadContactPhoto)
+	f.Post("/contact/{id}/photo/delete", DeleteContactPhoto)
+
+	return f
+}
+
+func performPhotoUpload(t *testing.T, f *flamego.Flame, target string, fields url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	for name, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				t.Fatalf("failed to write form field: %v", err)
+			}
+		}
+	}
+
+	part, err := writer.CreateFormFile("photo", "photo.png")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+
+	if _, err := io.WriteString(part, "image-bytes"); err != nil {
+		t.Fatalf("failed to write form file: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// stubContactPhotoUpload replaces image processing and storage, returning
+// the crop that was requested and the photos that were pushed
+func stubContactPhotoUpload(t *testing.T, setErr, pushErr error) (*utils.PhotoCrop, *[][]byte) {
+	t.Helper()
+
+	var (
+		gotCrop utils.PhotoCrop
+		pushed  [][]byte
+	)
+
+	originalCropPhotoFn := cropPhotoFn
+	originalSetContactPhotoDBFn := setContactPhotoDBFn
+	originalPushContactPhotoFn := pushContactPhotoFn
+
+	cropPhotoFn = func(_ io.Reader, crop utils.PhotoCrop, _ int) ([]byte, error) {
+		gotCrop = crop
+		return []byte("cropped"), nil
+	}
+	setContactPhotoDBFn = func(context.Context, string, []byte) error {
+		return setErr
+	}
+	pushContactPhotoFn = func(_ context.Context, _ string, photo []byte) error {
+		pushed = append(pushed, photo)
+		return pushErr
+	}
+
+	t.Cleanup(func() {
+		cropPhotoFn = originalCropPhotoFn
+		setContactPhotoDBFn = originalSetContactPhotoDBFn
+		pushContactPhotoFn = originalPushContactPhotoFn
+	})
+
+	return &gotCrop, &pushed
+}
+
+func TestUploadContactPhotoStoresAndPushes(t *testing.T) {
+	gotCrop, pushed := stubContactPhotoUpload(t, nil, nil)
+
+	s := newTestSession()
+	rec := performPhotoUpload(t, newContactPhotoTestApp(s), "/contact/c1/photo", url.Values{
+		"crop_zoom": {"2"},
+		"crop_x":    {"0.25"},
+	})
+
+	assertRedirect(t, rec, "/contact/c1/edit")
+	assertFlash(t, s, FlashSuccess, "Photo updated")
+
+	if *gotCrop != (utils.PhotoCrop{Zoom: 2, X: 0.25, Y: 0.5}) {
+		t.Fatalf("unexpected crop %+v", *gotCrop)
+	}
+
+	if len(*pushed) != 1 || string((*pushed)[0]) != "cropped" {
+		t.Fatalf("expected the cropped photo to be pushed, got %q", *pushed)
+	}
+}
+
+func TestUploadContactPhotoWarnsWhenCardDAVPushFails(t *testing.T) {
+	stubContactPhotoUpload(t, nil, errTestCardDAVDown)
+
+	s := newTestSession()
+	rec := performPhotoUpload(t, newContactPhotoTestApp(s), "/contact/c1/photo", nil)
+
+	assertRedirect(t, rec, "/contact/c1/edit")
+	assertFlash(t, s, FlashWarning, "Photo saved, but the CardDAV card could not be updated")
+}
+
+func TestUploadContactPhotoWithoutStorage(t *testing.T) {
+	_, pushed := stubContactPhotoUpload(t, db.ErrWebDAVFilesPathNotConfigured, nil)
+
+	s := newTestSession()
+	rec := performPhotoUpload(t, newContactPhotoTestApp(s), "/contact/c1/photo", nil)
+
+	assertRedirect(t, rec, "/contact/c1/edit")
+	assertFlash(t, s, FlashError, "Photo storage is not configured")
+
+	if len(*pushed) != 0 {
+		t.Fatal("expected nothing to be pushed when saving fails")
+	}
+}
+
+func TestDeleteContactPhotoRemovesFromCardDAV(t *testing.T) {
+	_, pushed := stubContactPhotoUpload(t, nil, nil)
+
+	originalDeleteContactPhotoDBFn := deleteContactPhotoDBFn
+	deleteContactPhotoDBFn = func(context.Context, string) error {
+		return nil
+	}
+
+	t.Cleanup(func() {
+		deleteContactPhotoDBFn = originalDeleteContactPhotoDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactPhotoTestApp(s), "/contact/c1/photo/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contact/c1/edit")
+	assertFlash(t, s, FlashSuccess, "Photo removed")
+
+	if len(*pushed) != 1 || (*pushed)[0] != nil {
+		t.Fatalf("expected an empty photo to be pushed, got %q", *pushed)
+	}
+}
+
+func TestContactPhotoServesCachedThumbnail(t *testing.T) {
+	fetches := 0
+
+	originalGetContactPhotoDBFn := getContactPhotoDBFn
+	originalResizePhotoFn := resizePhotoFn
+
+	getContactPhotoDBFn = func(_ context.Context, contactID string) ([]byte, error) {
+		fetches++
+
+		if contactID == "missing" {
+			return nil, db.ErrContactPhotoNotFound
+		}
+
+		return []byte("full"), nil
+	}
+	resizePhotoFn = func(_ []byte, size int) ([]byte, error) {
+		return []byte("thumb-" + string(rune('0'+size/96))), nil
+	}
+
+	t.Cleanup(func() {
+		getContactPhotoDBFn = originalGetContactPhotoDBFn
+		resizePhotoFn = originalResizePhotoFn
+	})
+
+	f := newContactPhotoTestApp(newTestSession())
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/contact/cached-photo/photo?v=7&size=90", nil)
+		rec := httptest.NewRecorder()
+		f.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "thumb-1" {
+			t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+		}
+
+		if rec.Header().Get("ETag") != `"cached-photo-7-96"` || rec.Header().Get("Content-Type") != "image/jpeg" {
+			t.Fatalf("unexpected headers %v", rec.Header())
+		}
+	}
+
+	if fetches != 1 {
+		t.Fatalf("expected the thumbnail to be cached, fetched %d times", fetches)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contact/cached-photo/photo?v=7&size=90", nil)
+	req.Header.Set("If-None-Match", `"cached-photo-7-96"`)
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected %d, got %d", http.StatusNotModified, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/contact/missing/photo", nil)
+	rec = httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestContactPhotoSize(t *testing.T) {
+	tests := map[string]int{
+		"":     defaultContactPhotoSize,
+		"abc":  defaultContactPhotoSize,
+		"40":   96,
+		"150":  192,
+		"400":  512,
+		"4000": 512,
+	}
+
+	for value, want := range tests {
+		if got := contactPhotoSize(value); got != want {
+			t.Fatalf("contactPhotoSize(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/event"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	addRelationshipDBFn      = db.AddRelationship
+	deleteRelationshipDBFn   = db.DeleteRelationship
+	getRelationshipGraphDBFn = db.GetRelationshipGraph
+)
+
+// relationshipGraphDepth is how many hops from a focused contact are shown
+const relationshipGraphDepth = 2
+
+// relationshipGraphTiers are the graph categories, so nodes are coloured by tier
+var relationshipGraphTiers = []db.Tier{db.TierA, db.TierB, db.TierC, db.TierD, db.TierE, db.TierF}
+
+// relationshipGraphCategory returns the category index for a tier
+func relationshipGraphCategory(tier db.Tier) int {
+	for i, candidate := range relationshipGraphTiers {
+		if candidate == tier {
+			return i
+		}
+	}
+
+	return len(relationshipGraphTiers) - 1
+}
+
+// relationshipErrorMessage maps relationship errors to a flash message
+func relationshipErrorMessage(err error, fallback string) string {
+	switch {
+	case errors.Is(err, db.ErrRelationshipTypeInvalid):
+		return "Please choose a relationship type"
+	case errors.Is(err, db.ErrRelationshipSelf):
+		return "A contact cannot be related to itself"
+	case errors.Is(err, db.ErrRelationshipExists):
+		return "That relationship already exists"
+	case errors.Is(err, db.ErrRelationshipNotFound):
+		return "Relationship not found"
+	default:
+		return fallback
+	}
+}
+
+// AddRelationship handles relating another contact to this one
+func AddRelationship(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	if contactID == "" {
+		c.Redirect("/", http.StatusSeeOther)
+		return
+	}
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+
+	relatedID := form.Get("related_contact_id")
+	if relatedID == "" {
+		SetErrorFlash(s, "Please choose a contact")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	input := db.AddRelationshipInput{
+		ContactID:        contactID,
+		RelatedContactID: relatedID,
+		Type:             db.RelationshipType(form.Get("relationship_type")),
+	}
+
+	if err := addRelationshipDBFn(c.Request().Context(), input); err != nil {
+		logger.Error("Error adding relationship", "error", err)
+		SetErrorFlash(s, relationshipErrorMessage(err, "Failed to add relationship"))
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// DeleteRelationship handles removing a relationship from a contact
+func DeleteRelationship(c flamego.Context, s session.Session) {
+	contactID := c.Param("id")
+	relationshipID := c.Param("relationship_id")
+
+	if contactID == "" || relationshipID == "" {
+		c.Redirect("/", http.StatusSeeOther)
+		return
+	}
+
+	if err := deleteRelationshipDBFn(c.Request().Context(), relationshipID, contactID); err != nil {
+		logger.Error("Error deleting relationship", "error", err)
+		SetErrorFlash(s, relationshipErrorMessage(err, "Failed to delete relationship"))
+	}
+
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// RelationshipGraph renders the network of contact relationships. A contact
+// query parameter narrows the graph to that contact's neighbourhood.
+func RelationshipGraph(c flamego.Context, t template.Template, data template.Data) {
+	graph, err := getRelationshipGraphDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error fetching relationship graph", "error", err)
+
+		data["Error"] = "Failed to load relationships"
+		graph = &db.RelationshipGraph{}
+	}
+
+	focusID := c.Query("contact")
+	if focusID != "" {
+		graph = graph.Neighborhood(focusID, relationshipGraphDepth)
+
+		for _, node := range graph.Nodes {
+			if node.ID == focusID {
+				data["FocusContact"] = node
+			}
+		}
+	}
+
+	if len(graph.Nodes) > 0 {
+		chart, err := renderRelationshipGraph(graph, focusID)
+		if err != nil {
+			logger.Error("Error rendering relationship graph", "error", err)
+
+			data["Error"] = "Failed to render relationship graph"
+		} else {
+			data["GraphChart"] = htmltemplate.HTML(chart) //nolint:gosec // Chart markup is generated server-side.
+		}
+	}
+
+	data["NodeCount"] = len(graph.Nodes)
+	data["EdgeCount"] = len(graph.Edges)
+	data["IsContacts"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "Relationships", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "contact_graph")
+}
+
+// buildRelationshipGraphData converts the graph into chart nodes and links.
+// Links reference nodes by index since display names are not unique.
+func buildRelationshipGraphData(graph *db.RelationshipGraph, focusID string) ([]opts.GraphNode, []opts.GraphLink, []string) {
+	index := make(map[string]int, len(graph.Nodes))
+	degree := make(map[string]int, len(graph.Nodes))
+
+	for _, edge := range graph.Edges {
+		degree[edge.From]++
+		degree[edge.To]++
+	}
+
+	nodes := make([]opts.GraphNode, 0, len(graph.Nodes))
+	ids := make([]string, 0, len(graph.Nodes))
+
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+		ids = append(ids, node.ID)
+
+		size := 12 + 4*min(degree[node.ID], 6)
+		if node.ID == focusID {
+			size += 8
+		}
+
+		nodes = append(nodes, opts.GraphNode{
+			Name:       node.NameDisplay,
+			Value:      float32(degree[node.ID]),
+			Category:   relationshipGraphCategory(node.Tier),
+			SymbolSize: size,
+		})
+	}
+
+	links := make([]opts.GraphLink, 0, len(graph.Edges))
+
+	for _, edge := range graph.Edges {
+		from, fromOK := index[edge.From]
+		to, toOK := index[edge.To]
+
+		if !fromOK || !toOK {
+			continue
+		}
+
+		links = append(links, opts.GraphLink{
+			Source: from,
+			Target: to,
+			Label: &opts.EdgeLabel{
+				Show:      opts.Bool(true),
+				Formatter: edge.Type.Label(),
+			},
+		})
+	}
+
+	return nodes, links, ids
+}
+
+func renderRelationshipGraph(graph *db.RelationshipGraph, focusID string) (string, error) {
+	nodes, links, ids := buildRelationshipGraphData(graph, focusID)
+
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode relationship graph ids: %w", err)
+	}
+
+	categories := make([]*opts.GraphCategory, 0, len(relationshipGraphTiers))
+	categoryNames := make([]string, 0, len(relationshipGraphTiers))
+
+	for _, tier := range relationshipGraphTiers {
+		categories = append(categories, &opts.GraphCategory{Name: "Tier " + string(tier)})
+		categoryNames = append(categoryNames, "Tier "+string(tier))
+	}
+
+	// Clicking a contact opens its page
+	clickHandler := fmt.Sprintf(`function (params) {
+		var ids = %s;
+		if (params.dataType === 'node' && ids[params.dataIndex]) {
+			window.location.href = '/contact/' + ids[params.dataIndex];
+		}
+	}`, idsJSON)
+
+	chart := charts.NewGraph()
+	chart.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:   "100%",
+			Height:  "640px",
+			ChartID: "contact_relationship_graph",
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show: opts.Bool(true),
+		}),
+		charts.WithLegendOpts(opts.Legend{
+			Show: opts.Bool(true),
+			Data: categoryNames,
+		}),
+		charts.WithEventListeners(event.Listener{
+			EventName: "click",
+			Handler:   opts.FuncOpts(clickHandler),
+		}),
+	)
+
+	chart.AddSeries("Relationships", nodes, links,
+		charts.WithGraphChartOpts(opts.GraphChart{
+			Layout:             "force",
+			Roam:               opts.Bool(true),
+			Draggable:          opts.Bool(true),
+			FocusNodeAdjacency: opts.Bool(true),
+			Categories:         categories,
+			Force: &opts.GraphForce{
+				Repulsion:  180,
+				EdgeLength: 90,
+			},
+		}),
+		charts.WithLabelOpts(opts.Label{
+			Show:     opts.Bool(true),
+			Position: "right",
+		}),
+	)
+
+	var buf bytes.Buffer
+	if err := chart.Render(&buf); err != nil {
+		return "", fmt.Errorf("failed to render relationship graph: %w", err)
+	}
+
+	return buf.String(), nil
+}
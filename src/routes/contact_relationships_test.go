@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newContactRelationshipsTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/contact/{id}/relationship", AddRelationship)
+	f.Post("/contact/{id}/relationship/{relationship_id}/delete", DeleteRelationship)
+
+	return f
+}
+
+func TestAddRelationshipPassesInput(t *testing.T) {
+	var got db.AddRelationshipInput
+
+	originalAddRelationshipDBFn := addRelationshipDBFn
+	addRelationshipDBFn = func(_ context.Context, input db.AddRelationshipInput) error {
+		got = input
+		return nil
+	}
+
+	t.Cleanup(func() {
+		addRelationshipDBFn = originalAddRelationshipDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactRelationshipsTestApp(s), "/contact/c1/relationship", url.Values{
+		"related_contact_id": {"c2"},
+		"relationship_type":  {"manager"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertNoFlash(t, s)
+
+	if got.ContactID != "c1" || got.RelatedContactID != "c2" || got.Type != db.RelationshipManager {
+		t.Fatalf("unexpected input %+v", got)
+	}
+}
+
+func TestAddRelationshipRequiresContact(t *testing.T) {
+	originalAddRelationshipDBFn := addRelationshipDBFn
+	addRelationshipDBFn = func(context.Context, db.AddRelationshipInput) error {
+		return errTestShouldNotBeCalled
+	}
+
+	t.Cleanup(func() {
+		addRelationshipDBFn = originalAddRelationshipDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactRelationshipsTestApp(s), "/contact/c1/relationship", url.Values{
+		"relationship_type": {"friend"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Please choose a contact")
+}
+
+func TestAddRelationshipErrorSetsFlash(t *testing.T) {
+	originalAddRelationshipDBFn := addRelationshipDBFn
+	addRelationshipDBFn = func(context.Context, db.AddRelationshipInput) error {
+		return db.ErrRelationshipExists
+	}
+
+	t.Cleanup(func() {
+		addRelationshipDBFn = originalAddRelationshipDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactRelationshipsTestApp(s), "/contact/c1/relationship", url.Values{
+		"related_contact_id": {"c2"},
+		"relationship_type":  {"friend"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "That relationship already exists")
+}
+
+func TestDeleteRelationshipErrorSetsFlash(t *testing.T) {
+	originalDeleteRelationshipDBFn := deleteRelationshipDBFn
+	deleteRelationshipDBFn = func(context.Context, string, string) error {
+		return errTestBoom
+	}
+
+	t.Cleanup(func() {
+		deleteRelationshipDBFn = originalDeleteRelationshipDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactRelationshipsTestApp(s), "/contact/c1/relationship/r1/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashError, "Failed to delete relationship")
+}
+
+func TestBuildRelationshipGraphData(t *testing.T) {
+	graph := &db.RelationshipGraph{
+		Nodes: []db.RelationshipGraphNode{
+			{ID: "a", NameDisplay: "Sam", Tier: db.TierA},
+			{ID: "b", NameDisplay: "Sam", Tier: db.TierC},
+		},
+		Edges: []db.RelationshipGraphEdge{
+			{From: "a", To: "b", Type: db.RelationshipSibling},
+			{From: "a", To: "missing", Type: db.RelationshipFriend},
+		},
+	}
+
+	nodes, links, ids := buildRelationshipGraphData(graph, "a")
+	if len(nodes) != 2 || len(ids) != 2 || ids[1] != "b" {
+		t.Fatalf("unexpected nodes %+v ids %v", nodes, ids)
+	}
+
+	if nodes[0].Category != 0 || nodes[1].Category != 2 {
+		t.Fatalf("expected tier categories, got %v and %v", nodes[0].Category, nodes[1].Category)
+	}
+
+	if len(links) != 1 || links[0].Source != 0 || links[0].Target != 1 || links[0].Label.Formatter != "Sibling" {
+		t.Fatalf("unexpected links %+v", links)
+	}
+
+	chart, err := renderRelationshipGraph(graph, "")
+	if err != nil {
+		t.Fatalf("renderRelationshipGraph failed: %v", err)
+	}
+
+	if !strings.Contains(chart, `"/contact/"`) && !strings.Contains(chart, `'/contact/'`) {
+		t.Fatal("expected click handler linking to contact pages")
+	}
+}
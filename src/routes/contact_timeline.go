@@ -0,0 +1,132 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var listContactTimelineDBFn = db.ListContactTimeline
+
+// parseContactTimelinePage reads a 1-based page number, defaulting to 1
+func parseContactTimelinePage(value string) int {
+	page, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || page < 1 {
+		return 1
+	}
+
+	return page
+}
+
+// contactTimelinePageURL links to a page of a contact's timeline, keeping
+// the kind filter
+func contactTimelinePageURL(contactID string, kind db.ContactTimelineKind, page int) string {
+	values := url.Values{}
+	if kind != "" {
+		values.Set("kind", string(kind))
+	}
+
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+
+	pageURL := "/contact/" + contactID + "/timeline"
+	if encoded := values.Encode(); encoded != "" {
+		pageURL += "?" + encoded
+	}
+
+	return pageURL
+}
+
+// ViewContactTimeline shows every channel of contact with a person in one
+// paginated feed, optionally narrowed to a single kind
+func ViewContactTimeline(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	contactID := c.Param("id")
+	if contactID == "" {
+		SetErrorFlash(s, "Contact ID is required")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := c.Request().Context()
+
+	contact, err := getContactDBFn(ctx, contactID)
+	if err != nil {
+		logger.Error("Error fetching contact", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Contact not found")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	if contact.IsService {
+		SetErrorFlash(s, "Timeline is not available for service contacts")
+		c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+
+		return
+	}
+
+	data["PageRequiresSensitiveAccess"] = true
+
+	if !HasSensitiveAccess(s, time.Now()) {
+		redirectToBreakGlass(c, s)
+		return
+	}
+
+	var kind db.ContactTimelineKind
+	if value := strings.TrimSpace(c.Query("kind")); db.IsValidContactTimelineKind(value) {
+		kind = db.ContactTimelineKind(value)
+	}
+
+	opts := db.ContactTimelineOptions{
+		ContactID: contactID,
+		Page:      parseContactTimelinePage(c.Query("page")),
+	}
+
+	if kind != "" {
+		opts.Kinds = []db.ContactTimelineKind{kind}
+	}
+
+	timeline, err := listContactTimelineDBFn(ctx, opts)
+	if err != nil {
+		logger.Error("Error fetching timeline for contact", "contact_id", contactID, "error", err)
+
+		data["Error"] = "Failed to load timeline"
+	} else {
+		data["Timeline"] = timeline
+
+		if timeline.HasPrevious {
+			data["PreviousPageURL"] = contactTimelinePageURL(contactID, kind, timeline.Page-1)
+		}
+
+		if timeline.HasNext {
+			data["NextPageURL"] = contactTimelinePageURL(contactID, kind, timeline.Page+1)
+		}
+	}
+
+	data["Contact"] = contact
+	data["ContactName"] = contact.NameDisplay
+	data["TimelineKinds"] = db.ContactTimelineKinds
+	data["TimelineKind"] = string(kind)
+	data["IsContacts"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: contact.NameDisplay, URL: "/contact/" + contactID, IsCurrent: false},
+		{Name: "Timeline", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "contact_timeline")
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func TestParseContactTimelinePage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"":    1,
+		"0":   1,
+		"-2":  1,
+		"abc": 1,
+		" 3 ": 3,
+	}
+
+	for value, want := range tests {
+		if got := parseContactTimelinePage(value); got != want {
+			t.Fatalf("parseContactTimelinePage(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestContactTimelinePageURL(t *testing.T) {
+	t.Parallel()
+
+	if got := contactTimelinePageURL("c1", "", 1); got != "/contact/c1/timeline" {
+		t.Fatalf("unexpected first page URL %q", got)
+	}
+
+	if got := contactTimelinePageURL("c1", db.TimelineKindChat, 2); got != "/contact/c1/timeline?kind=chat&page=2" {
+		t.Fatalf("unexpected filtered page URL %q", got)
+	}
+}
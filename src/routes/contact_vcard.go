@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	exportContactsVCardDBFn = db.ExportContactsVCard
+	importContactsVCardDBFn = db.ImportContactsVCard
+)
+
+// ExportContactsVCard downloads contacts as a vCard file. A tag ID or a
+// contacts search query narrows the export; otherwise every contact is included.
+func ExportContactsVCard(c flamego.Context, s session.Session) {
+	tagID := strings.TrimSpace(c.Query("tag"))
+	query := strings.TrimSpace(c.Query("q"))
+
+	opts := db.VCardExportOptions{SearchQuery: query}
+	returnURL := contactsSearchURL(query)
+
+	if tagID != "" {
+		opts.TagIDs = []string{tagID}
+		returnURL = "/tags/" + tagID
+	}
+
+	vCardBytes, err := exportContactsVCardDBFn(c.Request().Context(), opts)
+	if err != nil {
+		logger.Error("Error exporting contacts vcf", "error", err)
+		SetErrorFlash(s, "Failed to export contacts")
+		c.Redirect(returnURL, http.StatusSeeOther)
+
+		return
+	}
+
+	headers := c.ResponseWriter().Header()
+	headers.Set("Content-Type", "text/vcard; charset=utf-8")
+	headers.Set("Content-Disposition", "attachment; filename=\"contacts.vcf\"")
+	headers.Set("Content-Length", strconv.Itoa(len(vCardBytes)))
+	headers.Set("X-Content-Type-Options", "nosniff")
+
+	c.ResponseWriter().WriteHeader(http.StatusOK)
+
+	if _, err := c.ResponseWriter().Write(vCardBytes); err != nil {
+		logger.Error("Error writing contacts vcf response", "error", err)
+	}
+}
+
+// ImportContactsVCard creates or updates contacts from an uploaded vCard file
+func ImportContactsVCard(c flamego.Context, s session.Session) {
+	// Parse multipart form (max 10MB)
+	if err := c.Request().ParseMultipartForm(10 << 20); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse upload form")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	file, header, err := c.Request().FormFile("vcard_file")
+	if err != nil {
+		logger.Error("Error getting file", "error", err)
+		SetErrorFlash(s, "No file uploaded or invalid file")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.Error("Error closing vCard upload file", "error", err)
+		}
+	}()
+
+	logger.Info("Importing vCard file", "filename", header.Filename, "bytes", header.Size)
+
+	result, err := importContactsVCardDBFn(c.Request().Context(), file)
+	if err != nil {
+		logger.Error("Error importing vCard file", "error", err)
+		SetErrorFlash(s, "Failed to import contacts: "+err.Error())
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	message := fmt.Sprintf("Imported contacts: %d created, %d updated", result.Created, result.Updated)
+	if result.Skipped > 0 {
+		message += fmt.Sprintf(" (%d without a name skipped)", result.Skipped)
+	}
+
+	SetSuccessFlash(s, message)
+	c.Redirect("/contacts", http.StatusSeeOther)
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newContactVCardTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Get("/contacts/export.vcf", ExportContactsVCard)
+	f.Post("/contacts/import", ImportContactsVCard)
+
+	return f
+}
+
+func performVCardUpload(t *testing.T, f *flamego.Flame, content string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("vcard_file", "contacts.vcf")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+
+	if _, err := io.WriteString(part, content); err != nil {
+		t.Fatalf("failed to write form file: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/contacts/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestExportContactsVCardByQuery(t *testing.T) {
+	var gotOpts db.VCardExportOptions
+
+	originalExportContactsVCardDBFn := exportContactsVCardDBFn
+	exportContactsVCardDBFn = func(_ context.Context, opts db.VCardExportOptions) ([]byte, error) {
+		gotOpts = opts
+		return []byte("BEGIN:VCARD\r\nEND:VCARD\r\n"), nil
+	}
+
+	t.Cleanup(func() {
+		exportContactsVCardDBFn = originalExportContactsVCardDBFn
+	})
+
+	s := newTestSession()
+	req := httptest.NewRequest(http.MethodGet, "/contacts/export.vcf?q="+url.QueryEscape("tier:a"), nil)
+	rec := httptest.NewRecorder()
+	newContactVCardTestApp(s).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "text/vcard; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	if got := rec.Header().Get("Content-Disposition"); got != "attachment; filename=\"contacts.vcf\"" {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+
+	if rec.Body.String() != "BEGIN:VCARD\r\nEND:VCARD\r\n" {
+		t.Fatalf("unexpected body %q", rec.Body.String())
+	}
+
+	if gotOpts.SearchQuery != "tier:a" || len(gotOpts.TagIDs) != 0 {
+		t.Fatalf("unexpected export options %#v", gotOpts)
+	}
+}
+
+func TestExportContactsVCardErrorRedirectsToTag(t *testing.T) {
+	var gotOpts db.VCardExportOptions
+
+	originalExportContactsVCardDBFn := exportContactsVCardDBFn
+	exportContactsVCardDBFn = func(_ context.Context, opts db.VCardExportOptions) ([]byte, error) {
+		gotOpts = opts
+		return nil, db.ErrDatabaseConnectionNotInitialized
+	}
+
+	t.Cleanup(func() {
+		exportContactsVCardDBFn = originalExportContactsVCardDBFn
+	})
+
+	s := newTestSession()
+	req := httptest.NewRequest(http.MethodGet, "/contacts/export.vcf?tag=t1", nil)
+	rec := httptest.NewRecorder()
+	newContactVCardTestApp(s).ServeHTTP(rec, req)
+
+	assertRedirect(t, rec, "/tags/t1")
+	assertFlash(t, s, FlashError, "Failed to export contacts")
+
+	if len(gotOpts.TagIDs) != 1 || gotOpts.TagIDs[0] != "t1" {
+		t.Fatalf("unexpected export options %#v", gotOpts)
+	}
+}
+
+func TestImportContactsVCardReportsCounts(t *testing.T) {
+	var gotContent string
+
+	originalImportContactsVCardDBFn := importContactsVCardDBFn
+	importContactsVCardDBFn = func(_ context.Context, r io.Reader) (*db.VCardImportResult, error) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		gotContent = string(content)
+
+		return &db.VCardImportResult{Created: 2, Updated: 1, Skipped: 1}, nil
+	}
+
+	t.Cleanup(func() {
+		importContactsVCardDBFn = originalImportContactsVCardDBFn
+	})
+
+	s := newTestSession()
+	rec := performVCardUpload(t, newContactVCardTestApp(s), "BEGIN:VCARD\r\nEND:VCARD\r\n")
+
+	assertRedirect(t, rec, "/contacts")
+	assertFlash(t, s, FlashSuccess, "Imported contacts: 2 created, 1 updated (1 without a name skipped)")
+
+	if gotContent != "BEGIN:VCARD\r\nEND:VCARD\r\n" {
+		t.Fatalf("unexpected uploaded content %q", gotContent)
+	}
+}
+
+func TestImportContactsVCardWithoutFile(t *testing.T) {
+	originalImportContactsVCardDBFn := importContactsVCardDBFn
+	importContactsVCardDBFn = func(context.Context, io.Reader) (*db.VCardImportResult, error) {
+		return nil, errTestShouldNotBeCalled
+	}
+
+	t.Cleanup(func() {
+		importContactsVCardDBFn = originalImportContactsVCardDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newContactVCardTestApp(s), "/contacts/import", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contacts")
+	assertFlash(t, s, FlashError, "Failed to parse upload form")
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+const emailTrackingURL = "/email"
+
+var (
+	emailPollConfigFn         = db.EmailPollConfigFromEnv
+	pollEmailFn               = db.PollEmail
+	listEmailFolderStatesDBFn = db.ListEmailFolderStates
+	listEmailIgnoreRulesDBFn  = db.ListEmailIgnoreRules
+	addEmailIgnoreRuleDBFn    = db.AddEmailIgnoreRule
+	deleteEmailIgnoreRuleDBFn = db.DeleteEmailIgnoreRule
+)
+
+// EmailTracking shows the IMAP polling state and the ignore rules
+func EmailTracking(c flamego.Context, t template.Template, data template.Data) {
+	ctx := c.Request().Context()
+
+	config, err := emailPollConfigFn()
+	switch {
+	case errors.Is(err, db.ErrEmailPollNotConfigured):
+	case err != nil:
+		data["ConfigError"] = err.Error()
+	default:
+		data["EmailConfig"] = config
+	}
+
+	folders, err := listEmailFolderStatesDBFn(ctx)
+	if err != nil {
+		logger.Error("Error listing email folders", "error", err)
+
+		data["Error"] = "Failed to load polled folders"
+	}
+
+	rules, err := listEmailIgnoreRulesDBFn(ctx)
+	if err != nil {
+		logger.Error("Error listing email ignore rules", "error", err)
+
+		data["Error"] = "Failed to load ignore rules"
+	}
+
+	data["Folders"] = folders
+	data["IgnoreRules"] = rules
+	data["IsContacts"] = true
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Contacts", URL: "/contacts", IsCurrent: false},
+		{Name: "Email Tracking", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "email_tracking")
+}
+
+// PollEmailNow polls the IMAP account outside the schedule
+func PollEmailNow(c flamego.Context, s session.Session) {
+	if _, err := emailPollConfigFn(); err != nil {
+		SetErrorFlash(s, "Email polling is not configured")
+		c.Redirect(emailTrackingURL, http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	// Poll asynchronously; the folders update when done
+	go func() {
+		result, err := pollEmailFn(ctx)
+
+		switch {
+		case errors.Is(err, db.ErrEmailPollInProgress):
+			logger.Info("Manual email poll skipped, a poll is already running")
+		case err != nil:
+			logger.Error("Manual email poll failed", "error", err)
+		default:
+			logger.Info("Manual email poll completed", "summary", result.Summary(), "errors", result.Errors)
+		}
+	}()
+
+	SetInfoFlash(s, "Email poll started in background")
+	c.Redirect(emailTrackingURL, http.StatusSeeOther)
+}
+
+// AddEmailIgnoreRule keeps a folder or address out of email polling
+func AddEmailIgnoreRule(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(emailTrackingURL, http.StatusSeeOther)
+
+		return
+	}
+
+	ruleType := db.EmailIgnoreRuleType(c.Request().Form.Get("rule_type"))
+
+	err := addEmailIgnoreRuleDBFn(c.Request().Context(), ruleType, c.Request().Form.Get("pattern"))
+
+	switch {
+	case errors.Is(err, db.ErrEmailIgnoreRuleInvalid):
+		SetErrorFlash(s, "Enter a folder name, or an address, @domain or local part@")
+	case errors.Is(err, db.ErrEmailIgnoreRuleExists):
+		SetErrorFlash(s, "That rule already exists")
+	case err != nil:
+		logger.Error("Error adding email ignore rule", "error", err)
+		SetErrorFlash(s, "Failed to add ignore rule")
+	default:
+		SetSuccessFlash(s, "Ignore rule added")
+	}
+
+	c.Redirect(emailTrackingURL, http.StatusSeeOther)
+}
+
+// DeleteEmailIgnoreRule removes an ignore rule
+func DeleteEmailIgnoreRule(c flamego.Context, s session.Session) {
+	err := deleteEmailIgnoreRuleDBFn(c.Request().Context(), c.Param("id"))
+
+	switch {
+	case errors.Is(err, db.ErrEmailIgnoreRuleNotFound):
+		SetErrorFlash(s, "Ignore rule not found")
+	case err != nil:
+		logger.Error("Error deleting email ignore rule", "rule_id", c.Param("id"), "error", err)
+		SetErrorFlash(s, "Failed to remove ignore rule")
+	default:
+		SetSuccessFlash(s, "Ignore rule removed")
+	}
+
+	c.Redirect(emailTrackingURL, http.StatusSeeOther)
+}
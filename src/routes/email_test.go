@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newEmailTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/email/poll", PollEmailNow)
+	f.Post("/email/ignore-rules", AddEmailIgnoreRule)
+	f.Post("/email/ignore-rules/{id}/delete", DeleteEmailIgnoreRule)
+
+	return f
+}
+
+// stubEmailPoll replaces the IMAP configuration and poll, returning a
+// channel signalled by every poll started
+func stubEmailPoll(t *testing.T, configErr error) chan struct{} {
+	t.Helper()
+
+	started := make(chan struct{}, 1)
+
+	originalEmailPollConfigFn := emailPollConfigFn
+	originalPollEmailFn := pollEmailFn
+
+	emailPollConfigFn = func() (*db.EmailPollConfig, error) {
+		if configErr != nil {
+			return nil, configErr
+		}
+
+		return &db.EmailPollConfig{Addr: "mail.example.com:993", TLS: true, Username: "me@example.com"}, nil
+	}
+	pollEmailFn = func(context.Context) (db.EmailPollResult, error) {
+		started <- struct{}{}
+
+		return db.EmailPollResult{Folders: 1}, nil
+	}
+
+	t.Cleanup(func() {
+		emailPollConfigFn = originalEmailPollConfigFn
+		pollEmailFn = originalPollEmailFn
+	})
+
+	return started
+}
+
+func TestPollEmailNowRequiresConfiguration(t *testing.T) {
+	started := stubEmailPoll(t, db.ErrEmailPollNotConfigured)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newEmailTestApp(s), "/email/poll", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/email")
+	assertFlash(t, s, FlashError, "Email polling is not configured")
+
+	if len(started) != 0 {
+		t.Fatal("expected no poll without configuration")
+	}
+}
+
+func TestPollEmailNowStartsPoll(t *testing.T) {
+	started := stubEmailPoll(t, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newEmailTestApp(s), "/email/poll", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/email")
+	assertFlash(t, s, FlashInfo, "Email poll started in background")
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected a poll to start")
+	}
+}
+
+func TestAddEmailIgnoreRule(t *testing.T) {
+	var (
+		gotType    db.EmailIgnoreRuleType
+		gotPattern string
+	)
+
+	originalAddEmailIgnoreRuleDBFn := addEmailIgnoreRuleDBFn
+	addEmailIgnoreRuleDBFn = func(_ context.Context, ruleType db.EmailIgnoreRuleType, pattern string) error {
+		gotType = ruleType
+		gotPattern = pattern
+
+		if pattern == "taken@" {
+			return db.ErrEmailIgnoreRuleExists
+		}
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		addEmailIgnoreRuleDBFn = originalAddEmailIgnoreRuleDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newEmailTestApp(s), "/email/ignore-rules", url.Values{
+		"rule_type": {"address"},
+		"pattern":   {"noreply@"},
+	}, nil)
+
+	assertRedirect(t, rec, "/email")
+	assertFlash(t, s, FlashSuccess, "Ignore rule added")
+
+	if gotType != db.EmailIgnoreAddress || gotPattern != "noreply@" {
+		t.Fatalf("unexpected rule %q %q", gotType, gotPattern)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newEmailTestApp(s), "/email/ignore-rules", url.Values{
+		"rule_type": {"address"},
+		"pattern":   {"taken@"},
+	}, nil)
+
+	assertRedirect(t, rec, "/email")
+	assertFlash(t, s, FlashError, "That rule already exists")
+}
+
+func TestDeleteEmailIgnoreRuleNotFound(t *testing.T) {
+	originalDeleteEmailIgnoreRuleDBFn := deleteEmailIgnoreRuleDBFn
+	deleteEmailIgnoreRuleDBFn = func(_ context.Context, id string) error {
+		if id != "r1" {
+			return errTestShouldNotBeCalled
+		}
+
+		return db.ErrEmailIgnoreRuleNotFound
+	}
+
+	t.Cleanup(func() {
+		deleteEmailIgnoreRuleDBFn = originalDeleteEmailIgnoreRuleDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newEmailTestApp(s), "/email/ignore-rules/r1/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/email")
+	assertFlash(t, s, FlashError, "Ignore rule not found")
+}
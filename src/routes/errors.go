@@ -22,4 +22,5 @@ var (
 	errDisplayNameMissing        = errors.New("display name missing")
 	errRegistrationUserMissing   = errors.New("registration user missing")
 	errInvalidADIFExportDate     = errors.New("invalid ADIF export date")
+	errWhatsAppUnavailable       = errors.New("whatsapp client is not available")
 )
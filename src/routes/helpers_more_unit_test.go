@@ -246,6 +246,8 @@ func TestParseChatPlatformAllSupportedValues(t *testing.T) {
 		"email":    db.ChatPlatformEmail,
 		"whatsapp": db.ChatPlatformWhatsApp,
 		"signal":   db.ChatPlatformSignal,
+		"telegram": db.ChatPlatformTelegram,
+		"matrix":   db.ChatPlatformMatrix,
 		"wechat":   db.ChatPlatformWeChat,
 		"teams":    db.ChatPlatformTeams,
 		"slack":    db.ChatPlatformSlack,
@@ -20,6 +20,9 @@ import (
 	"github.com/humaidq/groundwave/whatsapp"
 )
 
+// upcomingDatesWindowDays is how far ahead the dashboard lists birthdays and anniversaries
+const upcomingDatesWindowDays = 30
+
 // Welcome renders the welcome/dashboard page
 func Welcome(c flamego.Context, s session.Session, t template.Template, data template.Data) {
 	ctx := c.Request().Context()
@@ -54,6 +57,16 @@ func Welcome(c flamego.Context, s session.Session, t template.Template, data tem
 			data["OverdueCount"] = len(overdueContacts)
 		}
 
+		// Get upcoming birthdays and anniversaries
+		upcomingDates, err := db.ListUpcomingContactDates(ctx, time.Now(), upcomingDatesWindowDays)
+		if err != nil {
+			logger.Error("Error fetching upcoming contact dates", "error", err)
+		} else {
+			data["UpcomingDates"] = upcomingDates
+		}
+
+		data["CalendarFeedPath"] = calendarFeedPath()
+
 		// Get QSO count
 		qsoCount, err := db.GetQSOCount(ctx)
 		if err != nil {
@@ -148,6 +161,26 @@ func Home(c flamego.Context, s session.Session, t template.Template, data templa
 
 	data["SearchQuery"] = searchQuery
 
+	savedSearches, err := db.ListSavedSearches(ctx)
+	if err != nil {
+		logger.Error("Error fetching saved searches", "error", err)
+	} else {
+		data["SavedSearches"] = savedSearches
+	}
+
+	if configured, err := hasCardDAVSourcesFn(ctx); err != nil {
+		logger.Error("Error checking CardDAV sources", "error", err)
+	} else if configured {
+		data["CardDAVConfigured"] = true
+
+		syncRuns, err := db.ListCardDAVSyncRuns(ctx, cardDAVSyncHistorySize)
+		if err != nil {
+			logger.Error("Error fetching CardDAV sync runs", "error", err)
+		} else {
+			data["CardDAVSyncRuns"] = syncRuns
+		}
+	}
+
 	// Get overdue contacts count for the button
 	overdueContacts, err := db.GetOverdueContacts(ctx)
 	if err != nil {
@@ -180,6 +213,15 @@ func Overdue(c flamego.Context, s session.Session, t template.Template, data tem
 		data["OverdueContacts"] = contacts
 	}
 
+	if sensitiveAccess {
+		cadences, err := db.ListTierCadences(c.Request().Context())
+		if err != nil {
+			logger.Error("Error fetching tier cadences", "error", err)
+		} else {
+			data["TierCadences"] = cadences
+		}
+	}
+
 	data["IsOverdue"] = true
 	data["IsContacts"] = true
 	data["Breadcrumbs"] = []BreadcrumbItem{
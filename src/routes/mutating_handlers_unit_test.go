@@ -51,6 +51,9 @@ func newMutatingHandlersTestApp(s session.Session) *flamego.Flame {
 	f.Post("/rebuild-cache", func(c flamego.Context, sess session.Session) {
 		RebuildCache(c, sess)
 	})
+	f.Post("/overdue/cadences", func(c flamego.Context, sess session.Session) {
+		UpdateTierCadences(c, sess)
+	})
 
 	return f
 }
@@ -841,22 +844,19 @@ func TestUpdateZettelCommentSuccessInboxRedirect(t *testing.T) {
 	assertFlash(t, s, FlashSuccess, "Comment updated successfully")
 }
 
-func TestRebuildCacheStartsBackgroundRebuildWithDetachedContext(t *testing.T) {
-	releaseRebuild := make(chan struct{})
-	rebuildResult := make(chan error, 1)
-
+func TestRebuildCacheReportsChangesWithDetachedContext(t *testing.T) {
 	originalRebuildZettelkastenCachesFn := rebuildZettelkastenCachesFn
-	rebuildZettelkastenCachesFn = func(ctx context.Context) error {
-		<-releaseRebuild
-
-		select {
-		case <-ctx.Done():
-			rebuildResult <- ctx.Err()
-		default:
-			rebuildResult <- nil
+	rebuildZettelkastenCachesFn = func(ctx context.Context) (*db.ZKCacheRebuildReport, error) {
+		if ctx.Done() != nil {
+			return nil, errTestShouldNotBeCalled
 		}
 
-		return nil
+		return &db.ZKCacheRebuildReport{
+			Added:     []string{"new.org"},
+			Changed:   []string{"a.org", "b.org", "c.org", "d.org"},
+			Failed:    []string{"daily/2024-01-01.org"},
+			Unchanged: 10,
+		}, nil
 	}
 
 	t.Cleanup(func() {
@@ -874,16 +874,191 @@ func TestRebuildCacheStartsBackgroundRebuildWithDetachedContext(t *testing.T) {
 	)
 
 	assertRedirect(t, rec, "/zk")
-	assertFlash(t, s, FlashInfo, "Cache rebuild started in background")
+	assertFlash(t, s, FlashInfo, "Cache rebuilt: 1 added (new.org), 4 changed (a.org, b.org, c.org and 1 more), 10 unchanged; 1 unreadable (daily/2024-01-01.org)")
+}
+
+func TestRebuildCacheReportsNoChanges(t *testing.T) {
+	originalRebuildZettelkastenCachesFn := rebuildZettelkastenCachesFn
+	rebuildZettelkastenCachesFn = func(context.Context) (*db.ZKCacheRebuildReport, error) {
+		return &db.ZKCacheRebuildReport{Unchanged: 12}, nil
+	}
+
+	t.Cleanup(func() {
+		rebuildZettelkastenCachesFn = originalRebuildZettelkastenCachesFn
+	})
+
+	s := newTestSession()
+	f := newMutatingHandlersTestApp(s)
+	rec := performFormPOST(t, f, "/rebuild-cache", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/zk")
+	assertFlash(t, s, FlashInfo, "Cache rebuilt, no files changed (12 checked)")
+
+	rebuildZettelkastenCachesFn = func(context.Context) (*db.ZKCacheRebuildReport, error) {
+		return nil, errTestShouldNotBeCalled
+	}
+
+	rec = performFormPOST(t, f, "/rebuild-cache", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/zk")
+	assertFlash(t, s, FlashError, "Cache rebuild failed")
+}
+
+func TestRebuildCacheLeavesSlowRebuildInBackground(t *testing.T) {
+	originalRebuildZettelkastenCachesFn := rebuildZettelkastenCachesFn
+	originalZKRebuildWait := zkRebuildWait
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	rebuildZettelkastenCachesFn = func(context.Context) (*db.ZKCacheRebuildReport, error) {
+		defer close(finished)
 
-	close(releaseRebuild)
+		<-release
+
+		return &db.ZKCacheRebuildReport{Unchanged: 1}, nil
+	}
+	zkRebuildWait = 10 * time.Millisecond
+
+	t.Cleanup(func() {
+		rebuildZettelkastenCachesFn = originalRebuildZettelkastenCachesFn
+		zkRebuildWait = originalZKRebuildWait
+	})
+
+	s := newTestSession()
+	f := newMutatingHandlersTestApp(s)
+	rec := performFormPOST(t, f, "/rebuild-cache", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/zk")
+	assertFlash(t, s, FlashInfo, "Cache rebuild is still running in the background")
+
+	// Clicking again while it runs does not queue another rebuild
+	rec = performFormPOST(t, f, "/rebuild-cache", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/zk")
+	assertFlash(t, s, FlashInfo, "Cache rebuild already running")
+
+	close(release)
+	<-finished
+
+	for zkRebuildRunning.Load() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestParseCadenceDays(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantNil bool
+		wantErr bool
+	}{
+		{input: "", wantNil: true},
+		{input: "   ", wantNil: true},
+		{input: "14", want: 14},
+		{input: " 30 ", want: 30},
+		{input: "0", wantErr: true},
+		{input: "-7", wantErr: true},
+		{input: "weekly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCadenceDays(tt.input)
+		if tt.wantErr {
+			if !errors.Is(err, db.ErrCadenceDaysInvalid) {
+				t.Fatalf("parseCadenceDays(%q) error = %v, want ErrCadenceDaysInvalid", tt.input, err)
+			}
+
+			continue
+		}
 
-	select {
-	case err := <-rebuildResult:
 		if err != nil {
-			t.Fatalf("expected detached rebuild context, got %v", err)
+			t.Fatalf("parseCadenceDays(%q) unexpected error: %v", tt.input, err)
+		}
+
+		if tt.wantNil {
+			if got != nil {
+				t.Fatalf("parseCadenceDays(%q) = %d, want nil", tt.input, *got)
+			}
+
+			continue
 		}
-	case <-time.After(1 * time.Second):
-		t.Fatal("timed out waiting for rebuild goroutine")
+
+		if got == nil || *got != tt.want {
+			t.Fatalf("parseCadenceDays(%q) = %v, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateTierCadencesRejectsInvalidInterval(t *testing.T) {
+	originalUpdateTierCadenceDBFn := updateTierCadenceDBFn
+	updateTierCadenceDBFn = func(context.Context, db.Tier, *int) error {
+		return errTestShouldNotBeCalled
+	}
+
+	t.Cleanup(func() {
+		updateTierCadenceDBFn = originalUpdateTierCadenceDBFn
+	})
+
+	s := newTestSession()
+	f := newMutatingHandlersTestApp(s)
+	rec := performFormPOST(
+		t,
+		f,
+		"/overdue/cadences",
+		url.Values{
+			"tier_A": {"21"},
+			"tier_B": {"soon"},
+		},
+		nil,
+	)
+
+	assertRedirect(t, rec, "/overdue")
+	assertFlash(t, s, FlashError, "Tier B interval must be a positive number of days")
+}
+
+func TestUpdateTierCadencesSuccess(t *testing.T) {
+	originalUpdateTierCadenceDBFn := updateTierCadenceDBFn
+	captured := make(map[db.Tier]*int)
+
+	updateTierCadenceDBFn = func(_ context.Context, tier db.Tier, days *int) error {
+		captured[tier] = days
+		return nil
+	}
+
+	t.Cleanup(func() {
+		updateTierCadenceDBFn = originalUpdateTierCadenceDBFn
+	})
+
+	s := newTestSession()
+	f := newMutatingHandlersTestApp(s)
+	rec := performFormPOST(
+		t,
+		f,
+		"/overdue/cadences",
+		url.Values{
+			"tier_A": {"14"},
+			"tier_C": {" 90 "},
+			"tier_F": {""},
+		},
+		nil,
+	)
+
+	assertRedirect(t, rec, "/overdue")
+	assertFlash(t, s, FlashSuccess, "Tier cadences updated")
+
+	if len(captured) != 6 {
+		t.Fatalf("expected all 6 tiers to be updated, got %d", len(captured))
+	}
+
+	if captured[db.TierA] == nil || *captured[db.TierA] != 14 {
+		t.Fatalf("unexpected tier A interval: %v", captured[db.TierA])
+	}
+
+	if captured[db.TierC] == nil || *captured[db.TierC] != 90 {
+		t.Fatalf("unexpected tier C interval: %v", captured[db.TierC])
+	}
+
+	if captured[db.TierB] != nil || captured[db.TierF] != nil {
+		t.Fatalf("expected empty tiers to clear their interval")
 	}
 }
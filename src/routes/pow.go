@@ -295,7 +295,7 @@ func proofOfWorkVerifiedTTL(s session.Session, now time.Time, risk proofOfWorkRe
 
 func isProofOfWorkExemptPath(request *http.Request) bool {
 	path := request.URL.Path
-	if path == "/pow" || path == "/pow/verify" || path == "/connectivity" || path == "/qrz" {
+	if path == "/pow" || path == "/pow/verify" || path == "/connectivity" || path == "/qrz" || path == "/calendar/dates.ics" {
 		return true
 	}
 
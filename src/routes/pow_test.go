@@ -50,6 +50,9 @@ func newProofOfWorkTestApp(s session.Session, config ProofOfWorkConfig) *flamego
 	f.Get("/qrz", func(c flamego.Context) {
 		c.ResponseWriter().WriteHeader(http.StatusNoContent)
 	})
+	f.Get("/calendar/dates.ics", func(c flamego.Context) {
+		c.ResponseWriter().WriteHeader(http.StatusNoContent)
+	})
 
 	return f
 }
@@ -293,6 +296,22 @@ func TestRequireProofOfWorkSkipsQRZPath(t *testing.T) {
 	}
 }
 
+func TestRequireProofOfWorkSkipsCalendarFeed(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSession()
+	f := newProofOfWorkTestApp(s, ProofOfWorkConfig{Difficulty: 8})
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/dates.ics?token=secret", nil)
+	rec := httptest.NewRecorder()
+
+	f.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+}
+
 func TestProofOfWorkVerifyUnlocksSession(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	saveSearchDBFn        = db.SaveSearch
+	deleteSavedSearchDBFn = db.DeleteSavedSearch
+)
+
+// contactsSearchURL links to the contacts list filtered by a query
+func contactsSearchURL(query string) string {
+	if query == "" {
+		return "/contacts"
+	}
+
+	return "/contacts?q=" + url.QueryEscape(query)
+}
+
+// SaveSearch stores the current contacts query under a name
+func SaveSearch(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	}
+
+	name := strings.TrimSpace(c.Request().Form.Get("name"))
+	query := strings.TrimSpace(c.Request().Form.Get("query"))
+
+	if err := saveSearchDBFn(c.Request().Context(), name, query); err != nil {
+		logger.Error("Error saving search", "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrSavedSearchNameRequired):
+			SetErrorFlash(s, "Please name the search")
+		case errors.Is(err, db.ErrSavedSearchQueryRequired):
+			SetErrorFlash(s, "Enter a search before saving it")
+		default:
+			SetErrorFlash(s, "Failed to save search")
+		}
+
+		c.Redirect(contactsSearchURL(query), http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Search saved")
+	c.Redirect(contactsSearchURL(query), http.StatusSeeOther)
+}
+
+// DeleteSavedSearch removes a saved search from the contacts sidebar
+func DeleteSavedSearch(c flamego.Context, s session.Session) {
+	if err := deleteSavedSearchDBFn(c.Request().Context(), c.Param("id")); err != nil {
+		logger.Error("Error deleting saved search", "error", err)
+
+		if errors.Is(err, db.ErrSavedSearchNotFound) {
+			SetErrorFlash(s, "Saved search not found")
+		} else {
+			SetErrorFlash(s, "Failed to delete saved search")
+		}
+	}
+
+	c.Redirect("/contacts", http.StatusSeeOther)
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newSavedSearchesTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/contacts/saved-searches", SaveSearch)
+	f.Post("/contacts/saved-searches/{id}/delete", DeleteSavedSearch)
+
+	return f
+}
+
+func TestSaveSearchRedirectsToQuery(t *testing.T) {
+	var gotName, gotQuery string
+
+	originalSaveSearchDBFn := saveSearchDBFn
+	saveSearchDBFn = func(_ context.Context, name, query string) error {
+		gotName, gotQuery = name, query
+		return nil
+	}
+
+	t.Cleanup(func() {
+		saveSearchDBFn = originalSaveSearchDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newSavedSearchesTestApp(s), "/contacts/saved-searches", url.Values{
+		"name":  {" Family "},
+		"query": {"tag:family OR tag:friends"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contacts?q=tag%3Afamily+OR+tag%3Afriends")
+	assertFlash(t, s, FlashSuccess, "Search saved")
+
+	if gotName != "Family" || gotQuery != "tag:family OR tag:friends" {
+		t.Fatalf("unexpected saved search %q %q", gotName, gotQuery)
+	}
+}
+
+func TestSaveSearchErrorSetsFlash(t *testing.T) {
+	originalSaveSearchDBFn := saveSearchDBFn
+	saveSearchDBFn = func(context.Context, string, string) error {
+		return db.ErrSavedSearchNameRequired
+	}
+
+	t.Cleanup(func() {
+		saveSearchDBFn = originalSaveSearchDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newSavedSearchesTestApp(s), "/contacts/saved-searches", url.Values{
+		"query": {"tag:family"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contacts?q=tag%3Afamily")
+	assertFlash(t, s, FlashError, "Please name the search")
+}
+
+func TestDeleteSavedSearchNotFound(t *testing.T) {
+	originalDeleteSavedSearchDBFn := deleteSavedSearchDBFn
+	deleteSavedSearchDBFn = func(_ context.Context, id string) error {
+		if id != "s1" {
+			return errTestShouldNotBeCalled
+		}
+
+		return db.ErrSavedSearchNotFound
+	}
+
+	t.Cleanup(func() {
+		deleteSavedSearchDBFn = originalDeleteSavedSearchDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newSavedSearchesTestApp(s), "/contacts/saved-searches/s1/delete", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/contacts")
+	assertFlash(t, s, FlashError, "Saved search not found")
+}
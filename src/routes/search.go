@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	htmltemplate "html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var searchAllDBFn = db.SearchAll
+
+// SearchResultView is a search result with its snippet ready for display
+type SearchResultView struct {
+	db.SearchResult
+	SnippetHTML htmltemplate.HTML
+}
+
+// highlightSearchSnippet escapes a snippet and marks the matched terms
+func highlightSearchSnippet(snippet string) htmltemplate.HTML {
+	escaped := htmltemplate.HTMLEscapeString(strings.Join(strings.Fields(snippet), " "))
+	escaped = strings.ReplaceAll(escaped, db.SearchHighlightStart, "<mark>")
+	escaped = strings.ReplaceAll(escaped, db.SearchHighlightStop, "</mark>")
+
+	return htmltemplate.HTML(escaped) //nolint:gosec // Snippet text is escaped above.
+}
+
+// Search renders ranked full-text results across every domain the user can
+// see. Sensitive domains only appear while the sensitive view is unlocked.
+func Search(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	ctx := c.Request().Context()
+
+	isAdmin, err := resolveSessionIsAdmin(ctx, s)
+	if err != nil {
+		logger.Error("Error resolving admin state", "error", err)
+
+		isAdmin = false
+	}
+
+	userID, _ := getSessionUserID(s)
+	query := strings.TrimSpace(c.Query("q"))
+	kind := db.SearchResultKind(c.Query("type"))
+
+	opts := db.SearchOptions{
+		Query:           query,
+		IsAdmin:         isAdmin,
+		SensitiveAccess: HasSensitiveAccess(s, time.Now()),
+		UserID:          userID,
+	}
+
+	data["Kinds"] = db.SearchableKinds(opts)
+
+	if kind != "" {
+		opts.Kinds = []db.SearchResultKind{kind}
+	}
+
+	if query != "" {
+		results, err := searchAllDBFn(ctx, opts)
+		if err != nil {
+			logger.Error("Error searching", "error", err)
+
+			data["Error"] = "Search failed"
+		} else {
+			views := make([]SearchResultView, 0, len(results))
+			for _, result := range results {
+				views = append(views, SearchResultView{
+					SearchResult: result,
+					SnippetHTML:  highlightSearchSnippet(result.Snippet),
+				})
+			}
+
+			data["Results"] = views
+		}
+	}
+
+	data["SearchQuery"] = query
+	data["SearchKind"] = string(kind)
+	data["UnlockURL"] = "/break-glass?next=" + url.QueryEscape(c.Request().URL.RequestURI())
+	data["IsSearch"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Search", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "search")
+}
@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"testing"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func TestHighlightSearchSnippetEscapesText(t *testing.T) {
+	t.Parallel()
+
+	snippet := "<b>bold</b> " + db.SearchHighlightStart + "antenna" + db.SearchHighlightStop + "\n  gain"
+
+	got := string(highlightSearchSnippet(snippet))
+	want := "&lt;b&gt;bold&lt;/b&gt; <mark>antenna</mark> gain"
+
+	if got != want {
+		t.Fatalf("highlightSearchSnippet() = %q, want %q", got, want)
+	}
+}
@@ -66,6 +66,13 @@ func EditTagForm(c flamego.Context, s session.Session, t template.Template, data
 		return
 	}
 
+	cadenceDays, err := db.GetTagCadence(c.Request().Context(), tagID)
+	if err != nil {
+		logger.Error("Error fetching tag cadence", "tag_id", tagID, "error", err)
+	} else {
+		data["TagCadenceDays"] = cadenceDays
+	}
+
 	data["Tag"] = tag
 	data["IsContacts"] = true
 	data["Breadcrumbs"] = []BreadcrumbItem{
@@ -100,7 +107,19 @@ func UpdateTag(c flamego.Context, s session.Session) {
 		return
 	}
 
-	err := db.RenameTag(c.Request().Context(), tagID, name, getOptionalString(form.Get("description")))
+	cadenceDays, err := parseCadenceDays(form.Get("cadence_days"))
+	if err != nil {
+		SetErrorFlash(s, "Follow-up interval must be a positive number of days")
+		c.Redirect("/tags/"+tagID+"/edit", http.StatusSeeOther)
+
+		return
+	}
+
+	err = db.RenameTag(c.Request().Context(), tagID, name, getOptionalString(form.Get("description")))
+	if err == nil {
+		err = db.UpdateTagCadence(c.Request().Context(), tagID, cadenceDays)
+	}
+
 	if err != nil {
 		logger.Error("Error updating tag", "error", err)
 		SetErrorFlash(s, "Failed to update tag")
@@ -5,25 +5,107 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flamego/flamego"
+	"github.com/flamego/session"
 	"github.com/flamego/template"
 
 	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
 )
 
-// Todo renders the todo org-mode page.
+var (
+	getTodoNoteDBFn  = db.GetTodoNote
+	setTodoStateDBFn = db.SetTodoState
+	addTodoTaskDBFn  = db.AddTodoTask
+)
+
+// todoAgendaView is the agenda along with what the task forms post back
+type todoAgendaView struct {
+	db.TodoAgenda
+	Keywords  utils.OrgTodoKeywords
+	ETag      string // Empty when the file cannot be written back safely
+	CSRFToken string
+}
+
+// todoTaskForm holds a task and the fields of its state buttons
+type todoTaskForm struct {
+	Task        utils.OrgTask
+	Editable    bool
+	NextKeyword string
+	DoneKeyword string // Empty when the task is already done
+	ETag        string
+	CSRFToken   string
+	Span        db.TodoAgendaSpan
+	Date        string
+	Tag         string
+}
+
+// TaskForm returns the state buttons of a task, for use in templates
+func (v todoAgendaView) TaskForm(task utils.OrgTask) todoTaskForm {
+	form := todoTaskForm{
+		Task:        task,
+		Editable:    v.ETag != "",
+		NextKeyword: v.Keywords.Next(task.Keyword),
+		ETag:        v.ETag,
+		CSRFToken:   v.CSRFToken,
+		Span:        v.Span,
+		Date:        v.Start.Format("2006-01-02"),
+		Tag:         v.Tag,
+	}
+
+	if !task.Done && len(v.Keywords.Done) > 0 {
+		form.DoneKeyword = v.Keywords.Done[0]
+	}
+
+	return form
+}
+
+// todoAgendaOptions reads the agenda span, day and tag filter from the
+// query, defaulting to the current week
+func todoAgendaOptions(c flamego.Context) db.TodoAgendaOptions {
+	opts := db.TodoAgendaOptions{
+		Span: db.TodoAgendaSpanWeek,
+		Tag:  strings.TrimSpace(c.Query("tag")),
+	}
+
+	if db.TodoAgendaSpan(c.Query("span")) == db.TodoAgendaSpanDay {
+		opts.Span = db.TodoAgendaSpanDay
+	}
+
+	if date, err := time.Parse("2006-01-02", strings.TrimSpace(c.Query("date"))); err == nil {
+		opts.Date = date
+	}
+
+	return opts
+}
+
+// Todo renders the agenda of the todo org-mode file along with the file itself.
 func Todo(c flamego.Context, t template.Template, data template.Data) {
 	ctx := c.Request().Context()
 
-	note, err := db.GetTodoNote(ctx)
+	note, err := getTodoNoteDBFn(ctx)
 	if err != nil {
 		logger.Error("Error fetching todo note", "error", err)
 
 		data["Error"] = "Failed to load todo list"
 	} else {
 		data["Note"] = note
+		csrfToken, _ := data["csrf_token"].(string)
+
+		data["Agenda"] = todoAgendaView{
+			TodoAgenda: db.BuildTodoAgenda(note.Tasks, time.Now(), todoAgendaOptions(c)),
+			Keywords:   note.Keywords,
+			ETag:       note.ETag,
+			CSRFToken:  csrfToken,
+		}
 	}
 
 	data["IsTodo"] = true
@@ -32,3 +114,161 @@ func Todo(c flamego.Context, t template.Template, data template.Data) {
 
 	t.HTML(http.StatusOK, "todo")
 }
+
+// todoAgendaURL links back to the agenda view a form was posted from
+func todoAgendaURL(form url.Values) string {
+	query := url.Values{}
+
+	if span := db.TodoAgendaSpan(form.Get("span")); span == db.TodoAgendaSpanDay || span == db.TodoAgendaSpanWeek {
+		query.Set("span", string(span))
+	}
+
+	if date := strings.TrimSpace(form.Get("date")); date != "" {
+		if _, err := time.Parse("2006-01-02", date); err == nil {
+			query.Set("date", date)
+		}
+	}
+
+	if tag := strings.TrimSpace(form.Get("tag")); tag != "" {
+		query.Set("tag", tag)
+	}
+
+	if len(query) == 0 {
+		return "/todo"
+	}
+
+	return "/todo?" + query.Encode()
+}
+
+// setTodoEditErrorFlash explains why the todo file was not written
+func setTodoEditErrorFlash(s session.Session, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrTodoConflict):
+		SetErrorFlash(s, "Todo file changed since the page loaded. Reload and try again")
+	case errors.Is(err, db.ErrTodoETagRequired):
+		SetErrorFlash(s, "Missing todo file version. Reload and try again")
+	case errors.Is(err, db.ErrTodoTaskNotFound):
+		SetErrorFlash(s, "Task not found in the todo file. Reload and try again")
+	case errors.Is(err, db.ErrTodoKeywordUnknown):
+		SetErrorFlash(s, "Unknown TODO keyword")
+	case errors.Is(err, db.ErrTodoTitleRequired):
+		SetErrorFlash(s, "Title is required")
+	default:
+		SetErrorFlash(s, fallback)
+	}
+}
+
+// UpdateTodoState sets or cycles the TODO keyword of a task and writes the
+// todo file back, refusing when it changed after the page was loaded
+func UpdateTodoState(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing todo state form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/todo", http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+	redirectURL := todoAgendaURL(form)
+
+	line, err := strconv.Atoi(form.Get("line"))
+	if err != nil || line < 0 {
+		SetErrorFlash(s, "Invalid task")
+		c.Redirect(redirectURL, http.StatusSeeOther)
+
+		return
+	}
+
+	change, err := setTodoStateDBFn(c.Request().Context(), line, form.Get("title"), strings.TrimSpace(form.Get("keyword")), form.Get("etag"), time.Now())
+	if err != nil {
+		logger.Error("Error updating todo state", "line", line, "error", err)
+		setTodoEditErrorFlash(s, err, "Failed to update task")
+		c.Redirect(redirectURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if change.RepeatedTo != nil {
+		SetSuccessFlash(s, fmt.Sprintf("%s repeats on %s", change.Title, change.RepeatedTo.Format("Mon 2 Jan")))
+	} else {
+		SetSuccessFlash(s, fmt.Sprintf("%s is now %s", change.Title, change.Keyword))
+	}
+
+	c.Redirect(redirectURL, http.StatusSeeOther)
+}
+
+// parseTodoFormDate parses an optional date field of the new task form
+func parseTodoFormDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil //nolint:nilnil // An empty field means no date.
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", value, err)
+	}
+
+	return &date, nil
+}
+
+// CreateTodoTask appends a task under a chosen headline of the todo file
+func CreateTodoTask(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing new todo form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/todo", http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+	redirectURL := todoAgendaURL(form)
+
+	// The parent is sent as "<line>:<title>", or empty for the top level
+	parentLine, parentTitle := -1, ""
+
+	if parent := form.Get("parent"); parent != "" {
+		rawLine, title, _ := strings.Cut(parent, ":")
+
+		line, err := strconv.Atoi(rawLine)
+		if err != nil || line < 0 {
+			SetErrorFlash(s, "Invalid heading")
+			c.Redirect(redirectURL, http.StatusSeeOther)
+
+			return
+		}
+
+		parentLine, parentTitle = line, title
+	}
+
+	scheduled, scheduledErr := parseTodoFormDate(form.Get("scheduled"))
+	deadline, deadlineErr := parseTodoFormDate(form.Get("deadline"))
+
+	if scheduledErr != nil || deadlineErr != nil {
+		SetErrorFlash(s, "Invalid date")
+		c.Redirect(redirectURL, http.StatusSeeOther)
+
+		return
+	}
+
+	task := db.NewTodoTask{
+		Title:     form.Get("title"),
+		Priority:  form.Get("priority"),
+		Tags:      []string{form.Get("tags")},
+		Scheduled: scheduled,
+		Deadline:  deadline,
+	}
+
+	if err := addTodoTaskDBFn(c.Request().Context(), parentLine, parentTitle, task, form.Get("etag")); err != nil {
+		logger.Error("Error adding todo task", "error", err)
+		setTodoEditErrorFlash(s, err, "Failed to add task")
+		c.Redirect(redirectURL, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Task added")
+	c.Redirect(redirectURL, http.StatusSeeOther)
+}
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/utils"
+)
+
+type todoStateUpdate struct {
+	line    int
+	title   string
+	keyword string
+	etag    string
+}
+
+type todoTaskAddition struct {
+	parentLine  int
+	parentTitle string
+	task        db.NewTodoTask
+	etag        string
+}
+
+func newTodoTestApp(s session.Session, t template.Template, data template.Data) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.MapTo(t, (*template.Template)(nil))
+		c.Map(data)
+		c.Next()
+	})
+
+	f.Get("/todo", Todo)
+	f.Post("/todo/state", UpdateTodoState)
+	f.Post("/todo/new", CreateTodoTask)
+
+	return f
+}
+
+// stubTodoEditing replaces the todo file writes, returning what was sent
+func stubTodoEditing(t *testing.T, change *db.TodoStateChange, saveErr error) (*[]todoStateUpdate, *[]todoTaskAddition) {
+	t.Helper()
+
+	var (
+		updates   []todoStateUpdate
+		additions []todoTaskAddition
+	)
+
+	originalSetTodoStateDBFn := setTodoStateDBFn
+	originalAddTodoTaskDBFn := addTodoTaskDBFn
+
+	setTodoStateDBFn = func(_ context.Context, line int, title, keyword, etag string, _ time.Time) (*db.TodoStateChange, error) {
+		if saveErr != nil {
+			return nil, saveErr
+		}
+
+		updates = append(updates, todoStateUpdate{line: line, title: title, keyword: keyword, etag: etag})
+
+		return change, nil
+	}
+	addTodoTaskDBFn = func(_ context.Context, parentLine int, parentTitle string, task db.NewTodoTask, etag string) error {
+		if saveErr != nil {
+			return saveErr
+		}
+
+		additions = append(additions, todoTaskAddition{parentLine: parentLine, parentTitle: parentTitle, task: task, etag: etag})
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		setTodoStateDBFn = originalSetTodoStateDBFn
+		addTodoTaskDBFn = originalAddTodoTaskDBFn
+	})
+
+	return &updates, &additions
+}
+
+func TestTodoRendersAgendaWithTaskForms(t *testing.T) {
+	original := getTodoNoteDBFn
+
+	t.Cleanup(func() { getTodoNoteDBFn = original })
+
+	content := "#+TODO: TODO NEXT | DONE\n* TODO Call :phone:\n"
+	getTodoNoteDBFn = func(context.Context) (*db.TodoNote, error) {
+		return &db.TodoNote{
+			Tasks:    utils.ParseOrgTasks(content),
+			Keywords: utils.ParseOrgTodoKeywords(content),
+			ETag:     `"v1"`,
+		}, nil
+	}
+
+	stub := &filesTemplateStub{}
+	data := template.Data{"csrf_token": "token"}
+	rec := httptest.NewRecorder()
+	newTodoTestApp(newTestSession(), stub, data).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo?span=day&date=2025-03-12&tag=phone", nil))
+
+	agenda, ok := data["Agenda"].(todoAgendaView)
+	if !stub.called || stub.name != "todo" || !ok {
+		t.Fatalf("expected the agenda to render, got %+v", stub)
+	}
+
+	if agenda.Span != db.TodoAgendaSpanDay || agenda.Tag != "phone" || agenda.Start.Format("2006-01-02") != "2025-03-12" {
+		t.Fatalf("expected the query to select the agenda, got %+v", agenda.TodoAgenda)
+	}
+
+	form := agenda.TaskForm(agenda.Unscheduled[0])
+	if !form.Editable || form.NextKeyword != "NEXT" || form.DoneKeyword != "DONE" || form.ETag != `"v1"` || form.CSRFToken != "token" || form.Date != "2025-03-12" {
+		t.Fatalf("unexpected task form %+v", form)
+	}
+}
+
+func TestUpdateTodoState(t *testing.T) {
+	updates, _ := stubTodoEditing(t, &db.TodoStateChange{Title: "Call", Keyword: "DONE"}, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newTodoTestApp(s, &filesTemplateStub{}, template.Data{}), "/todo/state", url.Values{
+		"line":    {"1"},
+		"title":   {"Call"},
+		"keyword": {"DONE"},
+		"etag":    {`"v1"`},
+		"span":    {"day"},
+		"date":    {"2025-03-12"},
+		"tag":     {"phone"},
+	}, nil)
+
+	assertRedirect(t, rec, "/todo?date=2025-03-12&span=day&tag=phone")
+	assertFlash(t, s, FlashSuccess, "Call is now DONE")
+
+	if len(*updates) != 1 || (*updates)[0] != (todoStateUpdate{line: 1, title: "Call", keyword: "DONE", etag: `"v1"`}) {
+		t.Fatalf("unexpected updates %+v", *updates)
+	}
+}
+
+func TestUpdateTodoStateRepeats(t *testing.T) {
+	next := time.Date(2025, time.March, 17, 0, 0, 0, 0, time.UTC)
+	stubTodoEditing(t, &db.TodoStateChange{Title: "Weekly sync", Keyword: "TODO", RepeatedTo: &next}, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newTodoTestApp(s, &filesTemplateStub{}, template.Data{}), "/todo/state", url.Values{
+		"line":  {"4"},
+		"title": {"Weekly sync"},
+		"etag":  {`"v1"`},
+		"date":  {"not a date"},
+	}, nil)
+
+	assertRedirect(t, rec, "/todo")
+	assertFlash(t, s, FlashSuccess, "Weekly sync repeats on Mon 17 Mar")
+}
+
+func TestUpdateTodoStateConflict(t *testing.T) {
+	stubTodoEditing(t, nil, db.ErrTodoConflict)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newTodoTestApp(s, &filesTemplateStub{}, template.Data{}), "/todo/state", url.Values{
+		"line":  {"1"},
+		"title": {"Call"},
+		"etag":  {`"stale"`},
+		"span":  {"week"},
+	}, nil)
+
+	assertRedirect(t, rec, "/todo?span=week")
+	assertFlash(t, s, FlashError, "Todo file changed since the page loaded. Reload and try again")
+}
+
+func TestCreateTodoTask(t *testing.T) {
+	_, additions := stubTodoEditing(t, nil, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newTodoTestApp(s, &filesTemplateStub{}, template.Data{}), "/todo/new", url.Values{
+		"parent":   {"3:Work: Q1"},
+		"title":    {"Write tests"},
+		"priority": {"A"},
+		"tags":     {"dev go"},
+		"deadline": {"2025-03-14"},
+		"etag":     {`"v1"`},
+	}, nil)
+
+	assertRedirect(t, rec, "/todo")
+	assertFlash(t, s, FlashSuccess, "Task added")
+
+	if len(*additions) != 1 {
+		t.Fatalf("expected one task to be added, got %+v", *additions)
+	}
+
+	added := (*additions)[0]
+	if added.parentLine != 3 || added.parentTitle != "Work: Q1" || added.task.Title != "Write tests" || added.etag != `"v1"` {
+		t.Fatalf("unexpected addition %+v", added)
+	}
+
+	if added.task.Scheduled != nil || added.task.Deadline == nil || added.task.Deadline.Format("2006-01-02") != "2025-03-14" {
+		t.Fatalf("unexpected dates %+v", added.task)
+	}
+}
+
+func TestCreateTodoTaskRejectsInvalidDate(t *testing.T) {
+	_, additions := stubTodoEditing(t, nil, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newTodoTestApp(s, &filesTemplateStub{}, template.Data{}), "/todo/new", url.Values{
+		"title":     {"Write tests"},
+		"scheduled": {"tomorrow"},
+		"etag":      {`"v1"`},
+	}, nil)
+
+	assertRedirect(t, rec, "/todo")
+	assertFlash(t, s, FlashError, "Invalid date")
+
+	if len(*additions) != 0 {
+		t.Fatalf("expected nothing to be added, got %+v", *additions)
+	}
+}
@@ -17,7 +17,7 @@ import (
 )
 
 // WhatsAppPairing renders the WhatsApp pairing/status page
-func WhatsAppPairing(_ flamego.Context, t template.Template, data template.Data) {
+func WhatsAppPairing(c flamego.Context, t template.Template, data template.Data) {
 	client := whatsapp.GetClient()
 
 	if client != nil {
@@ -30,6 +30,13 @@ func WhatsAppPairing(_ flamego.Context, t template.Template, data template.Data)
 		data["IsConnected"] = false
 	}
 
+	unknownCount, err := countUnknownWhatsAppNumbersDBFn(c.Request().Context())
+	if err != nil {
+		logger.Error("Error counting unknown WhatsApp numbers", "error", err)
+	}
+
+	data["UnknownNumberCount"] = unknownCount
+
 	data["Breadcrumbs"] = []BreadcrumbItem{
 		{Name: "WhatsApp", URL: "", IsCurrent: true},
 	}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+const whatsAppInboxURL = "/whatsapp/inbox"
+
+var (
+	listUnknownWhatsAppNumbersDBFn        = db.ListUnknownWhatsAppNumbers
+	countUnknownWhatsAppNumbersDBFn       = db.CountUnknownWhatsAppNumbers
+	createContactFromWhatsAppNumberDBFn   = db.CreateContactFromWhatsAppNumber
+	attachWhatsAppNumberDBFn              = db.AttachWhatsAppNumber
+	ignoreWhatsAppNumberDBFn              = db.IgnoreWhatsAppNumber
+	listWhatsAppInboxContactNamesDBFn     = db.ListContactNames
+	pushAttachedWhatsAppNumberToCardDAVFn = pushAttachedWhatsAppNumberToCardDAV
+)
+
+// pushAttachedWhatsAppNumberToCardDAV writes a newly attached number into
+// the contact's CardDAV card, if it has one
+func pushAttachedWhatsAppNumberToCardDAV(ctx context.Context, contactID string) error {
+	contact, err := getContactDBFn(ctx, contactID)
+	if err != nil {
+		return err
+	}
+
+	if contact.CardDAVUUID == nil || *contact.CardDAVUUID == "" {
+		return nil
+	}
+
+	return db.UpdateCardDAVContact(ctx, contact)
+}
+
+// backfilledMessage describes how many queued messages were moved
+func backfilledMessage(count int) string {
+	if count == 1 {
+		return "1 message"
+	}
+
+	return fmt.Sprintf("%d messages", count)
+}
+
+// WhatsAppInbox lists WhatsApp numbers that matched no contact
+func WhatsAppInbox(c flamego.Context, t template.Template, data template.Data) {
+	ctx := c.Request().Context()
+
+	numbers, err := listUnknownWhatsAppNumbersDBFn(ctx)
+	if err != nil {
+		logger.Error("Error listing unknown WhatsApp numbers", "error", err)
+
+		data["Error"] = "Failed to load unknown correspondents"
+	}
+
+	contactNames, err := listWhatsAppInboxContactNamesDBFn(ctx)
+	if err != nil {
+		logger.Error("Error fetching contact names", "error", err)
+	}
+
+	data["Numbers"] = numbers
+	data["ContactNames"] = contactNames
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "WhatsApp", URL: "/whatsapp", IsCurrent: false},
+		{Name: "Unknown Correspondents", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "whatsapp_inbox")
+}
+
+// CreateContactFromWhatsAppNumber creates a contact for an unknown number
+// and backfills its queued messages
+func CreateContactFromWhatsAppNumber(c flamego.Context, s session.Session) {
+	phone := c.Param("phone")
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+
+		return
+	}
+
+	name := strings.TrimSpace(c.Request().Form.Get("name"))
+
+	contactID, backfilled, err := createContactFromWhatsAppNumberDBFn(c.Request().Context(), phone, name)
+	if err != nil {
+		logger.Error("Error creating contact from WhatsApp number", "phone", phone, "error", err)
+
+		if errors.Is(err, db.ErrWhatsAppNumberNotFound) {
+			SetErrorFlash(s, "Number is no longer in the inbox")
+		} else {
+			SetErrorFlash(s, "Failed to create contact")
+		}
+
+		c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Contact created with "+backfilledMessage(backfilled))
+	c.Redirect("/contact/"+contactID, http.StatusSeeOther)
+}
+
+// AttachWhatsAppNumber adds an unknown number to an existing contact and
+// backfills its queued messages
+func AttachWhatsAppNumber(c flamego.Context, s session.Session) {
+	phone := c.Param("phone")
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+
+		return
+	}
+
+	contactID := strings.TrimSpace(c.Request().Form.Get("contact_id"))
+	if contactID == "" {
+		SetErrorFlash(s, "Choose a contact to attach the number to")
+		c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := c.Request().Context()
+
+	backfilled, err := attachWhatsAppNumberDBFn(ctx, phone, contactID)
+	if err != nil {
+		logger.Error("Error attaching WhatsApp number", "phone", phone, "contact_id", contactID, "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrWhatsAppNumberNotFound):
+			SetErrorFlash(s, "Number is no longer in the inbox")
+		case errors.Is(err, db.ErrContactNotFound):
+			SetErrorFlash(s, "Contact not found")
+		default:
+			SetErrorFlash(s, "Failed to attach number")
+		}
+
+		c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if err := pushAttachedWhatsAppNumberToCardDAVFn(ctx, contactID); err != nil {
+		logger.Error("Error pushing attached WhatsApp number to CardDAV", "contact_id", contactID, "error", err)
+		SetWarningFlash(s, "Number attached with "+backfilledMessage(backfilled)+", but it could not be synced to CardDAV")
+	} else {
+		SetSuccessFlash(s, "Number attached with "+backfilledMessage(backfilled))
+	}
+
+	c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+}
+
+// IgnoreWhatsAppNumber drops an unknown number and its later messages
+func IgnoreWhatsAppNumber(c flamego.Context, s session.Session) {
+	phone := c.Param("phone")
+
+	if err := ignoreWhatsAppNumberDBFn(c.Request().Context(), phone); err != nil {
+		logger.Error("Error ignoring WhatsApp number", "phone", phone, "error", err)
+
+		if errors.Is(err, db.ErrWhatsAppNumberNotFound) {
+			SetErrorFlash(s, "Number is no longer in the inbox")
+		} else {
+			SetErrorFlash(s, "Failed to ignore number")
+		}
+	} else {
+		SetSuccessFlash(s, "Number ignored")
+	}
+
+	c.Redirect(whatsAppInboxURL, http.StatusSeeOther)
+}
@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func newWhatsAppInboxTestApp(s session.Session) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.Next()
+	})
+
+	f.Post("/whatsapp/inbox/{phone}/create", CreateContactFromWhatsAppNumber)
+	f.Post("/whatsapp/inbox/{phone}/attach", AttachWhatsAppNumber)
+	f.Post("/whatsapp/inbox/{phone}/ignore", IgnoreWhatsAppNumber)
+
+	return f
+}
+
+func TestCreateContactFromWhatsAppNumberRoute(t *testing.T) {
+	var gotPhone, gotName string
+
+	originalCreateContactFromWhatsAppNumberDBFn := createContactFromWhatsAppNumberDBFn
+	createContactFromWhatsAppNumberDBFn = func(_ context.Context, phone, name string) (string, int, error) {
+		if phone == "0" {
+			return "", 0, db.ErrWhatsAppNumberNotFound
+		}
+
+		gotPhone, gotName = phone, name
+
+		return "c1", 3, nil
+	}
+
+	t.Cleanup(func() {
+		createContactFromWhatsAppNumberDBFn = originalCreateContactFromWhatsAppNumberDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/create", url.Values{"name": {" Sam "}}, nil)
+
+	assertRedirect(t, rec, "/contact/c1")
+	assertFlash(t, s, FlashSuccess, "Contact created with 3 messages")
+
+	if gotPhone != "971501234567" || gotName != "Sam" {
+		t.Fatalf("unexpected arguments %q %q", gotPhone, gotName)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/0/create", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashError, "Number is no longer in the inbox")
+}
+
+func TestAttachWhatsAppNumberRoute(t *testing.T) {
+	var (
+		pushed  string
+		pushErr error
+	)
+
+	originalAttachWhatsAppNumberDBFn := attachWhatsAppNumberDBFn
+	originalPushFn := pushAttachedWhatsAppNumberToCardDAVFn
+	attachWhatsAppNumberDBFn = func(_ context.Context, _ string, contactID string) (int, error) {
+		if contactID == "missing" {
+			return 0, db.ErrContactNotFound
+		}
+
+		return 1, nil
+	}
+	pushAttachedWhatsAppNumberToCardDAVFn = func(_ context.Context, contactID string) error {
+		pushed = contactID
+		return pushErr
+	}
+
+	t.Cleanup(func() {
+		attachWhatsAppNumberDBFn = originalAttachWhatsAppNumberDBFn
+		pushAttachedWhatsAppNumberToCardDAVFn = originalPushFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/attach", url.Values{"contact_id": {"c1"}}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashSuccess, "Number attached with 1 message")
+
+	if pushed != "c1" {
+		t.Fatalf("expected attached number to be pushed for c1, got %q", pushed)
+	}
+
+	pushErr = errors.New("server down")
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/attach", url.Values{"contact_id": {"c1"}}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashWarning, "Number attached with 1 message, but it could not be synced to CardDAV")
+
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/attach", url.Values{"contact_id": {"missing"}}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashError, "Contact not found")
+
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/attach", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashError, "Choose a contact to attach the number to")
+}
+
+func TestIgnoreWhatsAppNumberRoute(t *testing.T) {
+	var ignored string
+
+	originalIgnoreWhatsAppNumberDBFn := ignoreWhatsAppNumberDBFn
+	ignoreWhatsAppNumberDBFn = func(_ context.Context, phone string) error {
+		if phone == "0" {
+			return db.ErrWhatsAppNumberNotFound
+		}
+
+		ignored = phone
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		ignoreWhatsAppNumberDBFn = originalIgnoreWhatsAppNumberDBFn
+	})
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/971501234567/ignore", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashSuccess, "Number ignored")
+
+	if ignored != "971501234567" {
+		t.Fatalf("expected number to be ignored, got %q", ignored)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppInboxTestApp(s), "/whatsapp/inbox/0/ignore", url.Values{}, nil)
+
+	assertRedirect(t, rec, "/whatsapp/inbox")
+	assertFlash(t, s, FlashError, "Number is no longer in the inbox")
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/whatsapp"
+)
+
+var (
+	getWhatsAppRecipientDBFn      = db.GetWhatsAppRecipient
+	listTagWhatsAppRecipientsDBFn = db.ListTagWhatsAppRecipients
+	recordSentWhatsAppMessageDBFn = db.RecordSentWhatsAppMessage
+	getWhatsAppTagDBFn            = db.GetTag
+	whatsAppConnectedFn           = whatsAppConnected
+	sendWhatsAppTextFn            = sendWhatsAppText
+	whatsAppBulkSendInterval      = 3 * time.Second
+)
+
+// whatsAppDraft is a message rendered for one recipient of a bulk send
+type whatsAppDraft struct {
+	Recipient db.WhatsAppRecipient
+	Message   string
+}
+
+func whatsAppConnected() bool {
+	client := whatsapp.GetClient()
+
+	return client != nil && client.IsConnected()
+}
+
+func sendWhatsAppText(ctx context.Context, phone, text string) (whatsapp.Message, error) {
+	client := whatsapp.GetClient()
+	if client == nil {
+		return whatsapp.Message{}, errWhatsAppUnavailable
+	}
+
+	return client.SendText(ctx, phone, text)
+}
+
+// deliverWhatsAppMessage sends a rendered message to a recipient and adds
+// it to their chats. Only a failed send is returned as an error; a failure
+// to record the sent message is logged.
+func deliverWhatsAppMessage(ctx context.Context, recipient db.WhatsAppRecipient, text string) error {
+	sent, err := sendWhatsAppTextFn(ctx, recipient.Phone, text)
+	if err != nil {
+		return err
+	}
+
+	if err := recordSentWhatsAppMessageDBFn(ctx, recipient.ContactID, sent.ID, sent.Text, sent.Timestamp); err != nil {
+		logger.Error("Error recording sent WhatsApp message", "contact_id", recipient.ContactID, "message_id", sent.ID, "error", err)
+	}
+
+	return nil
+}
+
+// whatsAppSendErrorMessage explains a failed send to the user
+func whatsAppSendErrorMessage(recipient db.WhatsAppRecipient, err error) string {
+	if errors.Is(err, whatsapp.ErrNotOnWhatsApp) {
+		return fmt.Sprintf("%s is not on WhatsApp", recipient.Phone)
+	}
+
+	return "Failed to send WhatsApp message"
+}
+
+// SendContactWhatsApp sends a WhatsApp message to a contact
+func SendContactWhatsApp(c flamego.Context, s session.Session) {
+	ctx := c.Request().Context()
+	contactID := c.Param("id")
+	chatsURL := "/contact/" + contactID + "/chats"
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if !whatsAppConnectedFn() {
+		SetErrorFlash(s, "WhatsApp is not connected")
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	recipient, err := getWhatsAppRecipientDBFn(ctx, contactID)
+	if errors.Is(err, db.ErrContactNotFound) {
+		SetErrorFlash(s, "Contact not found")
+		c.Redirect("/contacts", http.StatusSeeOther)
+
+		return
+	} else if err != nil {
+		logger.Error("Error loading WhatsApp recipient", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, "Failed to load contact")
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if recipient.Phone == "" {
+		SetErrorFlash(s, recipient.Name+" has no phone number")
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	text := db.RenderWhatsAppMessage(c.Request().Form.Get("message"), recipient)
+	if text == "" {
+		SetErrorFlash(s, "Message content is required")
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if err := deliverWhatsAppMessage(ctx, recipient, text); err != nil {
+		logger.Error("Error sending WhatsApp message", "contact_id", contactID, "error", err)
+		SetErrorFlash(s, whatsAppSendErrorMessage(recipient, err))
+		c.Redirect(chatsURL, http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "WhatsApp message sent to "+recipient.Name)
+	c.Redirect(chatsURL, http.StatusSeeOther)
+}
+
+// loadTagWhatsAppRecipients fills the tag and its recipients into the page
+// data, redirecting when the tag cannot be loaded
+func loadTagWhatsAppRecipients(c flamego.Context, s session.Session, data template.Data) ([]db.WhatsAppRecipient, bool) {
+	ctx := c.Request().Context()
+	tagID := c.Param("id")
+
+	tag, err := getWhatsAppTagDBFn(ctx, tagID)
+	if err != nil {
+		logger.Error("Error fetching tag", "tag_id", tagID, "error", err)
+		SetErrorFlash(s, "Tag not found")
+		c.Redirect("/tags", http.StatusSeeOther)
+
+		return nil, false
+	}
+
+	recipients, err := listTagWhatsAppRecipientsDBFn(ctx, tagID)
+	if err != nil {
+		logger.Error("Error loading WhatsApp recipients", "tag_id", tagID, "error", err)
+		SetErrorFlash(s, "Failed to load contacts")
+		c.Redirect("/tags/"+tagID, http.StatusSeeOther)
+
+		return nil, false
+	}
+
+	var reachable, skipped []db.WhatsAppRecipient
+
+	for _, recipient := range recipients {
+		if recipient.Phone == "" {
+			skipped = append(skipped, recipient)
+		} else {
+			reachable = append(reachable, recipient)
+		}
+	}
+
+	data["Tag"] = tag
+	data["Recipients"] = reachable
+	data["Skipped"] = skipped
+	data["WhatsAppConnected"] = whatsAppConnectedFn()
+	data["IsContacts"] = true
+	data["PageRequiresSensitiveAccess"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Tags", URL: "/tags", IsCurrent: false},
+		{Name: tag.Name, URL: "/tags/" + tagID, IsCurrent: false},
+		{Name: "WhatsApp Message", URL: "", IsCurrent: true},
+	}
+
+	return reachable, true
+}
+
+// TagWhatsAppForm renders the message form for the contacts with a tag
+func TagWhatsAppForm(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	if _, ok := loadTagWhatsAppRecipients(c, s, data); !ok {
+		return
+	}
+
+	t.HTML(http.StatusOK, "tag_whatsapp")
+}
+
+// SendTagWhatsApp previews a message to the contacts with a tag, rendered
+// for each of them, and sends it once the preview is confirmed. Only the
+// contacts shown in the confirmed preview are messaged.
+func SendTagWhatsApp(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	tagID := c.Param("id")
+	formURL := "/tags/" + tagID + "/whatsapp"
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(formURL, http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+
+	recipients, ok := loadTagWhatsAppRecipients(c, s, data)
+	if !ok {
+		return
+	}
+
+	message := strings.TrimSpace(form.Get("message"))
+	if message == "" {
+		SetErrorFlash(s, "Message content is required")
+		c.Redirect(formURL, http.StatusSeeOther)
+
+		return
+	}
+
+	if form.Get("confirm") != "yes" {
+		drafts := make([]whatsAppDraft, 0, len(recipients))
+		for _, recipient := range recipients {
+			drafts = append(drafts, whatsAppDraft{Recipient: recipient, Message: db.RenderWhatsAppMessage(message, recipient)})
+		}
+
+		data["Message"] = message
+		data["Drafts"] = drafts
+
+		t.HTML(http.StatusOK, "tag_whatsapp")
+
+		return
+	}
+
+	if !whatsAppConnectedFn() {
+		SetErrorFlash(s, "WhatsApp is not connected")
+		c.Redirect(formURL, http.StatusSeeOther)
+
+		return
+	}
+
+	confirmed := make(map[string]bool)
+	for _, contactID := range form["contact_ids"] {
+		confirmed[contactID] = true
+	}
+
+	var drafts []whatsAppDraft
+
+	for _, recipient := range recipients {
+		text := db.RenderWhatsAppMessage(message, recipient)
+		if confirmed[recipient.ContactID] && text != "" {
+			drafts = append(drafts, whatsAppDraft{Recipient: recipient, Message: text})
+		}
+	}
+
+	if len(drafts) == 0 {
+		SetErrorFlash(s, "No contacts to message")
+		c.Redirect(formURL, http.StatusSeeOther)
+
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	// Send asynchronously, spaced out so the account is not flagged for
+	// bulk messaging
+	go sendWhatsAppDrafts(ctx, tagID, drafts)
+
+	messages := "messages"
+	if len(drafts) == 1 {
+		messages = "message"
+	}
+
+	SetInfoFlash(s, fmt.Sprintf("Sending %d WhatsApp %s in background", len(drafts), messages))
+	c.Redirect("/tags/"+tagID, http.StatusSeeOther)
+}
+
+// sendWhatsAppDrafts sends bulk messages one at a time
+func sendWhatsAppDrafts(ctx context.Context, tagID string, drafts []whatsAppDraft) {
+	var sent, failed int
+
+	for i, draft := range drafts {
+		if i > 0 {
+			time.Sleep(whatsAppBulkSendInterval)
+		}
+
+		if err := deliverWhatsAppMessage(ctx, draft.Recipient, draft.Message); err != nil {
+			logger.Error("Error sending WhatsApp message", "tag_id", tagID, "contact_id", draft.Recipient.ContactID, "error", err)
+
+			failed++
+
+			continue
+		}
+
+		sent++
+	}
+
+	logger.Info("Bulk WhatsApp send completed", "tag_id", tagID, "sent", sent, "failed", failed)
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+	"github.com/google/uuid"
+
+	"github.com/humaidq/groundwave/db"
+	"github.com/humaidq/groundwave/whatsapp"
+)
+
+func newWhatsAppSendTestApp(s session.Session, t template.Template, data template.Data) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.MapTo(t, (*template.Template)(nil))
+		c.Map(data)
+		c.Next()
+	})
+
+	f.Post("/contact/{id}/whatsapp/send", SendContactWhatsApp)
+	f.Post("/tags/{id}/whatsapp", SendTagWhatsApp)
+
+	return f
+}
+
+type sentWhatsAppMessage struct {
+	phone string
+	text  string
+}
+
+// stubWhatsAppSend replaces the WhatsApp connection and recipient lookups,
+// returning the messages sent and a channel receiving each recorded one
+func stubWhatsAppSend(t *testing.T, recipients []db.WhatsAppRecipient, sendErr error) (*[]sentWhatsAppMessage, chan string) {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		sent []sentWhatsAppMessage
+	)
+
+	recorded := make(chan string, len(recipients))
+
+	originalWhatsAppConnectedFn := whatsAppConnectedFn
+	originalSendWhatsAppTextFn := sendWhatsAppTextFn
+	originalGetWhatsAppRecipientDBFn := getWhatsAppRecipientDBFn
+	originalListTagWhatsAppRecipientsDBFn := listTagWhatsAppRecipientsDBFn
+	originalRecordSentWhatsAppMessageDBFn := recordSentWhatsAppMessageDBFn
+	originalGetWhatsAppTagDBFn := getWhatsAppTagDBFn
+	originalWhatsAppBulkSendInterval := whatsAppBulkSendInterval
+
+	whatsAppConnectedFn = func() bool { return true }
+	sendWhatsAppTextFn = func(_ context.Context, phone, text string) (whatsapp.Message, error) {
+		if sendErr != nil {
+			return whatsapp.Message{}, sendErr
+		}
+
+		mu.Lock()
+		sent = append(sent, sentWhatsAppMessage{phone: phone, text: text})
+		mu.Unlock()
+
+		return whatsapp.Message{ID: "3EB0" + phone, Text: text, Timestamp: time.Now()}, nil
+	}
+	getWhatsAppRecipientDBFn = func(_ context.Context, contactID string) (db.WhatsAppRecipient, error) {
+		for _, recipient := range recipients {
+			if recipient.ContactID == contactID {
+				return recipient, nil
+			}
+		}
+
+		return db.WhatsAppRecipient{}, db.ErrContactNotFound
+	}
+	listTagWhatsAppRecipientsDBFn = func(context.Context, string) ([]db.WhatsAppRecipient, error) {
+		return recipients, nil
+	}
+	recordSentWhatsAppMessageDBFn = func(_ context.Context, contactID, messageID, _ string, _ time.Time) error {
+		if messageID == "" {
+			return errTestShouldNotBeCalled
+		}
+
+		recorded <- contactID
+
+		return nil
+	}
+	getWhatsAppTagDBFn = func(context.Context, string) (*db.Tag, error) {
+		return &db.Tag{ID: uuid.New(), Name: "Friends"}, nil
+	}
+	whatsAppBulkSendInterval = 0
+
+	t.Cleanup(func() {
+		whatsAppConnectedFn = originalWhatsAppConnectedFn
+		sendWhatsAppTextFn = originalSendWhatsAppTextFn
+		getWhatsAppRecipientDBFn = originalGetWhatsAppRecipientDBFn
+		listTagWhatsAppRecipientsDBFn = originalListTagWhatsAppRecipientsDBFn
+		recordSentWhatsAppMessageDBFn = originalRecordSentWhatsAppMessageDBFn
+		getWhatsAppTagDBFn = originalGetWhatsAppTagDBFn
+		whatsAppBulkSendInterval = originalWhatsAppBulkSendInterval
+	})
+
+	return &sent, recorded
+}
+
+var testWhatsAppRecipients = []db.WhatsAppRecipient{
+	{ContactID: "c1", Name: "Sara Ali", GivenName: "Sara", Phone: "+971501234567"},
+	{ContactID: "c2", Name: "Omar", GivenName: "Omar", Phone: "+447700900123"},
+	{ContactID: "c3", Name: "Lena"},
+}
+
+func TestSendContactWhatsApp(t *testing.T) {
+	sent, recorded := stubWhatsAppSend(t, testWhatsAppRecipients, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppSendTestApp(s, &filesTemplateStub{}, template.Data{}), "/contact/c1/whatsapp/send", url.Values{
+		"message": {"Hi {first_name}"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c1/chats")
+	assertFlash(t, s, FlashSuccess, "WhatsApp message sent to Sara Ali")
+
+	if len(*sent) != 1 || (*sent)[0] != (sentWhatsAppMessage{phone: "+971501234567", text: "Hi Sara"}) {
+		t.Fatalf("unexpected sends %+v", *sent)
+	}
+
+	if contactID := <-recorded; contactID != "c1" {
+		t.Fatalf("expected the message recorded for c1, got %q", contactID)
+	}
+
+	s = newTestSession()
+	rec = performFormPOST(t, newWhatsAppSendTestApp(s, &filesTemplateStub{}, template.Data{}), "/contact/c3/whatsapp/send", url.Values{
+		"message": {"Hi"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c3/chats")
+	assertFlash(t, s, FlashError, "Lena has no phone number")
+}
+
+func TestSendContactWhatsAppNotOnWhatsApp(t *testing.T) {
+	stubWhatsAppSend(t, testWhatsAppRecipients, whatsapp.ErrNotOnWhatsApp)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppSendTestApp(s, &filesTemplateStub{}, template.Data{}), "/contact/c2/whatsapp/send", url.Values{
+		"message": {"Hi"},
+	}, nil)
+
+	assertRedirect(t, rec, "/contact/c2/chats")
+	assertFlash(t, s, FlashError, "+447700900123 is not on WhatsApp")
+}
+
+func TestSendTagWhatsAppPreviewsDrafts(t *testing.T) {
+	sent, _ := stubWhatsAppSend(t, testWhatsAppRecipients, nil)
+
+	s := newTestSession()
+	tpl := &filesTemplateStub{}
+	data := template.Data{}
+
+	performFormPOST(t, newWhatsAppSendTestApp(s, tpl, data), "/tags/t1/whatsapp", url.Values{
+		"message":     {"Hi {first_name}"},
+		"contact_ids": {"c1", "c2"},
+	}, nil)
+
+	if !tpl.called || tpl.status != http.StatusOK || tpl.name != "tag_whatsapp" {
+		t.Fatalf("expected the preview to render, got %+v", tpl)
+	}
+
+	drafts, ok := data["Drafts"].([]whatsAppDraft)
+	if !ok || len(drafts) != 2 || drafts[0].Message != "Hi Sara" || drafts[1].Message != "Hi Omar" {
+		t.Fatalf("unexpected drafts %+v", data["Drafts"])
+	}
+
+	if skipped, ok := data["Skipped"].([]db.WhatsAppRecipient); !ok || len(skipped) != 1 || skipped[0].ContactID != "c3" {
+		t.Fatalf("expected the contact without a phone to be skipped, got %+v", data["Skipped"])
+	}
+
+	if len(*sent) != 0 {
+		t.Fatalf("expected nothing sent without confirmation, got %+v", *sent)
+	}
+}
+
+func TestSendTagWhatsAppSendsConfirmedDrafts(t *testing.T) {
+	sent, recorded := stubWhatsAppSend(t, testWhatsAppRecipients, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newWhatsAppSendTestApp(s, &filesTemplateStub{}, template.Data{}), "/tags/t1/whatsapp", url.Values{
+		"message":     {"Hi {first_name}"},
+		"contact_ids": {"c2", "c3"},
+		"confirm":     {"yes"},
+	}, nil)
+
+	assertRedirect(t, rec, "/tags/t1")
+	assertFlash(t, s, FlashInfo, "Sending 1 WhatsApp message in background")
+
+	select {
+	case contactID := <-recorded:
+		if contactID != "c2" {
+			t.Fatalf("expected only the confirmed contact with a phone, got %q", contactID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a message to be sent")
+	}
+
+	if len(*sent) != 1 || (*sent)[0].text != "Hi Omar" {
+		t.Fatalf("unexpected sends %+v", *sent)
+	}
+}
@@ -9,9 +9,13 @@ import (
 	"crypto/rand"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/flamego/flamego"
 	"github.com/flamego/session"
@@ -51,6 +55,10 @@ const zkHistoryMaxItems = 4
 var (
 	updateZettelCommentDBFn     = db.UpdateZettelComment
 	rebuildZettelkastenCachesFn = db.RebuildZettelkastenCaches
+	relatedZKNotesDBFn          = db.ListRelatedZKNotes
+	zkEmbeddingsConfiguredFn    = db.ZKEmbeddingsConfigured
+	getZKNoteForChatDBFn        = db.GetZKNoteForChat
+	streamZKChatFn              = db.StreamZKChat
 )
 
 func init() {
@@ -273,6 +281,12 @@ func ViewZKNote(c flamego.Context, s session.Session, t template.Template, data
 
 	backlinks := buildBacklinks(ctx, backlinkIDs, "/zk", backlinkVisibilityAll)
 
+	// Related notes need embeddings, so the panel is left out without them
+	relatedNotes, err := relatedZKNotesDBFn(ctx, noteID)
+	if err != nil && !errors.Is(err, db.ErrOllamaEmbedConfigIncomplete) {
+		logger.Error("Error fetching related notes", "note_id", noteID, "error", err)
+	}
+
 	// Get last cache build time
 	lastCacheUpdate := db.GetLastCacheBuildTime()
 
@@ -285,6 +299,7 @@ func ViewZKNote(c flamego.Context, s session.Session, t template.Template, data
 	data["Comments"] = comments
 	data["NoteID"] = noteID // Pass note ID explicitly from URL
 	data["Backlinks"] = backlinks
+	data["RelatedNotes"] = relatedNotes
 	data["LastCacheUpdate"] = lastCacheUpdate
 	data["IsZettelkasten"] = true
 	data["EnableTimestampCountdown"] = true
@@ -433,6 +448,7 @@ func ZettelkastenChat(c flamego.Context, s session.Session, t template.Template,
 	}
 
 	data["Notes"] = notes
+	data["RetrievalEnabled"] = zkEmbeddingsConfiguredFn()
 	data["IsZettelkasten"] = true
 	data["Breadcrumbs"] = []BreadcrumbItem{
 		{Name: "Zettelkasten", URL: "/zk", IsCurrent: false},
@@ -547,7 +563,9 @@ func ZettelkastenChatStream(c flamego.Context) {
 		return
 	}
 
-	if len(reqBody.NoteIDs) == 0 {
+	// Without embeddings the selected notes are the only context
+	retrievalEnabled := zkEmbeddingsConfiguredFn()
+	if len(reqBody.NoteIDs) == 0 && !retrievalEnabled {
 		sendError("Select at least one note")
 		return
 	}
@@ -559,7 +577,7 @@ func ZettelkastenChatStream(c flamego.Context) {
 			continue
 		}
 
-		note, err := db.GetZKNoteForChat(ctx, noteID)
+		note, err := getZKNoteForChatDBFn(ctx, noteID)
 		if err != nil {
 			logger.Error("Error fetching zettelkasten note", "note_id", noteID, "error", err)
 			sendError("Note not found: " + noteID)
@@ -570,12 +588,12 @@ func ZettelkastenChatStream(c flamego.Context) {
 		notes = append(notes, *note)
 	}
 
-	if len(notes) == 0 {
+	if len(notes) == 0 && !retrievalEnabled {
 		sendError("No valid notes selected")
 		return
 	}
 
-	err := db.StreamZKChat(ctx, notes, message, func(chunk string) error {
+	err := streamZKChatFn(ctx, notes, message, func(chunk string) error {
 		sendEvent("chunk", chunk)
 		return nil
 	})
@@ -754,22 +772,114 @@ func DeleteAllZettelComments(c flamego.Context, s session.Session, _ template.Te
 	c.Redirect("/zk/"+zettelID, http.StatusSeeOther)
 }
 
-// RebuildCache manually triggers a full cache rebuild.
+// zkRebuildReportMaxFiles caps how many file names a rebuild flash lists
+const zkRebuildReportMaxFiles = 3
+
+// describeZKRebuildFiles lists the first few file names of a rebuild
+// report, e.g. "2 changed (a.org, b.org)"
+func describeZKRebuildFiles(files []string, verb string) string {
+	shown := files
+	if len(shown) > zkRebuildReportMaxFiles {
+		shown = shown[:zkRebuildReportMaxFiles]
+	}
+
+	description := fmt.Sprintf("%d %s (%s", len(files), verb, strings.Join(shown, ", "))
+	if hidden := len(files) - len(shown); hidden > 0 {
+		description += fmt.Sprintf(" and %d more", hidden)
+	}
+
+	return description + ")"
+}
+
+// describeZKRebuildReport sums up what a cache rebuild changed
+func describeZKRebuildReport(report *db.ZKCacheRebuildReport) string {
+	if !report.HasChanges() {
+		return fmt.Sprintf("Cache rebuilt, no files changed (%d checked)", report.Unchanged+len(report.Failed))
+	}
+
+	var parts []string
+
+	for _, group := range []struct {
+		files []string
+		verb  string
+	}{
+		{report.Added, "added"},
+		{report.Changed, "changed"},
+		{report.Removed, "removed"},
+	} {
+		if len(group.files) > 0 {
+			parts = append(parts, describeZKRebuildFiles(group.files, group.verb))
+		}
+	}
+
+	return fmt.Sprintf("Cache rebuilt: %s, %d unchanged", strings.Join(parts, ", "), report.Unchanged)
+}
+
+// zkRebuildWait is how long the refresh button waits for a rebuild to
+// report before leaving it to finish in the background
+var zkRebuildWait = 15 * time.Second
+
+// zkRebuildRunning stops repeated clicks from queueing more rebuilds
+var zkRebuildRunning atomic.Bool
+
+// zkRebuildResult is the outcome of a rebuild started by the button
+type zkRebuildResult struct {
+	report *db.ZKCacheRebuildReport
+	err    error
+}
+
+// RebuildCache manually triggers a cache rebuild and reports which files
+// changed. A rebuild that takes longer than zkRebuildWait, such as the first
+// one or one queued behind the worker, carries on in the background.
 func RebuildCache(c flamego.Context, s session.Session) {
+	if !zkRebuildRunning.CompareAndSwap(false, true) {
+		SetInfoFlash(s, "Cache rebuild already running")
+		c.Redirect("/zk", http.StatusSeeOther)
+
+		return
+	}
+
+	// A closed tab should not cut the rebuild short
 	ctx := context.WithoutCancel(c.Request().Context())
+	done := make(chan zkRebuildResult, 1)
 
-	// Trigger cache rebuild asynchronously to avoid blocking the HTTP request
 	go func() {
-		if err := rebuildZettelkastenCachesFn(ctx); err != nil {
+		report, err := rebuildZettelkastenCachesFn(ctx)
+		zkRebuildRunning.Store(false)
+
+		if err != nil {
 			logger.Error("Manual cache rebuild failed", "error", err)
 		} else {
 			logger.Info("Manual cache rebuild completed successfully")
 		}
+
+		done <- zkRebuildResult{report: report, err: err}
 	}()
 
-	// Immediately redirect back to the zettelkasten index with info message
-	// Cache will be rebuilt in the background
-	SetInfoFlash(s, "Cache rebuild started in background")
+	var result zkRebuildResult
+
+	select {
+	case result = <-done:
+	case <-time.After(zkRebuildWait):
+		SetInfoFlash(s, "Cache rebuild is still running in the background")
+		c.Redirect("/zk", http.StatusSeeOther)
+
+		return
+	}
+
+	if result.err != nil {
+		SetErrorFlash(s, "Cache rebuild failed")
+		c.Redirect("/zk", http.StatusSeeOther)
+
+		return
+	}
+
+	message := describeZKRebuildReport(result.report)
+	if len(result.report.Failed) > 0 {
+		message += "; " + describeZKRebuildFiles(result.report.Failed, "unreadable")
+	}
+
+	SetInfoFlash(s, message)
 	c.Redirect("/zk", http.StatusSeeOther)
 }
 
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flamego/flamego"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+func performZKChatStreamRequest(t *testing.T, body string) string {
+	t.Helper()
+
+	f := flamego.New()
+	f.Post("/zk/chat/stream", ZettelkastenChatStream)
+
+	req := httptest.NewRequest(http.MethodPost, "/zk/chat/stream", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+
+	return rec.Body.String()
+}
+
+func stubZKChat(t *testing.T, retrievalEnabled bool) *[]db.ZKChatNote {
+	t.Helper()
+
+	originalZKEmbeddingsConfiguredFn := zkEmbeddingsConfiguredFn
+	originalGetZKNoteForChatDBFn := getZKNoteForChatDBFn
+	originalStreamZKChatFn := streamZKChatFn
+
+	t.Cleanup(func() {
+		zkEmbeddingsConfiguredFn = originalZKEmbeddingsConfiguredFn
+		getZKNoteForChatDBFn = originalGetZKNoteForChatDBFn
+		streamZKChatFn = originalStreamZKChatFn
+	})
+
+	streamed := &[]db.ZKChatNote{}
+
+	zkEmbeddingsConfiguredFn = func() bool { return retrievalEnabled }
+	getZKNoteForChatDBFn = func(_ context.Context, id string) (*db.ZKChatNote, error) {
+		return &db.ZKChatNote{ID: id, Title: "Note " + id}, nil
+	}
+	streamZKChatFn = func(_ context.Context, notes []db.ZKChatNote, _ string, onChunk func(string) error) error {
+		*streamed = notes
+		return onChunk("Answer")
+	}
+
+	return streamed
+}
+
+func TestZettelkastenChatStreamRequiresNotesWithoutRetrieval(t *testing.T) {
+	stubZKChat(t, false)
+
+	streamZKChatFn = func(context.Context, []db.ZKChatNote, string, func(string) error) error {
+		return errTestShouldNotBeCalled
+	}
+
+	body := performZKChatStreamRequest(t, `{"note_ids":[],"message":"What do I know?"}`)
+	if body != "event: error\ndata: Select at least one note\n\n" {
+		t.Fatalf("unexpected stream body:\n%q", body)
+	}
+}
+
+func TestZettelkastenChatStreamRetrievesWithoutSelectedNotes(t *testing.T) {
+	streamed := stubZKChat(t, true)
+
+	body := performZKChatStreamRequest(t, `{"note_ids":[],"message":"What do I know?"}`)
+	if body != "event: chunk\ndata: Answer\n\nevent: done\ndata: \n\n" {
+		t.Fatalf("unexpected stream body:\n%q", body)
+	}
+
+	if len(*streamed) != 0 {
+		t.Fatalf("expected no selected notes, got %#v", *streamed)
+	}
+}
+
+func TestZettelkastenChatStreamPassesSelectedNotes(t *testing.T) {
+	streamed := stubZKChat(t, true)
+
+	body := performZKChatStreamRequest(t, `{"note_ids":["id:note-1"," "],"message":"Summarise"}`)
+	if !strings.HasSuffix(body, "event: done\ndata: \n\n") {
+		t.Fatalf("unexpected stream body:\n%q", body)
+	}
+
+	if len(*streamed) != 1 || (*streamed)[0].ID != "note-1" {
+		t.Fatalf("expected the selected note to be streamed, got %#v", *streamed)
+	}
+}
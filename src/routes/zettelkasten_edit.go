@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+var (
+	getZKNoteSourceDBFn = db.GetZKNoteSource
+	updateZKNoteDBFn    = db.UpdateZKNote
+	createZKNoteDBFn    = db.CreateZKNote
+)
+
+// EditZKNoteForm renders the org-mode editor for a note
+func EditZKNoteForm(c flamego.Context, s session.Session, t template.Template, data template.Data) {
+	noteID := strings.TrimPrefix(c.Param("id"), "id:")
+	noteURL := "/zk/" + noteID
+
+	source, err := getZKNoteSourceDBFn(c.Request().Context(), noteID)
+	if err != nil {
+		logger.Error("Error fetching note for edit", "note_id", noteID, "error", err)
+		SetErrorFlash(s, "Note not found")
+		c.Redirect("/zk", http.StatusSeeOther)
+
+		return
+	}
+
+	if source.ETag == "" {
+		SetErrorFlash(s, "Editing unavailable for this note")
+		c.Redirect(noteURL, http.StatusSeeOther)
+
+		return
+	}
+
+	data["Source"] = source
+	data["IsZettelkasten"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Zettelkasten", URL: "/zk", IsCurrent: false},
+		{Name: source.Title, URL: noteURL, IsCurrent: false},
+		{Name: "Edit", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "zettelkasten_edit")
+}
+
+// UpdateZKNote saves edits to a note, refusing them when the file changed
+// after the editor was opened
+func UpdateZKNote(c flamego.Context, s session.Session) {
+	noteID := strings.TrimPrefix(c.Param("id"), "id:")
+	noteURL := "/zk/" + noteID
+	editURL := noteURL + "/edit"
+
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing note edit form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect(editURL, http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+
+	err := updateZKNoteDBFn(c.Request().Context(), noteID, form.Get("content"), form.Get("etag"))
+	if err != nil {
+		logger.Error("Error updating note", "note_id", noteID, "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrZKNoteConflict):
+			SetErrorFlash(s, "Note changed since you opened it. Reload and try again")
+			c.Redirect(editURL, http.StatusSeeOther)
+		case errors.Is(err, db.ErrZKNoteETagRequired):
+			SetErrorFlash(s, "Missing note version. Reload and try again")
+			c.Redirect(editURL, http.StatusSeeOther)
+		case errors.Is(err, db.ErrZKNoteIDChanged):
+			SetErrorFlash(s, "The :ID: property of a note cannot be changed or removed")
+			c.Redirect(editURL, http.StatusSeeOther)
+		case errors.Is(err, db.ErrZKNoteNotFound):
+			SetErrorFlash(s, "Note not found")
+			c.Redirect("/zk", http.StatusSeeOther)
+		default:
+			SetErrorFlash(s, "Failed to save note")
+			c.Redirect(editURL, http.StatusSeeOther)
+		}
+
+		return
+	}
+
+	SetSuccessFlash(s, "Note saved")
+	c.Redirect(noteURL, http.StatusSeeOther)
+}
+
+// NewZKNoteForm renders the form for a new note
+func NewZKNoteForm(t template.Template, data template.Data) {
+	data["IsZettelkasten"] = true
+	data["Breadcrumbs"] = []BreadcrumbItem{
+		{Name: "Zettelkasten", URL: "/zk", IsCurrent: false},
+		{Name: "New Note", URL: "", IsCurrent: true},
+	}
+
+	t.HTML(http.StatusOK, "zettelkasten_edit")
+}
+
+// CreateZKNote writes a new note and opens it
+func CreateZKNote(c flamego.Context, s session.Session) {
+	if err := c.Request().ParseForm(); err != nil {
+		logger.Error("Error parsing new note form", "error", err)
+		SetErrorFlash(s, "Failed to parse form data")
+		c.Redirect("/zk/new", http.StatusSeeOther)
+
+		return
+	}
+
+	form := c.Request().Form
+
+	noteID, err := createZKNoteDBFn(c.Request().Context(), form.Get("title"), form.Get("content"), time.Now())
+	if err != nil {
+		logger.Error("Error creating note", "error", err)
+
+		switch {
+		case errors.Is(err, db.ErrZKNoteTitleRequired):
+			SetErrorFlash(s, "Title is required")
+		case errors.Is(err, db.ErrZKNoteExists):
+			SetErrorFlash(s, "A note file with that name already exists. Try again in a second")
+		default:
+			SetErrorFlash(s, "Failed to create note")
+		}
+
+		c.Redirect("/zk/new", http.StatusSeeOther)
+
+		return
+	}
+
+	SetSuccessFlash(s, "Note created")
+	c.Redirect("/zk/"+noteID, http.StatusSeeOther)
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/flamego/session"
+	"github.com/flamego/template"
+
+	"github.com/humaidq/groundwave/db"
+)
+
+const testZKNoteID = "33333333-3333-3333-3333-333333333333"
+
+func newZKEditTestApp(s session.Session, t template.Template, data template.Data) *flamego.Flame {
+	f := flamego.New()
+	f.Use(func(c flamego.Context) {
+		c.MapTo(s, (*session.Session)(nil))
+		c.MapTo(t, (*template.Template)(nil))
+		c.Map(data)
+		c.Next()
+	})
+
+	f.Get("/zk/new", NewZKNoteForm)
+	f.Get("/zk/{id}", func(c flamego.Context) { c.ResponseWriter().WriteHeader(http.StatusTeapot) })
+	f.Get("/zk/{id}/edit", EditZKNoteForm)
+	f.Post("/zk/new", CreateZKNote)
+	f.Post("/zk/{id}/edit", UpdateZKNote)
+
+	return f
+}
+
+type zkNoteUpdate struct {
+	id      string
+	content string
+	etag    string
+}
+
+// stubZKNoteEditing replaces the note storage, returning the saved updates
+// and the titles of created notes
+func stubZKNoteEditing(t *testing.T, source *db.ZKNoteSource, saveErr error) (*[]zkNoteUpdate, *[]string) {
+	t.Helper()
+
+	var (
+		updates []zkNoteUpdate
+		created []string
+	)
+
+	originalGetZKNoteSourceDBFn := getZKNoteSourceDBFn
+	originalUpdateZKNoteDBFn := updateZKNoteDBFn
+	originalCreateZKNoteDBFn := createZKNoteDBFn
+
+	getZKNoteSourceDBFn = func(_ context.Context, id string) (*db.ZKNoteSource, error) {
+		if source == nil || id != source.ID {
+			return nil, db.ErrZKNoteNotFound
+		}
+
+		return source, nil
+	}
+	updateZKNoteDBFn = func(_ context.Context, id, content, etag string) error {
+		if saveErr != nil {
+			return saveErr
+		}
+
+		updates = append(updates, zkNoteUpdate{id: id, content: content, etag: etag})
+
+		return nil
+	}
+	createZKNoteDBFn = func(_ context.Context, title, _ string, _ time.Time) (string, error) {
+		if saveErr != nil {
+			return "", saveErr
+		}
+
+		created = append(created, title)
+
+		return testZKNoteID, nil
+	}
+
+	t.Cleanup(func() {
+		getZKNoteSourceDBFn = originalGetZKNoteSourceDBFn
+		updateZKNoteDBFn = originalUpdateZKNoteDBFn
+		createZKNoteDBFn = originalCreateZKNoteDBFn
+	})
+
+	return &updates, &created
+}
+
+func TestEditZKNoteForm(t *testing.T) {
+	source := &db.ZKNoteSource{ID: testZKNoteID, Title: "Note Two", Content: "text", ETag: `"1"`}
+	stubZKNoteEditing(t, source, nil)
+
+	stub := &filesTemplateStub{}
+	data := template.Data{}
+	f := newZKEditTestApp(newTestSession(), stub, data)
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/zk/"+testZKNoteID+"/edit", nil))
+
+	if !stub.called || stub.name != "zettelkasten_edit" || data["Source"] != source {
+		t.Fatalf("expected editor to render with the note, got %+v", stub)
+	}
+
+	source.ETag = ""
+	s := newTestSession()
+	rec = httptest.NewRecorder()
+	newZKEditTestApp(s, &filesTemplateStub{}, template.Data{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/zk/"+testZKNoteID+"/edit", nil))
+
+	assertRedirect(t, rec, "/zk/"+testZKNoteID)
+	assertFlash(t, s, FlashError, "Editing unavailable for this note")
+}
+
+func TestUpdateZKNote(t *testing.T) {
+	updates, _ := stubZKNoteEditing(t, nil, nil)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newZKEditTestApp(s, &filesTemplateStub{}, template.Data{}), "/zk/"+testZKNoteID+"/edit", url.Values{
+		"content": {"new text"},
+		"etag":    {`"1"`},
+	}, nil)
+
+	assertRedirect(t, rec, "/zk/"+testZKNoteID)
+	assertFlash(t, s, FlashSuccess, "Note saved")
+
+	if len(*updates) != 1 || (*updates)[0] != (zkNoteUpdate{id: testZKNoteID, content: "new text", etag: `"1"`}) {
+		t.Fatalf("unexpected updates %+v", *updates)
+	}
+}
+
+func TestUpdateZKNoteConflict(t *testing.T) {
+	stubZKNoteEditing(t, nil, db.ErrZKNoteConflict)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newZKEditTestApp(s, &filesTemplateStub{}, template.Data{}), "/zk/"+testZKNoteID+"/edit", url.Values{
+		"content": {"new text"},
+		"etag":    {`"stale"`},
+	}, nil)
+
+	assertRedirect(t, rec, "/zk/"+testZKNoteID+"/edit")
+	assertFlash(t, s, FlashError, "Note changed since you opened it. Reload and try again")
+}
+
+func TestCreateZKNote(t *testing.T) {
+	_, created := stubZKNoteEditing(t, nil, nil)
+
+	stub := &filesTemplateStub{}
+	rec := httptest.NewRecorder()
+	newZKEditTestApp(newTestSession(), stub, template.Data{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/zk/new", nil))
+
+	if !stub.called || stub.name != "zettelkasten_edit" {
+		t.Fatalf("expected new note form to render, got %+v", stub)
+	}
+
+	s := newTestSession()
+	rec = performFormPOST(t, newZKEditTestApp(s, &filesTemplateStub{}, template.Data{}), "/zk/new", url.Values{
+		"title":   {"Fresh Idea"},
+		"content": {"body"},
+	}, nil)
+
+	assertRedirect(t, rec, "/zk/"+testZKNoteID)
+	assertFlash(t, s, FlashSuccess, "Note created")
+
+	if len(*created) != 1 || (*created)[0] != "Fresh Idea" {
+		t.Fatalf("unexpected created notes %v", *created)
+	}
+}
+
+func TestCreateZKNoteRequiresTitle(t *testing.T) {
+	stubZKNoteEditing(t, nil, db.ErrZKNoteTitleRequired)
+
+	s := newTestSession()
+	rec := performFormPOST(t, newZKEditTestApp(s, &filesTemplateStub{}, template.Data{}), "/zk/new", url.Values{
+		"title": {" "},
+	}, nil)
+
+	assertRedirect(t, rec, "/zk/new")
+	assertFlash(t, s, FlashError, "Title is required")
+}
@@ -12,4 +12,6 @@ var (
 	errNoIDPropertyFound         = errors.New("no ID property found in content")
 	errInvalidUUIDFormat         = errors.New("invalid UUID format")
 	errUUIDLengthOutOfBounds     = errors.New("UUID length out of bounds")
+	errPhotoTooLarge             = errors.New("photo dimensions are too large")
+	errPhotoSizeInvalid          = errors.New("photo size must be positive")
 )
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// ICalEvent is an all-day event in an iCalendar feed.
+type ICalEvent struct {
+	UID         string
+	Date        time.Time // Only the calendar date is used
+	Summary     string
+	Description string
+	URL         string
+}
+
+const icalLineLimit = 75
+
+// BuildICalendar renders events as an RFC 5545 VCALENDAR document.
+func BuildICalendar(name string, events []ICalEvent, generatedAt time.Time) string {
+	var b strings.Builder
+
+	stamp := generatedAt.UTC().Format("20060102T150405Z")
+
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Groundwave//Contact Dates//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+
+	if name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(name))
+	}
+
+	for _, event := range events {
+		start := event.Date.Format("20060102")
+		end := event.Date.AddDate(0, 0, 1).Format("20060102")
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+escapeICalText(event.UID))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+start)
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+end)
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(event.Summary))
+
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+
+		if event.URL != "" {
+			writeICalLine(&b, "URL:"+event.URL)
+		}
+
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+// escapeICalText escapes a TEXT property value.
+func escapeICalText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+
+	return replacer.Replace(value)
+}
+
+// writeICalLine writes a content line, folding it at 75 octets without
+// splitting multi-byte characters.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isUTF8Boundary(line, cut) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = icalLineLimit - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isUTF8Boundary(s string, i int) bool {
+	return i >= len(s) || s[i]&0xC0 != 0x80
+}
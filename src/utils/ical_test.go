@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildICalendarAllDayEvent(t *testing.T) {
+	t.Parallel()
+
+	generatedAt := time.Date(2025, time.March, 1, 12, 30, 0, 0, time.UTC)
+	events := []ICalEvent{
+		{
+			UID:         "abc-birthday-2025@groundwave",
+			Date:        time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC),
+			Summary:     "Ada Lovelace turns 30",
+			Description: "Birthday; born 1995,\nsee notes",
+			URL:         "https://example.com/contact/abc",
+		},
+	}
+
+	out := BuildICalendar("Contact Dates", events, generatedAt)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"VERSION:2.0\r\n",
+		"X-WR-CALNAME:Contact Dates\r\n",
+		"UID:abc-birthday-2025@groundwave\r\n",
+		"DTSTAMP:20250301T123000Z\r\n",
+		"DTSTART;VALUE=DATE:20250315\r\n",
+		"DTEND;VALUE=DATE:20250316\r\n",
+		"SUMMARY:Ada Lovelace turns 30\r\n",
+		"DESCRIPTION:Birthday\\; born 1995\\,\\nsee notes\r\n",
+		"URL:https://example.com/contact/abc\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if strings.Count(out, "BEGIN:VEVENT") != 1 {
+		t.Fatalf("expected one event, got:\n%s", out)
+	}
+}
+
+func TestBuildICalendarFoldsLongLines(t *testing.T) {
+	t.Parallel()
+
+	summary := strings.Repeat("é", 100)
+	out := BuildICalendar("", []ICalEvent{{
+		UID:     "long@groundwave",
+		Date:    time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Summary: summary,
+	}}, time.Now())
+
+	if strings.Contains(out, "X-WR-CALNAME") {
+		t.Fatalf("expected no calendar name when empty")
+	}
+
+	var unfolded strings.Builder
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line exceeds 75 octets (%d): %q", len(line), line)
+		}
+
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+			continue
+		}
+
+		unfolded.WriteString("\n" + line)
+	}
+
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+summary) {
+		t.Fatalf("expected folded summary to unfold to the original text")
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{input: "plain", want: "plain"},
+		{input: `back\slash`, want: `back\\slash`},
+		{input: "a;b,c", want: `a\;b\,c`},
+		{input: "line1\r\nline2\nline3", want: `line1\nline2\nline3`},
+	}
+
+	for _, tc := range cases {
+		if got := escapeICalText(tc.input); got != tc.want {
+			t.Fatalf("escapeICalText(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
@@ -192,6 +192,37 @@ func CreateGridMapWithDistance(myGrid, theirGrid string, config MapConfig) (floa
 	return distance, nil
 }
 
+// defaultLocationZoom shows a few streets around a single location.
+const defaultLocationZoom = 15
+
+// WriteLocationMap renders a PNG map centred on a single marked location.
+func WriteLocationMap(w io.Writer, lat, lon float64, config MapConfig) error {
+	ctx := newMapContext()
+	ctx.SetSize(config.Width, config.Height)
+
+	zoom := config.Zoom
+	if zoom <= 0 {
+		zoom = defaultLocationZoom
+	}
+
+	pos := s2.LatLngFromDegrees(lat, lon)
+
+	ctx.SetZoom(zoom)
+	ctx.SetCenter(pos)
+	ctx.AddObject(sm.NewMarker(pos, color.RGBA{255, 0, 0, 255}, 16.0))
+
+	img, err := ctx.Render()
+	if err != nil {
+		return fmt.Errorf("failed to render map: %w", err)
+	}
+
+	if err := encodePNG(w, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	return nil
+}
+
 func saveImage(img image.Image, filename string) error {
 	file, err := createFile(filename)
 	if err != nil {
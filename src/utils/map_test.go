@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"image"
@@ -377,3 +378,73 @@ func TestSaveImageCloseError(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 }
+
+func TestWriteLocationMapUsesStubContext(t *testing.T) {
+	origNewMapContext := newMapContext
+	stub := &stubMapContext{}
+	newMapContext = func() mapContext {
+		return stub
+	}
+
+	defer func() {
+		newMapContext = origNewMapContext
+	}()
+
+	var buf bytes.Buffer
+
+	config := MapConfig{Width: 600, Height: 300}
+	if err := WriteLocationMap(&buf, 25.2048, 55.2708, config); err != nil {
+		t.Fatalf("WriteLocationMap failed: %v", err)
+	}
+
+	if stub.width != 600 || stub.height != 300 {
+		t.Fatalf("expected size 600x300, got %dx%d", stub.width, stub.height)
+	}
+
+	if stub.zoom != defaultLocationZoom {
+		t.Fatalf("expected default zoom %d, got %d", defaultLocationZoom, stub.zoom)
+	}
+
+	if got := stub.center.Lat.Degrees(); got < 25.2047 || got > 25.2049 {
+		t.Fatalf("unexpected center latitude %f", got)
+	}
+
+	if len(stub.objects) != 1 {
+		t.Fatalf("expected 1 marker, got %d", len(stub.objects))
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x89PNG")) {
+		t.Fatalf("expected PNG output")
+	}
+}
+
+func TestWriteLocationMapErrors(t *testing.T) {
+	origNewMapContext := newMapContext
+	origEncodePNG := encodePNG
+
+	defer func() {
+		newMapContext = origNewMapContext
+		encodePNG = origEncodePNG
+	}()
+
+	newMapContext = func() mapContext {
+		return &stubMapContext{renderErr: errTestRenderFailed}
+	}
+
+	err := WriteLocationMap(io.Discard, 0, 0, MapConfig{Width: 1, Height: 1, Zoom: 3})
+	if err == nil || !strings.Contains(err.Error(), "failed to render map") {
+		t.Fatalf("expected render error, got %v", err)
+	}
+
+	newMapContext = func() mapContext {
+		return &stubMapContext{}
+	}
+	encodePNG = func(_ io.Writer, _ image.Image) error {
+		return errTestEncodeFailed
+	}
+
+	err = WriteLocationMap(io.Discard, 0, 0, MapConfig{Width: 1, Height: 1})
+	if err == nil || !strings.Contains(err.Error(), "failed to encode PNG") {
+		t.Fatalf("expected encode error, got %v", err)
+	}
+}
@@ -45,7 +45,7 @@ func ParseOrgToHTML(content string) (string, error) {
 func ParseOrgToHTMLWithBasePath(content string, basePath string) (string, error) {
 	config := newOrgConfig()
 
-	config.DefaultSettings["TODO"] = "TODO PROJ STRT WAIT HOLD | DONE KILL"
+	config.DefaultSettings["TODO"] = OrgDefaultTodoKeywords
 
 	trimmedBase := strings.TrimRight(strings.TrimSpace(basePath), "/")
 	if trimmedBase == "" {
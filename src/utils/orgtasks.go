@@ -0,0 +1,551 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrgDefaultTodoKeywords is the TODO sequence used when a file declares
+// none with #+TODO. Keywords after the bar mark a task as done.
+const OrgDefaultTodoKeywords = "TODO PROJ STRT WAIT HOLD | DONE KILL"
+
+// orgMaxRepeats bounds how many repeats are stepped through when looking
+// for an occurrence, so a tiny interval on an ancient date stays cheap
+const orgMaxRepeats = 10000
+
+var (
+	orgHeadlinePattern     = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgPriorityPattern     = regexp.MustCompile(`^\[#([A-Za-z0-9])\]\s*`)
+	orgTagsPattern         = regexp.MustCompile(`\s+(:[^\s:]+(?::[^\s:]+)*:)\s*$`)
+	orgPlanningPattern     = regexp.MustCompile(`^\s*(?:SCHEDULED|DEADLINE|CLOSED):`)
+	orgScheduledPattern    = regexp.MustCompile(`SCHEDULED:\s*<([^>]+)>`)
+	orgDeadlinePattern     = regexp.MustCompile(`DEADLINE:\s*<([^>]+)>`)
+	orgClosedPattern       = regexp.MustCompile(`CLOSED:\s*\[([^\]]+)\]`)
+	orgTodoDirective       = regexp.MustCompile(`(?i)^\s*#\+(?:SEQ_|TYP_)?TODO:\s*(.*)$`)
+	orgFileTagsDirective   = regexp.MustCompile(`(?i)^\s*#\+FILETAGS:\s*(.*)$`)
+	orgBlockBeginDirective = regexp.MustCompile(`(?i)^\s*#\+BEGIN_`)
+	orgBlockEndDirective   = regexp.MustCompile(`(?i)^\s*#\+END_`)
+	orgTimePattern         = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?:-\d{1,2}:\d{2})?$`)
+	orgRepeaterPattern     = regexp.MustCompile(`^(\.\+|\+\+|\+)(\d+)([hdwmy])$`)
+)
+
+// OrgTimestamp is an active org-mode timestamp such as
+// <2025-03-01 Sat 09:00 +1w>. Date holds the wall-clock date and time in UTC.
+type OrgTimestamp struct {
+	Date     time.Time
+	HasTime  bool
+	Repeater string // e.g. "+1w", ".+1d" or "++1m", empty when not repeating
+}
+
+// Day returns the timestamp's date at midnight
+func (ts OrgTimestamp) Day() time.Time {
+	return time.Date(ts.Date.Year(), ts.Date.Month(), ts.Date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// repeater splits the repeater into its mark (+, ++ or .+), count and unit
+func (ts OrgTimestamp) repeater() (mark string, count int, unit byte, ok bool) {
+	matches := orgRepeaterPattern.FindStringSubmatch(ts.Repeater)
+	if matches == nil {
+		return "", 0, 0, false
+	}
+
+	count, err := strconv.Atoi(matches[2])
+	if err != nil || count <= 0 {
+		return "", 0, 0, false
+	}
+
+	return matches[1], count, matches[3][0], true
+}
+
+// repeatStep returns the interval of the repeater, with hourly repeats
+// stepping a day since the agenda only deals in days
+func (ts OrgTimestamp) repeatStep() (count int, unit byte, ok bool) {
+	_, count, unit, ok = ts.repeater()
+	if !ok {
+		return 0, 0, false
+	}
+
+	if unit == 'h' {
+		count, unit = 1, 'd'
+	}
+
+	return count, unit, true
+}
+
+// RepeatAfter returns where a repeating timestamp moves when its task is
+// marked done at now: "+" shifts it once, "++" shifts it until it is in the
+// future and ".+" shifts it from now. The time of day is kept except for
+// hourly repeats.
+func (ts OrgTimestamp) RepeatAfter(now time.Time) (time.Time, bool) {
+	mark, count, unit, ok := ts.repeater()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	shift := func(t time.Time) time.Time {
+		if unit == 'h' {
+			return t.Add(time.Duration(count) * time.Hour)
+		}
+
+		return addOrgInterval(t, unit, count)
+	}
+
+	// Wall-clock time in UTC, the form timestamps are parsed into
+	wallNow := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+
+	switch mark {
+	case ".+":
+		from := time.Date(wallNow.Year(), wallNow.Month(), wallNow.Day(), ts.Date.Hour(), ts.Date.Minute(), 0, 0, time.UTC)
+		if unit == 'h' {
+			from = wallNow
+		}
+
+		return shift(from), true
+	case "++":
+		next := shift(ts.Date)
+		for i := 0; i < orgMaxRepeats && !next.After(wallNow); i++ {
+			next = shift(next)
+		}
+
+		return next, true
+	default:
+		return shift(ts.Date), true
+	}
+}
+
+// OccurrencesBetween returns the days between from and to (inclusive) the
+// timestamp falls on, following its repeater when it has one
+func (ts OrgTimestamp) OccurrencesBetween(from, to time.Time) []time.Time {
+	base := ts.Day()
+
+	count, unit, repeating := ts.repeatStep()
+	if !repeating {
+		if base.Before(from) || base.After(to) {
+			return nil
+		}
+
+		return []time.Time{base}
+	}
+
+	var occurrences []time.Time
+
+	for i := 0; i < orgMaxRepeats; i++ {
+		occurrence := addOrgInterval(base, unit, count*i)
+		if occurrence.After(to) {
+			break
+		}
+
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences
+}
+
+// NextOccurrence returns the first day on or after from that the timestamp
+// falls on, if any
+func (ts OrgTimestamp) NextOccurrence(from time.Time) (time.Time, bool) {
+	base := ts.Day()
+	if !base.Before(from) {
+		return base, true
+	}
+
+	count, unit, repeating := ts.repeatStep()
+	if !repeating {
+		return time.Time{}, false
+	}
+
+	for i := 1; i < orgMaxRepeats; i++ {
+		if occurrence := addOrgInterval(base, unit, count*i); !occurrence.Before(from) {
+			return occurrence, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// addOrgInterval adds n units of a repeater interval to a day
+func addOrgInterval(day time.Time, unit byte, n int) time.Time {
+	switch unit {
+	case 'w':
+		return day.AddDate(0, 0, 7*n)
+	case 'm':
+		return day.AddDate(0, n, 0)
+	case 'y':
+		return day.AddDate(n, 0, 0)
+	default:
+		return day.AddDate(0, 0, n)
+	}
+}
+
+// OrgTask is a parsed headline. As a task it carries a TODO keyword or
+// planning timestamps.
+type OrgTask struct {
+	Line      int // Zero-based line of the headline in the file
+	Level     int
+	Keyword   string // Empty for headlines that are only scheduled
+	Done      bool
+	Priority  string
+	Title     string
+	Tags      []string // Own tags followed by inherited and file tags
+	Scheduled *OrgTimestamp
+	Deadline  *OrgTimestamp
+	Closed    *time.Time
+}
+
+// Stars returns the leading stars of the headline, e.g. "**" on level two
+func (t OrgTask) Stars() string {
+	return strings.Repeat("*", t.Level)
+}
+
+// HasTag reports whether the task carries a tag, ignoring case
+func (t OrgTask) HasTag(tag string) bool {
+	for _, candidate := range t.Tags {
+		if strings.EqualFold(candidate, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrgTodoKeywords is the TODO sequence of a file
+type OrgTodoKeywords struct {
+	Active []string
+	Done   []string
+}
+
+// Lookup reports whether word is a keyword and whether it marks a task done
+func (k OrgTodoKeywords) Lookup(word string) (isKeyword, done bool) {
+	for _, keyword := range k.Active {
+		if keyword == word {
+			return true, false
+		}
+	}
+
+	for _, keyword := range k.Done {
+		if keyword == word {
+			return true, true
+		}
+	}
+
+	return false, false
+}
+
+// All returns the keywords in cycling order, active ones first
+func (k OrgTodoKeywords) All() []string {
+	all := make([]string, 0, len(k.Active)+len(k.Done))
+	all = append(all, k.Active...)
+
+	return append(all, k.Done...)
+}
+
+// Next returns the keyword after the given one, wrapping around to the
+// first rather than dropping the keyword as org does, so a task stays a
+// task. A headline without a keyword moves to the first one.
+func (k OrgTodoKeywords) Next(keyword string) string {
+	all := k.All()
+	if len(all) == 0 {
+		return ""
+	}
+
+	for i, candidate := range all {
+		if candidate == keyword {
+			return all[(i+1)%len(all)]
+		}
+	}
+
+	return all[0]
+}
+
+// add appends a #+TODO sequence. Without a bar the last keyword is the
+// done state. Fast access keys like TODO(t) are dropped.
+func (k *OrgTodoKeywords) add(sequence string) {
+	var (
+		active, done []string
+		seenBar      bool
+	)
+
+	for _, field := range strings.Fields(sequence) {
+		if field == "|" {
+			seenBar = true
+			continue
+		}
+
+		if index := strings.Index(field, "("); index > 0 {
+			field = field[:index]
+		}
+
+		if seenBar {
+			done = append(done, field)
+		} else {
+			active = append(active, field)
+		}
+	}
+
+	if !seenBar && len(active) > 0 {
+		done = active[len(active)-1:]
+		active = active[:len(active)-1]
+	}
+
+	k.Active = append(k.Active, active...)
+	k.Done = append(k.Done, done...)
+}
+
+// ParseOrgTodoKeywords returns the TODO sequence declared by the #+TODO
+// lines of a file, or OrgDefaultTodoKeywords when there are none
+func ParseOrgTodoKeywords(content string) OrgTodoKeywords {
+	var keywords OrgTodoKeywords
+
+	for _, line := range strings.Split(content, "\n") {
+		if matches := orgTodoDirective.FindStringSubmatch(strings.TrimRight(line, "\r")); matches != nil {
+			keywords.add(matches[1])
+		}
+	}
+
+	if len(keywords.Active) == 0 && len(keywords.Done) == 0 {
+		keywords.add(OrgDefaultTodoKeywords)
+	}
+
+	return keywords
+}
+
+// parseOrgTags splits a :tag1:tag2: string
+func parseOrgTags(raw string) []string {
+	var tags []string
+
+	for _, tag := range strings.Split(strings.TrimSpace(raw), ":") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// ParseOrgTimestamp parses the inside of an org timestamp, e.g.
+// "2025-03-01 Sat 09:00 +1w", ignoring day names and warning periods
+func ParseOrgTimestamp(raw string) (OrgTimestamp, bool) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return OrgTimestamp{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", fields[0])
+	if err != nil {
+		return OrgTimestamp{}, false
+	}
+
+	ts := OrgTimestamp{Date: date}
+
+	for _, field := range fields[1:] {
+		switch {
+		case orgTimePattern.MatchString(field):
+			matches := orgTimePattern.FindStringSubmatch(field)
+			hour, _ := strconv.Atoi(matches[1])
+			minute, _ := strconv.Atoi(matches[2])
+
+			if hour < 24 && minute < 60 {
+				ts.Date = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, time.UTC)
+				ts.HasTime = true
+			}
+		case orgRepeaterPattern.MatchString(field):
+			ts.Repeater = field
+		}
+	}
+
+	return ts, true
+}
+
+// parseOrgHeadline splits a headline into keyword, priority, title and tags
+func parseOrgHeadline(text string, keywords OrgTodoKeywords) OrgTask {
+	var task OrgTask
+
+	if matches := orgTagsPattern.FindStringSubmatchIndex(text); matches != nil {
+		task.Tags = parseOrgTags(text[matches[2]:matches[3]])
+		text = text[:matches[0]]
+	}
+
+	if word, rest, _ := strings.Cut(text, " "); word != "" {
+		if isKeyword, done := keywords.Lookup(word); isKeyword {
+			task.Keyword = word
+			task.Done = done
+			text = rest
+		}
+	}
+
+	text = strings.TrimSpace(text)
+	if matches := orgPriorityPattern.FindStringSubmatch(text); matches != nil {
+		task.Priority = strings.ToUpper(matches[1])
+		text = text[len(matches[0]):]
+	}
+
+	task.Title = strings.TrimSpace(text)
+
+	return task
+}
+
+// parseOrgPlanning fills in the planning timestamps of a task from the
+// line following its headline
+func parseOrgPlanning(task *OrgTask, line string) {
+	if matches := orgScheduledPattern.FindStringSubmatch(line); matches != nil {
+		if ts, ok := ParseOrgTimestamp(matches[1]); ok {
+			task.Scheduled = &ts
+		}
+	}
+
+	if matches := orgDeadlinePattern.FindStringSubmatch(line); matches != nil {
+		if ts, ok := ParseOrgTimestamp(matches[1]); ok {
+			task.Deadline = &ts
+		}
+	}
+
+	if matches := orgClosedPattern.FindStringSubmatch(line); matches != nil {
+		if ts, ok := ParseOrgTimestamp(matches[1]); ok {
+			closed := ts.Date
+			task.Closed = &closed
+		}
+	}
+}
+
+// ParseOrgTasks returns the headlines of an org file that carry a TODO
+// keyword or a SCHEDULED or DEADLINE timestamp, in file order. Keywords
+// come from #+TODO lines, or OrgDefaultTodoKeywords when there are none.
+// Tags are inherited from parent headlines and #+FILETAGS.
+func ParseOrgTasks(content string) []OrgTask {
+	var tasks []OrgTask
+
+	for _, headline := range ParseOrgHeadlines(content) {
+		if headline.Keyword != "" || headline.Scheduled != nil || headline.Deadline != nil {
+			tasks = append(tasks, headline)
+		}
+	}
+
+	return tasks
+}
+
+// ParseOrgHeadlines returns every headline of an org file in file order,
+// parsed like ParseOrgTasks, skipping lines inside #+BEGIN blocks
+func ParseOrgHeadlines(content string) []OrgTask {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	keywords := ParseOrgTodoKeywords(content)
+
+	var fileTags []string
+
+	for _, line := range lines {
+		if matches := orgFileTagsDirective.FindStringSubmatch(line); matches != nil {
+			fileTags = append(fileTags, parseOrgTags(matches[1])...)
+		}
+	}
+
+	var (
+		headlines []OrgTask
+		parents   [][]string // Own tags of the enclosing headlines by level
+		inBlock   bool
+	)
+
+	for i, line := range lines {
+		if inBlock {
+			inBlock = !orgBlockEndDirective.MatchString(line)
+			continue
+		}
+
+		if orgBlockBeginDirective.MatchString(line) {
+			inBlock = true
+			continue
+		}
+
+		matches := orgHeadlinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		level := len(matches[1])
+		headline := parseOrgHeadline(matches[2], keywords)
+		headline.Line = i
+		headline.Level = level
+
+		if len(parents) >= level {
+			parents = parents[:level-1]
+		}
+
+		for len(parents) < level-1 {
+			parents = append(parents, nil)
+		}
+
+		parents = append(parents, headline.Tags)
+
+		if i+1 < len(lines) && orgPlanningPattern.MatchString(lines[i+1]) {
+			parseOrgPlanning(&headline, lines[i+1])
+		}
+
+		headline.Tags = inheritOrgTags(headline.Tags, parents[:level-1], fileTags)
+		headlines = append(headlines, headline)
+	}
+
+	return headlines
+}
+
+// inheritOrgTags appends the tags of parent headlines and the file to the
+// task's own tags, skipping duplicates
+func inheritOrgTags(own []string, parents [][]string, fileTags []string) []string {
+	tags := make([]string, 0, len(own)+len(fileTags))
+	seen := make(map[string]struct{}, cap(tags))
+
+	appendTags := func(candidates []string) {
+		for _, tag := range candidates {
+			key := strings.ToLower(tag)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			tags = append(tags, tag)
+		}
+	}
+
+	appendTags(own)
+
+	for i := len(parents) - 1; i >= 0; i-- {
+		appendTags(parents[i])
+	}
+
+	appendTags(fileTags)
+
+	return tags
+}
+
+// OrgTaskTags returns every tag used by the tasks, sorted ignoring case
+func OrgTaskTags(tasks []OrgTask) []string {
+	seen := make(map[string]struct{})
+
+	var tags []string
+
+	for _, task := range tasks {
+		for _, tag := range task.Tags {
+			key := strings.ToLower(tag)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			tags = append(tags, tag)
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+
+	return tags
+}
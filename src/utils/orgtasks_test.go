@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseOrgTasks(t *testing.T) {
+	t.Parallel()
+
+	content := "#+TITLE: Tasks\n#+FILETAGS: :home:\n" +
+		"* Work :work:\n" +
+		"** TODO [#A] Ship report :urgent:\n   DEADLINE: <2025-03-05 Wed -2d>\n" +
+		"** STRT Review PR\n   SCHEDULED: <2025-03-03 Mon 09:30 +1w>\n" +
+		"** DONE Old thing\n   CLOSED: [2025-03-02 Sun 11:00] SCHEDULED: <2025-03-01 Sat>\n" +
+		"* Meeting\n  SCHEDULED: <2025-03-04 Tue 14:00-15:00>\n" +
+		"* Notes\n" +
+		"#+BEGIN_SRC org\n* TODO Not a task\n#+END_SRC\n" +
+		"* TODO Buy milk"
+
+	tasks := ParseOrgTasks(content)
+	if len(tasks) != 5 {
+		t.Fatalf("expected 5 tasks, got %#v", tasks)
+	}
+
+	report := tasks[0]
+	if report.Keyword != "TODO" || report.Done || report.Priority != "A" || report.Title != "Ship report" || report.Line != 3 || report.Level != 2 {
+		t.Fatalf("unexpected task: %#v", report)
+	}
+
+	if !reflect.DeepEqual(report.Tags, []string{"urgent", "work", "home"}) {
+		t.Fatalf("expected own, inherited and file tags, got %v", report.Tags)
+	}
+
+	if report.Deadline == nil || report.Deadline.Day() != time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC) || report.Scheduled != nil {
+		t.Fatalf("unexpected deadline: %#v", report.Deadline)
+	}
+
+	review := tasks[1]
+	if review.Scheduled == nil || !review.Scheduled.HasTime || review.Scheduled.Date.Hour() != 9 || review.Scheduled.Repeater != "+1w" {
+		t.Fatalf("unexpected scheduled timestamp: %#v", review.Scheduled)
+	}
+
+	done := tasks[2]
+	if !done.Done || done.Closed == nil || done.Closed.Hour() != 11 {
+		t.Fatalf("expected a closed task, got %#v", done)
+	}
+
+	if meeting := tasks[3]; meeting.Keyword != "" || meeting.Title != "Meeting" || meeting.Scheduled == nil || meeting.Scheduled.Date.Hour() != 14 {
+		t.Fatalf("expected a scheduled headline without keyword, got %#v", meeting)
+	}
+
+	if milk := tasks[4]; milk.Title != "Buy milk" || !reflect.DeepEqual(milk.Tags, []string{"home"}) {
+		t.Fatalf("unexpected last task: %#v", milk)
+	}
+}
+
+func TestParseOrgTasksUsesFileKeywords(t *testing.T) {
+	t.Parallel()
+
+	tasks := ParseOrgTasks("#+TODO: NEXT(n) WAITING | FINISHED(f)\n#+TODO: IDEA\n* NEXT Call\n* FINISHED Write\n* IDEA Blog\n* TODO Plain")
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %#v", tasks)
+	}
+
+	if tasks[0].Keyword != "NEXT" || tasks[0].Done || tasks[1].Keyword != "FINISHED" || !tasks[1].Done {
+		t.Fatalf("unexpected keywords: %#v", tasks)
+	}
+
+	// Without a bar the last keyword of a sequence is the done state
+	if tasks[2].Keyword != "IDEA" || !tasks[2].Done {
+		t.Fatalf("expected IDEA to be a done keyword, got %#v", tasks[2])
+	}
+}
+
+func TestOrgTimestampOccurrences(t *testing.T) {
+	t.Parallel()
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	weekly, ok := ParseOrgTimestamp("2025-03-03 Mon +1w")
+	if !ok {
+		t.Fatalf("failed to parse timestamp")
+	}
+
+	got := weekly.OccurrencesBetween(day(time.March, 5), day(time.March, 24))
+	if !reflect.DeepEqual(got, []time.Time{day(time.March, 10), day(time.March, 17), day(time.March, 24)}) {
+		t.Fatalf("unexpected weekly occurrences: %v", got)
+	}
+
+	if next, ok := weekly.NextOccurrence(day(time.March, 11)); !ok || !next.Equal(day(time.March, 17)) {
+		t.Fatalf("unexpected next occurrence: %v", next)
+	}
+
+	monthly, _ := ParseOrgTimestamp("2025-01-15 Wed .+1m")
+	if got := monthly.OccurrencesBetween(day(time.February, 1), day(time.March, 31)); !reflect.DeepEqual(got, []time.Time{day(time.February, 15), day(time.March, 15)}) {
+		t.Fatalf("unexpected monthly occurrences: %v", got)
+	}
+
+	once, _ := ParseOrgTimestamp("2025-03-03 Mon")
+	if got := once.OccurrencesBetween(day(time.March, 4), day(time.March, 10)); got != nil {
+		t.Fatalf("expected no occurrence outside the range, got %v", got)
+	}
+
+	if _, ok := once.NextOccurrence(day(time.March, 4)); ok {
+		t.Fatalf("expected a past timestamp without repeater to have no next occurrence")
+	}
+
+	if _, ok := ParseOrgTimestamp("soon"); ok {
+		t.Fatalf("expected an invalid timestamp to fail")
+	}
+}
+
+func TestOrgTimestampRepeatAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 12, 15, 0, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"2025-03-03 Mon 09:00 +1w":  "2025-03-10 09:00",
+		"2025-03-03 Mon 09:00 ++1w": "2025-03-17 09:00",
+		"2025-03-03 Mon 09:00 .+1w": "2025-03-19 09:00",
+		"2025-01-31 Fri +1m":        "2025-03-03 00:00",
+		"2025-03-12 Wed 14:00 .+2h": "2025-03-12 17:00",
+	}
+
+	for raw, want := range tests {
+		ts, ok := ParseOrgTimestamp(raw)
+		if !ok {
+			t.Fatalf("failed to parse %q", raw)
+		}
+
+		next, ok := ts.RepeatAfter(now)
+		if !ok || next.Format("2006-01-02 15:04") != want {
+			t.Fatalf("RepeatAfter(%q) = %v, want %s", raw, next, want)
+		}
+	}
+
+	if _, ok := (OrgTimestamp{Date: now}).RepeatAfter(now); ok {
+		t.Fatalf("expected a timestamp without repeater not to repeat")
+	}
+}
+
+func TestOrgTodoKeywordsNext(t *testing.T) {
+	t.Parallel()
+
+	keywords := ParseOrgTodoKeywords("#+TODO: TODO NEXT | DONE")
+
+	for current, want := range map[string]string{"TODO": "NEXT", "NEXT": "DONE", "DONE": "TODO", "": "TODO"} {
+		if got := keywords.Next(current); got != want {
+			t.Fatalf("Next(%q) = %q, want %q", current, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2025 Humaid Alqasimi
+ * SPDX-License-Identifier: Apache-2.0
+ */
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Register GIF decoding for uploaded photos
+	"image/jpeg"
+	_ "image/png" // Register PNG decoding for uploaded photos
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// photoJPEGQuality is used for stored photos and thumbnails
+const photoJPEGQuality = 88
+
+// maxPhotoPixels guards against decoding huge images into memory
+const maxPhotoPixels = 50_000_000
+
+// PhotoCrop selects a square region of an image. Zoom 1 uses the largest
+// square that fits; higher values zoom in. X and Y place the centre of the
+// square as a fraction (0 to 1) of the space left over on each axis.
+type PhotoCrop struct {
+	Zoom float64
+	X    float64
+	Y    float64
+}
+
+// DefaultPhotoCrop is the largest centred square
+var DefaultPhotoCrop = PhotoCrop{Zoom: 1, X: 0.5, Y: 0.5}
+
+// cropRect returns the square within bounds that the crop selects
+func (c PhotoCrop) cropRect(bounds image.Rectangle) image.Rectangle {
+	zoom := c.Zoom
+	if math.IsNaN(zoom) || zoom < 1 {
+		zoom = 1
+	}
+
+	side := int(float64(min(bounds.Dx(), bounds.Dy())) / zoom)
+	if side < 1 {
+		side = 1
+	}
+
+	x := bounds.Min.X + int(math.Round(clampUnit(c.X)*float64(bounds.Dx()-side)))
+	y := bounds.Min.Y + int(math.Round(clampUnit(c.Y)*float64(bounds.Dy()-side)))
+
+	return image.Rect(x, y, x+side, y+side)
+}
+
+func clampUnit(value float64) float64 {
+	if math.IsNaN(value) {
+		return 0.5
+	}
+
+	return math.Max(0, math.Min(1, value))
+}
+
+// CropPhoto decodes a JPEG, PNG or GIF image, crops the selected square and
+// scales it to size×size pixels, returning it encoded as JPEG.
+func CropPhoto(r io.Reader, crop PhotoCrop, size int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo header: %w", err)
+	}
+
+	if config.Width*config.Height > maxPhotoPixels {
+		return nil, fmt.Errorf("%w: %dx%d", errPhotoTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+
+	return scalePhoto(img, crop.cropRect(img.Bounds()), size)
+}
+
+// ResizePhoto scales an already square JPEG photo down to size×size pixels.
+// Photos that are already small enough are returned unchanged.
+func ResizePhoto(data []byte, size int) ([]byte, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+
+	if img.Bounds().Dx() <= size && img.Bounds().Dy() <= size {
+		return data, nil
+	}
+
+	return scalePhoto(img, img.Bounds(), size)
+}
+
+func scalePhoto(img image.Image, src image.Rectangle, size int) ([]byte, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("%w: %d", errPhotoSizeInvalid, size)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: photoJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode photo: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2025 Humaid Alqasimi
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// splitTestImage is red on the left half and blue on the right half
+func splitTestImage(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+
+	for y := range 100 {
+		for x := range 200 {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func decodeTestJPEG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected JPEG output: %v", err)
+	}
+
+	return img
+}
+
+func TestCropPhotoSelectsSquare(t *testing.T) {
+	t.Parallel()
+
+	source := splitTestImage(t)
+
+	tests := []struct {
+		name    string
+		crop    PhotoCrop
+		wantRed bool
+	}{
+		{name: "left", crop: PhotoCrop{Zoom: 1, X: 0, Y: 0.5}, wantRed: true},
+		{name: "right", crop: PhotoCrop{Zoom: 1, X: 1, Y: 0.5}, wantRed: false},
+		{name: "zoomed left", crop: PhotoCrop{Zoom: 2, X: 0.2, Y: 0.5}, wantRed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := CropPhoto(bytes.NewReader(source), tt.crop, 32)
+			if err != nil {
+				t.Fatalf("CropPhoto failed: %v", err)
+			}
+
+			img := decodeTestJPEG(t, out)
+			if img.Bounds().Dx() != 32 || img.Bounds().Dy() != 32 {
+				t.Fatalf("expected 32x32 output, got %v", img.Bounds())
+			}
+
+			r, _, b, _ := img.At(16, 16).RGBA()
+			if isRed := r > b; isRed != tt.wantRed {
+				t.Fatalf("unexpected colour at centre: r=%d b=%d", r, b)
+			}
+		})
+	}
+}
+
+func TestCropPhotoRejectsNonImages(t *testing.T) {
+	t.Parallel()
+
+	if _, err := CropPhoto(strings.NewReader("not an image"), DefaultPhotoCrop, 32); err == nil {
+		t.Fatal("expected an error for non-image input")
+	}
+}
+
+func TestResizePhoto(t *testing.T) {
+	t.Parallel()
+
+	stored, err := CropPhoto(bytes.NewReader(splitTestImage(t)), DefaultPhotoCrop, 64)
+	if err != nil {
+		t.Fatalf("CropPhoto failed: %v", err)
+	}
+
+	small, err := ResizePhoto(stored, 16)
+	if err != nil {
+		t.Fatalf("ResizePhoto failed: %v", err)
+	}
+
+	if img := decodeTestJPEG(t, small); img.Bounds().Dx() != 16 {
+		t.Fatalf("expected 16px thumbnail, got %v", img.Bounds())
+	}
+
+	same, err := ResizePhoto(stored, 128)
+	if err != nil {
+		t.Fatalf("ResizePhoto failed: %v", err)
+	}
+
+	if !bytes.Equal(same, stored) {
+		t.Fatal("expected photos smaller than the size to be returned unchanged")
+	}
+}
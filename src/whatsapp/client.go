@@ -14,6 +14,7 @@ import (
 
 	"github.com/skip2/go-qrcode"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
@@ -32,8 +33,44 @@ const (
 	StatusPairing      Status = "pairing"
 )
 
+// MessageKind tells a new message apart from a change to an earlier one
+type MessageKind string
+
+// MessageKind values describe what a message event does.
+const (
+	MessageNew    MessageKind = "new"
+	MessageEdit   MessageKind = "edit"
+	MessageRevoke MessageKind = "revoke"
+)
+
+// Media describes an attachment on a message
+type Media struct {
+	Type     string // image, video, audio, voice, document or sticker
+	MimeType string
+	FileName string
+	Size     uint64
+	file     whatsmeow.DownloadableMessage
+}
+
+// Message is a direct-chat message sent or received on WhatsApp. Edits and
+// revokes carry the ID of the message they change.
+type Message struct {
+	JID        string
+	ID         string
+	Timestamp  time.Time
+	IsOutgoing bool
+	Kind       MessageKind
+	Text       string // Message text or media caption
+	ReplyToID  string
+	Media      *Media
+}
+
 // MessageHandler is called when a WhatsApp message is sent or received
-type MessageHandler func(jid string, timestamp time.Time, isOutgoing bool, message string)
+type MessageHandler func(message Message)
+
+// HistoryHandler is called with the direct-chat messages of each history
+// sync blob the phone sends, starting right after pairing
+type HistoryHandler func(messages []Message)
 
 // Client manages the WhatsApp connection via whatsmeow
 type Client struct {
@@ -44,6 +81,7 @@ type Client struct {
 	qrCode        string // Base64 encoded PNG
 	mu            sync.RWMutex
 	onMessage     MessageHandler
+	onHistory     HistoryHandler
 	stopReconnect chan struct{}
 }
 
@@ -58,7 +96,7 @@ func GetClient() *Client {
 }
 
 // Initialize sets up the WhatsApp client with PostgreSQL storage
-func Initialize(ctx context.Context, databaseURL string, onMessage MessageHandler) error {
+func Initialize(ctx context.Context, databaseURL string, onMessage MessageHandler, onHistory HistoryHandler) error {
 	var initErr error
 
 	once.Do(func() {
@@ -87,6 +125,7 @@ func Initialize(ctx context.Context, databaseURL string, onMessage MessageHandle
 			deviceStore:   deviceStore,
 			status:        StatusDisconnected,
 			onMessage:     onMessage,
+			onHistory:     onHistory,
 			stopReconnect: make(chan struct{}),
 		}
 
@@ -346,22 +385,52 @@ func (c *Client) handleEvent(evt interface{}) {
 
 	case *events.Message:
 		c.handleMessage(v)
+
+	case *events.HistorySync:
+		c.handleHistorySync(v)
 	}
 }
 
 // handleMessage processes incoming/outgoing messages
 func (c *Client) handleMessage(evt *events.Message) {
+	c.dispatchMessage(evt, c.lookupPNForLID)
+}
+
+// lookupPNForLID returns the phone number JID stored for a LID, or an empty
+// JID when none is known
+func (c *Client) lookupPNForLID(lid types.JID) types.JID {
+	if c.client == nil || c.client.Store == nil || c.client.Store.LIDs == nil {
+		return types.JID{}
+	}
+
+	pn, err := c.client.Store.LIDs.GetPNForLID(context.Background(), lid)
+	if err != nil {
+		logger.Warn("Failed to look up phone number for WhatsApp LID", "lid", lid, "error", err)
+	}
+
+	return pn
+}
+
+// dispatchMessage passes a direct-chat message on to the message handler.
+// A message whose other party only has a LID, with no phone number known
+// for it, is dropped, as the LID is not a phone number to match contacts on.
+func (c *Client) dispatchMessage(evt *events.Message, lookupPN func(types.JID) types.JID) {
 	// Skip group messages - only track direct chats
 	if evt.Info.IsGroup {
 		return
 	}
 
-	messageText := extractMessageText(evt)
-	if messageText == "" {
+	otherParty := directChatPhoneJID(resolveOtherPartyJID(evt.Info), "", lookupPN)
+	if otherParty.IsEmpty() {
+		logger.Debug("Skipping WhatsApp message without a phone number", "chat", evt.Info.Chat, "message_id", evt.Info.ID)
+
 		return
 	}
 
-	otherParty := resolveOtherPartyJID(evt.Info)
+	message, ok := messageFromEvent(otherParty, evt)
+	if !ok {
+		return
+	}
 
 	logger.Info(
 		"WhatsApp message info",
@@ -374,15 +443,90 @@ func (c *Client) handleMessage(evt *events.Message) {
 		"device_sent_meta", evt.Info.DeviceSentMeta,
 		"message_id", evt.Info.ID,
 		"message_type", evt.Info.Type,
+		"kind", message.Kind,
 	)
 
-	// Determine if this is an outgoing message
-	isOutgoing := isOutgoingMessage(evt.Info)
-
 	// Call the message handler
 	if c.onMessage != nil {
-		c.onMessage(otherParty.User, evt.Info.Timestamp, isOutgoing, messageText)
+		c.onMessage(message)
+	}
+}
+
+// handleHistorySync collects the direct-chat text messages of a history
+// sync blob and passes them on in one batch
+func (c *Client) handleHistorySync(evt *events.HistorySync) {
+	if c.onHistory == nil || evt.Data == nil {
+		return
+	}
+
+	var messages []Message
+
+	conversations := evt.Data.GetConversations()
+	for _, conversation := range conversations {
+		chatJID, err := types.ParseJID(conversation.GetID())
+		if err != nil {
+			logger.Warn("Failed to parse WhatsApp history chat", "chat", conversation.GetID(), "error", err)
+			continue
+		}
+
+		phoneJID := directChatPhoneJID(chatJID, conversation.GetPnJID(), c.lookupPNForLID)
+		if phoneJID.IsEmpty() {
+			continue
+		}
+
+		for _, historyMsg := range conversation.GetMessages() {
+			msgEvt, err := c.client.ParseWebMessage(chatJID, historyMsg.GetMessage())
+			if err != nil {
+				logger.Warn("Failed to parse WhatsApp history message", "chat", chatJID, "error", err)
+				continue
+			}
+
+			// History carries edits already applied and no revoked messages
+			if message, ok := messageFromEvent(phoneJID, msgEvt); ok && message.Kind == MessageNew {
+				messages = append(messages, message)
+			}
+		}
+	}
+
+	logger.Info(
+		"WhatsApp history sync received",
+		"sync_type", evt.Data.GetSyncType().String(),
+		"chunk", evt.Data.GetChunkOrder(),
+		"progress", evt.Data.GetProgress(),
+		"conversations", len(conversations),
+		"messages", len(messages),
+	)
+
+	if len(messages) > 0 {
+		c.onHistory(messages)
+	}
+}
+
+// directChatPhoneJID returns the phone number JID of a direct chat, looking
+// LID chats up by their phone number. Group and other chats, and LIDs with
+// no known phone number, give an empty JID.
+func directChatPhoneJID(chat types.JID, pnJID string, lookupPN func(types.JID) types.JID) types.JID {
+	chat = chat.ToNonAD()
+
+	if isPhoneNumberServer(chat.Server) {
+		return chat
+	}
+
+	if !isLIDServer(chat.Server) {
+		return types.JID{}
+	}
+
+	if pn, err := types.ParseJID(pnJID); err == nil && isPhoneNumberServer(pn.Server) {
+		return pn.ToNonAD()
 	}
+
+	if lookupPN != nil {
+		if pn := lookupPN(chat); !pn.IsEmpty() && isPhoneNumberServer(pn.Server) {
+			return pn.ToNonAD()
+		}
+	}
+
+	return types.JID{}
 }
 
 func resolveOtherPartyJID(info types.MessageInfo) types.JID {
@@ -460,34 +604,183 @@ func isOutgoingMessage(info types.MessageInfo) bool {
 	return strings.TrimSpace(info.DeviceSentMeta.DestinationJID) != ""
 }
 
-func extractMessageText(evt *events.Message) string {
-	if evt == nil {
-		return ""
+// messageFromEvent converts a message event from or to the given party,
+// skipping messages with neither text nor an attachment
+func messageFromEvent(party types.JID, evt *events.Message) (Message, bool) {
+	if evt == nil || evt.Info.IsGroup {
+		return Message{}, false
 	}
 
-	messageEvent := evt.UnwrapRaw()
-	if messageEvent == nil || messageEvent.Message == nil {
-		return ""
+	if evt.Message == nil {
+		evt.UnwrapRaw()
+	}
+
+	content := evt.Message
+	if content == nil {
+		return Message{}, false
+	}
+
+	message := Message{
+		JID:        party.User,
+		ID:         evt.Info.ID,
+		Timestamp:  evt.Info.Timestamp,
+		IsOutgoing: isOutgoingMessage(evt.Info),
+		Kind:       MessageNew,
+	}
+
+	if protocol := content.GetProtocolMessage(); protocol != nil {
+		message.ID = protocol.GetKey().GetID()
+
+		switch protocol.GetType() {
+		case waE2E.ProtocolMessage_REVOKE:
+			message.Kind = MessageRevoke
+
+			return message, message.ID != ""
+		case waE2E.ProtocolMessage_MESSAGE_EDIT:
+			message.Kind = MessageEdit
+			message.Text, _, _ = extractMessageContent(protocol.GetEditedMessage())
+
+			return message, message.ID != "" && message.Text != ""
+		default:
+			return Message{}, false
+		}
+	}
+
+	message.Text, message.Media, message.ReplyToID = extractMessageContent(content)
+	if message.Text == "" && message.Media == nil {
+		return Message{}, false
+	}
+
+	return message, true
+}
+
+// extractMessageContent returns the text or caption of a message, its
+// attachment and the ID of the message it replies to
+func extractMessageContent(message *waE2E.Message) (string, *Media, string) {
+	if message == nil {
+		return "", nil, ""
 	}
 
-	message := messageEvent.Message
 	if text := strings.TrimSpace(message.GetConversation()); text != "" {
-		return text
+		return text, nil, ""
 	}
 
 	if extended := message.GetExtendedTextMessage(); extended != nil {
-		if text := strings.TrimSpace(extended.GetText()); text != "" {
-			return text
-		}
+		return strings.TrimSpace(extended.GetText()), nil, extended.GetContextInfo().GetStanzaID()
 	}
 
 	if image := message.GetImageMessage(); image != nil {
-		if caption := strings.TrimSpace(image.GetCaption()); caption != "" {
-			return "<image> " + caption
+		media := &Media{Type: "image", MimeType: image.GetMimetype(), Size: image.GetFileLength(), file: image}
+
+		return strings.TrimSpace(image.GetCaption()), media, image.GetContextInfo().GetStanzaID()
+	}
+
+	if video := message.GetVideoMessage(); video != nil {
+		media := &Media{Type: "video", MimeType: video.GetMimetype(), Size: video.GetFileLength(), file: video}
+
+		return strings.TrimSpace(video.GetCaption()), media, video.GetContextInfo().GetStanzaID()
+	}
+
+	if audio := message.GetAudioMessage(); audio != nil {
+		media := &Media{Type: "audio", MimeType: audio.GetMimetype(), Size: audio.GetFileLength(), file: audio}
+		if audio.GetPTT() {
+			media.Type = "voice"
+		}
+
+		return "", media, audio.GetContextInfo().GetStanzaID()
+	}
+
+	if document := message.GetDocumentMessage(); document != nil {
+		media := &Media{
+			Type:     "document",
+			MimeType: document.GetMimetype(),
+			FileName: document.GetFileName(),
+			Size:     document.GetFileLength(),
+			file:     document,
+		}
+
+		return strings.TrimSpace(document.GetCaption()), media, document.GetContextInfo().GetStanzaID()
+	}
+
+	if sticker := message.GetStickerMessage(); sticker != nil {
+		media := &Media{Type: "sticker", MimeType: sticker.GetMimetype(), Size: sticker.GetFileLength(), file: sticker}
+
+		return "", media, sticker.GetContextInfo().GetStanzaID()
+	}
+
+	return "", nil, ""
+}
+
+// DownloadMedia fetches and decrypts a message attachment
+func (c *Client) DownloadMedia(ctx context.Context, media *Media) ([]byte, error) {
+	if c.client == nil {
+		return nil, errNotConnected
+	}
+
+	if media == nil || media.file == nil {
+		return nil, errNoMediaToDownload
+	}
+
+	data, err := c.client.Download(ctx, media.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+
+	return data, nil
+}
+
+// SendText sends a text message to a phone number in international format
+// and returns it as a sent message. The number is looked up first, so
+// numbers not on WhatsApp fail before anything is sent.
+func (c *Client) SendText(ctx context.Context, phone, text string) (Message, error) {
+	if c.client == nil || !c.IsConnected() {
+		return Message{}, errNotConnected
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Message{}, errEmptyMessage
+	}
+
+	phone = NormalizePhone(phone)
+	if phone == "" {
+		return Message{}, ErrNotOnWhatsApp
+	}
+
+	responses, err := c.client.IsOnWhatsApp(ctx, []string{"+" + phone})
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to look up WhatsApp number: %w", err)
+	}
+
+	jid, ok := registeredJID(responses)
+	if !ok {
+		return Message{}, ErrNotOnWhatsApp
+	}
+
+	resp, err := c.client.SendMessage(ctx, jid, &waE2E.Message{Conversation: &text})
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	return Message{
+		JID:        jid.User,
+		ID:         resp.ID,
+		Timestamp:  resp.Timestamp,
+		IsOutgoing: true,
+		Kind:       MessageNew,
+		Text:       text,
+	}, nil
+}
+
+// registeredJID picks the chat JID of the first number found on WhatsApp
+func registeredJID(responses []types.IsOnWhatsAppResponse) (types.JID, bool) {
+	for _, response := range responses {
+		if response.IsIn && !response.JID.IsEmpty() {
+			return response.JID.ToNonAD(), true
 		}
 	}
 
-	return ""
+	return types.JID{}, false
 }
 
 // IsConnected returns true if WhatsApp is connected
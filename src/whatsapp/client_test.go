@@ -7,9 +7,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	waStore "go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -323,3 +328,264 @@ func TestIsOutgoingMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestDirectChatPhoneJID(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(lid types.JID) types.JID {
+		if lid.User == "77777777777" {
+			return types.NewJID("44444444444", types.DefaultUserServer)
+		}
+
+		return types.JID{}
+	}
+
+	tests := []struct {
+		name  string
+		chat  types.JID
+		pnJID string
+		want  string
+	}{
+		{
+			name: "phone chat",
+			chat: types.NewADJID("11111111111", 0, 3),
+			want: "11111111111@s.whatsapp.net",
+		},
+		{
+			name:  "lid chat uses conversation phone jid",
+			chat:  types.NewJID("88888888888", types.HiddenUserServer),
+			pnJID: "22222222222@s.whatsapp.net",
+			want:  "22222222222@s.whatsapp.net",
+		},
+		{
+			name: "lid chat falls back to stored mapping",
+			chat: types.NewJID("77777777777", types.HiddenUserServer),
+			want: "44444444444@s.whatsapp.net",
+		},
+		{
+			name: "unmapped lid chat",
+			chat: types.NewJID("66666666666", types.HiddenUserServer),
+			want: "",
+		},
+		{
+			name: "group chat",
+			chat: types.NewJID("12345-67890", types.GroupServer),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := directChatPhoneJID(tt.chat, tt.pnJID, lookup)
+
+			if tt.want == "" {
+				if !got.IsEmpty() {
+					t.Fatalf("directChatPhoneJID() = %s, want empty", got)
+				}
+
+				return
+			}
+
+			if got.String() != tt.want {
+				t.Fatalf("directChatPhoneJID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageFromEvent(t *testing.T) {
+	t.Parallel()
+
+	phone := types.NewJID("11111111111", types.DefaultUserServer)
+	sentAt := time.Unix(1700000000, 0)
+
+	newEvent := func(fromMe bool, message *waE2E.Message) *events.Message {
+		return &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{IsFromMe: fromMe, Chat: phone},
+				ID:            "ABC123",
+				Timestamp:     sentAt,
+			},
+			RawMessage: message,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		evt    *events.Message
+		want   Message
+		wantOK bool
+	}{
+		{
+			name:   "text",
+			evt:    newEvent(true, &waE2E.Message{Conversation: proto.String(" See you soon ")}),
+			want:   Message{JID: "11111111111", ID: "ABC123", Timestamp: sentAt, IsOutgoing: true, Kind: MessageNew, Text: "See you soon"},
+			wantOK: true,
+		},
+		{
+			name: "reply",
+			evt: newEvent(false, &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+				Text:        proto.String("Yes"),
+				ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("QUOTED1")},
+			}}),
+			want:   Message{JID: "11111111111", ID: "ABC123", Timestamp: sentAt, Kind: MessageNew, Text: "Yes", ReplyToID: "QUOTED1"},
+			wantOK: true,
+		},
+		{
+			name: "edit",
+			evt: newEvent(true, &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+				Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+				Key:           &waCommon.MessageKey{ID: proto.String("OLD1")},
+				EditedMessage: &waE2E.Message{Conversation: proto.String("Fixed typo")},
+			}}),
+			want:   Message{JID: "11111111111", ID: "OLD1", Timestamp: sentAt, IsOutgoing: true, Kind: MessageEdit, Text: "Fixed typo"},
+			wantOK: true,
+		},
+		{
+			name: "revoke",
+			evt: newEvent(false, &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+				Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+				Key:  &waCommon.MessageKey{ID: proto.String("OLD2")},
+			}}),
+			want:   Message{JID: "11111111111", ID: "OLD2", Timestamp: sentAt, Kind: MessageRevoke},
+			wantOK: true,
+		},
+		{
+			name:   "empty",
+			evt:    newEvent(false, &waE2E.Message{}),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := messageFromEvent(phone, tt.evt)
+			if ok != tt.wantOK {
+				t.Fatalf("messageFromEvent() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if ok && got != tt.want {
+				t.Fatalf("messageFromEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchMessageResolvesLIDs(t *testing.T) {
+	t.Parallel()
+
+	lid := types.NewJID("99999999999999", types.HiddenUserServer)
+	newEvent := func() *events.Message {
+		return &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{
+					IsFromMe: true,
+					Chat:     lid,
+					Sender:   types.NewJID("11111111111", types.DefaultUserServer),
+				},
+				ID:        "ABC123",
+				Timestamp: time.Unix(1700000000, 0),
+			},
+			RawMessage: &waE2E.Message{Conversation: proto.String("Hello")},
+		}
+	}
+
+	var received []Message
+
+	client := &Client{onMessage: func(message Message) {
+		received = append(received, message)
+	}}
+
+	// Without a known phone number the LID never reaches the handler
+	client.dispatchMessage(newEvent(), func(types.JID) types.JID { return types.JID{} })
+
+	if len(received) != 0 {
+		t.Fatalf("expected a LID-only message to be dropped, got %+v", received)
+	}
+
+	client.dispatchMessage(newEvent(), func(jid types.JID) types.JID {
+		if jid.User == lid.User {
+			return types.NewJID("22222222222", types.DefaultUserServer)
+		}
+
+		return types.JID{}
+	})
+
+	if len(received) != 1 || received[0].JID != "22222222222" || !received[0].IsOutgoing {
+		t.Fatalf("expected the message under the stored phone number, got %+v", received)
+	}
+}
+
+func TestExtractMessageContentMedia(t *testing.T) {
+	t.Parallel()
+
+	text, media, replyTo := extractMessageContent(&waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+		Mimetype:   proto.String("audio/ogg; codecs=opus"),
+		FileLength: proto.Uint64(2048),
+		PTT:        proto.Bool(true),
+	}})
+	if text != "" || replyTo != "" || media == nil || media.Type != "voice" || media.Size != 2048 {
+		t.Fatalf("unexpected voice note content %q %+v %q", text, media, replyTo)
+	}
+
+	text, media, _ = extractMessageContent(&waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+		Mimetype: proto.String("application/pdf"),
+		FileName: proto.String("ticket.pdf"),
+		Caption:  proto.String(" Your ticket "),
+	}})
+	if text != "Your ticket" || media == nil || media.Type != "document" || media.FileName != "ticket.pdf" {
+		t.Fatalf("unexpected document content %q %+v", text, media)
+	}
+}
+
+func TestRegisteredJID(t *testing.T) {
+	t.Parallel()
+
+	phone := types.NewJID("971501234567", types.DefaultUserServer)
+
+	tests := []struct {
+		name      string
+		responses []types.IsOnWhatsAppResponse
+		want      string
+		wantOK    bool
+	}{
+		{name: "no responses"},
+		{
+			name:      "not registered",
+			responses: []types.IsOnWhatsAppResponse{{Query: "+971501234567", JID: phone}},
+		},
+		{
+			name: "registered device JID",
+			responses: []types.IsOnWhatsAppResponse{
+				{Query: "+971501234567", JID: types.JID{User: "971501234567", Device: 3, Server: types.DefaultUserServer}, IsIn: true},
+			},
+			want:   "971501234567@s.whatsapp.net",
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := registeredJID(tt.responses)
+			if ok != tt.wantOK || (ok && got.String() != tt.want) {
+				t.Fatalf("registeredJID() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSendTextRequiresConnection(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{status: StatusDisconnected}
+
+	if _, err := client.SendText(context.Background(), "+971501234567", "Hello"); !errors.Is(err, errNotConnected) {
+		t.Fatalf("expected errNotConnected, got %v", err)
+	}
+}
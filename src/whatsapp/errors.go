@@ -10,4 +10,11 @@ var (
 	errNoExistingSessionToReconnect = errors.New("no existing session to reconnect")
 	errNoDeviceStoreLoader          = errors.New("device store loader is not configured")
 	errNoDeviceStoreContainer       = errors.New("whatsapp SQL store container is unavailable")
+	errNotConnected                 = errors.New("whatsapp client is not connected")
+	errNoMediaToDownload            = errors.New("message has no media to download")
+	errEmptyMessage                 = errors.New("message text is empty")
+
+	// ErrNotOnWhatsApp is returned when sending to a number without a
+	// WhatsApp account
+	ErrNotOnWhatsApp = errors.New("phone number is not on WhatsApp")
 )